@@ -2,35 +2,64 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
 
+	"fyne.io/fyne/v2"
+
+	"github.com/DatKorso/Merge-excel/internal/cli"
 	"github.com/DatKorso/Merge-excel/internal/config"
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
 	"github.com/DatKorso/Merge-excel/internal/gui"
 	"github.com/DatKorso/Merge-excel/internal/logger"
+	"github.com/DatKorso/Merge-excel/internal/profiling"
 	"github.com/DatKorso/Merge-excel/internal/updater"
 )
 
 const (
 	appVersion = "0.1.2"
 	appID      = "com.github.excel-merger"
-	
-	// GitHub repository info
-	githubOwner = "DatKorso"
-	githubRepo  = "Merge-excel"
+)
+
+// Источник обновлений, переопределяется при сборке:
+// go build -ldflags "-X main.githubAPIBaseURL=https://github.example.com/api/v3 -X main.githubOwner=corp"
+var (
+	githubAPIBaseURL = "" // Пусто - https://api.github.com
+	githubOwner      = "DatKorso"
+	githubRepo       = "Merge-excel"
 )
 
 func main() {
+	// Вывод версии (используется и для проверки новой версии при самообновлении)
+	if len(os.Args) > 1 && (os.Args[1] == "--version" || os.Args[1] == "-v") {
+		fmt.Println(appVersion)
+		return
+	}
+
+	// Объединение без GUI: excel-merger merge -profile ... -base ... -output ... файлы
+	if len(os.Args) > 1 && os.Args[1] == "merge" {
+		os.Exit(cli.RunMerge(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// Инициализация директорий приложения
 	if err := initAppDirectories(); err != nil {
 		log.Fatalf("Ошибка при инициализации директорий: %v", err)
 	}
 
-	// Инициализация логгера
+	// Инициализация логгера (уровень из настроек применяется после их загрузки)
 	logCfg := logger.DefaultConfig()
+	if level, _, err := logger.ResolveLevel(""); err == nil {
+		logCfg.Level = level
+	}
+	if format, _, err := logger.ResolveFormat(""); err == nil {
+		logCfg.Format = format
+	}
+	// До загрузки настроек пути скрываются, чтобы первые записи не раскрыли имя пользователя
+	logCfg.Redact = true
 	appLogger, err := logger.InitLogger(logCfg)
 	if err != nil {
 		log.Fatalf("Ошибка при инициализации логгера: %v", err)
@@ -41,14 +70,76 @@ func main() {
 "log_file", logCfg.LogFile,
 )
 
+	// Удаляем резервную копию, оставшуюся после самообновления
+	if exePath, err := os.Executable(); err == nil {
+		if err := updater.CleanupOldBinary(exePath); err != nil {
+			appLogger.Warn("не удалось удалить предыдущую версию приложения", "error", err)
+		}
+	}
+
 	// Инициализация config manager
-	configManager, err := config.NewManager(appLogger)
+	configManager, err := config.NewManager(appLogger.With("component", "config"))
 	if err != nil {
 		log.Fatalf("Ошибка при инициализации config manager: %v", err)
 	}
 
 	// Создание и запуск GUI приложения
 	application := gui.NewApp(appLogger, configManager)
+
+	// Уровень и формат журнала: переменные окружения имеют приоритет над настройками
+	logLevelSetting, logFormatSetting := "", ""
+	if settings := application.GetSettings(); settings != nil {
+		logLevelSetting = settings.LogLevel
+		logFormatSetting = settings.LogFormat
+	}
+	logLevel, logLevelSource, err := logger.ResolveLevel(logLevelSetting)
+	if err != nil {
+		appLogger.Warn("некорректный уровень журнала", "error", err)
+	}
+	logger.SetLevel(logLevel)
+	appLogger.Info("уровень журнала", "level", logLevel.String(), "source", logLevelSource)
+
+	logFormat, logFormatSource, err := logger.ResolveFormat(logFormatSetting)
+	if err != nil {
+		appLogger.Warn("некорректный формат журнала", "error", err)
+	}
+	logger.SetFormat(logFormat)
+	appLogger.Info("формат журнала", "format", logFormat, "source", logFormatSource)
+
+	if settings := application.GetSettings(); settings != nil {
+		logger.SetRedaction(settings.RedactLogPaths, settings.RedactLogRoots)
+	}
+
+	// Настраиваем проверку обновлений
+	updateChecker := updater.NewUpdateChecker(appVersion, githubOwner, githubRepo, appLogger.With("component", "updater"))
+	updateChecker.SetCacheDir(filepath.Join(filepath.Dir(configManager.GetConfigDir()), "cache"))
+	if err := updateChecker.SetSource(updater.Source{APIBaseURL: githubAPIBaseURL}); err != nil {
+		appLogger.Error("некорректный адрес API обновлений в параметрах сборки", "error", err)
+	}
+	if settings := application.GetSettings(); settings != nil {
+		updateChecker.SetChannel(settings.UpdateChannel)
+
+		// Настройки пользователя имеют приоритет над параметрами сборки
+		if err := updateChecker.SetSource(updater.Source{
+			APIBaseURL:   settings.UpdateAPIBaseURL,
+			Owner:        settings.UpdateOwner,
+			Repo:         settings.UpdateRepo,
+			CABundlePath: settings.UpdateCABundlePath,
+		}); err != nil {
+			appLogger.Error("не удалось настроить источник обновлений", "error", err)
+		}
+	}
+	application.SetUpdateChecker(updateChecker, appVersion)
+
+	// Профилирование объединений для диагностики: только по переменным окружения
+	if profilingCfg := profiling.FromEnv(filepath.Dir(logCfg.LogFile)); profilingCfg.Enabled() {
+		application.SetProfiling(profilingCfg)
+		appLogger.Info("профилирование объединений включено",
+			"cpu_profile", profilingCfg.CPUProfile,
+			"mem_profile", profilingCfg.MemProfile,
+			"dir", profilingCfg.Dir,
+		)
+	}
 	
 	appLogger.Info("GUI инициализирован, запускаю приложение")
 	
@@ -73,6 +164,7 @@ func initAppDirectories() error {
 		appDir,
 		filepath.Join(appDir, "configs", "profiles"),
 		filepath.Join(appDir, "logs"),
+		filepath.Join(appDir, "cache"),
 	}
 
 	for _, dir := range dirs {
@@ -86,35 +178,51 @@ func initAppDirectories() error {
 
 // checkForUpdates проверяет наличие обновлений в фоновом режиме
 func checkForUpdates(appLogger *slog.Logger, application *gui.App) {
+	defer apperrors.Recover(appLogger, "автоматическая проверка обновлений", func(err error) {
+		fyne.Do(func() {
+			application.ShowError(err)
+		})
+	})
+
 	// Небольшая задержка, чтобы окно успело загрузиться
 	time.Sleep(2 * time.Second)
 	
+	// Проверяем расписание: проверка может быть отключена или еще не наступило ее время
+	schedule := application.GetUpdateSchedule()
+	if !schedule.ShouldCheck(time.Now()) {
+		appLogger.Info("Автоматическая проверка обновлений пропущена",
+			"enabled", schedule.Enabled,
+			"last_checked_at", schedule.LastCheckedAt,
+			"next_check_at", schedule.NextCheckAt(),
+		)
+		return
+	}
+	
 	appLogger.Info("Запуск проверки обновлений")
 	
 	// Создаем контекст с таймаутом
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 	
-	// Создаем checker для обновлений
-	updateChecker := updater.NewUpdateChecker(appVersion, githubOwner, githubRepo, appLogger)
-	
-	// Проверяем обновления
-	releaseInfo, err := updateChecker.CheckForUpdates(ctx)
-	if err != nil {
-		appLogger.Warn("Не удалось проверить обновления", "error", err)
-		return
+	// Проверяем обновления (параллельно с ручной проверкой не запускается)
+	releaseInfo, err := application.GetUpdateRunner().Check(ctx)
+	outcome := updater.ClassifyCheck(releaseInfo, err)
+	if outcome.Recorded() {
+		application.RecordUpdateCheck(err)
 	}
-	
-	// Если обновление доступно, показываем диалог
-	if releaseInfo != nil && releaseInfo.IsNewer {
-		appLogger.Info("Найдено обновление, показываю диалог",
-			"new_version", releaseInfo.Version,
-		)
-		
-		// Показываем диалог в UI потоке
-		window := application.GetWindow()
-		if window != nil {
-			updater.ShowUpdateDialog(window, releaseInfo)
-		}
+	switch outcome {
+	case updater.OutcomeInProgress:
+		appLogger.Info("Проверка обновлений уже выполняется, фоновая проверка пропущена")
+	case updater.OutcomeRateLimited:
+		appLogger.Info("Лимит запросов GitHub API исчерпан, проверка отложена", "error", err)
+	case updater.OutcomeOffline:
+		appLogger.Debug("Сеть недоступна, проверка обновлений пропущена", "error", err)
+	case updater.OutcomeFailed:
+		appLogger.Warn("Не удалось проверить обновления", "error", err)
+	case updater.OutcomeUpdateAvailable:
+		// Способ уведомления (диалог, баннер или журнал) выбирает приложение согласно настройкам
+		fyne.Do(func() {
+			application.NotifyUpdate(releaseInfo)
+		})
 	}
 }
@@ -0,0 +1,524 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DatKorso/Merge-excel/internal/config"
+	"github.com/DatKorso/Merge-excel/internal/core"
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/excel"
+	"github.com/DatKorso/Merge-excel/internal/logger"
+	"github.com/DatKorso/Merge-excel/internal/profiling"
+)
+
+// Коды завершения команды merge
+const (
+	ExitOK      = 0 // Результат сохранен без предупреждений не ниже порога -warning-threshold (или без -strict-warnings)
+	ExitFailure = 1 // Объединение не выполнено или аргументы неверны
+	ExitPartial = 2 // Результат сохранен, но есть предупреждения не ниже порога -warning-threshold
+)
+
+// Статусы объединения в отчете
+const (
+	StatusOK      = "ok"
+	StatusPartial = "partial"
+	StatusFailed  = "failed"
+)
+
+// Report итог объединения, выводимый с --json-output
+type Report struct {
+	Status          string                 `json:"status"`
+	ExitCode        int                    `json:"exit_code"`
+	Error           string                 `json:"error,omitempty"`      // Сообщение об ошибке на языке -lang
+	ErrorCode       string                 `json:"error_code,omitempty"` // Код ошибки приложения (E001 и т.д.)
+	Output          string                 `json:"output,omitempty"`
+	RunID           string                 `json:"run_id,omitempty"`
+	ProcessedFiles  int                    `json:"processed_files"`
+	ProcessedSheets int                    `json:"processed_sheets"`
+	TotalRows       int                    `json:"total_rows"`
+	DurationMs      int64                  `json:"duration_ms"`
+	Sheets          map[string]SheetReport `json:"sheets,omitempty"`
+	WarningCounts   map[string]int         `json:"warning_counts"`
+	Warnings        []WarningReport        `json:"warnings"`
+	Estimate        *EstimateReport        `json:"estimate,omitempty"`
+	InputFiles      []FileHashReport       `json:"input_files,omitempty"`
+	OutputFile      *FileHashReport        `json:"output_file,omitempty"`
+	DuplicateFiles  []DuplicateFileReport  `json:"duplicate_files,omitempty"`
+	Profile         *profiling.Stats       `json:"profile,omitempty"` // Только при включенном профилировании
+	Log             []string               `json:"log,omitempty"`     // Журнал запуска, только при ошибке объединения
+}
+
+// EstimateReport прогноз объема объединения, вычисленный до его начала
+type EstimateReport struct {
+	Rows            int64 `json:"rows"`
+	Cells           int64 `json:"cells"`
+	OutputBytes     int64 `json:"output_bytes"`
+	PeakMemoryBytes int64 `json:"peak_memory_bytes"`
+}
+
+// SheetReport статистика листа результата в отчете
+type SheetReport struct {
+	RowsMerged  int                `json:"rows_merged"`
+	FilesCount  int                `json:"files_count"`
+	GroupColumn string             `json:"group_column,omitempty"`
+	Values      []ValueCountReport `json:"values,omitempty"` // Строки по значениям столбца group_column
+}
+
+// ValueCountReport количество строк листа с одним значением столбца разбивки
+type ValueCountReport struct {
+	Value string `json:"value"`
+	Rows  int    `json:"rows"`
+}
+
+// FileHashReport отпечаток содержимого прочитанного или сохраненного файла в отчете
+type FileHashReport struct {
+	Path      string `json:"path"`
+	Algorithm string `json:"algorithm"`
+	Hash      string `json:"hash"`
+	Size      int64  `json:"size"`
+}
+
+// newFileHashReport преобразует отпечаток файла для отчета
+func newFileHashReport(hash core.FileHash) FileHashReport {
+	return FileHashReport{Path: hash.Path, Algorithm: hash.Algorithm, Hash: hash.Sum, Size: hash.Size}
+}
+
+// DuplicateFileReport файл с тем же содержимым, что и у файла раньше в списке, в отчете
+type DuplicateFileReport struct {
+	Path     string `json:"path"`
+	Original string `json:"original"`
+	Skipped  bool   `json:"skipped"`
+}
+
+// WarningReport предупреждение объединения в отчете
+type WarningReport struct {
+	Severity string                `json:"severity"`
+	Message  string                `json:"message"`
+	Samples  []core.ExcludedSample `json:"samples,omitempty"` // Примеры строк, исключенных фильтром
+}
+
+// mergeOptions аргументы команды merge
+type mergeOptions struct {
+	profilePath string
+	basePath    string
+	outputPath  string
+	files       []string
+	profiling   profiling.Config
+	language    string // Язык сообщений об ошибках
+
+	// Код ExitPartial при предупреждениях не ниже warningThreshold; без strictWarnings сохраненный результат - ExitOK
+	strictWarnings   bool
+	warningThreshold core.Severity
+}
+
+// hiddenFlags служебные флаги, не выводимые в справке
+var hiddenFlags = map[string]bool{"cpuprofile": true, "memprofile": true}
+
+// RunMerge выполняет команду merge: объединяет файлы по профилю без GUI и сохраняет результат
+// args - аргументы после имени команды. Журнал всегда пишется в stderr; итог выводится
+// в stdout в формате JSON (--json-output) или в stderr в текстовом виде.
+// Возвращает код завершения: ExitOK, ExitPartial или ExitFailure
+func RunMerge(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	profilePath := flags.String("profile", "", "файл профиля (.json)")
+	basePath := flags.String("base", "", "базовый файл (.xlsx)")
+	outputPath := flags.String("output", "", "файл результата (.xlsx)")
+	jsonOutput := flags.Bool("json-output", false, "вывести итог в stdout в формате JSON")
+	strictWarnings := flags.Bool("strict-warnings", false, "завершаться с кодом 2, если есть предупреждения не ниже -warning-threshold")
+	warningThreshold := flags.String("warning-threshold", core.SeverityWarning.String(), "наименьший уровень предупреждений для кода 2 с -strict-warnings: info, warning или error")
+	language := flags.String("lang", config.SettingsLanguage(), "язык сообщений об ошибках: ru или en (по умолчанию - из настроек приложения)")
+
+	// Профилирование для диагностики медленных объединений; по умолчанию из переменных окружения
+	profilingCfg := profiling.FromEnv(filepath.Dir(logger.DefaultConfig().LogFile))
+	flags.StringVar(&profilingCfg.CPUProfile, "cpuprofile", profilingCfg.CPUProfile, "файл CPU-профиля")
+	flags.StringVar(&profilingCfg.MemProfile, "memprofile", profilingCfg.MemProfile, "файл профиля памяти")
+
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Использование: excel-merger merge -profile профиль.json -base база.xlsx -output результат.xlsx [-json-output] [-strict-warnings] [-warning-threshold уровень] файл.xlsx|шаблон...")
+		printVisibleDefaults(flags, stderr)
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitFailure
+	}
+	threshold, err := core.ParseSeverity(*warningThreshold)
+	if err != nil {
+		fmt.Fprintf(stderr, "-warning-threshold: %v\n", err)
+		return ExitFailure
+	}
+
+	logger := slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	report := runMerge(mergeOptions{
+		profilePath: *profilePath,
+		basePath:    *basePath,
+		outputPath:  *outputPath,
+		files:       flags.Args(),
+		profiling:   profilingCfg,
+		language:    apperrors.NormalizeLanguage(*language),
+
+		strictWarnings:   *strictWarnings,
+		warningThreshold: threshold,
+	}, logger)
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "не удалось вывести отчет: %v\n", err)
+			return ExitFailure
+		}
+	} else {
+		printReport(stderr, report, *language)
+	}
+
+	return report.ExitCode
+}
+
+// runMerge объединяет файлы и формирует отчет; ошибки не возвращаются, а попадают в отчет
+func runMerge(opts mergeOptions, logger *slog.Logger) *Report {
+	if err := opts.validate(); err != nil {
+		return failedReport(err, opts.language)
+	}
+	files, err := expandInputFiles(opts.basePath, opts.files)
+	if err != nil {
+		return failedReport(err, opts.language)
+	}
+	if len(files) != len(opts.files) {
+		logger.Info("файлы найдены по шаблонам имен", "args", opts.files, "files_count", len(files))
+	}
+	opts.files = files
+
+	profile, err := config.ReadProfileFile(opts.profilePath)
+	if err != nil {
+		return failedReport(err, opts.language)
+	}
+
+	// Профили снимаются только вокруг объединения и сохранения результата
+	session, err := profiling.Start(opts.profiling, logger)
+	if err != nil {
+		logger.Warn("не удалось включить профилирование", "error", err)
+	}
+
+	report := mergeAndSave(opts, profile, session, logger)
+
+	stats, err := session.Stop()
+	if err != nil {
+		logger.Warn("не удалось сохранить профили объединения", "error", err)
+	}
+	report.Profile = stats
+	return report
+}
+
+// mergeAndSave объединяет файлы по профилю и сохраняет результат
+func mergeAndSave(opts mergeOptions, profile *core.Profile, session *profiling.Session, logger *slog.Logger) *Report {
+	// Создаем конфигурацию для объединения
+	sheetConfigs := make(map[string]*core.SheetConfig)
+	for i := range profile.Sheets {
+		if profile.Sheets[i].Enabled {
+			sheetConfigs[profile.Sheets[i].SheetName] = &profile.Sheets[i]
+		}
+	}
+
+	// Файл с несколькими листами открывается один раз, а не для каждого листа
+	merger := core.NewMerger(nil, logger)
+	merger.SetReaderPool(excel.NewReaderPool(excel.DefaultReaderPoolSize))
+	merger.SetSettings(profile.Settings)
+
+	// Прогноз объема только предупреждает: объединение выполняется в любом случае
+	estimate, estimateErr := merger.EstimateFiles(opts.basePath, opts.files, sheetConfigs)
+	if estimateErr != nil {
+		logger.Warn("не удалось оценить объединение", "error", estimateErr)
+	} else if limit := int64(config.DefaultMergeMemoryLimitMB) << 20; estimate.ExceedsMemory(limit) {
+		logger.Warn("прогноз памяти объединения превышает порог",
+			"estimate", estimate.String(),
+			"limit_bytes", limit,
+		)
+	}
+
+	startTime := time.Now()
+	result, err := merger.MergeFiles(opts.basePath, opts.files, sheetConfigs)
+	if err != nil {
+		// Журнал неудачного запуска - основной источник сведений о причине ошибки
+		report := failedReport(err, opts.language)
+		if result != nil {
+			report.RunID = result.RunID
+			report.Log = result.LogLines
+		}
+		return report
+	}
+	defer result.Close()
+	result.Duration = time.Since(startTime)
+	if estimateErr == nil {
+		result.Estimate = &estimate
+	}
+	session.AddRows(result.TotalRows)
+
+	// Убеждаемся что путь имеет расширение .xlsx
+	outputPath := opts.outputPath
+	if !strings.EqualFold(filepath.Ext(outputPath), ".xlsx") {
+		outputPath += ".xlsx"
+	}
+
+	// Существующий файл заменяется или дополняется только после успешной записи
+	if err := result.SaveWithPolicy(outputPath, profile.Settings.OnExistingOutput); err != nil {
+		return failedReport(err, opts.language)
+	}
+	if info, err := os.Stat(outputPath); err == nil {
+		session.AddBytesWritten(info.Size())
+	}
+
+	logger.Info("результат сохранен",
+		"path", outputPath,
+		"total_rows", result.TotalRows,
+		"processed_files", result.ProcessedFiles,
+	)
+
+	return newReport(result, outputPath, warningsExitCode(result.Warnings, opts.strictWarnings, opts.warningThreshold))
+}
+
+// validate проверяет обязательные аргументы команды merge
+func (o mergeOptions) validate() error {
+	var missing []string
+	if o.profilePath == "" {
+		missing = append(missing, "-profile")
+	}
+	if o.basePath == "" {
+		missing = append(missing, "-base")
+	}
+	if o.outputPath == "" {
+		missing = append(missing, "-output")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("не указаны обязательные параметры: %s", strings.Join(missing, ", "))
+	}
+	if len(o.files) == 0 {
+		return errors.New("не указаны файлы для объединения")
+	}
+	return nil
+}
+
+// expandInputFiles заменяет аргументы с символами шаблона (*, ?, [) найденными по ним файлами .xlsx
+// Например, "exports/2025-11-*.xlsx" - все выгрузки ноября; в cmd.exe шаблоны не раскрываются оболочкой.
+// Пути без шаблона передаются как есть; из найденных по шаблону пропускаются базовый файл и уже указанные.
+// Существующий файл считается путем, даже если в имени есть такие символы: "Отчет [1].xlsx"
+func expandInputFiles(basePath string, args []string) ([]string, error) {
+	seen := map[string]bool{filepath.Clean(basePath): true}
+	var files []string
+	for _, arg := range args {
+		paths := []string{arg}
+		isPattern := core.HasGlobMeta(arg)
+		if _, err := os.Stat(arg); err == nil {
+			isPattern = false
+		}
+		if isPattern {
+			var err error
+			if paths, err = core.ExpandGlob(arg); err != nil {
+				return nil, err
+			}
+		}
+		for _, path := range paths {
+			// Явно указанные пути не отбрасываются: их повторы обрабатывает объединение
+			if isPattern && seen[filepath.Clean(path)] {
+				continue
+			}
+			seen[filepath.Clean(path)] = true
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// newReport формирует отчет по результату объединения с кодом завершения exitCode
+// Итог частичный, если код завершения - ExitPartial
+func newReport(result *core.MergeResult, outputPath string, exitCode int) *Report {
+	report := &Report{
+		Status:          StatusOK,
+		ExitCode:        ExitOK,
+		Output:          outputPath,
+		RunID:           result.RunID,
+		ProcessedFiles:  result.ProcessedFiles,
+		ProcessedSheets: result.ProcessedSheets,
+		TotalRows:       result.TotalRows,
+		DurationMs:      result.Duration.Milliseconds(),
+		Sheets:          make(map[string]SheetReport, len(result.SheetStats)),
+		WarningCounts:   make(map[string]int, len(result.WarningCounts)),
+		Warnings:        make([]WarningReport, 0, len(result.Warnings)),
+	}
+
+	if estimate := result.Estimate; estimate != nil {
+		report.Estimate = &EstimateReport{
+			Rows:            estimate.Rows,
+			Cells:           estimate.Cells,
+			OutputBytes:     estimate.OutputBytes,
+			PeakMemoryBytes: estimate.PeakMemoryBytes,
+		}
+	}
+	for _, hash := range result.InputHashes {
+		report.InputFiles = append(report.InputFiles, newFileHashReport(hash))
+	}
+	if result.OutputHash != nil {
+		output := newFileHashReport(*result.OutputHash)
+		report.OutputFile = &output
+	}
+	for _, duplicate := range result.DuplicateFiles {
+		report.DuplicateFiles = append(report.DuplicateFiles, DuplicateFileReport{
+			Path:     duplicate.Path,
+			Original: duplicate.Original,
+			Skipped:  duplicate.Skipped,
+		})
+	}
+	for name, stat := range result.SheetStats {
+		sheet := SheetReport{RowsMerged: stat.RowsMerged, FilesCount: stat.FilesCount, GroupColumn: stat.GroupColumn}
+		for _, value := range stat.ValueCounts {
+			sheet.Values = append(sheet.Values, ValueCountReport{Value: value.Value, Rows: value.Count})
+		}
+		report.Sheets[name] = sheet
+	}
+	for severity, count := range result.WarningCounts {
+		report.WarningCounts[severity.String()] = count
+	}
+	for _, warning := range result.Warnings {
+		report.Warnings = append(report.Warnings, WarningReport{
+			Severity: warning.Severity.String(),
+			Message:  warning.Message,
+			Samples:  warning.Samples,
+		})
+	}
+
+	report.ExitCode = exitCode
+	if exitCode == ExitPartial {
+		report.Status = StatusPartial
+	}
+	return report
+}
+
+// warningsExitCode возвращает код завершения сохраненного результата: ExitPartial, если в режиме strict
+// есть предупреждение с важностью не ниже threshold, иначе ExitOK
+func warningsExitCode(warnings []core.Warning, strict bool, threshold core.Severity) int {
+	if !strict {
+		return ExitOK
+	}
+	for _, warning := range warnings {
+		if warning.Severity >= threshold {
+			return ExitPartial
+		}
+	}
+	return ExitOK
+}
+
+// printVisibleDefaults выводит описание флагов без служебных hiddenFlags
+func printVisibleDefaults(flags *flag.FlagSet, w io.Writer) {
+	visible := flag.NewFlagSet(flags.Name(), flag.ContinueOnError)
+	visible.SetOutput(w)
+	flags.VisitAll(func(f *flag.Flag) {
+		if !hiddenFlags[f.Name] {
+			visible.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	visible.PrintDefaults()
+}
+
+// failedReport формирует отчет о неудачном объединении
+func failedReport(err error, lang string) *Report {
+	report := &Report{
+		Status:        StatusFailed,
+		ExitCode:      ExitFailure,
+		Error:         err.Error(),
+		WarningCounts: map[string]int{},
+		Warnings:      []WarningReport{},
+	}
+
+	// Ошибка приложения описывается сообщением из каталога на языке lang, как в GUI
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		report.Error = apperrors.LocalizedMessage(appErr, lang)
+		report.ErrorCode = appErr.Code
+	}
+	return report
+}
+
+// reportText подписи текстового итога объединения на одном языке
+type reportText struct {
+	failed     string
+	saved      string
+	totals     string
+	estimate   string
+	warnings   string
+	profiling  string
+	cpuProfile string
+	memProfile string
+}
+
+// reportTexts подписи текстового итога по языкам сообщений об ошибках
+var reportTexts = map[string]reportText{
+	apperrors.LangRussian: {
+		failed:     "Ошибка: %s\n",
+		saved:      "Результат сохранен: %s\n",
+		totals:     "Файлов: %d, листов: %d, строк: %d, время: %s\n",
+		estimate:   "Прогноз перед объединением: результат ≈ %s, память ≈ %s\n",
+		warnings:   "Предупреждения (%d):\n",
+		profiling:  "Профилирование: строк %d, записано байт %d, куча %d байт\n",
+		cpuProfile: "  CPU-профиль: %s\n",
+		memProfile: "  Профиль памяти: %s\n",
+	},
+	apperrors.LangEnglish: {
+		failed:     "Error: %s\n",
+		saved:      "Result saved: %s\n",
+		totals:     "Files: %d, sheets: %d, rows: %d, time: %s\n",
+		estimate:   "Estimate before merge: result ≈ %s, memory ≈ %s\n",
+		warnings:   "Warnings (%d):\n",
+		profiling:  "Profiling: rows %d, bytes written %d, heap %d bytes\n",
+		cpuProfile: "  CPU profile: %s\n",
+		memProfile: "  Memory profile: %s\n",
+	},
+}
+
+// printReport выводит итог объединения в текстовом виде на языке lang
+func printReport(w io.Writer, report *Report, lang string) {
+	text := reportTexts[apperrors.NormalizeLanguage(lang)]
+	if report.Status == StatusFailed {
+		fmt.Fprintf(w, text.failed, report.Error)
+		return
+	}
+
+	fmt.Fprintf(w, text.saved, report.Output)
+	fmt.Fprintf(w, text.totals,
+		report.ProcessedFiles, report.ProcessedSheets, report.TotalRows,
+		time.Duration(report.DurationMs)*time.Millisecond)
+
+	if estimate := report.Estimate; estimate != nil {
+		fmt.Fprintf(w, text.estimate,
+			core.FormatSize(estimate.OutputBytes), core.FormatSize(estimate.PeakMemoryBytes))
+	}
+
+	if len(report.Warnings) > 0 {
+		fmt.Fprintf(w, text.warnings, len(report.Warnings))
+		for _, warning := range report.Warnings {
+			fmt.Fprintf(w, "  [%s] %s\n", warning.Severity, warning.Message)
+		}
+	}
+
+	if stats := report.Profile; stats != nil {
+		fmt.Fprintf(w, text.profiling,
+			stats.RowsProcessed, stats.BytesWritten, stats.HeapInUse)
+		if stats.CPUProfile != "" {
+			fmt.Fprintf(w, text.cpuProfile, stats.CPUProfile)
+		}
+		if stats.MemProfile != "" {
+			fmt.Fprintf(w, text.memProfile, stats.MemProfile)
+		}
+	}
+}
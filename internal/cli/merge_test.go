@@ -0,0 +1,449 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/core"
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+	"github.com/DatKorso/Merge-excel/internal/profiling"
+)
+
+// writeTestProfile сохраняет профиль с включенным листом Data
+func writeTestProfile(t *testing.T, path string) {
+	t.Helper()
+	profile := core.NewProfile("CLI")
+	profile.BaseFileName = "base.xlsx"
+	profile.AddSheet(core.SheetConfig{SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1})
+
+	data, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatalf("не удалось сериализовать профиль: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("не удалось сохранить профиль: %v", err)
+	}
+}
+
+// TestRunMergeJSONOutput тестирует JSON-отчет и код завершения для каждого итога объединения
+func TestRunMergeJSONOutput(t *testing.T) {
+	dir := t.TempDir()
+	profilePath := filepath.Join(dir, "profile.json")
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	otherPath := filepath.Join(dir, "other.xlsx")
+	writeTestProfile(t, profilePath)
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул", "Цена"}, {"A1", "100"}})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{{"Артикул", "Цена"}, {"A2", "200"}})
+	exceltest.WriteWorkbook(t, otherPath, "Заказы", [][]string{{"Артикул", "Цена"}, {"A3", "300"}})
+	// Та же выгрузка, скачанная повторно
+	copyPath := filepath.Join(dir, "source (1).xlsx")
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(copyPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		base         string
+		output       string
+		files        []string
+		wantCode     int
+		wantStatus   string
+		wantRows     int
+		wantWarnings int
+		wantError    string
+		wantCopies   int
+		wantLog      bool // Отчет об ошибке содержит журнал запуска
+	}{
+		{"успех", basePath, "ok.xlsx", []string{sourcePath}, ExitOK, StatusOK, 2, 0, "", 0, false},
+		{"частичный итог", basePath, "partial", []string{sourcePath, otherPath}, ExitPartial, StatusPartial, 2, 1, "", 0, false},
+		{"копия файла", basePath, "copy.xlsx", []string{sourcePath, copyPath}, ExitPartial, StatusPartial, 3, 1, "", 1, false},
+		{"ошибка", filepath.Join(dir, "missing.xlsx"), "failed.xlsx", []string{sourcePath}, ExitFailure, StatusFailed, 0, 0, "Файл не найден", 0, true},
+		{"нет файлов", basePath, "none.xlsx", nil, ExitFailure, StatusFailed, 0, 0, "не указаны файлы", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := filepath.Join(dir, tt.output)
+			args := append([]string{
+				"-profile", profilePath,
+				"-base", tt.base,
+				"-output", outputPath,
+				"-json-output",
+				"-strict-warnings",
+				"-lang", "ru",
+			}, tt.files...)
+
+			var stdout, stderr bytes.Buffer
+			code := RunMerge(args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("код завершения = %d, ожидался %d\nstderr: %s", code, tt.wantCode, stderr.String())
+			}
+
+			var report Report
+			if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+				t.Fatalf("stdout не является JSON: %v\n%s", err, stdout.String())
+			}
+			if report.Status != tt.wantStatus || report.ExitCode != tt.wantCode {
+				t.Errorf("status = %q, exit_code = %d", report.Status, report.ExitCode)
+			}
+			if report.TotalRows != tt.wantRows {
+				t.Errorf("total_rows = %d, ожидалось %d", report.TotalRows, tt.wantRows)
+			}
+			if len(report.Warnings) != tt.wantWarnings {
+				t.Errorf("warnings = %v", report.Warnings)
+			}
+			if !strings.Contains(report.Error, tt.wantError) {
+				t.Errorf("error = %q, ожидалось %q", report.Error, tt.wantError)
+			}
+
+			if tt.wantStatus == StatusFailed {
+				if tt.wantLog && (report.RunID == "" || len(report.Log) == 0) {
+					t.Errorf("в отчете об ошибке нет журнала запуска: run_id = %q, log = %q", report.RunID, report.Log)
+				}
+				return
+			}
+			if len(report.Log) != 0 {
+				t.Errorf("журнал запуска выводится только при ошибке: %q", report.Log)
+			}
+			if !strings.HasSuffix(report.Output, ".xlsx") {
+				t.Errorf("output = %q без расширения .xlsx", report.Output)
+			}
+			info, err := os.Stat(report.Output)
+			if err != nil {
+				t.Fatalf("результат не сохранен: %v", err)
+			}
+			// Отпечатки: базовый файл первым и все открытые источники
+			if len(report.InputFiles) != 1+len(tt.files) || report.InputFiles[0].Path != tt.base {
+				t.Errorf("input_files = %+v", report.InputFiles)
+			}
+			for _, file := range report.InputFiles {
+				if file.Algorithm != "sha256" || len(file.Hash) != 64 || file.Size == 0 {
+					t.Errorf("отпечаток входного файла %+v", file)
+				}
+			}
+			if out := report.OutputFile; out == nil || out.Path != report.Output || len(out.Hash) != 64 || out.Size != info.Size() {
+				t.Errorf("output_file = %+v, размер файла %d", out, info.Size())
+			}
+			// Прогноз считается по всем найденным листам: по строке данных в базовом файле и каждом источнике
+			rows := int64(tt.wantRows)
+			if report.Estimate == nil || report.Estimate.Rows != rows || report.Estimate.Cells != 2*rows || report.Estimate.OutputBytes == 0 {
+				t.Errorf("estimate = %+v", report.Estimate)
+			}
+			if tt.wantWarnings > 0 && report.WarningCounts["warning"] != tt.wantWarnings {
+				t.Errorf("warning_counts = %v", report.WarningCounts)
+			}
+			if len(report.DuplicateFiles) != tt.wantCopies {
+				t.Errorf("duplicate_files = %+v", report.DuplicateFiles)
+			} else if tt.wantCopies > 0 && (report.DuplicateFiles[0].Path != copyPath || report.DuplicateFiles[0].Original != sourcePath) {
+				t.Errorf("duplicate_files = %+v", report.DuplicateFiles)
+			}
+			if stderr.Len() == 0 {
+				t.Error("журнал не выведен в stderr")
+			}
+		})
+	}
+}
+
+// TestRunMergeLocalizedError тестирует сообщение об ошибке приложения на языке -lang
+func TestRunMergeLocalizedError(t *testing.T) {
+	dir := t.TempDir()
+	profilePath := filepath.Join(dir, "profile.json")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	writeTestProfile(t, profilePath)
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{{"Артикул"}, {"A1"}})
+
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"en", "File not found. Please check the file path."},
+		{"ru", "Файл не найден. Пожалуйста, проверьте путь к файлу."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			args := []string{
+				"-profile", profilePath,
+				"-base", filepath.Join(dir, "missing.xlsx"),
+				"-output", filepath.Join(dir, "out.xlsx"),
+				"-json-output",
+				"-lang", tt.lang,
+				sourcePath,
+			}
+			var stdout, stderr bytes.Buffer
+			if code := RunMerge(args, &stdout, &stderr); code != ExitFailure {
+				t.Fatalf("код завершения = %d, ожидался %d", code, ExitFailure)
+			}
+
+			var report Report
+			if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+				t.Fatalf("stdout не является JSON: %v\n%s", err, stdout.String())
+			}
+			if !strings.HasPrefix(report.Error, tt.want) || report.ErrorCode != "E001" {
+				t.Errorf("error = %q, error_code = %q; ожидалось %q, E001", report.Error, report.ErrorCode, tt.want)
+			}
+		})
+	}
+}
+
+// TestPrintReportLocalized тестирует текстовый итог на языке сообщений
+func TestPrintReportLocalized(t *testing.T) {
+	saved := &Report{Status: StatusPartial, Output: "out.xlsx", ProcessedFiles: 2, ProcessedSheets: 1, TotalRows: 3,
+		Warnings: []WarningReport{{Severity: "warning", Message: "лист 'Data': нет строк"}}}
+	failed := &Report{Status: StatusFailed, Error: "File not found."}
+
+	tests := []struct {
+		name   string
+		report *Report
+		lang   string
+		want   []string
+	}{
+		{"результат по-английски", saved, "en", []string{"Result saved: out.xlsx", "Files: 2, sheets: 1, rows: 3", "Warnings (1):"}},
+		{"результат по-русски", saved, "ru", []string{"Результат сохранен: out.xlsx", "Файлов: 2, листов: 1, строк: 3", "Предупреждения (1):"}},
+		{"ошибка по-английски", failed, "en", []string{"Error: File not found."}},
+		{"неподдерживаемый язык", failed, "de", []string{"Ошибка: File not found."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			printReport(&out, tt.report, tt.lang)
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("итог не содержит %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+// TestRunMergeTextOutput тестирует текстовый итог в stderr без --json-output
+func TestRunMergeTextOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := RunMerge([]string{"-base", "base.xlsx", "source.xlsx"}, &stdout, &stderr)
+	if code != ExitFailure {
+		t.Errorf("код завершения = %d, ожидался %d", code, ExitFailure)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout должен быть пустым: %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "-profile, -output") {
+		t.Errorf("stderr не содержит списка недостающих параметров: %q", stderr.String())
+	}
+}
+
+// TestRunMergeProfiling тестирует создание файлов профилей по переменным окружения
+func TestRunMergeProfiling(t *testing.T) {
+	dir := t.TempDir()
+	profilePath := filepath.Join(dir, "profile.json")
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	writeTestProfile(t, profilePath)
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул", "Цена"}, {"A1", "100"}})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{{"Артикул", "Цена"}, {"A2", "200"}})
+
+	cpuPath := filepath.Join(dir, "profiles", "cpu.pprof")
+	memPath := filepath.Join(dir, "profiles", "mem.pprof")
+	t.Setenv(profiling.CPUProfileEnv, cpuPath)
+	t.Setenv(profiling.MemProfileEnv, memPath)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-profile", profilePath, "-base", basePath, "-output", filepath.Join(dir, "result.xlsx"), "-json-output", sourcePath}
+	if code := RunMerge(args, &stdout, &stderr); code != ExitOK {
+		t.Fatalf("код завершения = %d\nstderr: %s", code, stderr.String())
+	}
+
+	var report Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("stdout не является JSON: %v", err)
+	}
+	if report.Profile == nil {
+		t.Fatal("в отчете нет сведений профилирования")
+	}
+	if report.Profile.RowsProcessed != 2 || report.Profile.BytesWritten == 0 || report.Profile.HeapInUse == 0 {
+		t.Errorf("сведения профилирования = %+v", report.Profile)
+	}
+	if report.Profile.CPUProfile != cpuPath || report.Profile.MemProfile != memPath {
+		t.Errorf("пути профилей = %q, %q", report.Profile.CPUProfile, report.Profile.MemProfile)
+	}
+	for _, path := range []string{cpuPath, memPath} {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("файл профиля %s не создан: %v", path, err)
+		}
+	}
+	if !strings.Contains(stderr.String(), cpuPath) {
+		t.Error("путь CPU-профиля не записан в журнал")
+	}
+
+	// Служебные флаги не показываются в справке
+	stderr.Reset()
+	RunMerge([]string{"-h"}, &stdout, &stderr)
+	if strings.Contains(stderr.String(), "cpuprofile") || !strings.Contains(stderr.String(), "json-output") {
+		t.Errorf("справка: %q", stderr.String())
+	}
+}
+
+// TestWarningsExitCode тестирует код завершения сохраненного результата по предупреждениям и порогу
+func TestWarningsExitCode(t *testing.T) {
+	info := core.Warning{Severity: core.SeverityInfo, Message: "сведения"}
+	warning := core.Warning{Severity: core.SeverityWarning, Message: "предупреждение"}
+	skipped := core.Warning{Severity: core.SeverityError, Message: "файл пропущен"}
+
+	tests := []struct {
+		name      string
+		warnings  []core.Warning
+		strict    bool
+		threshold core.Severity
+		want      int
+	}{
+		{"нет предупреждений", nil, true, core.SeverityInfo, ExitOK},
+		{"пустой список", []core.Warning{}, true, core.SeverityError, ExitOK},
+		{"сведения при пороге info", []core.Warning{info}, true, core.SeverityInfo, ExitPartial},
+		{"сведения ниже порога", []core.Warning{info}, true, core.SeverityWarning, ExitOK},
+		{"предупреждение на пороге", []core.Warning{info, warning}, true, core.SeverityWarning, ExitPartial},
+		{"предупреждение ниже порога error", []core.Warning{info, warning}, true, core.SeverityError, ExitOK},
+		{"пропуск файла при пороге error", []core.Warning{warning, skipped}, true, core.SeverityError, ExitPartial},
+		{"пропуск файла выше порога", []core.Warning{skipped}, true, core.SeverityInfo, ExitPartial},
+		{"без строгого режима", []core.Warning{skipped}, false, core.SeverityInfo, ExitOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := warningsExitCode(tt.warnings, tt.strict, tt.threshold); got != tt.want {
+				t.Errorf("warningsExitCode() = %d, ожидалось %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunMergeWarningThreshold тестирует флаги -strict-warnings и -warning-threshold:
+// результат и отчет сохраняются при любом коде завершения
+func TestRunMergeWarningThreshold(t *testing.T) {
+	dir := t.TempDir()
+	profilePath := filepath.Join(dir, "profile.json")
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	otherPath := filepath.Join(dir, "other.xlsx")
+	writeTestProfile(t, profilePath)
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул", "Цена"}, {"A1", "100"}})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{{"Артикул", "Цена"}, {"A2", "200"}})
+	// В файле нет листа Data: предупреждение уровня warning
+	exceltest.WriteWorkbook(t, otherPath, "Заказы", [][]string{{"Артикул", "Цена"}, {"A3", "300"}})
+
+	tests := []struct {
+		name     string
+		flags    []string
+		wantCode int
+	}{
+		{"по умолчанию", nil, ExitOK},
+		{"строгий режим", []string{"-strict-warnings"}, ExitPartial},
+		{"порог info", []string{"-strict-warnings", "-warning-threshold", "info"}, ExitPartial},
+		{"ниже порога error", []string{"-strict-warnings", "-warning-threshold", "error"}, ExitOK},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := filepath.Join(dir, fmt.Sprintf("result%d.xlsx", i))
+			args := append(append([]string{
+				"-profile", profilePath,
+				"-base", basePath,
+				"-output", outputPath,
+				"-json-output",
+			}, tt.flags...), sourcePath, otherPath)
+
+			var stdout, stderr bytes.Buffer
+			code := RunMerge(args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("код завершения = %d, ожидался %d\nstderr: %s", code, tt.wantCode, stderr.String())
+			}
+
+			var report Report
+			if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+				t.Fatalf("stdout не является JSON: %v\n%s", err, stdout.String())
+			}
+			// Статус и код завершения определяются одним решением
+			wantStatus := StatusOK
+			if tt.wantCode == ExitPartial {
+				wantStatus = StatusPartial
+			}
+			if report.ExitCode != tt.wantCode || report.Status != wantStatus || len(report.Warnings) != 1 {
+				t.Errorf("status = %q, exit_code = %d, warnings = %v", report.Status, report.ExitCode, report.Warnings)
+			}
+			if _, err := os.Stat(outputPath); err != nil {
+				t.Errorf("результат не сохранен: %v", err)
+			}
+		})
+	}
+
+	// Файл только с шапкой дает лишь сведения: ниже порога warning по умолчанию
+	emptyPath := filepath.Join(dir, "empty.xlsx")
+	exceltest.WriteWorkbook(t, emptyPath, "Data", [][]string{{"Артикул", "Цена"}})
+	var infoOut, infoErr bytes.Buffer
+	infoArgs := []string{"-profile", profilePath, "-base", basePath, "-output", filepath.Join(dir, "info.xlsx"), "-json-output", "-strict-warnings", sourcePath, emptyPath}
+	if code := RunMerge(infoArgs, &infoOut, &infoErr); code != ExitOK {
+		t.Errorf("код завершения только со сведениями = %d, ожидался %d\nstderr: %s", code, ExitOK, infoErr.String())
+	}
+	var infoReport Report
+	if err := json.Unmarshal(infoOut.Bytes(), &infoReport); err != nil {
+		t.Fatalf("stdout не является JSON: %v\n%s", err, infoOut.String())
+	}
+	if infoReport.Status != StatusOK || infoReport.ExitCode != ExitOK || len(infoReport.Warnings) != 1 || infoReport.Warnings[0].Severity != core.SeverityInfo.String() {
+		t.Errorf("status = %q, exit_code = %d, warnings = %v", infoReport.Status, infoReport.ExitCode, infoReport.Warnings)
+	}
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-profile", profilePath, "-base", basePath, "-output", filepath.Join(dir, "bad.xlsx"), "-warning-threshold", "critical", sourcePath}
+	if code := RunMerge(args, &stdout, &stderr); code != ExitFailure {
+		t.Errorf("код завершения с неверным порогом = %d, ожидался %d", code, ExitFailure)
+	}
+	if !strings.Contains(stderr.String(), "critical") {
+		t.Errorf("stderr не содержит неверного уровня: %q", stderr.String())
+	}
+}
+
+// TestExpandInputFiles тестирует раскрытие шаблонов имен в аргументах команды merge
+func TestExpandInputFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"base.xlsx", "2025-11-01.xlsx", "2025-11-02.xlsx", "~$2025-11-02.xlsx", "Отчет [1].xlsx"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	basePath := filepath.Join(dir, "base.xlsx")
+	first := filepath.Join(dir, "2025-11-01.xlsx")
+	second := filepath.Join(dir, "2025-11-02.xlsx")
+	bracketed := filepath.Join(dir, "Отчет [1].xlsx")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{"пути без шаблона", []string{second, "missing.xlsx"}, []string{second, "missing.xlsx"}, false},
+		{"шаблон", []string{filepath.Join(dir, "2025-11-*.xlsx")}, []string{first, second}, false},
+		{"базовый файл и повторы пропускаются", []string{second, filepath.Join(dir, "*.xlsx")}, []string{second, first, bracketed}, false},
+		{"существующий файл со скобками в имени", []string{bracketed}, []string{bracketed}, false},
+		{"шаблон со скобками", []string{filepath.Join(dir, "2025-11-0[1].xlsx")}, []string{first}, false},
+		{"нет совпадений", []string{first, filepath.Join(dir, "2024-*.xlsx")}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := expandInputFiles(basePath, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandInputFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(files) != fmt.Sprint(tt.want) {
+				t.Errorf("expandInputFiles() = %v, ожидалось %v", files, tt.want)
+			}
+		})
+	}
+}
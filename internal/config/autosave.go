@@ -0,0 +1,264 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DatKorso/Merge-excel/internal/core"
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+)
+
+// autosaveFilename файл слота автосохранения в директории профилей
+// Не попадает в список сохраненных профилей и не может быть занят профилем с тем же именем
+const autosaveFilename = "_autosave.json"
+
+// legacyAutosaveFilename слот автосохранения прежних версий в директории конфигурации
+// Читается, пока не записан новый слот, и удаляется вместе с ним
+const legacyAutosaveFilename = "autosave.json"
+
+// DefaultAutosaveDelay задержка автосохранения после последнего изменения профиля
+const DefaultAutosaveDelay = 2 * time.Second
+
+// autosavePath возвращает путь к слоту автосохранения
+func (m *Manager) autosavePath() string {
+	return filepath.Join(m.profilesDir, autosaveFilename)
+}
+
+// legacyAutosavePath возвращает путь к слоту автосохранения прежних версий
+func (m *Manager) legacyAutosavePath() string {
+	return filepath.Join(m.configDir, legacyAutosaveFilename)
+}
+
+// isAutosaveFile сообщает, является ли файл name директории профилей слотом автосохранения
+func isAutosaveFile(name string) bool {
+	return strings.EqualFold(name, autosaveFilename)
+}
+
+// SaveAutosave сохраняет профиль в слот автосохранения
+// Профиль не валидируется: автосохранение хранит и незаконченные настройки
+func (m *Manager) SaveAutosave(profile *core.Profile) error {
+	if profile == nil {
+		return fmt.Errorf("профиль не может быть nil")
+	}
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать профиль: %w", err)
+	}
+	return m.writeAutosave(data)
+}
+
+// writeAutosave атомарно записывает сериализованный профиль в слот автосохранения:
+// данные пишутся во временный файл рядом со слотом и заменяют его переименованием,
+// поэтому сбой во время записи не портит предыдущее автосохранение
+func (m *Manager) writeAutosave(data []byte) error {
+	path := m.autosavePath()
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".autosave-*.tmp")
+	if err != nil {
+		return fmt.Errorf("не удалось записать автосохранение профиля: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("не удалось записать автосохранение профиля: %w", err)
+	}
+
+	m.logger.Debug("профиль автосохранен", "file", path)
+	return nil
+}
+
+// LoadAutosave загружает профиль из слота автосохранения
+// Возвращает nil без ошибки, если автосохранения нет
+func (m *Manager) LoadAutosave() (*core.Profile, error) {
+	data, err := os.ReadFile(m.autosavePath())
+	if os.IsNotExist(err) {
+		data, err = os.ReadFile(m.legacyAutosavePath())
+	}
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать автосохранение профиля: %w", err)
+	}
+
+	var profile core.Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("не удалось десериализовать автосохранение профиля: %w", err)
+	}
+
+	m.logger.Info("найдено автосохранение профиля",
+		"profile", profile.ProfileName,
+		"updated_at", profile.UpdatedAt,
+	)
+
+	return &profile, nil
+}
+
+// ClearAutosave удаляет слот автосохранения, например после явного сохранения профиля
+func (m *Manager) ClearAutosave() error {
+	for _, path := range []string{m.autosavePath(), m.legacyAutosavePath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("не удалось удалить автосохранение профиля: %w", err)
+		}
+	}
+	return nil
+}
+
+// autosaveTimer отложенный вызов автосохранения
+type autosaveTimer interface {
+	Stop() bool
+}
+
+// autosaveClock источник таймеров автосохранения; в тестах подменяется управляемыми часами
+type autosaveClock interface {
+	AfterFunc(d time.Duration, f func()) autosaveTimer
+}
+
+// systemClock таймеры пакета time
+type systemClock struct{}
+
+// AfterFunc вызывает f в отдельной горутине через d
+func (systemClock) AfterFunc(d time.Duration, f func()) autosaveTimer {
+	return time.AfterFunc(d, f)
+}
+
+// Autosaver откладывает автосохранение профиля: серия изменений за время delay
+// приводит к одной записи последнего состояния
+// Пока открыто окно сохранения профиля (Suspend), запись откладывается до Resume
+type Autosaver struct {
+	manager *Manager
+	delay   time.Duration
+	logger  *slog.Logger
+	clock   autosaveClock
+
+	mu        sync.Mutex
+	timer     autosaveTimer
+	pending   []byte // Последнее несохраненное состояние профиля
+	suspended int    // Число незавершенных Suspend
+}
+
+// NewAutosaver создает отложенное автосохранение профилей в слот manager
+func NewAutosaver(manager *Manager, delay time.Duration) *Autosaver {
+	return &Autosaver{
+		manager: manager,
+		delay:   delay,
+		logger:  manager.logger,
+		clock:   systemClock{},
+	}
+}
+
+// Schedule запоминает состояние профиля и откладывает запись на delay после последнего вызова
+// Профиль сериализуется сразу, поэтому дальнейшие изменения не попадают в отложенную запись
+func (a *Autosaver) Schedule(profile *core.Profile) {
+	if profile == nil {
+		return
+	}
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		a.logger.Warn("не удалось сериализовать профиль для автосохранения", "error", err)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.pending = data
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.timer = a.clock.AfterFunc(a.delay, a.save)
+}
+
+// Suspend приостанавливает автосохранение, например на время окна сохранения профиля,
+// чтобы запись слота не совпала с явным сохранением. Изменения продолжают накапливаться
+func (a *Autosaver) Suspend() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.suspended++
+}
+
+// Resume возобновляет автосохранение после Suspend; накопленное состояние
+// записывается через delay, если его не отменило явное сохранение
+func (a *Autosaver) Resume() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.suspended == 0 {
+		return
+	}
+	a.suspended--
+	if a.suspended == 0 && a.pending != nil && a.timer == nil {
+		a.timer = a.clock.AfterFunc(a.delay, a.save)
+	}
+}
+
+// Flush немедленно записывает отложенное состояние, например при закрытии приложения
+// Записывает и во время Suspend: при закрытии изменения важнее совпадения с сохранением
+func (a *Autosaver) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	return a.writePending()
+}
+
+// Cancel отменяет отложенную запись, например после явного сохранения профиля
+func (a *Autosaver) Cancel() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	a.pending = nil
+}
+
+// save записывает отложенное состояние по срабатыванию таймера
+func (a *Autosaver) save() {
+	defer apperrors.Recover(a.logger, "автосохранение профиля", nil)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.timer = nil
+	// Запись во время явного сохранения откладывается до Resume
+	if a.suspended > 0 {
+		return
+	}
+	if err := a.writePending(); err != nil {
+		a.logger.Warn("не удалось автосохранить профиль", "error", err)
+	}
+}
+
+// writePending записывает отложенное состояние; вызывается под a.mu
+func (a *Autosaver) writePending() error {
+	if a.pending == nil {
+		return nil
+	}
+
+	data := a.pending
+	a.pending = nil
+	return a.manager.writeAutosave(data)
+}
@@ -0,0 +1,336 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DatKorso/Merge-excel/internal/core"
+)
+
+// TestAutosaveSlot тестирует сохранение, восстановление и удаление слота автосохранения
+func TestAutosaveSlot(t *testing.T) {
+	manager := newTestManager(t)
+
+	profile, err := manager.LoadAutosave()
+	if err != nil || profile != nil {
+		t.Fatalf("LoadAutosave() без автосохранения = %v, %v; ожидалось nil, nil", profile, err)
+	}
+
+	// Незаконченный профиль без базового файла тоже сохраняется
+	draft := core.NewProfile("Черновик")
+	draft.Sheets = []core.SheetConfig{{SheetName: "Data", Enabled: true, HeaderRow: 3}}
+	if err := manager.SaveAutosave(draft); err != nil {
+		t.Fatalf("SaveAutosave() error = %v", err)
+	}
+
+	restored, err := manager.LoadAutosave()
+	if err != nil {
+		t.Fatalf("LoadAutosave() error = %v", err)
+	}
+	if restored == nil || restored.ProfileName != "Черновик" || len(restored.Sheets) != 1 || restored.Sheets[0].HeaderRow != 3 {
+		t.Errorf("восстановлен профиль %+v", restored)
+	}
+
+	// Слот не попадает в список сохраненных профилей
+	if profiles, err := manager.ListProfiles(); err != nil || len(profiles) != 0 {
+		t.Errorf("ListProfiles() = %v, %v; автосохранение не должно быть в списке", profiles, err)
+	}
+
+	if err := manager.ClearAutosave(); err != nil {
+		t.Fatalf("ClearAutosave() error = %v", err)
+	}
+	if profile, _ := manager.LoadAutosave(); profile != nil {
+		t.Errorf("после ClearAutosave() найден профиль %v", profile)
+	}
+	if err := manager.ClearAutosave(); err != nil {
+		t.Errorf("повторный ClearAutosave() error = %v", err)
+	}
+
+	if err := os.WriteFile(manager.autosavePath(), []byte("{broken"), 0644); err != nil {
+		t.Fatalf("не удалось записать файл: %v", err)
+	}
+	if _, err := manager.LoadAutosave(); err == nil {
+		t.Error("ожидалась ошибка для поврежденного автосохранения")
+	}
+}
+
+// TestAutosaverDebounce тестирует отложенную запись последнего состояния профиля
+func TestAutosaverDebounce(t *testing.T) {
+	const delay = 50 * time.Millisecond
+
+	t.Run("серия изменений записывается один раз", func(t *testing.T) {
+		manager := newTestManager(t)
+		autosaver := NewAutosaver(manager, delay)
+
+		profile := core.NewProfile("Первое")
+		autosaver.Schedule(profile)
+		profile.ProfileName = "Второе"
+		autosaver.Schedule(profile)
+
+		// Изменение после Schedule не попадает в запись
+		profile.ProfileName = "Не сохранено"
+
+		if _, err := os.Stat(manager.autosavePath()); !os.IsNotExist(err) {
+			t.Fatalf("автосохранение записано до истечения задержки: %v", err)
+		}
+
+		waitForAutosave(t, manager, "Второе")
+	})
+
+	t.Run("Flush записывает сразу", func(t *testing.T) {
+		manager := newTestManager(t)
+		autosaver := NewAutosaver(manager, time.Hour)
+
+		autosaver.Schedule(core.NewProfile("При закрытии"))
+		if err := autosaver.Flush(); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+		if profile, _ := manager.LoadAutosave(); profile == nil || profile.ProfileName != "При закрытии" {
+			t.Errorf("после Flush() восстановлен профиль %v", profile)
+		}
+		if err := autosaver.Flush(); err != nil {
+			t.Errorf("повторный Flush() error = %v", err)
+		}
+	})
+
+	t.Run("Cancel отменяет запись", func(t *testing.T) {
+		manager := newTestManager(t)
+		autosaver := NewAutosaver(manager, delay)
+
+		autosaver.Schedule(core.NewProfile("Отменено"))
+		autosaver.Cancel()
+
+		time.Sleep(3 * delay)
+		if profile, _ := manager.LoadAutosave(); profile != nil {
+			t.Errorf("после Cancel() записан профиль %v", profile)
+		}
+	})
+}
+
+// waitForAutosave ждет появления в слоте автосохранения профиля с именем name
+func waitForAutosave(t *testing.T, manager *Manager, name string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if profile, err := manager.LoadAutosave(); err == nil && profile != nil {
+			if profile.ProfileName != name {
+				t.Fatalf("автосохранен профиль %q, ожидался %q", profile.ProfileName, name)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("профиль %q не автосохранен", name)
+}
+
+// TestAutosaveSettingDefault тестирует, что автосохранение включено и в настройках прежних версий
+func TestAutosaveSettingDefault(t *testing.T) {
+	manager := newTestManager(t)
+	if err := os.WriteFile(filepath.Join(manager.configDir, "settings.json"), []byte(`{"use_ozon_template": false}`), 0644); err != nil {
+		t.Fatalf("не удалось записать настройки: %v", err)
+	}
+
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings() error = %v", err)
+	}
+	if !settings.AutosaveProfile {
+		t.Error("AutosaveProfile = false, ожидалось включенное автосохранение по умолчанию")
+	}
+}
+
+// fakeClock управляемые часы: таймеры срабатывают только при Advance
+type fakeClock struct {
+	now    time.Duration
+	timers []*fakeTimer
+}
+
+// fakeTimer таймер fakeClock
+type fakeTimer struct {
+	at      time.Duration
+	f       func()
+	stopped bool
+}
+
+// Stop отменяет таймер; возвращает false, если он уже сработал или отменен
+func (t *fakeTimer) Stop() bool {
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+// AfterFunc запоминает вызов f через d
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) autosaveTimer {
+	timer := &fakeTimer{at: c.now + d, f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Advance переводит часы на d и синхронно вызывает наступившие таймеры
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now += d
+	for _, timer := range c.timers {
+		if !timer.stopped && timer.at <= c.now {
+			timer.stopped = true
+			timer.f()
+		}
+	}
+}
+
+// newFakeAutosaver создает автосохранение с задержкой delay на управляемых часах
+func newFakeAutosaver(t *testing.T, delay time.Duration) (*Manager, *Autosaver, *fakeClock) {
+	t.Helper()
+	manager := newTestManager(t)
+	autosaver := NewAutosaver(manager, delay)
+	clock := &fakeClock{}
+	autosaver.clock = clock
+	return manager, autosaver, clock
+}
+
+// autosavedName возвращает имя профиля в слоте автосохранения (пусто - слот пуст)
+func autosavedName(t *testing.T, manager *Manager) string {
+	t.Helper()
+	profile, err := manager.LoadAutosave()
+	if err != nil {
+		t.Fatalf("LoadAutosave() error = %v", err)
+	}
+	if profile == nil {
+		return ""
+	}
+	return profile.ProfileName
+}
+
+// TestAutosaverClock тестирует задержку и приостановку автосохранения на управляемых часах
+func TestAutosaverClock(t *testing.T) {
+	const delay = 3 * time.Second
+
+	t.Run("задержка отсчитывается от последнего изменения", func(t *testing.T) {
+		manager, autosaver, clock := newFakeAutosaver(t, delay)
+
+		autosaver.Schedule(core.NewProfile("Первое"))
+		clock.Advance(2 * time.Second)
+		autosaver.Schedule(core.NewProfile("Второе"))
+		clock.Advance(2 * time.Second)
+		if name := autosavedName(t, manager); name != "" {
+			t.Fatalf("записан профиль %q до истечения задержки после последнего изменения", name)
+		}
+
+		clock.Advance(time.Second)
+		if name := autosavedName(t, manager); name != "Второе" {
+			t.Errorf("автосохранен профиль %q, ожидался %q", name, "Второе")
+		}
+	})
+
+	t.Run("запись откладывается на время окна сохранения", func(t *testing.T) {
+		manager, autosaver, clock := newFakeAutosaver(t, delay)
+
+		autosaver.Schedule(core.NewProfile("Изменен"))
+		autosaver.Suspend()
+		clock.Advance(2 * delay)
+		if name := autosavedName(t, manager); name != "" {
+			t.Fatalf("записан профиль %q при открытом окне сохранения", name)
+		}
+
+		// Окно закрыто без сохранения: накопленное состояние записывается через delay
+		autosaver.Resume()
+		if name := autosavedName(t, manager); name != "" {
+			t.Fatalf("профиль %q записан сразу после Resume", name)
+		}
+		clock.Advance(delay)
+		if name := autosavedName(t, manager); name != "Изменен" {
+			t.Errorf("автосохранен профиль %q, ожидался %q", name, "Изменен")
+		}
+	})
+
+	t.Run("явное сохранение отменяет отложенную запись", func(t *testing.T) {
+		manager, autosaver, clock := newFakeAutosaver(t, delay)
+
+		autosaver.Schedule(core.NewProfile("Изменен"))
+		autosaver.Suspend()
+		clock.Advance(2 * delay)
+		autosaver.Cancel()
+		if err := manager.ClearAutosave(); err != nil {
+			t.Fatal(err)
+		}
+		autosaver.Resume()
+		clock.Advance(2 * delay)
+		if name := autosavedName(t, manager); name != "" {
+			t.Errorf("после явного сохранения записан профиль %q", name)
+		}
+
+		// Лишний Resume не ломает счетчик приостановок
+		autosaver.Resume()
+		autosaver.Schedule(core.NewProfile("Снова"))
+		clock.Advance(delay)
+		if name := autosavedName(t, manager); name != "Снова" {
+			t.Errorf("автосохранен профиль %q, ожидался %q", name, "Снова")
+		}
+	})
+
+	t.Run("Flush записывает и при приостановке", func(t *testing.T) {
+		manager, autosaver, _ := newFakeAutosaver(t, delay)
+
+		autosaver.Schedule(core.NewProfile("При закрытии"))
+		autosaver.Suspend()
+		if err := autosaver.Flush(); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+		if name := autosavedName(t, manager); name != "При закрытии" {
+			t.Errorf("автосохранен профиль %q, ожидался %q", name, "При закрытии")
+		}
+	})
+}
+
+// TestAutosaveSlotLocation тестирует расположение слота, запись без временных файлов
+// и чтение слота прежних версий
+func TestAutosaveSlotLocation(t *testing.T) {
+	manager := newTestManager(t)
+
+	// Слот прежних версий читается, пока не записан новый
+	legacy, err := json.Marshal(core.NewProfile("Прежний"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(manager.configDir, "autosave.json"), legacy, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if name := autosavedName(t, manager); name != "Прежний" {
+		t.Errorf("из слота прежних версий восстановлен %q", name)
+	}
+
+	if err := manager.SaveAutosave(core.NewProfile("Новый")); err != nil {
+		t.Fatalf("SaveAutosave() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(manager.profilesDir, "_autosave.json")); err != nil {
+		t.Errorf("слот не записан в директорию профилей: %v", err)
+	}
+	if name := autosavedName(t, manager); name != "Новый" {
+		t.Errorf("восстановлен %q, ожидался %q", name, "Новый")
+	}
+	entries, err := os.ReadDir(manager.profilesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("в директории профилей остались временные файлы: %v", entries)
+	}
+
+	// Имя слота не может занять сохраненный профиль
+	profile := core.NewProfile("Автосохранение")
+	profile.BaseFileName = "base.xlsx"
+	profile.AddSheet(core.SheetConfig{SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1})
+	if err := manager.SaveProfile(profile, "_autosave"); err == nil || !strings.Contains(err.Error(), "зарезервировано") {
+		t.Errorf("SaveProfile() под именем слота автосохранения error = %v", err)
+	}
+
+	if err := manager.ClearAutosave(); err != nil {
+		t.Fatalf("ClearAutosave() error = %v", err)
+	}
+	if name := autosavedName(t, manager); name != "" {
+		t.Errorf("после ClearAutosave() найден профиль %q", name)
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/DatKorso/Merge-excel/internal/core"
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
 )
 
 // Manager управляет профилями конфигурации
@@ -77,6 +80,9 @@ func (m *Manager) SaveProfile(profile *core.Profile, filename string) error {
 
 	// Убираем расширение если оно есть
 	filename = strings.TrimSuffix(filename, ".json")
+	if isAutosaveFile(filepath.Base(filename) + ".json") {
+		return fmt.Errorf("имя профиля '%s' зарезервировано для автосохранения", filename)
+	}
 
 	// Полный путь к файлу
 	filePath := filepath.Join(m.profilesDir, filename+".json")
@@ -114,8 +120,25 @@ func (m *Manager) LoadProfile(filename string) (*core.Profile, error) {
 		return nil, fmt.Errorf("файл профиля не найден: %s", filename)
 	}
 
+	profile, err := ReadProfileFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("профиль загружен",
+		"profile", profile.ProfileName,
+		"file", filePath,
+		"sheets_count", len(profile.Sheets),
+	)
+
+	return profile, nil
+}
+
+// ReadProfileFile читает и валидирует профиль из JSON файла по произвольному пути
+// (например, профиль, переданный в командной строке)
+func ReadProfileFile(path string) (*core.Profile, error) {
 	// Читаем файл
-	data, err := os.ReadFile(filePath)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось прочитать файл профиля: %w", err)
 	}
@@ -131,12 +154,6 @@ func (m *Manager) LoadProfile(filename string) (*core.Profile, error) {
 		return nil, fmt.Errorf("загруженный профиль невалиден: %w", err)
 	}
 
-	m.logger.Info("профиль загружен",
-		"profile", profile.ProfileName,
-		"file", filePath,
-		"sheets_count", len(profile.Sheets),
-	)
-
 	return &profile, nil
 }
 
@@ -154,8 +171,8 @@ func (m *Manager) ListProfiles() ([]ProfileInfo, error) {
 			continue
 		}
 
-		// Проверяем расширение .json
-		if !strings.HasSuffix(entry.Name(), ".json") {
+		// Проверяем расширение .json; слот автосохранения - не сохраненный профиль
+		if !strings.HasSuffix(entry.Name(), ".json") || isAutosaveFile(entry.Name()) {
 			continue
 		}
 
@@ -309,6 +326,76 @@ func (m *Manager) ImportProfile(srcPath string) error {
 	return nil
 }
 
+// ImportProfilesDir импортирует все профили (.json) из указанной директории
+// Невалидные файлы пропускаются, ошибки по ним возвращаются в errs.
+// При совпадении имени с существующим профилем он перезаписывается (overwrite)
+// либо импортируемый профиль сохраняется под новым именем с суффиксом _2, _3...
+func (m *Manager) ImportProfilesDir(dir string, overwrite bool) (imported int, errs []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, []error{fmt.Errorf("не удалось прочитать директорию %s: %w", dir, err)}
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".json") || isAutosaveFile(entry.Name()) {
+			continue
+		}
+
+		srcPath := filepath.Join(dir, entry.Name())
+
+		data, err := os.ReadFile(srcPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: не удалось прочитать файл: %w", entry.Name(), err))
+			continue
+		}
+
+		var profile core.Profile
+		if err := json.Unmarshal(data, &profile); err != nil {
+			errs = append(errs, fmt.Errorf("%s: не удалось десериализовать профиль: %w", entry.Name(), err))
+			continue
+		}
+
+		if err := profile.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: профиль невалиден: %w", entry.Name(), err))
+			continue
+		}
+
+		filename := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if !overwrite {
+			filename = m.uniqueProfileFilename(filename)
+		}
+
+		if err := m.SaveProfile(&profile, filename); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+
+		imported++
+	}
+
+	m.logger.Info("импорт профилей из директории завершен",
+		"dir", dir,
+		"imported", imported,
+		"failed", len(errs),
+	)
+
+	return imported, errs
+}
+
+// uniqueProfileFilename возвращает имя файла профиля, не занятое существующими профилями
+func (m *Manager) uniqueProfileFilename(filename string) string {
+	if !m.ProfileExists(filename) {
+		return filename
+	}
+
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s_%d", filename, i)
+		if !m.ProfileExists(candidate) {
+			return candidate
+		}
+	}
+}
+
 // GetProfilesDir возвращает путь к директории профилей
 func (m *Manager) GetProfilesDir() string {
 	return m.profilesDir
@@ -332,18 +419,230 @@ type ProfileInfo struct {
 	IsCorrupt   bool      // Файл поврежден
 }
 
+// Каналы обновлений (совпадают со значениями updater.ChannelStable/ChannelPrerelease)
+const (
+	UpdateChannelStable     = "stable"
+	UpdateChannelPrerelease = "prerelease"
+)
+
+// Способы уведомления о новой версии (совпадают со значениями updater.NotifyDialog/NotifyBanner/NotifySilent)
+const (
+	UpdateNotificationDialog = "dialog"
+	UpdateNotificationBanner = "banner"
+	UpdateNotificationSilent = "silent"
+)
+
+// Обработка CSV-файлов, перетащенных в список для объединения
+const (
+	DroppedCSVConvert = "convert" // Предложить преобразовать в xlsx (по умолчанию)
+	DroppedCSVSkip    = "skip"    // Пропустить с пояснением в итоге
+)
+
+// DefaultCheckIntervalHours интервал автоматической проверки обновлений по умолчанию
+const DefaultCheckIntervalHours = 24
+
 // AppSettings настройки приложения
 type AppSettings struct {
-	UseOzonTemplate bool `json:"use_ozon_template"` // Использовать шаблон Ozon по умолчанию
-	Version         string `json:"version"`
+	UseOzonTemplate     bool      `json:"use_ozon_template"`     // Использовать шаблон Ozon по умолчанию
+	UpdateChannel       string    `json:"update_channel"`        // Канал обновлений: stable или prerelease
+	CheckUpdates        bool      `json:"check_updates"`         // Автоматически проверять обновления при запуске
+	CheckIntervalHours  int       `json:"check_interval_hours"`  // Интервал между проверками в часах (0 - при каждом запуске)
+	LastCheckedAt       time.Time `json:"last_checked_at"`       // Время последней попытки проверки обновлений
+	UpdateCheckFailures int       `json:"update_check_failures"` // Количество неудачных проверок подряд
+	AllowSelfUpdate     bool      `json:"allow_self_update"`     // Разрешить установку обновлений из приложения
+	UpdateNotification  string    `json:"update_notification"`   // Способ уведомления: dialog, banner или silent
+	SkippedVersion      string    `json:"skipped_version"`       // Версия, о которой пользователь просил не напоминать
+	LogLevel            string    `json:"log_level"`             // Уровень журнала: debug, info, warn или error
+	LogFormat           string    `json:"log_format"`            // Формат журнала: json, text или both
+	RedactLogPaths      bool      `json:"redact_log_paths"`      // Заменять домашний каталог пользователя в журнале на "~"
+	LastOutputPath      string    `json:"last_output_path"`      // Путь к последнему сохраненному результату
+	Language            string    `json:"language"`              // Язык сообщений об ошибках: ru или en
+	Version             string    `json:"version"`
+
+	// Не предупреждать перед объединением LargeMergeFileCount и более файлов
+	SuppressLargeMergeWarning bool `json:"suppress_large_merge_warning"`
+
+	// Порог ожидаемой памяти объединения в МБ, выше которого перед запуском показывается
+	// предупреждение (0 - DefaultMergeMemoryLimitMB, отрицательное значение - без предупреждения)
+	MergeMemoryLimitMB int `json:"merge_memory_limit_mb,omitempty"`
+
+	// Автосохранять текущий профиль при изменениях и предлагать восстановить его при запуске
+	AutosaveProfile bool `json:"autosave_profile"`
+
+	// Наибольшее количество файлов в списке для объединения (0 - без ограничения)
+	// Защищает от случайного выбора сотен файлов, например всей папки загрузок
+	MaxMergeFiles int `json:"max_merge_files"`
+
+	// Что делать с перетащенными CSV-файлами: DroppedCSVConvert или DroppedCSVSkip (пусто - DroppedCSVConvert)
+	DroppedCSV string `json:"dropped_csv,omitempty"`
+
+	// Строка заголовков для листов нового базового файла (0 - первая строка)
+	// Шаблон Ozon задает строку заголовков своих листов сам
+	DefaultHeaderRow int `json:"default_header_row,omitempty"`
+
+	// Источник обновлений (пустые значения - параметры сборки)
+	UpdateAPIBaseURL   string `json:"update_api_base_url,omitempty"`   // Адрес API, например https://github.example.com/api/v3
+	UpdateOwner        string `json:"update_owner,omitempty"`          // Владелец репозитория с релизами
+	UpdateRepo         string `json:"update_repo,omitempty"`           // Имя репозитория с релизами
+	UpdateCABundlePath string `json:"update_ca_bundle_path,omitempty"` // PEM-файл с корневыми сертификатами
+
+	// Дополнительные каталоги, скрываемые в журнале вместе с домашним (при RedactLogPaths)
+	RedactLogRoots []string `json:"redact_log_roots,omitempty"`
+
+	// Собственные бренды для фильтра листа "Шаблон" в шаблоне Ozon (пустой список - без фильтра)
+	OzonBrands []string `json:"ozon_brands"`
+}
+
+// DefaultOzonBrands бренды фильтра шаблона Ozon по умолчанию
+var DefaultOzonBrands = []string{"Shuzzi"}
+
+// NormalizeBrands убирает из списка брендов пробелы по краям, пустые строки и повторы
+// без учета регистра; остается первое написание бренда, порядок сохраняется
+func NormalizeBrands(brands []string) []string {
+	normalized := make([]string, 0, len(brands))
+	seen := make(map[string]bool, len(brands))
+	for _, brand := range brands {
+		brand = strings.TrimSpace(brand)
+		key := strings.ToLower(brand)
+		if brand == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, brand)
+	}
+	return normalized
 }
 
+// LargeMergeFileCount количество файлов, начиная с которого перед объединением
+// показывается предупреждение о длительной обработке
+const LargeMergeFileCount = 5
+
+// DefaultMergeMemoryLimitMB порог ожидаемой памяти объединения по умолчанию:
+// половина памяти распространенного ноутбука на 8 ГБ
+const DefaultMergeMemoryLimitMB = 4096
+
+// DefaultMaxMergeFiles ограничение списка файлов для объединения по умолчанию
+const DefaultMaxMergeFiles = 100
+
 // NewAppSettings создает настройки по умолчанию
 func NewAppSettings() *AppSettings {
 	return &AppSettings{
-		UseOzonTemplate: true, // По умолчанию включен
-		Version:         "1.0",
+		UseOzonTemplate:    true, // По умолчанию включен
+		OzonBrands:         slices.Clone(DefaultOzonBrands),
+		UpdateChannel:      UpdateChannelStable,
+		CheckUpdates:       true,
+		CheckIntervalHours: DefaultCheckIntervalHours,
+		UpdateNotification: UpdateNotificationDialog,
+		LogLevel:           "info",
+		LogFormat:          "both",
+		Language:           apperrors.DefaultLanguage,
+		Version:            "1.0",
+		AutosaveProfile:    true,
+		MaxMergeFiles:      DefaultMaxMergeFiles,
+	}
+}
+
+// RecordUpdateCheck фиксирует время попытки проверки обновлений и ее результат
+func (s *AppSettings) RecordUpdateCheck(at time.Time, failed bool) {
+	s.LastCheckedAt = at
+	if failed {
+		s.UpdateCheckFailures++
+	} else {
+		s.UpdateCheckFailures = 0
+	}
+}
+
+// NormalizeUpdateAPIBaseURL проверяет адрес API обновлений и приводит его к виду без завершающего "/"
+// Допускаются только абсолютные http(s) адреса без параметров запроса; пустой адрес возвращается пустым
+func NormalizeUpdateAPIBaseURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
 	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("некорректный адрес API обновлений %q: %w", raw, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", fmt.Errorf("некорректный адрес API обновлений %q: ожидается схема http или https", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("некорректный адрес API обновлений %q: не указан хост", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("некорректный адрес API обновлений %q: адрес не должен содержать параметры запроса", raw)
+	}
+
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+// resetInvalidUpdateSource сбрасывает некорректные параметры источника обновлений к значениям по умолчанию
+// Остальные настройки не меняются. Возвращает по ошибке на каждый сброшенный параметр
+func (s *AppSettings) resetInvalidUpdateSource() []error {
+	var problems []error
+	if _, err := NormalizeUpdateAPIBaseURL(s.UpdateAPIBaseURL); err != nil {
+		problems = append(problems, err)
+		s.UpdateAPIBaseURL = ""
+	}
+
+	if strings.ContainsAny(s.UpdateOwner, "/ ") || strings.ContainsAny(s.UpdateRepo, "/ ") {
+		problems = append(problems, fmt.Errorf("некорректный репозиторий обновлений %q/%q: имена не должны содержать пробелы и \"/\"", s.UpdateOwner, s.UpdateRepo))
+		s.UpdateOwner = ""
+		s.UpdateRepo = ""
+	}
+
+	if s.UpdateCABundlePath != "" {
+		if _, err := os.Stat(s.UpdateCABundlePath); err != nil {
+			problems = append(problems, fmt.Errorf("файл сертификатов для обновлений недоступен: %w", err))
+			s.UpdateCABundlePath = ""
+		}
+	}
+
+	return problems
+}
+
+// ShouldWarnLargeMerge сообщает, нужно ли предупредить о длительном объединении fileCount файлов
+func (s *AppSettings) ShouldWarnLargeMerge(fileCount int) bool {
+	return fileCount >= LargeMergeFileCount && !s.SuppressLargeMergeWarning
+}
+
+// MergeMemoryLimit возвращает порог ожидаемой памяти объединения в байтах (0 - без порога)
+func (s *AppSettings) MergeMemoryLimit() int64 {
+	switch {
+	case s.MergeMemoryLimitMB < 0:
+		return 0
+	case s.MergeMemoryLimitMB == 0:
+		return DefaultMergeMemoryLimitMB << 20
+	default:
+		return int64(s.MergeMemoryLimitMB) << 20
+	}
+}
+
+// CanAddMergeFile сообщает, можно ли добавить еще один файл в список из count файлов
+// MaxMergeFiles = 0 (или меньше) снимает ограничение
+func (s *AppSettings) CanAddMergeFile(count int) bool {
+	return s.MaxMergeFiles <= 0 || count < s.MaxMergeFiles
+}
+
+// HeaderRowForNewSheets возвращает строку заголовков для листов нового базового файла
+func (s *AppSettings) HeaderRowForNewSheets() int {
+	return max(s.DefaultHeaderRow, 1)
+}
+
+// LastOutput возвращает путь к последнему сохраненному результату
+// и признак того, что файл по этому пути все еще существует
+func (s *AppSettings) LastOutput() (string, bool) {
+	if s.LastOutputPath == "" {
+		return "", false
+	}
+
+	info, err := os.Stat(s.LastOutputPath)
+	if err != nil || info.IsDir() {
+		return s.LastOutputPath, false
+	}
+
+	return s.LastOutputPath, true
 }
 
 // SaveSettings сохраняет настройки приложения
@@ -367,6 +666,27 @@ func (m *Manager) SaveSettings(settings *AppSettings) error {
 	return nil
 }
 
+// SettingsLanguage возвращает язык сообщений об ошибках из файла настроек приложения
+// Файл только читается: без него или при ошибке чтения возвращается язык по умолчанию
+func SettingsLanguage() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return apperrors.DefaultLanguage
+	}
+	data, err := os.ReadFile(filepath.Join(homeDir, ".excel-merger", "configs", "settings.json"))
+	if err != nil {
+		return apperrors.DefaultLanguage
+	}
+
+	var settings struct {
+		Language string `json:"language"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return apperrors.DefaultLanguage
+	}
+	return apperrors.NormalizeLanguage(settings.Language)
+}
+
 // LoadSettings загружает настройки приложения
 func (m *Manager) LoadSettings() (*AppSettings, error) {
 	settingsPath := filepath.Join(m.configDir, "settings.json")
@@ -385,12 +705,18 @@ func (m *Manager) LoadSettings() (*AppSettings, error) {
 		return nil, fmt.Errorf("не удалось прочитать файл настроек: %w", err)
 	}
 
-	var settings AppSettings
+	settings := *NewAppSettings()
 	if err := json.Unmarshal(data, &settings); err != nil {
 		m.logger.Warn("не удалось десериализовать настройки, используем по умолчанию", "error", err)
 		return NewAppSettings(), nil
 	}
 
+	// Некорректный источник обновлений не должен стоить пользователю остальных настроек:
+	// сбрасываются только ошибочные параметры, при следующем сохранении они удаляются из файла
+	for _, problem := range settings.resetInvalidUpdateSource() {
+		m.logger.Warn("параметр источника обновлений в файле настроек сброшен", "path", settingsPath, "error", problem)
+	}
+
 	m.logger.Info("настройки загружены", "use_ozon_template", settings.UseOzonTemplate)
 	return &settings, nil
 }
@@ -398,9 +724,9 @@ func (m *Manager) LoadSettings() (*AppSettings, error) {
 // GetOzonTemplate возвращает предустановленный шаблон для Ozon
 // Шаблон включает листы: "Шаблон", "Озон.Видео", "Озон.Видеообложка"
 // с номером строки заголовков = 4
-// Для листа "Шаблон" будет применена фильтрация по значению "Shuzzi"
+// Для листа "Шаблон" будет применена фильтрация по брендам brands (AppSettings.OzonBrands)
 // Для листов "Озон.Видео" и "Озон.Видеообложка" будет применена фильтрация по артикулам из листа "Шаблон"
-func (m *Manager) GetOzonTemplate() map[string]core.SheetConfig {
+func (m *Manager) GetOzonTemplate(brands []string) map[string]core.SheetConfig {
 	template := map[string]core.SheetConfig{
 		"Шаблон": {
 			SheetName:    "Шаблон",
@@ -408,7 +734,7 @@ func (m *Manager) GetOzonTemplate() map[string]core.SheetConfig {
 			HeaderRow:    4,
 			Headers:      []string{},
 			FilterColumn: -1, // Будет определен автоматически при анализе файла
-			FilterValues: []string{"Shuzzi"},
+			FilterValues: NormalizeBrands(brands),
 		},
 		"Озон.Видео": {
 			SheetName:           "Озон.Видео",
@@ -416,6 +742,7 @@ func (m *Manager) GetOzonTemplate() map[string]core.SheetConfig {
 			HeaderRow:           4,
 			Headers:             []string{},
 			UseTemplateArticles: true, // Фильтровать по артикулам из листа "Шаблон"
+			RawTextColumns:      []string{"Озон.Видео: ссылка"},
 		},
 		"Озон.Видеообложка": {
 			SheetName:           "Озон.Видеообложка",
@@ -423,6 +750,7 @@ func (m *Manager) GetOzonTemplate() map[string]core.SheetConfig {
 			HeaderRow:           4,
 			Headers:             []string{},
 			UseTemplateArticles: true, // Фильтровать по артикулам из листа "Шаблон"
+			RawTextColumns:      []string{"Озон.Видеообложка: ссылка"},
 		},
 	}
 
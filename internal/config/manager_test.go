@@ -1,9 +1,12 @@
 package config
 
 import (
+	"encoding/json"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -344,3 +347,482 @@ func TestLoadProfileErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestRecordUpdateCheck(t *testing.T) {
+	settings := NewAppSettings()
+	if !settings.CheckUpdates || settings.CheckIntervalHours != DefaultCheckIntervalHours {
+		t.Fatalf("неожиданные настройки проверки обновлений по умолчанию: %+v", settings)
+	}
+
+	first := time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC)
+	settings.RecordUpdateCheck(first, true)
+	settings.RecordUpdateCheck(first.Add(time.Hour), true)
+	if settings.UpdateCheckFailures != 2 {
+		t.Errorf("ожидалось 2 неудачные проверки, получено %d", settings.UpdateCheckFailures)
+	}
+	if !settings.LastCheckedAt.Equal(first.Add(time.Hour)) {
+		t.Errorf("время последней проверки не обновлено: %v", settings.LastCheckedAt)
+	}
+
+	settings.RecordUpdateCheck(first.Add(2*time.Hour), false)
+	if settings.UpdateCheckFailures != 0 {
+		t.Errorf("счетчик неудач должен сбрасываться после успешной проверки, получено %d", settings.UpdateCheckFailures)
+	}
+}
+
+// newTestManager создает менеджер с изолированной директорией профилей
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	configDir := t.TempDir()
+	profilesDir := filepath.Join(configDir, "profiles")
+	if err := os.MkdirAll(profilesDir, 0755); err != nil {
+		t.Fatalf("не удалось создать директорию профилей: %v", err)
+	}
+	return &Manager{configDir: configDir, profilesDir: profilesDir, logger: logger}
+}
+
+// writeProfileFile записывает профиль в JSON файл для импорта
+func writeProfileFile(t *testing.T, dir, filename string, profile *core.Profile) {
+	t.Helper()
+	data, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatalf("не удалось сериализовать профиль: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, filename), data, 0644); err != nil {
+		t.Fatalf("не удалось записать профиль: %v", err)
+	}
+}
+
+func TestImportProfilesDir(t *testing.T) {
+	srcDir := t.TempDir()
+
+	valid := func(name string) *core.Profile {
+		profile := core.NewProfile(name)
+		profile.BaseFileName = "base.xlsx"
+		profile.Sheets = []core.SheetConfig{{SheetName: "Sheet1", Enabled: true, HeaderRow: 1}}
+		return profile
+	}
+
+	writeProfileFile(t, srcDir, "shoes.json", valid("Обувь"))
+	writeProfileFile(t, srcDir, "bags.json", valid("Сумки"))
+	writeProfileFile(t, srcDir, "no_base.json", core.NewProfile("Без базового файла"))
+	if err := os.WriteFile(filepath.Join(srcDir, "broken.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatalf("не удалось записать файл: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "readme.txt"), []byte("not a profile"), 0644); err != nil {
+		t.Fatalf("не удалось записать файл: %v", err)
+	}
+
+	t.Run("mixed directory", func(t *testing.T) {
+		manager := newTestManager(t)
+
+		imported, errs := manager.ImportProfilesDir(srcDir, false)
+		if imported != 2 {
+			t.Errorf("ожидалось 2 импортированных профиля, получено %d", imported)
+		}
+		if len(errs) != 2 {
+			t.Errorf("ожидалось 2 ошибки, получено %d: %v", len(errs), errs)
+		}
+		if !manager.ProfileExists("shoes") || !manager.ProfileExists("bags") {
+			t.Error("валидные профили не были импортированы")
+		}
+	})
+
+	t.Run("rename on collision", func(t *testing.T) {
+		manager := newTestManager(t)
+		if err := manager.SaveProfile(valid("Существующий"), "shoes"); err != nil {
+			t.Fatalf("не удалось сохранить профиль: %v", err)
+		}
+
+		imported, _ := manager.ImportProfilesDir(srcDir, false)
+		if imported != 2 {
+			t.Errorf("ожидалось 2 импортированных профиля, получено %d", imported)
+		}
+
+		existing, err := manager.LoadProfile("shoes")
+		if err != nil || existing.ProfileName != "Существующий" {
+			t.Errorf("существующий профиль не должен перезаписываться: %v", err)
+		}
+		renamed, err := manager.LoadProfile("shoes_2")
+		if err != nil || renamed.ProfileName != "Обувь" {
+			t.Errorf("импортированный профиль должен быть сохранен как shoes_2: %v", err)
+		}
+	})
+
+	t.Run("overwrite on collision", func(t *testing.T) {
+		manager := newTestManager(t)
+		if err := manager.SaveProfile(valid("Существующий"), "shoes"); err != nil {
+			t.Fatalf("не удалось сохранить профиль: %v", err)
+		}
+
+		imported, _ := manager.ImportProfilesDir(srcDir, true)
+		if imported != 2 {
+			t.Errorf("ожидалось 2 импортированных профиля, получено %d", imported)
+		}
+
+		overwritten, err := manager.LoadProfile("shoes")
+		if err != nil || overwritten.ProfileName != "Обувь" {
+			t.Errorf("существующий профиль должен быть перезаписан: %v", err)
+		}
+		if manager.ProfileExists("shoes_2") {
+			t.Error("при перезаписи не должен создаваться профиль shoes_2")
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		manager := newTestManager(t)
+		imported, errs := manager.ImportProfilesDir(filepath.Join(srcDir, "missing"), false)
+		if imported != 0 || len(errs) != 1 {
+			t.Errorf("ожидалась одна ошибка без импорта, получено imported=%d errs=%v", imported, errs)
+		}
+	})
+}
+
+func TestLastOutputPersistence(t *testing.T) {
+	manager := newTestManager(t)
+
+	outputPath := filepath.Join(t.TempDir(), "result.xlsx")
+	if err := os.WriteFile(outputPath, []byte("xlsx"), 0644); err != nil {
+		t.Fatalf("не удалось создать файл результата: %v", err)
+	}
+
+	settings := NewAppSettings()
+	settings.LastOutputPath = outputPath
+	if err := manager.SaveSettings(settings); err != nil {
+		t.Fatalf("не удалось сохранить настройки: %v", err)
+	}
+
+	loaded, err := manager.LoadSettings()
+	if err != nil {
+		t.Fatalf("не удалось загрузить настройки: %v", err)
+	}
+	if loaded.LastOutputPath != outputPath {
+		t.Errorf("путь к результату не сохранился: ожидалось %s, получено %s", outputPath, loaded.LastOutputPath)
+	}
+
+	if path, ok := loaded.LastOutput(); !ok || path != outputPath {
+		t.Errorf("существующий результат должен быть найден: path=%s ok=%v", path, ok)
+	}
+
+	// Файл удален после сохранения
+	if err := os.Remove(outputPath); err != nil {
+		t.Fatalf("не удалось удалить файл результата: %v", err)
+	}
+	if path, ok := loaded.LastOutput(); ok || path != outputPath {
+		t.Errorf("удаленный результат не должен считаться существующим: path=%s ok=%v", path, ok)
+	}
+
+	// Результат еще не сохранялся
+	if _, ok := NewAppSettings().LastOutput(); ok {
+		t.Error("при пустом пути результат не должен считаться существующим")
+	}
+}
+
+// TestLoadSettingsValidatesUpdateSource тестирует сброс только некорректных параметров источника обновлений
+func TestLoadSettingsValidatesUpdateSource(t *testing.T) {
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, []byte("pem"), 0644); err != nil {
+		t.Fatalf("не удалось создать файл сертификатов: %v", err)
+	}
+	// source параметры источника обновлений: адрес API, владелец, репозиторий, файл сертификатов
+	type source struct{ apiURL, owner, repo, caPath string }
+	enterprise := func(s *AppSettings) {
+		s.UpdateAPIBaseURL = "https://github.example.com/api/v3"
+		s.UpdateOwner = "corp"
+		s.UpdateRepo = "merger-fork"
+		s.UpdateCABundlePath = caPath
+	}
+
+	tests := []struct {
+		name   string
+		modify func(s *AppSettings)
+		want   source
+	}{
+		{"по умолчанию", func(s *AppSettings) {}, source{}},
+		{"GitHub Enterprise", enterprise, source{"https://github.example.com/api/v3", "corp", "merger-fork", caPath}},
+		{"адрес без схемы", func(s *AppSettings) {
+			enterprise(s)
+			s.UpdateAPIBaseURL = "github.example.com/api/v3"
+		}, source{"", "corp", "merger-fork", caPath}},
+		{"адрес с параметрами", func(s *AppSettings) {
+			enterprise(s)
+			s.UpdateAPIBaseURL = "https://github.example.com/api/v3?x=1"
+		}, source{"", "corp", "merger-fork", caPath}},
+		{"владелец со слэшем", func(s *AppSettings) {
+			enterprise(s)
+			s.UpdateOwner = "corp/team"
+		}, source{"https://github.example.com/api/v3", "", "", caPath}},
+		{"нет файла сертификатов", func(s *AppSettings) {
+			enterprise(s)
+			s.UpdateCABundlePath = filepath.Join(t.TempDir(), "missing.pem")
+		}, source{"https://github.example.com/api/v3", "corp", "merger-fork", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t)
+			settings := NewAppSettings()
+			settings.MaxMergeFiles = 250
+			settings.OzonBrands = []string{"Shuzzi"}
+			tt.modify(settings)
+			if err := manager.SaveSettings(settings); err != nil {
+				t.Fatalf("не удалось сохранить настройки: %v", err)
+			}
+
+			loaded, err := manager.LoadSettings()
+			if err != nil {
+				t.Fatalf("LoadSettings() error = %v", err)
+			}
+			got := source{loaded.UpdateAPIBaseURL, loaded.UpdateOwner, loaded.UpdateRepo, loaded.UpdateCABundlePath}
+			if got != tt.want {
+				t.Errorf("источник обновлений = %+v, ожидалось %+v", got, tt.want)
+			}
+			// Остальные настройки не сбрасываются вместе с источником обновлений
+			if loaded.MaxMergeFiles != 250 || !slices.Equal(loaded.OzonBrands, []string{"Shuzzi"}) {
+				t.Errorf("настройки сброшены: MaxMergeFiles = %d, OzonBrands = %v", loaded.MaxMergeFiles, loaded.OzonBrands)
+			}
+		})
+	}
+}
+
+// TestShouldWarnLargeMerge тестирует решение о показе предупреждения о большом объединении
+func TestShouldWarnLargeMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileCount int
+		suppress  bool
+		want      bool
+	}{
+		{"мало файлов", LargeMergeFileCount - 1, false, false},
+		{"порог", LargeMergeFileCount, false, true},
+		{"много файлов", LargeMergeFileCount * 4, false, true},
+		{"отключено пользователем", LargeMergeFileCount * 4, true, false},
+		{"отключено, мало файлов", 1, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := NewAppSettings()
+			settings.SuppressLargeMergeWarning = tt.suppress
+			if got := settings.ShouldWarnLargeMerge(tt.fileCount); got != tt.want {
+				t.Errorf("ShouldWarnLargeMerge(%d) = %v, want %v", tt.fileCount, got, tt.want)
+			}
+		})
+	}
+
+	// Отключение предупреждения сохраняется между запусками
+	manager := newTestManager(t)
+	settings := NewAppSettings()
+	if !settings.ShouldWarnLargeMerge(LargeMergeFileCount) {
+		t.Fatal("по умолчанию предупреждение должно показываться")
+	}
+	settings.SuppressLargeMergeWarning = true
+	if err := manager.SaveSettings(settings); err != nil {
+		t.Fatalf("не удалось сохранить настройки: %v", err)
+	}
+	loaded, err := manager.LoadSettings()
+	if err != nil {
+		t.Fatalf("не удалось загрузить настройки: %v", err)
+	}
+	if loaded.ShouldWarnLargeMerge(LargeMergeFileCount) {
+		t.Error("отключенное предупреждение не сохранилось")
+	}
+}
+
+// TestMergeMemoryLimit тестирует порог ожидаемой памяти объединения
+func TestMergeMemoryLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limitMB int
+		want    int64
+	}{
+		{"по умолчанию", 0, DefaultMergeMemoryLimitMB << 20},
+		{"задан пользователем", 2048, 2048 << 20},
+		{"отключен", -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := NewAppSettings()
+			settings.MergeMemoryLimitMB = tt.limitMB
+			if got := settings.MergeMemoryLimit(); got != tt.want {
+				t.Errorf("MergeMemoryLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCanAddMergeFile тестирует ограничение количества файлов для объединения
+func TestCanAddMergeFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxFiles int
+		count    int
+		want     bool
+	}{
+		{"пустой список", DefaultMaxMergeFiles, 0, true},
+		{"ниже ограничения", DefaultMaxMergeFiles, DefaultMaxMergeFiles - 1, true},
+		{"ограничение достигнуто", DefaultMaxMergeFiles, DefaultMaxMergeFiles, false},
+		{"ограничение превышено", 3, 10, false},
+		{"без ограничения", 0, 10000, true},
+		{"отрицательное значение - без ограничения", -1, 10000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := NewAppSettings()
+			settings.MaxMergeFiles = tt.maxFiles
+			if got := settings.CanAddMergeFile(tt.count); got != tt.want {
+				t.Errorf("CanAddMergeFile(%d) при MaxMergeFiles = %d: %v, want %v", tt.count, tt.maxFiles, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMaxMergeFilesPersistence тестирует сохранение ограничения количества файлов
+func TestMaxMergeFilesPersistence(t *testing.T) {
+	manager := newTestManager(t)
+
+	// Файл настроек прежней версии без поля получает ограничение по умолчанию
+	settingsPath := filepath.Join(manager.configDir, "settings.json")
+	if err := os.WriteFile(settingsPath, []byte(`{"use_ozon_template": true}`), 0644); err != nil {
+		t.Fatalf("не удалось записать настройки: %v", err)
+	}
+	loaded, err := manager.LoadSettings()
+	if err != nil {
+		t.Fatalf("не удалось загрузить настройки: %v", err)
+	}
+	if loaded.MaxMergeFiles != DefaultMaxMergeFiles {
+		t.Errorf("MaxMergeFiles = %d, want %d", loaded.MaxMergeFiles, DefaultMaxMergeFiles)
+	}
+
+	// Снятое ограничение (0) не заменяется значением по умолчанию при загрузке
+	loaded.MaxMergeFiles = 0
+	if err := manager.SaveSettings(loaded); err != nil {
+		t.Fatalf("не удалось сохранить настройки: %v", err)
+	}
+	reloaded, err := manager.LoadSettings()
+	if err != nil {
+		t.Fatalf("не удалось загрузить настройки: %v", err)
+	}
+	if reloaded.MaxMergeFiles != 0 || !reloaded.CanAddMergeFile(DefaultMaxMergeFiles*10) {
+		t.Errorf("MaxMergeFiles = %d, ожидалось 0 (без ограничения)", reloaded.MaxMergeFiles)
+	}
+}
+
+// TestOzonBrands тестирует список брендов шаблона Ozon в настройках
+func TestOzonBrands(t *testing.T) {
+	manager := newTestManager(t)
+
+	// Файл настроек прежней версии без поля получает бренды по умолчанию
+	settingsPath := filepath.Join(manager.configDir, "settings.json")
+	if err := os.WriteFile(settingsPath, []byte(`{"use_ozon_template": true}`), 0644); err != nil {
+		t.Fatalf("не удалось записать настройки: %v", err)
+	}
+	loaded, err := manager.LoadSettings()
+	if err != nil {
+		t.Fatalf("не удалось загрузить настройки: %v", err)
+	}
+	if len(loaded.OzonBrands) != 1 || loaded.OzonBrands[0] != "Shuzzi" {
+		t.Errorf("OzonBrands = %q, ожидались бренды по умолчанию", loaded.OzonBrands)
+	}
+
+	// Новый бренд сохраняется и попадает в фильтр листа "Шаблон"
+	loaded.OzonBrands = NormalizeBrands([]string{" Shuzzi ", "", "Nord", "shuzzi", "NORD", "Lumo"})
+	if err := manager.SaveSettings(loaded); err != nil {
+		t.Fatalf("не удалось сохранить настройки: %v", err)
+	}
+	reloaded, err := manager.LoadSettings()
+	if err != nil {
+		t.Fatalf("не удалось загрузить настройки: %v", err)
+	}
+	if got := strings.Join(reloaded.OzonBrands, ","); got != "Shuzzi,Nord,Lumo" {
+		t.Errorf("OzonBrands = %s, ожидалось Shuzzi,Nord,Lumo", got)
+	}
+
+	template := manager.GetOzonTemplate(reloaded.OzonBrands)
+	if got := strings.Join(template["Шаблон"].FilterValues, ","); got != "Shuzzi,Nord,Lumo" {
+		t.Errorf("фильтр листа Шаблон = %s, ожидалось Shuzzi,Nord,Lumo", got)
+	}
+
+	// Пустой список отключает фильтр по бренду
+	if values := manager.GetOzonTemplate(nil)["Шаблон"].FilterValues; len(values) != 0 {
+		t.Errorf("фильтр без брендов = %q", values)
+	}
+}
+
+// TestDefaultHeaderRow тестирует строку заголовков для новых листов
+func TestDefaultHeaderRow(t *testing.T) {
+	manager := newTestManager(t)
+
+	// Файл настроек прежней версии без поля - первая строка
+	settingsPath := filepath.Join(manager.configDir, "settings.json")
+	if err := os.WriteFile(settingsPath, []byte(`{"use_ozon_template": true}`), 0644); err != nil {
+		t.Fatalf("не удалось записать настройки: %v", err)
+	}
+	loaded, err := manager.LoadSettings()
+	if err != nil {
+		t.Fatalf("не удалось загрузить настройки: %v", err)
+	}
+	if got := loaded.HeaderRowForNewSheets(); got != 1 {
+		t.Errorf("HeaderRowForNewSheets() = %d, want 1", got)
+	}
+
+	loaded.DefaultHeaderRow = 4
+	if err := manager.SaveSettings(loaded); err != nil {
+		t.Fatalf("не удалось сохранить настройки: %v", err)
+	}
+	reloaded, err := manager.LoadSettings()
+	if err != nil {
+		t.Fatalf("не удалось загрузить настройки: %v", err)
+	}
+	if got := reloaded.HeaderRowForNewSheets(); got != 4 {
+		t.Errorf("HeaderRowForNewSheets() = %d, want 4", got)
+	}
+
+	reloaded.DefaultHeaderRow = -2
+	if got := reloaded.HeaderRowForNewSheets(); got != 1 {
+		t.Errorf("HeaderRowForNewSheets() для некорректного значения = %d, want 1", got)
+	}
+}
+
+// TestSettingsLanguage тестирует чтение языка сообщений из файла настроек без его создания
+func TestSettingsLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string // Содержимое файла настроек; пусто - файла нет
+		want     string
+	}{
+		{"нет файла", "", "ru"},
+		{"английский", `{"language": "en-US"}`, "en"},
+		{"неподдерживаемый язык", `{"language": "de"}`, "ru"},
+		{"поврежденный файл", `{"language":`, "ru"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("USERPROFILE", home)
+			settingsPath := filepath.Join(home, ".excel-merger", "configs", "settings.json")
+			if tt.settings != "" {
+				if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(settingsPath, []byte(tt.settings), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := SettingsLanguage(); got != tt.want {
+				t.Errorf("SettingsLanguage() = %q, ожидалось %q", got, tt.want)
+			}
+			if tt.settings == "" {
+				if _, err := os.Stat(settingsPath); !os.IsNotExist(err) {
+					t.Error("файл настроек не должен создаваться")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// affixPlaceholder место значения в описании префикса и суффикса: "SHZ-*", "*-RU"
+const affixPlaceholder = "*"
+
+// affixAlwaysMark отметка правила, добавляющего префикс и суффикс даже к значениям, где они уже есть
+const affixAlwaysMark = "(всегда)"
+
+// AffixRule правило, добавляющее префикс и суффикс к значениям столбца, например код продавца
+// "SHZ-" к артикулам поставщика. Столбец ищется по заголовку в каждом файле отдельно
+type AffixRule struct {
+	Header        string `json:"header"`                    // Заголовок столбца (без учета регистра и пробелов)
+	Prefix        string `json:"prefix,omitempty"`          // Добавляется в начало значения
+	Suffix        string `json:"suffix,omitempty"`          // Добавляется в конец значения
+	SkipIfPresent bool   `json:"skip_if_present,omitempty"` // Не добавлять префикс (суффикс) к значению, которое уже им начинается (оканчивается)
+}
+
+// Validate проверяет, что правило задает столбец и хотя бы префикс или суффикс
+func (r AffixRule) Validate() error {
+	if strings.TrimSpace(r.Header) == "" {
+		return fmt.Errorf("не указан заголовок столбца")
+	}
+	if r.Prefix == "" && r.Suffix == "" {
+		return fmt.Errorf("для столбца '%s' не указан ни префикс, ни суффикс", r.Header)
+	}
+	return nil
+}
+
+// apply добавляет префикс и суффикс к значению value
+// Пустые значения не изменяются. changed - значение изменено; при SkipIfPresent значение,
+// в котором уже есть и префикс, и суффикс, возвращается без изменений
+func (r AffixRule) apply(value string) (result string, changed bool) {
+	if strings.TrimSpace(value) == "" {
+		return value, false
+	}
+
+	result = value
+	if r.Prefix != "" && !(r.SkipIfPresent && strings.HasPrefix(value, r.Prefix)) {
+		result = r.Prefix + result
+	}
+	if r.Suffix != "" && !(r.SkipIfPresent && strings.HasSuffix(value, r.Suffix)) {
+		result += r.Suffix
+	}
+	return result, result != value
+}
+
+// affixStats итог правила по листу: измененные и пропущенные значения, файлы без столбца
+type affixStats struct {
+	modified int
+	skipped  int
+	missing  []string
+}
+
+// warnings возвращает итог правила rule по листу sheetName: сведения об измененных
+// и пропущенных значениях и предупреждение о файлах, где столбец не найден
+func (s affixStats) warnings(sheetName string, rule AffixRule) []Warning {
+	var warnings []Warning
+	if s.modified > 0 || s.skipped > 0 {
+		warnings = append(warnings, newWarning(SeverityInfo,
+			"лист '%s', столбец '%s': префикс и суффикс добавлены к %d значениям, пропущено значений, где они уже есть: %d",
+			sheetName, rule.Header, s.modified, s.skipped))
+	}
+	if len(s.missing) > 0 {
+		warnings = append(warnings, newWarning(SeverityWarning,
+			"лист '%s': столбец '%s' для префикса и суффикса не найден в файлах: %s",
+			sheetName, rule.Header, strings.Join(s.missing, ", ")))
+	}
+	return warnings
+}
+
+// rawAffixWarnings предупреждает о правилах для столбцов без преобразований raw:
+// префикс и суффикс к таким столбцам не добавляются
+func rawAffixWarnings(sheetName string, headers []string, rules []AffixRule, raw map[int]bool) []Warning {
+	var warnings []Warning
+	for _, rule := range rules {
+		if column := columnIndexByHeader(headers, rule.Header); column >= 0 && raw[column] {
+			warnings = append(warnings, newWarning(SeverityWarning,
+				"лист '%s': столбец '%s' переносится без преобразований, префикс и суффикс к нему не добавляются",
+				sheetName, rule.Header))
+		}
+	}
+	return warnings
+}
+
+// applyAffixRules применяет правила к строкам одного файла с заголовками headers
+// Измененная строка копируется, поэтому кэшированные строки базового файла не изменяются.
+// Возвращает для каждого правила измененные и пропущенные значения и найден ли столбец.
+// Столбцы без преобразований raw не изменяются: их значения переносятся как есть
+func applyAffixRules(rows [][]string, headers []string, rules []AffixRule, raw map[int]bool) (modified, skipped []int, found []bool) {
+	modified = make([]int, len(rules))
+	skipped = make([]int, len(rules))
+	found = make([]bool, len(rules))
+
+	for i, rule := range rules {
+		column := columnIndexByHeader(headers, rule.Header)
+		if column < 0 {
+			continue
+		}
+		found[i] = true
+		if raw[column] {
+			continue
+		}
+
+		for j, row := range rows {
+			if column >= len(row) || strings.TrimSpace(row[column]) == "" {
+				continue
+			}
+			value, changed := rule.apply(row[column])
+			if !changed {
+				skipped[i]++
+				continue
+			}
+			row = append([]string(nil), row...)
+			row[column] = value
+			rows[j] = row
+			modified[i]++
+		}
+	}
+	return modified, skipped, found
+}
+
+// ParseAffixRules разбирает правила вида "Артикул: SHZ-*; Штрихкод: *-RU (всегда)"
+// Звездочка обозначает значение: текст до нее - префикс, после - суффикс. Префикс и суффикс
+// не добавляются к значениям, где они уже есть, если правило не отмечено "(всегда)"
+func ParseAffixRules(spec string) ([]AffixRule, error) {
+	var rules []AffixRule
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		header, pattern, ok := strings.Cut(part, ":")
+		header = strings.TrimSpace(header)
+		pattern = strings.TrimSpace(pattern)
+		always := strings.HasSuffix(pattern, affixAlwaysMark)
+		pattern = strings.TrimSpace(strings.TrimSuffix(pattern, affixAlwaysMark))
+		if !ok || header == "" || strings.Count(pattern, affixPlaceholder) != 1 {
+			return nil, fmt.Errorf("некорректное правило '%s', ожидается вид Артикул: SHZ-*", part)
+		}
+
+		prefix, suffix, _ := strings.Cut(pattern, affixPlaceholder)
+		rule := AffixRule{Header: header, Prefix: prefix, Suffix: suffix, SkipIfPresent: !always}
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// FormatAffixRules формирует описание правил для ParseAffixRules
+func FormatAffixRules(rules []AffixRule) string {
+	parts := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		part := rule.Header + ": " + rule.Prefix + affixPlaceholder + rule.Suffix
+		if !rule.SkipIfPresent {
+			part += " " + affixAlwaysMark
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
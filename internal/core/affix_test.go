@@ -0,0 +1,164 @@
+package core
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAffixRuleApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        AffixRule
+		value       string
+		want        string
+		wantChanged bool
+	}{
+		{"префикс", AffixRule{Prefix: "SHZ-", SkipIfPresent: true}, "A1", "SHZ-A1", true},
+		{"префикс уже есть", AffixRule{Prefix: "SHZ-", SkipIfPresent: true}, "SHZ-A1", "SHZ-A1", false},
+		{"префикс всегда", AffixRule{Prefix: "SHZ-"}, "SHZ-A1", "SHZ-SHZ-A1", true},
+		{"суффикс", AffixRule{Suffix: "-RU", SkipIfPresent: true}, "A1", "A1-RU", true},
+		{"есть только суффикс", AffixRule{Prefix: "SHZ-", Suffix: "-RU", SkipIfPresent: true}, "A1-RU", "SHZ-A1-RU", true},
+		{"пустое значение", AffixRule{Prefix: "SHZ-"}, " ", " ", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := tt.rule.apply(tt.value)
+			if got != tt.want || changed != tt.wantChanged {
+				t.Errorf("apply(%q) = %q, %v; ожидалось %q, %v", tt.value, got, changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestApplyAffixRules(t *testing.T) {
+	rules := []AffixRule{
+		{Header: "артикул", Prefix: "SHZ-", SkipIfPresent: true},
+		{Header: "Штрихкод", Suffix: "-RU", SkipIfPresent: true},
+	}
+	original := []string{"Ботинки", "A1"}
+	rows := [][]string{original, {"Кеды", "SHZ-A2"}, {"Тапки"}, {"Сапоги", ""}}
+
+	// Столбец ищется по заголовку файла: здесь артикул во втором столбце, штрихкода нет
+	modified, skipped, found := applyAffixRules(rows, []string{"Название", " Артикул "}, rules, nil)
+	want := [][]string{{"Ботинки", "SHZ-A1"}, {"Кеды", "SHZ-A2"}, {"Тапки"}, {"Сапоги", ""}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("строки = %q, ожидалось %q", rows, want)
+	}
+	if !reflect.DeepEqual(modified, []int{1, 0}) || !reflect.DeepEqual(skipped, []int{1, 0}) || !reflect.DeepEqual(found, []bool{true, false}) {
+		t.Errorf("изменено %v, пропущено %v, найдено %v", modified, skipped, found)
+	}
+	if original[1] != "A1" {
+		t.Error("исходная строка изменена на месте")
+	}
+
+	// Повторное применение не добавляет префикс второй раз
+	modified, skipped, _ = applyAffixRules(rows, []string{"Название", "Артикул"}, rules, nil)
+	if !reflect.DeepEqual(rows, want) || modified[0] != 0 || skipped[0] != 2 {
+		t.Errorf("повторное применение: строки %q, изменено %v, пропущено %v", rows, modified, skipped)
+	}
+
+	// Столбец без преобразований найден, но не изменяется
+	rows = [][]string{{"Ботинки", "A1"}}
+	modified, skipped, found = applyAffixRules(rows, []string{"Название", "Артикул"}, rules, map[int]bool{1: true})
+	if rows[0][1] != "A1" || modified[0] != 0 || skipped[0] != 0 || !found[0] {
+		t.Errorf("столбец без преобразований: строки %q, изменено %v, пропущено %v, найдено %v", rows, modified, skipped, found)
+	}
+}
+
+func TestParseAffixRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []AffixRule
+		wantErr bool
+	}{
+		{"пусто", " ", nil, false},
+		{"префикс", "Артикул: SHZ-*", []AffixRule{{Header: "Артикул", Prefix: "SHZ-", SkipIfPresent: true}}, false},
+		{"суффикс всегда и префикс", "Штрихкод: *-RU (всегда); Код: K*", []AffixRule{
+			{Header: "Штрихкод", Suffix: "-RU"},
+			{Header: "Код", Prefix: "K", SkipIfPresent: true},
+		}, false},
+		{"без звездочки", "Артикул: SHZ-", nil, true},
+		{"только звездочка", "Артикул: *", nil, true},
+		{"без заголовка", ": SHZ-*", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAffixRules(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAffixRules(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAffixRules(%q) = %+v, ожидалось %+v", tt.spec, got, tt.want)
+			}
+			if !tt.wantErr {
+				if again, _ := ParseAffixRules(FormatAffixRules(got)); !reflect.DeepEqual(again, got) {
+					t.Errorf("разбор FormatAffixRules = %+v, ожидалось %+v", again, got)
+				}
+			}
+		})
+	}
+}
+
+// TestMergeFilesAffixRules тестирует префикс артикулов перед сопоставлением с листом "Шаблон"
+func TestMergeFilesAffixRules(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	otherPath := filepath.Join(dir, "other.xlsx")
+
+	// В шаблоне Ozon артикулы уже с кодом продавца, у поставщиков - без него
+	template := []string{"Название", "Артикул"}
+	video := []string{"Артикул", "Ссылка"}
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Шаблон", [][]string{template, {"Ботинки", "SHZ-A1"}, {"Кеды", "SHZ-A2"}}},
+		{"Видео", [][]string{video, {"SHZ-A1", "v1"}}},
+	})
+	writeTestWorkbookSheets(t, sourcePath, []testSheet{
+		{"Шаблон", [][]string{template}},
+		{"Видео", [][]string{video, {"A2", "v2"}, {"A3", "v3"}}},
+	})
+	writeTestWorkbookSheets(t, otherPath, []testSheet{
+		{"Шаблон", [][]string{template}},
+		{"Видео", [][]string{{"Код", "Ссылка"}, {"A2", "v4"}}},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Шаблон": {SheetName: "Шаблон", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+		"Видео": {
+			SheetName: "Видео", Enabled: true, HeaderRow: 1, FilterColumn: -1, UseTemplateArticles: true,
+			AffixRules: []AffixRule{{Header: "Артикул", Prefix: "SHZ-", SkipIfPresent: true}},
+		},
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath, otherPath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка объединения: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Видео")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{video, {"SHZ-A1", "v1"}, {"SHZ-A2", "v2"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("лист Видео = %q, ожидалось %q", rows, want)
+	}
+
+	var report []string
+	for _, warning := range result.Warnings {
+		report = append(report, warning.Message)
+	}
+	text := strings.Join(report, "\n")
+	if !strings.Contains(text, "добавлены к 2 значениям, пропущено значений, где они уже есть: 1") {
+		t.Errorf("нет итога префикса в предупреждениях:\n%s", text)
+	}
+	if !strings.Contains(text, "не найден в файлах: other.xlsx") {
+		t.Errorf("нет предупреждения о файле без столбца:\n%s", text)
+	}
+}
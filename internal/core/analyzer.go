@@ -3,14 +3,21 @@ package core
 import (
 	"fmt"
 	"log/slog"
+	"path/filepath"
+	"sync"
 
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
 	"github.com/DatKorso/Merge-excel/internal/excel"
 )
 
 // BaseAnalyzer анализирует базовый файл и создает конфигурацию для объединения
 type BaseAnalyzer struct {
-	reader *excel.Reader
-	logger *slog.Logger
+	reader  *excel.Reader
+	logger  *slog.Logger
+	readers *excel.ReaderPool // Пул открытых книг сеанса (nil - каждый файл открывается заново)
+
+	mu      sync.Mutex
+	session *slog.Logger // Логгер текущего сеанса анализа с run_id (nil - без сеанса)
 }
 
 // NewBaseAnalyzer создает новый анализатор базового файла
@@ -25,13 +32,56 @@ func NewBaseAnalyzer(reader *excel.Reader, logger *slog.Logger) *BaseAnalyzer {
 	}
 }
 
+// SetReaderPool задает пул открытых книг, общий с объединением
+func (a *BaseAnalyzer) SetReaderPool(pool *excel.ReaderPool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.readers = pool
+}
+
+// borrowReader выдает книгу файла из пула сеанса
+func (a *BaseAnalyzer) borrowReader(filePath string) (*excel.Reader, func(), error) {
+	a.mu.Lock()
+	pool := a.readers
+	a.mu.Unlock()
+
+	reader, release, err := pool.Borrow(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("не удалось открыть файл: %w", err)
+	}
+	return reader, release, nil
+}
+
+// StartSession начинает сеанс анализа файла и возвращает его идентификатор
+// Записи журнала анализатора до следующего сеанса помечаются этим run_id
+func (a *BaseAnalyzer) StartSession() string {
+	runID := newRunID()
+
+	a.mu.Lock()
+	a.session = a.logger.With("run_id", runID)
+	a.mu.Unlock()
+
+	return runID
+}
+
+// log возвращает логгер текущего сеанса анализа
+func (a *BaseAnalyzer) log() *slog.Logger {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.session != nil {
+		return a.session
+	}
+	return a.logger
+}
+
 // GetSheetNames возвращает список всех листов в базовом файле
 func (a *BaseAnalyzer) GetSheetNames(filePath string) ([]string, error) {
-	reader, err := excel.NewReader(filePath)
+	reader, release, err := a.borrowReader(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("не удалось открыть файл: %w", err)
+		return nil, err
 	}
-	defer reader.Close()
+	defer release()
 
 	sheetNames := reader.GetSheetNames()
 	if len(sheetNames) == 0 {
@@ -41,13 +91,176 @@ func (a *BaseAnalyzer) GetSheetNames(filePath string) ([]string, error) {
 	return sheetNames, nil
 }
 
+// AnalyzeSheets создает настройки для всех листов базового файла: листы выключены,
+// строка заголовков - headerRow (значение меньше 1 заменяется на 1), заголовки прочитаны из нее.
+// Лист, где строки headerRow нет, получает пустые заголовки.
+// Лист с одной таблицей Excel или одним именованным диапазоном получает DataRangeName,
+// а строка заголовков и заголовки берутся из этой области данных
+func (a *BaseAnalyzer) AnalyzeSheets(filePath string, headerRow int) ([]SheetConfig, error) {
+	reader, release, err := a.borrowReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	sheetNames := reader.GetSheetNames()
+	if len(sheetNames) == 0 {
+		return nil, fmt.Errorf("файл не содержит листов")
+	}
+	headerRow = max(headerRow, 1)
+
+	// Без диапазонов листы настраиваются по строке заголовков
+	ranges, err := reader.GetDataRanges()
+	if err != nil {
+		a.log().Warn("не удалось прочитать именованные диапазоны и таблицы", "error", err)
+	}
+
+	sheets := make([]SheetConfig, 0, len(sheetNames))
+	for _, name := range sheetNames {
+		sheet := SheetConfig{
+			SheetName: name,
+			Enabled:   false,
+			HeaderRow: headerRow,
+		}
+		dataRange, hasRange := sheetDataRange(ranges, name)
+		if hasRange {
+			sheet.DataRangeName = dataRange.Name
+			sheet.HeaderRow = dataRange.HeaderRow()
+			a.log().Info("область данных листа из диапазона",
+				"sheet", name, "data_range", dataRange.Name, "range", dataRange.String())
+		}
+
+		headers, err := reader.GetHeaderRow(name, sheet.HeaderRow)
+		if err != nil {
+			a.log().Debug("строка заголовков не прочитана", "sheet", name, "header_row", sheet.HeaderRow, "error", err)
+			headers = []string{}
+		}
+		if hasRange && len(headers) > dataRange.LastCol {
+			headers = headers[:dataRange.LastCol]
+		}
+		sheet.Headers = headers
+		sheets = append(sheets, sheet)
+	}
+
+	a.log().Info("листы базового файла проанализированы", "sheets_count", len(sheets), "header_row", headerRow)
+	return sheets, nil
+}
+
+// MissingSheets возвращает включенные листы профиля, которых нет в базовом файле
+// Имена сравниваются так же, как при объединении: без учета регистра и пробелов
+func (a *BaseAnalyzer) MissingSheets(filePath string, sheets []SheetConfig) ([]string, error) {
+	sheetNames, err := a.GetSheetNames(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, sheet := range sheets {
+		if !sheet.Enabled {
+			continue
+		}
+		if _, ok := findSheet(sheetNames, sheet.SheetName); !ok {
+			missing = append(missing, sheet.SheetName)
+		}
+	}
+	return missing, nil
+}
+
+// MissingSourceSheets возвращает включенные листы профиля, которых нет в файле для объединения
+// Лист считается найденным и по одному из альтернативных имен SourceSheetNames или по шаблону SourceSheetPattern
+// Читаются только имена листов, поэтому проверка подходит для момента добавления файла
+func (a *BaseAnalyzer) MissingSourceSheets(filePath string, sheets []SheetConfig) ([]string, error) {
+	sheetNames, err := a.GetSheetNames(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, sheet := range sheets {
+		if !sheet.Enabled {
+			continue
+		}
+		if _, ok := locateSourceSheet(sheetNames, sheet.SheetName, &sheet); !ok {
+			missing = append(missing, sheet.SheetName)
+		}
+	}
+	return missing, nil
+}
+
+// CheckEnabledSheets проверяет перед объединением, что все включенные листы профиля
+// есть в базовом файле: профиль мог быть создан для другого файла
+// Возвращает ошибку ErrCodeSheetNotFound со списком всех отсутствующих листов
+func (a *BaseAnalyzer) CheckEnabledSheets(filePath string, sheets []SheetConfig) error {
+	missing, err := a.MissingSheets(filePath, sheets)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		a.log().Warn("в базовом файле нет включенных листов профиля", "file", filePath, "missing", missing)
+		return apperrors.NewSheetsNotFoundError(missing, filepath.Base(filePath))
+	}
+	return nil
+}
+
+// GetActiveSheetName возвращает имя активного листа базового файла
+func (a *BaseAnalyzer) GetActiveSheetName(filePath string) (string, error) {
+	reader, release, err := a.borrowReader(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	return reader.GetActiveSheetName()
+}
+
+// FindSheetsWithSameHeaders находит группы листов базового файла с одинаковой
+// строкой заголовков headerRow; такие листы можно объединить в один лист результата.
+// Листы без заголовков не группируются; возвращаются только группы из двух и более листов
+func (a *BaseAnalyzer) FindSheetsWithSameHeaders(filePath string, headerRow int) ([][]string, error) {
+	reader, release, err := a.borrowReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var groups [][]string
+	var groupHeaders [][]string
+	for _, sheetName := range reader.GetSheetNames() {
+		headers, err := reader.GetHeaderRow(sheetName, headerRow)
+		if err != nil || len(trimTrailingEmpty(headers)) == 0 {
+			continue
+		}
+
+		found := false
+		for i := range groups {
+			if sameHeaders(groupHeaders[i], headers) {
+				groups[i] = append(groups[i], sheetName)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, []string{sheetName})
+			groupHeaders = append(groupHeaders, headers)
+		}
+	}
+
+	var result [][]string
+	for _, group := range groups {
+		if len(group) > 1 {
+			result = append(result, group)
+		}
+	}
+	return result, nil
+}
+
 // GetHeaders возвращает заголовки для указанного листа
 func (a *BaseAnalyzer) GetHeaders(filePath, sheetName string, headerRow int) ([]string, error) {
-	reader, err := excel.NewReader(filePath)
+	reader, release, err := a.borrowReader(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("не удалось открыть файл: %w", err)
+		return nil, err
 	}
-	defer reader.Close()
+	defer release()
 
 	if !reader.SheetExists(sheetName) {
 		return nil, fmt.Errorf("лист '%s' не найден", sheetName)
@@ -65,11 +278,11 @@ func (a *BaseAnalyzer) GetHeaders(filePath, sheetName string, headerRow int) ([]
 // Проверяет все столбцы до нахождения нужной ячейки
 // Возвращает 0-based индекс столбца или -1 если не найден
 func (a *BaseAnalyzer) FindBrandColumnInFirstRows(filePath, sheetName string, headerRow int) (int, error) {
-	reader, err := excel.NewReader(filePath)
+	reader, release, err := a.borrowReader(filePath)
 	if err != nil {
-		return -1, fmt.Errorf("не удалось открыть файл: %w", err)
+		return -1, err
 	}
-	defer reader.Close()
+	defer release()
 
 	if !reader.SheetExists(sheetName) {
 		return -1, fmt.Errorf("лист '%s' не найден", sheetName)
@@ -84,17 +297,98 @@ func (a *BaseAnalyzer) FindBrandColumnInFirstRows(filePath, sheetName string, he
 	// Ищем ячейку "Бренд в одежде и обуви*" во всей строке
 	for i, cell := range row2 {
 		if cell == "Бренд в одежде и обуви*" {
-			a.logger.Info("найден столбец бренда", "column_index", i, "column_letter", columnIndexToLetter(i), "sheet", sheetName)
+			a.log().Info("найден столбец бренда", "column_index", i, "column_letter", ColumnIndexToLetter(i), "sheet", sheetName)
 			return i, nil
 		}
 	}
 
-	a.logger.Warn("столбец 'Бренд в одежде и обуви*' не найден в строке 2", "sheet", sheetName)
+	a.log().Warn("столбец 'Бренд в одежде и обуви*' не найден в строке 2", "sheet", sheetName)
 	return -1, nil
 }
 
-// columnIndexToLetter преобразует 0-based индекс столбца в букву Excel (0 -> A, 25 -> Z, 26 -> AA и т.д.)
-func columnIndexToLetter(index int) string {
+// FilterRules правила фильтрации строк листа для предпросмотра
+type FilterRules struct {
+	Column   int             // 0-based индекс столбца фильтрации (-1 = не используется)
+	Values   []string        // Значения столбца, строки с которыми остаются в результате
+	Articles map[string]bool // Разрешенные артикулы (пусто = не используется)
+}
+
+// FilterPreview результат предпросмотра фильтрации
+type FilterPreview struct {
+	TotalRows     int        // Всего непустых строк данных
+	KeptCount     int        // Количество строк, которые останутся
+	ExcludedCount int        // Количество строк, которые будут исключены
+	Kept          [][]string // Примеры оставшихся строк (не более sampleN)
+	Excluded      [][]string // Примеры исключенных строк (не более sampleN)
+}
+
+// PreviewFilter показывает, какие строки листа останутся и какие будут исключены фильтрами
+// Использует те же функции фильтрации, что и объединение; sampleN ограничивает число примеров
+func (a *BaseAnalyzer) PreviewFilter(filePath, sheetName string, headerRow int, rules FilterRules, sampleN int) (*FilterPreview, error) {
+	reader, release, err := a.borrowReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if !reader.SheetExists(sheetName) {
+		return nil, fmt.Errorf("лист '%s' не найден", sheetName)
+	}
+
+	rows, err := reader.GetRows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать лист: %w", err)
+	}
+
+	if headerRow < 1 || headerRow > len(rows) {
+		return nil, fmt.Errorf("лист '%s' содержит только %d строк, но указана строка заголовков %d",
+			sheetName, len(rows), headerRow)
+	}
+
+	preview := splitByFilter(rows[headerRow-1], filterEmptyRows(rows[headerRow:]), rules, sampleN)
+
+	a.log().Info("предпросмотр фильтрации",
+		"sheet", sheetName,
+		"total_rows", preview.TotalRows,
+		"kept", preview.KeptCount,
+		"excluded", preview.ExcludedCount,
+	)
+
+	return preview, nil
+}
+
+// splitByFilter разделяет строки на оставшиеся и исключенные фильтрами
+func splitByFilter(headerRow []string, dataRows [][]string, rules FilterRules, sampleN int) *FilterPreview {
+	preview := &FilterPreview{TotalRows: len(dataRows)}
+	articleColumn := findArticleColumn(headerRow)
+
+	for _, row := range dataRows {
+		single := [][]string{row}
+		if rules.Column >= 0 && len(rules.Values) > 0 {
+			single = filterRowsByColumnValue(single, rules.Column, rules.Values)
+		}
+		if len(rules.Articles) > 0 && len(single) > 0 {
+			single = filterRowsByArticleColumn(single, articleColumn, rules.Articles, nil)
+		}
+
+		if len(single) > 0 {
+			preview.KeptCount++
+			if len(preview.Kept) < sampleN {
+				preview.Kept = append(preview.Kept, row)
+			}
+		} else {
+			preview.ExcludedCount++
+			if len(preview.Excluded) < sampleN {
+				preview.Excluded = append(preview.Excluded, row)
+			}
+		}
+	}
+
+	return preview
+}
+
+// ColumnIndexToLetter преобразует 0-based индекс столбца в букву Excel (0 -> A, 25 -> Z, 26 -> AA и т.д.)
+func ColumnIndexToLetter(index int) string {
 	result := ""
 	for index >= 0 {
 		result = string(rune('A'+index%26)) + result
@@ -1,12 +1,18 @@
 package core
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
 	"github.com/DatKorso/Merge-excel/internal/excel"
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
 )
 
 func TestGetSheetNames(t *testing.T) {
@@ -132,3 +138,408 @@ func TestFindBrandColumnInFirstRows(t *testing.T) {
 		}
 	})
 }
+
+func TestPreviewFilter(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	path := filepath.Join(t.TempDir(), "filter.xlsx")
+
+	rows := [][]string{
+		{"Отчет по товарам"},
+		{"Артикул", "Бренд", "Цена"},
+		{"A1", "Shuzzi", "100"},
+		{"A2", "Other", "200"},
+		{"A3", " shuzzi ", "300"},
+		{"", "", ""},
+		{"A4", "Nike", "400"},
+		{"A5", "Shuzzi", "500"},
+		{"A6", "Adidas", "600"},
+	}
+	exceltest.WriteWorkbook(t, path, "Шаблон", rows)
+
+	analyzer := NewBaseAnalyzer(nil, logger)
+
+	tests := []struct {
+		name  string
+		rules FilterRules
+	}{
+		{"brand filter", FilterRules{Column: 1, Values: []string{"Shuzzi"}}},
+		{"article filter", FilterRules{Column: -1, Articles: map[string]bool{"A2": true, "A4": true}}},
+		{"brand and article filters", FilterRules{Column: 1, Values: []string{"Shuzzi"}, Articles: map[string]bool{"A1": true, "A2": true}}},
+		{"no filters", FilterRules{Column: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview, err := analyzer.PreviewFilter(path, "Шаблон", 2, tt.rules, 100)
+			if err != nil {
+				t.Fatalf("ошибка предпросмотра: %v", err)
+			}
+
+			// Полная фильтрация теми же функциями, что и при объединении
+			dataRows := filterEmptyRows(rows[2:])
+			kept := dataRows
+			if tt.rules.Column >= 0 && len(tt.rules.Values) > 0 {
+				kept = filterRowsByColumnValue(kept, tt.rules.Column, tt.rules.Values)
+			}
+			if len(tt.rules.Articles) > 0 {
+				kept = filterRowsByArticles(rows[1], kept, tt.rules.Articles)
+			}
+
+			if preview.TotalRows != len(dataRows) {
+				t.Errorf("всего строк: ожидалось %d, получено %d", len(dataRows), preview.TotalRows)
+			}
+			if preview.KeptCount != len(kept) {
+				t.Errorf("оставлено строк: ожидалось %d, получено %d", len(kept), preview.KeptCount)
+			}
+			if preview.ExcludedCount != len(dataRows)-len(kept) {
+				t.Errorf("исключено строк: ожидалось %d, получено %d", len(dataRows)-len(kept), preview.ExcludedCount)
+			}
+			for i := range kept {
+				if preview.Kept[i][0] != kept[i][0] {
+					t.Errorf("строка %d: ожидался артикул %s, получен %s", i, kept[i][0], preview.Kept[i][0])
+				}
+			}
+		})
+	}
+
+	t.Run("sample size", func(t *testing.T) {
+		preview, err := analyzer.PreviewFilter(path, "Шаблон", 2, FilterRules{Column: 1, Values: []string{"Shuzzi"}}, 1)
+		if err != nil {
+			t.Fatalf("ошибка предпросмотра: %v", err)
+		}
+		if len(preview.Kept) != 1 || len(preview.Excluded) != 1 {
+			t.Errorf("ожидалось по одному примеру, получено kept=%d excluded=%d", len(preview.Kept), len(preview.Excluded))
+		}
+		if preview.KeptCount != 3 || preview.ExcludedCount != 3 {
+			t.Errorf("счетчики не должны зависеть от размера выборки: kept=%d excluded=%d", preview.KeptCount, preview.ExcludedCount)
+		}
+	})
+}
+
+// TestCheckEnabledSheets тестирует проверку наличия включенных листов профиля в базовом файле
+func TestCheckEnabledSheets(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	basePath := filepath.Join(t.TempDir(), "base.xlsx")
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{name: "Data", rows: [][]string{{"Артикул"}}},
+		{name: "Заказы", rows: [][]string{{"Артикул"}}},
+	})
+	analyzer := NewBaseAnalyzer(nil, logger)
+
+	tests := []struct {
+		name        string
+		sheets      []SheetConfig
+		wantMissing []string
+	}{
+		{
+			"все листы на месте",
+			[]SheetConfig{{SheetName: "Data", Enabled: true}, {SheetName: " заказы ", Enabled: true}},
+			nil,
+		},
+		{
+			"выключенные листы не проверяются",
+			[]SheetConfig{{SheetName: "Data", Enabled: true}, {SheetName: "Архив", Enabled: false}},
+			nil,
+		},
+		{
+			"профиль от другого файла",
+			[]SheetConfig{
+				{SheetName: "Отчет", Enabled: true},
+				{SheetName: "Data", Enabled: true},
+				{SheetName: "Остатки", Enabled: true},
+			},
+			[]string{"Отчет", "Остатки"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, err := analyzer.MissingSheets(basePath, tt.sheets)
+			if err != nil {
+				t.Fatalf("MissingSheets() error = %v", err)
+			}
+			if strings.Join(missing, ",") != strings.Join(tt.wantMissing, ",") {
+				t.Errorf("MissingSheets() = %v, ожидалось %v", missing, tt.wantMissing)
+			}
+
+			err = analyzer.CheckEnabledSheets(basePath, tt.sheets)
+			if len(tt.wantMissing) == 0 {
+				if err != nil {
+					t.Errorf("CheckEnabledSheets() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, apperrors.ErrSheetNotFound) {
+				t.Fatalf("CheckEnabledSheets() error = %v, ожидалась ErrSheetNotFound", err)
+			}
+			if !strings.Contains(err.Error(), "В файле base.xlsx нет листов: 'Отчет', 'Остатки'") {
+				t.Errorf("сообщение не перечисляет отсутствующие листы: %v", err)
+			}
+		})
+	}
+
+	if err := analyzer.CheckEnabledSheets(filepath.Join(t.TempDir(), "нет.xlsx"), nil); err == nil {
+		t.Error("ожидалась ошибка для несуществующего файла")
+	}
+}
+
+// TestMissingSourceSheets тестирует проверку листов файла при добавлении в список объединения
+func TestMissingSourceSheets(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	sourcePath := filepath.Join(t.TempDir(), "source.xlsx")
+	writeTestWorkbookSheets(t, sourcePath, []testSheet{
+		{name: "Шаблон", rows: [][]string{{"Артикул"}}},
+		{name: "Price", rows: [][]string{{"Артикул"}}},
+	})
+	analyzer := NewBaseAnalyzer(nil, logger)
+
+	tests := []struct {
+		name        string
+		sheets      []SheetConfig
+		wantMissing []string
+	}{
+		{
+			"листы совпадают",
+			[]SheetConfig{{SheetName: " шаблон", Enabled: true}},
+			nil,
+		},
+		{
+			"лист найден по альтернативному имени",
+			[]SheetConfig{{SheetName: "Прайс", Enabled: true, SourceSheetNames: []string{"price"}}},
+			nil,
+		},
+		{
+			"выключенные листы не проверяются",
+			[]SheetConfig{{SheetName: "Шаблон", Enabled: true}, {SheetName: "Озон.Видео", Enabled: false}},
+			nil,
+		},
+		{
+			"в файле нет части листов",
+			[]SheetConfig{
+				{SheetName: "Шаблон", Enabled: true},
+				{SheetName: "Озон.Видео", Enabled: true},
+				{SheetName: "Прайс", Enabled: true},
+			},
+			[]string{"Озон.Видео", "Прайс"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, err := analyzer.MissingSourceSheets(sourcePath, tt.sheets)
+			if err != nil {
+				t.Fatalf("MissingSourceSheets() error = %v", err)
+			}
+			if strings.Join(missing, ",") != strings.Join(tt.wantMissing, ",") {
+				t.Errorf("MissingSourceSheets() = %v, ожидалось %v", missing, tt.wantMissing)
+			}
+		})
+	}
+
+	if _, err := analyzer.MissingSourceSheets(filepath.Join(t.TempDir(), "нет.xlsx"), nil); err == nil {
+		t.Error("ожидалась ошибка для несуществующего файла")
+	}
+}
+
+// TestColumnStats тестирует сводку значений столбца для подбора фильтров
+func TestColumnStats(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	path := filepath.Join(t.TempDir(), "stats.xlsx")
+
+	exceltest.WriteWorkbook(t, path, "Шаблон", [][]string{
+		{"Отчет по товарам"},
+		{"Артикул", "Бренд", "Цена", "Дата"},
+		{"A1", "Shuzzi", "100", "01.02.2024"},
+		{"A2", "Other", "1 250,5", "2024-02-03"},
+		{"A3", " shuzzi ", "-20", ""},
+		{"", "", "", ""},
+		{"A4", "", "300", "05.02.2024"},
+		{"A5", "Shuzzi", "40", "06.02.2024"},
+		{"A6", "Nike", "50", "07.02.2024"},
+	})
+
+	analyzer := NewBaseAnalyzer(nil, logger)
+
+	tests := []struct {
+		name     string
+		column   int
+		topN     int
+		header   string
+		empty    int
+		distinct int
+		top      []ValueCount
+		typ      string
+		min, max float64
+	}{
+		{
+			name: "текст", column: 1, topN: 2, header: "Бренд", empty: 1, distinct: 3,
+			top: []ValueCount{{"Shuzzi", 3}, {"Other", 1}}, typ: ColumnTypeText,
+		},
+		{
+			name: "числа", column: 2, topN: 10, header: "Цена", empty: 0, distinct: 6,
+			typ: ColumnTypeNumber, min: -20, max: 1250.5,
+		},
+		{
+			name: "даты", column: 3, topN: 10, header: "Дата", empty: 1, distinct: 5, typ: ColumnTypeDate,
+		},
+		{
+			name: "столбец за пределами строк", column: 10, topN: 10, empty: 6, typ: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := analyzer.ColumnStats(path, "Шаблон", 2, tt.column, tt.topN)
+			if err != nil {
+				t.Fatalf("ошибка статистики: %v", err)
+			}
+			if stats.Header != tt.header || stats.TotalRows != 6 || stats.EmptyCount != tt.empty || stats.DistinctCount != tt.distinct {
+				t.Errorf("заголовок %q, строк %d, пустых %d, разных %d; ожидалось %q, 6, %d, %d",
+					stats.Header, stats.TotalRows, stats.EmptyCount, stats.DistinctCount, tt.header, tt.empty, tt.distinct)
+			}
+			if stats.Type != tt.typ || stats.Min != tt.min || stats.Max != tt.max {
+				t.Errorf("тип %q, диапазон %v..%v; ожидалось %q, %v..%v", stats.Type, stats.Min, stats.Max, tt.typ, tt.min, tt.max)
+			}
+			if tt.top != nil && !reflect.DeepEqual(stats.TopValues, tt.top) {
+				t.Errorf("частые значения = %v, ожидалось %v", stats.TopValues, tt.top)
+			}
+		})
+	}
+
+	if _, err := analyzer.ColumnStats(path, "Нет", 2, 0, 10); err == nil {
+		t.Error("ожидалась ошибка для отсутствующего листа")
+	}
+	if _, err := analyzer.ColumnStats(path, "Шаблон", 20, 0, 10); err == nil {
+		t.Error("ожидалась ошибка для строки заголовков за пределами листа")
+	}
+}
+
+// TestDistinctValues тестирует группировку значений столбца для выбора значений фильтра
+func TestDistinctValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   [][]string
+		column int
+		want   []ValueCount
+		empty  int
+	}{
+		{
+			name:  "регистр и пробелы по краям",
+			rows:  [][]string{{"shuzzi"}, {"Nike"}, {"SHUZZI"}, {" Shuzzi "}, {"nike"}},
+			want:  []ValueCount{{"shuzzi", 3}, {"Nike", 2}},
+			empty: 0,
+		},
+		{
+			name:  "одинаковое количество - порядок появления",
+			rows:  [][]string{{"B"}, {"A"}, {"C"}, {"a"}, {"b"}},
+			want:  []ValueCount{{"B", 2}, {"A", 2}, {"C", 1}},
+			empty: 0,
+		},
+		{
+			name:   "пустые и короткие строки",
+			rows:   [][]string{{"x", " "}, {"y"}, {"z", "Да"}, {"", "да"}},
+			column: 1,
+			want:   []ValueCount{{"Да", 2}},
+			empty:  2,
+		},
+		{
+			name:   "нет значений",
+			rows:   [][]string{{"x"}},
+			column: 1,
+			want:   nil,
+			empty:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, empty := distinctValues(tt.rows, tt.column)
+			if !reflect.DeepEqual(got, tt.want) || empty != tt.empty {
+				t.Errorf("distinctValues() = %v, пустых %d; ожидалось %v, %d", got, empty, tt.want, tt.empty)
+			}
+		})
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	path := filepath.Join(t.TempDir(), "values.xlsx")
+	rows := [][]string{{"Артикул", "Бренд"}}
+	for i := 0; i < 30; i++ {
+		rows = append(rows, []string{fmt.Sprintf("A%d", i), fmt.Sprintf("Бренд %d", i)})
+	}
+	rows = append(rows, []string{"A30", "бренд 0"})
+	exceltest.WriteWorkbook(t, path, "Лист1", rows)
+
+	values, err := NewBaseAnalyzer(nil, logger).DistinctValues(path, "Лист1", 1, 1)
+	if err != nil {
+		t.Fatalf("ошибка: %v", err)
+	}
+	// Все значения без ограничения сводки
+	if len(values) != 30 || values[0] != (ValueCount{"Бренд 0", 2}) {
+		t.Errorf("значений %d, первое %v", len(values), values[0])
+	}
+}
+
+// TestMatchFilterValues тестирует отметку значений текущего фильтра среди значений столбца
+func TestMatchFilterValues(t *testing.T) {
+	values := []ValueCount{{"Shuzzi", 3}, {"Nike", 2}, {"Adidas", 1}}
+
+	tests := []struct {
+		name        string
+		filter      []string
+		wantChecked []string
+		wantMissing []string
+	}{
+		{"без фильтра", nil, nil, nil},
+		{"регистр и пробелы", []string{" SHUZZI ", "adidas"}, []string{"Shuzzi", "Adidas"}, nil},
+		{"опечатка", []string{"Shuzi", "Nike", " "}, []string{"Nike"}, []string{"Shuzi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checked, missing := MatchFilterValues(values, tt.filter)
+			if !reflect.DeepEqual(checked, tt.wantChecked) || !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Errorf("MatchFilterValues() = %q, %q; ожидалось %q, %q", checked, missing, tt.wantChecked, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSheets(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	path := filepath.Join(t.TempDir(), "base.xlsx")
+	writeTestWorkbookSheets(t, path, []testSheet{
+		{name: "Шаблон", rows: [][]string{{"Отчет"}, {"Пояснение"}, {"Тип"}, {"Артикул", "Цена"}, {"A1", "100"}}},
+		{name: "Справка", rows: [][]string{{"Текст"}}},
+	})
+	analyzer := NewBaseAnalyzer(nil, logger)
+
+	tests := []struct {
+		name        string
+		headerRow   int
+		wantRow     int
+		wantHeaders [][]string
+	}{
+		{"строка по умолчанию", 1, 1, [][]string{{"Отчет"}, {"Текст"}}},
+		{"строка из настроек", 4, 4, [][]string{{"Артикул", "Цена"}, {}}},
+		{"некорректная строка", 0, 1, [][]string{{"Отчет"}, {"Текст"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheets, err := analyzer.AnalyzeSheets(path, tt.headerRow)
+			if err != nil {
+				t.Fatalf("AnalyzeSheets() error = %v", err)
+			}
+			if len(sheets) != 2 || sheets[0].SheetName != "Шаблон" || sheets[1].SheetName != "Справка" {
+				t.Fatalf("листы = %+v", sheets)
+			}
+			for i, sheet := range sheets {
+				if sheet.Enabled || sheet.HeaderRow != tt.wantRow {
+					t.Errorf("лист %s: включен = %v, строка заголовков = %d, ожидалась %d",
+						sheet.SheetName, sheet.Enabled, sheet.HeaderRow, tt.wantRow)
+				}
+				if sheet.Headers == nil || !reflect.DeepEqual(sheet.Headers, tt.wantHeaders[i]) {
+					t.Errorf("лист %s: заголовки = %q, ожидались %q", sheet.SheetName, sheet.Headers, tt.wantHeaders[i])
+				}
+			}
+		})
+	}
+}
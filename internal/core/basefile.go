@@ -0,0 +1,142 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// baseWorkbook строки листов базового файла, прочитанные один раз за объединение
+// Заголовки и данные всех листов берутся отсюда, без повторного открытия файла
+type baseWorkbook struct {
+	path   string
+	sheets map[string][][]string // Строки листов, найденных в файле
+	errs   map[string]error      // Ошибки чтения отдельных листов
+
+	ranges   map[string]excel.DataRange // Области данных листов с DataRangeName, найденные в файле
+	warnings []Warning                  // Диапазоны данных, не найденные в файле
+
+	conditionalFormats map[string]*excel.ConditionalFormats // Условное форматирование листов (только при переносе)
+}
+
+// loadBaseWorkbook открывает базовый файл и читает листы sheetNames с настройками из sheetConfigs
+// Каждый прочитанный лист - одна операция прогресса; settings - снимок настроек объединения
+func (m *Merger) loadBaseWorkbook(logger *slog.Logger, settings ProfileSettings, path string, sheetNames []string, sheetConfigs map[string]*SheetConfig, currentOp *int, totalOps int) (*baseWorkbook, error) {
+	reader, release, err := m.openReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть базовый файл: %w", err)
+	}
+	defer release()
+	m.recordFingerprints(path, reader)
+
+	base := &baseWorkbook{
+		path:   path,
+		sheets: make(map[string][][]string),
+		errs:   make(map[string]error),
+		ranges: make(map[string]excel.DataRange),
+
+		conditionalFormats: make(map[string]*excel.ConditionalFormats),
+	}
+
+	for i, sheetName := range sheetNames {
+		*currentOp++
+		m.notifyProgress(*currentOp, totalOps,
+			fmt.Sprintf("Чтение базового файла %s, лист %s (%d/%d)",
+				filepath.Base(path), sheetName, i+1, len(sheetNames)))
+
+		// Отсутствующий лист обнаружится при его обработке;
+		// имя может отличаться регистром и пробелами
+		actualName, ok := findSheet(reader.GetSheetNames(), sheetName)
+		if !ok {
+			continue
+		}
+
+		config := sheetConfigs[sheetName]
+		rows, err := reader.GetRows(actualName, excel.RowsOptions{RawCellFallback: config.RawCellFallback})
+		if err != nil {
+			base.errs[sheetName] = err
+			continue
+		}
+
+		// Область данных задает строку заголовков и ширину листа; без нее - строка заголовков из настроек
+		if config.DataRangeName != "" {
+			dataRange, err := findSheetDataRange(reader, actualName, config.DataRangeName)
+			if err != nil {
+				warning := newWarning(SeverityWarning, "лист '%s': %v в базовом файле, используется строка заголовков %d",
+					sheetName, err, config.HeaderRow)
+				base.warnings = append(base.warnings, warning)
+				logger.Warn(warning.Message, "sheet", sheetName, "data_range", config.DataRangeName)
+			} else {
+				base.ranges[sheetName] = dataRange
+				rows = truncateColumns(rows, dataRange.LastCol)
+				logger.Info("область данных листа из диапазона",
+					"sheet", sheetName,
+					"data_range", config.DataRangeName,
+					"range", dataRange.String(),
+					"header_row", dataRange.HeaderRow(),
+				)
+			}
+		}
+		base.sheets[sheetName] = rows
+
+		// Без условного форматирования лист объединяется как обычно: сообщаем и продолжаем
+		if settings.PreserveConditionalFormats {
+			formats, err := reader.GetConditionalFormats(actualName)
+			if err != nil {
+				warning := newWarning(SeverityWarning, "лист '%s': условное форматирование не перенесено: %v", sheetName, err)
+				base.warnings = append(base.warnings, warning)
+				logger.Warn(warning.Message, "sheet", sheetName)
+			} else if formats.Len() > 0 {
+				base.conditionalFormats[sheetName] = formats
+			}
+		}
+	}
+
+	logger.Info("базовый файл прочитан",
+		"file", filepath.Base(path),
+		"sheets_count", len(base.sheets),
+	)
+
+	return base, nil
+}
+
+// resolveConfigs возвращает настройки листов, где у листов с найденной областью данных
+// строка заголовков и ширина взяты из нее; настройки sheetConfigs не меняются
+func (b *baseWorkbook) resolveConfigs(sheetConfigs map[string]*SheetConfig) map[string]*SheetConfig {
+	if len(b.ranges) == 0 {
+		return sheetConfigs
+	}
+	resolved := make(map[string]*SheetConfig, len(sheetConfigs))
+	for name, config := range sheetConfigs {
+		resolved[name] = config
+		if dataRange, ok := b.ranges[name]; ok {
+			resolved[name] = withDataRange(config, dataRange)
+		}
+	}
+	return resolved
+}
+
+// rows возвращает все строки листа базового файла
+func (b *baseWorkbook) rows(sheetName string) ([][]string, error) {
+	if err := b.errs[sheetName]; err != nil {
+		return nil, apperrors.NewFileReadError(b.path, err, apperrors.WithContext("sheet", sheetName))
+	}
+
+	rows, ok := b.sheets[sheetName]
+	if !ok {
+		return nil, apperrors.NewSheetNotFoundError(sheetName, filepath.Base(b.path))
+	}
+	return rows, nil
+}
+
+// dataRows возвращает непустые строки данных листа после строки заголовков
+func (b *baseWorkbook) dataRows(sheetName string, headerRow int) [][]string {
+	rows := b.sheets[sheetName]
+	if len(rows) <= headerRow {
+		return [][]string{}
+	}
+	return filterEmptyRows(rows[headerRow:])
+}
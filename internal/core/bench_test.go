@@ -0,0 +1,153 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// Запуск: make bench или go test -run '^$' -bench . -benchmem ./internal/core
+// Метрики: rows/s - объединенных строк в секунду, peak-heap-MB - максимальный объем кучи за замер
+
+// BenchmarkMergeFiles измеряет объединение целиком: чтение базового файла и источников,
+// сопоставление столбцов и запись результата в книгу (без сохранения на диск)
+func BenchmarkMergeFiles(b *testing.B) {
+	sizes := []struct {
+		rows    int // Строк данных в каждом файле
+		columns int
+		files   int // Файлов вместе с базовым
+	}{
+		{1000, 10, 2},
+		{10000, 20, 5},
+		{50000, 20, 3},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, size := range sizes {
+		name := fmt.Sprintf("rows=%d/cols=%d/files=%d", size.rows, size.columns, size.files)
+		b.Run(name, func(b *testing.B) {
+			dir := b.TempDir()
+			rows := append([][]string{exceltest.BenchHeaders(size.columns)}, exceltest.BenchRows(size.rows, size.columns)...)
+
+			basePath := filepath.Join(dir, "base.xlsx")
+			exceltest.WriteWorkbook(b, basePath, "Data", rows)
+			sources := make([]string, size.files-1)
+			for i := range sources {
+				sources[i] = filepath.Join(dir, fmt.Sprintf("source%d.xlsx", i+1))
+				exceltest.WriteWorkbook(b, sources[i], "Data", rows)
+			}
+			sheetConfigs := map[string]*SheetConfig{
+				"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+			}
+			totalRows := size.rows * size.files
+
+			b.ReportAllocs()
+			heap := exceltest.StartPeakHeap()
+			for b.Loop() {
+				result, err := NewMerger(nil, logger).MergeFiles(basePath, sources, sheetConfigs)
+				if err != nil {
+					b.Fatalf("ошибка при объединении файлов: %v", err)
+				}
+				if result.TotalRows != totalRows {
+					b.Fatalf("TotalRows = %d, ожидалось %d", result.TotalRows, totalRows)
+				}
+				result.Close()
+			}
+			exceltest.ReportThroughput(b, totalRows, heap.Stop())
+		})
+	}
+}
+
+// BenchmarkMergeFilesOzonPreset измеряет объединение трех листов шаблона Ozon
+// с фильтрацией по артикулам листа "Шаблон"; opens/op - открытий файлов за объединение
+func BenchmarkMergeFilesOzonPreset(b *testing.B) {
+	const rowsPerSheet, columns, files = 5000, 20, 3
+	sheetNames := []string{"Шаблон", "Озон.Видео", "Озон.Видеообложка"}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := b.TempDir()
+
+	// Как в выгрузке Ozon: три строки пояснений над заголовками в строке 4
+	preamble := [][]string{{"Инструкция"}, {"Обязательные поля"}, {""}}
+	sheetRows := append(preamble, exceltest.BenchHeaders(columns))
+	sheetRows = append(sheetRows, exceltest.BenchRows(rowsPerSheet, columns)...)
+	sheets := make([]testSheet, len(sheetNames))
+	sheetConfigs := make(map[string]*SheetConfig, len(sheetNames))
+	for i, name := range sheetNames {
+		sheets[i] = testSheet{name, sheetRows}
+		sheetConfigs[name] = &SheetConfig{
+			SheetName:           name,
+			Enabled:             true,
+			HeaderRow:           4,
+			FilterColumn:        -1,
+			UseTemplateArticles: !IsTemplateSheet(name),
+		}
+	}
+
+	basePath := filepath.Join(dir, "base.xlsx")
+	writeTestWorkbookSheets(b, basePath, sheets)
+	sources := make([]string, files-1)
+	for i := range sources {
+		sources[i] = filepath.Join(dir, fmt.Sprintf("source%d.xlsx", i+1))
+		writeTestWorkbookSheets(b, sources[i], sheets)
+	}
+	totalRows := rowsPerSheet * len(sheetNames) * files
+
+	opens := 0
+	b.ReportAllocs()
+	heap := exceltest.StartPeakHeap()
+	for b.Loop() {
+		merger := NewMerger(nil, logger)
+		merger.openReader = func(path string) (*excel.Reader, func(), error) {
+			opens++
+			return (*excel.ReaderPool)(nil).Borrow(path)
+		}
+		result, err := merger.MergeFiles(basePath, sources, sheetConfigs)
+		if err != nil {
+			b.Fatalf("ошибка при объединении файлов: %v", err)
+		}
+		if result.TotalRows != totalRows {
+			b.Fatalf("TotalRows = %d, ожидалось %d", result.TotalRows, totalRows)
+		}
+		result.Close()
+	}
+	exceltest.ReportThroughput(b, totalRows, heap.Stop())
+	b.ReportMetric(float64(opens)/float64(b.N), "opens/op")
+}
+
+// BenchmarkFilterRows измеряет фильтрацию строк в памяти: пропуск пустых строк,
+// фильтр по значению столбца бренда и фильтр по артикулам листа "Шаблон"
+func BenchmarkFilterRows(b *testing.B) {
+	const rowsCount, columns, brandColumn = 300000, 20, 2
+	brands := []string{"Зима", "ЛЕТО", " осень ", "Весна"}
+
+	rows := exceltest.BenchRows(rowsCount, columns)
+	articles := make(map[string]bool, rowsCount/2)
+	for i, row := range rows {
+		row[brandColumn] = brands[i%len(brands)]
+		if i%10 == 0 {
+			rows[i] = make([]string, columns) // Пустая строка
+		}
+		if i%2 == 0 {
+			articles[row[0]] = true
+		}
+	}
+	filterValues := []string{"зима", "Осень"}
+
+	b.ReportAllocs()
+	heap := exceltest.StartPeakHeap()
+	for b.Loop() {
+		data := filterEmptyRows(rows)
+		data = filterRowsByColumnValue(data, brandColumn, filterValues)
+		data = filterRowsByArticleColumn(data, 0, articles, nil)
+		if len(data) == 0 {
+			b.Fatal("фильтры исключили все строки")
+		}
+	}
+	exceltest.ReportThroughput(b, rowsCount, heap.Stop())
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// valueTally считает записанные в результат строки листа по значениям одного столбца
+// Значения сравниваются так же, как при фильтрации: без учета регистра и пробелов по краям
+type valueTally struct {
+	header string         // Заголовок столбца
+	column int            // 0-based индекс столбца в строках результата
+	fixed  bool           // Считаются только заранее заданные значения (значения фильтра)
+	index  map[string]int // Позиция значения в counts по ключу в нижнем регистре
+	counts []ValueCount
+}
+
+// newSheetTally создает подсчет строк листа: по значениям фильтра, если он задан,
+// иначе по столбцу GroupByColumn. nil - разбивка не нужна или столбец не найден
+func newSheetTally(config *SheetConfig, sheetName string, headers []string) (*valueTally, []Warning) {
+	if config.FilterColumn >= 0 && len(config.FilterValues) > 0 {
+		header := ""
+		if config.FilterColumn < len(headers) {
+			header = strings.TrimSpace(headers[config.FilterColumn])
+		}
+		tally := &valueTally{header: header, column: config.FilterColumn, fixed: true, index: make(map[string]int)}
+		for _, value := range config.FilterValues {
+			value = strings.TrimSpace(value)
+			key := strings.ToLower(value)
+			if _, ok := tally.index[key]; ok || value == "" {
+				continue
+			}
+			tally.index[key] = len(tally.counts)
+			tally.counts = append(tally.counts, ValueCount{Value: value})
+		}
+		return tally, nil
+	}
+
+	groupHeader := strings.TrimSpace(config.GroupByColumn)
+	if groupHeader == "" {
+		return nil, nil
+	}
+	column := columnIndexByHeader(headers, groupHeader)
+	if column < 0 {
+		return nil, []Warning{newWarning(SeverityInfo,
+			"лист '%s': столбец '%s' для разбивки итогов не найден", sheetName, groupHeader)}
+	}
+	return &valueTally{header: strings.TrimSpace(headers[column]), column: column, index: make(map[string]int)}, nil
+}
+
+// add учитывает строки rows; для nil подсчета ничего не делает
+// Строки со значением не из фильтра (например, строки базового листа, оставленные как есть) не учитываются
+func (t *valueTally) add(rows [][]string) {
+	if t == nil {
+		return
+	}
+	for _, row := range rows {
+		value := ""
+		if t.column < len(row) {
+			value = strings.TrimSpace(row[t.column])
+		}
+		key := strings.ToLower(value)
+		if i, ok := t.index[key]; ok {
+			t.counts[i].Count++
+			continue
+		}
+		if t.fixed {
+			continue
+		}
+		// Значение записывается в том виде, в каком встретилось первым
+		t.index[key] = len(t.counts)
+		t.counts = append(t.counts, ValueCount{Value: value, Count: 1})
+	}
+}
+
+// result возвращает количества по убыванию; одинаковые идут в порядке фильтра или первого появления
+func (t *valueTally) result() []ValueCount {
+	counts := append([]ValueCount(nil), t.counts...)
+	sort.SliceStable(counts, func(i, j int) bool { return counts[i].Count > counts[j].Count })
+	return counts
+}
+
+// sheetStat собирает статистику листа sheetName с разбивкой строк по значениям, если она велась
+func (m *Merger) sheetStat(sheetName string, rowsMerged, filesCount int) *SheetStat {
+	stat := &SheetStat{RowsMerged: rowsMerged, FilesCount: filesCount}
+	if tally, ok := m.breakdowns[sheetName]; ok {
+		stat.GroupColumn = tally.header
+		stat.ValueCounts = tally.result()
+	}
+	return stat
+}
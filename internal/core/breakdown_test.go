@@ -0,0 +1,93 @@
+package core
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+func TestMergeFilesValueBreakdown(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	first := filepath.Join(dir, "first.xlsx")
+	second := filepath.Join(dir, "second.xlsx")
+
+	exceltest.WriteWorkbook(t, basePath, "Товары", [][]string{{"Артикул", "Бренд"}})
+	// Shuzzi: 2 + 1, Other: 1 + 2, пустой бренд: 1
+	exceltest.WriteWorkbook(t, first, "Товары", [][]string{
+		{"Артикул", "Бренд"},
+		{"A1", "Shuzzi"}, {"A2", "Other"}, {"A3", " shuzzi "},
+	})
+	exceltest.WriteWorkbook(t, second, "Товары", [][]string{
+		{"Артикул", "Бренд"},
+		{"B1", "OTHER"}, {"B2", "Shuzzi"}, {"B3", "Other"}, {"B4"},
+	})
+
+	tests := []struct {
+		name       string
+		config     SheetConfig
+		wantRows   int
+		wantColumn string
+		want       []ValueCount
+	}{
+		{
+			name: "по значениям фильтра",
+			// Повтор значения фильтра считается один раз, несовпавшее значение - с нулем
+			config:     SheetConfig{FilterColumn: 1, FilterValues: []string{"Other", "shuzzi", "SHUZZI", "Nike"}, GroupByColumn: "Артикул"},
+			wantRows:   6,
+			wantColumn: "Бренд",
+			want:       []ValueCount{{"Other", 3}, {"shuzzi", 3}, {"Nike", 0}},
+		},
+		{
+			name:       "по столбцу разбивки",
+			config:     SheetConfig{FilterColumn: -1, GroupByColumn: " бренд "},
+			wantRows:   7,
+			wantColumn: "Бренд",
+			want:       []ValueCount{{"Shuzzi", 3}, {"Other", 3}, {"", 1}},
+		},
+		{
+			name:     "без разбивки",
+			config:   SheetConfig{FilterColumn: -1},
+			wantRows: 7,
+		},
+		{
+			name:     "столбец разбивки не найден",
+			config:   SheetConfig{FilterColumn: -1, GroupByColumn: "Цвет"},
+			wantRows: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.SheetName = "Товары"
+			config.Enabled = true
+			config.HeaderRow = 1
+			result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{first, second},
+				map[string]*SheetConfig{"Товары": &config})
+			if err != nil {
+				t.Fatalf("MergeFiles() error = %v", err)
+			}
+			defer result.Close()
+
+			stat := result.SheetStats["Товары"]
+			if stat == nil {
+				t.Fatal("нет статистики листа 'Товары'")
+			}
+			if stat.RowsMerged != tt.wantRows {
+				t.Errorf("RowsMerged = %d, ожидалось %d", stat.RowsMerged, tt.wantRows)
+			}
+			if stat.GroupColumn != tt.wantColumn {
+				t.Errorf("GroupColumn = %q, ожидалось %q", stat.GroupColumn, tt.wantColumn)
+			}
+			if !reflect.DeepEqual(stat.ValueCounts, tt.want) {
+				t.Errorf("ValueCounts = %+v, ожидалось %+v", stat.ValueCounts, tt.want)
+			}
+		})
+	}
+}
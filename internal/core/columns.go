@@ -0,0 +1,249 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConstantColumn столбец с фиксированным значением, добавляемый к каждой строке результата
+type ConstantColumn struct {
+	Header string `json:"header"` // Заголовок столбца
+	Value  string `json:"value"`  // Значение во всех строках данных
+}
+
+// appendConstantColumns добавляет постоянные столбцы после первых width столбцов строки
+// Короткая строка дополняется пустыми ячейками; ячейки за пределами width
+// (без заголовка) сохраняются после постоянных столбцов
+func appendConstantColumns(row []string, width int, columns []ConstantColumn, header bool) []string {
+	if len(columns) == 0 {
+		return row
+	}
+
+	result := make([]string, width, max(width, len(row))+len(columns))
+	copy(result, row)
+	for _, column := range columns {
+		if header {
+			result = append(result, column.Header)
+		} else {
+			result = append(result, column.Value)
+		}
+	}
+	if len(row) > width {
+		result = append(result, row[width:]...)
+	}
+	return result
+}
+
+// resolveRawTextColumns находит столбцы без преобразований по заголовкам листа результата
+// Для заголовков, которых нет на листе, возвращаются сведения-предупреждения
+func resolveRawTextColumns(config *SheetConfig, sheetName string, headers []string) (map[int]bool, []Warning) {
+	if len(config.RawTextColumns) == 0 {
+		return nil, nil
+	}
+
+	var warnings []Warning
+	columns := make(map[int]bool, len(config.RawTextColumns))
+	for _, header := range config.RawTextColumns {
+		column := columnIndexByHeader(headers, header)
+		if column < 0 {
+			warnings = append(warnings, newWarning(SeverityInfo,
+				"лист '%s': столбец '%s' для переноса без преобразований не найден", sheetName, header))
+			continue
+		}
+		columns[column] = true
+	}
+	return columns, warnings
+}
+
+// withoutColumns возвращает типы столбцов без столбцов excluded
+func withoutColumns(types map[int]string, excluded map[int]bool) map[int]string {
+	if len(excluded) == 0 || len(types) == 0 {
+		return types
+	}
+
+	result := make(map[int]string, len(types))
+	for col, columnType := range types {
+		if !excluded[col] {
+			result[col] = columnType
+		}
+	}
+	return result
+}
+
+// normalizeMapValue приводит значение к виду для сравнения с ключами ValueMaps
+func normalizeMapValue(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// newValueMapper готовит замены значений по столбцам с нормализованными ключами
+// Столбцы без преобразований raw пропускаются: их значения переносятся как есть
+func newValueMapper(valueMaps map[int]map[string]string, raw map[int]bool) map[int]map[string]string {
+	if len(valueMaps) == 0 {
+		return nil
+	}
+
+	mapper := make(map[int]map[string]string, len(valueMaps))
+	for col, values := range valueMaps {
+		if col < 0 || len(values) == 0 || raw[col] {
+			continue
+		}
+		normalized := make(map[string]string, len(values))
+		for from, to := range values {
+			normalized[normalizeMapValue(from)] = to
+		}
+		mapper[col] = normalized
+	}
+	return mapper
+}
+
+// rawValueMapWarnings предупреждает о заменах значений, заданных для столбцов без преобразований raw:
+// такие замены не применяются
+func rawValueMapWarnings(sheetName string, headers []string, valueMaps map[int]map[string]string, raw map[int]bool) []Warning {
+	var warnings []Warning
+	for col := range raw {
+		if len(valueMaps[col]) == 0 {
+			continue
+		}
+		warnings = append(warnings, newWarning(SeverityWarning,
+			"лист '%s': столбец '%s' переносится без преобразований, замены значений к нему не применяются",
+			sheetName, headers[col]))
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Message < warnings[j].Message })
+	return warnings
+}
+
+// mapValues заменяет значения столбцов строк по подготовленным newValueMapper заменам
+// Значения без замены не изменяются. Возвращает количество замененных значений
+func mapValues(rows [][]string, mapper map[int]map[string]string) int {
+	if len(mapper) == 0 {
+		return 0
+	}
+
+	replaced := 0
+	for _, row := range rows {
+		for col, values := range mapper {
+			if col >= len(row) {
+				continue
+			}
+			if to, ok := values[normalizeMapValue(row[col])]; ok && row[col] != to {
+				row[col] = to
+				replaced++
+			}
+		}
+	}
+	return replaced
+}
+
+// ParseHeaderList разбирает список заголовков через точку с запятой
+func ParseHeaderList(spec string) []string {
+	var headers []string
+	for _, header := range strings.Split(spec, ";") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
+
+// ParseConstantColumns разбирает описание постоянных столбцов вида "Категория=Обувь; Кампания=SALE-25"
+func ParseConstantColumns(spec string) ([]ConstantColumn, error) {
+	var columns []ConstantColumn
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		header, value, ok := strings.Cut(part, "=")
+		header = strings.TrimSpace(header)
+		if !ok || header == "" {
+			return nil, fmt.Errorf("некорректное описание столбца '%s', ожидается вид Заголовок=Значение", part)
+		}
+		columns = append(columns, ConstantColumn{Header: header, Value: strings.TrimSpace(value)})
+	}
+	return columns, nil
+}
+
+// FormatConstantColumns формирует описание постоянных столбцов для ParseConstantColumns
+func FormatConstantColumns(columns []ConstantColumn) string {
+	parts := make([]string, 0, len(columns))
+	for _, column := range columns {
+		parts = append(parts, column.Header+"="+column.Value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ParseValueMaps разбирает замены значений по столбцам вида "F: да=Yes, 1=Yes; G: нет=No"
+func ParseValueMaps(spec string) (map[int]map[string]string, error) {
+	valueMaps := make(map[int]map[string]string)
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		letter, pairs, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("некорректное описание замен '%s', ожидается вид F: да=Yes, нет=No", part)
+		}
+		col, err := columnLetterToIndex(strings.TrimSpace(letter))
+		if err != nil {
+			return nil, err
+		}
+
+		values := valueMaps[col]
+		if values == nil {
+			values = make(map[string]string)
+			valueMaps[col] = values
+		}
+		for _, pair := range strings.Split(pairs, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			from, to, ok := strings.Cut(pair, "=")
+			from = strings.TrimSpace(from)
+			if !ok || from == "" {
+				return nil, fmt.Errorf("некорректная замена '%s' в столбце %s, ожидается вид да=Yes",
+					strings.TrimSpace(pair), strings.TrimSpace(letter))
+			}
+			values[from] = strings.TrimSpace(to)
+		}
+	}
+
+	for col, values := range valueMaps {
+		if len(values) == 0 {
+			delete(valueMaps, col)
+		}
+	}
+	if len(valueMaps) == 0 {
+		return nil, nil
+	}
+	return valueMaps, nil
+}
+
+// FormatValueMaps формирует описание замен значений для ParseValueMaps
+func FormatValueMaps(valueMaps map[int]map[string]string) string {
+	columns := make([]int, 0, len(valueMaps))
+	for col := range valueMaps {
+		columns = append(columns, col)
+	}
+	sort.Ints(columns)
+
+	parts := make([]string, 0, len(columns))
+	for _, col := range columns {
+		values := valueMaps[col]
+		froms := make([]string, 0, len(values))
+		for from := range values {
+			froms = append(froms, from)
+		}
+		sort.Strings(froms)
+
+		pairs := make([]string, 0, len(froms))
+		for _, from := range froms {
+			pairs = append(pairs, from+"="+values[from])
+		}
+		parts = append(parts, ColumnIndexToLetter(col)+": "+strings.Join(pairs, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
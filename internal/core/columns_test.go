@@ -0,0 +1,137 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendConstantColumns(t *testing.T) {
+	columns := []ConstantColumn{{Header: "Категория", Value: "Обувь"}, {Header: "Кампания", Value: "SALE"}}
+
+	tests := []struct {
+		name   string
+		row    []string
+		width  int
+		header bool
+		want   []string
+	}{
+		{"заголовок", []string{"Артикул", "Цена"}, 2, true, []string{"Артикул", "Цена", "Категория", "Кампания"}},
+		{"полная строка", []string{"A1", "100"}, 2, false, []string{"A1", "100", "Обувь", "SALE"}},
+		{"короткая строка", []string{"A1"}, 2, false, []string{"A1", "", "Обувь", "SALE"}},
+		{"ячейки без заголовка", []string{"A1", "100", "лишнее"}, 2, false, []string{"A1", "100", "Обувь", "SALE", "лишнее"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := appendConstantColumns(tt.row, tt.width, columns, tt.header)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("appendConstantColumns() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseHeaderList тестирует разбор списка заголовков столбцов без преобразований
+func TestParseHeaderList(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"", nil},
+		{" ; ", nil},
+		{"Озон.Видео: ссылка; Код ", []string{"Озон.Видео: ссылка", "Код"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseHeaderList(tt.spec); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseHeaderList(%q) = %q, ожидалось %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseConstantColumns(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []ConstantColumn
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"Категория=Обувь; Код = A=1 ", []ConstantColumn{{"Категория", "Обувь"}, {"Код", "A=1"}}, false},
+		{"Пусто=", []ConstantColumn{{"Пусто", ""}}, false},
+		{"Категория", nil, true},
+		{"=Обувь", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseConstantColumns(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseConstantColumns(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseConstantColumns(%q) = %v, ожидалось %v", tt.spec, got, tt.want)
+		}
+		if back, _ := ParseConstantColumns(FormatConstantColumns(got)); !reflect.DeepEqual(back, got) {
+			t.Errorf("FormatConstantColumns(%v) не разбирается обратно: %v", got, back)
+		}
+	}
+}
+
+func TestParseValueMaps(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    map[int]map[string]string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"F: да=Yes, 1=Yes; G: нет = No", map[int]map[string]string{
+			5: {"да": "Yes", "1": "Yes"},
+			6: {"нет": "No"},
+		}, false},
+		{"b: пусто=", map[int]map[string]string{1: {"пусто": ""}}, false},
+		{"F:", nil, false},
+		{"да=Yes", nil, true},
+		{"F: да", nil, true},
+		{"F: =Yes", nil, true},
+		{"1: да=Yes", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseValueMaps(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseValueMaps(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseValueMaps(%q) = %v, ожидалось %v", tt.spec, got, tt.want)
+		}
+		if back, _ := ParseValueMaps(FormatValueMaps(got)); !reflect.DeepEqual(back, got) {
+			t.Errorf("FormatValueMaps(%v) не разбирается обратно: %v", got, back)
+		}
+	}
+}
+
+func TestMapValues(t *testing.T) {
+	mapper := newValueMapper(map[int]map[string]string{
+		1:  {"Да": "Yes", " 1 ": "Yes", "false": "No"},
+		5:  {"x": "y"},
+		-1: {"a": "b"},
+		0:  {"a1": "Z"},
+	}, map[int]bool{0: true})
+	rows := [][]string{
+		{"A1", "да"},
+		{"A2", "1"},
+		{"A3", "FALSE"},
+		{"A4", "Yes"},
+		{"A5", "11"},
+		{"A6"},
+	}
+
+	if replaced := mapValues(rows, mapper); replaced != 3 {
+		t.Errorf("заменено значений = %d, ожидалось 3", replaced)
+	}
+	want := [][]string{{"A1", "Yes"}, {"A2", "Yes"}, {"A3", "No"}, {"A4", "Yes"}, {"A5", "11"}, {"A6"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("строки = %q, ожидалось %q", rows, want)
+	}
+}
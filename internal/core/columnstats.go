@@ -0,0 +1,181 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// ValueCount значение столбца и количество строк с ним
+type ValueCount struct {
+	Value string
+	Count int
+}
+
+// ColumnStats сводка значений столбца для подбора фильтров
+// Значения сравниваются так же, как при фильтрации: без учета регистра и пробелов по краям
+type ColumnStats struct {
+	Header        string       // Заголовок столбца
+	TotalRows     int          // Непустые строки данных листа
+	EmptyCount    int          // Строки, где ячейка столбца пуста
+	DistinctCount int          // Количество разных непустых значений
+	TopValues     []ValueCount // Самые частые значения, не более topN, по убыванию количества
+	Type          string       // Тип столбца: ColumnTypeNumber, ColumnTypeDate, ColumnTypeText (пусто - нет значений)
+	Min           float64      // Минимум для числового столбца
+	Max           float64      // Максимум для числового столбца
+}
+
+// ColumnStats возвращает сводку значений 0-based столбца column листа sheetName:
+// частые значения (не более topN), количество пустых ячеек, тип и диапазон чисел
+func (a *BaseAnalyzer) ColumnStats(filePath, sheetName string, headerRow, column, topN int) (*ColumnStats, error) {
+	reader, release, err := a.borrowReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if !reader.SheetExists(sheetName) {
+		return nil, fmt.Errorf("лист '%s' не найден", sheetName)
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("некорректный номер столбца: %d", column)
+	}
+
+	rows, err := reader.GetRows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать лист: %w", err)
+	}
+
+	if headerRow < 1 || headerRow > len(rows) {
+		return nil, fmt.Errorf("лист '%s' содержит только %d строк, но указана строка заголовков %d",
+			sheetName, len(rows), headerRow)
+	}
+
+	header := ""
+	if column < len(rows[headerRow-1]) {
+		header = rows[headerRow-1][column]
+	}
+	stats := columnStats(header, filterEmptyRows(rows[headerRow:]), column, topN)
+
+	a.log().Info("статистика столбца",
+		"sheet", sheetName,
+		"column", ColumnIndexToLetter(column),
+		"distinct", stats.DistinctCount,
+		"empty", stats.EmptyCount,
+		"type", stats.Type,
+	)
+
+	return stats, nil
+}
+
+// DistinctValues возвращает все разные непустые значения 0-based столбца column листа sheetName
+// с количеством строк, по убыванию количества. Значения группируются так же, как сравниваются
+// при фильтрации: "Shuzzi", "shuzzi" и " SHUZZI " - одно значение в записи, встреченной первой
+func (a *BaseAnalyzer) DistinctValues(filePath, sheetName string, headerRow, column int) ([]ValueCount, error) {
+	stats, err := a.ColumnStats(filePath, sheetName, headerRow, column, -1)
+	if err != nil {
+		return nil, err
+	}
+	return stats.TopValues, nil
+}
+
+// columnStats считает сводку столбца column по строкам данных; topN < 0 - все значения
+func columnStats(header string, dataRows [][]string, column, topN int) *ColumnStats {
+	values, empty := distinctValues(dataRows, column)
+	stats := &ColumnStats{
+		Header:        header,
+		TotalRows:     len(dataRows),
+		EmptyCount:    empty,
+		DistinctCount: len(values),
+	}
+
+	numeric, dates := true, true
+	for i, value := range values {
+		if number, ok := excel.ParseNumber(value.Value); ok {
+			if i == 0 || number < stats.Min {
+				stats.Min = number
+			}
+			if i == 0 || number > stats.Max {
+				stats.Max = number
+			}
+			dates = false
+		} else {
+			numeric = false
+			if dates && !isDateText(value.Value) {
+				dates = false
+			}
+		}
+	}
+
+	switch {
+	case len(values) == 0:
+		stats.Type = ""
+	case numeric:
+		stats.Type = ColumnTypeNumber
+	case dates:
+		stats.Type = ColumnTypeDate
+	default:
+		stats.Type = ColumnTypeText
+	}
+	if stats.Type != ColumnTypeNumber {
+		stats.Min, stats.Max = 0, 0
+	}
+
+	if topN >= 0 && len(values) > topN {
+		values = values[:topN]
+	}
+	stats.TopValues = values
+	return stats
+}
+
+// distinctValues группирует непустые значения столбца column без учета регистра и пробелов
+// по краям и возвращает их по убыванию количества строк, а также количество пустых ячеек
+// Значения с одинаковым количеством идут в порядке первого появления
+func distinctValues(dataRows [][]string, column int) (values []ValueCount, empty int) {
+	indexByKey := make(map[string]int)
+	for _, row := range dataRows {
+		value := ""
+		if column < len(row) {
+			value = strings.TrimSpace(row[column])
+		}
+		if value == "" {
+			empty++
+			continue
+		}
+
+		key := strings.ToLower(value)
+		if i, ok := indexByKey[key]; ok {
+			values[i].Count++
+			continue
+		}
+		indexByKey[key] = len(values)
+		values = append(values, ValueCount{Value: value, Count: 1})
+	}
+
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Count > values[j].Count })
+	return values, empty
+}
+
+// MatchFilterValues сопоставляет значения фильтра filterValues со значениями столбца values:
+// checked - записи values, которые отмечены фильтром, missing - значения фильтра, которых нет
+// в данных (например, опечатка, из-за которой фильтр не оставит ни одной строки)
+func MatchFilterValues(values []ValueCount, filterValues []string) (checked, missing []string) {
+	matched := make(map[string]bool, len(filterValues))
+	for _, value := range values {
+		for _, filterValue := range filterValues {
+			if strings.EqualFold(strings.TrimSpace(filterValue), value.Value) {
+				checked = append(checked, value.Value)
+				matched[filterValue] = true
+				break
+			}
+		}
+	}
+	for _, filterValue := range filterValues {
+		if !matched[filterValue] && strings.TrimSpace(filterValue) != "" {
+			missing = append(missing, filterValue)
+		}
+	}
+	return checked, missing
+}
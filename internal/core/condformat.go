@@ -0,0 +1,40 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// applyConditionalFormats переносит условное форматирование листов базового файла на заполненные листы результата
+// Лист результата получает правила листа, который его создал; области продлеваются до последней строки данных
+func (m *Merger) applyConditionalFormats(logger *slog.Logger, writer *excel.Writer, base *baseWorkbook, sheetConfigs map[string]*SheetConfig) error {
+	if len(base.conditionalFormats) == 0 {
+		return nil
+	}
+
+	outputNames := make([]string, 0, len(m.outputs))
+	for name := range m.outputs {
+		outputNames = append(outputNames, name)
+	}
+	sort.Strings(outputNames)
+
+	for _, outputName := range outputNames {
+		sheetName := m.outputs[outputName].firstSheet
+		formats := base.conditionalFormats[sheetName]
+		if formats == nil {
+			continue
+		}
+		if err := writer.ApplyConditionalFormats(outputName, formats, sheetConfigs[sheetName].HeaderRow); err != nil {
+			return fmt.Errorf("не удалось перенести условное форматирование листа '%s': %w", sheetName, err)
+		}
+		logger.Info("перенесено условное форматирование",
+			"sheet", sheetName,
+			"output_sheet", outputName,
+			"ranges", formats.Len(),
+		)
+	}
+	return nil
+}
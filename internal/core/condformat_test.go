@@ -0,0 +1,101 @@
+package core
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// addConditionalFormat добавляет в сохраненную книгу path правило "значение < 5" с красной заливкой
+func addConditionalFormat(t *testing.T, path, sheet, ref string) {
+	t.Helper()
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	format, err := f.NewConditionalStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetConditionalFormat(sheet, ref, []excelize.ConditionalFormatOptions{
+		{Type: "cell", Criteria: "<", Value: "5", Format: &format},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMergeFilesPreservesConditionalFormats(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	first := filepath.Join(dir, "first.xlsx")
+	second := filepath.Join(dir, "second.xlsx")
+
+	exceltest.WriteWorkbook(t, basePath, "Остатки", [][]string{{"Артикул", "Остаток"}, {"A1", "3"}, {"A2", "10"}})
+	addConditionalFormat(t, basePath, "Остатки", "B2:B3")
+	exceltest.WriteWorkbook(t, first, "Остатки", [][]string{{"Артикул", "Остаток"}, {"B1", "1"}, {"B2", "20"}})
+	exceltest.WriteWorkbook(t, second, "Остатки", [][]string{{"Артикул", "Остаток"}, {"C1", "4"}, {"C2", "7"}})
+
+	tests := []struct {
+		name     string
+		settings ProfileSettings
+		wantRefs []string
+	}{
+		{"перенос выключен", ProfileSettings{}, nil},
+		{"новая книга", ProfileSettings{PreserveConditionalFormats: true}, []string{"B2:B7"}},
+		// Правило копии базового файла заменяется продленным, а не дублируется
+		{"копия базового файла", ProfileSettings{PreserveConditionalFormats: true, MergeIntoBase: true}, []string{"B2:B7"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merger := NewMerger(nil, logger)
+			merger.SetSettings(tt.settings)
+			config := &SheetConfig{SheetName: "Остатки", Enabled: true, HeaderRow: 1, FilterColumn: -1}
+			result, err := merger.MergeFiles(basePath, []string{first, second}, map[string]*SheetConfig{"Остатки": config})
+			if err != nil {
+				t.Fatalf("MergeFiles() error = %v", err)
+			}
+			defer result.Close()
+
+			file := result.WorkbookData.GetFile()
+			formats, err := file.GetConditionalFormats("Остатки")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var refs []string
+			for ref := range formats {
+				refs = append(refs, ref)
+			}
+			sort.Strings(refs)
+			if !reflect.DeepEqual(refs, tt.wantRefs) {
+				t.Fatalf("области условного форматирования = %q, ожидалось %q", refs, tt.wantRefs)
+			}
+
+			for _, ref := range refs {
+				rules := formats[ref]
+				if len(rules) != 1 || rules[0].Criteria != "less than" || rules[0].Value != "5" || rules[0].Format == nil {
+					t.Fatalf("правила %s = %+v", ref, rules)
+				}
+				style, err := file.GetConditionalStyle(*rules[0].Format)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(style.Fill.Color) == 0 || style.Fill.Color[0] != "FFC7CE" {
+					t.Errorf("заливка правила %s = %+v, ожидалась FFC7CE", ref, style.Fill)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,14 @@
 package core
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
 
 // Profile представляет сохраненный профиль настроек
 type Profile struct {
@@ -15,22 +23,128 @@ type Profile struct {
 
 // SheetConfig настройки для одного листа
 type SheetConfig struct {
-	SheetName           string   `json:"sheet_name"`
-	Enabled             bool     `json:"enabled"`
-	HeaderRow           int      `json:"header_row"` // 1-based index
-	Headers             []string `json:"headers"`
-	FilterColumn        int      `json:"filter_column,omitempty"`         // 0-based column index для фильтрации (0 = не используется)
-	FilterValues        []string `json:"filter_values,omitempty"`         // Значения для исключения из результата
-	UseTemplateArticles bool     `json:"use_template_articles,omitempty"` // Фильтровать по артикулам из листа "Шаблон" (для Ozon пресета)
+	SheetName           string           `json:"sheet_name"`
+	Enabled             bool             `json:"enabled"`
+	HeaderRow           int              `json:"header_row"` // 1-based index
+	Headers             []string         `json:"headers"`
+	FilterColumn        int              `json:"filter_column,omitempty"`         // 0-based column index для фильтрации (0 = не используется)
+	FilterValues        []string         `json:"filter_values,omitempty"`         // Значения для исключения из результата
+	UseTemplateArticles bool             `json:"use_template_articles,omitempty"` // Фильтровать по артикулам из листа "Шаблон" (для Ozon пресета)
+	TabColor            string           `json:"tab_color,omitempty"`             // Цвет ярлыка листа в результате (#RRGGBB)
+	ColumnTypes         map[int]string   `json:"column_types,omitempty"`          // Ожидаемые типы столбцов по 0-based индексу: number, date или text
+	ConstantColumns     []ConstantColumn `json:"constant_columns,omitempty"`      // Столбцы с фиксированным значением, добавляемые справа
+	OutputSheet         string           `json:"output_sheet,omitempty"`          // Лист результата; листы с одинаковым значением объединяются в один
+	SourceSheetNames    []string         `json:"source_sheet_names,omitempty"`    // Другие имена этого листа в файлах-источниках, например "Прайс", "Price"
+	SourceSheetPattern  string           `json:"source_sheet_pattern,omitempty"`  // Часть имени листа в файлах-источниках, если лист не найден по имени, например "прайс"
+	TypeDescriptorRow   int              `json:"type_descriptor_row,omitempty"`   // 1-based строка шапки с описанием типов полей (0 = не используется)
+
+	// Именованный диапазон или таблица Excel с областью данных листа, например "ШаблонДанные".
+	// Строка заголовков и ширина данных берутся из диапазона вместо HeaderRow; если диапазон
+	// не найден или некорректен, используется HeaderRow с предупреждением
+	DataRangeName string `json:"data_range_name,omitempty"`
+
+	// dataColumns ширина области данных из DataRangeName (0 - без ограничения); задается при объединении
+	dataColumns int
+
+	// Склейка дубликатов: строки с одинаковым значением столбца DedupKey объединяются в одну
+	DedupKey string            `json:"dedup_key,omitempty"` // Заголовок столбца-ключа (пусто - дубликаты сохраняются)
+	Coalesce map[string]string `json:"coalesce,omitempty"`  // Стратегии по заголовку столбца: first или join-unique(разделитель)
+
+	// Столбец для разбивки числа строк листа по значениям в итогах объединения, например "Бренд".
+	// Используется, только когда фильтр по значению столбца не задан: иначе строки считаются по значениям фильтра
+	GroupByColumn string `json:"group_by_column,omitempty"`
+
+	// Преамбула переменной длины между заголовками и данными: первая строка данных ищется по столбцу
+	DataStartColumn string `json:"data_start_column,omitempty"` // Буква столбца для поиска, например A (пусто - данные сразу после заголовков)
+	DataStartMarker string `json:"data_start_marker,omitempty"` // Значение строки-маркера в этом столбце; данные начинаются со следующей строки
+
+	// Префиксы и суффиксы значений столбцов, например код продавца "SHZ-" к артикулам поставщика.
+	// Добавляются до фильтрации и извлечения артикулов листа "Шаблон"
+	AffixRules []AffixRule `json:"affix_rules,omitempty"`
+
+	// Разделители между строками разных файлов: none (по умолчанию), blank-row или label-row.
+	// Строки-разделители не входят в счетчики строк; несовместимы со склейкой дубликатов
+	SeparatorMode string `json:"separator_mode,omitempty"`
+
+	// Не защищать шапку листа результата, даже если защита включена в настройках профиля
+	SkipHeaderProtection bool `json:"skip_header_protection,omitempty"`
+
+	// Перечитывать пустые ячейки без формата: значения, скрытые форматом ячейки (например, ";;;"),
+	// иначе теряются. Каждая пустая ячейка читается отдельно, поэтому лист читается медленнее
+	RawCellFallback bool `json:"raw_cell_fallback,omitempty"`
+
+	// Столбцы без преобразований по заголовку (например, ссылки на фото и видео Ozon):
+	// значения переносятся как есть, без приведения к числу, проверки типа и склейки
+	RawTextColumns []string `json:"raw_text_columns,omitempty"`
+
+	// Замены значений по 0-based индексу столбца, например {"да": "Yes", "1": "Yes"}:
+	// приводят разные записи одного значения у поставщиков к одной. Значение сравнивается
+	// с ключами без учета регистра и пробелов по краям; значения без замены не меняются
+	ValueMaps map[int]map[string]string `json:"value_maps,omitempty"`
+}
+
+// OutputSheetName возвращает имя листа результата (по умолчанию совпадает с именем листа)
+func (c *SheetConfig) OutputSheetName() string {
+	if name := strings.TrimSpace(c.OutputSheet); name != "" {
+		return name
+	}
+	return c.SheetName
+}
+
+// DataStartColumnIndex возвращает 0-based столбец поиска первой строки данных
+// или -1, если поиск не настроен
+func (c *SheetConfig) DataStartColumnIndex() (int, error) {
+	letter := strings.TrimSpace(c.DataStartColumn)
+	if letter == "" {
+		return -1, nil
+	}
+	return columnLetterToIndex(letter)
 }
 
 // ProfileSettings дополнительные настройки профиля
 type ProfileSettings struct {
-	SkipEmptyRows bool `json:"skip_empty_rows"`
-	ShowWarnings  bool `json:"show_warnings"`
-	PreviewRows   int  `json:"preview_rows"`
+	SkipEmptyRows        bool   `json:"skip_empty_rows"`
+	ShowWarnings         bool   `json:"show_warnings"`
+	PreviewRows          int    `json:"preview_rows"`
+	AutoSplitLargeSheets bool   `json:"auto_split_large_sheets,omitempty"` // Разбивать листы, превышающие лимит строк Excel
+	StyleTemplatePath    string `json:"style_template_path,omitempty"`     // Файл-шаблон оформления строки заголовков
+	OnError              string `json:"on_error,omitempty"`                // Поведение при ошибке листа: abort или continue
+	MergeIntoBase        bool   `json:"merge_into_base,omitempty"`         // Дописывать данные в копию базового файла с его оформлением
+	HashAlgorithm        string `json:"hash_algorithm,omitempty"`          // Алгоритм отпечатков файлов: sha256 (по умолчанию) или crc64
+	DuplicateFiles       string `json:"duplicate_files,omitempty"`         // Файлы с одинаковым содержимым: warn (по умолчанию) или skip
+	OnExistingOutput     string `json:"on_existing_output,omitempty"`      // Файл результата уже существует: overwrite, append или cancel (пусто - спросить)
+
+	// Перенос условного форматирования листов базового файла (например, подсветки малого остатка)
+	// на листы результата; области правил продлеваются до последней строки данных
+	PreserveConditionalFormats bool `json:"preserve_conditional_formats,omitempty"`
+
+	// Защита строк шапки листов результата от случайного изменения: данные, сортировка
+	// и автофильтр остаются доступны. Пароль защищает от случайного снятия защиты,
+	// но хранится в профиле открытым текстом
+	ProtectHeaders     bool   `json:"protect_headers,omitempty"`
+	ProtectionPassword string `json:"protection_password,omitempty"` // Пароль снятия защиты (пусто - без пароля)
 }
 
+// Политики обработки ошибок листа при объединении
+const (
+	OnErrorAbort    = "abort"    // Прервать объединение (по умолчанию)
+	OnErrorContinue = "continue" // Пропустить лист с предупреждением и продолжить
+)
+
+// Политики обработки файлов с одинаковым содержимым
+const (
+	DuplicateFilesWarn = "warn" // Объединить все файлы и предупредить о повторе строк (по умолчанию)
+	DuplicateFilesSkip = "skip" // Пропустить копии, объединив только первый из одинаковых файлов
+)
+
+// Политики сохранения результата в уже существующий файл
+// Пустое значение - спросить пользователя; без интерфейса (CLI) файл перезаписывается
+const (
+	OnExistingOverwrite = "overwrite" // Заменить файл новым результатом
+	OnExistingAppend    = "append"    // Дописать строки результата в листы существующего файла
+	OnExistingCancel    = "cancel"    // Не сохранять, оставив файл без изменений
+)
+
 // NewProfile создает новый профиль с настройками по умолчанию
 func NewProfile(name string) *Profile {
 	now := time.Now()
@@ -99,49 +213,131 @@ func (p *Profile) GetEnabledSheets() []SheetConfig {
 	return enabled
 }
 
+// ApplyPreset возвращает листам, входящим в шаблон preset, настройки шаблона:
+// включение, строку заголовков, фильтры и столбцы без преобразований
+// Листы ищутся по имени без учета регистра и пробелов; остальные листы не изменяются
+// Возвращает индексы листов, к которым применен шаблон
+func ApplyPreset(sheets []SheetConfig, preset map[string]SheetConfig) []int {
+	var applied []int
+	for i := range sheets {
+		sheet := &sheets[i]
+		_, config, ok := LookupSheetConfig(preset, sheet.SheetName)
+		if !ok {
+			continue
+		}
+
+		// Заголовки для предпросмотра прочитаны из прежней строки заголовков
+		if sheet.HeaderRow != config.HeaderRow {
+			sheet.Headers = []string{}
+		}
+		sheet.Enabled = config.Enabled
+		sheet.HeaderRow = config.HeaderRow
+		sheet.FilterColumn = config.FilterColumn
+		sheet.FilterValues = append([]string(nil), config.FilterValues...)
+		sheet.UseTemplateArticles = config.UseTemplateArticles
+		sheet.RawTextColumns = append([]string(nil), config.RawTextColumns...)
+		applied = append(applied, i)
+	}
+	return applied
+}
+
+// SetTemplateFilterValues задает значения фильтра values листам "Шаблон" без сброса
+// остальных настроек, например после изменения списка брендов шаблона Ozon
+// Возвращает индексы листов, значения фильтра которых изменились
+func SetTemplateFilterValues(sheets []SheetConfig, values []string) []int {
+	var changed []int
+	for i := range sheets {
+		sheet := &sheets[i]
+		if !IsTemplateSheet(sheet.SheetName) || slices.Equal(sheet.FilterValues, values) {
+			continue
+		}
+		sheet.FilterValues = append([]string(nil), values...)
+		changed = append(changed, i)
+	}
+	return changed
+}
+
 // Validate проверяет корректность профиля
 func (p *Profile) Validate() error {
 	if p.ProfileName == "" {
-		return &AppError{
-			Code:    "E009",
-			Message: "Имя профиля не может быть пустым",
-		}
+		return apperrors.NewConfigError("Имя профиля не может быть пустым")
 	}
 
 	if p.BaseFileName == "" {
-		return &AppError{
-			Code:    "E009",
-			Message: "Базовый файл не указан",
-		}
+		return apperrors.NewConfigError("Базовый файл не указан")
+	}
+
+	switch p.Settings.DuplicateFiles {
+	case "", DuplicateFilesWarn, DuplicateFilesSkip:
+	default:
+		return apperrors.NewConfigError(
+			fmt.Sprintf("Неизвестная политика для одинаковых файлов '%s' (ожидается %s или %s)",
+				p.Settings.DuplicateFiles, DuplicateFilesWarn, DuplicateFilesSkip),
+			apperrors.WithContext("duplicate_files", p.Settings.DuplicateFiles))
+	}
+
+	switch p.Settings.OnExistingOutput {
+	case "", OnExistingOverwrite, OnExistingAppend, OnExistingCancel:
+	default:
+		return apperrors.NewConfigError(
+			fmt.Sprintf("Неизвестная политика для существующего файла результата '%s' (ожидается %s, %s или %s)",
+				p.Settings.OnExistingOutput, OnExistingOverwrite, OnExistingAppend, OnExistingCancel),
+			apperrors.WithContext("on_existing_output", p.Settings.OnExistingOutput))
+	}
+
+	if err := excel.ValidateHashAlgorithm(p.Settings.HashAlgorithm); err != nil {
+		return apperrors.NewConfigError(err.Error(),
+			apperrors.WithContext("hash_algorithm", p.Settings.HashAlgorithm))
 	}
 
 	for i, sheet := range p.Sheets {
 		if sheet.SheetName == "" {
-			return &AppError{
-				Code:    "E009",
-				Message: "Имя листа не может быть пустым",
-				Context: map[string]interface{}{"sheet_index": i},
-			}
+			return apperrors.NewConfigError("Имя листа не может быть пустым",
+				apperrors.WithContext("sheet_index", i))
 		}
 		if sheet.HeaderRow < 1 {
-			return &AppError{
-				Code:    "E004",
-				Message: "Номер строки заголовков должен быть больше 0",
-				Context: map[string]interface{}{"sheet": sheet.SheetName, "header_row": sheet.HeaderRow},
+			return apperrors.NewInvalidHeaderRowError(sheet.HeaderRow,
+				apperrors.WithContext("sheet", sheet.SheetName))
+		}
+		if sheet.TypeDescriptorRow < 0 || sheet.TypeDescriptorRow > sheet.HeaderRow {
+			return apperrors.NewConfigError(
+				fmt.Sprintf("Строка описания типов листа '%s' должна быть в шапке (от 1 до %d)", sheet.SheetName, sheet.HeaderRow),
+				apperrors.WithContext("sheet", sheet.SheetName),
+				apperrors.WithContext("type_descriptor_row", sheet.TypeDescriptorRow))
+		}
+		if _, err := sheet.DataStartColumnIndex(); err != nil {
+			return apperrors.NewConfigError(
+				fmt.Sprintf("Столбец поиска начала данных листа '%s': %v", sheet.SheetName, err),
+				apperrors.WithContext("sheet", sheet.SheetName))
+		}
+		for header, spec := range sheet.Coalesce {
+			if _, err := ParseCoalesceStrategy(spec); err != nil {
+				return apperrors.NewConfigError(
+					fmt.Sprintf("Стратегия склейки столбца '%s' листа '%s': %v", header, sheet.SheetName, err),
+					apperrors.WithContext("sheet", sheet.SheetName))
+			}
+		}
+		for _, rule := range sheet.AffixRules {
+			if err := rule.Validate(); err != nil {
+				return apperrors.NewConfigError(
+					fmt.Sprintf("Префикс и суффикс листа '%s': %v", sheet.SheetName, err),
+					apperrors.WithContext("sheet", sheet.SheetName))
+			}
+		}
+		if err := ValidateSeparatorMode(sheet.SeparatorMode, sheet.DedupKey); err != nil {
+			return apperrors.NewConfigError(
+				fmt.Sprintf("Разделители листа '%s': %v", sheet.SheetName, err),
+				apperrors.WithContext("sheet", sheet.SheetName),
+				apperrors.WithContext("separator_mode", sheet.SeparatorMode))
+		}
+		for col := range sheet.ValueMaps {
+			if col < 0 {
+				return apperrors.NewConfigError(
+					fmt.Sprintf("Замены значений листа '%s' заданы для некорректного столбца %d", sheet.SheetName, col),
+					apperrors.WithContext("sheet", sheet.SheetName))
 			}
 		}
 	}
 
 	return nil
 }
-
-// AppError ошибка приложения (временное определение, будет импортироваться из errors)
-type AppError struct {
-	Code    string
-	Message string
-	Context map[string]interface{}
-}
-
-func (e *AppError) Error() string {
-	return e.Message
-}
@@ -1,6 +1,10 @@
 package core
 
 import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -161,6 +165,55 @@ func TestGetEnabledSheets(t *testing.T) {
 	}
 }
 
+// TestApplyPreset тестирует сброс листов к настройкам шаблона
+func TestApplyPreset(t *testing.T) {
+	preset := map[string]SheetConfig{
+		"Шаблон":     {SheetName: "Шаблон", Enabled: true, HeaderRow: 4, FilterColumn: -1, FilterValues: []string{"Shuzzi"}},
+		"Озон.Видео": {SheetName: "Озон.Видео", Enabled: true, HeaderRow: 4, UseTemplateArticles: true, RawTextColumns: []string{"Озон.Видео: ссылка"}},
+	}
+
+	profile := NewProfile("Ozon")
+	profile.BaseFileName = "/data/base.xlsx"
+	profile.Sheets = []SheetConfig{
+		// Настройки шаблона изменены пользователем
+		{SheetName: "Шаблон", Enabled: false, HeaderRow: 2, FilterColumn: 5, FilterValues: []string{"Other"}, Headers: []string{"Старый"}},
+		{SheetName: "озон.видео ", Enabled: true, HeaderRow: 4, Headers: []string{"Артикул"}},
+		// Лист вне шаблона
+		{SheetName: "Справка", Enabled: true, HeaderRow: 3, FilterValues: []string{"x"}},
+	}
+
+	applied := ApplyPreset(profile.Sheets, preset)
+
+	if len(applied) != 2 || applied[0] != 0 || applied[1] != 1 {
+		t.Fatalf("ApplyPreset() = %v, ожидались листы 0 и 1", applied)
+	}
+	if profile.BaseFileName != "/data/base.xlsx" || len(profile.Sheets) != 3 {
+		t.Errorf("изменен выбор файла или список листов: %q, %d листов", profile.BaseFileName, len(profile.Sheets))
+	}
+
+	template := profile.Sheets[0]
+	if !template.Enabled || template.HeaderRow != 4 || template.FilterColumn != -1 ||
+		len(template.FilterValues) != 1 || template.FilterValues[0] != "Shuzzi" || len(template.Headers) != 0 {
+		t.Errorf("лист Шаблон не сброшен к шаблону: %+v", template)
+	}
+
+	video := profile.Sheets[1]
+	if !video.UseTemplateArticles || len(video.RawTextColumns) != 1 || len(video.Headers) != 1 {
+		t.Errorf("лист Озон.Видео: %+v", video)
+	}
+
+	other := profile.Sheets[2]
+	if !other.Enabled || other.HeaderRow != 3 || len(other.FilterValues) != 1 {
+		t.Errorf("лист вне шаблона изменен: %+v", other)
+	}
+
+	// Настройки листа не разделяют срезы с шаблоном
+	profile.Sheets[0].FilterValues[0] = "Изменено"
+	if preset["Шаблон"].FilterValues[0] != "Shuzzi" {
+		t.Error("изменение листа затронуло шаблон")
+	}
+}
+
 func TestValidate(t *testing.T) {
 	// Валидный профиль
 	profile := NewProfile("Valid Profile")
@@ -199,4 +252,139 @@ func TestValidate(t *testing.T) {
 	if err := invalidProfile3.Validate(); err == nil {
 		t.Error("Expected validation to fail for HeaderRow < 1")
 	}
+
+	// Строка описания типов за пределами шапки
+	for _, row := range []int{-1, 6} {
+		invalidProfile4 := NewProfile("Invalid TypeDescriptorRow")
+		invalidProfile4.BaseFileName = "base.xlsx"
+		invalidProfile4.AddSheet(SheetConfig{SheetName: "Лист1", Enabled: true, HeaderRow: 5, TypeDescriptorRow: row})
+		if err := invalidProfile4.Validate(); err == nil {
+			t.Errorf("Expected validation to fail for TypeDescriptorRow = %d", row)
+		}
+	}
+
+	// Неизвестная стратегия склейки дубликатов
+	invalidProfile5 := NewProfile("Invalid Coalesce")
+	invalidProfile5.BaseFileName = "base.xlsx"
+	invalidProfile5.AddSheet(SheetConfig{SheetName: "Лист1", Enabled: true, HeaderRow: 1,
+		DedupKey: "Артикул", Coalesce: map[string]string{"Теги": "last"}})
+	if err := invalidProfile5.Validate(); err == nil {
+		t.Error("Expected validation to fail for unknown coalesce strategy")
+	}
+
+	// Некорректный столбец поиска начала данных
+	invalidProfile6 := NewProfile("Invalid DataStartColumn")
+	invalidProfile6.BaseFileName = "base.xlsx"
+	invalidProfile6.AddSheet(SheetConfig{SheetName: "Лист1", Enabled: true, HeaderRow: 1, DataStartColumn: "A1"})
+	if err := invalidProfile6.Validate(); err == nil {
+		t.Error("Expected validation to fail for invalid DataStartColumn")
+	}
+
+	// Замены значений для отрицательного столбца
+	invalidProfile7 := NewProfile("Invalid ValueMaps")
+	invalidProfile7.BaseFileName = "base.xlsx"
+	invalidProfile7.AddSheet(SheetConfig{SheetName: "Лист1", Enabled: true, HeaderRow: 1,
+		ValueMaps: map[int]map[string]string{-1: {"да": "Yes"}}})
+	if err := invalidProfile7.Validate(); err == nil {
+		t.Error("Expected validation to fail for negative ValueMaps column")
+	}
+
+	// Неизвестный алгоритм отпечатков файлов
+	invalidProfile8 := NewProfile("Invalid HashAlgorithm")
+	invalidProfile8.BaseFileName = "base.xlsx"
+	invalidProfile8.Settings.HashAlgorithm = "md5"
+	if err := invalidProfile8.Validate(); err == nil {
+		t.Error("Expected validation to fail for unknown hash algorithm")
+	}
+
+	// Неизвестная политика для одинаковых файлов
+	invalidProfile9 := NewProfile("Invalid DuplicateFiles")
+	invalidProfile9.BaseFileName = "base.xlsx"
+	invalidProfile9.Settings.DuplicateFiles = "delete"
+	if err := invalidProfile9.Validate(); err == nil {
+		t.Error("Expected validation to fail for unknown duplicate files policy")
+	}
+
+	// Test invalid existing output policy
+	invalidProfile10 := NewProfile("Invalid OnExistingOutput")
+	invalidProfile10.BaseFileName = "base.xlsx"
+	invalidProfile10.Settings.OnExistingOutput = "rename"
+	if err := invalidProfile10.Validate(); err == nil {
+		t.Error("Expected validation to fail for unknown existing output policy")
+	}
+
+	// Test separators combined with duplicate coalescing
+	invalidProfile11 := NewProfile("Invalid SeparatorMode")
+	invalidProfile11.BaseFileName = "base.xlsx"
+	invalidProfile11.Sheets = []SheetConfig{{SheetName: "Data", HeaderRow: 1, SeparatorMode: SeparatorLabelRow, DedupKey: "Артикул"}}
+	if err := invalidProfile11.Validate(); err == nil {
+		t.Error("Expected validation to fail for separators with dedup key")
+	}
+
+	// Test affix rule without prefix and suffix
+	invalidProfile12 := NewProfile("Invalid AffixRules")
+	invalidProfile12.BaseFileName = "base.xlsx"
+	invalidProfile12.Sheets = []SheetConfig{{SheetName: "Data", HeaderRow: 1, AffixRules: []AffixRule{{Header: "Артикул"}}}}
+	if err := invalidProfile12.Validate(); err == nil {
+		t.Error("Expected validation to fail for affix rule without prefix and suffix")
+	}
+}
+
+// TestSetTemplateFilterValues тестирует объединение после изменения списка брендов шаблона
+func TestSetTemplateFilterValues(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+
+	template := []string{"Название", "Бренд", "Артикул"}
+	video := []string{"Артикул", "Ссылка"}
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Шаблон", [][]string{template, {"Ботинки", "Shuzzi", "A1"}, {"Кеды", "Nord", "A2"}, {"Туфли", "Other", "A3"}}},
+		{"Видео", [][]string{video, {"A1", "v1"}}},
+	})
+	writeTestWorkbookSheets(t, sourcePath, []testSheet{
+		{"Шаблон", [][]string{template, {"Сапоги", "nord", "A4"}, {"Шлепанцы", "Other", "A5"}}},
+		{"Видео", [][]string{video, {"A2", "v2"}, {"A3", "v3"}, {"A4", "v4"}}},
+	})
+
+	sheets := []SheetConfig{
+		{SheetName: "Шаблон", Enabled: true, HeaderRow: 1, FilterColumn: 1, FilterValues: []string{"Shuzzi"}, DedupKey: "Артикул"},
+		{SheetName: "Видео", Enabled: true, HeaderRow: 1, FilterColumn: -1, UseTemplateArticles: true},
+	}
+
+	// В список добавлен бренд; остальные настройки листа не сбрасываются
+	if changed := SetTemplateFilterValues(sheets, []string{"Shuzzi", "Nord"}); len(changed) != 1 || changed[0] != 0 {
+		t.Fatalf("SetTemplateFilterValues() = %v, ожидался лист 0", changed)
+	}
+	if sheets[0].FilterColumn != 1 || sheets[0].DedupKey != "Артикул" || sheets[1].FilterValues != nil {
+		t.Errorf("изменены другие настройки: %+v", sheets)
+	}
+	if changed := SetTemplateFilterValues(sheets, []string{"Shuzzi", "Nord"}); changed != nil {
+		t.Errorf("повторное применение изменило листы %v", changed)
+	}
+
+	sheetConfigs := make(map[string]*SheetConfig, len(sheets))
+	for i := range sheets {
+		sheetConfigs[sheets[i].SheetName] = &sheets[i]
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка объединения: %v", err)
+	}
+	defer result.Close()
+
+	for sheet, column := range map[string]int{"Шаблон": 2, "Видео": 0} {
+		rows, err := result.WorkbookData.GetFile().GetRows(sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var articles []string
+		for _, row := range rows[1:] {
+			articles = append(articles, row[column])
+		}
+		if got := strings.Join(articles, ","); got != "A1,A2,A4" {
+			t.Errorf("лист %s: артикулы = %s, ожидалось A1,A2,A4", sheet, got)
+		}
+	}
 }
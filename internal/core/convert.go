@@ -0,0 +1,166 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// Кодировки CSV-файлов
+const (
+	CSVEncodingUTF8        = "utf-8"
+	CSVEncodingWindows1251 = "windows-1251" // Выгрузки Excel и 1С на русской Windows
+)
+
+// csvDelimiters разделители, среди которых выбирается разделитель по первой строке
+var csvDelimiters = []rune{';', ',', '\t'}
+
+// utf8BOM метка порядка байтов, которую Excel добавляет в начало CSV в UTF-8
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// CSVOptions параметры преобразования CSV в xlsx
+type CSVOptions struct {
+	Delimiter rune   // Разделитель полей; 0 - определить по первой строке (";", "," или табуляция)
+	Encoding  string // Кодировка файла: CSVEncodingUTF8 (по умолчанию) или CSVEncodingWindows1251
+	SheetName string // Имя листа результата; пусто - имя CSV-файла без расширения
+}
+
+// ConvertCSVToXLSX преобразует CSV-файл в книгу xlsx с одним листом без объединения
+// Строки читаются и записываются потоком (excel.StreamWriter), поэтому размер файла
+// ограничен только лимитом строк Excel. Значения переносятся как текст, без преобразований.
+// Существующий файл xlsxPath заменяется только после успешной записи.
+// Возвращает количество записанных строк
+func ConvertCSVToXLSX(csvPath, xlsxPath string, opts CSVOptions) (int, error) {
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось открыть CSV-файл: %w", err)
+	}
+	defer file.Close()
+
+	input, err := decodeCSV(file, opts.Encoding)
+	if err != nil {
+		return 0, err
+	}
+
+	delimiter := opts.Delimiter
+	if delimiter == 0 {
+		delimiter = detectCSVDelimiter(input)
+	}
+
+	sheetName := opts.SheetName
+	if sheetName == "" {
+		sheetName = csvSheetName(csvPath)
+	}
+
+	writer, err := excel.NewStreamWriter(sheetName)
+	if err != nil {
+		return 0, err
+	}
+	defer writer.Close()
+
+	reader := csv.NewReader(input)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1 // Строки могут быть разной длины
+	reader.LazyQuotes = true    // Кавычки внутри неэкранированных полей встречаются в выгрузках
+	reader.ReuseRecord = true
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("не удалось прочитать CSV-файл %s: %w", filepath.Base(csvPath), err)
+		}
+		if err := writer.WriteRow(record); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := writer.SaveAtomic(xlsxPath); err != nil {
+		return 0, err
+	}
+	return writer.Rows(), nil
+}
+
+// decodeCSV возвращает поток CSV в UTF-8 без метки BOM
+func decodeCSV(r io.Reader, encoding string) (*bufio.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", CSVEncodingUTF8, "utf8":
+	case CSVEncodingWindows1251, "cp1251":
+		r = charmap.Windows1251.NewDecoder().Reader(r)
+	default:
+		return nil, fmt.Errorf("неподдерживаемая кодировка CSV: %q (ожидается %s или %s)",
+			encoding, CSVEncodingUTF8, CSVEncodingWindows1251)
+	}
+
+	input := bufio.NewReaderSize(r, 64*1024)
+	if bom, _ := input.Peek(len(utf8BOM)); bytes.Equal(bom, utf8BOM) {
+		input.Discard(len(utf8BOM))
+	}
+	return input, nil
+}
+
+// detectCSVDelimiter выбирает разделитель, который чаще других встречается в первой
+// строке вне кавычек; без разделителей в строке возвращает ";"
+func detectCSVDelimiter(input *bufio.Reader) rune {
+	head, _ := input.Peek(input.Size())
+
+	counts := make(map[rune]int, len(csvDelimiters))
+	inQuotes := false
+	for len(head) > 0 {
+		r, size := utf8.DecodeRune(head)
+		head = head[size:]
+		if r == '"' {
+			inQuotes = !inQuotes
+			continue
+		}
+		if inQuotes {
+			continue
+		}
+		if r == '\n' {
+			break
+		}
+		counts[r]++
+	}
+
+	best := csvDelimiters[0]
+	for _, delimiter := range csvDelimiters[1:] {
+		if counts[delimiter] > counts[best] {
+			best = delimiter
+		}
+	}
+	return best
+}
+
+// csvSheetName возвращает имя листа по имени CSV-файла с учетом ограничений Excel:
+// не длиннее 31 символа и без символов : \ / ? * [ ]
+func csvSheetName(csvPath string) string {
+	name := strings.TrimSuffix(filepath.Base(csvPath), filepath.Ext(csvPath))
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, "' ")
+
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		return "Лист1"
+	}
+	return name
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// TestConvertCSVToXLSX тестирует преобразование CSV с кавычками и многострочными полями
+func TestConvertCSVToXLSX(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   []byte
+		opts      CSVOptions
+		wantSheet string
+		want      [][]string
+	}{
+		{
+			name: "многострочные поля в кавычках",
+			content: []byte("Артикул;Описание;Цена\n" +
+				"A-1;\"Первая строка\nвторая строка\";100\n" +
+				"A-2;\"Текст с ; и \"\"кавычками\"\"\";00120\n"),
+			wantSheet: "товары",
+			want: [][]string{
+				{"Артикул", "Описание", "Цена"},
+				{"A-1", "Первая строка\nвторая строка", "100"},
+				{"A-2", "Текст с ; и \"кавычками\"", "00120"},
+			},
+		},
+		{
+			name:      "разделитель запятая определяется автоматически",
+			content:   []byte("Код,\"Название; полное\"\n1,Товар\n"),
+			wantSheet: "товары",
+			want:      [][]string{{"Код", "Название; полное"}, {"1", "Товар"}},
+		},
+		{
+			name:      "метка BOM и табуляция",
+			content:   append([]byte{0xEF, 0xBB, 0xBF}, []byte("Код\tЦена\n7\t1,5\n")...),
+			opts:      CSVOptions{SheetName: "Цены"},
+			wantSheet: "Цены",
+			want:      [][]string{{"Код", "Цена"}, {"7", "1,5"}},
+		},
+		{
+			name:      "строки разной длины и явный разделитель",
+			content:   []byte("a|b|c\nd\n"),
+			opts:      CSVOptions{Delimiter: '|'},
+			wantSheet: "товары",
+			want:      [][]string{{"a", "b", "c"}, {"d"}},
+		},
+		{
+			name:      "кодировка windows-1251",
+			content:   encodeWindows1251(t, "Бренд;Цвет\nЗима;\"синий\nтемный\"\n"),
+			opts:      CSVOptions{Encoding: CSVEncodingWindows1251},
+			wantSheet: "товары",
+			want:      [][]string{{"Бренд", "Цвет"}, {"Зима", "синий\nтемный"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			csvPath := filepath.Join(dir, "товары.csv")
+			xlsxPath := filepath.Join(dir, "товары.xlsx")
+			if err := os.WriteFile(csvPath, tt.content, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rows, err := ConvertCSVToXLSX(csvPath, xlsxPath, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertCSVToXLSX() error = %v", err)
+			}
+			if rows != len(tt.want) {
+				t.Errorf("записано строк = %d, ожидалось %d", rows, len(tt.want))
+			}
+
+			reader, err := excel.NewReader(xlsxPath)
+			if err != nil {
+				t.Fatalf("не удалось открыть результат: %v", err)
+			}
+			defer reader.Close()
+
+			if names := reader.GetSheetNames(); !reflect.DeepEqual(names, []string{tt.wantSheet}) {
+				t.Fatalf("листы = %v, ожидался [%s]", names, tt.wantSheet)
+			}
+			got, err := reader.GetRows(tt.wantSheet)
+			if err != nil {
+				t.Fatalf("не удалось прочитать строки: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("строки = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvertCSVToXLSXErrors тестирует ошибки преобразования и сохранность существующего файла
+func TestConvertCSVToXLSXErrors(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "data.csv")
+	xlsxPath := filepath.Join(dir, "data.xlsx")
+	if err := os.WriteFile(csvPath, []byte("a;b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(xlsxPath, []byte("прежний файл"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ConvertCSVToXLSX(filepath.Join(dir, "missing.csv"), xlsxPath, CSVOptions{}); err == nil {
+		t.Error("ожидалась ошибка для отсутствующего файла")
+	}
+	if _, err := ConvertCSVToXLSX(csvPath, xlsxPath, CSVOptions{Encoding: "koi8-r"}); err == nil {
+		t.Error("ожидалась ошибка для неподдерживаемой кодировки")
+	}
+
+	content, err := os.ReadFile(xlsxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "прежний файл" {
+		t.Error("существующий файл изменен при ошибке преобразования")
+	}
+}
+
+// TestCSVSheetName тестирует имя листа по имени CSV-файла
+func TestCSVSheetName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/tmp/отчет.csv", "отчет"},
+		{"/tmp/цены [май]?.csv", "цены _май__"},
+		{"/tmp/.csv", "Лист1"},
+		{"/tmp/очень длинное имя файла выгрузки маркетплейса.csv", "очень длинное имя файла выгрузк"},
+	}
+
+	for _, tt := range tests {
+		if got := csvSheetName(tt.path); got != tt.want {
+			t.Errorf("csvSheetName(%q) = %q, ожидалось %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// encodeWindows1251 перекодирует текст в windows-1251
+func encodeWindows1251(t *testing.T, s string) []byte {
+	t.Helper()
+	encoded, err := charmap.Windows1251.NewEncoder().String(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(encoded)
+}
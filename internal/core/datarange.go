@@ -0,0 +1,72 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// findSheetDataRange ищет диапазон name книги reader и проверяет, что он находится
+// на листе sheetName и над его данными есть строка заголовков
+func findSheetDataRange(reader *excel.Reader, sheetName, name string) (excel.DataRange, error) {
+	dataRange, ok, err := reader.FindDataRange(name)
+	if err != nil {
+		return excel.DataRange{}, err
+	}
+	if !ok {
+		return excel.DataRange{}, fmt.Errorf("диапазон '%s' не найден", name)
+	}
+	if NormalizeSheetName(dataRange.Sheet) != NormalizeSheetName(sheetName) {
+		return excel.DataRange{}, fmt.Errorf("диапазон '%s' находится на листе '%s'", name, dataRange.Sheet)
+	}
+	if dataRange.HeaderRow() < 1 {
+		return excel.DataRange{}, fmt.Errorf("у таблицы '%s' нет строки заголовков", name)
+	}
+	return dataRange, nil
+}
+
+// sheetDataRange возвращает единственный диапазон листа sheetName из ranges для новых настроек листа
+// Таблица Excel предпочтительнее именованного диапазона; несколько таблиц или несколько
+// диапазонов без таблиц - неоднозначно, и лист настраивается по строке заголовков
+func sheetDataRange(ranges []excel.DataRange, sheetName string) (excel.DataRange, bool) {
+	var tables, names []excel.DataRange
+	for _, dataRange := range ranges {
+		if NormalizeSheetName(dataRange.Sheet) != NormalizeSheetName(sheetName) || dataRange.HeaderRow() < 1 {
+			continue
+		}
+		if dataRange.Table {
+			tables = append(tables, dataRange)
+		} else {
+			names = append(names, dataRange)
+		}
+	}
+	switch {
+	case len(tables) == 1:
+		return tables[0], true
+	case len(tables) == 0 && len(names) == 1:
+		return names[0], true
+	}
+	return excel.DataRange{}, false
+}
+
+// withDataRange возвращает копию настроек листа со строкой заголовков и шириной данных из dataRange
+func withDataRange(config *SheetConfig, dataRange excel.DataRange) *SheetConfig {
+	resolved := *config
+	resolved.HeaderRow = dataRange.HeaderRow()
+	resolved.dataColumns = dataRange.LastCol
+	return &resolved
+}
+
+// truncateColumns обрезает строки до первых columns столбцов; columns < 1 - без ограничения
+// Строки не копируются: срезы укорачиваются без изменения значений
+func truncateColumns(rows [][]string, columns int) [][]string {
+	if columns < 1 {
+		return rows
+	}
+	for i, row := range rows {
+		if len(row) > columns {
+			rows[i] = row[:columns]
+		}
+	}
+	return rows
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// addDefinedName добавляет в сохраненную книгу path именованный диапазон name со ссылкой refersTo
+func addDefinedName(t *testing.T, path, name, refersTo string) {
+	t.Helper()
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := f.SetDefinedName(&excelize.DefinedName{Name: name, RefersTo: refersTo}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMergeFilesUsesDataRange(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	shiftedPath := filepath.Join(dir, "shifted.xlsx")
+
+	// Заголовки в четвертой строке; столбец C за пределами области данных
+	exceltest.WriteWorkbook(t, basePath, "Шаблон", [][]string{
+		{"Шаблон поставщика"}, {"Пояснение"}, {},
+		{"Артикул", "Цена", "Служебный"},
+		{"A1", "100", "x"},
+	})
+	addDefinedName(t, basePath, "ШаблонДанные", "'Шаблон'!$A$4:$B$100")
+	// Источник без диапазона читается по области базового файла
+	exceltest.WriteWorkbook(t, sourcePath, "Шаблон", [][]string{
+		{"Шаблон поставщика"}, {"Пояснение"}, {},
+		{"Артикул", "Цена", "Служебный"},
+		{"A2", "200", "y"},
+	})
+	// Источник с тем же диапазоном в другом месте читается по своей области
+	exceltest.WriteWorkbook(t, shiftedPath, "Шаблон", [][]string{
+		{"Артикул", "Цена", "Служебный"},
+		{"A3", "300", "z"},
+	})
+	addDefinedName(t, shiftedPath, "ШаблонДанные", "'Шаблон'!$A$1:$B$10")
+
+	tests := []struct {
+		name        string
+		rangeName   string
+		wantRows    [][]string
+		wantWarning string
+	}{
+		{
+			name:      "диапазон найден",
+			rangeName: "шаблонданные",
+			wantRows: [][]string{
+				{"Шаблон поставщика"}, {"Пояснение"}, nil,
+				{"Артикул", "Цена"},
+				{"A1", "100"}, {"A2", "200"}, {"A3", "300"},
+			},
+		},
+		{
+			name:      "диапазон не найден",
+			rangeName: "НетТакого",
+			// Строка заголовков из настроек: строки 2-4 базового файла и источника - данные,
+			// а у смещенного источника нет столбцов с заголовками базового листа
+			wantRows: [][]string{
+				{"Шаблон поставщика"},
+				{"Пояснение"}, {"Артикул", "Цена", "Служебный"}, {"A1", "100", "x"},
+				{"Пояснение"}, {"Артикул", "Цена", "Служебный"}, {"A2", "200", "y"},
+			},
+			wantWarning: "диапазон 'НетТакого' не найден в базовом файле, используется строка заголовков 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &SheetConfig{
+				SheetName:     "Шаблон",
+				Enabled:       true,
+				HeaderRow:     1,
+				FilterColumn:  -1,
+				DataRangeName: tt.rangeName,
+			}
+			result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath, shiftedPath},
+				map[string]*SheetConfig{"Шаблон": config})
+			if err != nil {
+				t.Fatalf("MergeFiles() error = %v", err)
+			}
+			defer result.Close()
+
+			rows, err := result.WorkbookData.GetFile().GetRows("Шаблон")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("строки результата = %q, ожидалось %q", rows, tt.wantRows)
+			}
+
+			var warnings []string
+			for _, warning := range result.Warnings {
+				if strings.Contains(warning.Message, "диапазон") {
+					warnings = append(warnings, warning.Message)
+				}
+			}
+			if tt.wantWarning == "" && len(warnings) > 0 {
+				t.Errorf("неожиданные предупреждения: %q", warnings)
+			}
+			if tt.wantWarning != "" && (len(warnings) != 1 || !strings.Contains(warnings[0], tt.wantWarning)) {
+				t.Errorf("предупреждения = %q, ожидалось %q", warnings, tt.wantWarning)
+			}
+
+			// Настройки профиля не меняются: строка заголовков из диапазона действует только при объединении
+			if config.HeaderRow != 1 {
+				t.Errorf("HeaderRow профиля = %d, ожидалось 1", config.HeaderRow)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSheetsDataRange(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	path := filepath.Join(t.TempDir(), "base.xlsx")
+	writeTestWorkbookSheets(t, path, []testSheet{
+		{name: "Шаблон", rows: [][]string{{"Отчет"}, {}, {}, {"Артикул", "Цена", "Служебный"}, {"A1", "100", "x"}}},
+		{name: "Справка", rows: [][]string{{"Текст"}}},
+	})
+	addDefinedName(t, path, "ШаблонДанные", "'Шаблон'!$A$4:$B$100")
+	// Диапазон, указывающий на несуществующий лист, не учитывается
+	addDefinedName(t, path, "Старый", "'Удален'!$A$1:$B$2")
+
+	sheets, err := NewBaseAnalyzer(nil, logger).AnalyzeSheets(path, 1)
+	if err != nil {
+		t.Fatalf("AnalyzeSheets() error = %v", err)
+	}
+
+	want := []SheetConfig{
+		{SheetName: "Шаблон", HeaderRow: 4, Headers: []string{"Артикул", "Цена"}, DataRangeName: "ШаблонДанные"},
+		{SheetName: "Справка", HeaderRow: 1, Headers: []string{"Текст"}},
+	}
+	if !reflect.DeepEqual(sheets, want) {
+		t.Errorf("AnalyzeSheets() = %+v, ожидалось %+v", sheets, want)
+	}
+}
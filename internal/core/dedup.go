@@ -0,0 +1,324 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Стратегии склейки значений столбца у строк-дубликатов
+const (
+	CoalesceFirst      = "first"       // Первое непустое значение (по умолчанию)
+	CoalesceJoinUnique = "join-unique" // Уникальные значения через разделитель
+)
+
+// defaultJoinSeparator разделитель значений join-unique по умолчанию
+const defaultJoinSeparator = ", "
+
+// CoalesceStrategy способ склейки значений одного столбца у строк с одинаковым ключом
+type CoalesceStrategy struct {
+	Mode      string // CoalesceFirst или CoalesceJoinUnique
+	Separator string // Разделитель значений для CoalesceJoinUnique
+}
+
+// ParseCoalesceStrategy разбирает стратегию вида "first", "join-unique" или "join-unique(; )"
+// Разделитель в скобках сохраняется вместе с пробелами
+func ParseCoalesceStrategy(spec string) (CoalesceStrategy, error) {
+	spec = strings.TrimSpace(spec)
+	prefix := CoalesceJoinUnique + "("
+
+	switch {
+	case spec == "" || strings.EqualFold(spec, CoalesceFirst):
+		return CoalesceStrategy{Mode: CoalesceFirst}, nil
+	case strings.EqualFold(spec, CoalesceJoinUnique):
+		return CoalesceStrategy{Mode: CoalesceJoinUnique, Separator: defaultJoinSeparator}, nil
+	case len(spec) > len(prefix) && strings.EqualFold(spec[:len(prefix)], prefix) && strings.HasSuffix(spec, ")"):
+		separator := spec[len(prefix) : len(spec)-1]
+		if separator == "" {
+			return CoalesceStrategy{}, fmt.Errorf("пустой разделитель в стратегии '%s'", spec)
+		}
+		return CoalesceStrategy{Mode: CoalesceJoinUnique, Separator: separator}, nil
+	}
+	return CoalesceStrategy{}, fmt.Errorf("неизвестная стратегия склейки '%s', ожидается %s или %s(разделитель)",
+		spec, CoalesceFirst, CoalesceJoinUnique)
+}
+
+// String формирует описание стратегии для ParseCoalesceStrategy
+func (s CoalesceStrategy) String() string {
+	if s.Mode == CoalesceJoinUnique {
+		return fmt.Sprintf("%s(%s)", CoalesceJoinUnique, s.Separator)
+	}
+	return CoalesceFirst
+}
+
+// coalesce склеивает значения столбца строк-дубликатов
+// Для join-unique уже склеенные значения разбиваются по разделителю, чтобы повторы не накапливались
+func (s CoalesceStrategy) coalesce(values []string) string {
+	if s.Mode != CoalesceJoinUnique {
+		for _, value := range values {
+			if strings.TrimSpace(value) != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	var parts []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, part := range splitJoined(value, s.Separator) {
+			if !seen[part] {
+				seen[part] = true
+				parts = append(parts, part)
+			}
+		}
+	}
+	return strings.Join(parts, s.Separator)
+}
+
+// splitJoined разбивает значение по разделителю без учета пробелов вокруг него
+func splitJoined(value, separator string) []string {
+	var pieces []string
+	if sep := strings.TrimSpace(separator); sep != "" {
+		pieces = strings.Split(value, sep)
+	} else {
+		pieces = strings.Fields(value)
+	}
+
+	parts := pieces[:0]
+	for _, piece := range pieces {
+		if piece = strings.TrimSpace(piece); piece != "" {
+			parts = append(parts, piece)
+		}
+	}
+	return parts
+}
+
+// ParseCoalesceColumns разбирает стратегии склейки по столбцам вида "Теги=join-unique(, ); Бренд=first"
+// Точка с запятой внутри скобок разделителя не разбивает описание
+func ParseCoalesceColumns(spec string) (map[string]string, error) {
+	columns := make(map[string]string)
+	for _, part := range splitOutsideParens(spec, ';') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		header, strategySpec, ok := strings.Cut(part, "=")
+		header = strings.TrimSpace(header)
+		if !ok || header == "" {
+			return nil, fmt.Errorf("некорректное описание склейки '%s', ожидается вид Заголовок=стратегия", part)
+		}
+		strategy, err := ParseCoalesceStrategy(strategySpec)
+		if err != nil {
+			return nil, fmt.Errorf("столбец '%s': %w", header, err)
+		}
+		columns[header] = strategy.String()
+	}
+	if len(columns) == 0 {
+		return nil, nil
+	}
+	return columns, nil
+}
+
+// FormatCoalesceColumns формирует описание стратегий склейки для ParseCoalesceColumns
+func FormatCoalesceColumns(columns map[string]string) string {
+	headers := make([]string, 0, len(columns))
+	for header := range columns {
+		headers = append(headers, header)
+	}
+	sort.Strings(headers)
+
+	parts := make([]string, 0, len(headers))
+	for _, header := range headers {
+		parts = append(parts, header+"="+columns[header])
+	}
+	return strings.Join(parts, "; ")
+}
+
+// splitOutsideParens разбивает строку по sep, не учитывая sep внутри круглых скобок
+func splitOutsideParens(s string, sep rune) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case r == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// resolveDedup находит ключевой столбец и стратегии склейки по заголовкам листа результата
+// Возвращает keyColumn = -1, если склейка не настроена или ключевой столбец не найден
+func resolveDedup(config *SheetConfig, sheetName string, headers []string) (int, map[int]CoalesceStrategy, []Warning) {
+	keyHeader := strings.TrimSpace(config.DedupKey)
+	if keyHeader == "" {
+		return -1, nil, nil
+	}
+
+	var warnings []Warning
+	keyColumn := columnIndexByHeader(headers, keyHeader)
+	if keyColumn < 0 {
+		warnings = append(warnings, newWarning(SeverityWarning,
+			"лист '%s': ключевой столбец '%s' для склейки дубликатов не найден, дубликаты сохранены", sheetName, keyHeader))
+		return -1, nil, warnings
+	}
+
+	strategies := make(map[int]CoalesceStrategy)
+	// Предупреждения выдаются в порядке имен столбцов, а не в случайном порядке обхода карты
+	headerNames := make([]string, 0, len(config.Coalesce))
+	for header := range config.Coalesce {
+		headerNames = append(headerNames, header)
+	}
+	sort.Strings(headerNames)
+	for _, header := range headerNames {
+		spec := config.Coalesce[header]
+		column := columnIndexByHeader(headers, header)
+		if column < 0 {
+			warnings = append(warnings, newWarning(SeverityInfo,
+				"лист '%s': столбец '%s' из стратегий склейки не найден", sheetName, header))
+			continue
+		}
+		strategy, err := ParseCoalesceStrategy(spec)
+		if err != nil {
+			warnings = append(warnings, newWarning(SeverityInfo,
+				"лист '%s': столбец '%s' склеивается по первому значению: %v", sheetName, header, err))
+			continue
+		}
+		strategies[column] = strategy
+	}
+	return keyColumn, strategies, warnings
+}
+
+// columnIndexByHeader возвращает индекс столбца с заголовком header без учета регистра и пробелов или -1
+func columnIndexByHeader(headers []string, header string) int {
+	key := strings.ToLower(strings.TrimSpace(header))
+	for i, h := range headers {
+		if strings.ToLower(strings.TrimSpace(h)) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// dedupRows склеивает строки с одинаковым значением ключевого столбца keyColumn
+// Склеенная строка занимает место первого дубликата; строки без ключа сохраняются как есть.
+// Столбцы без стратегии получают первое непустое значение
+func dedupRows(rows [][]string, keyColumn int, strategies map[int]CoalesceStrategy) [][]string {
+	result := make([][]string, 0, len(rows))
+	positions := make(map[string]int)
+	groups := make(map[int][][]string)
+
+	for _, row := range rows {
+		key := ""
+		if keyColumn < len(row) {
+			key = strings.TrimSpace(row[keyColumn])
+		}
+		if key == "" {
+			result = append(result, row)
+			continue
+		}
+
+		if pos, ok := positions[key]; ok {
+			groups[pos] = append(groups[pos], row)
+			continue
+		}
+		positions[key] = len(result)
+		groups[len(result)] = [][]string{row}
+		result = append(result, row)
+	}
+
+	for pos, group := range groups {
+		if len(group) > 1 {
+			result[pos] = coalesceRows(group, strategies)
+		}
+	}
+	return result
+}
+
+// keyedRow строка, уже стоящая в листе результата, с которой склеиваются дубликаты из файлов
+type keyedRow struct {
+	rowNum int      // Номер строки в листе результата
+	values []string // Значения строки после склейки
+}
+
+// indexKeyedRows добавляет в keyed строки rows, записанные в лист с номера строки firstRow,
+// по ключевому столбцу keyColumn. Для повторяющегося ключа остается первая строка
+func indexKeyedRows(keyed map[string]*keyedRow, rows [][]string, firstRow, keyColumn int) {
+	for i, row := range rows {
+		if keyColumn >= len(row) {
+			continue
+		}
+		key := strings.TrimSpace(row[keyColumn])
+		if _, ok := keyed[key]; key == "" || ok {
+			continue
+		}
+		keyed[key] = &keyedRow{rowNum: firstRow + i, values: row}
+	}
+}
+
+// coalesceExisting склеивает строки rows, ключ которых уже есть в листе результата (existing),
+// с этими строками по стратегиям столбцов. Возвращает строки с новыми ключами и строки листа,
+// значения которых изменились, в порядке листа. Измененная строка заменяет запись existing
+// новой, а не изменяет ее: копия индекса не затрагивает записи исходного индекса
+func coalesceExisting(rows [][]string, keyColumn int, strategies map[int]CoalesceStrategy, existing map[string]*keyedRow) (added [][]string, updated []*keyedRow) {
+	if len(existing) == 0 {
+		return rows, nil
+	}
+
+	added = make([][]string, 0, len(rows))
+	changed := make(map[int]*keyedRow)
+	for _, row := range rows {
+		key := ""
+		if keyColumn < len(row) {
+			key = strings.TrimSpace(row[keyColumn])
+		}
+		target, ok := existing[key]
+		if key == "" || !ok {
+			added = append(added, row)
+			continue
+		}
+
+		merged := coalesceRows([][]string{target.values, row}, strategies)
+		if slices.Equal(merged, target.values) {
+			continue
+		}
+		existing[key] = &keyedRow{rowNum: target.rowNum, values: merged}
+		changed[target.rowNum] = existing[key]
+	}
+
+	updated = make([]*keyedRow, 0, len(changed))
+	for _, row := range changed {
+		updated = append(updated, row)
+	}
+	sort.Slice(updated, func(i, j int) bool { return updated[i].rowNum < updated[j].rowNum })
+	return added, updated
+}
+
+// coalesceRows склеивает строки-дубликаты в одну по стратегиям столбцов
+func coalesceRows(rows [][]string, strategies map[int]CoalesceStrategy) []string {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	merged := make([]string, width)
+	values := make([]string, len(rows))
+	for column := range merged {
+		for i, row := range rows {
+			values[i] = ""
+			if column < len(row) {
+				values[i] = row[column]
+			}
+		}
+		merged[column] = strategies[column].coalesce(values)
+	}
+	return merged
+}
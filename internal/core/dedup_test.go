@@ -0,0 +1,284 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// TestParseCoalesceStrategy тестирует разбор стратегий склейки
+func TestParseCoalesceStrategy(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    CoalesceStrategy
+		wantErr bool
+	}{
+		{"", CoalesceStrategy{Mode: CoalesceFirst}, false},
+		{" First ", CoalesceStrategy{Mode: CoalesceFirst}, false},
+		{"join-unique", CoalesceStrategy{Mode: CoalesceJoinUnique, Separator: ", "}, false},
+		{"join-unique(; )", CoalesceStrategy{Mode: CoalesceJoinUnique, Separator: "; "}, false},
+		{"JOIN-UNIQUE( | )", CoalesceStrategy{Mode: CoalesceJoinUnique, Separator: " | "}, false},
+		{"join-unique()", CoalesceStrategy{}, true},
+		{"join-unique(,", CoalesceStrategy{}, true},
+		{"last", CoalesceStrategy{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseCoalesceStrategy(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCoalesceStrategy(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCoalesceStrategy(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+			if err == nil {
+				if again, _ := ParseCoalesceStrategy(got.String()); again != got {
+					t.Errorf("String() = %q не разбирается обратно в %+v", got.String(), got)
+				}
+			}
+		})
+	}
+}
+
+// TestParseCoalesceColumns тестирует разбор и форматирование стратегий по столбцам
+func TestParseCoalesceColumns(t *testing.T) {
+	columns, err := ParseCoalesceColumns(" Теги = join-unique(; ); Бренд=first;; Цвет=join-unique ")
+	if err != nil {
+		t.Fatalf("ParseCoalesceColumns() error = %v", err)
+	}
+	want := map[string]string{"Теги": "join-unique(; )", "Бренд": "first", "Цвет": "join-unique(, )"}
+	if fmt.Sprint(columns) != fmt.Sprint(want) {
+		t.Errorf("ParseCoalesceColumns() = %v, want %v", columns, want)
+	}
+
+	formatted := FormatCoalesceColumns(columns)
+	if formatted != "Бренд=first; Теги=join-unique(; ); Цвет=join-unique(, )" {
+		t.Errorf("FormatCoalesceColumns() = %q", formatted)
+	}
+	if again, _ := ParseCoalesceColumns(formatted); fmt.Sprint(again) != fmt.Sprint(columns) {
+		t.Errorf("описание %q не разбирается обратно: %v", formatted, again)
+	}
+
+	if columns, err := ParseCoalesceColumns("  "); err != nil || columns != nil {
+		t.Errorf("пустое описание: %v, %v", columns, err)
+	}
+	for _, spec := range []string{"Теги", "=first", "Теги=last"} {
+		if _, err := ParseCoalesceColumns(spec); err == nil {
+			t.Errorf("ParseCoalesceColumns(%q) ожидалась ошибка", spec)
+		}
+	}
+}
+
+// TestDedupRows тестирует склейку дубликатов: теги собираются в уникальный список,
+// остальные столбцы получают первое непустое значение
+func TestDedupRows(t *testing.T) {
+	rows := [][]string{
+		{"A1", "", "лето, sale"},
+		{"B1", "Nike", "new"},
+		{"", "без ключа", "x"},
+		{" A1 ", "Adidas", "sale, хит"},
+		{"A1", "Puma", "Лето"},
+		{"", "без ключа", "x"},
+	}
+	strategies := map[int]CoalesceStrategy{2: {Mode: CoalesceJoinUnique, Separator: ", "}}
+
+	got := dedupRows(rows, 0, strategies)
+	want := [][]string{
+		{"A1", "Adidas", "лето, sale, хит, Лето"},
+		{"B1", "Nike", "new"},
+		{"", "без ключа", "x"},
+		{"", "без ключа", "x"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("dedupRows() = %v, want %v", got, want)
+	}
+
+	// Разделитель с пробелами и строки разной длины
+	got = dedupRows([][]string{{"K", "a | b"}, {"K", "b|c", "extra"}}, 0,
+		map[int]CoalesceStrategy{1: {Mode: CoalesceJoinUnique, Separator: " | "}})
+	if fmt.Sprint(got) != fmt.Sprint([][]string{{"K", "a | b | c", "extra"}}) {
+		t.Errorf("dedupRows() = %v", got)
+	}
+}
+
+// TestMergeFilesDedupCoalesce тестирует склейку дубликатов из разных файлов при объединении
+func TestMergeFilesDedupCoalesce(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Артикул", "Цена", "Теги"},
+		{"A1", "100", "лето; sale"},
+		{"B1", "50", "new"},
+	})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{
+		{"Артикул", "Цена", "Теги"},
+		{"A1", "120", "sale; хит"},
+		{"C1", "70", ""},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {
+			SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1,
+			DedupKey: "артикул",
+			Coalesce: map[string]string{"Теги": "join-unique(; )", "Нет такого": "first"},
+		},
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Data")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	want := [][]string{
+		{"Артикул", "Цена", "Теги"},
+		{"A1", "100", "лето; sale; хит"},
+		{"B1", "50", "new"},
+		{"C1", "70"},
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("строки результата = %v, ожидалось %v", rows, want)
+	}
+	if result.TotalRows != 3 {
+		t.Errorf("TotalRows = %d, ожидалось 3", result.TotalRows)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Severity != SeverityInfo {
+		t.Errorf("ожидалось одно информационное предупреждение о ненайденном столбце: %v", result.Warnings)
+	}
+}
+
+func TestMergeFilesDedupIntoBase(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Артикул", "Цена", "Теги"},
+		{"A1", "100", "лето"},
+		{"B1", "50", "new"},
+	})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{
+		{"Артикул", "Цена", "Теги"},
+		{"A1", "120", "хит"},
+		{"B1", "50", "new"},
+		{"C1", "70", ""},
+		{"C1", "", "sale"},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {
+			SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1,
+			DedupKey: "Артикул",
+			Coalesce: map[string]string{"Теги": "join-unique(; )"},
+		},
+	}
+	merger := NewMerger(nil, logger)
+	merger.SetSettings(ProfileSettings{MergeIntoBase: true})
+	result, err := merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Data")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	// Дубликаты строк базового файла склеиваются с ними на месте, новые ключи дописываются
+	want := [][]string{
+		{"Артикул", "Цена", "Теги"},
+		{"A1", "100", "лето; хит"},
+		{"B1", "50", "new"},
+		{"C1", "70", "sale"},
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("строки результата = %v, ожидалось %v", rows, want)
+	}
+	if result.TotalRows != 3 {
+		t.Errorf("TotalRows = %d, ожидалось 3", result.TotalRows)
+	}
+}
+
+func TestCoalesceExisting(t *testing.T) {
+	existing := make(map[string]*keyedRow)
+	indexKeyedRows(existing, [][]string{
+		{"A1", "", "x"},
+		{"", "пусто"},
+		{"B1", "5", "y"},
+		{"A1", "дубль"},
+	}, 2, 0)
+	if len(existing) != 2 || existing["A1"].rowNum != 2 || existing["B1"].rowNum != 4 {
+		t.Fatalf("indexKeyedRows = %v", existing)
+	}
+
+	added, updated := coalesceExisting([][]string{
+		{"B1", "5", "y"},
+		{"A1", "10", "z"},
+		{"C1", "1", ""},
+	}, 0, nil, existing)
+	if fmt.Sprint(added) != fmt.Sprint([][]string{{"C1", "1", ""}}) {
+		t.Errorf("added = %v", added)
+	}
+	if len(updated) != 1 || updated[0].rowNum != 2 || fmt.Sprint(updated[0].values) != fmt.Sprint([]string{"A1", "10", "x"}) {
+		t.Errorf("updated = %v", updated)
+	}
+}
+
+func TestMergeFilesDedupAcrossOutputSheet(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Ботинки", [][]string{{"Артикул", "Цена", "Теги"}, {"A1", "100", "лето"}, {"B1", "200", ""}}},
+		{"Кроссовки", [][]string{{"Артикул", "Цена", "Теги"}, {"K1", "300", ""}}},
+	})
+	writeTestWorkbookSheets(t, sourcePath, []testSheet{
+		{"Кроссовки", [][]string{{"Артикул", "Цена", "Теги"}, {"A1", "150", "хит"}, {"K2", "400", ""}}},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Ботинки": {
+			SheetName: "Ботинки", Enabled: true, HeaderRow: 1, FilterColumn: -1, OutputSheet: "Обувь",
+			DedupKey: "Артикул", Coalesce: map[string]string{"Теги": "join-unique(; )"},
+		},
+		"Кроссовки": {
+			SheetName: "Кроссовки", Enabled: true, HeaderRow: 1, FilterColumn: -1, OutputSheet: "Обувь",
+			DedupKey: "Артикул", Coalesce: map[string]string{"Теги": "join-unique(; )"},
+		},
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Обувь")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	// Дубликат строки предыдущего листа склеивается с ней, а не дописывается
+	want := [][]string{
+		{"Артикул", "Цена", "Теги"},
+		{"A1", "100", "лето; хит"},
+		{"B1", "200"},
+		{"K1", "300"},
+		{"K2", "400"},
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("строки результата = %v, ожидалось %v", rows, want)
+	}
+	if result.TotalRows != 4 {
+		t.Errorf("TotalRows = %d, ожидалось 4", result.TotalRows)
+	}
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// Эмпирические константы оценки; получены по BenchmarkMergeFiles и BenchmarkWriteRows
+// (docs/performance.md). Результат целиком строится в памяти, поэтому пик памяти
+// пропорционален числу ячеек всех файлов
+const (
+	estimatedOutputBytesPerCell = 8   // Размер сжатого .xlsx на ячейку
+	estimatedMemoryBytesPerCell = 600 // Пик кучи при объединении на ячейку
+)
+
+// MergeEstimate прогноз объема объединения, вычисленный до его начала
+// Фильтры строк не учитываются, поэтому прогноз - оценка сверху
+type MergeEstimate struct {
+	Rows            int64 // Строк данных во всех файлах
+	Cells           int64 // Ячеек данных во всех файлах
+	OutputBytes     int64 // Ожидаемый размер файла результата
+	PeakMemoryBytes int64 // Ожидаемый пик памяти при объединении
+}
+
+// EstimateMerge оценивает размер результата и пик памяти по размерам данных листов
+func EstimateMerge(sizes []excel.SheetSize) MergeEstimate {
+	var estimate MergeEstimate
+	for _, size := range sizes {
+		if size.Rows <= 0 || size.Columns <= 0 {
+			continue
+		}
+		estimate.Rows += int64(size.Rows)
+		estimate.Cells += int64(size.Rows) * int64(size.Columns)
+	}
+	estimate.OutputBytes = estimate.Cells * estimatedOutputBytesPerCell
+	estimate.PeakMemoryBytes = estimate.Cells * estimatedMemoryBytesPerCell
+	return estimate
+}
+
+// ExceedsMemory сообщает, что ожидаемый пик памяти больше limit байт; limit <= 0 - без ограничения
+func (e MergeEstimate) ExceedsMemory(limit int64) bool {
+	return limit > 0 && e.PeakMemoryBytes > limit
+}
+
+// String возвращает прогноз для пользователя
+func (e MergeEstimate) String() string {
+	return fmt.Sprintf("Ожидаемый размер результата ≈ %s, потребуется ≈ %s памяти",
+		FormatSize(e.OutputBytes), FormatSize(e.PeakMemoryBytes))
+}
+
+// FormatSize форматирует размер в байтах для пользователя: "180 МБ", "2,5 ГБ"
+func FormatSize(bytes int64) string {
+	const mb = 1 << 20
+	switch {
+	case bytes < mb:
+		return "1 МБ"
+	case bytes < 1024*mb:
+		return fmt.Sprintf("%d МБ", (bytes+mb/2)/mb)
+	default:
+		return strings.Replace(fmt.Sprintf("%.1f ГБ", float64(bytes)/(1024*mb)), ".", ",", 1)
+	}
+}
+
+// EstimateFiles оценивает объединение до его начала по размерам листов файлов
+// Читается только разметка листов (excel.ScanSheetSizes), значения ячеек не загружаются.
+// Листы ищутся так же, как при объединении; файлы-источники, которые не удалось
+// просмотреть, в оценку не входят
+func (m *Merger) EstimateFiles(baseFilePath string, filePaths []string, sheetConfigs map[string]*SheetConfig) (MergeEstimate, error) {
+	var sizes []excel.SheetSize
+
+	for i, filePath := range append([]string{baseFilePath}, filePaths...) {
+		fileSizes, err := mergedSheetSizes(filePath, i == 0, sheetConfigs)
+		if err != nil {
+			if i == 0 {
+				return MergeEstimate{}, fmt.Errorf("не удалось оценить базовый файл: %w", err)
+			}
+			m.logger.Warn("файл не вошел в оценку объединения", "file", filepath.Base(filePath), "error", err)
+			continue
+		}
+		sizes = append(sizes, fileSizes...)
+	}
+
+	estimate := EstimateMerge(sizes)
+	m.logger.Info("оценка объединения",
+		"rows", estimate.Rows,
+		"cells", estimate.Cells,
+		"output_bytes", estimate.OutputBytes,
+		"peak_memory_bytes", estimate.PeakMemoryBytes,
+	)
+	return estimate, nil
+}
+
+// FileEstimate оценка одного файла списка для объединения
+type FileEstimate struct {
+	Rows  int64 // Строк данных во включенных листах
+	Bytes int64 // Размер файла на диске
+}
+
+// EstimateSourceFile оценивает файл-источник по разметке листов, не читая значения ячеек
+// Считаются строки данных листов sheetConfigs, найденных так же, как при объединении;
+// без настроенных листов считаются строки всех листов файла
+func EstimateSourceFile(path string, sheetConfigs map[string]*SheetConfig) (FileEstimate, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileEstimate{}, fmt.Errorf("не удалось оценить файл %s: %w", filepath.Base(path), err)
+	}
+	sizes, err := mergedSheetSizes(path, false, sheetConfigs)
+	if err != nil {
+		return FileEstimate{}, fmt.Errorf("не удалось оценить файл %s: %w", filepath.Base(path), err)
+	}
+
+	estimate := FileEstimate{Bytes: info.Size()}
+	for _, size := range sizes {
+		estimate.Rows += int64(max(0, size.Rows))
+	}
+	return estimate, nil
+}
+
+// mergedSheetSizes возвращает размеры данных листов файла, которые попадут в объединение
+// Из строк листа вычитается шапка до строки заголовков включительно
+func mergedSheetSizes(filePath string, isBase bool, sheetConfigs map[string]*SheetConfig) ([]excel.SheetSize, error) {
+	fileSizes, err := excel.ScanSheetSizes(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetNames := make([]string, 0, len(fileSizes))
+	for name := range fileSizes {
+		sheetNames = append(sheetNames, name)
+	}
+	sort.Strings(sheetNames)
+
+	var sizes []excel.SheetSize
+	if len(sheetConfigs) == 0 {
+		for _, name := range sheetNames {
+			sizes = append(sizes, fileSizes[name])
+		}
+		return sizes, nil
+	}
+
+	for _, sheetName := range sortedSheetNames(sheetConfigs) {
+		config := sheetConfigs[sheetName]
+		var actualName string
+		var ok bool
+		if isBase {
+			actualName, ok = findSheet(sheetNames, sheetName)
+		} else {
+			actualName, ok = locateSourceSheet(sheetNames, sheetName, config)
+		}
+		if !ok {
+			continue
+		}
+
+		size := fileSizes[actualName]
+		size.Rows = max(0, size.Rows-config.HeaderRow)
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
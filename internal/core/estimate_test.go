@@ -0,0 +1,164 @@
+package core
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// TestEstimateMerge тестирует расчет прогноза по размерам листов
+func TestEstimateMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		sizes     []excel.SheetSize
+		wantRows  int64
+		wantCells int64
+	}{
+		{"без листов", nil, 0, 0},
+		{"пустые листы не учитываются", []excel.SheetSize{{Rows: 0, Columns: 10}, {Rows: 5, Columns: 0}}, 0, 0},
+		{"сумма по листам", []excel.SheetSize{{Rows: 1000, Columns: 10}, {Rows: 500, Columns: 4}}, 1500, 12000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateMerge(tt.sizes)
+			if got.Rows != tt.wantRows || got.Cells != tt.wantCells {
+				t.Errorf("EstimateMerge() = %+v, ожидалось строк %d, ячеек %d", got, tt.wantRows, tt.wantCells)
+			}
+			if got.OutputBytes != tt.wantCells*estimatedOutputBytesPerCell ||
+				got.PeakMemoryBytes != tt.wantCells*estimatedMemoryBytesPerCell {
+				t.Errorf("размеры не пропорциональны числу ячеек: %+v", got)
+			}
+		})
+	}
+
+	estimate := EstimateMerge([]excel.SheetSize{{Rows: 1_000_000, Columns: 10}})
+	if !estimate.ExceedsMemory(1<<30) || estimate.ExceedsMemory(0) || estimate.ExceedsMemory(8<<30) {
+		t.Errorf("ExceedsMemory() для пика %d байт", estimate.PeakMemoryBytes)
+	}
+}
+
+// TestFormatSize тестирует вывод размеров для пользователя
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "1 МБ"},
+		{180 << 20, "180 МБ"},
+		{2560 << 20, "2,5 ГБ"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatSize(tt.bytes); got != tt.want {
+			t.Errorf("FormatSize(%d) = %q, ожидалось %q", tt.bytes, got, tt.want)
+		}
+	}
+
+	want := "Ожидаемый размер результата ≈ 180 МБ, потребуется ≈ 2,5 ГБ памяти"
+	if got := (MergeEstimate{OutputBytes: 180 << 20, PeakMemoryBytes: 2560 << 20}).String(); got != want {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+// TestEstimateFiles тестирует прогноз для синтетических книг и сравнивает его с результатом
+func TestEstimateFiles(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+	rows := append([][]string{exceltest.BenchHeaders(10)}, exceltest.BenchRows(2000, 10)...)
+
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	renamedPath := filepath.Join(dir, "renamed.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", rows)
+	exceltest.WriteWorkbook(t, sourcePath, "Data", rows)
+	exceltest.WriteWorkbook(t, renamedPath, "Данные", rows[:1001])
+	brokenPath := filepath.Join(dir, "broken.xlsx")
+	if err := os.WriteFile(brokenPath, []byte("не архив"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1, SourceSheetNames: []string{"данные"}},
+	}
+	merger := NewMerger(nil, logger)
+	sources := []string{sourcePath, renamedPath, brokenPath}
+
+	estimate, err := merger.EstimateFiles(basePath, sources, sheetConfigs)
+	if err != nil {
+		t.Fatalf("EstimateFiles() error = %v", err)
+	}
+	if estimate.Rows != 5000 || estimate.Cells != 50000 {
+		t.Errorf("EstimateFiles() = %+v, ожидалось строк 5000, ячеек 50000", estimate)
+	}
+
+	// Прогноз размера должен быть того же порядка, что и сохраненный результат
+	result, err := merger.MergeFiles(basePath, sources, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+	outputPath := filepath.Join(dir, "result.xlsx")
+	if err := result.WorkbookData.Save(outputPath); err != nil {
+		t.Fatalf("не удалось сохранить результат: %v", err)
+	}
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ratio := float64(estimate.OutputBytes) / float64(info.Size()); ratio < 0.5 || ratio > 2 {
+		t.Errorf("прогноз размера %d байт, фактически %d (отношение %.2f)", estimate.OutputBytes, info.Size(), ratio)
+	}
+
+	if _, err := merger.EstimateFiles(brokenPath, nil, sheetConfigs); err == nil {
+		t.Error("ожидалась ошибка для базового файла без архива")
+	}
+}
+
+// TestEstimateSourceFile тестирует оценку одного файла списка
+func TestEstimateSourceFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "source.xlsx")
+	writeTestWorkbookSheets(t, path, []testSheet{
+		{"Данные", [][]string{{"Выгрузка"}, {"Артикул", "Цена"}, {"A1", "1"}, {"A2", "2"}, {"A3", "3"}}},
+		{"Прочее", [][]string{{"x"}, {"y"}}},
+	})
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		sheetConfigs map[string]*SheetConfig
+		wantRows     int64
+	}{
+		{"лист по альтернативному имени", map[string]*SheetConfig{
+			"Data": {SheetName: "Data", Enabled: true, HeaderRow: 2, SourceSheetNames: []string{"данные"}},
+		}, 3},
+		{"листа нет в файле", map[string]*SheetConfig{
+			"Нет": {SheetName: "Нет", Enabled: true, HeaderRow: 1},
+		}, 0},
+		{"без настроенных листов", nil, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EstimateSourceFile(path, tt.sheetConfigs)
+			if err != nil {
+				t.Fatalf("ошибка: %v", err)
+			}
+			if got.Rows != tt.wantRows || got.Bytes != info.Size() {
+				t.Errorf("EstimateSourceFile() = %+v, ожидалось строк %d, размер %d", got, tt.wantRows, info.Size())
+			}
+		})
+	}
+
+	if _, err := EstimateSourceFile(filepath.Join(dir, "missing.xlsx"), nil); err == nil {
+		t.Error("ожидалась ошибка для отсутствующего файла")
+	}
+}
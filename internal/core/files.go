@@ -0,0 +1,251 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// excelLockPrefix префикс временных файлов блокировки, которые Excel создает рядом с открытой книгой
+const excelLockPrefix = "~$"
+
+// ListExcelFiles возвращает отсортированные пути файлов .xlsx и .xlsx.gz в директории dir без вложенных папок
+// Временные файлы блокировки Excel (~$*.xlsx) пропускаются
+func ListExcelFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать директорию %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isMergeInput(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// ExpandGlob возвращает отсортированные пути файлов .xlsx и .xlsx.gz, подходящих под шаблон pattern,
+// например "exports/2025-11-*.xlsx". Синтаксис шаблона - как у filepath.Match. Папки и временные
+// файлы блокировки Excel (~$*.xlsx) пропускаются, как при добавлении папки. Если подходящих файлов нет,
+// возвращается ошибка: пустой список файлов не должен молча давать пустой результат
+func ExpandGlob(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный шаблон имен файлов '%s': %w", pattern, err)
+	}
+
+	var files []string
+	for _, path := range matches {
+		if !isMergeInput(filepath.Base(path)) {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, path)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("по шаблону '%s' не найдено файлов .xlsx", pattern)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// HasGlobMeta сообщает, содержит ли путь символы шаблона имен файлов *, ? или [
+func HasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// isMergeInput сообщает, подходит ли файл name для объединения: книга .xlsx или .xlsx.gz,
+// не временный файл блокировки Excel
+func isMergeInput(name string) bool {
+	return !strings.HasPrefix(name, excelLockPrefix) && strings.EqualFold(excel.WorkbookExt(name), ".xlsx")
+}
+
+// SkippedPath путь из вставленного или перетащенного списка, который не добавлен, и причина
+type SkippedPath struct {
+	Path   string
+	Reason string
+}
+
+// ParsePastedPaths разбирает текст из буфера обмена на пути файлов: по одному на строку
+// Пробелы и кавычки по краям убираются, ссылки file:// превращаются в пути
+// (file://server/share/a.xlsx - в UNC-путь \\server\share\a.xlsx), пустые строки пропускаются.
+// Обратные косые черты путей Windows сохраняются как есть
+func ParsePastedPaths(text string) []string {
+	var paths []string
+	for _, line := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		path := strings.TrimSpace(line)
+		// Кавычки добавляет проводник Windows при копировании пути с пробелами
+		for len(path) >= 2 && (path[0] == '"' && path[len(path)-1] == '"' || path[0] == '\'' && path[len(path)-1] == '\'') {
+			path = strings.TrimSpace(path[1 : len(path)-1])
+		}
+		if len(path) > len("file://") && strings.EqualFold(path[:len("file://")], "file://") {
+			path = fileURIPath(path)
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// fileURIPath превращает ссылку file:// в путь файла; некорректная ссылка возвращается как есть
+func fileURIPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	path := parsed.Path
+	// file:///C:/dir/a.xlsx - диск Windows после косой черты
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	// Сервер в ссылке - сетевая папка
+	if host := parsed.Host; host != "" && !strings.EqualFold(host, "localhost") {
+		path = "//" + host + path
+	}
+	return filepath.FromSlash(path)
+}
+
+// ValidatePastedPaths отбирает из paths существующие файлы .xlsx и .xlsx.gz в порядке вставки
+// Остальные пути и повторы возвращаются в skipped с причиной
+func ValidatePastedPaths(paths []string) (valid []string, skipped []SkippedPath) {
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		reason := ""
+		if !strings.EqualFold(excel.WorkbookExt(path), ".xlsx") {
+			reason = "не файл .xlsx"
+		} else if seen[path] {
+			reason = "повторяется в списке"
+		} else if info, err := os.Stat(path); err != nil {
+			reason = "файл не найден"
+		} else if info.IsDir() {
+			reason = "это папка"
+		}
+
+		if reason != "" {
+			skipped = append(skipped, SkippedPath{Path: path, Reason: reason})
+			continue
+		}
+		seen[path] = true
+		valid = append(valid, path)
+	}
+	return valid, skipped
+}
+
+// DropAction что делать с перетащенным в окно файлом
+type DropAction int
+
+const (
+	DropAddFile    DropAction = iota // Книга .xlsx или .xlsx.gz - добавить в список для объединения
+	DropConvertCSV                   // CSV-файл - предложить преобразовать в xlsx
+)
+
+// DroppedPath перетащенный файл, который будет обработан, и способ обработки
+type DroppedPath struct {
+	Path   string
+	Action DropAction
+}
+
+// unsupportedWorkbookExts форматы таблиц, которые нельзя объединить без пересохранения в xlsx
+var unsupportedWorkbookExts = map[string]bool{".xls": true, ".xlsb": true, ".xlsm": true, ".ods": true}
+
+// ClassifyDroppedPaths распределяет перетащенные в окно файлы по способу обработки в порядке перетаскивания
+// CSV-файлы направляются на преобразование при convertCSV, иначе пропускаются. Остальные форматы,
+// несуществующие пути, папки и повторы возвращаются в skipped с причиной
+func ClassifyDroppedPaths(paths []string, convertCSV bool) (accepted []DroppedPath, skipped []SkippedPath) {
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		action := DropAddFile
+		reason := ""
+		ext := strings.ToLower(excel.WorkbookExt(path))
+		if ext != ".xlsx" {
+			// Сжатыми бывают только книги xlsx: "a.csv.gz" - не CSV
+			ext = strings.ToLower(filepath.Ext(path))
+		}
+		switch {
+		case ext == ".xlsx":
+		case ext == ".csv" && convertCSV:
+			action = DropConvertCSV
+		case ext == ".csv":
+			reason = "CSV-файлы при перетаскивании пропускаются (см. настройки)"
+		case unsupportedWorkbookExts[ext]:
+			reason = fmt.Sprintf("формат %s не поддерживается, сохраните файл в Excel как .xlsx", ext)
+		default:
+			reason = "не файл .xlsx"
+		}
+		if reason == "" {
+			if seen[path] {
+				reason = "повторяется в списке"
+			} else if info, err := os.Stat(path); err != nil {
+				reason = "файл не найден"
+			} else if info.IsDir() {
+				reason = "это папка"
+			}
+		}
+
+		if reason != "" {
+			skipped = append(skipped, SkippedPath{Path: path, Reason: reason})
+			continue
+		}
+		seen[path] = true
+		accepted = append(accepted, DroppedPath{Path: path, Action: action})
+	}
+	return accepted, skipped
+}
+
+// DuplicateFile файл списка с тем же содержимым, что и у файла, стоящего раньше
+type DuplicateFile struct {
+	Path     string // Повторяющийся файл
+	Original string // Первый файл списка с тем же содержимым
+	Skipped  bool   // Файл исключен из объединения (политика DuplicateFilesSkip)
+}
+
+// FindDuplicateFiles находит файлы paths, содержимое которых совпадает с файлом, стоящим раньше
+// Отпечатки algorithm считаются только для файлов, размер которых совпадает с размером
+// другого файла списка, поэтому файлы одного размера с разным содержимым не отмечаются.
+// Недоступные файлы пропускаются: их ошибка будет выдана при объединении
+func FindDuplicateFiles(paths []string, algorithm string) []DuplicateFile {
+	sizes := make(map[int64]int, len(paths))
+	fileSizes := make([]int64, len(paths))
+	for i, path := range paths {
+		fileSizes[i] = -1
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			fileSizes[i] = info.Size()
+			sizes[info.Size()]++
+		}
+	}
+
+	var duplicates []DuplicateFile
+	originals := make(map[excel.Fingerprint]string)
+	for i, path := range paths {
+		if fileSizes[i] < 0 || sizes[fileSizes[i]] < 2 {
+			continue
+		}
+		fingerprints, err := excel.HashFile(path)
+		if err != nil {
+			continue
+		}
+		fingerprint, ok := fingerprints.Get(algorithm)
+		if !ok {
+			continue
+		}
+		if original, ok := originals[fingerprint]; ok {
+			duplicates = append(duplicates, DuplicateFile{Path: path, Original: original})
+			continue
+		}
+		originals[fingerprint] = path
+	}
+	return duplicates
+}
@@ -0,0 +1,313 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// TestListExcelFiles тестирует отбор файлов .xlsx в директории
+func TestListExcelFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.xlsx", "A.XLSX", "c.xlsx.gz", "~$b.xlsx", "notes.txt", "old.xls", "logs.gz"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл %s: %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested.xlsx"), 0755); err != nil {
+		t.Fatalf("не удалось создать директорию: %v", err)
+	}
+
+	files, err := ListExcelFiles(dir)
+	if err != nil {
+		t.Fatalf("ListExcelFiles() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "A.XLSX"), filepath.Join(dir, "b.xlsx"), filepath.Join(dir, "c.xlsx.gz")}
+	if fmt.Sprint(files) != fmt.Sprint(want) {
+		t.Errorf("ListExcelFiles() = %v, want %v", files, want)
+	}
+
+	if _, err := ListExcelFiles(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ожидалась ошибка для несуществующей директории")
+	}
+}
+
+// TestExpandGlob тестирует отбор файлов .xlsx по шаблону имен
+func TestExpandGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"2025-11-02.xlsx", "2025-11-01.xlsx", "2025-11-03.xlsx.gz", "~$2025-11-01.xlsx", "2025-11-notes.txt", "2025-12-01.xlsx"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("не удалось создать файл %s: %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "2025-11-archive.xlsx"), 0755); err != nil {
+		t.Fatalf("не удалось создать директорию: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "отсортированные файлы без временных и папок",
+			pattern: filepath.Join(dir, "2025-11-*"),
+			want:    []string{"2025-11-01.xlsx", "2025-11-02.xlsx", "2025-11-03.xlsx.gz"},
+		},
+		{
+			name:    "шаблон с расширением",
+			pattern: filepath.Join(dir, "*-0?.xlsx"),
+			want:    []string{"2025-11-01.xlsx", "2025-11-02.xlsx", "2025-12-01.xlsx"},
+		},
+		{name: "нет совпадений", pattern: filepath.Join(dir, "2024-*.xlsx"), wantErr: "не найдено файлов .xlsx"},
+		{name: "только временные файлы", pattern: filepath.Join(dir, "~$*"), wantErr: "не найдено файлов .xlsx"},
+		{name: "некорректный шаблон", pattern: filepath.Join(dir, "[2025"), wantErr: "некорректный шаблон"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ExpandGlob(tt.pattern)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExpandGlob() error = %v, ожидалось %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandGlob() error = %v", err)
+			}
+			var want []string
+			for _, name := range tt.want {
+				want = append(want, filepath.Join(dir, name))
+			}
+			if fmt.Sprint(files) != fmt.Sprint(want) {
+				t.Errorf("ExpandGlob() = %v, want %v", files, want)
+			}
+		})
+	}
+}
+
+// writeDuplicateExports создает в dir две копии выгрузки под именами, как при повторном
+// скачивании: "Повседневная обувь_04.11.2025.xlsx" и "... (1).xlsx"
+// Содержимое берется из testdata, а без тестовых файлов - из созданной книги
+func writeDuplicateExports(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	first := filepath.Join(dir, "Повседневная обувь_04.11.2025.xlsx")
+	second := filepath.Join(dir, "Повседневная обувь_04.11.2025 (1).xlsx")
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "testdata", "Повседневная обувь_04.11.2025.xlsx"))
+	if err != nil {
+		exceltest.WriteWorkbook(t, first, "Data", [][]string{{"Артикул", "Цена"}, {"A1", "100"}, {"A2", "200"}})
+		if data, err = os.ReadFile(first); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return first, second
+}
+
+// TestFindDuplicateFiles тестирует поиск файлов с одинаковым содержимым
+func TestFindDuplicateFiles(t *testing.T) {
+	dir := t.TempDir()
+	first, second := writeDuplicateExports(t, dir)
+
+	// Тот же размер, другое содержимое: не копия
+	data, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0xFF
+	near := filepath.Join(dir, "near.xlsx")
+	if err := os.WriteFile(near, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.xlsx")
+
+	tests := []struct {
+		name      string
+		paths     []string
+		algorithm string
+		want      []DuplicateFile
+	}{
+		{"копия под другим именем", []string{first, near, second, missing}, "", []DuplicateFile{{Path: second, Original: first}}},
+		{"crc64", []string{second, first}, "crc64", []DuplicateFile{{Path: first, Original: second}}},
+		{"один путь дважды", []string{near, near}, "", []DuplicateFile{{Path: near, Original: near}}},
+		{"без копий", []string{first, near, missing}, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindDuplicateFiles(tt.paths, tt.algorithm)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("FindDuplicateFiles() = %+v, ожидалось %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParsePastedPaths тестирует разбор путей, скопированных из письма или проводника
+func TestParsePastedPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "строки Windows и пустые строки",
+			text: "\\\\srv\\Поставщики\\a.xlsx\r\n\r\n  C:\\Выгрузки\\b.xlsx  \r\n",
+			want: []string{`\\srv\Поставщики\a.xlsx`, `C:\Выгрузки\b.xlsx`},
+		},
+		{
+			name: "пути в кавычках с пробелами",
+			text: "\"C:\\Мои файлы\\прайс октябрь.xlsx\"\n'/home/user/new price.xlsx'\n\" \"",
+			want: []string{`C:\Мои файлы\прайс октябрь.xlsx`, "/home/user/new price.xlsx"},
+		},
+		{
+			name: "ссылки file://",
+			text: "file:///home/user/%D0%BF%D1%80%D0%B0%D0%B9%D1%81.xlsx\nFILE:///C:/Data/a%20b.xlsx\nfile://srv/share/c.xlsx\nfile://localhost/tmp/d.xlsx",
+			want: []string{
+				filepath.FromSlash("/home/user/прайс.xlsx"),
+				filepath.FromSlash("C:/Data/a b.xlsx"),
+				filepath.FromSlash("//srv/share/c.xlsx"),
+				filepath.FromSlash("/tmp/d.xlsx"),
+			},
+		},
+		{
+			name: "ссылка в кавычках",
+			text: `"file:///tmp/a.xlsx"`,
+			want: []string{filepath.FromSlash("/tmp/a.xlsx")},
+		},
+		{
+			name: "пустой буфер",
+			text: " \n\t\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParsePastedPaths(tt.text); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("ParsePastedPaths() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidatePastedPaths тестирует отбор существующих файлов .xlsx из вставленных путей
+func TestValidatePastedPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.xlsx", "b b.XLSX", "c.xlsx.gz", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "folder.xlsx"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	a := filepath.Join(dir, "a.xlsx")
+	paths := []string{
+		a,
+		filepath.Join(dir, "b b.XLSX"),
+		filepath.Join(dir, "notes.txt"),
+		filepath.Join(dir, "missing.xlsx"),
+		filepath.Join(dir, "folder.xlsx"),
+		a,
+		filepath.Join(dir, "c.xlsx.gz"),
+	}
+	valid, skipped := ValidatePastedPaths(paths)
+
+	wantValid := []string{a, filepath.Join(dir, "b b.XLSX"), filepath.Join(dir, "c.xlsx.gz")}
+	if fmt.Sprint(valid) != fmt.Sprint(wantValid) {
+		t.Errorf("valid = %q, ожидалось %q", valid, wantValid)
+	}
+	wantSkipped := []SkippedPath{
+		{filepath.Join(dir, "notes.txt"), "не файл .xlsx"},
+		{filepath.Join(dir, "missing.xlsx"), "файл не найден"},
+		{filepath.Join(dir, "folder.xlsx"), "это папка"},
+		{a, "повторяется в списке"},
+	}
+	if fmt.Sprint(skipped) != fmt.Sprint(wantSkipped) {
+		t.Errorf("skipped = %v, ожидалось %v", skipped, wantSkipped)
+	}
+}
+
+// TestClassifyDroppedPaths тестирует распределение перетащенных файлов по способу обработки
+func TestClassifyDroppedPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.xlsx", "b.XLSX.gz", "prices.CSV", "old.xls", "bin.xlsb", "notes.txt", "data.csv.gz"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "folder.xlsx"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+	paths := []string{
+		path("a.xlsx"), path("prices.CSV"), path("old.xls"), path("bin.xlsb"), path("notes.txt"),
+		path("data.csv.gz"), path("folder.xlsx"), path("missing.csv"), path("a.xlsx"), path("b.XLSX.gz"),
+	}
+
+	tests := []struct {
+		name         string
+		convertCSV   bool
+		wantAccepted []DroppedPath
+		wantSkipped  []SkippedPath
+	}{
+		{
+			name:       "CSV на преобразование",
+			convertCSV: true,
+			wantAccepted: []DroppedPath{
+				{path("a.xlsx"), DropAddFile},
+				{path("prices.CSV"), DropConvertCSV},
+				{path("b.XLSX.gz"), DropAddFile},
+			},
+			wantSkipped: []SkippedPath{
+				{path("old.xls"), "формат .xls не поддерживается, сохраните файл в Excel как .xlsx"},
+				{path("bin.xlsb"), "формат .xlsb не поддерживается, сохраните файл в Excel как .xlsx"},
+				{path("notes.txt"), "не файл .xlsx"},
+				{path("data.csv.gz"), "не файл .xlsx"},
+				{path("folder.xlsx"), "это папка"},
+				{path("missing.csv"), "файл не найден"},
+				{path("a.xlsx"), "повторяется в списке"},
+			},
+		},
+		{
+			name: "CSV пропускаются",
+			wantAccepted: []DroppedPath{
+				{path("a.xlsx"), DropAddFile},
+				{path("b.XLSX.gz"), DropAddFile},
+			},
+			wantSkipped: []SkippedPath{
+				{path("prices.CSV"), "CSV-файлы при перетаскивании пропускаются (см. настройки)"},
+				{path("old.xls"), "формат .xls не поддерживается, сохраните файл в Excel как .xlsx"},
+				{path("bin.xlsb"), "формат .xlsb не поддерживается, сохраните файл в Excel как .xlsx"},
+				{path("notes.txt"), "не файл .xlsx"},
+				{path("data.csv.gz"), "не файл .xlsx"},
+				{path("folder.xlsx"), "это папка"},
+				{path("missing.csv"), "CSV-файлы при перетаскивании пропускаются (см. настройки)"},
+				{path("a.xlsx"), "повторяется в списке"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accepted, skipped := ClassifyDroppedPaths(paths, tt.convertCSV)
+			if fmt.Sprint(accepted) != fmt.Sprint(tt.wantAccepted) {
+				t.Errorf("accepted = %v, ожидалось %v", accepted, tt.wantAccepted)
+			}
+			if fmt.Sprint(skipped) != fmt.Sprint(tt.wantSkipped) {
+				t.Errorf("skipped = %v, ожидалось %v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FileListSummary сводка списка файлов для объединения: количество файлов, строки и размер
+// Оценки файлов (EstimateSourceFile) поступают по мере фоновой проверки; пока проверены
+// не все файлы, итог показывается как "≈ ?". Не безопасна для одновременного использования
+type FileListSummary struct {
+	files     []string
+	estimates map[string]FileEstimate // Оценки проверенных файлов
+	failed    map[string]bool         // Файлы, которые не удалось оценить
+}
+
+// NewFileListSummary создает сводку пустого списка
+func NewFileListSummary() *FileListSummary {
+	return &FileListSummary{
+		estimates: make(map[string]FileEstimate),
+		failed:    make(map[string]bool),
+	}
+}
+
+// SetFiles задает текущий список файлов и возвращает добавленные файлы, которые нужно оценить
+// Оценки файлов, оставшихся в списке, сохраняются; оценки убранных файлов забываются
+func (s *FileListSummary) SetFiles(paths []string) []string {
+	previous := make(map[string]bool, len(s.files))
+	for _, path := range s.files {
+		previous[path] = true
+	}
+
+	inList := make(map[string]bool, len(paths))
+	var added []string
+	for _, path := range paths {
+		inList[path] = true
+		if !previous[path] {
+			added = append(added, path)
+		}
+	}
+	for path := range s.estimates {
+		if !inList[path] {
+			delete(s.estimates, path)
+		}
+	}
+	for path := range s.failed {
+		if !inList[path] {
+			delete(s.failed, path)
+		}
+	}
+
+	s.files = append([]string(nil), paths...)
+	return added
+}
+
+// SetEstimate записывает оценку файла path; err - файл не удалось оценить
+// Оценка файла, убранного из списка во время проверки, отбрасывается
+func (s *FileListSummary) SetEstimate(path string, estimate FileEstimate, err error) {
+	found := false
+	for _, file := range s.files {
+		if file == path {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+
+	if err != nil {
+		s.failed[path] = true
+		delete(s.estimates, path)
+		return
+	}
+	delete(s.failed, path)
+	s.estimates[path] = estimate
+}
+
+// Pending возвращает количество файлов, оценка которых еще не получена
+func (s *FileListSummary) Pending() int {
+	pending := 0
+	for _, path := range s.files {
+		if !s.isDone(path) {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Totals возвращает строки и размер оцененных файлов списка
+func (s *FileListSummary) Totals() (rows, bytes int64) {
+	for _, path := range s.files {
+		if estimate, ok := s.estimates[path]; ok {
+			rows += estimate.Rows
+			bytes += estimate.Bytes
+		}
+	}
+	return rows, bytes
+}
+
+// String возвращает строку для подвала списка: "Файлов: 14, строк ≈ 312 000, 640 МБ"
+func (s *FileListSummary) String() string {
+	if len(s.files) == 0 {
+		return "Файлов: 0"
+	}
+	if s.Pending() > 0 {
+		return fmt.Sprintf("Файлов: %d, строк ≈ ?, ≈ ? МБ", len(s.files))
+	}
+
+	rows, bytes := s.Totals()
+	text := fmt.Sprintf("Файлов: %d, строк ≈ %s, %s", len(s.files), formatCount(rows), FormatSize(bytes))
+	if failed := len(s.failed); failed > 0 {
+		text += fmt.Sprintf(" (не удалось оценить: %d)", failed)
+	}
+	return text
+}
+
+// isDone сообщает, что по файлу получена оценка или ошибка
+func (s *FileListSummary) isDone(path string) bool {
+	_, ok := s.estimates[path]
+	return ok || s.failed[path]
+}
+
+// formatCount форматирует число с пробелами между разрядами: 312000 - "312 000"
+func formatCount(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFileListSummary(t *testing.T) {
+	summary := NewFileListSummary()
+	if got := summary.String(); got != "Файлов: 0" {
+		t.Errorf("пустой список: %q", got)
+	}
+
+	// Добавлены три файла: оценки еще не получены
+	added := summary.SetFiles([]string{"a.xlsx", "b.xlsx", "c.xlsx"})
+	if !reflect.DeepEqual(added, []string{"a.xlsx", "b.xlsx", "c.xlsx"}) {
+		t.Errorf("SetFiles() = %q", added)
+	}
+	if got := summary.String(); got != "Файлов: 3, строк ≈ ?, ≈ ? МБ" {
+		t.Errorf("до оценок: %q", got)
+	}
+
+	summary.SetEstimate("a.xlsx", FileEstimate{Rows: 300000, Bytes: 600 << 20}, nil)
+	summary.SetEstimate("b.xlsx", FileEstimate{Rows: 12000, Bytes: 40 << 20}, nil)
+	if summary.Pending() != 1 {
+		t.Errorf("Pending() = %d, ожидался 1", summary.Pending())
+	}
+	summary.SetEstimate("c.xlsx", FileEstimate{}, errors.New("не архив"))
+	if got := summary.String(); got != "Файлов: 3, строк ≈ 312 000, 640 МБ (не удалось оценить: 1)" {
+		t.Errorf("после оценок: %q", got)
+	}
+
+	// Убранный файл выпадает из итога, новый ждет оценки; известные оценки не пересчитываются
+	added = summary.SetFiles([]string{"a.xlsx", "d.xlsx"})
+	if !reflect.DeepEqual(added, []string{"d.xlsx"}) {
+		t.Errorf("SetFiles() = %q, ожидался только новый файл", added)
+	}
+	if got := summary.String(); got != "Файлов: 2, строк ≈ ?, ≈ ? МБ" {
+		t.Errorf("после изменения списка: %q", got)
+	}
+	// Оценка файла, убранного во время проверки, отбрасывается
+	summary.SetEstimate("b.xlsx", FileEstimate{Rows: 1, Bytes: 1}, nil)
+	summary.SetEstimate("d.xlsx", FileEstimate{Rows: 5, Bytes: 1 << 30}, nil)
+	if rows, bytes := summary.Totals(); rows != 300005 || bytes != 600<<20+1<<30 {
+		t.Errorf("Totals() = %d, %d", rows, bytes)
+	}
+	if got := summary.String(); got != "Файлов: 2, строк ≈ 300 005, 1,6 ГБ" {
+		t.Errorf("итог: %q", got)
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := map[int64]string{0: "0", 999: "999", 1000: "1 000", 312000: "312 000", 1234567: "1 234 567", -4500: "-4 500"}
+	for n, want := range tests {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, ожидалось %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxExcludedSamples наибольшее количество примеров исключенных строк одного фильтра в одном файле
+const maxExcludedSamples = 3
+
+// maxTypoChecks наибольшее количество разных исключенных значений, проверяемых на сходство
+// со значениями фильтра после того, как примеры уже собраны; ограничивает стоимость проверки
+const maxTypoChecks = 100
+
+// ExcludedSample пример строки, исключенной фильтром при объединении
+type ExcludedSample struct {
+	Key    string `json:"key,omitempty"`  // Значение ключевого столбца строки, например артикул (пусто - ключ не определен)
+	Column string `json:"column"`         // Заголовок столбца, значение которого не прошло фильтр
+	Value  string `json:"value"`          // Значение этого столбца
+	Typo   bool   `json:"typo,omitempty"` // Значение похоже на одно из значений фильтра: вероятная опечатка
+}
+
+// String возвращает пример для отчета: "Бренд 'Shuzi' (опечатка?), строка 'A1'"
+func (s ExcludedSample) String() string {
+	text := fmt.Sprintf("%s '%s'", s.Column, s.Value)
+	if s.Value == "" {
+		text = s.Column + " пусто"
+	}
+	if s.Typo {
+		text += " (опечатка?)"
+	}
+	if s.Key != "" {
+		text += fmt.Sprintf(", строка '%s'", s.Key)
+	}
+	return text
+}
+
+// exclusionSampler считает строки, исключенные одним фильтром в одном файле, и запоминает
+// не больше maxExcludedSamples примеров с разными значениями. Значение, похожее на значение
+// фильтра, заменяет последний пример, если среди примеров еще нет опечатки
+type exclusionSampler struct {
+	column     int      // Проверяемый фильтром столбец
+	header     string   // Заголовок проверяемого столбца
+	keyColumn  int      // Столбец ключа строки (-1 - не определен)
+	candidates []string // Значения фильтра для поиска опечаток (nil - без поиска)
+
+	excluded int
+	samples  []ExcludedSample
+	seen     map[string]bool // Разные значения, уже попавшие в примеры или проверенные на опечатку
+	hasTypo  bool
+}
+
+// newExclusionSampler создает счетчик исключенных строк для столбца column с заголовком из headers
+// keyColumn, совпадающий с column, не выводится отдельно
+func newExclusionSampler(headers []string, column, keyColumn int, candidates []string) *exclusionSampler {
+	header := ColumnIndexToLetter(column)
+	if column >= 0 && column < len(headers) && strings.TrimSpace(headers[column]) != "" {
+		header = strings.TrimSpace(headers[column])
+	}
+	if keyColumn == column {
+		keyColumn = -1
+	}
+	return &exclusionSampler{
+		column:     column,
+		header:     header,
+		keyColumn:  keyColumn,
+		candidates: candidates,
+		seen:       make(map[string]bool),
+	}
+}
+
+// add учитывает строку row, исключенную фильтром; nil-счетчик ничего не делает
+func (s *exclusionSampler) add(row []string) {
+	if s == nil {
+		return
+	}
+	s.excluded++
+
+	// После сбора примеров строки только считаются, пока не найдена опечатка и не исчерпан лимит проверок
+	full := len(s.samples) >= maxExcludedSamples
+	if full && (s.hasTypo || len(s.candidates) == 0 || len(s.seen) >= maxExcludedSamples+maxTypoChecks) {
+		return
+	}
+
+	value := cellAt(row, s.column)
+	key := strings.ToLower(value)
+	if s.seen[key] {
+		return
+	}
+	s.seen[key] = true
+
+	sample := ExcludedSample{Column: s.header, Value: value, Typo: isLikelyTypo(value, s.candidates)}
+	if s.keyColumn >= 0 {
+		sample.Key = cellAt(row, s.keyColumn)
+	}
+	switch {
+	case !full:
+		s.samples = append(s.samples, sample)
+	case sample.Typo:
+		s.samples[len(s.samples)-1] = sample
+	default:
+		return
+	}
+	s.hasTypo = s.hasTypo || sample.Typo
+}
+
+// warning возвращает предупреждение об исключенных строках файла или false, если строк не было
+// Исключение строк фильтром ожидаемо, поэтому уровень - сведения; при вероятной опечатке - предупреждение
+func (s *exclusionSampler) warning(sheetName, fileName, filter string) (Warning, bool) {
+	if s == nil || s.excluded == 0 {
+		return Warning{}, false
+	}
+
+	examples := make([]string, len(s.samples))
+	for i, sample := range s.samples {
+		examples[i] = sample.String()
+	}
+	severity := SeverityInfo
+	if s.hasTypo {
+		severity = SeverityWarning
+	}
+	warning := newWarning(severity, "лист '%s', файл %s: %s исключил строк: %d, например: %s",
+		sheetName, fileName, filter, s.excluded, strings.Join(examples, "; "))
+	warning.Samples = s.samples
+	return warning, true
+}
+
+// cellAt возвращает значение столбца column строки без пробелов по краям (пусто, если столбца нет)
+func cellAt(row []string, column int) string {
+	if column < 0 || column >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[column])
+}
+
+// isLikelyTypo проверяет, что value отличается от одного из значений candidates на одну-две
+// буквы (на одну для коротких значений) без учета регистра, но не совпадает с ним
+func isLikelyTypo(value string, candidates []string) bool {
+	if value == "" {
+		return false
+	}
+	v := []rune(strings.ToLower(value))
+	for _, candidate := range candidates {
+		c := []rune(strings.ToLower(strings.TrimSpace(candidate)))
+		if len(c) == 0 {
+			continue
+		}
+		limit := 2
+		if len(c) <= 4 {
+			limit = 1
+		}
+		if d := editDistance(v, c, limit); d > 0 && d <= limit {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance возвращает расстояние Левенштейна между a и b или limit+1, если оно больше limit
+func editDistance(a, b []rune, limit int) int {
+	if diff := len(a) - len(b); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		best := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			best = min(best, curr[j])
+		}
+		if best > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
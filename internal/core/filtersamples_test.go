@@ -0,0 +1,142 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExclusionSampler(t *testing.T) {
+	headers := []string{"Артикул", "Бренд"}
+	filter := []string{"Shuzzi"}
+
+	var rows [][]string
+	for i := range 1000 {
+		rows = append(rows, []string{fmt.Sprintf("A%d", i), fmt.Sprintf("Other%d", i%50)})
+	}
+	rows = append(rows, []string{"T1", "shuzi"}, []string{"T2", "Shuzzi"}, []string{"T3"})
+
+	sampler := newExclusionSampler(headers, 1, 0, filter)
+	kept := filterRowsCountingMatches(rows, 1, filter, make([]int, 1), sampler)
+
+	if len(kept) != 1 || sampler.excluded != 1002 {
+		t.Fatalf("оставлено %d, исключено %d, ожидалось 1 и 1002", len(kept), sampler.excluded)
+	}
+	// Примеров не больше лимита, значения разные, опечатка заменяет последний пример
+	if len(sampler.samples) != maxExcludedSamples {
+		t.Fatalf("примеров %d, ожидалось %d", len(sampler.samples), maxExcludedSamples)
+	}
+	want := []ExcludedSample{
+		{Key: "A0", Column: "Бренд", Value: "Other0"},
+		{Key: "A1", Column: "Бренд", Value: "Other1"},
+		{Key: "T1", Column: "Бренд", Value: "shuzi", Typo: true},
+	}
+	for i := range want {
+		if sampler.samples[i] != want[i] {
+			t.Errorf("пример %d = %+v, ожидался %+v", i, sampler.samples[i], want[i])
+		}
+	}
+	// Проверка на опечатки ограничена
+	if len(sampler.seen) > maxExcludedSamples+maxTypoChecks {
+		t.Errorf("проверено значений %d, лимит %d", len(sampler.seen), maxExcludedSamples+maxTypoChecks)
+	}
+
+	warning, ok := sampler.warning("Шаблон", "a.xlsx", "фильтр по значению столбца")
+	if !ok || warning.Severity != SeverityWarning || len(warning.Samples) != maxExcludedSamples {
+		t.Fatalf("предупреждение = %+v", warning)
+	}
+	if !strings.Contains(warning.Message, "исключил строк: 1002") ||
+		!strings.Contains(warning.Message, "Бренд 'shuzi' (опечатка?), строка 'T1'") {
+		t.Errorf("текст предупреждения: %s", warning.Message)
+	}
+
+	// Без исключенных строк предупреждения нет
+	if _, ok := newExclusionSampler(headers, 1, 0, filter).warning("Шаблон", "a.xlsx", "фильтр"); ok {
+		t.Error("предупреждение без исключенных строк")
+	}
+	// nil-счетчик не мешает фильтрации
+	if kept := filterRowsByArticleColumn([][]string{{"A"}, {"B"}}, 0, map[string]bool{"A": true}, nil); len(kept) != 1 {
+		t.Errorf("фильтрация без счетчика оставила %d строк", len(kept))
+	}
+}
+
+func TestIsLikelyTypo(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"Shuzi", true},
+		{"SHUZZY", true},
+		{"Shuzzi", false},
+		{"Nord", false},
+		{"", false},
+		{"Lum", true},
+		{"Lxmx", false},
+	}
+	for _, tt := range tests {
+		if got := isLikelyTypo(tt.value, []string{"Shuzzi", " Lumo "}); got != tt.want {
+			t.Errorf("isLikelyTypo(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestMergeFilesFilterSamples тестирует отчет об исключенных строках каждого фильтра по файлам
+func TestMergeFilesFilterSamples(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	cleanPath := filepath.Join(dir, "clean.xlsx")
+
+	template := []string{"Артикул", "Бренд"}
+	video := []string{"Артикул", "Ссылка"}
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Шаблон", [][]string{template, {"A1", "Shuzzi"}}},
+		{"Видео", [][]string{video}},
+	})
+	writeTestWorkbookSheets(t, sourcePath, []testSheet{
+		{"Шаблон", [][]string{template, {"A2", "Shuzi"}, {"A3", "Nord"}, {"A4", "Shuzzi"}}},
+		{"Видео", [][]string{video, {"A4", "v4"}, {"A9", "v9"}}},
+	})
+	writeTestWorkbookSheets(t, cleanPath, []testSheet{
+		{"Шаблон", [][]string{template, {"A5", "shuzzi"}}},
+		{"Видео", [][]string{video, {"A5", "v5"}}},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Шаблон": {SheetName: "Шаблон", Enabled: true, HeaderRow: 1, FilterColumn: 1, FilterValues: []string{"Shuzzi"}},
+		"Видео":  {SheetName: "Видео", Enabled: true, HeaderRow: 1, FilterColumn: -1, UseTemplateArticles: true},
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath, cleanPath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка объединения: %v", err)
+	}
+	defer result.Close()
+
+	var sampled []Warning
+	for _, warning := range result.Warnings {
+		if len(warning.Samples) > 0 {
+			sampled = append(sampled, warning)
+		}
+	}
+	if len(sampled) != 2 {
+		t.Fatalf("отчетов об исключенных строках %d, ожидалось 2: %v", len(sampled), result.Warnings)
+	}
+
+	brand := sampled[0]
+	if brand.Severity != SeverityWarning || !strings.Contains(brand.Message, "source.xlsx") ||
+		!strings.Contains(brand.Message, "исключил строк: 2") ||
+		!strings.Contains(brand.Message, "Бренд 'Shuzi' (опечатка?), строка 'A2'") ||
+		!strings.Contains(brand.Message, "Бренд 'Nord', строка 'A3'") {
+		t.Errorf("отчет фильтра по бренду: %+v", brand)
+	}
+
+	articles := sampled[1]
+	if articles.Severity != SeverityInfo || !strings.Contains(articles.Message, "листа 'Шаблон'") ||
+		!strings.Contains(articles.Message, "исключил строк: 1") || articles.Samples[0].Value != "A9" {
+		t.Errorf("отчет фильтра по артикулам: %+v", articles)
+	}
+}
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// HeaderStatus состояние заголовков листа файла по сравнению с базовым файлом
+type HeaderStatus string
+
+// Состояния заголовков листа в отчете
+const (
+	HeaderMatch      HeaderStatus = "match"      // Все столбцы базового листа есть, лишних нет
+	HeaderMismatch   HeaderStatus = "mismatch"   // Части столбцов базового листа нет или есть лишние
+	HeaderMissing    HeaderStatus = "missing"    // Листа нет в файле
+	HeaderUnreadable HeaderStatus = "unreadable" // Файл или строку заголовков не удалось прочитать
+)
+
+// HeaderCheck результат проверки заголовков одного листа одного файла
+// Столбцы сравниваются так же, как при объединении: по имени, без учета регистра и пробелов
+type HeaderCheck struct {
+	Status    HeaderStatus
+	Missing   []string // Столбцы базового листа, которых нет в файле
+	Extra     []string // Столбцы файла, которых нет в базовом листе
+	Reordered bool     // Столбцы совпадают, но идут в другом порядке (на объединение не влияет)
+	Err       error    // Причина HeaderUnreadable
+}
+
+// HeaderReport матрица проверки заголовков: файлы × листы
+type HeaderReport struct {
+	Sheets []string        // Проверенные листы базового файла
+	Files  []string        // Пути проверенных файлов
+	Checks [][]HeaderCheck // Checks[i][j] - лист Sheets[j] файла Files[i]
+}
+
+// ProblemCount возвращает количество листов файлов с несовпадающими заголовками,
+// отсутствующих и непрочитанных
+func (r *HeaderReport) ProblemCount() int {
+	count := 0
+	for _, row := range r.Checks {
+		for _, check := range row {
+			if check.Status != HeaderMatch {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// CheckHeaders проверяет заголовки включенных листов профиля во всех файлах
+// Базовые заголовки читаются из базового файла; в файлах лист ищется так же, как при
+// объединении, с учетом альтернативных имен. Ошибка возвращается, только если не удалось
+// прочитать базовый файл; проблемы отдельных файлов попадают в отчет
+func (a *BaseAnalyzer) CheckHeaders(baseFile string, files []string, sheets []SheetConfig) (*HeaderReport, error) {
+	if err := a.CheckEnabledSheets(baseFile, sheets); err != nil {
+		return nil, err
+	}
+
+	var enabled []SheetConfig
+	for _, sheet := range sheets {
+		if sheet.Enabled {
+			enabled = append(enabled, sheet)
+		}
+	}
+
+	baseHeaders := make([][]string, len(enabled))
+	for j, sheet := range enabled {
+		headers, err := a.GetHeaders(baseFile, sheet.SheetName, sheet.HeaderRow)
+		if err != nil {
+			return nil, fmt.Errorf("лист '%s' базового файла: %w", sheet.SheetName, err)
+		}
+		baseHeaders[j] = headers
+	}
+
+	report := &HeaderReport{
+		Files:  append([]string(nil), files...),
+		Checks: make([][]HeaderCheck, len(files)),
+	}
+	for _, sheet := range enabled {
+		report.Sheets = append(report.Sheets, sheet.SheetName)
+	}
+	for i, path := range files {
+		report.Checks[i] = a.checkFileHeaders(path, enabled, baseHeaders)
+	}
+
+	a.log().Info("проверка заголовков файлов",
+		"files", len(files), "sheets", len(enabled), "problems", report.ProblemCount())
+	return report, nil
+}
+
+// checkFileHeaders проверяет заголовки листов sheets одного файла
+func (a *BaseAnalyzer) checkFileHeaders(path string, sheets []SheetConfig, baseHeaders [][]string) []HeaderCheck {
+	checks := make([]HeaderCheck, len(sheets))
+
+	reader, release, err := a.borrowReader(path)
+	if err != nil {
+		a.log().Warn("не удалось открыть файл для проверки заголовков", "path", path, "error", err)
+		for j := range checks {
+			checks[j] = HeaderCheck{Status: HeaderUnreadable, Err: err}
+		}
+		return checks
+	}
+	defer release()
+
+	sheetNames := reader.GetSheetNames()
+	for j, sheet := range sheets {
+		sourceSheet, ok := locateSourceSheet(sheetNames, sheet.SheetName, &sheet)
+		if !ok {
+			checks[j] = HeaderCheck{Status: HeaderMissing}
+			continue
+		}
+		headers, err := reader.GetHeaderRow(sourceSheet, sheet.HeaderRow)
+		if err != nil {
+			checks[j] = HeaderCheck{Status: HeaderUnreadable, Err: err}
+			continue
+		}
+		checks[j] = compareHeaders(baseHeaders[j], headers)
+	}
+	return checks
+}
+
+// compareHeaders сравнивает заголовки листа файла с заголовками базового листа
+// Пустые заголовки не учитываются ни в базовом листе, ни в файле
+func compareHeaders(baseHeaders, sourceHeaders []string) HeaderCheck {
+	check := HeaderCheck{Status: HeaderMatch}
+
+	mapping := MatchColumnsByName(baseHeaders, sourceHeaders)
+	matched := make(map[int]bool, len(mapping))
+	last := -1
+	for i, idx := range mapping {
+		if strings.TrimSpace(baseHeaders[i]) == "" {
+			continue
+		}
+		if idx < 0 {
+			check.Missing = append(check.Missing, strings.TrimSpace(baseHeaders[i]))
+			continue
+		}
+		matched[idx] = true
+		if idx < last {
+			check.Reordered = true
+		}
+		last = idx
+	}
+
+	for i, header := range sourceHeaders {
+		if header = strings.TrimSpace(header); header != "" && !matched[i] {
+			check.Extra = append(check.Extra, header)
+		}
+	}
+
+	if len(check.Missing) > 0 || len(check.Extra) > 0 {
+		check.Status = HeaderMismatch
+	}
+	return check
+}
+
+// String возвращает описание результата проверки для отчета
+func (c HeaderCheck) String() string {
+	switch c.Status {
+	case HeaderMissing:
+		return "нет листа"
+	case HeaderUnreadable:
+		if c.Err != nil {
+			return fmt.Sprintf("не удалось прочитать: %v", c.Err)
+		}
+		return "не удалось прочитать"
+	case HeaderMismatch:
+		var parts []string
+		if len(c.Missing) > 0 {
+			parts = append(parts, "нет столбцов: "+strings.Join(c.Missing, ", "))
+		}
+		if len(c.Extra) > 0 {
+			parts = append(parts, "лишние столбцы: "+strings.Join(c.Extra, ", "))
+		}
+		return strings.Join(parts, "; ")
+	default:
+		if c.Reordered {
+			return "совпадает (другой порядок столбцов)"
+		}
+		return "совпадает"
+	}
+}
+
+// Rows возвращает отчет в виде таблицы: строка заголовков "Файл" и имена листов,
+// затем по строке на файл с описанием результата каждого листа
+func (r *HeaderReport) Rows() [][]string {
+	rows := make([][]string, 0, len(r.Files)+1)
+	rows = append(rows, append([]string{"Файл"}, r.Sheets...))
+	for i, path := range r.Files {
+		row := make([]string, 0, len(r.Sheets)+1)
+		row = append(row, filepath.Base(path))
+		for _, check := range r.Checks[i] {
+			row = append(row, check.String())
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Export сохраняет отчет в файл path: CSV для расширения .csv, иначе книга xlsx
+// CSV записывается в UTF-8 с меткой BOM и разделителем ";", чтобы Excel открыл его без настройки
+func (r *HeaderReport) Export(path string) error {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return r.exportCSV(path)
+	}
+
+	writer, err := excel.NewStreamWriter("Заголовки")
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	for _, row := range r.Rows() {
+		if err := writer.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return writer.SaveAtomic(path)
+}
+
+// exportCSV сохраняет отчет в CSV-файл
+func (r *HeaderReport) exportCSV(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл отчета: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(utf8BOM); err != nil {
+		return fmt.Errorf("не удалось записать файл отчета: %w", err)
+	}
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+	if err := writer.WriteAll(r.Rows()); err != nil {
+		return fmt.Errorf("не удалось записать файл отчета: %w", err)
+	}
+	return file.Close()
+}
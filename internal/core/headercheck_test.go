@@ -0,0 +1,205 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+func TestCompareHeaders(t *testing.T) {
+	base := []string{"Артикул", "Цена", "Остаток", ""}
+
+	tests := []struct {
+		name    string
+		source  []string
+		want    HeaderStatus
+		missing []string
+		extra   []string
+		reorder bool
+	}{
+		{"совпадают", []string{"Артикул", "Цена", "Остаток"}, HeaderMatch, nil, nil, false},
+		{"регистр и пробелы", []string{" артикул", "ЦЕНА ", "Остаток", ""}, HeaderMatch, nil, nil, false},
+		{"другой порядок", []string{"Цена", "Артикул", "Остаток"}, HeaderMatch, nil, nil, true},
+		{"нет столбца", []string{"Артикул", "Остаток"}, HeaderMismatch, []string{"Цена"}, nil, false},
+		{"лишний столбец", []string{"Артикул", "Цена", "Остаток", "Комментарий"}, HeaderMismatch, nil, []string{"Комментарий"}, false},
+		{"переименован столбец", []string{"Артикул", "Стоимость", "Остаток"}, HeaderMismatch, []string{"Цена"}, []string{"Стоимость"}, false},
+		{"пустая строка заголовков", nil, HeaderMismatch, []string{"Артикул", "Цена", "Остаток"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareHeaders(base, tt.source)
+			if got.Status != tt.want {
+				t.Errorf("Status = %s, ожидалось %s", got.Status, tt.want)
+			}
+			if !reflect.DeepEqual(got.Missing, tt.missing) {
+				t.Errorf("Missing = %q, ожидалось %q", got.Missing, tt.missing)
+			}
+			if !reflect.DeepEqual(got.Extra, tt.extra) {
+				t.Errorf("Extra = %q, ожидалось %q", got.Extra, tt.extra)
+			}
+			if got.Reordered != tt.reorder {
+				t.Errorf("Reordered = %v, ожидалось %v", got.Reordered, tt.reorder)
+			}
+		})
+	}
+}
+
+func TestCheckHeaders(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	prices := []string{"Артикул", "Цена"}
+	stock := []string{"Артикул", "Склад", "Остаток"}
+	basePath := filepath.Join(dir, "base.xlsx")
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Цены", [][]string{prices, {"A1", "100"}}},
+		{"Остатки", [][]string{{"Выгрузка склада"}, stock}},
+	})
+
+	files := map[string][]testSheet{
+		// Оба листа совпадают, лист цен найден по альтернативному имени
+		"good.xlsx": {
+			{"Price", [][]string{prices}},
+			{"Остатки", [][]string{{""}, {"Артикул", "Склад", "Остаток"}}},
+		},
+		// Переименованный столбец и отсутствующий лист
+		"bad.xlsx": {
+			{"Цены", [][]string{{"Артикул", "Стоимость"}}},
+		},
+		// Заголовки в другом порядке и лишний столбец
+		"mixed.xlsx": {
+			{"цены ", [][]string{{"Цена", "Артикул"}}},
+			{"Остатки", [][]string{{""}, {"Артикул", "Склад", "Остаток", "Примечание"}}},
+		},
+	}
+	var paths []string
+	for _, name := range []string{"good.xlsx", "bad.xlsx", "mixed.xlsx"} {
+		path := filepath.Join(dir, name)
+		writeTestWorkbookSheets(t, path, files[name])
+		paths = append(paths, path)
+	}
+	// Файл, который не открывается как книга
+	brokenPath := filepath.Join(dir, "broken.xlsx")
+	if err := os.WriteFile(brokenPath, []byte("not a workbook"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	paths = append(paths, brokenPath)
+
+	sheets := []SheetConfig{
+		{SheetName: "Цены", Enabled: true, HeaderRow: 1, SourceSheetNames: []string{"Price"}},
+		{SheetName: "Остатки", Enabled: true, HeaderRow: 2},
+		{SheetName: "Отключен", Enabled: false, HeaderRow: 1},
+	}
+
+	analyzer := NewBaseAnalyzer(nil, logger)
+	report, err := analyzer.CheckHeaders(basePath, paths, sheets)
+	if err != nil {
+		t.Fatalf("ошибка проверки заголовков: %v", err)
+	}
+
+	if !reflect.DeepEqual(report.Sheets, []string{"Цены", "Остатки"}) {
+		t.Fatalf("Sheets = %q, ожидались только включенные листы", report.Sheets)
+	}
+
+	want := [][]HeaderStatus{
+		{HeaderMatch, HeaderMatch},
+		{HeaderMismatch, HeaderMissing},
+		{HeaderMatch, HeaderMismatch},
+		{HeaderUnreadable, HeaderUnreadable},
+	}
+	for i, row := range want {
+		for j, status := range row {
+			if got := report.Checks[i][j].Status; got != status {
+				t.Errorf("%s, лист '%s': %s, ожидалось %s",
+					filepath.Base(paths[i]), report.Sheets[j], got, status)
+			}
+		}
+	}
+	if !report.Checks[2][0].Reordered {
+		t.Error("другой порядок столбцов в mixed.xlsx не отмечен")
+	}
+	if got := report.ProblemCount(); got != 5 {
+		t.Errorf("ProblemCount() = %d, ожидалось 5", got)
+	}
+
+	rows := report.Rows()
+	wantRows := [][]string{
+		{"Файл", "Цены", "Остатки"},
+		{"good.xlsx", "совпадает", "совпадает"},
+		{"bad.xlsx", "нет столбцов: Цена; лишние столбцы: Стоимость", "нет листа"},
+		{"mixed.xlsx", "совпадает (другой порядок столбцов)", "лишние столбцы: Примечание"},
+	}
+	if !reflect.DeepEqual(rows[:len(wantRows)], wantRows) {
+		t.Errorf("Rows() = %q, ожидалось %q", rows[:len(wantRows)], wantRows)
+	}
+	if !strings.HasPrefix(rows[4][1], "не удалось прочитать") {
+		t.Errorf("непрочитанный файл в отчете: %q", rows[4][1])
+	}
+
+	// Без листа в базовом файле отчет не строится
+	sheets[0].SheetName = "Нет такого"
+	if _, err := analyzer.CheckHeaders(basePath, paths, sheets); !errors.Is(err, apperrors.ErrSheetNotFound) {
+		t.Errorf("ошибка = %v, ожидалась ErrCodeSheetNotFound", err)
+	}
+}
+
+func TestHeaderReportExport(t *testing.T) {
+	dir := t.TempDir()
+	report := &HeaderReport{
+		Sheets: []string{"Цены"},
+		Files:  []string{filepath.Join(dir, "a.xlsx"), filepath.Join(dir, "b.xlsx")},
+		Checks: [][]HeaderCheck{
+			{{Status: HeaderMatch}},
+			{{Status: HeaderMismatch, Missing: []string{"Цена"}, Extra: []string{"Стоимость; руб"}}},
+		},
+	}
+	want := report.Rows()
+
+	t.Run("xlsx", func(t *testing.T) {
+		path := filepath.Join(dir, "report.xlsx")
+		if err := report.Export(path); err != nil {
+			t.Fatalf("ошибка экспорта: %v", err)
+		}
+		reader, err := excel.NewReader(path)
+		if err != nil {
+			t.Fatalf("не удалось открыть отчет: %v", err)
+		}
+		defer reader.Close()
+		got, err := reader.GetRows("Заголовки")
+		if err != nil {
+			t.Fatalf("не удалось прочитать отчет: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("строки отчета %q, ожидалось %q", got, want)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		path := filepath.Join(dir, "report.csv")
+		if err := report.Export(path); err != nil {
+			t.Fatalf("ошибка экспорта: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(data, utf8BOM) {
+			t.Error("CSV без метки BOM: Excel откроет его в неверной кодировке")
+		}
+		// Значение с разделителем берется в кавычки
+		wantCSV := "Файл;Цены\na.xlsx;совпадает\nb.xlsx;\"нет столбцов: Цена; лишние столбцы: Стоимость; руб\"\n"
+		if got := string(data[len(utf8BOM):]); got != wantCSV {
+			t.Errorf("CSV:\n%s\nожидалось:\n%s", got, wantCSV)
+		}
+	})
+}
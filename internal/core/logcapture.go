@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DatKorso/Merge-excel/internal/logger"
+)
+
+// maxCapturedLogLines количество записей журнала одного объединения, сохраняемых в MergeResult
+const maxCapturedLogLines = 2000
+
+// deterministicRunID идентификатор запуска в воспроизводимом режиме объединения
+const deterministicRunID = "00000000-000000-0000"
+
+// newRunID создает идентификатор запуска объединения или сеанса анализа
+func newRunID() string {
+	return fmt.Sprintf("%s-%04x", time.Now().Format("20060102-150405"), rand.IntN(0x10000))
+}
+
+// logCapture накапливает записи журнала одного объединения в текстовом виде
+// Безопасен для одновременной записи из нескольких горутин
+type logCapture struct {
+	mu      sync.Mutex
+	lines   []string
+	limit   int
+	dropped int
+}
+
+// newLogCapture создает накопитель, хранящий не более limit записей
+func newLogCapture(limit int) *logCapture {
+	return &logCapture{limit: limit}
+}
+
+// Write принимает одну отформатированную запись журнала
+func (c *logCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.lines) < c.limit {
+		c.lines = append(c.lines, strings.TrimRight(string(p), "\n"))
+	} else {
+		c.dropped++
+	}
+	return len(p), nil
+}
+
+// Lines возвращает накопленные записи; при превышении лимита последней строкой
+// указывается количество отброшенных записей
+func (c *logCapture) Lines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lines := make([]string, len(c.lines), len(c.lines)+1)
+	copy(lines, c.lines)
+	if c.dropped > 0 {
+		lines = append(lines, fmt.Sprintf("... еще %d записей не сохранено", c.dropped))
+	}
+	return lines
+}
+
+// teeHandler передает записи основному хендлеру без изменений
+// и дополнительно сохраняет их в журнал объединения
+type teeHandler struct {
+	main    slog.Handler
+	capture slog.Handler
+}
+
+// newTeeHandler создает хендлер, дублирующий записи в capture
+// Пути в сохраненных записях скрываются так же, как в файле журнала: их копируют и пересылают
+func newTeeHandler(main slog.Handler, capture *logCapture) *teeHandler {
+	return &teeHandler{
+		main:    main,
+		capture: logger.NewRedactHandler(slog.NewTextHandler(capture, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+}
+
+func (h *teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.main.Enabled(ctx, level) || h.capture.Enabled(ctx, level)
+}
+
+func (h *teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var mainErr error
+	if h.main.Enabled(ctx, r.Level) {
+		mainErr = h.main.Handle(ctx, r.Clone())
+	}
+	return errors.Join(mainErr, h.capture.Handle(ctx, r))
+}
+
+func (h *teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &teeHandler{main: h.main.WithAttrs(attrs), capture: h.capture.WithAttrs(attrs)}
+}
+
+func (h *teeHandler) WithGroup(name string) slog.Handler {
+	return &teeHandler{main: h.main.WithGroup(name), capture: h.capture.WithGroup(name)}
+}
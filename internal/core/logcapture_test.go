@@ -0,0 +1,229 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+	"github.com/DatKorso/Merge-excel/internal/logger"
+)
+
+// TestMergeFilesCapturesRunLog тестирует сохранение журнала объединения в результат
+func TestMergeFilesCapturesRunLog(t *testing.T) {
+	var mainLog bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&mainLog, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	basePath := filepath.Join(t.TempDir(), "base.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Артикул", "Цена"},
+		{"A1", "100"},
+	})
+	// Источник без нужного листа дает предупреждение во время объединения
+	otherPath := filepath.Join(t.TempDir(), "other.xlsx")
+	exceltest.WriteWorkbook(t, otherPath, "Другой", [][]string{{"Артикул"}})
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+
+	merger := NewMerger(nil, logger)
+	logger.Info("запись до объединения")
+	result, err := merger.MergeFiles(basePath, []string{otherPath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+	merger.logger.Info("запись после объединения")
+
+	if merger.logger != logger {
+		t.Error("после объединения должен использоваться исходный логгер")
+	}
+	if result.RunID == "" {
+		t.Fatal("RunID не заполнен")
+	}
+
+	captured := strings.Join(result.LogLines, "\n")
+	for _, want := range []string{"начало объединения файлов", "обработка листа", "объединение завершено"} {
+		if !strings.Contains(captured, want) {
+			t.Errorf("журнал объединения не содержит %q:\n%s", want, captured)
+		}
+	}
+	for _, unwanted := range []string{"запись до объединения", "запись после объединения"} {
+		if strings.Contains(captured, unwanted) {
+			t.Errorf("журнал объединения содержит постороннюю запись %q", unwanted)
+		}
+	}
+	for _, line := range result.LogLines {
+		if !strings.Contains(line, "run_id="+result.RunID) {
+			t.Errorf("запись без идентификатора запуска: %s", line)
+		}
+	}
+
+	// В основном журнале идентификатор запуска есть у каждой записи объединения
+	// и отсутствует у записей до и после него
+	mainLines := strings.Split(strings.TrimSpace(mainLog.String()), "\n")
+	if len(mainLines) != len(result.LogLines)+2 {
+		t.Fatalf("в основном журнале %d записей, ожидалось %d", len(mainLines), len(result.LogLines)+2)
+	}
+	for i, line := range mainLines {
+		inRun := i > 0 && i < len(mainLines)-1
+		if strings.Contains(line, "run_id="+result.RunID) != inRun {
+			t.Errorf("запись %d: run_id ожидался = %v: %s", i, inRun, line)
+		}
+	}
+}
+
+// TestMergeFilesRunLoggerIsScoped тестирует, что run_id и журнал запуска не попадают
+// в записи других вызовов объединителя, сделанных во время объединения
+func TestMergeFilesRunLoggerIsScoped(t *testing.T) {
+	var mainLog bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&mainLog, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	basePath := filepath.Join(t.TempDir(), "base.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул"}, {"A1"}})
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+
+	merger := NewMerger(nil, logger)
+	estimated := false
+	merger.SetProgressCallback(func(current, total int, message string) {
+		if !estimated {
+			estimated = true
+			if _, err := merger.EstimateFiles(basePath, nil, sheetConfigs); err != nil {
+				t.Errorf("EstimateFiles() error = %v", err)
+			}
+		}
+	})
+	result, err := merger.MergeFiles(basePath, nil, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	if !estimated {
+		t.Fatal("оценка во время объединения не выполнялась")
+	}
+	for _, line := range result.LogLines {
+		if strings.Contains(line, "оценка объединения") {
+			t.Errorf("журнал объединения содержит запись другого вызова: %s", line)
+		}
+	}
+	for _, line := range strings.Split(mainLog.String(), "\n") {
+		if strings.Contains(line, "оценка объединения") && strings.Contains(line, "run_id=") {
+			t.Errorf("запись другого вызова помечена идентификатором запуска: %s", line)
+		}
+	}
+}
+
+// TestMergeFilesCapturesRunLogOnError тестирует сохранение журнала объединения, завершившегося ошибкой
+func TestMergeFilesCapturesRunLogOnError(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+
+	missingPath := filepath.Join(t.TempDir(), "missing.xlsx")
+	result, err := NewMerger(nil, logger).MergeFiles(missingPath, nil, sheetConfigs)
+	if err == nil {
+		result.Close()
+		t.Fatal("ожидалась ошибка для несуществующего базового файла")
+	}
+	if result == nil {
+		t.Fatal("при ошибке ожидался результат с журналом")
+	}
+	if result.WorkbookData != nil {
+		t.Error("при ошибке книги в результате быть не должно")
+	}
+	if result.RunID == "" {
+		t.Error("RunID не заполнен")
+	}
+	if captured := strings.Join(result.LogLines, "\n"); !strings.Contains(captured, "начало объединения файлов") {
+		t.Errorf("журнал объединения не содержит начала запуска:\n%s", captured)
+	}
+}
+
+// TestLogCaptureRedactsPaths тестирует скрытие путей в сохраненном журнале объединения
+func TestLogCaptureRedactsPaths(t *testing.T) {
+	dir := t.TempDir()
+	logger.SetRedaction(true, []string{dir})
+	t.Cleanup(func() { logger.SetRedaction(false, nil) })
+
+	var mainLog bytes.Buffer
+	capture := newLogCapture(10)
+	runLogger := slog.New(newTeeHandler(logger.NewRedactHandler(slog.NewTextHandler(&mainLog, nil)), capture))
+	runLogger.Info("файл обработан: "+filepath.Join(dir, "a.xlsx"), "file", filepath.Join(dir, "b.xlsx"))
+
+	lines := capture.Lines()
+	if len(lines) != 1 {
+		t.Fatalf("записей = %d, ожидалась 1", len(lines))
+	}
+	for name, text := range map[string]string{"журнал объединения": lines[0], "основной журнал": mainLog.String()} {
+		if strings.Contains(text, dir) {
+			t.Errorf("%s содержит путь пользователя: %s", name, text)
+		}
+		if !strings.Contains(text, filepath.Join("~", "a.xlsx")) || !strings.Contains(text, filepath.Join("~", "b.xlsx")) {
+			t.Errorf("%s без скрытых путей: %s", name, text)
+		}
+	}
+}
+
+// TestLogCaptureConcurrentAndBounded тестирует одновременную запись и ограничение размера
+func TestLogCaptureConcurrentAndBounded(t *testing.T) {
+	capture := newLogCapture(50)
+	logger := slog.New(newTeeHandler(slog.DiscardHandler, capture))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				logger.Debug("запись", "goroutine", g, "i", i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	lines := capture.Lines()
+	if len(lines) != 51 {
+		t.Fatalf("записей = %d, ожидалось 50 и строка об отброшенных", len(lines))
+	}
+	if want := fmt.Sprintf("еще %d записей", 30); !strings.Contains(lines[50], want) {
+		t.Errorf("последняя строка = %q, ожидалось упоминание %q", lines[50], want)
+	}
+}
+
+// TestAnalyzerSessionRunID тестирует пометку записей анализатора идентификатором сеанса
+func TestAnalyzerSessionRunID(t *testing.T) {
+	var buf bytes.Buffer
+	analyzer := NewBaseAnalyzer(nil, slog.New(slog.NewTextHandler(&buf, nil)))
+
+	basePath := filepath.Join(t.TempDir(), "base.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул"}, {"Бренд"}})
+
+	first := analyzer.StartSession()
+	if col, err := analyzer.FindBrandColumnInFirstRows(basePath, "Data", 1); err != nil || col != -1 {
+		t.Fatalf("FindBrandColumnInFirstRows() = %d, %v; ожидался -1 без ошибки", col, err)
+	}
+	second := analyzer.StartSession()
+	analyzer.FindBrandColumnInFirstRows(basePath, "Data", 1)
+
+	if first == "" || first == second {
+		t.Fatalf("идентификаторы сеансов должны быть разными: %q, %q", first, second)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("записей = %d, ожидалось 2:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{first, second} {
+		if !strings.Contains(lines[i], "run_id="+want) {
+			t.Errorf("запись %d без run_id=%s: %s", i, want, lines[i])
+		}
+	}
+}
@@ -1,16 +1,24 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
 	"github.com/DatKorso/Merge-excel/internal/excel"
 )
 
+// rowLimitWarningThreshold количество строк, при котором выдается предупреждение о приближении к лимиту Excel
+const rowLimitWarningThreshold = excel.MaxExcelRows / 10 * 9
+
 // ProgressCallback функция обратного вызова для обновления прогресса
 type ProgressCallback func(current, total int, message string)
 
@@ -27,7 +35,33 @@ type Merger struct {
 	progressCallback ProgressCallback
 	logger           *slog.Logger
 	mu               sync.Mutex
-	templateArticles map[string]bool // Уникальные артикулы из листа "Шаблон" для Ozon пресета
+	templateArticles map[string]bool         // Уникальные артикулы из листа "Шаблон" для Ozon пресета
+	settings         ProfileSettings         // Настройки профиля, влияющие на объединение
+	headerStyles     *excel.HeaderStyles     // Оформление заголовков из шаблона (nil - без оформления)
+	outputs          map[string]*outputSheet // Заполненные листы результата по имени
+	breakdowns       map[string]*valueTally  // Разбивка записанных строк по значениям столбца по листу базового файла
+	mergeIntoBase    bool                    // Результат строится на копии базового файла
+	deterministic    bool                    // Воспроизводимый результат: без меток времени и случайных значений
+
+	fingerprints map[string]excel.Fingerprints // Отпечатки открытых при объединении файлов по пути
+
+	readers *excel.ReaderPool // Пул открытых книг сеанса (nil - каждый файл открывается заново)
+
+	// openReader выдает книгу для чтения и функцию ее возврата; в тестах подменяется для подсчета открытий
+	openReader func(path string) (*excel.Reader, func(), error)
+
+	// newWriter создает книгу результата; в тестах подменяется для уменьшения лимита строк
+	newWriter func(baseFilePath string, mergeIntoBase bool) (*excel.Writer, error)
+}
+
+// outputSheet состояние листа результата, в который могут дописываться несколько листов базового файла
+type outputSheet struct {
+	firstSheet string   // Лист базового файла, создавший лист результата
+	headers    []string // Заголовки, с которыми должны совпадать дописываемые листы
+
+	// Строки листа по ключу склейки дубликатов: с ними склеиваются дубликаты дописываемых листов
+	keyColumn int                  // Столбец ключа склейки (-1 - склейки не было)
+	keys      map[string]*keyedRow // Строки листа по значению ключа
 }
 
 // NewMerger создает новый объединитель файлов
@@ -36,19 +70,65 @@ func NewMerger(reader *excel.Reader, logger *slog.Logger) *Merger {
 		logger = slog.Default()
 	}
 
-	return &Merger{
+	m := &Merger{
 		reader: reader,
 		logger: logger,
 	}
+	m.openReader = m.borrowReader
+	m.newWriter = newResultWriter
+	return m
+}
+
+// SetReaderPool задает пул открытых книг, общий с анализатором базового файла
+// Файлы, уже открытые при проверках, не открываются повторно при объединении;
+// по завершении MergeFiles книги пула закрываются
+func (m *Merger) SetReaderPool(pool *excel.ReaderPool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readers = pool
+}
+
+// closeReaders закрывает книги пула сеанса
+func (m *Merger) closeReaders() {
+	m.mu.Lock()
+	pool := m.readers
+	m.mu.Unlock()
+	pool.CloseAll()
+}
+
+// borrowReader выдает книгу из пула сеанса
+func (m *Merger) borrowReader(path string) (*excel.Reader, func(), error) {
+	m.mu.Lock()
+	pool := m.readers
+	m.mu.Unlock()
+	return pool.Borrow(path)
 }
 
 // SetProgressCallback устанавливает функцию обратного вызова для прогресса
+// Callback вызывается только внутри MergeFiles: после возврата из него прогресс не отправляется
 func (m *Merger) SetProgressCallback(callback ProgressCallback) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.progressCallback = callback
 }
 
+// SetDeterministic включает воспроизводимое объединение для тестов и CI
+// Одинаковые входные файлы дают побайтно одинаковую книгу: время в свойствах книги
+// заменяется на excel.FixedDocTimestamp, а идентификатор запуска - на deterministicRunID.
+// Порядок листов и строк от режима не зависит: он всегда определяется именами листов и порядком файлов
+func (m *Merger) SetDeterministic(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deterministic = enabled
+}
+
+// SetSettings устанавливает настройки профиля, используемые при объединении
+func (m *Merger) SetSettings(settings ProfileSettings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settings = settings
+}
+
 // notifyProgress уведомляет о прогрессе выполнения
 func (m *Merger) notifyProgress(current, total int, message string) {
 	m.mu.Lock()
@@ -68,19 +148,133 @@ type MergeResult struct {
 	TotalRows       int                    // Общее количество объединенных строк
 	SheetStats      map[string]*SheetStat  // Статистика по листам
 	Duration        time.Duration          // Время выполнения
-	Warnings        []string               // Предупреждения при обработке
+	Warnings        []Warning              // Предупреждения при обработке
+	WarningCounts   map[Severity]int       // Количество предупреждений по уровням важности
+	RunID           string                 // Идентификатор запуска объединения
+	LogLines        []string               // Записи журнала этого объединения (не более maxCapturedLogLines)
+	Estimate        *MergeEstimate         // Прогноз, показанный перед объединением (nil - оценка не выполнялась)
+	HashAlgorithm   string                 // Алгоритм отпечатков InputHashes и OutputHash
+	InputHashes     []FileHash             // Отпечатки прочитанных файлов: базовый первым, затем источники по порядку
+	OutputHash      *FileHash              // Отпечаток сохраненного результата (nil - результат еще не сохранен через Save)
+	DuplicateFiles  []DuplicateFile        // Файлы с тем же содержимым, что у файла раньше в списке, и решение по ним
+}
+
+// FileHash отпечаток содержимого файла, участвовавшего в объединении
+type FileHash struct {
+	Path string
+	excel.Fingerprint
 }
 
 // SheetStat статистика по листу
 type SheetStat struct {
-	RowsMerged int
-	FilesCount int
+	RowsMerged  int
+	FilesCount  int
+	GroupColumn string       // Заголовок столбца разбивки (пусто - разбивки нет)
+	ValueCounts []ValueCount // Записанные строки по значениям столбца разбивки, по убыванию количества
+}
+
+// Close закрывает объединенную книгу и освобождает ресурсы
+func (r *MergeResult) Close() error {
+	if r == nil || r.WorkbookData == nil {
+		return nil
+	}
+	return r.WorkbookData.Close()
+}
+
+// Save атомарно сохраняет объединенную книгу в path и записывает отпечаток результата в OutputHash
+func (r *MergeResult) Save(path string) error {
+	if err := r.WorkbookData.SaveAtomic(path); err != nil {
+		return err
+	}
+	if fingerprint, ok := r.WorkbookData.SavedFingerprints().Get(r.HashAlgorithm); ok {
+		r.OutputHash = &FileHash{Path: path, Fingerprint: fingerprint}
+	}
+	return nil
+}
+
+// ErrOutputExists файл результата уже существует, а политика OnExistingCancel запрещает его изменять
+var ErrOutputExists = errors.New("файл результата уже существует")
+
+// SaveWithPolicy сохраняет объединенную книгу в path с учетом политики onExisting
+// (OnExisting*) для уже существующего файла; пустая политика перезаписывает файл.
+// При OnExistingCancel файл не изменяется, а возвращается ошибка с ErrOutputExists
+func (r *MergeResult) SaveWithPolicy(path, onExisting string) error {
+	if _, err := os.Stat(path); err != nil {
+		return r.Save(path)
+	}
+
+	switch onExisting {
+	case OnExistingAppend:
+		return r.AppendTo(path)
+	case OnExistingCancel:
+		return apperrors.NewSaveError(path, ErrOutputExists)
+	default:
+		return r.Save(path)
+	}
+}
+
+// AppendTo дописывает объединенные строки в листы существующей книги path
+// Листы сопоставляются по имени (см. excel.Writer.AppendTo); книга сохраняется атомарно,
+// а ее отпечаток записывается в OutputHash
+func (r *MergeResult) AppendTo(path string) error {
+	target, err := excel.NewWriterFromFile(path)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	if err := r.WorkbookData.AppendTo(target); err != nil {
+		return apperrors.NewSaveError(path, err)
+	}
+	if err := target.SaveAtomic(path); err != nil {
+		return err
+	}
+	if fingerprint, ok := target.SavedFingerprints().Get(r.HashAlgorithm); ok {
+		r.OutputHash = &FileHash{Path: path, Fingerprint: fingerprint}
+	}
+	return nil
 }
 
 // MergeFiles объединяет несколько Excel файлов согласно конфигурации
 // baseFilePath - путь к базовому файлу (его данные тоже будут включены)
 // filePaths - список дополнительных файлов для объединения
-func (m *Merger) MergeFiles(baseFilePath string, filePaths []string, sheetConfigs map[string]*SheetConfig) (*MergeResult, error) {
+// При ошибке результирующая книга закрывается; при успехе ее закрывает вызывающий через MergeResult.Close
+// В режиме MergeIntoBase результат строится на копии базового файла: его листы, строки и оформление
+// сохраняются, данные дополнительных файлов дописываются после строк базового листа
+// Паника при объединении возвращается как ошибка ErrCodeInternal.
+// При ошибке возвращается результат без книги: только RunID и LogLines для разбора причины
+func (m *Merger) MergeFiles(baseFilePath string, filePaths []string, sheetConfigs map[string]*SheetConfig) (result *MergeResult, err error) {
+	// Все записи этого объединения помечаются идентификатором запуска и дополнительно
+	// сохраняются в результат. Логгер запуска передается параметром: поле m.logger общее
+	// для всех вызовов и не меняется
+	m.mu.Lock()
+	deterministic := m.deterministic
+	m.mu.Unlock()
+	runID := newRunID()
+	if deterministic {
+		runID = deterministicRunID
+	}
+	capture := newLogCapture(maxCapturedLogLines)
+	logger := slog.New(newTeeHandler(m.logger.Handler(), capture)).With("run_id", runID)
+
+	defer apperrors.Recover(logger, "объединение файлов", func(panicErr error) {
+		result = &MergeResult{RunID: runID, LogLines: capture.Lines()}
+		err = panicErr
+	})
+
+	// Книги пула сеанса после объединения больше не нужны: освобождаем занятую ими память
+	defer m.closeReaders()
+
+	result, err = m.mergeFiles(logger, runID, deterministic, baseFilePath, filePaths, sheetConfigs)
+	if err != nil {
+		result = &MergeResult{RunID: runID}
+	}
+	result.LogLines = capture.Lines()
+	return result, err
+}
+
+// mergeFiles выполняет объединение для MergeFiles; записи журнала пишутся в logger запуска runID
+func (m *Merger) mergeFiles(logger *slog.Logger, runID string, deterministic bool, baseFilePath string, filePaths []string, sheetConfigs map[string]*SheetConfig) (_ *MergeResult, err error) {
 	if baseFilePath == "" {
 		return nil, fmt.Errorf("путь к базовому файлу не указан")
 	}
@@ -89,7 +283,7 @@ func (m *Merger) MergeFiles(baseFilePath string, filePaths []string, sheetConfig
 		return nil, fmt.Errorf("нет листов для обработки")
 	}
 
-	m.logger.Info("начало объединения файлов",
+	logger.Info("начало объединения файлов",
 		"base_file", baseFilePath,
 		"additional_files_count", len(filePaths),
 		"sheets_count", len(sheetConfigs),
@@ -97,48 +291,127 @@ func (m *Merger) MergeFiles(baseFilePath string, filePaths []string, sheetConfig
 
 	result := &MergeResult{
 		SheetStats: make(map[string]*SheetStat),
-		Warnings:   []string{},
+		Warnings:   []Warning{},
+		RunID:      runID,
+	}
+
+	m.mu.Lock()
+	settings := m.settings
+	m.mu.Unlock()
+
+	if err := excel.ValidateHashAlgorithm(settings.HashAlgorithm); err != nil {
+		return nil, apperrors.NewConfigError(err.Error())
 	}
+	result.HashAlgorithm = excel.NormalizeHashAlgorithm(settings.HashAlgorithm)
 
-	// Создаем новый Writer для результата
-	writer := excel.NewWriter()
+	// Одинаковые файлы удвоили бы строки: копии находятся по содержимому до чтения книг
+	var duplicateWarnings []Warning
+	filePaths, result.DuplicateFiles, duplicateWarnings = m.resolveDuplicateFiles(logger, baseFilePath, filePaths, settings.DuplicateFiles, result.HashAlgorithm)
+	result.Warnings = append(result.Warnings, duplicateWarnings...)
+
+	// Создаем Writer для результата: новую книгу или копию базового файла
+	writer, err := m.newWriter(baseFilePath, settings.MergeIntoBase)
+	if err != nil {
+		return nil, err
+	}
+	// Книга закрывается при ошибке и при панике, когда ошибка еще не присвоена
+	completed := false
+	defer func() {
+		if !completed {
+			writer.Close()
+		}
+	}()
+	writer.SetAutoSplit(settings.AutoSplitLargeSheets)
 	result.WorkbookData = writer
 
 	// Инициализируем карту для артикулов
 	m.templateArticles = make(map[string]bool)
+	m.outputs = make(map[string]*outputSheet)
+	m.breakdowns = make(map[string]*valueTally)
+	m.mergeIntoBase = settings.MergeIntoBase
+	m.fingerprints = make(map[string]excel.Fingerprints)
+
+	// Загружаем оформление заголовков; без шаблона объединение продолжается без оформления
+	m.headerStyles = nil
+	if settings.StyleTemplatePath != "" {
+		styles, err := excel.LoadHeaderStyles(settings.StyleTemplatePath)
+		if err != nil {
+			warning := newWarning(SeverityWarning, "не удалось загрузить шаблон оформления %s: %v",
+				filepath.Base(settings.StyleTemplatePath), err)
+			result.Warnings = append(result.Warnings, warning)
+			logger.Warn(warning.Message, "path", settings.StyleTemplatePath, "error", err)
+		} else {
+			m.headerStyles = styles
+		}
+	}
+
+	// Необычно большие файлы читаются дольше и занимают больше памяти: предупреждаем заранее
+	result.Warnings = append(result.Warnings, m.largeFileWarnings(logger, append([]string{baseFilePath}, filePaths...))...)
+
+	// Лист "Шаблон" находится без учета регистра и пробелов в имени
+	templateName, templateConfig, hasTemplate := LookupSheetConfig(sheetConfigs, templateSheetName)
+
+	// Включенные листы: "Шаблон" первым, остальные в порядке имен
+	var enabledSheets []string
+	if hasTemplate && templateConfig.Enabled {
+		enabledSheets = append(enabledSheets, templateName)
+	}
+	for _, sheetName := range sortedSheetNames(sheetConfigs) {
+		if (!hasTemplate || sheetName != templateName) && sheetConfigs[sheetName].Enabled {
+			enabledSheets = append(enabledSheets, sheetName)
+		}
+	}
 
-	// Вычисляем общее количество операций для прогресса
-	// +1 для базового файла
+	// Операции прогресса - чтения листов: каждый включенный лист базового файла
+	// читается один раз, затем тот же лист читается из каждого дополнительного файла
 	totalFiles := 1 + len(filePaths)
-	totalOperations := len(sheetConfigs) * totalFiles
+	totalOperations := len(enabledSheets) * totalFiles
 	currentOperation := 0
 
+	base, err := m.loadBaseWorkbook(logger, settings, baseFilePath, enabledSheets, sheetConfigs, &currentOperation, totalOperations)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(result.Warnings, base.warnings...)
+	sheetConfigs = base.resolveConfigs(sheetConfigs)
+	if hasTemplate {
+		templateConfig = sheetConfigs[templateName]
+	}
+
+	// Ошибки листов, пропущенных в режиме OnErrorContinue
+	sheetErrs := apperrors.NewMultiError()
+	templateFailed := false
+
 	// Сначала обрабатываем лист "Шаблон", если он есть (для Ozon пресета)
-	templateConfig, hasTemplate := sheetConfigs["Шаблон"]
 	if hasTemplate && templateConfig.Enabled {
-		m.logger.Info("обработка листа", "sheet", "Шаблон")
+		logger.Info("обработка листа", "sheet", templateName)
 
-		rowsMerged, warnings, err := m.mergeSheetWithWriter(writer, "Шаблон", templateConfig, baseFilePath, filePaths, &currentOperation, totalOperations)
+		sheetStart := currentOperation
+		rowsMerged, warnings, err := m.mergeSheetWithWriter(logger, writer, templateName, templateConfig, base, filePaths, &currentOperation, totalOperations)
+		result.Warnings = append(result.Warnings, warnings...)
 		if err != nil {
-			writer.Close()
-			return nil, fmt.Errorf("ошибка при обработке листа '%s': %w", "Шаблон", err)
-		}
-
-		result.SheetStats["Шаблон"] = &SheetStat{
-			RowsMerged: rowsMerged,
-			FilesCount: totalFiles,
+			if err := m.skipFailedSheet(logger, writer, result, settings, templateName, templateConfig, err); err != nil {
+				return nil, err
+			}
+			m.skipSheetProgress(&currentOperation, sheetStart+len(filePaths), totalOperations, templateName)
+			sheetErrs.Append(err)
+			templateFailed = true
+		} else {
+			result.SheetStats[templateName] = m.sheetStat(templateName, rowsMerged, totalFiles)
+			result.TotalRows += rowsMerged
+			result.ProcessedSheets++
+
+			logger.Info("лист 'Шаблон' обработан, извлечено артикулов", "count", len(m.templateArticles))
 		}
-		result.TotalRows += rowsMerged
-		result.Warnings = append(result.Warnings, warnings...)
-		result.ProcessedSheets++
-
-		m.logger.Info("лист 'Шаблон' обработан, извлечено артикулов", "count", len(m.templateArticles))
 	}
 
-	// Обрабатываем остальные листы
-	for sheetName, sheetConfig := range sheetConfigs {
+	// Обрабатываем остальные листы в порядке имен: листы, объединяемые
+	// в один лист результата, дописываются в предсказуемом порядке
+	for _, sheetName := range sortedSheetNames(sheetConfigs) {
+		sheetConfig := sheetConfigs[sheetName]
+
 		// Пропускаем уже обработанный лист "Шаблон"
-		if sheetName == "Шаблон" {
+		if hasTemplate && sheetName == templateName {
 			continue
 		}
 
@@ -146,188 +419,400 @@ func (m *Merger) MergeFiles(baseFilePath string, filePaths []string, sheetConfig
 			continue
 		}
 
-		m.logger.Info("обработка листа", "sheet", sheetName)
+		// Без артикулов из пропущенного листа "Шаблон" фильтрация невозможна:
+		// лист попал бы в результат без фильтрации
+		if templateFailed && sheetConfig.UseTemplateArticles {
+			err := fmt.Errorf("фильтрация по артикулам невозможна без листа 'Шаблон'")
+			if err := m.skipFailedSheet(logger, writer, result, settings, sheetName, sheetConfig, err); err != nil {
+				return nil, err
+			}
+			m.skipSheetProgress(&currentOperation, currentOperation+len(filePaths), totalOperations, sheetName)
+			sheetErrs.Append(err)
+			continue
+		}
 
-		rowsMerged, warnings, err := m.mergeSheetWithWriter(writer, sheetName, sheetConfig, baseFilePath, filePaths, &currentOperation, totalOperations)
+		logger.Info("обработка листа", "sheet", sheetName)
+
+		sheetStart := currentOperation
+		rowsMerged, warnings, err := m.mergeSheetWithWriter(logger, writer, sheetName, sheetConfig, base, filePaths, &currentOperation, totalOperations)
+		result.Warnings = append(result.Warnings, warnings...)
 		if err != nil {
-			writer.Close()
-			return nil, fmt.Errorf("ошибка при обработке листа '%s': %w", sheetName, err)
+			if err := m.skipFailedSheet(logger, writer, result, settings, sheetName, sheetConfig, err); err != nil {
+				return nil, err
+			}
+			m.skipSheetProgress(&currentOperation, sheetStart+len(filePaths), totalOperations, sheetName)
+			sheetErrs.Append(err)
+			continue
 		}
 
-		result.SheetStats[sheetName] = &SheetStat{
-			RowsMerged: rowsMerged,
-			FilesCount: totalFiles,
-		}
+		result.SheetStats[sheetName] = m.sheetStat(sheetName, rowsMerged, totalFiles)
 		result.TotalRows += rowsMerged
-		result.Warnings = append(result.Warnings, warnings...)
 		result.ProcessedSheets++
 	}
 
+	// Все листы пропущены: сохранять нечего
+	if result.ProcessedSheets == 0 && sheetErrs.Len() > 0 {
+		return nil, fmt.Errorf("ни один лист не удалось обработать: %w", sheetErrs)
+	}
+
+	// Условное форматирование переносится после записи всех листов: области правил
+	// продлеваются до последней строки, в том числе строк, дописанных другими листами
+	if err := m.applyConditionalFormats(logger, writer, base, sheetConfigs); err != nil {
+		return nil, err
+	}
+
+	// Пустой Sheet1 новой книги не должен оставаться рядом с листами результата
+	if trimmed, err := writer.TrimDefaultSheet(); err != nil {
+		return nil, err
+	} else if trimmed {
+		logger.Info("удален пустой лист по умолчанию")
+	}
+
+	if deterministic {
+		if err := writer.ResetDocTimestamps(); err != nil {
+			return nil, err
+		}
+	}
+
 	result.ProcessedFiles = totalFiles
+	result.InputHashes = m.inputHashes(append([]string{baseFilePath}, filePaths...), result.HashAlgorithm)
+	result.WarningCounts = countWarnings(result.Warnings)
 
-	m.logger.Info("объединение завершено",
+	logger.Info("объединение завершено",
 		"processed_files", result.ProcessedFiles,
 		"total_rows", result.TotalRows,
 		"processed_sheets", result.ProcessedSheets,
 		"warnings_count", len(result.Warnings),
+		"warnings_by_severity", result.WarningCounts,
 	)
 
+	completed = true
 	return result, nil
 }
 
-// mergeSheet объединяет один лист из всех файлов
-func (m *Merger) mergeSheet(
+// mergeSheetWithWriter объединяет один лист из всех файлов и записывает в Writer
+// Строки базового файла берутся из base; прогресс увеличивается на каждый дополнительный файл
+func (m *Merger) mergeSheetWithWriter(
+	logger *slog.Logger,
+	writer *excel.Writer,
 	sheetName string,
 	config *SheetConfig,
+	base *baseWorkbook,
 	filePaths []string,
 	currentOp *int,
 	totalOps int,
-) (int, []string, error) {
-	var warnings []string
+) (int, []Warning, error) {
+	var warnings []Warning
 	rowsMerged := 0
 
-	for i, filePath := range filePaths {
-		*currentOp++
-		m.notifyProgress(*currentOp, totalOps,
-			fmt.Sprintf("Обработка %s, лист %s (%d/%d)",
-				filepath.Base(filePath), sheetName, i+1, len(filePaths)))
+	// Лист результата уже заполнен другим листом базового файла: дописываем в него
+	outputName := config.OutputSheetName()
+	output, appending := m.outputs[outputName]
 
-		// Открываем файл
-		reader, err := excel.NewReader(filePath)
-		if err != nil {
-			warning := fmt.Sprintf("не удалось открыть файл %s: %v", filepath.Base(filePath), err)
-			warnings = append(warnings, warning)
-			m.logger.Warn(warning, "file", filePath, "error", err)
-			continue
+	// Результат построен на копии базового файла: шапка и строки листа уже на месте
+	inBase := m.mergeIntoBase && !appending && outputName == sheetName && writer.SheetExists(outputName)
+
+	// Создаем лист в результирующей книге
+	if !appending {
+		if !inBase {
+			if err := writer.CreateSheet(outputName); err != nil {
+				return 0, warnings, fmt.Errorf("не удалось создать лист '%s': %w", outputName, err)
+			}
 		}
+		warnings = append(warnings, m.applyTabColor(logger, writer, outputName, config.TabColor)...)
+	}
 
-		// Проверяем наличие листа
-		if !reader.SheetExists(sheetName) {
-			warning := fmt.Sprintf("лист '%s' не найден в файле %s", sheetName, filepath.Base(filePath))
-			warnings = append(warnings, warning)
-			m.logger.Warn(warning, "file", filePath, "sheet", sheetName)
-			reader.Close()
-			continue
+	// Строки базового файла для копирования заголовков и строк до них
+	baseRows, err := base.rows(sheetName)
+	if err != nil {
+		return 0, warnings, err
+	}
+
+	// Заголовки базового листа для проверки совпадения столбцов
+	var baseHeaders []string
+	if config.HeaderRow > 0 && len(baseRows) >= config.HeaderRow {
+		baseHeaders = baseRows[config.HeaderRow-1]
+	}
+
+	// Дописываемый лист должен иметь ту же структуру, что и первый лист
+	if appending && !sameHeaders(output.headers, baseHeaders) {
+		return 0, warnings, apperrors.NewStructureMismatchError(sheetName,
+			fmt.Sprintf("заголовки листа '%s' не совпадают с листом '%s', объединение в лист '%s' невозможно",
+				sheetName, output.firstSheet, outputName),
+			apperrors.WithContext("output_sheet", outputName))
+	}
+
+	// Копируем строки до заголовков включительно (от 1 до headerRow) один раз на лист результата
+	if !appending && config.HeaderRow > 0 && len(baseRows) >= config.HeaderRow {
+		headerRows := baseRows[:config.HeaderRow]
+		if len(config.ConstantColumns) > 0 {
+			headerRows = append([][]string{}, headerRows...)
+			last := len(headerRows) - 1
+			headerRows[last] = appendConstantColumns(headerRows[last], len(headerRows[last]), config.ConstantColumns, true)
+		}
+		if inBase {
+			// Шапка уже в листе: дописываем только постоянные столбцы, не трогая оформление
+			if err := writeBaseConstantColumns(writer, outputName, baseRows, config.HeaderRow, len(baseHeaders), config.ConstantColumns); err != nil {
+				return 0, warnings, fmt.Errorf("не удалось записать постоянные столбцы: %w", err)
+			}
+		} else if err := writer.WriteRows(outputName, 1, headerRows); err != nil {
+			return 0, warnings, fmt.Errorf("не удалось записать заголовки: %w", err)
 		}
+		writer.SetHeaderRows(outputName, headerRows)
+	}
 
-		// Получаем строки данных (без заголовков)
-		dataRows, err := reader.GetDataRows(sheetName, config.HeaderRow)
-		if err != nil {
-			warning := fmt.Sprintf("не удалось прочитать данные из %s: %v",
-				filepath.Base(filePath), err)
-			warnings = append(warnings, warning)
-			m.logger.Warn(warning, "file", filePath, "error", err)
-			reader.Close()
-			continue
+	// Данные пишутся после занятых строк листа результата (шапки, данных предыдущих листов
+	// или данных листа базового файла), но не выше строки после заголовков
+	firstDataRow := config.HeaderRow + 1
+	if appending {
+		firstDataRow = 1
+	}
+
+	// Дописываемый лист при ошибке не должен оставить в листе результата часть своих строк:
+	// записи откладываются и выполняются, когда все файлы листа обработаны и строки помещаются в лист
+	var staged []func() error
+	stagedLastRow := 0
+	// write выполняет запись в лист результата, заканчивающуюся строкой lastRow,
+	// или откладывает ее для дописываемого листа
+	write := func(lastRow int, fn func() error) error {
+		if !appending {
+			return fn()
 		}
+		staged = append(staged, fn)
+		stagedLastRow = max(stagedLastRow, lastRow)
+		return nil
+	}
+	nextRow := func() int {
+		return max(writer.NextRow(outputName), firstDataRow, stagedLastRow+1)
+	}
 
-		// Фильтруем пустые строки
-		dataRows = filterEmptyRows(dataRows)
-		rowsMerged += len(dataRows)
+	// Типы столбцов из строки описания полей и настроек листа
+	columnTypes := resolveColumnTypes(config, baseRows)
+	if config.TypeDescriptorRow > 0 {
+		logger.Info("типы столбцов из строки описания",
+			"sheet", sheetName, "row", config.TypeDescriptorRow, "column_types", FormatColumnTypes(columnTypes))
+	}
 
-		m.logger.Info("файл обработан",
-			"file", filepath.Base(filePath),
-			"sheet", sheetName,
-			"rows_added", len(dataRows),
-		)
+	// Столбцы без преобразований не приводятся к числам и не проверяются по типу
+	rawColumns, rawWarnings := resolveRawTextColumns(config, outputName, baseHeaders)
+	warnings = append(warnings, rawWarnings...)
+	columnTypes = withoutColumns(columnTypes, rawColumns)
 
-		reader.Close()
+	// Числовые столбцы записываются числами
+	writer.SetNumberColumns(outputName, numberColumns(columnTypes))
+
+	// Проверка типов данных столбцов; данные не отбрасываются, только подсчитываются
+	validator := newColumnValidator(columnTypes)
+
+	// Записанные строки считаются по значениям фильтра или столбца разбивки
+	outputHeaders := appendConstantColumns(baseHeaders, len(baseHeaders), config.ConstantColumns, true)
+	tally, tallyWarnings := newSheetTally(config, outputName, outputHeaders)
+	warnings = append(warnings, tallyWarnings...)
+	if tally != nil {
+		m.breakdowns[sheetName] = tally
 	}
 
-	return rowsMerged, warnings, nil
-}
+	// writeData записывает строки данных в лист результата после уже записанных
+	writeData := func(rows [][]string) error {
+		if len(rows) == 0 {
+			return nil
+		}
+		startRow := nextRow()
+		validator.check(rows, startRow)
+		err := write(startRow+len(rows)-1, func() error {
+			if err := writer.WriteRows(outputName, startRow, rows); err != nil {
+				return fmt.Errorf("не удалось записать данные: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		rowsMerged += len(rows)
+		tally.add(rows)
+		return nil
+	}
 
-// mergeSheetWithWriter объединяет один лист из всех файлов и записывает в Writer
-func (m *Merger) mergeSheetWithWriter(
-	writer *excel.Writer,
-	sheetName string,
-	config *SheetConfig,
-	baseFilePath string,
-	filePaths []string,
-	currentOp *int,
-	totalOps int,
-) (int, []string, error) {
-	var warnings []string
-	rowsMerged := 0
+	// Склейка дубликатов: строки всех файлов копятся и записываются после чтения последнего файла
+	keyColumn, strategies, dedupWarnings := resolveDedup(config, outputName, outputHeaders)
+	warnings = append(warnings, dedupWarnings...)
+	for column := range rawColumns {
+		// Значение дубликата берется целиком из первой строки, без объединения
+		delete(strategies, column)
+	}
+	var pendingRows [][]string
+
+	// Строки, уже стоящие в листе результата: дубликаты из файлов склеиваются с ними, а не
+	// дописываются новыми строками. Это строки листа базового файла при объединении в базовый
+	// файл или строки предыдущих листов, дописанных в тот же лист по тому же ключу
+	// Индекс дописываемого листа копируется и заменяет индекс листа результата только после записи
+	var existingRows map[string]*keyedRow
+	if keyColumn >= 0 {
+		existingRows = make(map[string]*keyedRow)
+		if appending && output.keyColumn == keyColumn {
+			existingRows = maps.Clone(output.keys)
+		}
+	}
+	if keyColumn >= 0 && inBase && len(baseRows) > config.HeaderRow {
+		// Постоянные столбцы в лист базового файла уже дописаны
+		seeded := make([][]string, len(baseRows)-config.HeaderRow)
+		for i, row := range baseRows[config.HeaderRow:] {
+			seeded[i] = appendConstantColumns(row, len(baseHeaders), config.ConstantColumns, false)
+		}
+		indexKeyedRows(existingRows, seeded, config.HeaderRow+1, keyColumn)
+	}
 
-	// Создаем лист в результирующей книге
-	if err := writer.CreateSheet(sheetName); err != nil {
-		return 0, warnings, fmt.Errorf("не удалось создать лист '%s': %w", sheetName, err)
+	// Разделители между файлами: после склейки дубликатов строки не принадлежат одному файлу,
+	// поэтому разделители не записываются
+	separatorMode := config.SeparatorMode
+	if keyColumn >= 0 && separatorMode != "" && separatorMode != SeparatorNone {
+		warning := newWarning(SeverityWarning,
+			"лист '%s': разделители '%s' не записываются при склейке дубликатов", outputName, separatorMode)
+		warnings = append(warnings, warning)
+		logger.Warn(warning.Message, "sheet", sheetName)
+		separatorMode = SeparatorNone
 	}
 
-	// Открываем базовый файл для копирования заголовков и строк до них
-	baseReader, err := excel.NewReader(baseFilePath)
-	if err != nil {
-		return 0, warnings, fmt.Errorf("не удалось открыть базовый файл: %w", err)
+	// writeSeparator записывает разделитель перед строками файла filePath
+	// Строки-разделители не входят в rowsMerged и не проверяются по типам столбцов
+	writeSeparator := func(filePath string) error {
+		row := nextRow()
+		var separator func() error
+		switch separatorMode {
+		case SeparatorBlankRow:
+			// Пустая строка отделяет файл от строк предыдущих файлов, перед первым файлом не нужна
+			if appending || rowsMerged > 0 {
+				separator = func() error { return writer.WriteRow(outputName, row, nil) }
+			}
+		case SeparatorLabelRow:
+			label := separatorLabel(filepath.Base(filePath))
+			separator = func() error { return writer.WriteLabelRow(outputName, row, label) }
+		}
+		if separator == nil {
+			return nil
+		}
+		return write(row, func() error {
+			if err := separator(); err != nil {
+				return fmt.Errorf("не удалось записать разделитель: %w", err)
+			}
+			return nil
+		})
 	}
-	defer baseReader.Close()
 
-	// Проверяем наличие листа в базовом файле
-	if !baseReader.SheetExists(sheetName) {
-		return 0, warnings, fmt.Errorf("лист '%s' не найден в базовом файле", sheetName)
+	// Замены значений столбцов приводят записи поставщиков к одному виду
+	// Столбцы без преобразований не заменяются
+	valueMapper := newValueMapper(config.ValueMaps, rawColumns)
+	for _, warning := range rawValueMapWarnings(outputName, baseHeaders, config.ValueMaps, rawColumns) {
+		warnings = append(warnings, warning)
+		logger.Warn(warning.Message, "sheet", sheetName)
 	}
 
-	// Получаем все строки из базового файла
-	baseRows, err := baseReader.GetRows(sheetName)
-	if err != nil {
-		return 0, warnings, fmt.Errorf("не удалось прочитать базовый файл: %w", err)
+	// Префиксы и суффиксы столбцов: итог по каждому правилу за все файлы листа
+	affixTotals := make([]affixStats, len(config.AffixRules))
+	for _, warning := range rawAffixWarnings(outputName, baseHeaders, config.AffixRules, rawColumns) {
+		warnings = append(warnings, warning)
+		logger.Warn(warning.Message, "sheet", sheetName)
 	}
 
-	// Копируем строки до заголовков включительно (от 1 до headerRow)
-	if config.HeaderRow > 0 && len(baseRows) >= config.HeaderRow {
-		headerRows := baseRows[:config.HeaderRow]
-		if err := writer.WriteRows(sheetName, 1, headerRows); err != nil {
-			return 0, warnings, fmt.Errorf("не удалось записать заголовки: %w", err)
+	// Строки, совпавшие с каждым значением фильтра, во всех отфильтрованных файлах листа
+	filterMatched := make([]int, len(config.FilterValues))
+	filtered := false
+
+	// Столбец артикула ищется один раз на лист: по нему и извлекаются артикулы листа "Шаблон",
+	// и фильтруются строки, поэтому извлечение и фильтрация не расходятся
+	articleColumn := -1
+	if IsTemplateSheet(sheetName) || config.UseTemplateArticles {
+		articleColumn = findArticleColumn(baseHeaders)
+		if articleColumn < 0 {
+			warning := newWarning(SeverityWarning,
+				"лист '%s': не найден столбец артикула, артикулы листа 'Шаблон' не учитываются", sheetName)
+			warnings = append(warnings, warning)
+			logger.Warn(warning.Message, "sheet", sheetName, "headers", baseHeaders)
+		} else {
+			logger.Info("найден столбец артикула",
+				"sheet", sheetName,
+				"column_index", articleColumn,
+				"column_letter", ColumnIndexToLetter(articleColumn),
+				"header", baseHeaders[articleColumn],
+			)
 		}
 	}
 
-	// Начальная строка для данных (следующая после заголовков)
-	currentRow := config.HeaderRow + 1
+	// Ключ строки в примерах исключенных строк: артикул, иначе столбец склейки дубликатов
+	sampleKeyColumn := articleColumn
+	if sampleKeyColumn < 0 {
+		sampleKeyColumn = keyColumn
+	}
 
 	// Объединяем все файлы (включая базовый)
-	allFiles := append([]string{baseFilePath}, filePaths...)
+	allFiles := append([]string{base.path}, filePaths...)
 
 	// Обрабатываем каждый файл
 	for i, filePath := range allFiles {
-		*currentOp++
-		m.notifyProgress(*currentOp, totalOps,
-			fmt.Sprintf("Обработка %s, лист %s (%d/%d)",
-				filepath.Base(filePath), sheetName, i+1, len(allFiles)))
+		var dataRows [][]string
+		dataHeaders := baseHeaders
+
+		// Строки листа базового файла уже в результате и остаются как есть: без фильтрации и записи
+		keepAsIs := i == 0 && inBase
+		if i == 0 {
+			// Базовый файл уже прочитан и не сверяется сам с собой
+			dataRows = skipPreamble(base.dataRows(sheetName, config.HeaderRow), config)
+		} else {
+			*currentOp++
+			m.notifyProgress(*currentOp, totalOps,
+				fmt.Sprintf("Чтение %s, лист %s (%d/%d)",
+					filepath.Base(filePath), sheetName, i, len(filePaths)))
+
+			var warning *Warning
+			dataRows, dataHeaders, warning = m.readSourceRows(logger, filePath, sheetName, config, baseHeaders)
+			if warning != nil {
+				warnings = append(warnings, *warning)
+				continue
+			}
 
-		// Открываем файл
-		reader, err := excel.NewReader(filePath)
-		if err != nil {
-			warning := fmt.Sprintf("не удалось открыть файл %s: %v", filepath.Base(filePath), err)
-			warnings = append(warnings, warning)
-			m.logger.Warn(warning, "file", filePath, "error", err)
-			continue
+			// Файл только с шапкой ничего не добавляет: сообщаем, чтобы пустая выгрузка поставщика не осталась незамеченной
+			if len(dataRows) == 0 {
+				warning := newWarning(SeverityInfo, "файл %s, лист '%s': нет строк данных", filepath.Base(filePath), sheetName)
+				warnings = append(warnings, warning)
+				logger.Info(warning.Message, "file", filePath, "sheet", sheetName)
+				continue
+			}
 		}
 
-		// Проверяем наличие листа
-		if !reader.SheetExists(sheetName) {
-			warning := fmt.Sprintf("лист '%s' не найден в файле %s", sheetName, filepath.Base(filePath))
-			warnings = append(warnings, warning)
-			m.logger.Warn(warning, "file", filePath, "sheet", sheetName)
-			reader.Close()
-			continue
+		// Заменяем значения до фильтрации, чтобы фильтр видел значения в едином виде
+		if !keepAsIs && len(valueMapper) > 0 {
+			if replaced := mapValues(dataRows, valueMapper); replaced > 0 {
+				logger.Info("заменены значения столбцов",
+					"file", filepath.Base(filePath),
+					"sheet", sheetName,
+					"replaced", replaced,
+				)
+			}
 		}
 
-		// Получаем строки данных (без заголовков)
-		dataRows, err := reader.GetDataRows(sheetName, config.HeaderRow)
-		if err != nil {
-			warning := fmt.Sprintf("не удалось прочитать данные из %s: %v",
-				filepath.Base(filePath), err)
-			warnings = append(warnings, warning)
-			m.logger.Warn(warning, "file", filePath, "error", err)
-			reader.Close()
-			continue
+		// Префиксы и суффиксы добавляются до фильтрации и извлечения артикулов:
+		// артикулы листа "Шаблон" сопоставляются по итоговым значениям
+		if !keepAsIs && len(config.AffixRules) > 0 {
+			modified, skipped, found := applyAffixRules(dataRows, dataHeaders, config.AffixRules, rawColumns)
+			for j, rule := range config.AffixRules {
+				if !found[j] {
+					affixTotals[j].missing = append(affixTotals[j].missing, filepath.Base(filePath))
+					continue
+				}
+				affixTotals[j].modified += modified[j]
+				affixTotals[j].skipped += skipped[j]
+				logger.Info("добавлены префикс и суффикс",
+					"file", filepath.Base(filePath),
+					"sheet", sheetName,
+					"column", rule.Header,
+					"modified", modified[j],
+					"skipped", skipped[j],
+				)
+			}
 		}
 
-		// Фильтруем пустые строки
-		dataRows = filterEmptyRows(dataRows)
-
 		// Применяем фильтрацию по значению столбца, если настроена
-		if config.FilterColumn >= 0 && len(config.FilterValues) > 0 {
+		if !keepAsIs && config.FilterColumn >= 0 && len(config.FilterValues) > 0 {
 			beforeFilter := len(dataRows)
 			
 			// DEBUG: Собираем уникальные значения в столбце для логирования
@@ -339,11 +824,16 @@ func (m *Merger) mergeSheetWithWriter(
 				}
 			}
 			
-			dataRows = filterRowsByColumnValue(dataRows, config.FilterColumn, config.FilterValues)
+			sampler := newExclusionSampler(dataHeaders, config.FilterColumn, sampleKeyColumn, config.FilterValues)
+			dataRows = filterRowsCountingMatches(dataRows, config.FilterColumn, config.FilterValues, filterMatched, sampler)
+			filtered = true
+			if warning, ok := sampler.warning(outputName, filepath.Base(filePath), "фильтр по значению столбца"); ok {
+				warnings = append(warnings, warning)
+			}
 			afterFilter := len(dataRows)
 			excludedCount := beforeFilter - afterFilter
 			
-			m.logger.Info("применена фильтрация по столбцу",
+			logger.Info("применена фильтрация по столбцу",
 				"file", filepath.Base(filePath),
 				"sheet", sheetName,
 				"before_filter", beforeFilter,
@@ -356,22 +846,16 @@ func (m *Merger) mergeSheetWithWriter(
 		}
 
 		// Для листа "Шаблон" извлекаем артикулы после фильтрации (для Ozon пресета)
-		if sheetName == "Шаблон" && len(dataRows) > 0 {
-			// Получаем заголовки
-			var headerRow []string
-			if config.HeaderRow > 0 && len(baseRows) >= config.HeaderRow {
-				headerRow = baseRows[config.HeaderRow-1]
-			}
-			
+		if IsTemplateSheet(sheetName) && len(dataRows) > 0 {
 			// Извлекаем артикулы из обработанных строк
-			articles := extractArticlesFromRows(headerRow, dataRows)
+			articles := extractArticles(dataRows, articleColumn)
 			
 			// Добавляем артикулы в общую карту
 			for article := range articles {
 				m.templateArticles[article] = true
 			}
 			
-			m.logger.Info("извлечены артикулы из листа Шаблон",
+			logger.Info("извлечены артикулы из листа Шаблон",
 				"file", filepath.Base(filePath),
 				"articles_count", len(articles),
 				"total_articles", len(m.templateArticles),
@@ -379,20 +863,18 @@ func (m *Merger) mergeSheetWithWriter(
 		}
 
 		// Применяем фильтрацию по артикулам из листа "Шаблон", если настроена
-		if config.UseTemplateArticles && len(m.templateArticles) > 0 && len(dataRows) > 0 {
+		if !keepAsIs && config.UseTemplateArticles && len(m.templateArticles) > 0 && len(dataRows) > 0 {
 			beforeFilter := len(dataRows)
 			
-			// Получаем заголовки
-			var headerRow []string
-			if config.HeaderRow > 0 && len(baseRows) >= config.HeaderRow {
-				headerRow = baseRows[config.HeaderRow-1]
+			sampler := newExclusionSampler(dataHeaders, articleColumn, -1, nil)
+			dataRows = filterRowsByArticleColumn(dataRows, articleColumn, m.templateArticles, sampler)
+			if warning, ok := sampler.warning(outputName, filepath.Base(filePath), "фильтр по артикулам листа 'Шаблон'"); ok {
+				warnings = append(warnings, warning)
 			}
-			
-			dataRows = filterRowsByArticles(headerRow, dataRows, m.templateArticles)
 			afterFilter := len(dataRows)
 			excludedCount := beforeFilter - afterFilter
 			
-			m.logger.Info("применена фильтрация по артикулам из листа Шаблон",
+			logger.Info("применена фильтрация по артикулам из листа Шаблон",
 				"file", filepath.Base(filePath),
 				"sheet", sheetName,
 				"before_filter", beforeFilter,
@@ -402,89 +884,672 @@ func (m *Merger) mergeSheetWithWriter(
 			)
 		}
 
-		// Записываем данные в результирующий файл
-		if len(dataRows) > 0 {
-			if err := writer.WriteRows(sheetName, currentRow, dataRows); err != nil {
-				reader.Close()
-				return 0, warnings, fmt.Errorf("не удалось записать данные: %w", err)
+		// Добавляем столбцы с фиксированными значениями
+		if !keepAsIs {
+			for j, row := range dataRows {
+				dataRows[j] = appendConstantColumns(row, len(baseHeaders), config.ConstantColumns, false)
 			}
-			currentRow += len(dataRows)
+		}
+
+		// Записываем данные в результирующий файл
+		switch {
+		case keepAsIs:
 			rowsMerged += len(dataRows)
+			tally.add(dataRows)
+		case keyColumn >= 0:
+			pendingRows = append(pendingRows, dataRows...)
+		default:
+			if len(dataRows) > 0 {
+				if err := writeSeparator(filePath); err != nil {
+					return 0, warnings, err
+				}
+			}
+			if err := writeData(dataRows); err != nil {
+				return 0, warnings, err
+			}
 		}
 
-		m.logger.Info("файл обработан",
+		logger.Info("файл обработан",
 			"file", filepath.Base(filePath),
 			"sheet", sheetName,
 			"rows_added", len(dataRows),
 		)
+	}
 
-		reader.Close()
+	if keyColumn >= 0 {
+		dedupedRows, updatedRows := coalesceExisting(dedupRows(pendingRows, keyColumn, strategies), keyColumn, strategies, existingRows)
+		logger.Info("склеены дубликаты",
+			"sheet", sheetName,
+			"key", config.DedupKey,
+			"rows_before", len(pendingRows),
+			"rows_after", len(dedupedRows),
+			"existing_rows_updated", len(updatedRows),
+		)
+		for _, row := range updatedRows {
+			err := write(row.rowNum, func() error {
+				if err := writer.WriteRow(outputName, row.rowNum, row.values); err != nil {
+					return fmt.Errorf("не удалось обновить строку %d: %w", row.rowNum, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return 0, warnings, err
+			}
+		}
+		startRow := nextRow()
+		if err := writeData(dedupedRows); err != nil {
+			return 0, warnings, err
+		}
+		indexKeyedRows(existingRows, dedupedRows, startRow, keyColumn)
+	}
+
+	// Отложенные строки дописываемого листа записываются, только если все помещаются в лист
+	if len(staged) > 0 {
+		if err := writer.CheckRowLimit(outputName, stagedLastRow); err != nil {
+			return 0, warnings, fmt.Errorf("не удалось записать данные: %w", err)
+		}
+		for _, fn := range staged {
+			if err := fn(); err != nil {
+				return 0, warnings, err
+			}
+		}
+	}
+
+	if !appending {
+		m.outputs[outputName] = &outputSheet{firstSheet: sheetName, headers: baseHeaders, keyColumn: keyColumn, keys: existingRows}
+	} else if output.keyColumn == keyColumn {
+		output.keys = existingRows
+	}
+
+	if filtered {
+		filterWarnings := unmatchedFilterWarnings(outputName, config.FilterValues, filterMatched)
+		for _, warning := range filterWarnings {
+			logger.Warn(warning.Message, "sheet", sheetName, "column_index", config.FilterColumn)
+		}
+		warnings = append(warnings, filterWarnings...)
+	}
+
+	for j, rule := range config.AffixRules {
+		affixWarnings := affixTotals[j].warnings(outputName, rule)
+		for _, warning := range affixWarnings {
+			logger.Info(warning.Message, "sheet", sheetName, "severity", warning.Severity)
+		}
+		warnings = append(warnings, affixWarnings...)
+	}
+
+	typeWarnings := validator.warnings(outputName, baseHeaders)
+	for _, warning := range typeWarnings {
+		logger.Warn(warning.Message, "sheet", sheetName, "severity", warning.Severity)
+	}
+	warnings = append(warnings, typeWarnings...)
+
+	// Листы-продолжения получают тот же цвет ярлыка
+	for _, name := range writer.GetSplitSheets(outputName) {
+		warnings = append(warnings, m.applyTabColor(logger, writer, name, config.TabColor)...)
+	}
+
+	// Оформляем заголовки по шаблону (включая листы-продолжения)
+	if m.headerStyles != nil && len(baseHeaders) > 0 {
+		for _, name := range append([]string{outputName}, writer.GetSplitSheets(outputName)...) {
+			if err := writer.ApplyHeaderStyles(name, config.HeaderRow, len(baseHeaders)+len(config.ConstantColumns), m.headerStyles); err != nil {
+				return 0, warnings, fmt.Errorf("не удалось оформить заголовки: %w", err)
+			}
+		}
+	}
+
+	// Защищаем шапку после записи данных, чтобы строки данных остались доступны для изменения
+	if m.settings.ProtectHeaders && !config.SkipHeaderProtection && len(baseHeaders) > 0 {
+		width := len(baseHeaders) + len(config.ConstantColumns)
+		if err := writer.ProtectHeaderRows(outputName, config.HeaderRow, width, m.settings.ProtectionPassword); err != nil {
+			return 0, warnings, fmt.Errorf("не удалось защитить заголовки: %w", err)
+		}
+	}
+
+	// Проверяем лимит строк Excel
+	splitSheets := writer.GetSplitSheets(outputName)
+	lastRow := nextRow() - 1
+	if warning := rowLimitWarning(outputName, splitSheets, lastRow); warning != nil {
+		warnings = append(warnings, *warning)
+		logger.Warn(warning.Message, "sheet", outputName, "split_sheets", splitSheets, "rows", lastRow)
 	}
 
 	return rowsMerged, warnings, nil
 }
 
+// newResultWriter создает книгу результата: новую или копию базового файла,
+// сохраняющую его листы, данные и оформление
+func newResultWriter(baseFilePath string, mergeIntoBase bool) (*excel.Writer, error) {
+	if !mergeIntoBase {
+		return excel.NewWriter(), nil
+	}
+	return excel.NewWriterFromFile(baseFilePath)
+}
+
+// writeBaseConstantColumns дописывает постоянные столбцы в лист базового файла справа от width столбцов:
+// заголовки в строку headerRow, значения в непустые строки данных после нее
+func writeBaseConstantColumns(writer *excel.Writer, sheetName string, baseRows [][]string, headerRow, width int, columns []ConstantColumn) error {
+	if len(columns) == 0 {
+		return nil
+	}
+
+	headers := make([]string, len(columns))
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Header
+		values[i] = column.Value
+	}
+
+	if err := writer.WriteCellsAt(sheetName, headerRow, width+1, headers); err != nil {
+		return err
+	}
+	for i := headerRow; i < len(baseRows); i++ {
+		if isEmptyRow(baseRows[i]) {
+			continue
+		}
+		if err := writer.WriteCellsAt(sheetName, i+1, width+1, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rowLimitWarning возвращает предупреждение о лимите строк Excel для листа результата
+// Разбиение и приближение к лимиту не теряют данные, поэтому это сведения
+func rowLimitWarning(outputName string, splitSheets []string, totalRows int) *Warning {
+	if len(splitSheets) > 0 {
+		warning := newWarning(SeverityInfo, "лист '%s' превысил лимит Excel в %d строк, данные разбиты на листы: %s",
+			outputName, excel.MaxExcelRows, strings.Join(append([]string{outputName}, splitSheets...), ", "))
+		return &warning
+	}
+	if totalRows >= rowLimitWarningThreshold {
+		warning := newWarning(SeverityInfo, "лист '%s' содержит %d строк и приближается к лимиту Excel в %d строк",
+			outputName, totalRows, excel.MaxExcelRows)
+		return &warning
+	}
+	return nil
+}
+
+// skipSheetProgress доводит прогресс до target для листа, чтение которого
+// прервано или не выполнялось, чтобы итог совпадал с числом операций
+func (m *Merger) skipSheetProgress(currentOp *int, target, totalOps int, sheetName string) {
+	if *currentOp >= target {
+		return
+	}
+	*currentOp = target
+	m.notifyProgress(*currentOp, totalOps, fmt.Sprintf("Лист %s пропущен", sheetName))
+}
+
+// skipFailedSheet обрабатывает ошибку листа согласно политике settings.OnError
+// В режиме OnErrorContinue удаляет частично записанный лист из результата
+// и добавляет предупреждение; иначе возвращает ошибку, прерывающую объединение
+func (m *Merger) skipFailedSheet(logger *slog.Logger, writer *excel.Writer, result *MergeResult, settings ProfileSettings, sheetName string, config *SheetConfig, err error) error {
+	if settings.OnError != OnErrorContinue {
+		return fmt.Errorf("ошибка при обработке листа '%s': %w", sheetName, err)
+	}
+
+	// Лист результата, уже заполненный другими листами, сохраняется
+	if _, filled := m.outputs[config.OutputSheetName()]; !filled {
+		m.removeSheet(logger, writer, config.OutputSheetName())
+	}
+
+	warning := newWarning(SeverityError, "лист '%s' пропущен из-за ошибки: %v", sheetName, err)
+	result.Warnings = append(result.Warnings, warning)
+	logger.Warn(warning.Message, "sheet", sheetName, "error", err)
+	return nil
+}
+
+// removeSheet удаляет лист и его листы-продолжения из результирующей книги
+// Ошибки удаления только записываются в журнал: пропуск листа не прерывает объединение
+func (m *Merger) removeSheet(logger *slog.Logger, writer *excel.Writer, sheetName string) {
+	for _, name := range append(writer.GetSplitSheets(sheetName), sheetName) {
+		if !writer.SheetExists(name) {
+			continue
+		}
+		if err := writer.DeleteSheet(name); err != nil {
+			logger.Warn("не удалось удалить лист из результата", "sheet", name, "error", err)
+		}
+	}
+}
+
+// sortedSheetNames возвращает имена листов конфигурации в алфавитном порядке
+func sortedSheetNames(sheetConfigs map[string]*SheetConfig) []string {
+	names := make([]string, 0, len(sheetConfigs))
+	for name := range sheetConfigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sameHeaders сравнивает строки заголовков без учета пробелов по краям
+// и пустых ячеек в конце строки
+func sameHeaders(a, b []string) bool {
+	a, b = trimTrailingEmpty(a), trimTrailingEmpty(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if strings.TrimSpace(a[i]) != strings.TrimSpace(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// trimTrailingEmpty отбрасывает пустые ячейки в конце строки
+func trimTrailingEmpty(row []string) []string {
+	end := len(row)
+	for end > 0 && strings.TrimSpace(row[end-1]) == "" {
+		end--
+	}
+	return row[:end]
+}
+
+// applyTabColor устанавливает цвет ярлыка листа
+// Некорректный цвет не прерывает объединение и возвращается как предупреждение
+func (m *Merger) applyTabColor(logger *slog.Logger, writer *excel.Writer, sheetName, color string) []Warning {
+	if color == "" {
+		return nil
+	}
+
+	if err := writer.SetTabColor(sheetName, color); err != nil {
+		logger.Warn("не удалось установить цвет ярлыка", "sheet", sheetName, "color", color, "error", err)
+		return []Warning{newWarning(SeverityInfo, "лист '%s': %v", sheetName, err)}
+	}
+	return nil
+}
+
+// largeFileWarnings возвращает предупреждения о необычно больших файлах объединения
+// (не меньше excel.LargeFileSize на диске)
+func (m *Merger) largeFileWarnings(logger *slog.Logger, paths []string) []Warning {
+	var warnings []Warning
+	for _, path := range paths {
+		size, large := excel.IsLargeFile(path)
+		if !large {
+			continue
+		}
+		warning := newWarning(SeverityWarning,
+			"файл %s необычно большой (%d МБ): чтение займет больше времени и памяти, крупные листы распаковываются во временные файлы",
+			filepath.Base(path), size>>20)
+		logger.Warn(warning.Message, "file", path, "size_bytes", size)
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// resolveDuplicateFiles находит среди базового файла и filePaths файлы с одинаковым содержимым
+// В режиме DuplicateFilesSkip копии исключаются из возвращаемого списка файлов, иначе остаются
+// с предупреждением. Базовый файл стоит первым и поэтому никогда не считается копией
+func (m *Merger) resolveDuplicateFiles(logger *slog.Logger, baseFilePath string, filePaths []string, policy, algorithm string) ([]string, []DuplicateFile, []Warning) {
+	duplicates := FindDuplicateFiles(append([]string{baseFilePath}, filePaths...), algorithm)
+	if len(duplicates) == 0 {
+		return filePaths, nil, nil
+	}
+
+	skip := policy == DuplicateFilesSkip
+	skipped := make(map[string]int, len(duplicates))
+	warnings := make([]Warning, 0, len(duplicates))
+	for i := range duplicates {
+		duplicate := &duplicates[i]
+		duplicate.Skipped = skip
+		var warning Warning
+		if skip {
+			skipped[duplicate.Path]++
+			warning = newWarning(SeverityInfo, "файл %s пропущен: его содержимое совпадает с файлом %s",
+				filepath.Base(duplicate.Path), filepath.Base(duplicate.Original))
+		} else {
+			warning = newWarning(SeverityWarning, "содержимое файла %s совпадает с файлом %s: его строки повторятся в результате",
+				filepath.Base(duplicate.Path), filepath.Base(duplicate.Original))
+		}
+		logger.Warn(warning.Message, "file", duplicate.Path, "original", duplicate.Original, "skipped", skip)
+		warnings = append(warnings, warning)
+	}
+
+	if !skip {
+		return filePaths, duplicates, warnings
+	}
+	// Копия всегда стоит после оригинала, поэтому путь, указанный несколько раз,
+	// исключается с конца списка, а первое его вхождение остается
+	kept := make([]string, len(filePaths))
+	n := len(kept)
+	for i := len(filePaths) - 1; i >= 0; i-- {
+		if path := filePaths[i]; skipped[path] > 0 {
+			skipped[path]--
+			continue
+		}
+		n--
+		kept[n] = filePaths[i]
+	}
+	return kept[n:], duplicates, warnings
+}
+
+// readSourceRows читает строки данных листа из файла-источника без пустых строк
+// и строку заголовков листа источника. Если задан baseHeaders, файл без единого совпадающего столбца пропускается.
+// Вместо ошибки возвращает предупреждение: проблемный файл не прерывает объединение.
+// Отсутствие листа или совпадающих столбцов - предупреждение, сбой открытия
+// или чтения файла - ошибка, пониженная до предупреждения
+func (m *Merger) readSourceRows(logger *slog.Logger, filePath, sheetName string, config *SheetConfig, baseHeaders []string) ([][]string, []string, *Warning) {
+	candidates := sourceSheetNames(sheetName, config.SourceSheetNames)
+	rows, headers, err := m.loadSourceRows(logger, filePath, candidates, config, baseHeaders)
+	if err == nil {
+		return skipPreamble(rows, config), headers, nil
+	}
+
+	warning := Warning{Severity: SeverityError, Message: err.Error()}
+	switch {
+	case errors.Is(err, apperrors.ErrNoMatchingColumns):
+		warning.Severity = SeverityWarning
+		logger.Warn("нет совпадающих столбцов с базовым листом", "file", filePath, "error", err)
+	case errors.Is(err, apperrors.ErrSheetNotFound):
+		warning = newWarning(SeverityWarning, "лист '%s' не найден в файле %s",
+			strings.Join(candidates, "', '"), filepath.Base(filePath))
+		if config.SourceSheetPattern != "" {
+			warning = newWarning(SeverityWarning, "лист '%s' и листы с '%s' в имени не найдены в файле %s",
+				strings.Join(candidates, "', '"), config.SourceSheetPattern, filepath.Base(filePath))
+		}
+		logger.Warn(warning.Message, "file", filePath, "error", err)
+	default:
+		logger.Warn(warning.Message, "file", filePath, "error", err)
+	}
+	return nil, nil, &warning
+}
+
+// loadSourceRows открывает файл-источник и читает строки данных первого
+// найденного листа из candidates (основное имя листа и его альтернативы) вместе со строкой заголовков
+// Отсутствующий лист и лист без совпадающих столбцов возвращаются как
+// ошибки с кодами ErrCodeSheetNotFound и ErrCodeNoMatchingColumns.
+// Файл закрывается до возврата при любом исходе
+func (m *Merger) loadSourceRows(logger *slog.Logger, filePath string, candidates []string, config *SheetConfig, baseHeaders []string) ([][]string, []string, error) {
+	sheetName := candidates[0]
+	headerRow := config.HeaderRow
+	columns := config.dataColumns
+
+	// Открываем файл
+	reader, release, err := m.openReader(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("не удалось открыть файл %s: %w", filepath.Base(filePath), err)
+	}
+	defer release()
+	m.recordFingerprints(filePath, reader)
+
+	// Проверяем наличие листа; имя может отличаться регистром и пробелами
+	sourceSheet, matched, ok := findSourceSheet(reader.GetSheetNames(), candidates)
+	byPattern := false
+	if !ok && config.SourceSheetPattern != "" {
+		// Лист с непостоянным именем ищется по части имени
+		if names, err := reader.FindSheetsByPattern(config.SourceSheetPattern); err == nil && len(names) > 0 {
+			sourceSheet, ok, byPattern = names[0], true, true
+			if len(names) > 1 {
+				logger.Warn("шаблону имени соответствуют несколько листов, используется первый",
+					"file", filepath.Base(filePath), "pattern", config.SourceSheetPattern, "sheets", names)
+			}
+		}
+	}
+	if !ok {
+		return nil, nil, apperrors.NewSheetNotFoundError(sheetName, filepath.Base(filePath),
+			apperrors.WithContext("candidates", candidates))
+	}
+	switch {
+	case byPattern:
+		logger.Info("лист найден по шаблону имени",
+			"file", filepath.Base(filePath), "sheet", sheetName, "pattern", config.SourceSheetPattern, "source_sheet", sourceSheet)
+	case matched != sheetName:
+		logger.Info("лист найден по альтернативному имени",
+			"file", filepath.Base(filePath), "sheet", sheetName, "source_sheet", sourceSheet)
+	case sourceSheet != sheetName:
+		logger.Info("лист найден без учета регистра и пробелов",
+			"file", filepath.Base(filePath), "sheet", sheetName, "source_sheet", sourceSheet)
+	}
+
+	// Лист читается один раз: строка заголовков нужна и для сверки столбцов, и для правил,
+	// находящих столбец по заголовку в каждом файле
+	rows, err := reader.GetRows(sourceSheet, excel.RowsOptions{RawCellFallback: config.RawCellFallback})
+	if err != nil {
+		return nil, nil, fmt.Errorf("не удалось прочитать данные из %s: %w", filepath.Base(filePath), err)
+	}
+
+	// Диапазон с тем же именем в файле-источнике задает его собственную область данных;
+	// без него используется область базового файла
+	if config.DataRangeName != "" {
+		if dataRange, err := findSheetDataRange(reader, sourceSheet, config.DataRangeName); err == nil {
+			headerRow, columns = dataRange.HeaderRow(), dataRange.LastCol
+		} else {
+			logger.Debug("область данных источника не найдена, используется область базового файла",
+				"file", filepath.Base(filePath), "sheet", sourceSheet, "error", err)
+		}
+	}
+	rows = truncateColumns(rows, columns)
+	var sourceHeaders []string
+	if headerRow <= len(rows) {
+		sourceHeaders = rows[headerRow-1]
+	}
+
+	// Пропускаем файлы без единого столбца из базового листа (не тот файл или лист)
+	if hasHeaders(baseHeaders) && hasHeaders(sourceHeaders) &&
+		countMatchedColumns(MatchColumnsByName(baseHeaders, sourceHeaders)) == 0 {
+		return nil, nil, apperrors.NewNoMatchingColumnsError(filepath.Base(filePath), sheetName)
+	}
+
+	// Строки данных (без заголовков) без пустых строк
+	return filterEmptyRows(rows[min(headerRow, len(rows)):]), sourceHeaders, nil
+}
+
+// recordFingerprints запоминает отпечатки файла path, посчитанные при его открытии
+func (m *Merger) recordFingerprints(path string, reader *excel.Reader) {
+	if _, ok := m.fingerprints[path]; !ok {
+		m.fingerprints[path] = reader.Fingerprints()
+	}
+}
+
+// inputHashes возвращает отпечатки algorithm открытых файлов paths по порядку, пропуская неоткрытые
+func (m *Merger) inputHashes(paths []string, algorithm string) []FileHash {
+	hashes := make([]FileHash, 0, len(paths))
+	for _, path := range paths {
+		if fingerprint, ok := m.fingerprints[path].Get(algorithm); ok {
+			hashes = append(hashes, FileHash{Path: path, Fingerprint: fingerprint})
+		}
+	}
+	return hashes
+}
+
+// MatchColumnsByName сопоставляет столбцы источника со столбцами базового листа по имени
+// Сравнение выполняется без учета регистра и пробелов по краям.
+// Возвращает для каждого столбца базового листа индекс столбца в источнике или -1
+func MatchColumnsByName(baseHeaders, sourceHeaders []string) []int {
+	sourceIndex := make(map[string]int, len(sourceHeaders))
+	for i, header := range sourceHeaders {
+		key := strings.ToLower(strings.TrimSpace(header))
+		if key == "" {
+			continue
+		}
+		if _, exists := sourceIndex[key]; !exists {
+			sourceIndex[key] = i
+		}
+	}
+
+	mapping := make([]int, len(baseHeaders))
+	for i, header := range baseHeaders {
+		mapping[i] = -1
+		key := strings.ToLower(strings.TrimSpace(header))
+		if idx, exists := sourceIndex[key]; exists && key != "" {
+			mapping[i] = idx
+		}
+	}
+
+	return mapping
+}
+
+// countMatchedColumns возвращает количество сопоставленных столбцов
+func countMatchedColumns(mapping []int) int {
+	count := 0
+	for _, idx := range mapping {
+		if idx >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// hasHeaders проверяет, что строка заголовков содержит хотя бы одно непустое значение
+func hasHeaders(headers []string) bool {
+	for _, header := range headers {
+		if strings.TrimSpace(header) != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // filterEmptyRows фильтрует полностью пустые строки
+// Фильтры строк не изменяют входной срез: его могут использовать и после фильтрации
+// (например, кэш листов базового файла)
 func filterEmptyRows(rows [][]string) [][]string {
-	filtered := make([][]string, 0, len(rows))
+	return selectRows(rows, func(row []string) bool { return !isEmptyRow(row) })
+}
 
+// selectRows возвращает новый срез строк rows, для которых keep возвращает true
+// Память под результат выделяется один раз; сами строки не копируются
+func selectRows(rows [][]string, keep func(row []string) bool) [][]string {
+	filtered := make([][]string, 0, len(rows))
 	for _, row := range rows {
-		isEmpty := true
-		for _, cell := range row {
-			if cell != "" {
-				isEmpty = false
-				break
+		if keep(row) {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+// skipPreamble пропускает преамбулу переменной длины перед данными листа
+// Если задан маркер, данные начинаются со строки после первой строки с маркером
+// в столбце DataStartColumn, иначе - с первой строки, где этот столбец заполнен.
+// Без маркера в листе поиск идет по заполненности столбца
+func skipPreamble(rows [][]string, config *SheetConfig) [][]string {
+	column, err := config.DataStartColumnIndex()
+	if err != nil || column < 0 {
+		return rows
+	}
+
+	value := func(row []string) string {
+		if column < len(row) {
+			return strings.TrimSpace(row[column])
+		}
+		return ""
+	}
+
+	if marker := strings.TrimSpace(config.DataStartMarker); marker != "" {
+		for i, row := range rows {
+			if strings.EqualFold(value(row), marker) {
+				return rows[i+1:]
 			}
 		}
+	}
 
-		if !isEmpty {
-			filtered = append(filtered, row)
+	for i, row := range rows {
+		if value(row) != "" {
+			return rows[i:]
 		}
 	}
+	return [][]string{}
+}
 
-	return filtered
+// isEmptyRow проверяет, что в строке нет ни одного значения
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
 }
 
 // filterRowsByColumnValue фильтрует строки, оставляя только те, где значение в указанном столбце совпадает с одним из заданных значений
+// Значения сравниваются без учета регистра и пробелов по краям; входной срез не изменяется (см. filterEmptyRows)
 func filterRowsByColumnValue(rows [][]string, columnIndex int, filterValues []string) [][]string {
+	return filterRowsCountingMatches(rows, columnIndex, filterValues, nil, nil)
+}
+
+// filterRowsCountingMatches фильтрует строки как filterRowsByColumnValue и добавляет в matched[i]
+// количество оставленных строк, совпавших со значением filterValues[i] (nil - без подсчета).
+// Исключенные строки передаются в sampler (nil - без примеров)
+func filterRowsCountingMatches(rows [][]string, columnIndex int, filterValues []string, matched []int, sampler *exclusionSampler) [][]string {
 	if columnIndex < 0 || len(filterValues) == 0 {
 		return rows
 	}
 
-	// Нормализуем значения для фильтрации: trim + lowercase
-	normalizedFilterValues := make([]string, len(filterValues))
+	// Пробелы значений фильтра обрезаются один раз; регистр сравнивается через EqualFold,
+	// поэтому ячейки не копируются в нижнем регистре
+	trimmedFilterValues := make([]string, len(filterValues))
 	for i, val := range filterValues {
-		normalizedFilterValues[i] = strings.ToLower(strings.TrimSpace(val))
+		trimmedFilterValues[i] = strings.TrimSpace(val)
 	}
 
-	filtered := make([][]string, 0, len(rows))
-
-	for _, row := range rows {
-		// Проверяем, что столбец существует в строке
+	return selectRows(rows, func(row []string) bool {
+		// Строка без столбца исключается
 		if columnIndex >= len(row) {
-			// Если столбца нет, исключаем строку
-			continue
+			sampler.add(row)
+			return false
 		}
 
-		// Нормализуем значение ячейки: trim + lowercase
-		cellValue := strings.ToLower(strings.TrimSpace(row[columnIndex]))
-		shouldKeep := false
-
-		// Проверяем, совпадает ли значение ячейки с одним из нужных значений
-		for _, filterValue := range normalizedFilterValues {
-			if cellValue == filterValue {
-				shouldKeep = true
-				break
+		cellValue := strings.TrimSpace(row[columnIndex])
+		keep := false
+		for i, filterValue := range trimmedFilterValues {
+			if !strings.EqualFold(cellValue, filterValue) {
+				continue
 			}
+			if matched == nil {
+				return true
+			}
+			// Совпадение засчитывается каждому значению, в том числе повторенному в фильтре
+			matched[i]++
+			keep = true
+		}
+		if !keep {
+			sampler.add(row)
 		}
+		return keep
+	})
+}
 
-		// Оставляем только строки с нужными значениями
-		if shouldKeep {
-			filtered = append(filtered, row)
+// unmatchedFilterWarnings возвращает предупреждения о значениях фильтра, не совпавших
+// ни с одной строкой файлов листа: чаще всего это опечатка, из-за которой результат пуст
+func unmatchedFilterWarnings(sheetName string, filterValues []string, matched []int) []Warning {
+	var warnings []Warning
+	for i, value := range filterValues {
+		if matched[i] > 0 || strings.TrimSpace(value) == "" {
+			continue
 		}
+		warnings = append(warnings, newWarning(SeverityWarning,
+			"лист '%s': значение фильтра '%s' не найдено ни в одном файле, проверьте написание",
+			sheetName, strings.TrimSpace(value)))
 	}
+	return warnings
+}
 
-	return filtered
+// findArticleColumn находит столбец артикула по строке заголовков
+// Заголовок сравнивается без учета регистра, пробелов и звездочки обязательного поля:
+// сначала ищется точное "Артикул", затем заголовок, начинающийся с "артикул",
+// затем любой заголовок со словом "артикул". Так "Артикул*" шаблона и " артикул " другого
+// листа находятся одинаково, а "Код артикула поставщика" не перехватывает основной столбец.
+// Возвращает 0-based индекс столбца или -1, если столбец не найден
+func findArticleColumn(headers []string) int {
+	normalized := make([]string, len(headers))
+	for i, header := range headers {
+		normalized[i] = strings.TrimSpace(strings.TrimRight(strings.ToLower(strings.TrimSpace(header)), "* "))
+	}
+
+	matchers := []func(string) bool{
+		func(h string) bool { return h == "артикул" },
+		func(h string) bool { return strings.HasPrefix(h, "артикул") },
+		func(h string) bool { return strings.Contains(h, "артикул") },
+	}
+	for _, match := range matchers {
+		for i, header := range normalized {
+			if match(header) {
+				return i
+			}
+		}
+	}
+	return -1
 }
 
 // extractArticlesFromRows извлекает уникальные артикулы из строк данных
@@ -492,26 +1557,19 @@ func filterRowsByColumnValue(rows [][]string, columnIndex int, filterValues []st
 // dataRows - строки данных
 // Возвращает map с уникальными артикулами для быстрого поиска
 func extractArticlesFromRows(headerRow []string, dataRows [][]string) map[string]bool {
-	articles := make(map[string]bool)
-
-	// Ищем столбец "Артикул*" в заголовках
-	articleColIndex := -1
-	for i, header := range headerRow {
-		if strings.Contains(strings.ToLower(header), "артикул") {
-			articleColIndex = i
-			break
-		}
-	}
+	return extractArticles(dataRows, findArticleColumn(headerRow))
+}
 
-	// Если столбец не найден, возвращаем пустой map
-	if articleColIndex == -1 {
+// extractArticles извлекает уникальные непустые артикулы столбца column (-1 - столбец не найден)
+func extractArticles(dataRows [][]string, column int) map[string]bool {
+	articles := make(map[string]bool)
+	if column < 0 {
 		return articles
 	}
 
-	// Извлекаем уникальные артикулы
 	for _, row := range dataRows {
-		if articleColIndex < len(row) {
-			article := strings.TrimSpace(row[articleColIndex])
+		if column < len(row) {
+			article := strings.TrimSpace(row[column])
 			if article != "" {
 				articles[article] = true
 			}
@@ -527,36 +1585,26 @@ func extractArticlesFromRows(headerRow []string, dataRows [][]string) map[string
 // articles - map с разрешенными артикулами
 // Возвращает только строки, артикулы которых есть в articles
 func filterRowsByArticles(headerRow []string, dataRows [][]string, articles map[string]bool) [][]string {
-	if len(articles) == 0 {
-		// Если артикулов нет, возвращаем пустой массив
-		return [][]string{}
-	}
-
-	// Ищем столбец "Артикул*" в заголовках
-	articleColIndex := -1
-	for i, header := range headerRow {
-		if strings.Contains(strings.ToLower(header), "артикул") {
-			articleColIndex = i
-			break
-		}
-	}
+	return filterRowsByArticleColumn(dataRows, findArticleColumn(headerRow), articles, nil)
+}
 
-	// Если столбец не найден, возвращаем пустой массив
-	if articleColIndex == -1 {
-		return [][]string{}
+// filterRowsByArticleColumn оставляет строки, артикул в столбце column которых есть в articles
+// Без артикулов или без столбца (column = -1) не остается ни одной строки.
+// Входной срез не изменяется (см. filterEmptyRows); исключенные строки передаются в sampler (nil - без примеров)
+func filterRowsByArticleColumn(dataRows [][]string, column int, articles map[string]bool, sampler *exclusionSampler) [][]string {
+	if len(articles) == 0 || column < 0 {
+		return selectRows(dataRows, func(row []string) bool {
+			sampler.add(row)
+			return false
+		})
 	}
 
-	// Фильтруем строки
-	filtered := make([][]string, 0, len(dataRows))
-	for _, row := range dataRows {
-		if articleColIndex < len(row) {
-			article := strings.TrimSpace(row[articleColIndex])
-			if articles[article] {
-				filtered = append(filtered, row)
-			}
+	return selectRows(dataRows, func(row []string) bool {
+		keep := column < len(row) && articles[strings.TrimSpace(row[column])]
+		if !keep {
+			sampler.add(row)
 		}
-	}
-
-	return filtered
+		return keep
+	})
 }
 
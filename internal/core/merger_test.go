@@ -1,12 +1,27 @@
 package core
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/xuri/excelize/v2"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
 	"github.com/DatKorso/Merge-excel/internal/excel"
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
 )
 
 func TestNewMerger(t *testing.T) {
@@ -211,6 +226,88 @@ func TestFilterEmptyRows(t *testing.T) {
 	}
 }
 
+func TestFilterRowsKeepInput(t *testing.T) {
+	rowA := []string{"A", "зима"}
+	rowB := []string{"B", "лето"}
+	rowC := []string{"C", " Зима "}
+	empty := []string{"", ""}
+
+	tests := []struct {
+		name   string
+		input  [][]string
+		filter func([][]string) [][]string
+		want   [][]string
+	}{
+		{
+			name:   "все строки остаются",
+			input:  [][]string{rowA, rowB, rowC},
+			filter: filterEmptyRows,
+			want:   [][]string{rowA, rowB, rowC},
+		},
+		{
+			name:   "все строки исключены",
+			input:  [][]string{empty, empty},
+			filter: filterEmptyRows,
+			want:   [][]string{},
+		},
+		{
+			name:  "порядок оставшихся строк сохраняется",
+			input: [][]string{rowA, rowB, empty, rowC},
+			filter: func(rows [][]string) [][]string {
+				return filterRowsByColumnValue(filterEmptyRows(rows), 1, []string{"ЗИМА "})
+			},
+			want: [][]string{rowA, rowC},
+		},
+		{
+			name:  "артикулы: все строки исключены",
+			input: [][]string{rowA, rowB},
+			filter: func(rows [][]string) [][]string {
+				return filterRowsByArticleColumn(rows, 0, map[string]bool{"D": true}, nil)
+			},
+			want: [][]string{},
+		},
+		{
+			name:  "артикулы: без столбца",
+			input: [][]string{rowA, rowB},
+			filter: func(rows [][]string) [][]string {
+				return filterRowsByArticleColumn(rows, -1, map[string]bool{"A": true}, nil)
+			},
+			want: [][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := append([][]string(nil), tt.input...)
+			result := tt.filter(input)
+
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Fatalf("результат %v, ожидалось %v", result, tt.want)
+			}
+			// Входной срез не изменяется: его строки нужны и после фильтрации
+			if !reflect.DeepEqual(input, tt.input) {
+				t.Errorf("входной срез изменен: %v, ожидалось %v", input, tt.input)
+			}
+		})
+	}
+}
+
+func TestBaseWorkbookDataRowsKeepsCache(t *testing.T) {
+	rows := [][]string{{"Артикул"}, {""}, {"ART-1"}, {""}, {"ART-2"}}
+	base := &baseWorkbook{sheets: map[string][][]string{"Data": rows}}
+
+	for range 2 {
+		got := base.dataRows("Data", 1)
+		want := [][]string{{"ART-1"}, {"ART-2"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("dataRows = %v, ожидалось %v", got, want)
+		}
+	}
+	if !reflect.DeepEqual(base.sheets["Data"], [][]string{{"Артикул"}, {""}, {"ART-1"}, {""}, {"ART-2"}}) {
+		t.Errorf("строки листа в кэше изменены: %v", base.sheets["Data"])
+	}
+}
+
 func TestFilterRowsByColumnValue(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -305,6 +402,32 @@ func TestFilterRowsByColumnValue(t *testing.T) {
 	}
 }
 
+// TestFilterRowsCountingMatches тестирует подсчет строк, совпавших с каждым значением фильтра
+func TestFilterRowsCountingMatches(t *testing.T) {
+	rows := [][]string{
+		{"A1", "Shuzzi"},
+		{"A2", " shuzzi "},
+		{"A3", "Nike"},
+		{"A4"},
+	}
+	// Повторенное в другом регистре значение тоже получает совпадения
+	filterValues := []string{"Shuzzi", "Shuzi", "nike", "SHUZZI"}
+	matched := make([]int, len(filterValues))
+
+	kept := filterRowsCountingMatches(rows, 1, filterValues, matched, nil)
+	if len(kept) != 3 {
+		t.Errorf("оставлено строк: %d, ожидалось 3", len(kept))
+	}
+	if want := []int{2, 0, 1, 2}; !reflect.DeepEqual(matched, want) {
+		t.Errorf("совпадения = %v, ожидалось %v", matched, want)
+	}
+
+	warnings := unmatchedFilterWarnings("Лист", filterValues, matched)
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "'Shuzi'") || warnings[0].Severity != SeverityWarning {
+		t.Errorf("предупреждения = %+v, ожидалось одно о значении Shuzi", warnings)
+	}
+}
+
 func TestExtractArticlesFromRows(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -376,6 +499,31 @@ func TestExtractArticlesFromRows(t *testing.T) {
 	}
 }
 
+// TestFindArticleColumn тестирует поиск столбца артикула при разных написаниях заголовка
+func TestFindArticleColumn(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    int
+	}{
+		{"обязательное поле", []string{"Название", "Артикул*", "Цена"}, 1},
+		{"регистр, пробелы и звездочка", []string{"Название", " АРТИКУЛ * "}, 1},
+		{"точное совпадение важнее подстроки", []string{"Код артикула поставщика", "Артикул"}, 1},
+		{"начало заголовка важнее подстроки", []string{"Код артикула", "Артикул товара"}, 1},
+		{"только подстрока", []string{"Название", "Код артикула"}, 1},
+		{"не найден", []string{"Название", "Код"}, -1},
+		{"без заголовков", nil, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findArticleColumn(tt.headers); got != tt.want {
+				t.Errorf("findArticleColumn(%q) = %d, ожидалось %d", tt.headers, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestFilterRowsByArticles(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -454,3 +602,2036 @@ func TestFilterRowsByArticles(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchColumnsByName(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   []string
+		source []string
+		want   []int
+	}{
+		{"same order", []string{"Артикул", "Цена"}, []string{"Артикул", "Цена"}, []int{0, 1}},
+		{"different order and case", []string{"Артикул", "Цена"}, []string{" цена ", "АРТИКУЛ"}, []int{1, 0}},
+		{"partial overlap", []string{"Артикул", "Цена", "Бренд"}, []string{"Бренд"}, []int{-1, -1, 0}},
+		{"no overlap", []string{"Артикул", "Цена"}, []string{"Name", "Price"}, []int{-1, -1}},
+		{"empty headers never match", []string{"", "Цена"}, []string{"", "Вес"}, []int{-1, -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MatchColumnsByName(tt.base, tt.source)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ожидалось %v, получено %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ожидалось %v, получено %v", tt.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMergeFilesSkipsFileWithoutMatchingColumns(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+
+	basePath := filepath.Join(dir, "base.xlsx")
+	goodPath := filepath.Join(dir, "good.xlsx")
+	wrongPath := filepath.Join(dir, "wrong.xlsx")
+
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Артикул", "Цена"},
+		{"A1", "100"},
+	})
+	exceltest.WriteWorkbook(t, goodPath, "Data", [][]string{
+		{"Артикул", "Цена"},
+		{"A2", "200"},
+	})
+	exceltest.WriteWorkbook(t, wrongPath, "Data", [][]string{
+		{"Name", "Phone"},
+		{"Иван", "+7 900 000-00-00"},
+	})
+
+	merger := NewMerger(nil, logger)
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+
+	result, err := merger.MergeFiles(basePath, []string{goodPath, wrongPath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.WorkbookData.Close()
+
+	if result.TotalRows != 2 {
+		t.Errorf("ожидалось 2 строки, получено %d", result.TotalRows)
+	}
+
+	var found bool
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning.Message, apperrors.ErrCodeNoMatchingColumns) && strings.Contains(warning.Message, "wrong.xlsx") {
+			found = true
+			if warning.Severity != SeverityWarning {
+				t.Errorf("уровень = %s, ожидался warning", warning.Severity)
+			}
+		}
+		if strings.Contains(warning.Message, "good.xlsx") {
+			t.Errorf("неожиданное предупреждение для корректного файла: %s", warning)
+		}
+	}
+	if !found {
+		t.Errorf("ожидалось предупреждение %s для wrong.xlsx, получено %v", apperrors.ErrCodeNoMatchingColumns, result.Warnings)
+	}
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Data")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	for _, row := range rows {
+		if len(row) > 0 && row[0] == "Иван" {
+			t.Error("данные файла без совпадающих столбцов не должны попадать в результат")
+		}
+	}
+}
+
+// TestMergeFilesReportsHeaderOnlyFile тестирует сведения о файле, где есть только заголовки
+func TestMergeFilesReportsHeaderOnlyFile(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+
+	basePath := filepath.Join(dir, "base.xlsx")
+	emptyPath := filepath.Join(dir, "empty.xlsx")
+	blankPath := filepath.Join(dir, "blank.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул", "Цена"}, {"A1", "100"}})
+	exceltest.WriteWorkbook(t, emptyPath, "Data", [][]string{{"Артикул", "Цена"}})
+	// Пустые строки после заголовков тоже не данные
+	exceltest.WriteWorkbook(t, blankPath, "Data", [][]string{{"Артикул", "Цена"}, {"", ""}, {}})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{emptyPath, blankPath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	if result.TotalRows != 1 || result.SheetStats["Data"].RowsMerged != 1 {
+		t.Errorf("TotalRows = %d, RowsMerged = %d, ожидалась 1 строка базового файла",
+			result.TotalRows, result.SheetStats["Data"].RowsMerged)
+	}
+
+	want := []string{
+		"файл empty.xlsx, лист 'Data': нет строк данных",
+		"файл blank.xlsx, лист 'Data': нет строк данных",
+	}
+	if len(result.Warnings) != len(want) {
+		t.Fatalf("предупреждения = %v, ожидалось %q", result.Warnings, want)
+	}
+	for i, warning := range result.Warnings {
+		if warning.Message != want[i] || warning.Severity != SeverityInfo {
+			t.Errorf("предупреждение %d = %s (%s), ожидалось %q (info)", i, warning.Message, warning.Severity, want[i])
+		}
+	}
+	if result.WarningCounts[SeverityError] != 0 {
+		t.Errorf("файл без данных не должен считаться ошибкой: %v", result.WarningCounts)
+	}
+}
+
+func TestMergeFilesDoesNotLeakHandles(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+
+	basePath := filepath.Join(dir, "base.xlsx")
+	otherPath := filepath.Join(dir, "other.xlsx")
+	wrongSheetPath := filepath.Join(dir, "wrong_sheet.xlsx")
+	brokenPath := filepath.Join(dir, "broken.xlsx")
+
+	exceltest.WriteWorkbook(t, basePath, "Шаблон", [][]string{
+		{"Артикул", "Цена"},
+		{"A1", "100"},
+	})
+	exceltest.WriteWorkbook(t, otherPath, "Шаблон", [][]string{
+		{"Артикул", "Цена"},
+		{"A2", "200"},
+	})
+	exceltest.WriteWorkbook(t, wrongSheetPath, "Другой", [][]string{
+		{"Артикул", "Цена"},
+	})
+	if err := os.WriteFile(brokenPath, []byte("not an xlsx"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{otherPath, wrongSheetPath, brokenPath}
+
+	t.Run("ошибка посреди объединения", func(t *testing.T) {
+		before := excel.OpenHandles()
+
+		// Лист "Шаблон" обрабатывается первым, затем объединение прерывается
+		// на листе, которого нет в базовом файле
+		sheetConfigs := map[string]*SheetConfig{
+			"Шаблон":      {SheetName: "Шаблон", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+			"Отсутствует": {SheetName: "Отсутствует", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+		}
+
+		result, err := NewMerger(nil, logger).MergeFiles(basePath, files, sheetConfigs)
+		if err == nil {
+			result.Close()
+			t.Fatal("ожидалась ошибка объединения")
+		}
+
+		if after := excel.OpenHandles(); after != before {
+			t.Errorf("утечка дескрипторов: открыто %d, ожидалось %d", after, before)
+		}
+	})
+
+	t.Run("успешное объединение", func(t *testing.T) {
+		before := excel.OpenHandles()
+
+		sheetConfigs := map[string]*SheetConfig{
+			"Шаблон": {SheetName: "Шаблон", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+		}
+
+		result, err := NewMerger(nil, logger).MergeFiles(basePath, files, sheetConfigs)
+		if err != nil {
+			t.Fatalf("ошибка при объединении файлов: %v", err)
+		}
+
+		// Открытой остается только результирующая книга
+		if after := excel.OpenHandles(); after != before+1 {
+			t.Errorf("открыто %d дескрипторов, ожидалось %d", after, before+1)
+		}
+
+		if err := result.Close(); err != nil {
+			t.Errorf("ошибка закрытия результата: %v", err)
+		}
+		if err := result.Close(); err != nil {
+			t.Errorf("повторное закрытие должно быть безопасным: %v", err)
+		}
+		if after := excel.OpenHandles(); after != before {
+			t.Errorf("утечка дескрипторов: открыто %d, ожидалось %d", after, before)
+		}
+	})
+}
+
+func TestMergeFilesAppliesStyleTemplate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+
+	basePath := filepath.Join(dir, "base.xlsx")
+	templatePath := filepath.Join(dir, "style.xlsx")
+
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Отчет за неделю"},
+		{"Артикул", "Цена"},
+		{"A1", "100"},
+	})
+
+	// Шаблон оформления: заливка, жирный шрифт и граница в первой строке
+	template := excelize.NewFile()
+	styleID, err := template.NewStyle(&excelize.Style{
+		Fill:   excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"C00000"}},
+		Font:   &excelize.Font{Bold: true},
+		Border: []excelize.Border{{Type: "bottom", Color: "000000", Style: 1}},
+	})
+	if err != nil {
+		t.Fatalf("не удалось создать стиль шаблона: %v", err)
+	}
+	if err := template.SetCellStyle("Sheet1", "A1", "A1", styleID); err != nil {
+		t.Fatalf("не удалось оформить шаблон: %v", err)
+	}
+	if err := template.SaveAs(templatePath); err != nil {
+		t.Fatalf("не удалось сохранить шаблон: %v", err)
+	}
+	template.Close()
+
+	merger := NewMerger(nil, logger)
+	merger.SetSettings(ProfileSettings{StyleTemplatePath: templatePath})
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 2, FilterColumn: -1},
+	}
+
+	result, err := merger.MergeFiles(basePath, nil, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	file := result.WorkbookData.GetFile()
+	for _, cell := range []string{"A2", "B2"} {
+		idx, err := file.GetCellStyle("Data", cell)
+		if err != nil || idx == 0 {
+			t.Fatalf("заголовок %s не оформлен: idx=%d, err=%v", cell, idx, err)
+		}
+		style, err := file.GetStyle(idx)
+		if err != nil {
+			t.Fatalf("не удалось прочитать стиль %s: %v", cell, err)
+		}
+		if style.Font == nil || !style.Font.Bold || len(style.Fill.Color) == 0 || style.Fill.Color[0] != "C00000" ||
+			len(style.Border) != 1 || style.Border[0].Type != "bottom" {
+			t.Errorf("стиль заголовка %s не совпадает с шаблоном: %+v", cell, style)
+		}
+	}
+
+	// Строки данных и строки над заголовком не оформляются
+	for _, cell := range []string{"A1", "A3"} {
+		if idx, _ := file.GetCellStyle("Data", cell); idx != 0 {
+			t.Errorf("ячейка %s не должна быть оформлена, стиль %d", cell, idx)
+		}
+	}
+
+	// Недоступный шаблон не прерывает объединение
+	merger.SetSettings(ProfileSettings{StyleTemplatePath: filepath.Join(dir, "missing.xlsx")})
+	result2, err := merger.MergeFiles(basePath, nil, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result2.Close()
+
+	var found bool
+	for _, warning := range result2.Warnings {
+		if strings.Contains(warning.Message, "шаблон оформления") {
+			found = true
+			if warning.Severity != SeverityWarning {
+				t.Errorf("уровень = %s, ожидался warning", warning.Severity)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("ожидалось предупреждение о шаблоне оформления, получено %v", result2.Warnings)
+	}
+}
+
+func TestMergeFilesAppliesTabColor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	basePath := filepath.Join(t.TempDir(), "base.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Артикул", "Цена"},
+		{"A1", "100"},
+	})
+
+	tests := []struct {
+		name        string
+		color       string
+		want        string
+		wantWarning bool
+	}{
+		{"цвет задан", "#C00000", "FFC00000", false},
+		{"без цвета", "", "", false},
+		{"некорректный цвет", "красный", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheetConfigs := map[string]*SheetConfig{
+				"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1, TabColor: tt.color},
+			}
+
+			result, err := NewMerger(nil, logger).MergeFiles(basePath, nil, sheetConfigs)
+			if err != nil {
+				t.Fatalf("ошибка при объединении файлов: %v", err)
+			}
+			defer result.Close()
+
+			props, err := result.WorkbookData.GetFile().GetSheetProps("Data")
+			if err != nil {
+				t.Fatalf("не удалось прочитать свойства листа: %v", err)
+			}
+			got := ""
+			if props.TabColorRGB != nil {
+				got = *props.TabColorRGB
+			}
+			if got != tt.want {
+				t.Errorf("цвет ярлыка = %q, ожидалось %q", got, tt.want)
+			}
+			if hasWarning := len(result.Warnings) > 0; hasWarning != tt.wantWarning {
+				t.Errorf("предупреждения: %v", result.Warnings)
+			}
+			for _, warning := range result.Warnings {
+				if warning.Severity != SeverityInfo {
+					t.Errorf("уровень %q = %s, ожидался info", warning.Message, warning.Severity)
+				}
+			}
+		})
+	}
+}
+
+// TestMergeFilesOnErrorPolicy тестирует политику обработки ошибки листа
+func TestMergeFilesOnErrorPolicy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	basePath := filepath.Join(t.TempDir(), "base.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Артикул", "Цена"},
+		{"A1", "100"},
+	})
+
+	tests := []struct {
+		name        string
+		onError     string
+		withData    bool
+		wantErr     bool
+		wantSheets  []string
+		wantWarning bool
+	}{
+		{"по умолчанию прерывает", "", true, true, nil, false},
+		{"abort прерывает", OnErrorAbort, true, true, nil, false},
+		{"continue пропускает лист", OnErrorContinue, true, false, []string{"Data"}, true},
+		{"continue без обработанных листов", OnErrorContinue, false, true, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheetConfigs := map[string]*SheetConfig{
+				"Отсутствует": {SheetName: "Отсутствует", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+			}
+			if tt.withData {
+				sheetConfigs["Data"] = &SheetConfig{SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1}
+			}
+
+			merger := NewMerger(nil, logger)
+			merger.SetSettings(ProfileSettings{OnError: tt.onError})
+
+			result, err := merger.MergeFiles(basePath, nil, sheetConfigs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MergeFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), "Отсутствует") {
+					t.Errorf("ошибка должна указывать на лист: %v", err)
+				}
+				return
+			}
+			defer result.Close()
+
+			sheets := result.WorkbookData.GetSheetNames()
+			if strings.Join(sheets, ",") != strings.Join(tt.wantSheets, ",") {
+				t.Errorf("листы результата = %v, ожидалось %v", sheets, tt.wantSheets)
+			}
+			if result.ProcessedSheets != len(tt.wantSheets) {
+				t.Errorf("ProcessedSheets = %d, ожидалось %d", result.ProcessedSheets, len(tt.wantSheets))
+			}
+			if _, ok := result.SheetStats["Отсутствует"]; ok {
+				t.Error("пропущенный лист не должен попадать в статистику")
+			}
+
+			hasWarning := false
+			for _, warning := range result.Warnings {
+				if strings.Contains(warning.Message, "Отсутствует") {
+					hasWarning = true
+					if warning.Severity != SeverityError {
+						t.Errorf("уровень = %s, ожидался error", warning.Severity)
+					}
+				}
+			}
+			if hasWarning != tt.wantWarning {
+				t.Errorf("предупреждения: %v", result.Warnings)
+			}
+			if tt.wantWarning && result.WarningCounts[SeverityError] != 1 {
+				t.Errorf("WarningCounts = %v, ожидалась одна ошибка", result.WarningCounts)
+			}
+		})
+	}
+}
+
+// TestMergeFilesLeavesNoDefaultSheet тестирует, что в результате только настроенные листы, без пустого Sheet1
+func TestMergeFilesLeavesNoDefaultSheet(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Data", [][]string{{"Артикул"}, {"A1"}}},
+		{"Прайс", [][]string{{"Артикул"}, {"B1"}}},
+	})
+
+	tests := []struct {
+		name       string
+		sheets     []string
+		settings   ProfileSettings
+		wantSheets string
+	}{
+		{"все листы обработаны", []string{"Data", "Прайс"}, ProfileSettings{}, "[Data Прайс]"},
+		// Лист "A_нет" обрабатывается первым и удаляется из результата после ошибки
+		{"первый лист пропущен", []string{"A_нет", "Data"}, ProfileSettings{OnError: OnErrorContinue}, "[Data]"},
+		{"объединение в копию базового файла", []string{"A_нет", "Data"},
+			ProfileSettings{OnError: OnErrorContinue, MergeIntoBase: true}, "[Data Прайс]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheetConfigs := make(map[string]*SheetConfig)
+			for _, name := range tt.sheets {
+				sheetConfigs[name] = &SheetConfig{SheetName: name, Enabled: true, HeaderRow: 1, FilterColumn: -1}
+			}
+			merger := NewMerger(nil, logger)
+			merger.SetSettings(tt.settings)
+			result, err := merger.MergeFiles(basePath, nil, sheetConfigs)
+			if err != nil {
+				t.Fatalf("MergeFiles() error = %v", err)
+			}
+			defer result.Close()
+
+			if sheets := fmt.Sprint(result.WorkbookData.GetSheetNames()); sheets != tt.wantSheets {
+				t.Errorf("листы результата = %s, ожидалось %s", sheets, tt.wantSheets)
+			}
+
+			path := filepath.Join(t.TempDir(), "result.xlsx")
+			if err := result.Save(path); err != nil {
+				t.Fatal(err)
+			}
+			reader, err := excel.NewReader(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			if sheets := fmt.Sprint(reader.GetSheetNames()); sheets != tt.wantSheets {
+				t.Errorf("листы сохраненного результата = %s, ожидалось %s", sheets, tt.wantSheets)
+			}
+		})
+	}
+}
+
+// TestMergeFilesCollectsSheetErrors тестирует, что при пропуске всех листов
+// возвращаются ошибки каждого листа, а не только первая
+func TestMergeFilesCollectsSheetErrors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	basePath := filepath.Join(t.TempDir(), "base.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул"}, {"A1"}})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Нет1": {SheetName: "Нет1", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+		"Нет2": {SheetName: "Нет2", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+	merger := NewMerger(nil, logger)
+	merger.SetSettings(ProfileSettings{OnError: OnErrorContinue})
+
+	_, err := merger.MergeFiles(basePath, nil, sheetConfigs)
+	if err == nil {
+		t.Fatal("ожидалась ошибка")
+	}
+
+	var multi *apperrors.MultiError
+	if !errors.As(err, &multi) || multi.Len() != 2 {
+		t.Fatalf("ожидался MultiError из двух ошибок: %v", err)
+	}
+	for _, sheet := range []string{"1. ", "2. ", "Нет1", "Нет2"} {
+		if !strings.Contains(err.Error(), sheet) {
+			t.Errorf("ошибка %q не содержит %q", err, sheet)
+		}
+	}
+	if !errors.Is(err, apperrors.ErrSheetNotFound) {
+		t.Errorf("errors.Is(ErrSheetNotFound) = false: %v", err)
+	}
+}
+
+// TestMergeFilesReportsColumnTypeViolations тестирует проверку типов данных столбцов
+func TestMergeFilesReportsColumnTypeViolations(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Артикул", "Цена"},
+		{"A1", "100"},
+		{"A2", "по запросу"},
+	})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{
+		{"Артикул", "Цена"},
+		{"B1", "1 234,50"},
+		{"B2", "бесплатно"},
+		{"B3", ""},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {
+			SheetName:    "Data",
+			Enabled:      true,
+			HeaderRow:    1,
+			FilterColumn: -1,
+			ColumnTypes:  map[int]string{1: ColumnTypeNumber},
+		},
+	}
+
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	// Данные с нарушениями не отбрасываются
+	if result.TotalRows != 5 {
+		t.Errorf("TotalRows = %d, ожидалось 5", result.TotalRows)
+	}
+
+	var typeWarnings []string
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning.Message, "не соответствуют типу") {
+			typeWarnings = append(typeWarnings, warning.Message)
+			if warning.Severity != SeverityWarning {
+				t.Errorf("уровень = %s, ожидался warning", warning.Severity)
+			}
+		}
+	}
+	if len(typeWarnings) != 1 {
+		t.Fatalf("ожидалось одно предупреждение о типах, получено: %v", result.Warnings)
+	}
+	for _, want := range []string{"B 'Цена'", ": 2 значений", `B3="по запросу"`, `B5="бесплатно"`} {
+		if !strings.Contains(typeWarnings[0], want) {
+			t.Errorf("предупреждение %q не содержит %q", typeWarnings[0], want)
+		}
+	}
+}
+
+// TestMergeFilesAppendsConstantColumns тестирует добавление столбцов с фиксированным значением
+func TestMergeFilesAppendsConstantColumns(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Отчет"},
+		{"Артикул", "Цена"},
+		{"A1", "100"},
+	})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{
+		{"Отчет"},
+		{"Артикул", "Цена"},
+		{"B1"},
+		{"B2", "300"},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {
+			SheetName:    "Data",
+			Enabled:      true,
+			HeaderRow:    2,
+			FilterColumn: -1,
+			ConstantColumns: []ConstantColumn{
+				{Header: "Категория", Value: "Обувь"},
+				{Header: "Кампания", Value: "SALE-25"},
+			},
+		},
+	}
+
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Data")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+
+	want := [][]string{
+		{"Отчет"},
+		{"Артикул", "Цена", "Категория", "Кампания"},
+		{"A1", "100", "Обувь", "SALE-25"},
+		{"B1", "", "Обувь", "SALE-25"},
+		{"B2", "300", "Обувь", "SALE-25"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("строк = %d, ожидалось %d: %q", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("строка %d = %q, ожидалось %q", i+1, rows[i], want[i])
+		}
+	}
+}
+
+// testSheet лист тестового файла
+type testSheet struct {
+	name string
+	rows [][]string
+}
+
+// writeTestWorkbookSheets создает тестовый файл с несколькими листами
+func writeTestWorkbookSheets(t testing.TB, path string, sheets []testSheet) {
+	t.Helper()
+	writer := excel.NewWriter()
+	defer writer.Close()
+
+	for _, sheet := range sheets {
+		if err := writer.CreateSheet(sheet.name); err != nil {
+			t.Fatalf("не удалось создать лист: %v", err)
+		}
+		if err := writer.WriteRows(sheet.name, 1, sheet.rows); err != nil {
+			t.Fatalf("не удалось записать строки: %v", err)
+		}
+	}
+	if err := writer.Save(path); err != nil {
+		t.Fatalf("не удалось сохранить файл: %v", err)
+	}
+}
+
+// TestMergeFilesConcatenatesSheetsIntoOutputSheet тестирует объединение листов базового файла в один лист
+func TestMergeFilesConcatenatesSheetsIntoOutputSheet(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Ботинки", [][]string{{"Артикул", "Цена"}, {"B1", "100"}, {"B2", "200"}}},
+		{"Кроссовки", [][]string{{"Артикул", "Цена"}, {"K1", "300"}}},
+		{"Сандалии", [][]string{{"Артикул", "Размер", "Цена"}, {"S1", "40", "50"}}},
+	})
+	writeTestWorkbookSheets(t, sourcePath, []testSheet{
+		{"Кроссовки", [][]string{{"Артикул", "Цена"}, {"K2", "400"}}},
+	})
+
+	t.Run("одинаковая структура", func(t *testing.T) {
+		sheetConfigs := map[string]*SheetConfig{
+			"Ботинки":   {SheetName: "Ботинки", Enabled: true, HeaderRow: 1, FilterColumn: -1, OutputSheet: "Обувь"},
+			"Кроссовки": {SheetName: "Кроссовки", Enabled: true, HeaderRow: 1, FilterColumn: -1, OutputSheet: "Обувь"},
+		}
+
+		result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+		if err != nil {
+			t.Fatalf("ошибка при объединении файлов: %v", err)
+		}
+		defer result.Close()
+
+		if sheets := result.WorkbookData.GetSheetNames(); strings.Join(sheets, ",") != "Обувь" {
+			t.Fatalf("листы результата = %v, ожидался только 'Обувь'", sheets)
+		}
+
+		rows, err := result.WorkbookData.GetFile().GetRows("Обувь")
+		if err != nil {
+			t.Fatalf("не удалось прочитать результат: %v", err)
+		}
+		want := [][]string{
+			{"Артикул", "Цена"},
+			{"B1", "100"},
+			{"B2", "200"},
+			{"K1", "300"},
+			{"K2", "400"},
+		}
+		if len(rows) != len(want) {
+			t.Fatalf("строк = %d, ожидалось %d: %q", len(rows), len(want), rows)
+		}
+		for i := range want {
+			if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+				t.Errorf("строка %d = %q, ожидалось %q", i+1, rows[i], want[i])
+			}
+		}
+		if result.TotalRows != 4 || result.ProcessedSheets != 2 {
+			t.Errorf("TotalRows = %d, ProcessedSheets = %d", result.TotalRows, result.ProcessedSheets)
+		}
+	})
+
+	t.Run("разная структура", func(t *testing.T) {
+		sheetConfigs := map[string]*SheetConfig{
+			"Ботинки":  {SheetName: "Ботинки", Enabled: true, HeaderRow: 1, FilterColumn: -1, OutputSheet: "Обувь"},
+			"Сандалии": {SheetName: "Сандалии", Enabled: true, HeaderRow: 1, FilterColumn: -1, OutputSheet: "Обувь"},
+		}
+
+		_, err := NewMerger(nil, logger).MergeFiles(basePath, nil, sheetConfigs)
+		if err == nil || !strings.Contains(err.Error(), "не совпадают") {
+			t.Errorf("ожидалась ошибка несовпадения заголовков, получено: %v", err)
+		}
+	})
+}
+
+// TestMergeFilesAppendFailureLeavesOutputSheet тестирует, что дописываемый лист, пропущенный
+// из-за ошибки, не оставляет часть своих строк в листе результата
+func TestMergeFilesAppendFailureLeavesOutputSheet(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Ботинки", [][]string{{"Артикул", "Цена"}, {"B1", "100"}, {"B2", "200"}}},
+		{"Кроссовки", [][]string{{"Артикул", "Цена"}, {"K1", "300"}}},
+	})
+	writeTestWorkbookSheets(t, sourcePath, []testSheet{
+		{"Кроссовки", [][]string{{"Артикул", "Цена"}, {"K2", "400"}, {"K3", "500"}, {"K4", "600"}}},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Ботинки":   {SheetName: "Ботинки", Enabled: true, HeaderRow: 1, FilterColumn: -1, OutputSheet: "Обувь"},
+		"Кроссовки": {SheetName: "Кроссовки", Enabled: true, HeaderRow: 1, FilterColumn: -1, OutputSheet: "Обувь"},
+	}
+
+	// Строки K1 и K2 помещаются в лимит, K3 и K4 - нет
+	merger := NewMerger(nil, logger)
+	merger.SetSettings(ProfileSettings{OnError: OnErrorContinue})
+	merger.newWriter = func(baseFilePath string, mergeIntoBase bool) (*excel.Writer, error) {
+		writer, err := newResultWriter(baseFilePath, mergeIntoBase)
+		if err == nil {
+			writer.SetRowLimit(5)
+		}
+		return writer, err
+	}
+
+	result, err := merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Обувь")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	want := [][]string{{"Артикул", "Цена"}, {"B1", "100"}, {"B2", "200"}}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("строки результата = %v, ожидалось %v", rows, want)
+	}
+	if result.TotalRows != 2 || result.ProcessedSheets != 1 {
+		t.Errorf("TotalRows = %d, ProcessedSheets = %d", result.TotalRows, result.ProcessedSheets)
+	}
+
+	var skipped bool
+	for _, warning := range result.Warnings {
+		if warning.Severity == SeverityError && strings.Contains(warning.Message, "Кроссовки") {
+			skipped = true
+		}
+	}
+	if !skipped {
+		t.Errorf("ожидалось предупреждение о пропущенном листе: %v", result.Warnings)
+	}
+}
+
+// TestFindSheetsWithSameHeaders тестирует поиск листов с одинаковой структурой
+func TestFindSheetsWithSameHeaders(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	basePath := filepath.Join(t.TempDir(), "base.xlsx")
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Ботинки", [][]string{{"Артикул", "Цена"}}},
+		{"Сандалии", [][]string{{"Артикул", "Размер", "Цена"}}},
+		{"Кроссовки", [][]string{{" Артикул ", "Цена", ""}}},
+		{"Пустой", nil},
+	})
+
+	groups, err := NewBaseAnalyzer(nil, logger).FindSheetsWithSameHeaders(basePath, 1)
+	if err != nil {
+		t.Fatalf("FindSheetsWithSameHeaders() error = %v", err)
+	}
+	if len(groups) != 1 || strings.Join(groups[0], ",") != "Ботинки,Кроссовки" {
+		t.Errorf("группы = %v, ожидалась [[Ботинки Кроссовки]]", groups)
+	}
+}
+
+// TestMergeFilesReadsBaseFileOnce тестирует однократное чтение базового файла и точность прогресса
+func TestMergeFilesReadsBaseFileOnce(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePaths := []string{filepath.Join(dir, "source1.xlsx"), filepath.Join(dir, "source2.xlsx")}
+
+	sheets := []testSheet{
+		{"Ботинки", [][]string{{"Артикул", "Цена"}, {"B1", "100"}}},
+		{"Кроссовки", [][]string{{"Артикул", "Цена"}, {"K1", "300"}}},
+		{"Архив", [][]string{{"Артикул", "Цена"}, {"A1", "1"}}},
+	}
+	writeTestWorkbookSheets(t, basePath, sheets)
+	for _, path := range sourcePaths {
+		writeTestWorkbookSheets(t, path, sheets[:2])
+	}
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Ботинки":     {SheetName: "Ботинки", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+		"Кроссовки":   {SheetName: "Кроссовки", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+		"Архив":       {SheetName: "Архив", Enabled: false, HeaderRow: 1, FilterColumn: -1},
+		"Отсутствует": {SheetName: "Отсутствует", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+
+	merger := NewMerger(nil, logger)
+	merger.SetSettings(ProfileSettings{OnError: OnErrorContinue})
+
+	opens := make(map[string]int)
+	merger.openReader = func(path string) (*excel.Reader, func(), error) {
+		opens[filepath.Base(path)]++
+		return (*excel.ReaderPool)(nil).Borrow(path)
+	}
+
+	type update struct {
+		current, total int
+		message        string
+	}
+	var updates []update
+	merger.SetProgressCallback(func(current, total int, message string) {
+		updates = append(updates, update{current, total, message})
+	})
+
+	result, err := merger.MergeFiles(basePath, sourcePaths, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	// Базовый файл открывается один раз, источники - по разу на обработанный лист
+	wantOpens := map[string]int{"base.xlsx": 1, "source1.xlsx": 2, "source2.xlsx": 2}
+	for name, want := range wantOpens {
+		if opens[name] != want {
+			t.Errorf("%s открыт %d раз, ожидалось %d", name, opens[name], want)
+		}
+	}
+
+	// 3 включенных листа: чтение из базового файла + из двух источников
+	const wantTotal = 3 * 3
+	if len(updates) == 0 {
+		t.Fatal("ожидались обновления прогресса")
+	}
+	baseReads := 0
+	for i, u := range updates {
+		if u.total != wantTotal {
+			t.Errorf("обновление %d: total = %d, ожидалось %d", i, u.total, wantTotal)
+		}
+		if u.current > u.total || (i > 0 && u.current < updates[i-1].current) {
+			t.Errorf("обновление %d: некорректный прогресс %d/%d", i, u.current, u.total)
+		}
+		if strings.HasPrefix(u.message, "Чтение базового файла") {
+			baseReads++
+		}
+	}
+	if baseReads != 3 {
+		t.Errorf("чтений базового файла в прогрессе = %d, ожидалось 3", baseReads)
+	}
+	if last := updates[len(updates)-1]; last.current != wantTotal {
+		t.Errorf("итоговый прогресс = %d/%d, ожидалось %d/%d", last.current, last.total, wantTotal, wantTotal)
+	}
+
+	if result.TotalRows != 6 {
+		t.Errorf("TotalRows = %d, ожидалось 6", result.TotalRows)
+	}
+}
+
+// TestMergeFilesIntoBase тестирует дописывание данных в копию базового файла:
+// строки базового листа и его оформление сохраняются, данные источников идут после них
+func TestMergeFilesIntoBase(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+
+	base := excelize.NewFile()
+	if err := base.SetSheetName("Sheet1", "Data"); err != nil {
+		t.Fatalf("не удалось переименовать лист: %v", err)
+	}
+	for _, row := range []struct {
+		cell   string
+		values []interface{}
+	}{
+		{"A1", []interface{}{"Артикул", "Цена"}},
+		{"A2", []interface{}{"A1", "100"}},
+		{"A4", []interface{}{"A3", "300"}}, // строка 3 пустая
+	} {
+		if err := base.SetSheetRow("Data", row.cell, &row.values); err != nil {
+			t.Fatalf("не удалось записать строку: %v", err)
+		}
+	}
+	headerStyle, err := base.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		t.Fatalf("не удалось создать стиль: %v", err)
+	}
+	dataStyle, err := base.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFFF00"}}})
+	if err != nil {
+		t.Fatalf("не удалось создать стиль: %v", err)
+	}
+	if err := base.SetCellStyle("Data", "A1", "B1", headerStyle); err != nil {
+		t.Fatalf("не удалось оформить заголовки: %v", err)
+	}
+	if err := base.SetCellStyle("Data", "B2", "B2", dataStyle); err != nil {
+		t.Fatalf("не удалось оформить ячейку: %v", err)
+	}
+	if err := base.SetColWidth("Data", "A", "A", 30); err != nil {
+		t.Fatalf("не удалось задать ширину столбца: %v", err)
+	}
+	if _, err := base.NewSheet("Справка"); err != nil {
+		t.Fatalf("не удалось создать лист: %v", err)
+	}
+	if err := base.SaveAs(basePath); err != nil {
+		t.Fatalf("не удалось сохранить базовый файл: %v", err)
+	}
+	base.Close()
+
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{{"Артикул", "Цена"}, {"A2", "200"}})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {
+			SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1,
+			ConstantColumns: []ConstantColumn{{Header: "Акция", Value: "SALE"}},
+		},
+	}
+	merger := NewMerger(nil, logger)
+	merger.SetSettings(ProfileSettings{MergeIntoBase: true})
+
+	result, err := merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	file := result.WorkbookData.GetFile()
+	rows, err := file.GetRows("Data")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	want := [][]string{
+		{"Артикул", "Цена", "Акция"},
+		{"A1", "100", "SALE"},
+		nil,
+		{"A3", "300", "SALE"},
+		{"A2", "200", "SALE"},
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("строки результата = %v, ожидалось %v", rows, want)
+	}
+	if result.TotalRows != 3 {
+		t.Errorf("TotalRows = %d, ожидалось 3", result.TotalRows)
+	}
+
+	for cell, style := range map[string]int{"A1": headerStyle, "B1": headerStyle, "B2": dataStyle} {
+		if idx, _ := file.GetCellStyle("Data", cell); idx != style {
+			t.Errorf("стиль %s = %d, ожидался %d", cell, idx, style)
+		}
+	}
+	if width, _ := file.GetColWidth("Data", "A"); width != 30 {
+		t.Errorf("ширина столбца A = %v, ожидалась 30", width)
+	}
+	if sheets := result.WorkbookData.GetSheetNames(); strings.Join(sheets, ",") != "Data,Справка" {
+		t.Errorf("листы результата = %v, ожидались листы базового файла", sheets)
+	}
+
+	// Базовый файл не изменяется
+	original, err := excelize.OpenFile(basePath)
+	if err != nil {
+		t.Fatalf("не удалось открыть базовый файл: %v", err)
+	}
+	defer original.Close()
+	if baseRows, _ := original.GetRows("Data"); len(baseRows) != 4 {
+		t.Errorf("базовый файл изменен: %v", baseRows)
+	}
+}
+
+// TestMergeFilesRecoversPanic тестирует, что паника в функции прогресса
+// возвращается как ошибка и не оставляет открытых книг
+func TestMergeFilesRecoversPanic(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул"}, {"A1"}})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{{"Артикул"}, {"A2"}})
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+
+	merger := NewMerger(nil, logger)
+	calls := 0
+	merger.SetProgressCallback(func(current, total int, message string) {
+		calls++
+		if calls == 2 {
+			var rows [][]string
+			_ = rows[calls] // выход за границы среза, как при поврежденном файле
+		}
+	})
+
+	before := excel.OpenHandles()
+	result, err := merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err == nil {
+		result.Close()
+		t.Fatal("ожидалась ошибка после паники")
+	}
+	// При панике книги нет, а журнал запуска сохраняется для разбора причины
+	if result == nil || result.WorkbookData != nil || len(result.LogLines) == 0 {
+		t.Errorf("при панике ожидался результат только с журналом: %+v", result)
+	}
+	if !errors.Is(err, apperrors.ErrInternal) {
+		t.Errorf("errors.Is(ErrInternal) = false: %v", err)
+	}
+	if !strings.Contains(err.Error(), "index out of range") {
+		t.Errorf("ошибка должна содержать причину паники: %v", err)
+	}
+	if after := excel.OpenHandles(); after != before {
+		t.Errorf("утечка дескрипторов: открыто %d, ожидалось %d", after, before)
+	}
+
+	// После паники объединитель остается пригодным для работы
+	merger.SetProgressCallback(nil)
+	if merger.logger != logger {
+		t.Error("журнал объединителя не восстановлен после паники")
+	}
+	result, err = merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("повторное объединение: %v", err)
+	}
+	result.Close()
+}
+
+// TestSkipPreamble тестирует поиск первой строки данных после преамбулы переменной длины
+func TestSkipPreamble(t *testing.T) {
+	rows := [][]string{
+		{"", "Цены действительны до 01.12"},
+		{"", "Остатки на складе"},
+		{"артикул ", "Цена"},
+		{"A1", "100"},
+		{"A2", "200"},
+	}
+
+	tests := []struct {
+		name   string
+		column string
+		marker string
+		want   string
+	}{
+		{"поиск не настроен", "", "", "|Цены действительны до 01.12"},
+		{"первая заполненная ячейка", "A", "", "артикул |Цена"},
+		{"строка после маркера", "A", "Артикул", "A1|100"},
+		{"маркер не найден", "a", "Код", "артикул |Цена"},
+		{"столбец без значений", "C", "", ""},
+		{"некорректный столбец", "1", "", "|Цены действительны до 01.12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &SheetConfig{DataStartColumn: tt.column, DataStartMarker: tt.marker}
+			got := skipPreamble(rows, config)
+
+			first := ""
+			if len(got) > 0 {
+				first = strings.Join(got[0], "|")
+			}
+			if first != tt.want {
+				t.Errorf("первая строка данных = %q, ожидалось %q", first, tt.want)
+			}
+		})
+	}
+}
+
+// TestMergeFilesSkipsVariablePreamble тестирует объединение файлов с преамбулой разной длины
+func TestMergeFilesSkipsVariablePreamble(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+
+	// Между заголовками и данными - пояснения и строка-маркер, число строк в файлах разное
+	exceltest.WriteWorkbook(t, basePath, "Прайс", [][]string{
+		{"Артикул", "Цена"},
+		{"", "Цены с НДС"},
+		{"Артикул", ""},
+		{"A1", "100"},
+	})
+	exceltest.WriteWorkbook(t, sourcePath, "Прайс", [][]string{
+		{"Артикул", "Цена"},
+		{"", "Цены с НДС"},
+		{"", "Скидки не учтены"},
+		{},
+		{"", "Обновлено 01.10"},
+		{"Артикул", ""},
+		{"A2", "200"},
+		{"A3", "300"},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Прайс": {SheetName: "Прайс", Enabled: true, HeaderRow: 1, FilterColumn: -1,
+			DataStartColumn: "A", DataStartMarker: "Артикул"},
+	}
+
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Прайс")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	want := [][]string{
+		{"Артикул", "Цена"},
+		{"A1", "100"},
+		{"A2", "200"},
+		{"A3", "300"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("строк = %d, ожидалось %d: %q", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("строка %d = %q, ожидалось %q", i+1, rows[i], want[i])
+		}
+	}
+	if result.TotalRows != 3 {
+		t.Errorf("TotalRows = %d, ожидалось 3", result.TotalRows)
+	}
+}
+
+// TestMergeFilesRawCellFallback тестирует объединение значений, скрытых форматом ячейки в источнике
+func TestMergeFilesRawCellFallback(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Прайс", [][]string{{"Артикул", "Цена"}, {"A1", "100"}})
+
+	// Цена в источнике скрыта форматом ";;;": при обычном чтении ячейка пустая
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", "Прайс"); err != nil {
+		t.Fatal(err)
+	}
+	f.SetSheetRow("Прайс", "A1", &[]interface{}{"Артикул", "Цена"})
+	f.SetSheetRow("Прайс", "A2", &[]interface{}{"A2", 200})
+	hidden := ";;;"
+	style, err := f.NewStyle(&excelize.Style{CustomNumFmt: &hidden})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetCellStyle("Прайс", "B2", "B2", style); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SaveAs(sourcePath); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tests := []struct {
+		name     string
+		fallback bool
+		want     string
+	}{
+		{"без перечитывания", false, "A2"},
+		{"с перечитыванием", true, "A2|200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheetConfigs := map[string]*SheetConfig{
+				"Прайс": {SheetName: "Прайс", Enabled: true, HeaderRow: 1, FilterColumn: -1, RawCellFallback: tt.fallback},
+			}
+
+			result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+			if err != nil {
+				t.Fatalf("ошибка при объединении файлов: %v", err)
+			}
+			defer result.Close()
+
+			rows, err := result.WorkbookData.GetFile().GetRows("Прайс")
+			if err != nil {
+				t.Fatalf("не удалось прочитать результат: %v", err)
+			}
+			if len(rows) != 3 {
+				t.Fatalf("строк = %d, ожидалось 3: %q", len(rows), rows)
+			}
+			if got := strings.Join(rows[2], "|"); got != tt.want {
+				t.Errorf("строка источника = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMergeFilesKeepsRawTextColumns тестирует перенос ссылок и кодов без преобразований
+func TestMergeFilesKeepsRawTextColumns(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+
+	// Повтор параметров, разделитель склейки и пробел в конце не должны изменить ссылку
+	url := "https://cdn1.ozone.ru/s3/multimedia-video/6912345678.mp4?sign=a1b2&utm_source=seller&utm_source=seller" +
+		"&title=%D0%9A%D1%80%D0%BE%D1%81%D1%81%D0%BE%D0%B2%D0%BA%D0%B8, 42&expires=1767225600 "
+	exceltest.WriteWorkbook(t, basePath, "Озон.Видео", [][]string{
+		{"Артикул", "Озон.Видео: ссылка", "Код"},
+		{"A1", url, "0001234"},
+	})
+	exceltest.WriteWorkbook(t, sourcePath, "Озон.Видео", [][]string{
+		{"Артикул", "Озон.Видео: ссылка", "Код"},
+		{"A1", url, "0001234"},
+		{"A2", url + "&v=2", "0005678"},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Озон.Видео": {
+			SheetName:      "Озон.Видео",
+			Enabled:        true,
+			HeaderRow:      1,
+			FilterColumn:   -1,
+			ColumnTypes:    map[int]string{1: ColumnTypeNumber, 2: ColumnTypeNumber},
+			DedupKey:       "Артикул",
+			Coalesce:       map[string]string{"Озон.Видео: ссылка": "join-unique(&)"},
+			RawTextColumns: []string{"озон.видео: ссылка", "Код", "Нет такого"},
+		},
+	}
+
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	file := result.WorkbookData.GetFile()
+	rows, err := file.GetRows("Озон.Видео")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("строк = %d, ожидалось 3: %q", len(rows), rows)
+	}
+	if rows[1][1] != url {
+		t.Errorf("ссылка изменена:\n got %q\nwant %q", rows[1][1], url)
+	}
+	if rows[2][1] != url+"&v=2" {
+		t.Errorf("ссылка второй строки изменена: %q", rows[2][1])
+	}
+
+	// Код с ведущими нулями остается текстом, несмотря на тип number
+	for _, cell := range []string{"C2", "C3"} {
+		cellType, err := file.GetCellType("Озон.Видео", cell)
+		if err != nil {
+			t.Fatalf("не удалось получить тип ячейки %s: %v", cell, err)
+		}
+		if cellType == excelize.CellTypeNumber {
+			t.Errorf("ячейка %s записана числом", cell)
+		}
+	}
+	if rows[1][2] != "0001234" {
+		t.Errorf("код = %q, ожидалось 0001234", rows[1][2])
+	}
+
+	// Столбцы без преобразований не проверяются по типу; ненайденный заголовок - сведение
+	for _, warning := range result.Warnings {
+		if warning.Severity != SeverityInfo || !strings.Contains(warning.Message, "Нет такого") {
+			t.Errorf("неожиданное предупреждение: %s", warning.Message)
+		}
+	}
+	if result.WarningCounts[SeverityInfo] != 1 {
+		t.Errorf("WarningCounts = %v, ожидалось одно сведение о ненайденном столбце", result.WarningCounts)
+	}
+}
+
+// TestMergeFilesMapsValues тестирует приведение значений столбца к единому виду
+func TestMergeFilesMapsValues(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Артикул", "В наличии", "Комментарий"},
+		{"A1", "Да", "1"},
+	})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{
+		{"Артикул", "В наличии", "Комментарий"},
+		{"A2", "1", "да"},
+		{"A3", " ДА ", ""},
+		{"A4", "true", ""},
+		{"A5", "нет", ""},
+		{"A6", "может быть", ""},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {
+			SheetName:    "Data",
+			Enabled:      true,
+			HeaderRow:    1,
+			FilterColumn: -1,
+			ValueMaps: map[int]map[string]string{
+				1: {"да": "Yes", "1": "Yes", "TRUE": "Yes", "нет": "No"},
+			},
+		},
+	}
+
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Data")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+
+	want := [][]string{
+		{"Артикул", "В наличии", "Комментарий"},
+		{"A1", "Yes", "1"},
+		{"A2", "Yes", "да"},
+		{"A3", "Yes"},
+		{"A4", "Yes"},
+		{"A5", "No"},
+		{"A6", "может быть"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("результат = %q, ожидалось %q", rows, want)
+	}
+}
+
+// TestMergeFilesRawTextColumnsSkipTransforms тестирует, что к столбцам без преобразований
+// не применяются замены значений, префиксы и суффиксы
+func TestMergeFilesRawTextColumnsSkipTransforms(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{
+		{"Артикул", "Код", "В наличии"},
+		{"A1", "да", "да"},
+	})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{
+		{"Артикул", "Код", "В наличии"},
+		{"A2", "да ", "да"},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {
+			SheetName:      "Data",
+			Enabled:        true,
+			HeaderRow:      1,
+			FilterColumn:   -1,
+			RawTextColumns: []string{"Код"},
+			ValueMaps: map[int]map[string]string{
+				1: {"да": "Yes"},
+				2: {"да": "Yes"},
+			},
+			AffixRules: []AffixRule{
+				{Header: "Код", Prefix: "SHZ-"},
+				{Header: "Артикул", Prefix: "SHZ-"},
+			},
+		},
+	}
+
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Data")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+
+	want := [][]string{
+		{"Артикул", "Код", "В наличии"},
+		{"SHZ-A1", "да", "Yes"},
+		{"SHZ-A2", "да ", "Yes"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("результат = %q, ожидалось %q", rows, want)
+	}
+
+	// О пропущенных замене и префиксе сообщается один раз на лист
+	var skipped int
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning.Message, "'Код' переносится без преобразований") {
+			skipped++
+		}
+	}
+	if skipped != 2 {
+		t.Errorf("предупреждений о пропущенных преобразованиях = %d, ожидалось 2: %v", skipped, result.Warnings)
+	}
+}
+
+// TestMergeFilesSharesReaderPool тестирует, что проверки и объединение открывают каждый файл один раз
+func TestMergeFilesSharesReaderPool(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+
+	sheets := []testSheet{
+		{"Товары", [][]string{{"Артикул", "Цена"}, {"A1", "10"}}},
+		{"Видео", [][]string{{"Артикул", "Ссылка"}, {"A1", "https://example.com/1.mp4"}}},
+	}
+	writeTestWorkbookSheets(t, basePath, sheets)
+	writeTestWorkbookSheets(t, sourcePath, sheets)
+
+	profileSheets := []SheetConfig{
+		{SheetName: "Товары", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+		{SheetName: "Видео", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+	sheetConfigs := map[string]*SheetConfig{
+		"Товары": &profileSheets[0],
+		"Видео":  &profileSheets[1],
+	}
+
+	pool := excel.NewReaderPool(excel.DefaultReaderPoolSize)
+	before := excel.OpenHandles()
+	analyzer := NewBaseAnalyzer(nil, logger)
+	analyzer.SetReaderPool(pool)
+	merger := NewMerger(nil, logger)
+	merger.SetReaderPool(pool)
+
+	// Проверка при добавлении файла и перед объединением
+	if missing, err := analyzer.MissingSourceSheets(sourcePath, profileSheets); err != nil || len(missing) != 0 {
+		t.Fatalf("MissingSourceSheets() = %v, %v", missing, err)
+	}
+	if err := analyzer.CheckEnabledSheets(basePath, profileSheets); err != nil {
+		t.Fatalf("CheckEnabledSheets() error = %v", err)
+	}
+
+	result, err := merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+	if result.TotalRows != 4 {
+		t.Errorf("TotalRows = %d, ожидалось 4", result.TotalRows)
+	}
+
+	if pool.Opens() != 2 {
+		t.Errorf("файлы открыты %d раз, ожидалось 2 (по разу на файл)", pool.Opens())
+	}
+
+	// По завершении объединения книги пула закрыты
+	if pool.Len() != 0 {
+		t.Errorf("в пуле %d книг после объединения", pool.Len())
+	}
+	if after := excel.OpenHandles(); after != before+1 {
+		t.Errorf("открыто книг %d, ожидалось %d (только результат)", after, before+1)
+	}
+}
+
+// TestMergeFilesTemplateArticlesAlign тестирует фильтрацию по артикулам листа "Шаблон",
+// когда заголовок артикула в шаблоне и в фильтруемом листе записан по-разному
+func TestMergeFilesTemplateArticlesAlign(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+
+	template := []string{"Название", "Артикул*"}
+	video := []string{"Код артикула поставщика", " артикул ", "Ссылка"}
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Шаблон", [][]string{template, {"Ботинки", "A1"}}},
+		{"Видео", [][]string{video, {"S-1", "A1", "v1"}, {"S-9", "A9", "v9"}}},
+		{"Фото", [][]string{{"Название", "Фото"}, {"Ботинки", "p1"}}},
+	})
+	writeTestWorkbookSheets(t, sourcePath, []testSheet{
+		{"Шаблон", [][]string{template, {"Кеды", "A2"}}},
+		{"Видео", [][]string{video, {"A1", "A2", "v2"}, {"A2", "A3", "v3"}}},
+		{"Фото", [][]string{{"Название", "Фото"}, {"Кеды", "p2"}}},
+	})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Шаблон": {SheetName: "Шаблон", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+		"Видео":  {SheetName: "Видео", Enabled: true, HeaderRow: 1, FilterColumn: -1, UseTemplateArticles: true},
+		"Фото":   {SheetName: "Фото", Enabled: true, HeaderRow: 1, FilterColumn: -1, UseTemplateArticles: true},
+	}
+
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Видео")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	want := [][]string{video, {"S-1", "A1", "v1"}, {"A1", "A2", "v2"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("лист Видео = %q, ожидалось %q", rows, want)
+	}
+
+	// Лист без столбца артикула не фильтруется молча: в результате есть предупреждение
+	found := false
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning.Message, "'Фото'") && strings.Contains(warning.Message, "столбец артикула") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("нет предупреждения о листе без столбца артикула: %v", result.Warnings)
+	}
+}
+
+// TestMergeFilesRecordsFingerprints тестирует отпечатки прочитанных файлов и сохраненного результата
+func TestMergeFilesRecordsFingerprints(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	missingPath := filepath.Join(dir, "missing.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул"}, {"A1"}})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{{"Артикул"}, {"A2"}})
+
+	sha256File := func(path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+	merger := NewMerger(nil, logger)
+	merger.SetSettings(ProfileSettings{OnError: OnErrorContinue})
+
+	// Неоткрывшийся файл в отпечатки не попадает
+	result, err := merger.MergeFiles(basePath, []string{sourcePath, missingPath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка объединения: %v", err)
+	}
+	defer result.Close()
+
+	if result.HashAlgorithm != excel.HashSHA256 {
+		t.Errorf("HashAlgorithm = %q, ожидался %q", result.HashAlgorithm, excel.HashSHA256)
+	}
+	if len(result.InputHashes) != 2 {
+		t.Fatalf("InputHashes = %+v, ожидались базовый файл и источник", result.InputHashes)
+	}
+	for i, path := range []string{basePath, sourcePath} {
+		hash := result.InputHashes[i]
+		if hash.Path != path || hash.Sum != sha256File(path) {
+			t.Errorf("InputHashes[%d] = %+v, ожидался отпечаток %s", i, hash, filepath.Base(path))
+		}
+	}
+	if result.OutputHash != nil {
+		t.Error("OutputHash заполнен до сохранения")
+	}
+
+	outputPath := filepath.Join(dir, "result.xlsx")
+	if err := result.Save(outputPath); err != nil {
+		t.Fatalf("не удалось сохранить результат: %v", err)
+	}
+	if result.OutputHash == nil || result.OutputHash.Path != outputPath || result.OutputHash.Sum != sha256File(outputPath) {
+		t.Errorf("OutputHash = %+v не совпадает с сохраненным файлом", result.OutputHash)
+	}
+
+	// Алгоритм задается настройками профиля
+	merger.SetSettings(ProfileSettings{HashAlgorithm: "crc64"})
+	crcResult, err := merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка объединения: %v", err)
+	}
+	defer crcResult.Close()
+	for _, hash := range crcResult.InputHashes {
+		if hash.Algorithm != excel.HashCRC64 || len(hash.Sum) != 16 {
+			t.Errorf("отпечаток %+v, ожидался crc64", hash)
+		}
+	}
+
+	merger.SetSettings(ProfileSettings{HashAlgorithm: "md5"})
+	if _, err := merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs); !errors.Is(err, apperrors.ErrConfig) {
+		t.Errorf("ошибка = %v, ожидалась ошибка конфигурации", err)
+	}
+}
+
+// updateGolden перезаписывает эталонные файлы testdata/golden: go test ./internal/core -run Golden -update
+var updateGolden = flag.Bool("update", false, "перезаписать эталонные файлы testdata/golden")
+
+// goldenWorkbook нормализованное содержимое книги результата для сравнения с эталоном
+type goldenWorkbook struct {
+	Created  string        `json:"created"`
+	Modified string        `json:"modified"`
+	Sheets   []goldenSheet `json:"sheets"`
+	Warnings []string      `json:"warnings"`
+}
+
+// goldenSheet лист книги результата в порядке следования
+type goldenSheet struct {
+	Name string     `json:"name"`
+	Rows [][]string `json:"rows"`
+}
+
+// TestMergeFilesDeterministicGolden тестирует воспроизводимый режим: два объединения одних и тех же
+// файлов дают побайтно одинаковые книги, а их содержимое совпадает с эталоном
+func TestMergeFilesDeterministicGolden(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	writeTestWorkbookSheets(t, basePath, []testSheet{
+		{"Цены", [][]string{{"Артикул", "Цена", "Теги"}, {"A1", "100", "лето"}, {"A2", "200", ""}}},
+		{"Остатки", [][]string{{"Выгрузка склада"}, {"Артикул", "Остаток"}, {"A1", "5"}}},
+	})
+	writeTestWorkbookSheets(t, sourcePath, []testSheet{
+		{"Цены", [][]string{{"Артикул", "Цена", "Теги"}, {"A3", "300", "зима"}, {"A4", "400", ""}, {"A3", "", "обувь"}}},
+		{"Остатки", [][]string{{""}, {"Артикул", "Остаток"}, {"A3", "7"}}},
+	})
+
+	// Время изменения базового файла - текущее, как у реальной выгрузки
+	base, err := excelize.OpenFile(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if err := base.SetDocProps(&excelize.DocProperties{Created: now, Modified: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.Save(); err != nil {
+		t.Fatal(err)
+	}
+	base.Close()
+
+	// Дубликаты склеиваются среди дописанных строк; порядок предупреждений о столбцах склейки не зависит от карты
+	sheetConfigs := map[string]*SheetConfig{
+		"Цены": {SheetName: "Цены", Enabled: true, HeaderRow: 1, FilterColumn: -1, DedupKey: "Артикул",
+			Coalesce: map[string]string{"Теги": "join-unique", "Нет1": "first", "Нет2": "first"}},
+		"Остатки": {SheetName: "Остатки", Enabled: true, HeaderRow: 2, FilterColumn: -1},
+	}
+
+	merge := func(name string) (*MergeResult, string) {
+		merger := NewMerger(nil, logger)
+		merger.SetDeterministic(true)
+		merger.SetSettings(ProfileSettings{MergeIntoBase: true})
+		result, err := merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+		if err != nil {
+			t.Fatalf("ошибка объединения: %v", err)
+		}
+		t.Cleanup(func() { result.Close() })
+		path := filepath.Join(dir, name)
+		if err := result.Save(path); err != nil {
+			t.Fatalf("не удалось сохранить результат: %v", err)
+		}
+		return result, path
+	}
+
+	first, firstPath := merge("first.xlsx")
+	second, _ := merge("second.xlsx")
+	if first.RunID != deterministicRunID {
+		t.Errorf("RunID = %q, ожидался %q", first.RunID, deterministicRunID)
+	}
+	if first.OutputHash.Sum != second.OutputHash.Sum {
+		t.Errorf("результаты двух объединений различаются: %s и %s", first.OutputHash.Sum, second.OutputHash.Sum)
+	}
+
+	// Нормализованное содержимое не зависит от версии библиотеки записи, в отличие от байтов файла
+	output, err := excelize.OpenFile(firstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+	props, err := output.GetDocProps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := goldenWorkbook{Created: props.Created, Modified: props.Modified, Warnings: []string{}}
+	for _, name := range output.GetSheetList() {
+		rows, err := output.GetRows(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.Sheets = append(got.Sheets, goldenSheet{Name: name, Rows: rows})
+	}
+	for _, warning := range first.Warnings {
+		got.Warnings = append(got.Warnings, warning.String())
+	}
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+
+	goldenPath := filepath.Join("..", "..", "testdata", "golden", "merge_deterministic.json")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goldenPath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("нет эталона (создается флагом -update): %v", err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("результат не совпадает с эталоном %s:\n%s", goldenPath, data)
+	}
+}
+
+// TestMergeFilesDuplicateFiles тестирует обработку файлов с одинаковым содержимым
+func TestMergeFilesDuplicateFiles(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	firstPath := filepath.Join(dir, "Повседневная обувь_04.11.2025.xlsx")
+	secondPath := filepath.Join(dir, "Повседневная обувь_04.11.2025 (1).xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул", "Цена"}, {"B1", "10"}})
+	exceltest.WriteWorkbook(t, firstPath, "Data", [][]string{{"Артикул", "Цена"}, {"A1", "100"}, {"A2", "200"}})
+	data, err := os.ReadFile(firstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(secondPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+
+	tests := []struct {
+		name         string
+		policy       string
+		files        []string
+		wantRows     int
+		wantCopy     string
+		wantSeverity Severity
+		wantSkipped  bool
+	}{
+		{"предупреждение по умолчанию", "", []string{firstPath, secondPath}, 5, secondPath, SeverityWarning, false},
+		{"пропуск копии", DuplicateFilesSkip, []string{firstPath, secondPath}, 3, secondPath, SeverityInfo, true},
+		{"базовый файл в списке", DuplicateFilesSkip, []string{basePath, firstPath}, 3, basePath, SeverityInfo, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merger := NewMerger(nil, logger)
+			merger.SetSettings(ProfileSettings{DuplicateFiles: tt.policy})
+			result, err := merger.MergeFiles(basePath, tt.files, sheetConfigs)
+			if err != nil {
+				t.Fatalf("ошибка объединения: %v", err)
+			}
+			defer result.Close()
+
+			if result.TotalRows != tt.wantRows {
+				t.Errorf("TotalRows = %d, ожидалось %d", result.TotalRows, tt.wantRows)
+			}
+			if len(result.DuplicateFiles) != 1 || result.DuplicateFiles[0].Path != tt.wantCopy {
+				t.Fatalf("DuplicateFiles = %+v", result.DuplicateFiles)
+			}
+			if result.DuplicateFiles[0].Skipped != tt.wantSkipped {
+				t.Errorf("Skipped = %v, ожидалось %v", result.DuplicateFiles[0].Skipped, tt.wantSkipped)
+			}
+			if result.WarningCounts[tt.wantSeverity] != 1 {
+				t.Errorf("предупреждения = %v, ожидалось одно уровня %s", result.Warnings, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+// TestMergeResultSaveWithPolicy тестирует сохранение результата в уже существующий файл
+func TestMergeResultSaveWithPolicy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул"}, {"A1"}})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{{"Артикул"}, {"A2"}})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+
+	tests := []struct {
+		name       string
+		onExisting string
+		wantRows   [][]string // nil - файл не должен измениться
+		wantErr    error
+	}{
+		{"перезапись по умолчанию", "", [][]string{{"Артикул"}, {"A1"}, {"A2"}}, nil},
+		{"перезапись", OnExistingOverwrite, [][]string{{"Артикул"}, {"A1"}, {"A2"}}, nil},
+		{"дописывание", OnExistingAppend, [][]string{{"Артикул"}, {"Z1"}, {"Z2"}, {"A1"}, {"A2"}}, nil},
+		{"отмена", OnExistingCancel, nil, ErrOutputExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "result.xlsx")
+			writeTestWorkbookSheets(t, outputPath, []testSheet{
+				{"Data", [][]string{{"Артикул"}, {"Z1"}, {"Z2"}}},
+				{"Заметки", [][]string{{"не изменять"}}},
+			})
+			before, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+			if err != nil {
+				t.Fatalf("ошибка объединения: %v", err)
+			}
+			defer result.Close()
+
+			err = result.SaveWithPolicy(outputPath, tt.onExisting)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ошибка = %v, ожидалась %v", err, tt.wantErr)
+				}
+				after, _ := os.ReadFile(outputPath)
+				if !bytes.Equal(before, after) {
+					t.Error("файл изменен при отмене")
+				}
+				if result.OutputHash != nil {
+					t.Errorf("OutputHash = %+v при отмене", result.OutputHash)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ошибка сохранения: %v", err)
+			}
+
+			f, err := excelize.OpenFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			rows, err := f.GetRows("Data")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("строки Data = %q, ожидалось %q", rows, tt.wantRows)
+			}
+			// Другие листы файла сохраняются только при дописывании
+			index, err := f.GetSheetIndex("Заметки")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kept := index >= 0; kept != (tt.onExisting == OnExistingAppend) {
+				t.Errorf("лист Заметки сохранен = %v", kept)
+			}
+			if result.OutputHash == nil || result.OutputHash.Path != outputPath {
+				t.Errorf("OutputHash = %+v", result.OutputHash)
+			}
+		})
+	}
+
+	// Новый файл сохраняется при любой политике
+	newPath := filepath.Join(dir, "new.xlsx")
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка объединения: %v", err)
+	}
+	defer result.Close()
+	if err := result.SaveWithPolicy(newPath, OnExistingCancel); err != nil {
+		t.Errorf("новый файл не сохранен: %v", err)
+	}
+}
+
+// TestMergeFilesGzipSource тестирует объединение выгрузки, сжатой gzip (file.xlsx.gz)
+func TestMergeFilesGzipSource(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул"}, {"A1"}})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{{"Артикул"}, {"A2"}})
+
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write(data)
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gzipPath := sourcePath + ".gz"
+	if err := os.WriteFile(gzipPath, compressed.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{gzipPath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка объединения: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"Артикул"}, {"A1"}, {"A2"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("строки результата = %q, ожидалось %q", rows, want)
+	}
+}
+
+// TestMergeFilesProtectHeaders тестирует защиту шапки листов результата
+func TestMergeFilesProtectHeaders(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	sheets := []testSheet{
+		{"Data", [][]string{{"Отчет"}, {"Артикул", "Цена"}, {"A1", "100"}}},
+		{"Other", [][]string{{"Артикул"}, {"B1"}}},
+	}
+	writeTestWorkbookSheets(t, basePath, sheets)
+	writeTestWorkbookSheets(t, sourcePath, sheets)
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data":  {SheetName: "Data", Enabled: true, HeaderRow: 2, FilterColumn: -1},
+		"Other": {SheetName: "Other", Enabled: true, HeaderRow: 1, FilterColumn: -1, SkipHeaderProtection: true},
+	}
+	merger := NewMerger(nil, logger)
+	merger.SetSettings(ProfileSettings{ProtectHeaders: true, ProtectionPassword: "secret"})
+	result, err := merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка объединения: %v", err)
+	}
+	defer result.Close()
+
+	file := result.WorkbookData.GetFile()
+	tests := []struct {
+		sheet, cell string
+		locked      bool
+	}{
+		{"Data", "A1", true},
+		{"Data", "B2", true},
+		{"Data", "A3", false},
+		{"Data", "B4", false},
+		// Лист без защиты шапки не изменяется: ячейки заблокированы по умолчанию, но лист не защищен
+		{"Other", "A2", true},
+	}
+	for _, tt := range tests {
+		idx, err := file.GetCellStyle(tt.sheet, tt.cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		style, err := file.GetStyle(idx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locked := style.Protection == nil || style.Protection.Locked
+		if locked != tt.locked {
+			t.Errorf("%s!%s заблокирована = %v, ожидалось %v", tt.sheet, tt.cell, locked, tt.locked)
+		}
+	}
+
+	// Защиту листа нельзя снять неверным паролем; с незащищенного листа снимать нечего
+	if err := file.UnprotectSheet("Data", "wrong"); !errors.Is(err, excelize.ErrUnprotectSheetPassword) {
+		t.Errorf("снятие защиты листа Data неверным паролем: %v", err)
+	}
+	if err := file.UnprotectSheet("Other", "wrong"); !errors.Is(err, excelize.ErrUnprotectSheet) {
+		t.Errorf("лист Other защищен: %v", err)
+	}
+}
+
+// TestMergeFilesUnmatchedFilterValue тестирует предупреждение о значении фильтра, не найденном ни в одном файле
+func TestMergeFilesUnmatchedFilterValue(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	firstPath := filepath.Join(dir, "first.xlsx")
+	secondPath := filepath.Join(dir, "second.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул", "Бренд"}})
+	exceltest.WriteWorkbook(t, firstPath, "Data", [][]string{{"Артикул", "Бренд"}, {"A1", "Shuzzi"}, {"A2", "Other"}})
+	// Значение Nike есть только во втором файле: оно не должно попасть в предупреждение
+	exceltest.WriteWorkbook(t, secondPath, "Data", [][]string{{"Артикул", "Бренд"}, {"B1", "nike"}})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: 1, FilterValues: []string{"Shuzzi", "Shuzi", "Nike"}},
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{firstPath, secondPath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка объединения: %v", err)
+	}
+	defer result.Close()
+
+	var filterWarnings []string
+	for _, warning := range result.Warnings {
+		if strings.Contains(warning.Message, "значение фильтра") {
+			filterWarnings = append(filterWarnings, warning.Message)
+		}
+	}
+	if len(filterWarnings) != 1 || !strings.Contains(filterWarnings[0], "'Shuzi'") {
+		t.Errorf("предупреждения о фильтре = %q, ожидалось одно о значении Shuzi", filterWarnings)
+	}
+	if result.TotalRows != 2 {
+		t.Errorf("объединено строк: %d, ожидалось 2", result.TotalRows)
+	}
+}
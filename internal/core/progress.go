@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// ProgressSink доставляет обновления прогресса объединения потребителю через канал
+// Канал принадлежит потребителю: он создает приемник, читает Updates и закрывает его через Close
+// Send после Close или отмены контекста ничего не делает и не блокируется, поэтому
+// поздний вызов callback из объединения не приводит к отправке в закрытый канал
+type ProgressSink struct {
+	ctx     context.Context
+	updates chan ProgressUpdate
+	stop    chan struct{} // Закрывается первым, чтобы прервать заблокированные Send
+	once    sync.Once
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewProgressSink создает приемник прогресса с буфером buffer обновлений
+// Отмена ctx прекращает доставку так же, как Close
+func NewProgressSink(ctx context.Context, buffer int) *ProgressSink {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &ProgressSink{
+		ctx:     ctx,
+		updates: make(chan ProgressUpdate, buffer),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Send передает обновление потребителю; подходит как ProgressCallback
+// Ждет места в буфере, пока приемник не закрыт и контекст не отменен
+func (s *ProgressSink) Send(current, total int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.updates <- ProgressUpdate{Current: current, Total: total, Message: message}:
+	case <-s.stop:
+	case <-s.ctx.Done():
+	}
+}
+
+// Updates возвращает канал обновлений; он закрывается в Close
+func (s *ProgressSink) Updates() <-chan ProgressUpdate {
+	return s.updates
+}
+
+// Close прекращает доставку и закрывает канал обновлений
+// Можно вызывать несколько раз и одновременно с Send
+func (s *ProgressSink) Close() {
+	s.once.Do(func() {
+		close(s.stop)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.closed = true
+		close(s.updates)
+	})
+}
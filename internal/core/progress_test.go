@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// TestProgressSink тестирует доставку обновлений и закрытие приемника прогресса
+func TestProgressSink(t *testing.T) {
+	t.Run("доставка по порядку", func(t *testing.T) {
+		sink := NewProgressSink(context.Background(), 2)
+		sink.Send(1, 2, "первый")
+		sink.Send(2, 2, "второй")
+		sink.Close()
+
+		var got []ProgressUpdate
+		for update := range sink.Updates() {
+			got = append(got, update)
+		}
+		if len(got) != 2 || got[0].Message != "первый" || got[1].Current != 2 {
+			t.Errorf("получено %+v", got)
+		}
+	})
+
+	t.Run("отправка после закрытия", func(t *testing.T) {
+		sink := NewProgressSink(context.Background(), 1)
+		sink.Close()
+		sink.Close()
+		sink.Send(1, 1, "поздно")
+		if _, ok := <-sink.Updates(); ok {
+			t.Error("обновление доставлено после Close")
+		}
+	})
+
+	t.Run("отмена контекста снимает блокировку", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		sink := NewProgressSink(ctx, 0)
+
+		sent := make(chan struct{})
+		go func() {
+			sink.Send(1, 1, "без читателя")
+			close(sent)
+		}()
+		cancel()
+
+		select {
+		case <-sent:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Send заблокирован после отмены контекста")
+		}
+		sink.Close()
+	})
+}
+
+// TestProgressSinkStress отправляет прогресс из нескольких горутин, пока потребитель
+// закрывает приемник или отменяет контекст; запускать с -race
+func TestProgressSinkStress(t *testing.T) {
+	const rounds, producers, sends = 200, 4, 500
+
+	for round := range rounds {
+		ctx, cancel := context.WithCancel(context.Background())
+		sink := NewProgressSink(ctx, 1)
+
+		var wg sync.WaitGroup
+		for range producers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range sends {
+					sink.Send(i, sends, "строка")
+				}
+			}()
+		}
+
+		// Потребитель читает часть обновлений и уходит, как при завершении или отмене
+		for range round % 7 {
+			<-sink.Updates()
+		}
+		if round%2 == 0 {
+			cancel()
+		}
+		sink.Close()
+		wg.Wait()
+		cancel()
+	}
+}
+
+// TestMergeFilesWithClosedProgressSink тестирует, что объединение завершается,
+// когда потребитель прогресса закрыл приемник до конца объединения
+func TestMergeFilesWithClosedProgressSink(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	rows := [][]string{{"Артикул", "Цена"}, {"A1", "100"}}
+	exceltest.WriteWorkbook(t, basePath, "Data", rows)
+	exceltest.WriteWorkbook(t, sourcePath, "Data", rows)
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+
+	for _, read := range []int{0, 1, 100} {
+		sink := NewProgressSink(context.Background(), 0)
+		merger := NewMerger(nil, logger)
+		merger.SetProgressCallback(sink.Send)
+
+		done := make(chan error, 1)
+		go func() {
+			result, err := merger.MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+			if result != nil {
+				result.Close()
+			}
+			merger.SetProgressCallback(nil)
+			done <- err
+		}()
+
+		// Потребитель прочитал read обновлений (или все, что были) и закрыл приемник
+		received := 0
+	consume:
+		for received < read {
+			select {
+			case <-sink.Updates():
+				received++
+			case err := <-done:
+				done <- err
+				break consume
+			}
+		}
+		sink.Close()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("ошибка при объединении файлов: %v", err)
+			}
+		case <-time.After(30 * time.Second):
+			t.Fatalf("объединение не завершилось после закрытия приемника (прочитано %d)", received)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Разделители между строками разных файлов в листе результата
+const (
+	SeparatorNone     = "none"      // Без разделителей (по умолчанию)
+	SeparatorBlankRow = "blank-row" // Пустая строка между файлами
+	SeparatorLabelRow = "label-row" // Строка с именем файла перед его строками
+)
+
+// SeparatorLabelPrefix начало строки-подписи файла; по нему строки-подписи отличаются от данных
+const SeparatorLabelPrefix = "### Файл: "
+
+// ValidateSeparatorMode проверяет режим разделителей листа
+// Разделители несовместимы со склейкой дубликатов: после склейки строки не принадлежат одному файлу
+func ValidateSeparatorMode(mode, dedupKey string) error {
+	switch mode {
+	case "", SeparatorNone:
+		return nil
+	case SeparatorBlankRow, SeparatorLabelRow:
+		if dedupKey != "" {
+			return fmt.Errorf("разделители '%s' несовместимы со склейкой дубликатов по столбцу '%s'", mode, dedupKey)
+		}
+		return nil
+	default:
+		return fmt.Errorf("неизвестный режим разделителей '%s' (ожидается %s, %s или %s)",
+			mode, SeparatorNone, SeparatorBlankRow, SeparatorLabelRow)
+	}
+}
+
+// separatorLabel возвращает текст строки-подписи файла fileName
+func separatorLabel(fileName string) string {
+	return SeparatorLabelPrefix + fileName
+}
+
+// IsSeparatorRow сообщает, что строка - подпись файла, записанная в режиме SeparatorLabelRow
+// Пустые строки-разделители SeparatorBlankRow распознаются как пустые строки
+func IsSeparatorRow(row []string) bool {
+	if len(row) == 0 || !strings.HasPrefix(row[0], SeparatorLabelPrefix) {
+		return false
+	}
+	for _, value := range row[1:] {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+func TestValidateSeparatorMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		dedupKey string
+		wantErr  bool
+	}{
+		{"по умолчанию", "", "", false},
+		{"без разделителей", SeparatorNone, "Артикул", false},
+		{"пустая строка", SeparatorBlankRow, "", false},
+		{"подпись", SeparatorLabelRow, "", false},
+		{"неизвестный режим", "line", "", true},
+		{"со склейкой дубликатов", SeparatorLabelRow, "Артикул", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSeparatorMode(tt.mode, tt.dedupKey); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSeparatorMode(%q, %q) error = %v, wantErr %v", tt.mode, tt.dedupKey, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsSeparatorRow(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []string
+		want bool
+	}{
+		{"подпись", []string{separatorLabel("a.xlsx")}, true},
+		{"подпись с пустыми ячейками", []string{separatorLabel("a.xlsx"), "", " "}, true},
+		{"данные", []string{"A1", "100"}, false},
+		{"префикс и данные", []string{separatorLabel("a.xlsx"), "100"}, false},
+		{"пустая строка", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSeparatorRow(tt.row); got != tt.want {
+				t.Errorf("IsSeparatorRow(%q) = %v, ожидалось %v", tt.row, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMergeFilesSeparators тестирует размещение разделителей между файлами и подсчет строк
+func TestMergeFilesSeparators(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	firstPath := filepath.Join(dir, "first.xlsx")
+	emptyPath := filepath.Join(dir, "empty.xlsx")
+	secondPath := filepath.Join(dir, "second.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул"}, {"A1"}})
+	exceltest.WriteWorkbook(t, firstPath, "Data", [][]string{{"Артикул"}, {"B1"}, {"B2"}})
+	exceltest.WriteWorkbook(t, emptyPath, "Data", [][]string{{"Артикул"}})
+	exceltest.WriteWorkbook(t, secondPath, "Data", [][]string{{"Артикул"}, {"C1"}})
+
+	tests := []struct {
+		name         string
+		mode         string
+		dedupKey     string
+		want         [][]string
+		wantWarnings int
+	}{
+		// Файл без строк данных дает сведения "нет строк данных" в каждом режиме
+		{"без разделителей", SeparatorNone, "", [][]string{{"Артикул"}, {"A1"}, {"B1"}, {"B2"}, {"C1"}}, 1},
+		{"пустая строка", SeparatorBlankRow, "", [][]string{{"Артикул"}, {"A1"}, {}, {"B1"}, {"B2"}, {}, {"C1"}}, 1},
+		{"подпись", SeparatorLabelRow, "", [][]string{
+			{"Артикул"},
+			{separatorLabel("base.xlsx")}, {"A1"},
+			{separatorLabel("first.xlsx")}, {"B1"}, {"B2"},
+			{separatorLabel("second.xlsx")}, {"C1"},
+		}, 1},
+		{"подпись со склейкой дубликатов", SeparatorLabelRow, "Артикул", [][]string{{"Артикул"}, {"A1"}, {"B1"}, {"B2"}, {"C1"}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheetConfigs := map[string]*SheetConfig{
+				"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1, SeparatorMode: tt.mode, DedupKey: tt.dedupKey},
+			}
+			result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{firstPath, emptyPath, secondPath}, sheetConfigs)
+			if err != nil {
+				t.Fatalf("ошибка объединения: %v", err)
+			}
+			defer result.Close()
+
+			rows, err := result.WorkbookData.GetFile().GetRows("Data")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(rows) != fmt.Sprint(tt.want) {
+				t.Errorf("строки результата = %q, ожидалось %q", rows, tt.want)
+			}
+
+			// Разделители не входят в счетчики строк
+			if result.TotalRows != 4 || result.SheetStats["Data"].RowsMerged != 4 {
+				t.Errorf("TotalRows = %d, RowsMerged = %d, ожидалось 4", result.TotalRows, result.SheetStats["Data"].RowsMerged)
+			}
+			if len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("предупреждения = %v, ожидалось %d", result.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// sheetSetting группа настроек листа, которую CopySheetSettings переносит с одного листа на другие
+type sheetSetting struct {
+	name   string                         // Название для подтверждения: что будет перезаписано
+	values func(sheet *SheetConfig) []any // Значения группы для сравнения листов
+	copy   func(dst, src *SheetConfig)    // Копирует значения группы без общих срезов и карт
+}
+
+// copiedSheetSettings настройки, которые копируются на другие листы
+// Имя листа, цвет ярлыка, лист результата, другие имена листа, диапазон данных и флаг фильтрации
+// по артикулам листа "Шаблон" относятся к конкретному листу и не копируются
+var copiedSheetSettings = []sheetSetting{
+	{
+		name:   "строка заголовков",
+		values: func(s *SheetConfig) []any { return []any{s.HeaderRow, s.TypeDescriptorRow} },
+		copy: func(dst, src *SheetConfig) {
+			// Заголовки для предпросмотра прочитаны из прежней строки заголовков;
+			// новые заголовки читает из базового файла вызывающий
+			if dst.HeaderRow != src.HeaderRow {
+				dst.Headers = []string{}
+			}
+			dst.HeaderRow = src.HeaderRow
+			dst.TypeDescriptorRow = src.TypeDescriptorRow
+		},
+	},
+	{
+		name:   "фильтр",
+		values: func(s *SheetConfig) []any { return []any{s.FilterColumn, s.FilterValues} },
+		copy: func(dst, src *SheetConfig) {
+			dst.FilterColumn = src.FilterColumn
+			dst.FilterValues = slices.Clone(src.FilterValues)
+		},
+	},
+	{
+		name:   "типы столбцов",
+		values: func(s *SheetConfig) []any { return []any{s.ColumnTypes} },
+		copy:   func(dst, src *SheetConfig) { dst.ColumnTypes = maps.Clone(src.ColumnTypes) },
+	},
+	{
+		name:   "постоянные столбцы",
+		values: func(s *SheetConfig) []any { return []any{s.ConstantColumns} },
+		copy:   func(dst, src *SheetConfig) { dst.ConstantColumns = slices.Clone(src.ConstantColumns) },
+	},
+	{
+		name:   "склейка дубликатов",
+		values: func(s *SheetConfig) []any { return []any{s.DedupKey, s.Coalesce} },
+		copy: func(dst, src *SheetConfig) {
+			dst.DedupKey = src.DedupKey
+			dst.Coalesce = maps.Clone(src.Coalesce)
+		},
+	},
+	{
+		name:   "начало данных",
+		values: func(s *SheetConfig) []any { return []any{s.DataStartColumn, s.DataStartMarker} },
+		copy: func(dst, src *SheetConfig) {
+			dst.DataStartColumn = src.DataStartColumn
+			dst.DataStartMarker = src.DataStartMarker
+		},
+	},
+	{
+		name:   "чтение скрытых значений",
+		values: func(s *SheetConfig) []any { return []any{s.RawCellFallback} },
+		copy:   func(dst, src *SheetConfig) { dst.RawCellFallback = src.RawCellFallback },
+	},
+	{
+		name:   "столбцы без преобразований",
+		values: func(s *SheetConfig) []any { return []any{s.RawTextColumns} },
+		copy:   func(dst, src *SheetConfig) { dst.RawTextColumns = slices.Clone(src.RawTextColumns) },
+	},
+	{
+		name:   "замены значений",
+		values: func(s *SheetConfig) []any { return []any{s.ValueMaps} },
+		copy: func(dst, src *SheetConfig) {
+			dst.ValueMaps = nil
+			if src.ValueMaps != nil {
+				dst.ValueMaps = make(map[int]map[string]string, len(src.ValueMaps))
+				for col, values := range src.ValueMaps {
+					dst.ValueMaps[col] = maps.Clone(values)
+				}
+			}
+		},
+	},
+	{
+		name:   "префикс и суффикс",
+		values: func(s *SheetConfig) []any { return []any{s.AffixRules} },
+		copy:   func(dst, src *SheetConfig) { dst.AffixRules = slices.Clone(src.AffixRules) },
+	},
+	{
+		name:   "защита заголовков",
+		values: func(s *SheetConfig) []any { return []any{s.SkipHeaderProtection} },
+		copy:   func(dst, src *SheetConfig) { dst.SkipHeaderProtection = src.SkipHeaderProtection },
+	},
+	{
+		name:   "разделители",
+		values: func(s *SheetConfig) []any { return []any{s.SeparatorMode} },
+		copy:   func(dst, src *SheetConfig) { dst.SeparatorMode = src.SeparatorMode },
+	},
+}
+
+// SheetSettingsChanges возвращает названия настроек листа to, которые CopySheetSettings
+// перезапишет настройками листа from
+func SheetSettingsChanges(from, to *SheetConfig) []string {
+	var changes []string
+	for _, setting := range copiedSheetSettings {
+		if !sameSettingValues(setting.values(from), setting.values(to)) {
+			changes = append(changes, setting.name)
+		}
+	}
+	return changes
+}
+
+// CopySheetSettings копирует настройки листа sheets[from] на листы targets:
+// строку заголовков, фильтр, преобразования столбцов и флаги чтения
+// Имя, включение, цвет ярлыка, лист результата, другие имена листа и фильтрация по артикулам
+// листа "Шаблон" не копируются. Сам лист from и индексы вне sheets пропускаются.
+// Возвращает индексы листов, настройки которых изменились
+func CopySheetSettings(sheets []SheetConfig, from int, targets []int) []int {
+	if from < 0 || from >= len(sheets) {
+		return nil
+	}
+	source := &sheets[from]
+
+	var changed []int
+	for _, i := range targets {
+		if i == from || i < 0 || i >= len(sheets) {
+			continue
+		}
+		sheet := &sheets[i]
+		if len(SheetSettingsChanges(source, sheet)) == 0 {
+			continue
+		}
+		for _, setting := range copiedSheetSettings {
+			setting.copy(sheet, source)
+		}
+		changed = append(changed, i)
+	}
+	return changed
+}
+
+// sameSettingValues сравнивает значения группы настроек; пустые срезы и карты равны nil
+func sameSettingValues(a, b []any) bool {
+	for i := range a {
+		va, vb := reflect.ValueOf(a[i]), reflect.ValueOf(b[i])
+		switch va.Kind() {
+		case reflect.Slice, reflect.Map:
+			if va.Len() == 0 && vb.Len() == 0 {
+				continue
+			}
+		}
+		if !reflect.DeepEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetSheetsHeaderRow устанавливает строку заголовков headerRow на листы targets
+// Заголовки для предпросмотра листов с другой строкой заголовков сбрасываются: они прочитаны
+// из прежней строки. Индексы вне sheets пропускаются. Возвращает индексы изменившихся листов
+func SetSheetsHeaderRow(sheets []SheetConfig, targets []int, headerRow int) []int {
+	var changed []int
+	for _, i := range targets {
+		if i < 0 || i >= len(sheets) || sheets[i].HeaderRow == headerRow {
+			continue
+		}
+		sheets[i].HeaderRow = headerRow
+		sheets[i].Headers = []string{}
+		changed = append(changed, i)
+	}
+	return changed
+}
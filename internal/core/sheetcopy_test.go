@@ -0,0 +1,168 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSheetSettingsChanges(t *testing.T) {
+	source := SheetConfig{
+		SheetName:    "Шаблон",
+		HeaderRow:    4,
+		FilterColumn: 2,
+		FilterValues: []string{"Shuzzi"},
+		AffixRules:   []AffixRule{{Header: "Артикул", Prefix: "SHZ-", SkipIfPresent: true}},
+	}
+
+	tests := []struct {
+		name   string
+		target SheetConfig
+		want   []string
+	}{
+		{
+			name:   "одинаковые настройки",
+			target: SheetConfig{SheetName: "Другой", HeaderRow: 4, FilterColumn: 2, FilterValues: []string{"Shuzzi"}, AffixRules: []AffixRule{{Header: "Артикул", Prefix: "SHZ-", SkipIfPresent: true}}, TabColor: "#FF0000"},
+			want:   nil,
+		},
+		{
+			name:   "пустые срезы и карты равны отсутствующим",
+			target: SheetConfig{HeaderRow: 4, FilterColumn: 2, FilterValues: []string{"Shuzzi"}, AffixRules: []AffixRule{{Header: "Артикул", Prefix: "SHZ-", SkipIfPresent: true}}, ColumnTypes: map[int]string{}, RawTextColumns: []string{}},
+			want:   nil,
+		},
+		{
+			name:   "флаг артикулов шаблона не сравнивается",
+			target: SheetConfig{HeaderRow: 4, FilterColumn: 2, FilterValues: []string{"Shuzzi"}, AffixRules: []AffixRule{{Header: "Артикул", Prefix: "SHZ-", SkipIfPresent: true}}, UseTemplateArticles: true},
+			want:   nil,
+		},
+		{
+			name:   "разные настройки",
+			target: SheetConfig{HeaderRow: 2, FilterColumn: 2, SeparatorMode: SeparatorBlankRow},
+			want:   []string{"строка заголовков", "фильтр", "префикс и суффикс", "разделители"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SheetSettingsChanges(&source, &tt.target)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SheetSettingsChanges() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopySheetSettings(t *testing.T) {
+	sheets := []SheetConfig{
+		{
+			SheetName:       "Шаблон",
+			Enabled:         true,
+			HeaderRow:       4,
+			Headers:         []string{"Артикул", "Цена"},
+			FilterColumn:    1,
+			FilterValues:    []string{"Shuzzi"},
+			ColumnTypes:     map[int]string{1: ColumnTypeNumber},
+			ConstantColumns: []ConstantColumn{{Header: "Поставщик", Value: "Shuzzi"}},
+			DedupKey:        "Артикул",
+			Coalesce:        map[string]string{"Цена": "first"},
+			ValueMaps:       map[int]map[string]string{2: {"да": "Yes"}},
+			RawCellFallback: true,
+			TabColor:        "#FF0000",
+			OutputSheet:     "Итог",
+		},
+		{SheetName: "Озон.Видео", Enabled: false, HeaderRow: 4, Headers: []string{"Ссылка"}, UseTemplateArticles: true, TabColor: "#00FF00"},
+		{SheetName: "Справка", Enabled: true, HeaderRow: 1, Headers: []string{"Текст"}, SourceSheetNames: []string{"Help"}},
+		// Настройки уже совпадают
+		{SheetName: "Копия", HeaderRow: 4, FilterColumn: 1, FilterValues: []string{"Shuzzi"}, ColumnTypes: map[int]string{1: ColumnTypeNumber},
+			ConstantColumns: []ConstantColumn{{Header: "Поставщик", Value: "Shuzzi"}}, DedupKey: "Артикул", Coalesce: map[string]string{"Цена": "first"},
+			ValueMaps: map[int]map[string]string{2: {"да": "Yes"}}, RawCellFallback: true},
+	}
+
+	changed := CopySheetSettings(sheets, 0, []int{0, 1, 3, 5, -1})
+	if !reflect.DeepEqual(changed, []int{1}) {
+		t.Fatalf("CopySheetSettings() = %v, ожидался лист 1", changed)
+	}
+
+	video := sheets[1]
+	if video.HeaderRow != 4 || video.FilterColumn != 1 || video.DedupKey != "Артикул" || !video.RawCellFallback ||
+		video.ValueMaps[2]["да"] != "Yes" || len(video.ConstantColumns) != 1 {
+		t.Errorf("настройки не скопированы: %+v", video)
+	}
+	// Собственные настройки листа не копируются
+	if !video.UseTemplateArticles || video.Enabled || video.TabColor != "#00FF00" || video.OutputSheet != "" || video.SheetName != "Озон.Видео" {
+		t.Errorf("скопированы собственные настройки листа: %+v", video)
+	}
+	// Строка заголовков не изменилась - заголовки сохраняются
+	if len(video.Headers) != 1 {
+		t.Errorf("заголовки = %q, ожидались прежние", video.Headers)
+	}
+	if sheets[2].HeaderRow != 1 {
+		t.Error("изменен лист вне выбранных")
+	}
+
+	// Копии не разделяют срезы и карты с исходным листом
+	sheets[1].FilterValues[0] = "Изменено"
+	sheets[1].ColumnTypes[1] = ColumnTypeText
+	sheets[1].ValueMaps[2]["да"] = "Изменено"
+	if sheets[0].FilterValues[0] != "Shuzzi" || sheets[0].ColumnTypes[1] != ColumnTypeNumber || sheets[0].ValueMaps[2]["да"] != "Yes" {
+		t.Error("изменение копии затронуло исходный лист")
+	}
+
+	// При смене строки заголовков прежние заголовки сбрасываются
+	changed = CopySheetSettings(sheets, 0, []int{2})
+	if !reflect.DeepEqual(changed, []int{2}) || sheets[2].HeaderRow != 4 || len(sheets[2].Headers) != 0 {
+		t.Errorf("лист Справка: изменены %v, %+v", changed, sheets[2])
+	}
+	if !reflect.DeepEqual(sheets[2].SourceSheetNames, []string{"Help"}) {
+		t.Errorf("другие имена листа изменены: %q", sheets[2].SourceSheetNames)
+	}
+
+	if changed := CopySheetSettings(sheets, 10, []int{1}); changed != nil {
+		t.Errorf("копирование с несуществующего листа = %v", changed)
+	}
+}
+
+func TestSetSheetsHeaderRow(t *testing.T) {
+	newSheets := func() []SheetConfig {
+		return []SheetConfig{
+			{SheetName: "A", HeaderRow: 1, Headers: []string{"Артикул"}},
+			{SheetName: "B", HeaderRow: 4, Headers: []string{"Цена"}},
+			{SheetName: "C", HeaderRow: 1, Headers: []string{"Бренд"}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		targets     []int
+		headerRow   int
+		wantChanged []int
+		wantRows    []int
+	}{
+		{"часть листов", []int{0, 2}, 4, []int{0, 2}, []int{4, 4, 4}},
+		{"строка уже задана", []int{1, 2}, 4, []int{2}, []int{1, 4, 4}},
+		{"индексы вне списка", []int{-1, 5, 0}, 2, []int{0}, []int{2, 4, 1}},
+		{"без листов", nil, 3, nil, []int{1, 4, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheets := newSheets()
+			changed := SetSheetsHeaderRow(sheets, tt.targets, tt.headerRow)
+			if !reflect.DeepEqual(changed, tt.wantChanged) {
+				t.Errorf("changed = %v, ожидалось %v", changed, tt.wantChanged)
+			}
+			original := newSheets()
+			for i, sheet := range sheets {
+				if sheet.HeaderRow != tt.wantRows[i] {
+					t.Errorf("лист %s: HeaderRow = %d, ожидалось %d", sheet.SheetName, sheet.HeaderRow, tt.wantRows[i])
+				}
+				// Заголовки сбрасываются только у изменившихся листов
+				wantHeaders := original[i].Headers
+				if sheet.HeaderRow != original[i].HeaderRow {
+					wantHeaders = []string{}
+				}
+				if !reflect.DeepEqual(sheet.Headers, wantHeaders) {
+					t.Errorf("лист %s: Headers = %v, ожидалось %v", sheet.SheetName, sheet.Headers, wantHeaders)
+				}
+			}
+		})
+	}
+}
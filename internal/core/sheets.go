@@ -0,0 +1,115 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// templateSheetName имя листа Ozon с артикулами для фильтрации остальных листов
+const templateSheetName = "Шаблон"
+
+// NormalizeSheetName приводит имя листа к виду для сравнения:
+// без учета регистра, пробелов по краям и количества пробелов внутри
+// Сравнение согласовано с сопоставлением столбцов в MatchColumnsByName
+func NormalizeSheetName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// IsTemplateSheet проверяет, является ли лист листом "Шаблон"
+func IsTemplateSheet(sheetName string) bool {
+	return NormalizeSheetName(sheetName) == NormalizeSheetName(templateSheetName)
+}
+
+// LookupSheetConfig возвращает ключ и конфигурацию листа sheetName из configs
+// Точное совпадение имени имеет приоритет; без учета регистра и пробелов
+// конфигурация находится, только если совпадение единственное
+func LookupSheetConfig[C any](configs map[string]C, sheetName string) (string, C, bool) {
+	if config, ok := configs[sheetName]; ok {
+		return sheetName, config, true
+	}
+
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	name, ok := findSheet(names, sheetName)
+	if !ok {
+		var zero C
+		return "", zero, false
+	}
+	return name, configs[name], true
+}
+
+// ParseSheetNames разбирает список имен листов вида "Прайс; Price; Остатки"
+func ParseSheetNames(spec string) []string {
+	var names []string
+	for _, name := range strings.Split(spec, ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// FormatSheetNames формирует список имен листов для ParseSheetNames
+func FormatSheetNames(names []string) string {
+	return strings.Join(names, "; ")
+}
+
+// sourceSheetNames возвращает имена листа в файлах-источниках в порядке проверки:
+// сначала sheetName, затем альтернативные имена без повторов
+func sourceSheetNames(sheetName string, alternatives []string) []string {
+	names := []string{sheetName}
+	seen := map[string]bool{NormalizeSheetName(sheetName): true}
+	for _, name := range alternatives {
+		key := NormalizeSheetName(name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
+
+// findSourceSheet находит в списке листов книги первый лист из candidates
+// Возвращает имя листа в книге и имя из candidates, по которому он найден
+func findSourceSheet(sheetNames, candidates []string) (string, string, bool) {
+	for _, candidate := range candidates {
+		if name, ok := findSheet(sheetNames, candidate); ok {
+			return name, candidate, true
+		}
+	}
+	return "", "", false
+}
+
+// locateSourceSheet находит в списке листов книги лист sheetName с настройками config:
+// по имени и другим именам листа, затем по шаблону имени SourceSheetPattern.
+// Если шаблону соответствуют несколько листов, используется первый по порядку в книге
+func locateSourceSheet(sheetNames []string, sheetName string, config *SheetConfig) (string, bool) {
+	if name, _, ok := findSourceSheet(sheetNames, sourceSheetNames(sheetName, config.SourceSheetNames)); ok {
+		return name, true
+	}
+	if matches := excel.MatchSheetsByPattern(sheetNames, config.SourceSheetPattern); len(matches) > 0 {
+		return matches[0], true
+	}
+	return "", false
+}
+
+// findSheet находит в списке листов книги лист с именем sheetName
+// Точное совпадение имеет приоритет, затем единственное совпадение без учета регистра и пробелов
+func findSheet(sheetNames []string, sheetName string) (string, bool) {
+	var match string
+	matches := 0
+	for _, name := range sheetNames {
+		if name == sheetName {
+			return name, true
+		}
+		if NormalizeSheetName(name) == NormalizeSheetName(sheetName) {
+			match = name
+			matches++
+		}
+	}
+	return match, matches == 1
+}
@@ -0,0 +1,257 @@
+package core
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// TestLookupSheetConfig тестирует поиск конфигурации листа без учета регистра и пробелов
+func TestLookupSheetConfig(t *testing.T) {
+	configs := map[string]*SheetConfig{
+		"Шаблон":            {SheetName: "Шаблон"},
+		"Озон Одежда":       {SheetName: "Озон Одежда"},
+		"Data":              {SheetName: "Data"},
+		"data ":             {SheetName: "data "},
+		"Размерная  сетка ": {SheetName: "Размерная  сетка "},
+	}
+
+	tests := []struct {
+		name      string
+		sheetName string
+		wantKey   string
+		wantFound bool
+	}{
+		{"точное совпадение", "Шаблон", "Шаблон", true},
+		{"другой регистр и пробел в конце", "шаблон ", "Шаблон", true},
+		{"пробелы внутри и по краям", "  озон   одежда", "Озон Одежда", true},
+		{"лишние пробелы в ключе", "размерная сетка", "Размерная  сетка ", true},
+		{"точное совпадение важнее неоднозначного", "Data", "Data", true},
+		{"неоднозначное совпадение", "DATA", "", false},
+		{"нет совпадения", "Шаблон2", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, config, found := LookupSheetConfig(configs, tt.sheetName)
+			if found != tt.wantFound || key != tt.wantKey {
+				t.Fatalf("LookupSheetConfig(%q) = %q, %v; want %q, %v", tt.sheetName, key, found, tt.wantKey, tt.wantFound)
+			}
+			if found && config != configs[tt.wantKey] {
+				t.Errorf("возвращена конфигурация другого листа: %+v", config)
+			}
+		})
+	}
+}
+
+// TestIsTemplateSheet тестирует распознавание листа "Шаблон"
+func TestIsTemplateSheet(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Шаблон":   true,
+		"шаблон ":  true,
+		" ШАБЛОН":  true,
+		"Шаблон 2": false,
+		"":         false,
+	} {
+		if got := IsTemplateSheet(name); got != want {
+			t.Errorf("IsTemplateSheet(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// TestMergeFilesMatchesSheetNamesLoosely тестирует объединение листов, имена которых
+// в источниках отличаются регистром и пробелами
+func TestMergeFilesMatchesSheetNamesLoosely(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Данные", [][]string{{"Артикул", "Цена"}, {"A1", "100"}})
+	exceltest.WriteWorkbook(t, sourcePath, " данные  ", [][]string{{"Артикул", "Цена"}, {"A2", "200"}})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Данные": {SheetName: "Данные", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	if result.TotalRows != 2 {
+		t.Errorf("TotalRows = %d, ожидалось 2; предупреждения: %v", result.TotalRows, result.Warnings)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("неожиданные предупреждения: %v", result.Warnings)
+	}
+}
+
+// TestParseSheetNames тестирует разбор и форматирование списка имен листов
+func TestParseSheetNames(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"", ""},
+		{"Прайс", "Прайс"},
+		{" Прайс ; Price;;Остатки ", "Прайс; Price; Остатки"},
+		{"Лист 1, копия; Price", "Лист 1, копия; Price"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatSheetNames(ParseSheetNames(tt.spec)); got != tt.want {
+			t.Errorf("ParseSheetNames(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+// TestSourceSheetNames тестирует порядок и удаление повторов в именах листа источника
+func TestSourceSheetNames(t *testing.T) {
+	got := sourceSheetNames("Прайс", []string{"Price", " прайс", "", "Остатки ", "price"})
+	if want := "Прайс|Price|Остатки"; strings.Join(got, "|") != want {
+		t.Errorf("sourceSheetNames() = %q, want %q", got, want)
+	}
+}
+
+// TestMergeFilesUsesSourceSheetNames тестирует объединение листов источников с разными
+// именами в один настроенный лист результата
+func TestMergeFilesUsesSourceSheetNames(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	pricePath := filepath.Join(dir, "price.xlsx")
+	stockPath := filepath.Join(dir, "stock.xlsx")
+	bothPath := filepath.Join(dir, "both.xlsx")
+	otherPath := filepath.Join(dir, "other.xlsx")
+
+	header := []string{"Артикул", "Цена"}
+	exceltest.WriteWorkbook(t, basePath, "Прайс", [][]string{header, {"A1", "100"}})
+	exceltest.WriteWorkbook(t, pricePath, "Price", [][]string{header, {"A2", "200"}})
+	exceltest.WriteWorkbook(t, stockPath, " остатки", [][]string{header, {"A3", "300"}})
+	// Основное имя листа важнее альтернативных
+	writeTestWorkbookSheets(t, bothPath, []testSheet{
+		{"Price", [][]string{header, {"X", "0"}}},
+		{"Прайс", [][]string{header, {"A4", "400"}}},
+	})
+	exceltest.WriteWorkbook(t, otherPath, "Заказы", [][]string{header, {"Z", "0"}})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Прайс": {
+			SheetName:        "Прайс",
+			Enabled:          true,
+			HeaderRow:        1,
+			FilterColumn:     -1,
+			SourceSheetNames: []string{"Price", "Остатки"},
+		},
+	}
+	files := []string{pricePath, stockPath, bothPath, otherPath}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, files, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	if sheets := result.WorkbookData.GetSheetNames(); strings.Join(sheets, ",") != "Прайс" {
+		t.Fatalf("листы результата = %v, ожидался только 'Прайс'", sheets)
+	}
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Прайс")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	var articles []string
+	for _, row := range rows[1:] {
+		articles = append(articles, row[0])
+	}
+	if got := strings.Join(articles, ","); got != "A1,A2,A3,A4" {
+		t.Errorf("артикулы результата = %s, ожидалось A1,A2,A3,A4", got)
+	}
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, "other.xlsx") ||
+		!strings.Contains(result.Warnings[0].Message, "Остатки") || result.Warnings[0].Severity != SeverityWarning {
+		t.Errorf("ожидалось предупреждение об отсутствии листа в other.xlsx, получено %v", result.Warnings)
+	}
+}
+
+// TestMergeFilesUsesSourceSheetPattern тестирует поиск листа источника по части имени
+func TestMergeFilesUsesSourceSheetPattern(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	octoberPath := filepath.Join(dir, "october.xlsx")
+	exactPath := filepath.Join(dir, "exact.xlsx")
+	otherPath := filepath.Join(dir, "other.xlsx")
+
+	header := []string{"Артикул", "Цена"}
+	exceltest.WriteWorkbook(t, basePath, "Прайс", [][]string{header, {"A1", "100"}})
+	// Лист в произвольной позиции с меняющимся именем; подходит первый по порядку
+	writeTestWorkbookSheets(t, octoberPath, []testSheet{
+		{"Инструкция", [][]string{{"Текст"}}},
+		{"ПРАЙС на октябрь", [][]string{header, {"A2", "200"}}},
+		{"Старый прайс", [][]string{header, {"X", "0"}}},
+	})
+	// Точное имя важнее шаблона
+	writeTestWorkbookSheets(t, exactPath, []testSheet{
+		{"Прайс архив", [][]string{header, {"X", "0"}}},
+		{"Прайс", [][]string{header, {"A3", "300"}}},
+	})
+	exceltest.WriteWorkbook(t, otherPath, "Заказы", [][]string{header, {"Z", "0"}})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Прайс": {
+			SheetName:          "Прайс",
+			Enabled:            true,
+			HeaderRow:          1,
+			FilterColumn:       -1,
+			SourceSheetPattern: "прайс",
+		},
+	}
+	files := []string{octoberPath, exactPath, otherPath}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, files, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	rows, err := result.WorkbookData.GetFile().GetRows("Прайс")
+	if err != nil {
+		t.Fatalf("не удалось прочитать результат: %v", err)
+	}
+	var articles []string
+	for _, row := range rows[1:] {
+		articles = append(articles, row[0])
+	}
+	if got := strings.Join(articles, ","); got != "A1,A2,A3" {
+		t.Errorf("артикулы результата = %s, ожидалось A1,A2,A3", got)
+	}
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, "other.xlsx") ||
+		!strings.Contains(result.Warnings[0].Message, "'прайс' в имени") {
+		t.Errorf("ожидалось предупреждение об отсутствии листа в other.xlsx, получено %v", result.Warnings)
+	}
+}
+
+// TestLocateSourceSheet тестирует порядок поиска листа источника: имена, затем шаблон
+func TestLocateSourceSheet(t *testing.T) {
+	sheetNames := []string{"Инструкция", "Прайс поставщика", "Price"}
+	tests := []struct {
+		name   string
+		config SheetConfig
+		want   string
+	}{
+		{"по шаблону", SheetConfig{SourceSheetPattern: "прайс"}, "Прайс поставщика"},
+		{"другое имя важнее шаблона", SheetConfig{SourceSheetNames: []string{"price"}, SourceSheetPattern: "прайс"}, "Price"},
+		{"без шаблона", SheetConfig{}, ""},
+		{"шаблон не найден", SheetConfig{SourceSheetPattern: "остатки"}, ""},
+	}
+	for _, tt := range tests {
+		got, ok := locateSourceSheet(sheetNames, "Цены", &tt.config)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("%s: locateSourceSheet() = %q, %v, ожидалось %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// Ожидаемые типы данных столбцов
+const (
+	ColumnTypeNumber = "number" // Число, допускаются пробелы-разделители разрядов и десятичная запятая
+	ColumnTypeDate   = "date"   // Дата в распространенном формате или порядковый номер даты Excel
+	ColumnTypeText   = "text"   // Любое значение
+)
+
+// maxViolationSamples количество примеров ячеек в предупреждении о несоответствии типа
+const maxViolationSamples = 3
+
+// dateLayouts форматы дат, которые считаются корректными
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"02.01.2006",
+	"02.01.2006 15:04",
+	"02.01.2006 15:04:05",
+	"2.1.2006",
+	"02.01.06",
+	"01-02-06",
+	"1/2/06",
+	"1/2/2006",
+	"1/2/06 15:04",
+	time.RFC3339,
+}
+
+// IsValidColumnType проверяет, что тип столбца поддерживается
+func IsValidColumnType(columnType string) bool {
+	switch columnType {
+	case ColumnTypeNumber, ColumnTypeDate, ColumnTypeText:
+		return true
+	default:
+		return false
+	}
+}
+
+// matchesColumnType проверяет соответствие значения ячейки типу столбца
+// Пустые ячейки соответствуют любому типу
+func matchesColumnType(value, columnType string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return true
+	}
+
+	switch columnType {
+	case ColumnTypeNumber:
+		return isNumber(value)
+	case ColumnTypeDate:
+		// Ячейка даты без числового формата читается как порядковый номер
+		return isNumber(value) || isDateText(value)
+	default:
+		return true
+	}
+}
+
+// isNumber проверяет, является ли значение числом
+func isNumber(value string) bool {
+	_, ok := excel.ParseNumber(value)
+	return ok
+}
+
+// isDateText проверяет, что значение записано в одном из форматов дат dateLayouts
+func isDateText(value string) bool {
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// columnValidator подсчитывает ячейки, не соответствующие ожидаемым типам столбцов
+type columnValidator struct {
+	types      map[int]string
+	violations map[int]int
+	samples    map[int][]string
+}
+
+// newColumnValidator создает валидатор; возвращает nil, если типы не заданы
+func newColumnValidator(types map[int]string) *columnValidator {
+	if len(types) == 0 {
+		return nil
+	}
+
+	return &columnValidator{
+		types:      types,
+		violations: make(map[int]int),
+		samples:    make(map[int][]string),
+	}
+}
+
+// check проверяет строки данных, первая из которых записывается в строку firstRow листа
+func (v *columnValidator) check(rows [][]string, firstRow int) {
+	if v == nil {
+		return
+	}
+
+	for i, row := range rows {
+		for col, columnType := range v.types {
+			if col < 0 || col >= len(row) || matchesColumnType(row[col], columnType) {
+				continue
+			}
+
+			v.violations[col]++
+			if len(v.samples[col]) < maxViolationSamples {
+				ref := fmt.Sprintf("%s%d", ColumnIndexToLetter(col), firstRow+i)
+				v.samples[col] = append(v.samples[col], fmt.Sprintf("%s=%q", ref, row[col]))
+			}
+		}
+	}
+}
+
+// warnings формирует предупреждения о несоответствиях по столбцам листа
+func (v *columnValidator) warnings(sheetName string, headers []string) []Warning {
+	if v == nil {
+		return nil
+	}
+
+	columns := make([]int, 0, len(v.types))
+	for col := range v.types {
+		columns = append(columns, col)
+	}
+	sort.Ints(columns)
+
+	var warnings []Warning
+	for _, col := range columns {
+		columnType := v.types[col]
+		if !IsValidColumnType(columnType) {
+			warnings = append(warnings, newWarning(SeverityInfo, "лист '%s', столбец %s: неизвестный тип '%s', проверка пропущена",
+				sheetName, ColumnIndexToLetter(col), columnType))
+			continue
+		}
+
+		count := v.violations[col]
+		if count == 0 {
+			continue
+		}
+
+		column := ColumnIndexToLetter(col)
+		if col < len(headers) && strings.TrimSpace(headers[col]) != "" {
+			column = fmt.Sprintf("%s '%s'", column, strings.TrimSpace(headers[col]))
+		}
+		warnings = append(warnings, newWarning(SeverityWarning, "лист '%s', столбец %s: %d значений не соответствуют типу %s, например: %s",
+			sheetName, column, count, columnType, strings.Join(v.samples[col], ", ")))
+	}
+
+	return warnings
+}
+
+// descriptorKeywords ключевые слова описания поля в шаблоне и соответствующие типы
+// Проверяются по порядку: "Дата и время" - дата, а не число
+var descriptorKeywords = []struct {
+	keyword    string
+	columnType string
+}{
+	{"дата", ColumnTypeDate},
+	{"date", ColumnTypeDate},
+	{"числ", ColumnTypeNumber}, // число, числовое
+	{"number", ColumnTypeNumber},
+	{"integer", ColumnTypeNumber},
+	{"decimal", ColumnTypeNumber},
+	{"строка", ColumnTypeText},
+	{"текст", ColumnTypeText},
+	{"string", ColumnTypeText},
+	{"text", ColumnTypeText},
+}
+
+// ParseTypeDescriptorRow разбирает строку описания полей шаблона (например, Ozon)
+// в типы столбцов по 0-based индексу: "Целое число" - number, "Дата" - date,
+// "Строка" - text. Ячейки без распознанного типа пропускаются
+func ParseTypeDescriptorRow(row []string) map[int]string {
+	types := make(map[int]string)
+	for col, description := range row {
+		description = strings.ToLower(description)
+		for _, k := range descriptorKeywords {
+			if strings.Contains(description, k.keyword) {
+				types[col] = k.columnType
+				break
+			}
+		}
+	}
+
+	if len(types) == 0 {
+		return nil
+	}
+	return types
+}
+
+// resolveColumnTypes объединяет типы из строки описания полей с явно заданными типами
+// Явно заданный тип столбца важнее типа из описания
+func resolveColumnTypes(config *SheetConfig, baseRows [][]string) map[int]string {
+	var hints map[int]string
+	if row := config.TypeDescriptorRow; row > 0 && row <= len(baseRows) {
+		hints = ParseTypeDescriptorRow(baseRows[row-1])
+	}
+	if len(hints) == 0 {
+		return config.ColumnTypes
+	}
+
+	types := make(map[int]string, len(hints)+len(config.ColumnTypes))
+	for col, columnType := range hints {
+		types[col] = columnType
+	}
+	for col, columnType := range config.ColumnTypes {
+		types[col] = columnType
+	}
+	return types
+}
+
+// numberColumns возвращает отсортированные индексы столбцов типа number
+func numberColumns(types map[int]string) []int {
+	var columns []int
+	for col, columnType := range types {
+		if columnType == ColumnTypeNumber {
+			columns = append(columns, col)
+		}
+	}
+	sort.Ints(columns)
+	return columns
+}
+
+// ParseColumnTypes разбирает описание типов столбцов вида "B:number, D:date"
+func ParseColumnTypes(spec string) (map[int]string, error) {
+	types := make(map[int]string)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		letter, columnType, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("некорректное описание типа столбца '%s', ожидается вид B:number", part)
+		}
+
+		col, err := columnLetterToIndex(strings.TrimSpace(letter))
+		if err != nil {
+			return nil, err
+		}
+
+		columnType = strings.ToLower(strings.TrimSpace(columnType))
+		if !IsValidColumnType(columnType) {
+			return nil, fmt.Errorf("неизвестный тип столбца '%s', допустимы: number, date, text", columnType)
+		}
+		types[col] = columnType
+	}
+
+	if len(types) == 0 {
+		return nil, nil
+	}
+	return types, nil
+}
+
+// FormatColumnTypes формирует описание типов столбцов для ParseColumnTypes
+func FormatColumnTypes(types map[int]string) string {
+	columns := make([]int, 0, len(types))
+	for col := range types {
+		columns = append(columns, col)
+	}
+	sort.Ints(columns)
+
+	parts := make([]string, 0, len(columns))
+	for _, col := range columns {
+		parts = append(parts, fmt.Sprintf("%s:%s", ColumnIndexToLetter(col), types[col]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// columnLetterToIndex преобразует букву столбца Excel в 0-based индекс (A -> 0, AA -> 26)
+func columnLetterToIndex(letter string) (int, error) {
+	letter = strings.ToUpper(letter)
+	if letter == "" || len(letter) > 3 {
+		return 0, fmt.Errorf("некорректный столбец '%s'", letter)
+	}
+
+	index := 0
+	for _, r := range letter {
+		if r < 'A' || r > 'Z' {
+			return 0, fmt.Errorf("некорректный столбец '%s'", letter)
+		}
+		index = index*26 + int(r-'A'+1)
+	}
+	return index - 1, nil
+}
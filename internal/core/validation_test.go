@@ -0,0 +1,185 @@
+package core
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+func TestMatchesColumnType(t *testing.T) {
+	tests := []struct {
+		value      string
+		columnType string
+		want       bool
+	}{
+		{"100", ColumnTypeNumber, true},
+		{"1 234,50", ColumnTypeNumber, true},
+		{"-0.5", ColumnTypeNumber, true},
+		{"", ColumnTypeNumber, true},
+		{"бесплатно", ColumnTypeNumber, false},
+		{"12 руб", ColumnTypeNumber, false},
+		{"2025-11-04", ColumnTypeDate, true},
+		{"04.11.2025", ColumnTypeDate, true},
+		{"11-04-25", ColumnTypeDate, true},
+		{"45965", ColumnTypeDate, true},
+		{"31.13.2025", ColumnTypeDate, false},
+		{"завтра", ColumnTypeDate, false},
+		{"что угодно", ColumnTypeText, true},
+	}
+
+	for _, tt := range tests {
+		if got := matchesColumnType(tt.value, tt.columnType); got != tt.want {
+			t.Errorf("matchesColumnType(%q, %s) = %v, ожидалось %v", tt.value, tt.columnType, got, tt.want)
+		}
+	}
+}
+
+func TestParseColumnTypes(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    map[int]string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"B:number, d:Date", map[int]string{1: ColumnTypeNumber, 3: ColumnTypeDate}, false},
+		{"AA:text", map[int]string{26: ColumnTypeText}, false},
+		{"B", nil, true},
+		{"B:money", nil, true},
+		{"1:number", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseColumnTypes(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseColumnTypes(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseColumnTypes(%q) = %v, ожидалось %v", tt.spec, got, tt.want)
+		}
+		if err == nil && tt.want != nil {
+			if back, _ := ParseColumnTypes(FormatColumnTypes(got)); !reflect.DeepEqual(back, got) {
+				t.Errorf("FormatColumnTypes(%v) не разбирается обратно: %v", got, back)
+			}
+		}
+	}
+}
+
+// TestParseTypeDescriptorRow тестирует разбор строки описания полей в типы столбцов
+func TestParseTypeDescriptorRow(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []string
+		want map[int]string
+	}{
+		{"пустая строка", nil, nil},
+		{"без типов", []string{"Обязательное поле", ""}, nil},
+		{
+			"описание Ozon",
+			[]string{"Строка", "Десятичное число", "Целое число", "Дата", "Дата и время", "Список"},
+			map[int]string{0: ColumnTypeText, 1: ColumnTypeNumber, 2: ColumnTypeNumber, 3: ColumnTypeDate, 4: ColumnTypeDate},
+		},
+		{"английские названия", []string{"", "Decimal", "DATE", "Text"}, map[int]string{1: ColumnTypeNumber, 2: ColumnTypeDate, 3: ColumnTypeText}},
+		{"числовое значение", []string{"Числовое значение, руб."}, map[int]string{0: ColumnTypeNumber}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseTypeDescriptorRow(tt.row); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTypeDescriptorRow(%q) = %v, ожидалось %v", tt.row, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestResolveColumnTypes тестирует приоритет явно заданных типов над строкой описания
+func TestResolveColumnTypes(t *testing.T) {
+	baseRows := [][]string{
+		{"Строка", "Десятичное число", "Целое число"},
+		{"Артикул", "Цена", "Остаток"},
+	}
+	config := &SheetConfig{
+		HeaderRow:         2,
+		TypeDescriptorRow: 1,
+		ColumnTypes:       map[int]string{2: ColumnTypeText, 3: ColumnTypeDate},
+	}
+
+	want := map[int]string{0: ColumnTypeText, 1: ColumnTypeNumber, 2: ColumnTypeText, 3: ColumnTypeDate}
+	if got := resolveColumnTypes(config, baseRows); !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveColumnTypes() = %v, ожидалось %v", got, want)
+	}
+
+	// Без строки описания используются только явно заданные типы
+	config.TypeDescriptorRow = 0
+	if got := resolveColumnTypes(config, baseRows); !reflect.DeepEqual(got, config.ColumnTypes) {
+		t.Errorf("resolveColumnTypes() = %v, ожидалось %v", got, config.ColumnTypes)
+	}
+}
+
+// TestMergeFilesUsesTypeDescriptorRow тестирует запись числовых столбцов из строки описания числами
+func TestMergeFilesUsesTypeDescriptorRow(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	sourcePath := filepath.Join(dir, "source.xlsx")
+	descriptor := []string{"Строка", "Десятичное число"}
+	header := []string{"Артикул", "Цена"}
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{descriptor, header, {"00123", "1 234,5"}})
+	exceltest.WriteWorkbook(t, sourcePath, "Data", [][]string{descriptor, header, {"00124", "99"}, {"00125", "по запросу"}})
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 2, FilterColumn: -1, TypeDescriptorRow: 1},
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{sourcePath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	f := result.WorkbookData.GetFile()
+	tests := []struct {
+		cell       string
+		wantValue  string
+		wantNumber bool
+	}{
+		{"B1", "Десятичное число", false},
+		{"B2", "Цена", false},
+		{"A3", "00123", false},
+		{"B3", "1234.5", true},
+		{"B4", "99", true},
+		{"B5", "по запросу", false},
+	}
+	for _, tt := range tests {
+		value, err := f.GetCellValue("Data", tt.cell, excelize.Options{RawCellValue: true})
+		if err != nil {
+			t.Fatalf("не удалось прочитать %s: %v", tt.cell, err)
+		}
+		cellType, err := f.GetCellType("Data", tt.cell)
+		if err != nil {
+			t.Fatalf("не удалось прочитать тип %s: %v", tt.cell, err)
+		}
+		isNumber := cellType == excelize.CellTypeUnset || cellType == excelize.CellTypeNumber
+		if value != tt.wantValue || isNumber != tt.wantNumber {
+			t.Errorf("%s = %q (число: %v), ожидалось %q (число: %v)", tt.cell, value, isNumber, tt.wantValue, tt.wantNumber)
+		}
+	}
+
+	// Нечисловое значение в числовом столбце попадает в предупреждение о типах
+	var typeWarning bool
+	for _, warning := range result.Warnings {
+		if warning.Severity == SeverityWarning &&
+			strings.Contains(warning.Message, "B 'Цена'") && strings.Contains(warning.Message, "по запросу") {
+			typeWarning = true
+		}
+	}
+	if !typeWarning {
+		t.Errorf("ожидалось предупреждение о типе столбца B, получено %v", result.Warnings)
+	}
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity важность предупреждения объединения
+type Severity int
+
+const (
+	SeverityInfo    Severity = iota // Сведения: данные сохранены полностью
+	SeverityWarning                 // Предупреждение: часть данных потеряна или требует проверки
+	SeverityError                   // Ошибка, пониженная до предупреждения: файл или лист пропущен целиком
+)
+
+// String возвращает имя уровня важности: info, warning или error
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// ParseSeverity разбирает имя уровня важности info, warning или error без учета регистра
+func ParseSeverity(name string) (Severity, error) {
+	for _, severity := range []Severity{SeverityInfo, SeverityWarning, SeverityError} {
+		if strings.EqualFold(strings.TrimSpace(name), severity.String()) {
+			return severity, nil
+		}
+	}
+	return 0, fmt.Errorf("неизвестный уровень важности '%s' (допустимо: info, warning, error)", name)
+}
+
+// Warning предупреждение, выданное при объединении
+type Warning struct {
+	Severity Severity
+	Message  string
+	Samples  []ExcludedSample // Примеры строк, исключенных фильтром (только для отчета о фильтрации)
+}
+
+// String возвращает текст предупреждения
+func (w Warning) String() string {
+	return w.Message
+}
+
+// newWarning создает предупреждение с текстом по формату
+func newWarning(severity Severity, format string, args ...any) Warning {
+	return Warning{Severity: severity, Message: fmt.Sprintf(format, args...)}
+}
+
+// countWarnings возвращает количество предупреждений каждого уровня важности
+func countWarnings(warnings []Warning) map[Severity]int {
+	counts := make(map[Severity]int)
+	for _, warning := range warnings {
+		counts[warning.Severity]++
+	}
+	return counts
+}
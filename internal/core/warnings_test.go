@@ -0,0 +1,131 @@
+package core
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel"
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// TestSeverityString тестирует имена уровней важности
+func TestSeverityString(t *testing.T) {
+	for severity, want := range map[Severity]string{
+		SeverityInfo:    "info",
+		SeverityWarning: "warning",
+		SeverityError:   "error",
+		Severity(7):     "severity(7)",
+	} {
+		if got := severity.String(); got != want {
+			t.Errorf("Severity(%d).String() = %q, want %q", int(severity), got, want)
+		}
+	}
+}
+
+// TestParseSeverity тестирует разбор имен уровней важности
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Severity
+		wantErr bool
+	}{
+		{"info", SeverityInfo, false},
+		{" Warning ", SeverityWarning, false},
+		{"ERROR", SeverityError, false},
+		{"critical", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSeverity(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSeverity(%q) = %v, %v, ожидалось %v, ошибка %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestWarningSeverities тестирует уровни важности мест выдачи предупреждений,
+// которые не покрыты тестами объединения
+func TestWarningSeverities(t *testing.T) {
+	validator := newColumnValidator(map[int]string{0: "number", 1: "color"})
+	validator.check([][]string{{"abc"}}, 2)
+
+	split := rowLimitWarning("Data", []string{"Data_2"}, excel.MaxExcelRows+1)
+	nearLimit := rowLimitWarning("Data", nil, rowLimitWarningThreshold)
+
+	tests := []struct {
+		name    string
+		warning *Warning
+		want    Severity
+		text    string
+	}{
+		{"данные разбиты на листы", split, SeverityInfo, "Data, Data_2"},
+		{"приближение к лимиту строк", nearLimit, SeverityInfo, "приближается к лимиту"},
+		{"нарушение типа столбца", &validator.warnings("Data", nil)[0], SeverityWarning, "не соответствуют типу"},
+		{"неизвестный тип столбца", &validator.warnings("Data", nil)[1], SeverityInfo, "неизвестный тип"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.warning == nil {
+				t.Fatal("предупреждение не выдано")
+			}
+			if tt.warning.Severity != tt.want {
+				t.Errorf("уровень = %s, ожидался %s", tt.warning.Severity, tt.want)
+			}
+			if !strings.Contains(tt.warning.Message, tt.text) {
+				t.Errorf("предупреждение %q не содержит %q", tt.warning.Message, tt.text)
+			}
+		})
+	}
+
+	if warning := rowLimitWarning("Data", nil, rowLimitWarningThreshold-1); warning != nil {
+		t.Errorf("неожиданное предупреждение: %v", warning)
+	}
+}
+
+// TestMergeFilesCountsWarningsBySeverity тестирует уровни предупреждений о файлах-источниках
+// и их подсчет в результате
+func TestMergeFilesCountsWarningsBySeverity(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	otherPath := filepath.Join(dir, "other.xlsx")
+	brokenPath := filepath.Join(dir, "broken.xlsx")
+	exceltest.WriteWorkbook(t, basePath, "Data", [][]string{{"Артикул", "Цена"}, {"A1", "100"}})
+	exceltest.WriteWorkbook(t, otherPath, "Заказы", [][]string{{"Артикул", "Цена"}, {"A2", "200"}})
+	if err := os.WriteFile(brokenPath, []byte("not an xlsx file"), 0644); err != nil {
+		t.Fatalf("не удалось создать поврежденный файл: %v", err)
+	}
+
+	sheetConfigs := map[string]*SheetConfig{
+		"Data": {SheetName: "Data", Enabled: true, HeaderRow: 1, FilterColumn: -1},
+	}
+	result, err := NewMerger(nil, logger).MergeFiles(basePath, []string{otherPath, brokenPath}, sheetConfigs)
+	if err != nil {
+		t.Fatalf("ошибка при объединении файлов: %v", err)
+	}
+	defer result.Close()
+
+	want := map[string]Severity{
+		"other.xlsx":  SeverityWarning, // лист не найден
+		"broken.xlsx": SeverityError,   // файл не открывается
+	}
+	if len(result.Warnings) != len(want) {
+		t.Fatalf("предупреждения: %v", result.Warnings)
+	}
+	for _, warning := range result.Warnings {
+		for file, severity := range want {
+			if strings.Contains(warning.Message, file) && warning.Severity != severity {
+				t.Errorf("уровень %q = %s, ожидался %s", warning.Message, warning.Severity, severity)
+			}
+		}
+	}
+
+	if result.WarningCounts[SeverityWarning] != 1 || result.WarningCounts[SeverityError] != 1 ||
+		result.WarningCounts[SeverityInfo] != 0 {
+		t.Errorf("WarningCounts = %v", result.WarningCounts)
+	}
+}
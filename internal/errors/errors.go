@@ -1,20 +1,53 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
 
 // Коды ошибок
 const (
-	ErrCodeFileNotFound     = "E001"
-	ErrCodeFileReadError    = "E002"
-	ErrCodeSheetNotFound    = "E003"
-	ErrCodeInvalidHeaderRow = "E004"
-	ErrCodeEmptyFile        = "E005"
-	ErrCodeInvalidFormat    = "E006"
-	ErrCodePermissionDenied = "E007"
-	ErrCodeFileCorrupted    = "E008"
-	ErrCodeConfigError      = "E009"
-	ErrCodeMergeError       = "E010"
-	ErrCodeSaveError        = "E011"
+	ErrCodeFileNotFound      = "E001"
+	ErrCodeFileReadError     = "E002"
+	ErrCodeSheetNotFound     = "E003"
+	ErrCodeInvalidHeaderRow  = "E004"
+	ErrCodeEmptyFile         = "E005"
+	ErrCodeInvalidFormat     = "E006"
+	ErrCodePermissionDenied  = "E007"
+	ErrCodeFileCorrupted     = "E008"
+	ErrCodeConfigError       = "E009"
+	ErrCodeMergeError        = "E010"
+	ErrCodeSaveError         = "E011"
+	ErrCodeRowLimitExceeded  = "E012"
+	ErrCodeStructureMismatch = "E013"
+	ErrCodeNoMatchingColumns = "E014"
+	ErrCodeCancelled         = "E015"
+	ErrCodeInternal          = "E016"
+	ErrCodeFileTooLarge      = "E017"
+)
+
+// Значения для проверки через errors.Is: ошибка приложения совпадает
+// со значением, если у них одинаковый код
+var (
+	ErrFileNotFound      = &AppError{Code: ErrCodeFileNotFound, Message: "Файл не найден"}
+	ErrFileRead          = &AppError{Code: ErrCodeFileReadError, Message: "Ошибка при чтении файла"}
+	ErrSheetNotFound     = &AppError{Code: ErrCodeSheetNotFound, Message: "Лист не найден"}
+	ErrInvalidHeaderRow  = &AppError{Code: ErrCodeInvalidHeaderRow, Message: "Неверный номер строки заголовков"}
+	ErrEmptyFile         = &AppError{Code: ErrCodeEmptyFile, Message: "Файл пустой"}
+	ErrInvalidFormat     = &AppError{Code: ErrCodeInvalidFormat, Message: "Неверный формат файла"}
+	ErrPermissionDenied  = &AppError{Code: ErrCodePermissionDenied, Message: "Нет доступа к файлу"}
+	ErrFileCorrupted     = &AppError{Code: ErrCodeFileCorrupted, Message: "Файл поврежден"}
+	ErrConfig            = &AppError{Code: ErrCodeConfigError, Message: "Ошибка конфигурации"}
+	ErrMerge             = &AppError{Code: ErrCodeMergeError, Message: "Ошибка объединения"}
+	ErrSave              = &AppError{Code: ErrCodeSaveError, Message: "Не удалось сохранить файл"}
+	ErrRowLimitExceeded  = &AppError{Code: ErrCodeRowLimitExceeded, Message: "Превышен лимит строк Excel"}
+	ErrStructureMismatch = &AppError{Code: ErrCodeStructureMismatch, Message: "Структура листа не совпадает"}
+	ErrNoMatchingColumns = &AppError{Code: ErrCodeNoMatchingColumns, Message: "Нет совпадающих столбцов"}
+	ErrCancelled         = &AppError{Code: ErrCodeCancelled, Message: "Операция отменена пользователем"}
+	ErrInternal          = &AppError{Code: ErrCodeInternal, Message: "Внутренняя ошибка приложения"}
+	ErrFileTooLarge      = &AppError{Code: ErrCodeFileTooLarge, Message: "Файл слишком большой"}
 )
 
 // AppError представляет ошибку приложения с кодом и контекстом
@@ -36,137 +69,200 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
-// Конструкторы ошибок
+// Is сообщает, совпадает ли код ошибки с кодом target
+// Позволяет проверять ошибки через errors.Is(err, ErrSheetNotFound)
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
 
-// NewFileNotFoundError создает ошибку "файл не найден"
-func NewFileNotFoundError(path string) *AppError {
-	return &AppError{
-		Code:    ErrCodeFileNotFound,
-		Message: "Файл не найден",
-		Context: map[string]interface{}{"path": path},
+// LogValue представляет ошибку в журнале группой с кодом, сообщением, контекстом и причиной
+func (e *AppError) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("code", e.Code),
+		slog.String("message", e.Message),
+	}
+
+	if len(e.Context) > 0 {
+		keys := make([]string, 0, len(e.Context))
+		for key := range e.Context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		context := make([]slog.Attr, 0, len(keys))
+		for _, key := range keys {
+			context = append(context, slog.Any(key, e.Context[key]))
+		}
+		attrs = append(attrs, slog.Attr{Key: "context", Value: slog.GroupValue(context...)})
+	}
+
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("cause", e.Err.Error()))
 	}
+	return slog.GroupValue(attrs...)
 }
 
-// NewSheetNotFoundError создает ошибку "лист не найден"
-func NewSheetNotFoundError(sheet, file string) *AppError {
-	return &AppError{
-		Code:    ErrCodeSheetNotFound,
-		Message: fmt.Sprintf("Лист '%s' не найден в файле", sheet),
-		Context: map[string]interface{}{"sheet": sheet, "file": file},
+// Option дополнительный параметр ошибки приложения
+type Option func(*AppError)
+
+// WithCause задает исходную ошибку, доступную через errors.Unwrap
+func WithCause(err error) Option {
+	return func(e *AppError) {
+		e.Err = err
 	}
 }
 
-// NewInvalidHeaderRowError создает ошибку "неверный номер строки заголовков"
-func NewInvalidHeaderRowError(row int) *AppError {
-	return &AppError{
-		Code:    ErrCodeInvalidHeaderRow,
-		Message: fmt.Sprintf("Неверный номер строки заголовков: %d", row),
-		Context: map[string]interface{}{"row": row},
+// WithContext добавляет значение в контекст ошибки
+func WithContext(key string, value interface{}) Option {
+	return func(e *AppError) {
+		if e.Context == nil {
+			e.Context = make(map[string]interface{})
+		}
+		e.Context[key] = value
 	}
 }
 
-// NewFileReadError создает ошибку чтения файла
-func NewFileReadError(path string, err error) *AppError {
-	return &AppError{
-		Code:    ErrCodeFileReadError,
-		Message: "Ошибка при чтении файла",
-		Context: map[string]interface{}{"path": path},
+// newAppError создает ошибку и применяет к ней параметры opts
+func newAppError(code, message string, context map[string]interface{}, err error, opts []Option) *AppError {
+	e := &AppError{
+		Code:    code,
+		Message: message,
+		Context: context,
 		Err:     err,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Конструкторы ошибок
+
+// NewFileNotFoundError создает ошибку "файл не найден"
+func NewFileNotFoundError(path string, opts ...Option) *AppError {
+	return newAppError(ErrCodeFileNotFound, "Файл не найден",
+		map[string]interface{}{"path": path}, nil, opts)
+}
+
+// NewSheetNotFoundError создает ошибку "лист не найден"
+func NewSheetNotFoundError(sheet, file string, opts ...Option) *AppError {
+	return newAppError(ErrCodeSheetNotFound, fmt.Sprintf("Лист '%s' не найден в файле", sheet),
+		map[string]interface{}{"sheet": sheet, "file": file}, nil, opts)
+}
+
+// NewSheetsNotFoundError создает ошибку "листы не найдены" со списком всех отсутствующих листов
+func NewSheetsNotFoundError(sheets []string, file string, opts ...Option) *AppError {
+	return newAppError(ErrCodeSheetNotFound,
+		fmt.Sprintf("В файле %s нет листов: '%s'", file, strings.Join(sheets, "', '")),
+		map[string]interface{}{"sheets": sheets, "file": file}, nil, opts)
+}
+
+// NewInvalidHeaderRowError создает ошибку "неверный номер строки заголовков"
+func NewInvalidHeaderRowError(row int, opts ...Option) *AppError {
+	return newAppError(ErrCodeInvalidHeaderRow, fmt.Sprintf("Неверный номер строки заголовков: %d", row),
+		map[string]interface{}{"row": row}, nil, opts)
+}
+
+// NewInvalidHeaderRowInputError создает ошибку "неверный номер строки заголовков"
+// для введенного текста input, который не является числом
+func NewInvalidHeaderRowInputError(input string, opts ...Option) *AppError {
+	return newAppError(ErrCodeInvalidHeaderRow, fmt.Sprintf("Неверный номер строки заголовков: '%s'", input),
+		map[string]interface{}{"input": input}, nil, opts)
+}
+
+// NewFileReadError создает ошибку чтения файла
+func NewFileReadError(path string, err error, opts ...Option) *AppError {
+	return newAppError(ErrCodeFileReadError, "Ошибка при чтении файла",
+		map[string]interface{}{"path": path}, err, opts)
 }
 
 // NewEmptyFileError создает ошибку "файл пустой"
-func NewEmptyFileError(path string) *AppError {
-	return &AppError{
-		Code:    ErrCodeEmptyFile,
-		Message: "Файл пустой или не содержит данных",
-		Context: map[string]interface{}{"path": path},
-	}
+func NewEmptyFileError(path string, opts ...Option) *AppError {
+	return newAppError(ErrCodeEmptyFile, "Файл пустой или не содержит данных",
+		map[string]interface{}{"path": path}, nil, opts)
 }
 
 // NewInvalidFormatError создает ошибку "неверный формат файла"
-func NewInvalidFormatError(path string) *AppError {
-	return &AppError{
-		Code:    ErrCodeInvalidFormat,
-		Message: "Неверный формат файла. Поддерживаются только .xlsx файлы",
-		Context: map[string]interface{}{"path": path},
-	}
+func NewInvalidFormatError(path string, opts ...Option) *AppError {
+	return newAppError(ErrCodeInvalidFormat, "Неверный формат файла. Поддерживаются только .xlsx файлы",
+		map[string]interface{}{"path": path}, nil, opts)
 }
 
 // NewPermissionDeniedError создает ошибку "нет доступа"
-func NewPermissionDeniedError(path string) *AppError {
-	return &AppError{
-		Code:    ErrCodePermissionDenied,
-		Message: "Нет доступа к файлу",
-		Context: map[string]interface{}{"path": path},
-	}
+func NewPermissionDeniedError(path string, opts ...Option) *AppError {
+	return newAppError(ErrCodePermissionDenied, "Нет доступа к файлу",
+		map[string]interface{}{"path": path}, nil, opts)
 }
 
 // NewFileCorruptedError создает ошибку "файл поврежден"
-func NewFileCorruptedError(path string, err error) *AppError {
-	return &AppError{
-		Code:    ErrCodeFileCorrupted,
-		Message: "Файл поврежден и не может быть прочитан",
-		Context: map[string]interface{}{"path": path},
-		Err:     err,
-	}
+func NewFileCorruptedError(path string, err error, opts ...Option) *AppError {
+	return newAppError(ErrCodeFileCorrupted, "Файл поврежден и не может быть прочитан",
+		map[string]interface{}{"path": path}, err, opts)
 }
 
-// NewConfigError создает ошибку конфигурации (с одним параметром для совместимости)
-func NewConfigError(message string) *AppError {
-	return &AppError{
-		Code:    ErrCodeConfigError,
-		Message: message,
-	}
+// NewFileTooLargeError создает ошибку "файл слишком большой": распакованная книга
+// превышает предел limit байт, при котором ее еще можно открыть
+func NewFileTooLargeError(path string, limit int64, err error, opts ...Option) *AppError {
+	return newAppError(ErrCodeFileTooLarge,
+		fmt.Sprintf("Файл слишком большой: после распаковки он занимает больше %d МБ", limit>>20),
+		map[string]interface{}{"path": path, "unzip_limit": limit}, err, opts)
+}
+
+// NewConfigError создает ошибку конфигурации
+// Причину и контекст можно передать через WithCause и WithContext
+func NewConfigError(message string, opts ...Option) *AppError {
+	return newAppError(ErrCodeConfigError, message, nil, nil, opts)
 }
 
 // NewConfigErrorWithCause создает ошибку конфигурации с причиной
+//
+// Deprecated: используйте NewConfigError(message, WithCause(err))
 func NewConfigErrorWithCause(message string, err error) *AppError {
-	return &AppError{
-		Code:    ErrCodeConfigError,
-		Message: message,
-		Err:     err,
-	}
+	return NewConfigError(message, WithCause(err))
 }
 
 // NewMergeError создает ошибку объединения
-func NewMergeError(message string, err error) *AppError {
-	return &AppError{
-		Code:    ErrCodeMergeError,
-		Message: message,
-		Err:     err,
-	}
+// Причину и контекст можно передать через WithCause и WithContext
+func NewMergeError(message string, opts ...Option) *AppError {
+	return newAppError(ErrCodeMergeError, message, nil, nil, opts)
 }
 
 // NewSaveError создает ошибку сохранения файла
-func NewSaveError(path string, err error) *AppError {
-	return &AppError{
-		Code:    ErrCodeSaveError,
-		Message: "Не удалось сохранить файл",
-		Context: map[string]interface{}{"path": path},
-		Err:     err,
-	}
+func NewSaveError(path string, err error, opts ...Option) *AppError {
+	return newAppError(ErrCodeSaveError, "Не удалось сохранить файл",
+		map[string]interface{}{"path": path}, err, opts)
 }
 
-// UserMessages содержит понятные пользователю сообщения об ошибках
-var UserMessages = map[string]string{
-	ErrCodeFileNotFound:     "Файл не найден. Пожалуйста, проверьте путь к файлу.",
-	ErrCodeFileReadError:    "Не удалось прочитать файл. Возможно, он поврежден или открыт в другой программе.",
-	ErrCodeSheetNotFound:    "Указанный лист не найден в файле. Проверьте настройки.",
-	ErrCodeInvalidHeaderRow: "Неверный номер строки заголовков. Укажите значение от 1 и выше.",
-	ErrCodeEmptyFile:        "Файл пустой или не содержит данных.",
-	ErrCodeInvalidFormat:    "Неверный формат файла. Поддерживаются только .xlsx файлы.",
-	ErrCodePermissionDenied: "Нет доступа к файлу. Проверьте права доступа.",
-	ErrCodeFileCorrupted:    "Файл поврежден и не может быть прочитан.",
-	ErrCodeConfigError:      "Ошибка конфигурации. Проверьте настройки профиля.",
-	ErrCodeMergeError:       "Ошибка при объединении файлов. Проверьте логи.",
-	ErrCodeSaveError:        "Не удалось сохранить файл. Проверьте путь и права доступа.",
-}
-
-// UserMessage возвращает понятное пользователю сообщение об ошибке
-func UserMessage(code string) string {
-	if msg, exists := UserMessages[code]; exists {
-		return msg
-	}
-	return "Произошла неизвестная ошибка"
+// NewRowLimitExceededError создает ошибку превышения лимита строк Excel на листе
+func NewRowLimitExceededError(sheet string, rows, limit int, opts ...Option) *AppError {
+	return newAppError(ErrCodeRowLimitExceeded,
+		fmt.Sprintf("Лист '%s' превышает лимит Excel: %d строк при максимуме %d", sheet, rows, limit),
+		map[string]interface{}{"sheet": sheet, "rows": rows, "limit": limit}, nil, opts)
+}
+
+// NewStructureMismatchError создает ошибку несовпадения структуры листа с базовым
+func NewStructureMismatchError(sheet, message string, opts ...Option) *AppError {
+	return newAppError(ErrCodeStructureMismatch, message,
+		map[string]interface{}{"sheet": sheet}, nil, opts)
+}
+
+// NewCancelledError создает ошибку отмены операции пользователем
+func NewCancelledError(opts ...Option) *AppError {
+	return newAppError(ErrCodeCancelled, "Операция отменена пользователем", nil, nil, opts)
+}
+
+// NewInternalError создает ошибку из перехваченной паники value при выполнении операции operation
+func NewInternalError(operation string, value interface{}, opts ...Option) *AppError {
+	return newAppError(ErrCodeInternal,
+		fmt.Sprintf("Внутренняя ошибка приложения: %s", operation),
+		map[string]interface{}{"operation": operation, "panic": fmt.Sprint(value)},
+		fmt.Errorf("panic: %v", value), opts)
+}
+
+// NewNoMatchingColumnsError создает ошибку "нет совпадающих столбцов с базовым листом"
+func NewNoMatchingColumnsError(file, sheet string, opts ...Option) *AppError {
+	return newAppError(ErrCodeNoMatchingColumns,
+		fmt.Sprintf("В файле %s нет совпадающих столбцов с базовым листом '%s'", file, sheet),
+		map[string]interface{}{"file": file, "sheet": sheet}, nil, opts)
 }
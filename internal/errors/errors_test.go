@@ -0,0 +1,161 @@
+package errors
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestConstructors тестирует код, форматирование и Unwrap каждого конструктора
+func TestConstructors(t *testing.T) {
+	cause := stderrors.New("disk full")
+
+	tests := []struct {
+		name      string
+		err       *AppError
+		wantCode  string
+		wantError string
+		wantCause error
+	}{
+		{"файл не найден", NewFileNotFoundError("a.xlsx"), ErrCodeFileNotFound, "[E001] Файл не найден", nil},
+		{"лист не найден", NewSheetNotFoundError("Шаблон", "a.xlsx"), ErrCodeSheetNotFound, "[E003] Лист 'Шаблон' не найден в файле", nil},
+		{"строка заголовков", NewInvalidHeaderRowError(0), ErrCodeInvalidHeaderRow, "[E004] Неверный номер строки заголовков: 0", nil},
+		{"строка заголовков из текста", NewInvalidHeaderRowInputError("пятая"), ErrCodeInvalidHeaderRow, "[E004] Неверный номер строки заголовков: 'пятая'", nil},
+		{"ошибка чтения", NewFileReadError("a.xlsx", cause), ErrCodeFileReadError, "[E002] Ошибка при чтении файла: disk full", cause},
+		{"пустой файл", NewEmptyFileError("a.xlsx"), ErrCodeEmptyFile, "[E005] Файл пустой или не содержит данных", nil},
+		{"неверный формат", NewInvalidFormatError("a.xls"), ErrCodeInvalidFormat, "[E006] Неверный формат файла. Поддерживаются только .xlsx файлы", nil},
+		{"нет доступа", NewPermissionDeniedError("a.xlsx"), ErrCodePermissionDenied, "[E007] Нет доступа к файлу", nil},
+		{"файл поврежден", NewFileCorruptedError("a.xlsx", cause), ErrCodeFileCorrupted, "[E008] Файл поврежден и не может быть прочитан: disk full", cause},
+		{"конфигурация", NewConfigError("Базовый файл не выбран"), ErrCodeConfigError, "[E009] Базовый файл не выбран", nil},
+		{"конфигурация с причиной", NewConfigError("Профиль не загружен", WithCause(cause)), ErrCodeConfigError, "[E009] Профиль не загружен: disk full", cause},
+		{"устаревший конструктор", NewConfigErrorWithCause("Профиль не загружен", cause), ErrCodeConfigError, "[E009] Профиль не загружен: disk full", cause},
+		{"объединение", NewMergeError("Объединение прервано"), ErrCodeMergeError, "[E010] Объединение прервано", nil},
+		{"объединение с причиной", NewMergeError("Объединение прервано", WithCause(cause)), ErrCodeMergeError, "[E010] Объединение прервано: disk full", cause},
+		{"сохранение", NewSaveError("a.xlsx", cause), ErrCodeSaveError, "[E011] Не удалось сохранить файл: disk full", cause},
+		{"лимит строк", NewRowLimitExceededError("Data", 2000000, 1048576), ErrCodeRowLimitExceeded, "[E012] Лист 'Data' превышает лимит Excel: 2000000 строк при максимуме 1048576", nil},
+		{"структура листа", NewStructureMismatchError("Data", "Заголовки не совпадают"), ErrCodeStructureMismatch, "[E013] Заголовки не совпадают", nil},
+		{"нет совпадающих столбцов", NewNoMatchingColumnsError("b.xlsx", "Data"), ErrCodeNoMatchingColumns, "[E014] В файле b.xlsx нет совпадающих столбцов с базовым листом 'Data'", nil},
+		{"отмена", NewCancelledError(), ErrCodeCancelled, "[E015] Операция отменена пользователем", nil},
+		{"файл слишком большой", NewFileTooLargeError("a.xlsx", 16<<30, cause), ErrCodeFileTooLarge, "[E017] Файл слишком большой: после распаковки он занимает больше 16384 МБ: disk full", cause},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Code != tt.wantCode {
+				t.Errorf("Code = %s, want %s", tt.err.Code, tt.wantCode)
+			}
+			if got := tt.err.Error(); got != tt.wantError {
+				t.Errorf("Error() = %q, want %q", got, tt.wantError)
+			}
+			if got := tt.err.Unwrap(); got != tt.wantCause {
+				t.Errorf("Unwrap() = %v, want %v", got, tt.wantCause)
+			}
+
+			// Ошибка находится через errors.As и в обернутом виде
+			var appErr *AppError
+			wrapped := fmt.Errorf("context: %w", tt.err)
+			if !stderrors.As(wrapped, &appErr) || appErr.Code != tt.wantCode {
+				t.Errorf("errors.As не нашел ошибку с кодом %s", tt.wantCode)
+			}
+			if tt.wantCause != nil && !stderrors.Is(wrapped, tt.wantCause) {
+				t.Error("errors.Is не нашел причину")
+			}
+		})
+	}
+}
+
+// TestOptions тестирует параметры WithCause и WithContext
+func TestOptions(t *testing.T) {
+	cause := stderrors.New("permission denied")
+	err := NewFileReadError("a.xlsx", nil,
+		WithCause(cause),
+		WithContext("sheet", "Шаблон"),
+		WithContext("path", "b.xlsx"),
+	)
+
+	if err.Unwrap() != cause {
+		t.Errorf("Unwrap() = %v, want %v", err.Unwrap(), cause)
+	}
+	if err.Context["sheet"] != "Шаблон" {
+		t.Errorf("Context[sheet] = %v", err.Context["sheet"])
+	}
+	if err.Context["path"] != "b.xlsx" {
+		t.Errorf("WithContext должен заменять значение конструктора, Context[path] = %v", err.Context["path"])
+	}
+
+	// Контекст создается, если конструктор его не задает
+	if err := NewConfigError("ошибка", WithContext("sheet_index", 2)); err.Context["sheet_index"] != 2 {
+		t.Errorf("Context = %v", err.Context)
+	}
+	if err := NewConfigError("ошибка"); err.Context != nil || err.Err != nil {
+		t.Errorf("без параметров контекст и причина должны быть пустыми: %+v", err)
+	}
+}
+
+// TestIs тестирует сравнение с ошибками-значениями через errors.Is и errors.As
+func TestIs(t *testing.T) {
+	sheetErr := NewSheetNotFoundError("Шаблон", "a.xlsx")
+	cause := stderrors.New("disk full")
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"тот же код", sheetErr, ErrSheetNotFound, true},
+		{"другой код", sheetErr, ErrFileNotFound, false},
+		{"обернутая ошибка", fmt.Errorf("лист: %w", sheetErr), ErrSheetNotFound, true},
+		{"дважды обернутая ошибка", fmt.Errorf("файл: %w", fmt.Errorf("лист: %w", sheetErr)), ErrSheetNotFound, true},
+		{"errors.Join", stderrors.Join(cause, NewCancelledError()), ErrCancelled, true},
+		{"причина внутри ошибки приложения", NewSaveError("a.xlsx", NewPermissionDeniedError("a.xlsx")), ErrPermissionDenied, true},
+		{"исходная причина", fmt.Errorf("сохранение: %w", NewSaveError("a.xlsx", cause)), cause, true},
+		{"обычная ошибка", cause, ErrSheetNotFound, false},
+		{"nil", nil, ErrCancelled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stderrors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// errors.As возвращает саму ошибку, а не значение для сравнения
+	var appErr *AppError
+	if !stderrors.As(fmt.Errorf("лист: %w", sheetErr), &appErr) || appErr != sheetErr {
+		t.Errorf("errors.As() = %v, want %v", appErr, sheetErr)
+	}
+}
+
+// TestLogValue тестирует запись ошибки приложения в структурированный журнал
+func TestLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+
+	err := NewFileReadError("a.xlsx", stderrors.New("disk full"), WithContext("sheet", "Шаблон"))
+	log.Error("ошибка чтения", "error", err)
+
+	line := buf.String()
+	for _, want := range []string{
+		"error.code=E002",
+		"error.context.path=a.xlsx",
+		"error.context.sheet=Шаблон",
+		`error.cause="disk full"`,
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("журнал не содержит %q: %s", want, line)
+		}
+	}
+
+	// Ошибка без контекста и причины записывается только кодом и сообщением
+	buf.Reset()
+	log.Error("отмена", "error", NewCancelledError())
+	if line := buf.String(); strings.Contains(line, "context") || strings.Contains(line, "cause") {
+		t.Errorf("лишние атрибуты: %s", line)
+	}
+}
@@ -0,0 +1,116 @@
+package errors
+
+import "strings"
+
+// Языки сообщений об ошибках для пользователя
+const (
+	LangRussian     = "ru"
+	LangEnglish     = "en"
+	DefaultLanguage = LangRussian
+)
+
+// SupportedLanguages языки, для которых в каталоге есть сообщения всех ошибок
+var SupportedLanguages = []string{LangRussian, LangEnglish}
+
+// userMessages каталог понятных пользователю сообщений: язык -> код ошибки -> сообщение
+var userMessages = map[string]map[string]string{
+	LangRussian: {
+		ErrCodeFileNotFound:      "Файл не найден. Пожалуйста, проверьте путь к файлу.",
+		ErrCodeFileReadError:     "Не удалось прочитать файл. Возможно, он поврежден или открыт в другой программе.",
+		ErrCodeSheetNotFound:     "Указанный лист не найден в файле. Проверьте настройки.",
+		ErrCodeInvalidHeaderRow:  "Неверный номер строки заголовков. Укажите значение от 1 и выше.",
+		ErrCodeEmptyFile:         "Файл пустой или не содержит данных.",
+		ErrCodeInvalidFormat:     "Неверный формат файла. Поддерживаются только .xlsx файлы.",
+		ErrCodePermissionDenied:  "Нет доступа к файлу. Проверьте права доступа.",
+		ErrCodeFileCorrupted:     "Файл поврежден и не может быть прочитан.",
+		ErrCodeConfigError:       "Ошибка конфигурации. Проверьте настройки профиля.",
+		ErrCodeMergeError:        "Ошибка при объединении файлов. Проверьте логи.",
+		ErrCodeSaveError:         "Не удалось сохранить файл. Проверьте путь и права доступа.",
+		ErrCodeRowLimitExceeded:  "Результат превышает лимит Excel в 1 048 576 строк на листе. Включите автоматическое разбиение на несколько листов.",
+		ErrCodeStructureMismatch: "Структура листа не совпадает с базовым файлом. Проверьте заголовки столбцов.",
+		ErrCodeNoMatchingColumns: "В файле нет ни одного столбца из базового листа. Возможно, выбран не тот файл или лист.",
+		ErrCodeCancelled:         "Операция отменена.",
+		ErrCodeInternal:          "Внутренняя ошибка приложения. Операция прервана, подробности записаны в журнал.",
+		ErrCodeFileTooLarge:      "Файл необычно большой и не может быть открыт целиком. Разделите выгрузку на несколько файлов или удалите лишние листы.",
+	},
+	LangEnglish: {
+		ErrCodeFileNotFound:      "File not found. Please check the file path.",
+		ErrCodeFileReadError:     "Could not read the file. It may be damaged or open in another program.",
+		ErrCodeSheetNotFound:     "The sheet was not found in the file. Check the settings.",
+		ErrCodeInvalidHeaderRow:  "Invalid header row number. Enter a value of 1 or greater.",
+		ErrCodeEmptyFile:         "The file is empty or contains no data.",
+		ErrCodeInvalidFormat:     "Invalid file format. Only .xlsx files are supported.",
+		ErrCodePermissionDenied:  "Access to the file is denied. Check the file permissions.",
+		ErrCodeFileCorrupted:     "The file is damaged and cannot be read.",
+		ErrCodeConfigError:       "Configuration error. Check the profile settings.",
+		ErrCodeMergeError:        "Failed to merge the files. Check the logs.",
+		ErrCodeSaveError:         "Could not save the file. Check the path and permissions.",
+		ErrCodeRowLimitExceeded:  "The result exceeds the Excel limit of 1,048,576 rows per sheet. Enable automatic splitting into several sheets.",
+		ErrCodeStructureMismatch: "The sheet structure does not match the base file. Check the column headers.",
+		ErrCodeNoMatchingColumns: "The file has none of the base sheet columns. The wrong file or sheet may have been selected.",
+		ErrCodeCancelled:         "The operation was cancelled.",
+		ErrCodeInternal:          "Internal application error. The operation was stopped; details are in the log.",
+		ErrCodeFileTooLarge:      "The file is unusually large and cannot be opened as a whole. Split the export into several files or remove unneeded sheets.",
+	},
+}
+
+// unknownMessages сообщения о неизвестной ошибке по языкам
+var unknownMessages = map[string]string{
+	LangRussian: "Произошла неизвестная ошибка",
+	LangEnglish: "An unknown error occurred",
+}
+
+// UserMessages содержит понятные пользователю сообщения об ошибках на русском языке
+//
+// Deprecated: используйте UserMessageLocalized
+var UserMessages = userMessages[LangRussian]
+
+// NormalizeLanguage приводит код языка к поддерживаемому: "en-US" -> "en"
+// Пустой и неподдерживаемый язык заменяется на DefaultLanguage
+func NormalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := userMessages[lang]; ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// LookupUserMessage возвращает сообщение для кода ошибки на языке lang
+// Если перевода нет, используется сообщение на DefaultLanguage
+func LookupUserMessage(code, lang string) (string, bool) {
+	if msg, ok := userMessages[NormalizeLanguage(lang)][code]; ok {
+		return msg, true
+	}
+	msg, ok := userMessages[DefaultLanguage][code]
+	return msg, ok
+}
+
+// UserMessageLocalized возвращает понятное пользователю сообщение об ошибке на языке lang
+func UserMessageLocalized(code, lang string) string {
+	if msg, ok := LookupUserMessage(code, lang); ok {
+		return msg
+	}
+	return unknownMessages[NormalizeLanguage(lang)]
+}
+
+// LocalizedMessage возвращает сообщение об ошибке err для пользователя на языке lang:
+// общее сообщение по коду, дополненное подробностями конкретной ошибки с новой строки.
+// Для кода без сообщения в каталоге возвращаются только подробности
+func LocalizedMessage(err *AppError, lang string) string {
+	msg, ok := LookupUserMessage(err.Code, lang)
+	if !ok {
+		return err.Message
+	}
+	if err.Message != "" && err.Message != msg {
+		return msg + "\n" + err.Message
+	}
+	return msg
+}
+
+// UserMessage возвращает понятное пользователю сообщение об ошибке на DefaultLanguage
+func UserMessage(code string) string {
+	return UserMessageLocalized(code, DefaultLanguage)
+}
@@ -0,0 +1,128 @@
+package errors
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// errorCodes возвращает значения всех констант ErrCode* из errors.go
+// Коды берутся из исходного текста, чтобы новый код без перевода не остался незамеченным
+func errorCodes(t *testing.T) []string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "errors.go", nil, 0)
+	if err != nil {
+		t.Fatalf("не удалось разобрать errors.go: %v", err)
+	}
+
+	var codes []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			for i, name := range value.Names {
+				if !strings.HasPrefix(name.Name, "ErrCode") || i >= len(value.Values) {
+					continue
+				}
+				if lit, ok := value.Values[i].(*ast.BasicLit); ok {
+					codes = append(codes, strings.Trim(lit.Value, `"`))
+				}
+			}
+		}
+	}
+	if len(codes) == 0 {
+		t.Fatal("в errors.go не найдено ни одного кода ошибки")
+	}
+	return codes
+}
+
+// TestUserMessagesComplete проверяет, что у каждого кода ошибки есть сообщение на всех языках
+func TestUserMessagesComplete(t *testing.T) {
+	codes := errorCodes(t)
+
+	for _, lang := range SupportedLanguages {
+		catalog, ok := userMessages[lang]
+		if !ok {
+			t.Errorf("нет каталога сообщений для языка %s", lang)
+			continue
+		}
+		if unknownMessages[lang] == "" {
+			t.Errorf("нет сообщения о неизвестной ошибке для языка %s", lang)
+		}
+		for _, code := range codes {
+			if strings.TrimSpace(catalog[code]) == "" {
+				t.Errorf("нет сообщения для кода %s на языке %s", code, lang)
+			}
+		}
+	}
+
+	for _, code := range []string{ErrCodeCancelled, ErrCodeStructureMismatch, ErrCodeRowLimitExceeded} {
+		if !slices.Contains(codes, code) {
+			t.Errorf("код %s не найден в errors.go", code)
+		}
+	}
+}
+
+// TestUserMessageLocalized тестирует выбор языка и запасные варианты
+func TestUserMessageLocalized(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		lang string
+		want string
+	}{
+		{"русский", ErrCodeCancelled, LangRussian, "Операция отменена."},
+		{"английский", ErrCodeCancelled, LangEnglish, "The operation was cancelled."},
+		{"регион и регистр", ErrCodeFileNotFound, "EN-us", "File not found. Please check the file path."},
+		{"пустой язык", ErrCodeCancelled, "", "Операция отменена."},
+		{"неподдерживаемый язык", ErrCodeCancelled, "de", "Операция отменена."},
+		{"неизвестный код", "E999", LangEnglish, "An unknown error occurred"},
+		{"неизвестный код по умолчанию", "E999", "", "Произошла неизвестная ошибка"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UserMessageLocalized(tt.code, tt.lang); got != tt.want {
+				t.Errorf("UserMessageLocalized(%s, %q) = %q, want %q", tt.code, tt.lang, got, tt.want)
+			}
+		})
+	}
+
+	if UserMessage(ErrCodeSaveError) != UserMessages[ErrCodeSaveError] {
+		t.Error("UserMessage должен совпадать с русским каталогом")
+	}
+	if _, ok := LookupUserMessage("E999", LangRussian); ok {
+		t.Error("LookupUserMessage не должен находить неизвестный код")
+	}
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *AppError
+		lang string
+		want string
+	}{
+		{"с подробностями", &AppError{Code: ErrCodeFileNotFound, Message: "base.xlsx"}, LangEnglish,
+			"File not found. Please check the file path.\nbase.xlsx"},
+		{"без подробностей", &AppError{Code: ErrCodeCancelled}, LangRussian, "Операция отменена."},
+		{"подробности совпадают с сообщением", &AppError{Code: ErrCodeCancelled, Message: "Операция отменена."}, LangRussian,
+			"Операция отменена."},
+		{"неизвестный код", &AppError{Code: "E999", Message: "нет сообщения"}, LangEnglish, "нет сообщения"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LocalizedMessage(tt.err, tt.lang); got != tt.want {
+				t.Errorf("LocalizedMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
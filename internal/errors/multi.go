@@ -0,0 +1,73 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MultiError объединяет несколько ошибок, например ошибки разных листов
+// errors.Is и errors.As проверяют каждую ошибку через Unwrap() []error
+type MultiError struct {
+	Errors []error
+}
+
+// NewMultiError создает MultiError из ошибок errs; nil пропускаются,
+// вложенные MultiError раскрываются в общий список
+func NewMultiError(errs ...error) *MultiError {
+	m := &MultiError{}
+	for _, err := range errs {
+		m.Append(err)
+	}
+	return m
+}
+
+// Append добавляет ошибку; nil пропускается, вложенный MultiError раскрывается
+func (m *MultiError) Append(err error) {
+	if err == nil {
+		return
+	}
+	if nested, ok := err.(*MultiError); ok {
+		m.Errors = append(m.Errors, nested.Errors...)
+		return
+	}
+	m.Errors = append(m.Errors, err)
+}
+
+// Len возвращает количество ошибок
+func (m *MultiError) Len() int {
+	return len(m.Errors)
+}
+
+// ErrorOrNil возвращает nil без ошибок, единственную ошибку как есть, иначе сам MultiError
+func (m *MultiError) ErrorOrNil() error {
+	switch len(m.Errors) {
+	case 0:
+		return nil
+	case 1:
+		return m.Errors[0]
+	default:
+		return m
+	}
+}
+
+// Error формирует нумерованный список ошибок; единственная ошибка выводится без номера
+func (m *MultiError) Error() string {
+	switch len(m.Errors) {
+	case 0:
+		return "нет ошибок"
+	case 1:
+		return m.Errors[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Ошибок: %d", len(m.Errors))
+	for i, err := range m.Errors {
+		fmt.Fprintf(&b, "\n%d. %s", i+1, err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap возвращает все ошибки для errors.Is и errors.As
+func (m *MultiError) Unwrap() []error {
+	return m.Errors
+}
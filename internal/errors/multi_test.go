@@ -0,0 +1,101 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+)
+
+// TestMultiErrorFormatting тестирует вывод пустого, одиночного и составного списка ошибок
+func TestMultiErrorFormatting(t *testing.T) {
+	sheetErr := NewSheetNotFoundError("Шаблон", "a.xlsx")
+	plainErr := stderrors.New("disk full")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantLen   int
+		wantError string
+	}{
+		{"пустой", nil, 0, "нет ошибок"},
+		{"только nil", []error{nil, nil}, 0, "нет ошибок"},
+		{"одна ошибка", []error{sheetErr}, 1, "[E003] Лист 'Шаблон' не найден в файле"},
+		{
+			"несколько ошибок",
+			[]error{sheetErr, nil, plainErr},
+			2,
+			"Ошибок: 2\n1. [E003] Лист 'Шаблон' не найден в файле\n2. disk full",
+		},
+		{
+			"вложенный список раскрывается",
+			[]error{NewMultiError(sheetErr, plainErr), NewCancelledError()},
+			3,
+			"Ошибок: 3\n1. [E003] Лист 'Шаблон' не найден в файле\n2. disk full\n3. [E015] Операция отменена пользователем",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMultiError(tt.errs...)
+			if m.Len() != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", m.Len(), tt.wantLen)
+			}
+			if got := m.Error(); got != tt.wantError {
+				t.Errorf("Error() = %q, want %q", got, tt.wantError)
+			}
+		})
+	}
+}
+
+// TestMultiErrorOrNil тестирует ErrorOrNil для пустого, одиночного и составного списка
+func TestMultiErrorOrNil(t *testing.T) {
+	if err := NewMultiError().ErrorOrNil(); err != nil {
+		t.Errorf("ErrorOrNil() без ошибок = %v, want nil", err)
+	}
+
+	single := NewSaveError("a.xlsx", nil)
+	if err := NewMultiError(nil, single).ErrorOrNil(); err != single {
+		t.Errorf("ErrorOrNil() с одной ошибкой = %v, want %v", err, single)
+	}
+
+	m := NewMultiError(single, NewCancelledError())
+	if err := m.ErrorOrNil(); err != m {
+		t.Errorf("ErrorOrNil() с двумя ошибками = %v, want MultiError", err)
+	}
+}
+
+// TestMultiErrorIsAs тестирует поиск ошибок среди членов списка, в том числе обернутого
+func TestMultiErrorIsAs(t *testing.T) {
+	cause := stderrors.New("disk full")
+	saveErr := NewSaveError("a.xlsx", cause)
+	m := NewMultiError(NewSheetNotFoundError("Шаблон", "a.xlsx"), fmt.Errorf("лист Data: %w", saveErr))
+	wrapped := fmt.Errorf("объединение: %w", m)
+
+	for _, tt := range []struct {
+		name   string
+		target error
+		want   bool
+	}{
+		{"первый член", ErrSheetNotFound, true},
+		{"обернутый член", ErrSave, true},
+		{"причина члена", cause, true},
+		{"нет среди членов", ErrCancelled, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stderrors.Is(wrapped, tt.target); got != tt.want {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var asMulti *MultiError
+	if !stderrors.As(wrapped, &asMulti) || asMulti != m {
+		t.Error("errors.As не нашел MultiError")
+	}
+
+	// errors.As находит первую ошибку приложения среди членов
+	var appErr *AppError
+	if !stderrors.As(wrapped, &appErr) || appErr.Code != ErrCodeSheetNotFound {
+		t.Errorf("errors.As() = %v, want ошибку %s", appErr, ErrCodeSheetNotFound)
+	}
+}
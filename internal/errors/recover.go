@@ -0,0 +1,26 @@
+package errors
+
+import (
+	"log/slog"
+	"runtime/debug"
+)
+
+// Recover перехватывает панику горутины, записывает ее стек в журнал и передает
+// в onPanic ошибку ErrCodeInternal, чтобы показать ее пользователю обычным путем
+// Вызывается только через defer: defer errors.Recover(logger, "объединение", report)
+func Recover(logger *slog.Logger, operation string, onPanic func(err error)) {
+	value := recover()
+	if value == nil {
+		return
+	}
+
+	err := NewInternalError(operation, value)
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Error("перехвачена паника", "operation", operation, "error", err, "stack", string(debug.Stack()))
+
+	if onPanic != nil {
+		onPanic(err)
+	}
+}
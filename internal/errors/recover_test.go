@@ -0,0 +1,41 @@
+package errors
+
+import (
+	"bytes"
+	stderrors "errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestRecover тестирует перехват паники с записью стека в журнал
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	var got error
+	func() {
+		defer Recover(logger, "проверка", func(err error) { got = err })
+		panic("сбой")
+	}()
+
+	if !stderrors.Is(got, ErrInternal) {
+		t.Fatalf("ошибка = %v, ожидалась ErrInternal", got)
+	}
+	var appErr *AppError
+	if !stderrors.As(got, &appErr) || appErr.Context["operation"] != "проверка" || appErr.Context["panic"] != "сбой" {
+		t.Errorf("контекст ошибки = %v", appErr)
+	}
+	if !strings.Contains(buf.String(), "recover_test.go") {
+		t.Errorf("в журнале нет стека паники: %s", buf.String())
+	}
+
+	// Без паники обработчик не вызывается
+	called := false
+	func() {
+		defer Recover(logger, "проверка", func(error) { called = true })
+	}()
+	if called {
+		t.Error("обработчик вызван без паники")
+	}
+}
@@ -0,0 +1,126 @@
+package excel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// Запуск: make bench или go test -run '^$' -bench . -benchmem ./internal/excel
+// Метрики: rows/s - строк в секунду, peak-heap-MB - максимальный объем кучи за замер
+
+// benchSizes размеры синтетических листов: строки × столбцы
+var benchSizes = []struct {
+	rows    int
+	columns int
+}{
+	{1000, 10},
+	{10000, 20},
+	{100000, 20},
+}
+
+// writeDataWorkbook сохраняет в path книгу с одним листом Data из строк rows
+// Книга пишется потоком, поэтому подходит и для больших листов; путь с расширением .gz
+// получает книгу, сжатую gzip
+func writeDataWorkbook(tb testing.TB, path string, rows [][]string) {
+	tb.Helper()
+	writer, err := NewStreamWriter("Data")
+	if err != nil {
+		tb.Fatalf("не удалось создать лист: %v", err)
+	}
+	defer writer.Close()
+
+	for _, row := range rows {
+		if err := writer.WriteRow(row); err != nil {
+			tb.Fatalf("не удалось записать строку: %v", err)
+		}
+	}
+	bookPath, compress := strings.CutSuffix(path, ".gz")
+	if err := writer.SaveAtomic(bookPath); err != nil {
+		tb.Fatalf("не удалось сохранить файл: %v", err)
+	}
+	if !compress {
+		return
+	}
+
+	book, err := os.ReadFile(bookPath)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.Remove(bookPath); err != nil {
+		tb.Fatal(err)
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(book); err != nil {
+		tb.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.WriteFile(path, compressed.Bytes(), 0644); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// BenchmarkReadDataRows измеряет чтение строк данных листа (фаза чтения объединения)
+func BenchmarkReadDataRows(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(fmt.Sprintf("rows=%d/cols=%d", size.rows, size.columns), func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), "data.xlsx")
+			writeDataWorkbook(b, path, append([][]string{exceltest.BenchHeaders(size.columns)}, exceltest.BenchRows(size.rows, size.columns)...))
+
+			b.ReportAllocs()
+			heap := exceltest.StartPeakHeap()
+			for b.Loop() {
+				reader, err := NewReader(path)
+				if err != nil {
+					b.Fatalf("не удалось открыть файл: %v", err)
+				}
+				rows, err := reader.GetDataRows("Data", 1)
+				reader.Close()
+				if err != nil || len(rows) != size.rows {
+					b.Fatalf("прочитано %d строк, ожидалось %d: %v", len(rows), size.rows, err)
+				}
+			}
+			exceltest.ReportThroughput(b, size.rows, heap.Stop())
+		})
+	}
+}
+
+// BenchmarkWriteRows измеряет запись и сохранение листа (фаза записи объединения)
+func BenchmarkWriteRows(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(fmt.Sprintf("rows=%d/cols=%d", size.rows, size.columns), func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), "result.xlsx")
+			headers := exceltest.BenchHeaders(size.columns)
+			data := exceltest.BenchRows(size.rows, size.columns)
+
+			b.ReportAllocs()
+			heap := exceltest.StartPeakHeap()
+			for b.Loop() {
+				writer := NewWriter()
+				if err := writer.CreateSheet("Data"); err != nil {
+					b.Fatalf("не удалось создать лист: %v", err)
+				}
+				if err := writer.WriteHeaderRow("Data", 1, headers); err != nil {
+					b.Fatalf("не удалось записать заголовки: %v", err)
+				}
+				if err := writer.WriteRows("Data", 2, data); err != nil {
+					b.Fatalf("не удалось записать строки: %v", err)
+				}
+				if err := writer.Save(path); err != nil {
+					b.Fatalf("не удалось сохранить файл: %v", err)
+				}
+				writer.Close()
+			}
+			exceltest.ReportThroughput(b, size.rows, heap.Stop())
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package excel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ConditionalFormats правила условного форматирования листа, прочитанные из книги
+// Стили правил хранятся определениями: индексы стилей действуют только в своей книге
+type ConditionalFormats struct {
+	rules []conditionalRule
+}
+
+// conditionalRule правила одной области условного форматирования
+type conditionalRule struct {
+	ref     string                              // Области через пробел, например "C2:C100 E2:E100"
+	options []excelize.ConditionalFormatOptions // Правила области
+	styles  []*excelize.Style                   // Стиль каждого правила (nil - правило без стиля)
+}
+
+// Len возвращает количество областей с условным форматированием
+func (c *ConditionalFormats) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.rules)
+}
+
+// GetConditionalFormats возвращает правила условного форматирования листа sheetName
+// Области возвращаются в порядке ссылок, чтобы результат не зависел от обхода карты
+func (r *Reader) GetConditionalFormats(sheetName string) (*ConditionalFormats, error) {
+	formats, err := r.file.GetConditionalFormats(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать условное форматирование листа '%s': %w", sheetName, err)
+	}
+
+	refs := make([]string, 0, len(formats))
+	for ref := range formats {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	result := &ConditionalFormats{}
+	for _, ref := range refs {
+		rule := conditionalRule{ref: ref, options: formats[ref], styles: make([]*excelize.Style, len(formats[ref]))}
+		for i, option := range rule.options {
+			if option.Format == nil {
+				continue
+			}
+			style, err := r.file.GetConditionalStyle(*option.Format)
+			if err != nil {
+				return nil, fmt.Errorf("не удалось прочитать стиль условного форматирования %s: %w", ref, err)
+			}
+			rule.styles[i] = style
+		}
+		result.rules = append(result.rules, rule)
+	}
+	return result, nil
+}
+
+// ApplyConditionalFormats задает правила formats листу sheetName и его листам-продолжениям
+// Области, заходящие ниже строки заголовков headerRow, продлеваются до последней строки данных листа;
+// области шапки и ссылки на целые столбцы остаются как есть. Правила с той же областью,
+// уже имеющиеся на листе (например, в копии базового файла), заменяются
+func (w *Writer) ApplyConditionalFormats(sheetName string, formats *ConditionalFormats, headerRow int) error {
+	if formats.Len() == 0 {
+		return nil
+	}
+
+	// Стили правил создаются в книге результата один раз на все листы
+	styleIDs := make(map[*excelize.Style]int)
+	parts, lastRows := w.sheetParts(sheetName)
+	for _, rule := range formats.rules {
+		options := make([]excelize.ConditionalFormatOptions, len(rule.options))
+		for i, option := range rule.options {
+			if style := rule.styles[i]; style != nil {
+				id, ok := styleIDs[style]
+				if !ok {
+					var err error
+					if id, err = w.file.NewConditionalStyle(style); err != nil {
+						return fmt.Errorf("не удалось создать стиль условного форматирования %s: %w", rule.ref, err)
+					}
+					styleIDs[style] = id
+				}
+				option.Format = &id
+			}
+			options[i] = option
+		}
+
+		for i, part := range parts {
+			if err := w.file.UnsetConditionalFormat(part, rule.ref); err != nil {
+				return fmt.Errorf("не удалось заменить условное форматирование листа '%s': %w", part, err)
+			}
+			ref := extendConditionalRef(rule.ref, headerRow, lastRows[i])
+			if err := w.file.SetConditionalFormat(part, ref, options); err != nil {
+				return fmt.Errorf("не удалось задать условное форматирование %s листа '%s': %w", ref, part, err)
+			}
+		}
+	}
+	return nil
+}
+
+// extendConditionalRef продлевает до строки lastRow области ref, заходящие ниже строки headerRow
+// Области, которые не удалось разобрать (например, целые столбцы "C:C"), не меняются
+func extendConditionalRef(ref string, headerRow, lastRow int) string {
+	areas := strings.Fields(strings.ReplaceAll(ref, ",", " "))
+	for i, area := range areas {
+		dataRange, err := ParseRangeRef(area)
+		if err != nil || dataRange.LastRow <= headerRow || dataRange.LastRow >= lastRow {
+			continue
+		}
+		first, _ := excelize.CoordinatesToCellName(dataRange.FirstCol, dataRange.FirstRow)
+		last, _ := excelize.CoordinatesToCellName(dataRange.LastCol, lastRow)
+		areas[i] = first + ":" + last
+	}
+	return strings.Join(areas, " ")
+}
@@ -0,0 +1,28 @@
+package excel
+
+import "testing"
+
+func TestExtendConditionalRef(t *testing.T) {
+	tests := []struct {
+		ref       string
+		headerRow int
+		lastRow   int
+		want      string
+	}{
+		{"B2:B3", 1, 10, "B2:B10"},
+		{"$B$2:$C$3", 1, 10, "B2:C10"},
+		{"A1:D1", 1, 10, "A1:D1"},
+		{"A1:A5", 1, 10, "A1:A10"},
+		{"B2:B3 D2:D3", 1, 10, "B2:B10 D2:D10"},
+		{"B5", 1, 10, "B5:B10"},
+		{"C:C", 1, 10, "C:C"},
+		{"B2:B100", 1, 10, "B2:B100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := extendConditionalRef(tt.ref, tt.headerRow, tt.lastRow); got != tt.want {
+				t.Errorf("extendConditionalRef(%q) = %q, ожидалось %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package exceltest
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// BenchRows генерирует строки данных: артикул, числа и текст в остальных столбцах
+func BenchRows(rows, columns int) [][]string {
+	data := make([][]string, rows)
+	for i := range data {
+		row := make([]string, columns)
+		row[0] = fmt.Sprintf("ART-%07d", i)
+		for col := 1; col < columns; col++ {
+			if col%2 == 1 {
+				row[col] = strconv.Itoa(i * col)
+			} else {
+				row[col] = fmt.Sprintf("Значение %d-%d", i, col)
+			}
+		}
+		data[i] = row
+	}
+	return data
+}
+
+// BenchHeaders генерирует строку заголовков из columns столбцов
+func BenchHeaders(columns int) []string {
+	headers := make([]string, columns)
+	headers[0] = "Артикул"
+	for col := 1; col < columns; col++ {
+		headers[col] = fmt.Sprintf("Поле %d", col)
+	}
+	return headers
+}
+
+// ReportThroughput добавляет к результату скорость в строках в секунду и пик кучи
+func ReportThroughput(b *testing.B, rowsPerOp int, peakHeap uint64) {
+	b.Helper()
+	if seconds := b.Elapsed().Seconds(); seconds > 0 {
+		b.ReportMetric(float64(rowsPerOp*b.N)/seconds, "rows/s")
+	}
+	b.ReportMetric(float64(peakHeap)/(1<<20), "peak-heap-MB")
+}
+
+// PeakHeap отслеживает максимальный объем занятой кучи во время замера
+// Использует runtime/metrics, чтобы опрос не останавливал программу
+type PeakHeap struct {
+	stop chan struct{}
+	done chan struct{}
+	peak uint64
+}
+
+// StartPeakHeap начинает опрос объема кучи
+func StartPeakHeap() *PeakHeap {
+	runtime.GC()
+	p := &PeakHeap{stop: make(chan struct{}), done: make(chan struct{})}
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			metrics.Read(sample)
+			if value := sample[0].Value.Uint64(); value > p.peak {
+				p.peak = value
+			}
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return p
+}
+
+// Stop завершает опрос и возвращает пик кучи в байтах
+func (p *PeakHeap) Stop() uint64 {
+	close(p.stop)
+	<-p.done
+	return p.peak
+}
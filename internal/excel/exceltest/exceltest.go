@@ -0,0 +1,34 @@
+// Package exceltest содержит помощники тестов и бенчмарков: создание книг Excel,
+// синтетические строки данных и замер пика кучи
+// Пакет не импортирует excel, поэтому им пользуются и тесты самого пакета excel
+package exceltest
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteWorkbook создает xlsx файл path с одним листом sheetName и строками rows
+// Все значения записываются как текст
+func WriteWorkbook(t testing.TB, path, sheetName string, rows [][]string) {
+	t.Helper()
+	file := excelize.NewFile()
+	defer file.Close()
+
+	if err := file.SetSheetName(file.GetSheetName(0), sheetName); err != nil {
+		t.Fatalf("не удалось создать лист: %v", err)
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			t.Fatalf("не удалось записать строки: %v", err)
+		}
+		if err := file.SetSheetRow(sheetName, cell, &row); err != nil {
+			t.Fatalf("не удалось записать строки: %v", err)
+		}
+	}
+	if err := file.SaveAs(path); err != nil {
+		t.Fatalf("не удалось сохранить файл: %v", err)
+	}
+}
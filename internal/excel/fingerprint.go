@@ -0,0 +1,116 @@
+package excel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+)
+
+// Алгоритмы отпечатков содержимого файлов
+const (
+	HashSHA256 = "sha256" // Криптографический хеш: подтверждает, что файл не изменялся (по умолчанию)
+	HashCRC64  = "crc64"  // Быстрая контрольная сумма CRC-64 (ECMA) без защиты от подделки
+)
+
+// DefaultHashAlgorithm алгоритм отпечатков по умолчанию
+const DefaultHashAlgorithm = HashSHA256
+
+// crc64Table таблица CRC-64 ECMA
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// hashAlgorithms конструкторы хешей поддерживаемых алгоритмов
+var hashAlgorithms = map[string]func() hash.Hash{
+	HashSHA256: sha256.New,
+	HashCRC64:  func() hash.Hash { return crc64.New(crc64Table) },
+}
+
+// Fingerprint отпечаток содержимого файла
+type Fingerprint struct {
+	Algorithm string
+	Sum       string // Значение хеша в шестнадцатеричном виде
+	Size      int64  // Размер файла в байтах
+}
+
+// Fingerprints отпечатки одного файла по алгоритмам
+type Fingerprints map[string]Fingerprint
+
+// Get возвращает отпечаток алгоритма algorithm (пусто - DefaultHashAlgorithm)
+func (f Fingerprints) Get(algorithm string) (Fingerprint, bool) {
+	fingerprint, ok := f[NormalizeHashAlgorithm(algorithm)]
+	return fingerprint, ok
+}
+
+// NormalizeHashAlgorithm приводит имя алгоритма к виду констант; пусто - DefaultHashAlgorithm
+func NormalizeHashAlgorithm(algorithm string) string {
+	algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+	if algorithm == "" {
+		return DefaultHashAlgorithm
+	}
+	return algorithm
+}
+
+// ValidateHashAlgorithm проверяет, что алгоритм отпечатков поддерживается
+func ValidateHashAlgorithm(algorithm string) error {
+	if _, ok := hashAlgorithms[NormalizeHashAlgorithm(algorithm)]; !ok {
+		return fmt.Errorf("неизвестный алгоритм отпечатков %q (ожидается %s или %s)", algorithm, HashSHA256, HashCRC64)
+	}
+	return nil
+}
+
+// HashFile считает отпечатки содержимого файла path, не открывая его как книгу
+func HashFile(path string) (Fingerprints, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, apperrors.NewFileReadError(path, err)
+	}
+	defer file.Close()
+
+	hashes := newFingerprinter()
+	if _, err := io.Copy(hashes, file); err != nil {
+		return nil, apperrors.NewFileReadError(path, err)
+	}
+	return hashes.fingerprints(), nil
+}
+
+// fingerprinter считает отпечатки всех поддерживаемых алгоритмов за один проход
+// Используется как io.Writer рядом с чтением или записью файла, поэтому файл
+// не читается повторно ради отпечатка
+type fingerprinter struct {
+	hashes map[string]hash.Hash
+	size   int64
+}
+
+// newFingerprinter создает fingerprinter для всех поддерживаемых алгоритмов
+func newFingerprinter() *fingerprinter {
+	hashes := make(map[string]hash.Hash, len(hashAlgorithms))
+	for name, newHash := range hashAlgorithms {
+		hashes[name] = newHash()
+	}
+	return &fingerprinter{hashes: hashes}
+}
+
+// Write добавляет данные ко всем хешам
+func (f *fingerprinter) Write(p []byte) (int, error) {
+	for _, h := range f.hashes {
+		h.Write(p) // hash.Hash.Write не возвращает ошибок
+	}
+	f.size += int64(len(p))
+	return len(p), nil
+}
+
+// fingerprints возвращает отпечатки прочитанных или записанных данных
+func (f *fingerprinter) fingerprints() Fingerprints {
+	result := make(Fingerprints, len(f.hashes))
+	for name, h := range f.hashes {
+		result[name] = Fingerprint{Algorithm: name, Sum: hex.EncodeToString(h.Sum(nil)), Size: f.size}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package excel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/crc64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFingerprinter(t *testing.T) {
+	data := []byte("abc")
+	hashes := newFingerprinter()
+	hashes.Write(data[:1])
+	hashes.Write(data[1:])
+	got := hashes.fingerprints()
+
+	tests := []struct {
+		algorithm string
+		want      string
+	}{
+		{"", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{HashSHA256, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{" CRC64 ", fmt.Sprintf("%016x", crc64.Checksum(data, crc64Table))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			fingerprint, ok := got.Get(tt.algorithm)
+			if !ok {
+				t.Fatalf("нет отпечатка алгоритма %q", tt.algorithm)
+			}
+			if fingerprint.Sum != tt.want || fingerprint.Size != int64(len(data)) {
+				t.Errorf("отпечаток = %+v, ожидалось %s размером %d", fingerprint, tt.want, len(data))
+			}
+		})
+	}
+
+	if _, ok := got.Get("md5"); ok {
+		t.Error("найден отпечаток неподдерживаемого алгоритма")
+	}
+	if err := ValidateHashAlgorithm("md5"); err == nil {
+		t.Error("неподдерживаемый алгоритм принят")
+	}
+	if err := ValidateHashAlgorithm(""); err != nil {
+		t.Errorf("алгоритм по умолчанию отклонен: %v", err)
+	}
+}
+
+func TestWorkbookFingerprints(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "book.xlsx")
+
+	writer := NewWriter()
+	defer writer.Close()
+	if writer.SavedFingerprints() != nil {
+		t.Error("отпечатки есть до сохранения")
+	}
+	if err := writer.CreateSheet("Data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteRows("Data", 1, [][]string{{"Артикул", "Цена"}, {"A1", "100"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.SaveAtomic(path); err != nil {
+		t.Fatalf("не удалось сохранить книгу: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	want := hex.EncodeToString(sum[:])
+
+	saved, _ := writer.SavedFingerprints().Get(HashSHA256)
+	if saved.Sum != want || saved.Size != int64(len(data)) {
+		t.Errorf("отпечаток сохранения = %+v, ожидалось %s размером %d", saved, want, len(data))
+	}
+
+	reader, err := NewReader(path)
+	if err != nil {
+		t.Fatalf("не удалось открыть книгу: %v", err)
+	}
+	defer reader.Close()
+	read, _ := reader.Fingerprints().Get(HashSHA256)
+	if read != saved {
+		t.Errorf("отпечаток чтения = %+v, при сохранении %+v", read, saved)
+	}
+	// Книга, открытая из потока, читается полностью
+	rows, err := reader.GetRows("Data")
+	if err != nil || len(rows) != 2 {
+		t.Errorf("строки = %q, ошибка %v", rows, err)
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fingerprints, err := HashFile(path)
+	if err != nil {
+		t.Fatalf("ошибка: %v", err)
+	}
+	got, _ := fingerprints.Get(HashSHA256)
+	if got.Sum != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" || got.Size != 3 {
+		t.Errorf("отпечаток = %+v", got)
+	}
+	if _, err := HashFile(path + ".missing"); err == nil {
+		t.Error("ожидалась ошибка для отсутствующего файла")
+	}
+}
@@ -0,0 +1,12 @@
+package excel
+
+import "sync/atomic"
+
+// openHandles количество открытых и еще не закрытых Reader/Writer/StreamWriter
+var openHandles atomic.Int64
+
+// OpenHandles возвращает количество открытых Reader/Writer
+// Используется для обнаружения утечек дескрипторов файлов
+func OpenHandles() int64 {
+	return openHandles.Load()
+}
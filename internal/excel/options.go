@@ -0,0 +1,130 @@
+package excel
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+)
+
+// LargeFileSize размер файла на диске, начиная с которого книга считается необычно большой
+// Для таких книг увеличивается предел распаковки, а при объединении выводится предупреждение
+const LargeFileSize = 100 << 20
+
+// largeFileUnzipRatio во сколько раз распакованная большая книга может превышать файл на диске
+// Разметка листов сжимается в десятки раз; запас нужен для выгрузок с одним огромным листом
+const largeFileUnzipRatio = 200
+
+// ReaderOptions параметры открытия книги, соответствуют excelize.Options
+// Нулевые значения - ограничения excelize по умолчанию
+type ReaderOptions struct {
+	UnzipSizeLimit    int64  // Наибольший размер распакованной книги в байтах (0 - около 16 ГБ)
+	UnzipXMLSizeLimit int64  // Листы больше этого размера распаковываются во временный файл, а не в память (0 - 16 МБ)
+	TmpDir            string // Каталог временных файлов распакованных листов (пусто - системный)
+}
+
+// RowsOptions параметры чтения строк листа в Reader.GetRows
+type RowsOptions struct {
+	// RawCellFallback перечитывает пустые ячейки без применения формата: некоторые форматы
+	// (например, ";;;") скрывают значение, и ячейка читается пустой. Каждая пустая ячейка
+	// читается отдельно, поэтому чтение заметно медленнее
+	RawCellFallback bool
+}
+
+// OptionsForSize возвращает параметры открытия файла размером size байт на диске
+// Для необычно больших файлов предел распаковки растет вместе с размером файла
+func OptionsForSize(size int64) ReaderOptions {
+	if size < LargeFileSize {
+		return ReaderOptions{}
+	}
+	return ReaderOptions{UnzipSizeLimit: max(size*largeFileUnzipRatio, excelize.UnzipSizeLimit)}
+}
+
+// IsLargeFile сообщает, что файл path необычно большой, и возвращает его размер в байтах
+func IsLargeFile(path string) (int64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	return info.Size(), info.Size() >= LargeFileSize
+}
+
+// gzipExt расширение сжатых gzip выгрузок, например file.xlsx.gz
+const gzipExt = ".gz"
+
+// IsGzipPath сообщает, что файл path сжат gzip (оканчивается на .gz)
+func IsGzipPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), gzipExt)
+}
+
+// WorkbookExt возвращает расширение книги path без суффикса .gz: "file.xlsx.gz" - ".xlsx"
+func WorkbookExt(path string) string {
+	return filepath.Ext(TrimGzipExt(path))
+}
+
+// TrimGzipExt убирает из пути суффикс .gz: "file.xlsx.gz" - "file.xlsx"
+func TrimGzipExt(path string) string {
+	if IsGzipPath(path) {
+		return path[:len(path)-len(gzipExt)]
+	}
+	return path
+}
+
+// excelizeOptions преобразует параметры в excelize.Options
+func (o ReaderOptions) excelizeOptions() excelize.Options {
+	return excelize.Options{
+		UnzipSizeLimit:    o.UnzipSizeLimit,
+		UnzipXMLSizeLimit: o.UnzipXMLSizeLimit,
+		TmpDir:            o.TmpDir,
+	}
+}
+
+// unzipLimit возвращает действующий предел распаковки книги в байтах
+func (o ReaderOptions) unzipLimit() int64 {
+	if o.UnzipSizeLimit > 0 {
+		return o.UnzipSizeLimit
+	}
+	return excelize.UnzipSizeLimit
+}
+
+// openWorkbook открывает книгу path с параметрами opts и возвращает отпечатки ее содержимого
+// Отпечатки считаются по мере чтения файла, без повторного чтения. Книга, сжатая gzip
+// (file.xlsx.gz), распаковывается в памяти по мере чтения; отпечатки считаются по файлу на диске.
+// Превышение предела распаковки возвращается как ErrCodeFileTooLarge, а не как
+// ошибка чтения: файл цел, но слишком велик
+func openWorkbook(path string, opts ReaderOptions) (*excelize.File, Fingerprints, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, nil, apperrors.NewFileReadError(path, err)
+	}
+	defer file.Close()
+
+	hashes := newFingerprinter()
+	var content io.Reader = io.TeeReader(file, hashes)
+	if IsGzipPath(path) {
+		unzipped, err := gzip.NewReader(content)
+		if err != nil {
+			return nil, nil, apperrors.NewFileReadError(path, err)
+		}
+		defer unzipped.Close()
+		content = unzipped
+	}
+
+	f, err := excelize.OpenReader(content, opts.excelizeOptions())
+	if err != nil {
+		// excelize не экспортирует эту ошибку, поэтому она распознается по тексту
+		if strings.Contains(err.Error(), "unzip size exceeds") {
+			return nil, nil, apperrors.NewFileTooLargeError(path, opts.unzipLimit(), err)
+		}
+		return nil, nil, apperrors.NewFileReadError(path, err)
+	}
+	// Как в excelize.OpenFile: по пути определяется тип книги при сохранении
+	// Сжатая книга сохраняется как обычная, без суффикса .gz
+	f.Path = TrimGzipExt(path)
+	return f, hashes.fingerprints(), nil
+}
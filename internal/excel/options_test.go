@@ -0,0 +1,151 @@
+package excel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// TestOpenOversizedWorkbook тестирует открытие книги, превышающей пределы распаковки
+func TestOpenOversizedWorkbook(t *testing.T) {
+	const rows = 20000
+	path := filepath.Join(t.TempDir(), "oversized.xlsx")
+	writeDataWorkbook(t, path, exceltest.BenchRows(rows, 10))
+
+	t.Run("превышен предел распаковки", func(t *testing.T) {
+		_, err := NewReaderWithOptions(path, ReaderOptions{UnzipSizeLimit: 1 << 20, UnzipXMLSizeLimit: 1 << 20})
+		if !errors.Is(err, apperrors.ErrFileTooLarge) {
+			t.Fatalf("ошибка = %v, ожидалась ErrCodeFileTooLarge", err)
+		}
+		if errors.Is(err, apperrors.ErrFileRead) || errors.Is(err, apperrors.ErrFileCorrupted) {
+			t.Errorf("большой файл описан как поврежденный: %v", err)
+		}
+	})
+
+	t.Run("лист распаковывается во временный файл", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		reader, err := NewReaderWithOptions(path, ReaderOptions{UnzipSizeLimit: 1 << 30, UnzipXMLSizeLimit: 64 << 10, TmpDir: tmpDir})
+		if err != nil {
+			t.Fatalf("не удалось открыть файл: %v", err)
+		}
+		if entries, _ := os.ReadDir(tmpDir); len(entries) == 0 {
+			t.Error("лист больше UnzipXMLSizeLimit не распакован во временный файл")
+		}
+
+		data, err := reader.GetRows("Data")
+		if err != nil {
+			t.Fatalf("не удалось прочитать лист: %v", err)
+		}
+		if len(data) != rows {
+			t.Errorf("прочитано %d строк, ожидалось %d", len(data), rows)
+		}
+
+		reader.Close()
+		if entries, _ := os.ReadDir(tmpDir); len(entries) != 0 {
+			t.Errorf("временные файлы не удалены после Close: %d", len(entries))
+		}
+	})
+}
+
+// TestOptionsForSize тестирует выбор параметров открытия по размеру файла
+func TestOptionsForSize(t *testing.T) {
+	tests := []struct {
+		name string
+		size int64
+		want int64
+	}{
+		{"обычный файл", 5 << 20, 0},
+		{"чуть меньше порога", LargeFileSize - 1, 0},
+		{"порог", LargeFileSize, LargeFileSize * largeFileUnzipRatio},
+		{"очень большой файл", 1 << 30, (1 << 30) * largeFileUnzipRatio},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OptionsForSize(tt.size)
+			if got.UnzipSizeLimit != tt.want {
+				t.Errorf("OptionsForSize(%d).UnzipSizeLimit = %d, ожидалось %d", tt.size, got.UnzipSizeLimit, tt.want)
+			}
+			// Предел для больших файлов не меньше предела excelize по умолчанию
+			if tt.want != 0 && got.unzipLimit() < excelize.UnzipSizeLimit {
+				t.Errorf("предел распаковки %d меньше предела по умолчанию", got.unzipLimit())
+			}
+		})
+	}
+}
+
+// TestOpenGzipWorkbook тестирует чтение книги, сжатой gzip (file.xlsx.gz)
+func TestOpenGzipWorkbook(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "export.xlsx.gz")
+	want := [][]string{{"Артикул", "Цена"}, {"A1", "100"}}
+	writeDataWorkbook(t, path, want)
+
+	reader, err := NewReader(path)
+	if err != nil {
+		t.Fatalf("не удалось открыть сжатую книгу: %v", err)
+	}
+	defer reader.Close()
+	rows, err := reader.GetRows("Data")
+	if err != nil {
+		t.Fatalf("не удалось прочитать лист: %v", err)
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("строки = %q, ожидалось %q", rows, want)
+	}
+
+	// Отпечаток относится к файлу на диске, как у HashFile
+	onDisk, err := HashFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reader.Fingerprints().Get(""); got != onDisk[DefaultHashAlgorithm] {
+		t.Errorf("отпечаток = %+v, у файла %+v", got, onDisk[DefaultHashAlgorithm])
+	}
+
+	// Копия книги сохраняется как обычная xlsx
+	writer, err := NewWriterFromFile(path)
+	if err != nil {
+		t.Fatalf("не удалось открыть сжатую книгу для записи: %v", err)
+	}
+	defer writer.Close()
+	if writer.GetFile().Path != filepath.Join(dir, "export.xlsx") {
+		t.Errorf("путь книги = %s", writer.GetFile().Path)
+	}
+
+	// Файл .gz, не сжатый gzip, считается ошибкой чтения
+	broken := filepath.Join(dir, "broken.xlsx.gz")
+	if err := os.WriteFile(broken, []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewReader(broken); !errors.Is(err, apperrors.ErrFileRead) {
+		t.Errorf("ошибка = %v, ожидалась ErrFileRead", err)
+	}
+}
+
+func TestWorkbookExt(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"file.xlsx", ".xlsx"},
+		{"dir/file.xlsx.gz", ".xlsx"},
+		{"FILE.XLSX.GZ", ".XLSX"},
+		{"archive.gz", ""},
+		{"notes.txt", ".txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := WorkbookExt(tt.path); got != tt.want {
+				t.Errorf("WorkbookExt(%q) = %q, ожидалось %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
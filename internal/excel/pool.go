@@ -0,0 +1,176 @@
+package excel
+
+import (
+	"container/list"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultReaderPoolSize количество книг, которые пул держит открытыми по умолчанию
+// Открытая книга занимает память порядка размера распакованного файла
+const DefaultReaderPoolSize = 4
+
+// ReaderPool переиспользует открытые Reader в пределах сеанса работы с файлами
+// Один и тот же файл проверяется при добавлении, перед объединением и при объединении;
+// пул открывает и разбирает его один раз. Открытыми остаются не более size давно не
+// использованных книг (LRU). Книга открывается заново, если файл изменился (время
+// изменения или размер). Методы безопасны для одновременного вызова
+type ReaderPool struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*poolEntry
+	lru     *list.List // Свободные и занятые книги пула, в начале - использованные последними
+	opens   int
+
+	open func(path string) (*Reader, error) // Открытие книги; в тестах подменяется
+}
+
+// poolEntry книга пула
+type poolEntry struct {
+	path     string
+	reader   *Reader
+	modTime  time.Time
+	fileSize int64
+	borrowed bool // Книга выдана и не возвращена
+	stale    bool // Файл изменился или пул закрыт: книга закрывается при возврате
+	elem     *list.Element
+}
+
+// NewReaderPool создает пул, держащий открытыми не более size книг (size < 1 - DefaultReaderPoolSize)
+func NewReaderPool(size int) *ReaderPool {
+	if size < 1 {
+		size = DefaultReaderPoolSize
+	}
+	return &ReaderPool{
+		size:    size,
+		entries: make(map[string]*poolEntry),
+		lru:     list.New(),
+		open:    NewReader,
+	}
+}
+
+// Borrow возвращает Reader файла path и функцию его возврата в пул
+// Reader нельзя закрывать напрямую: после работы вызывается release, повторный вызов
+// release ничего не делает. Книга выдается одному пользователю: если она уже занята,
+// открывается отдельная книга вне пула. Для nil-пула каждый вызов открывает файл заново
+func (p *ReaderPool) Borrow(path string) (*Reader, func(), error) {
+	if p == nil {
+		reader, err := NewReader(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return reader, func() { reader.Close() }, nil
+	}
+
+	// Отсутствующий файл проверяет NewReader и возвращает ошибку с нужным кодом
+	info, statErr := os.Stat(path)
+
+	p.mu.Lock()
+	if entry, ok := p.entries[path]; ok {
+		if statErr == nil && entry.modTime.Equal(info.ModTime()) && entry.fileSize == info.Size() {
+			if !entry.borrowed {
+				entry.borrowed = true
+				p.lru.MoveToFront(entry.elem)
+				p.mu.Unlock()
+				return entry.reader, p.releaseFunc(entry), nil
+			}
+		} else {
+			p.removeLocked(entry)
+		}
+	}
+	p.opens++
+	p.mu.Unlock()
+
+	reader, err := p.open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Книга уже в пуле (занята) или файл не удалось проверить: книга только для этого вызова
+	if _, exists := p.entries[path]; exists || statErr != nil {
+		return reader, func() { reader.Close() }, nil
+	}
+
+	entry := &poolEntry{
+		path:     path,
+		reader:   reader,
+		modTime:  info.ModTime(),
+		fileSize: info.Size(),
+		borrowed: true,
+	}
+	entry.elem = p.lru.PushFront(entry)
+	p.entries[path] = entry
+	p.evictLocked()
+	return reader, p.releaseFunc(entry), nil
+}
+
+// releaseFunc возвращает функцию возврата книги entry в пул
+func (p *ReaderPool) releaseFunc(entry *poolEntry) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			entry.borrowed = false
+			if entry.stale {
+				entry.reader.Close()
+				return
+			}
+			p.evictLocked()
+		})
+	}
+}
+
+// removeLocked убирает книгу из пула; занятая книга закрывается при возврате
+func (p *ReaderPool) removeLocked(entry *poolEntry) {
+	delete(p.entries, entry.path)
+	p.lru.Remove(entry.elem)
+	entry.stale = true
+	if !entry.borrowed {
+		entry.reader.Close()
+	}
+}
+
+// evictLocked закрывает давно не использованные свободные книги сверх размера пула
+func (p *ReaderPool) evictLocked() {
+	for elem := p.lru.Back(); elem != nil && len(p.entries) > p.size; {
+		entry := elem.Value.(*poolEntry)
+		elem = elem.Prev()
+		if !entry.borrowed {
+			p.removeLocked(entry)
+		}
+	}
+}
+
+// CloseAll закрывает все книги пула, например по завершении объединения
+// Занятые книги закрываются при возврате; пул можно использовать дальше
+func (p *ReaderPool) CloseAll() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, entry := range p.entries {
+		p.removeLocked(entry)
+	}
+}
+
+// Opens возвращает, сколько раз пул открывал файлы
+func (p *ReaderPool) Opens() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.opens
+}
+
+// Len возвращает количество книг в пуле
+func (p *ReaderPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.entries)
+}
@@ -0,0 +1,222 @@
+package excel
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// TestReaderPoolReuse тестирует, что файл открывается один раз на несколько операций
+func TestReaderPoolReuse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.xlsx")
+	writeDataWorkbook(t, path, exceltest.BenchRows(5, 3))
+	before := OpenHandles()
+
+	pool := NewReaderPool(2)
+	first, release, err := pool.Borrow(path)
+	if err != nil {
+		t.Fatalf("Borrow() error = %v", err)
+	}
+	release()
+	release() // Повторный возврат ничего не делает
+
+	second, release, err := pool.Borrow(path)
+	if err != nil {
+		t.Fatalf("Borrow() error = %v", err)
+	}
+	if second != first {
+		t.Error("книга открыта повторно вместо выдачи из пула")
+	}
+	release()
+
+	if pool.Opens() != 1 {
+		t.Errorf("Opens() = %d, ожидалось 1", pool.Opens())
+	}
+
+	pool.CloseAll()
+	if pool.Len() != 0 || OpenHandles() != before {
+		t.Errorf("после CloseAll в пуле %d книг, открыто %d (ожидалось %d)", pool.Len(), OpenHandles(), before)
+	}
+}
+
+// TestReaderPoolInvalidation тестирует повторное открытие измененного файла
+func TestReaderPoolInvalidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.xlsx")
+	writeDataWorkbook(t, path, exceltest.BenchRows(5, 3))
+	before := OpenHandles()
+
+	pool := NewReaderPool(2)
+	defer pool.CloseAll()
+
+	reader, release, err := pool.Borrow(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	writeDataWorkbook(t, path, exceltest.BenchRows(8, 3))
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, release, err := pool.Borrow(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	if changed == reader {
+		t.Fatal("выдана книга, открытая до изменения файла")
+	}
+	if pool.Opens() != 2 {
+		t.Errorf("Opens() = %d, ожидалось 2", pool.Opens())
+	}
+	rows, err := changed.GetRows("Data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 8 {
+		t.Errorf("строк = %d, ожидалось 8", len(rows))
+	}
+	if OpenHandles() != before+1 {
+		t.Errorf("открыто книг %d, ожидалось %d: прежняя книга не закрыта", OpenHandles(), before+1)
+	}
+}
+
+// TestReaderPoolEviction тестирует закрытие давно не использованных книг сверх размера пула
+func TestReaderPoolEviction(t *testing.T) {
+	dir := t.TempDir()
+	paths := make([]string, 3)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, string(rune('a'+i))+".xlsx")
+		writeDataWorkbook(t, paths[i], exceltest.BenchRows(2, 3))
+	}
+	before := OpenHandles()
+
+	pool := NewReaderPool(2)
+	defer pool.CloseAll()
+	for _, path := range append(paths, paths[2]) {
+		_, release, err := pool.Borrow(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+
+	if pool.Len() != 2 || OpenHandles() != before+2 {
+		t.Errorf("в пуле %d книг, открыто %d; ожидалось 2 и %d", pool.Len(), OpenHandles(), before+2)
+	}
+	if pool.Opens() != 3 {
+		t.Errorf("Opens() = %d, ожидалось 3", pool.Opens())
+	}
+
+	// Первый файл вытеснен и открывается заново
+	if _, release, err := pool.Borrow(paths[0]); err != nil {
+		t.Fatal(err)
+	} else {
+		release()
+	}
+	if pool.Opens() != 4 {
+		t.Errorf("Opens() = %d, ожидалось 4", pool.Opens())
+	}
+}
+
+// TestReaderPoolBorrowed тестирует выдачу занятой книги и закрытие пула до возврата
+func TestReaderPoolBorrowed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.xlsx")
+	writeDataWorkbook(t, path, exceltest.BenchRows(3, 3))
+	before := OpenHandles()
+
+	pool := NewReaderPool(2)
+	first, releaseFirst, err := pool.Borrow(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, releaseSecond, err := pool.Borrow(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatal("занятая книга выдана второму пользователю")
+	}
+	releaseSecond()
+
+	pool.CloseAll()
+	if _, err := first.GetRows("Data"); err != nil {
+		t.Errorf("книга закрыта до возврата: %v", err)
+	}
+	releaseFirst()
+
+	if OpenHandles() != before {
+		t.Errorf("открыто книг %d, ожидалось %d", OpenHandles(), before)
+	}
+}
+
+// TestReaderPoolConcurrent тестирует одновременную выдачу и возврат книг
+func TestReaderPoolConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	paths := make([]string, 3)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, string(rune('a'+i))+".xlsx")
+		writeDataWorkbook(t, paths[i], exceltest.BenchRows(4, 3))
+	}
+	before := OpenHandles()
+
+	pool := NewReaderPool(2)
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				reader, release, err := pool.Borrow(paths[(worker+i)%len(paths)])
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if rows, err := reader.GetRows("Data"); err != nil || len(rows) != 4 {
+					t.Errorf("GetRows() = %d строк, ошибка %v", len(rows), err)
+				}
+				release()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if pool.Len() > 2 {
+		t.Errorf("в пуле %d книг, ожидалось не более 2", pool.Len())
+	}
+	pool.CloseAll()
+	if OpenHandles() != before {
+		t.Errorf("открыто книг %d, ожидалось %d", OpenHandles(), before)
+	}
+}
+
+// TestNilReaderPool тестирует открытие файла без пула
+func TestNilReaderPool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.xlsx")
+	writeDataWorkbook(t, path, exceltest.BenchRows(2, 3))
+	before := OpenHandles()
+
+	var pool *ReaderPool
+	reader, release, err := pool.Borrow(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reader.GetFilePath() != path {
+		t.Errorf("GetFilePath() = %s", reader.GetFilePath())
+	}
+	release()
+	pool.CloseAll()
+
+	if OpenHandles() != before {
+		t.Errorf("открыто книг %d, ожидалось %d", OpenHandles(), before)
+	}
+	if _, _, err := pool.Borrow(filepath.Join(t.TempDir(), "missing.xlsx")); err == nil {
+		t.Error("ожидалась ошибка для отсутствующего файла")
+	}
+}
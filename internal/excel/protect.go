@@ -0,0 +1,105 @@
+package excel
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// protectionStyleKey стиль ячейки и требуемая блокировка для кэша производных стилей
+type protectionStyleKey struct {
+	style  int
+	locked bool
+}
+
+// ProtectHeaderRows защищает от изменения строки 1..headerRows листа и его листов-продолжений
+// Ячейки данных в первых columns столбцах получают копию своего стиля с признаком "не заблокирована",
+// поэтому их можно редактировать, а оформление сохраняется. Сортировка и автофильтр
+// защищенного листа разрешены. Пустой password - защита без пароля.
+// Вызывается после записи данных: строки, записанные позже, остаются заблокированными
+func (w *Writer) ProtectHeaderRows(sheetName string, headerRows, columns int, password string) error {
+	if headerRows < 1 || columns < 1 {
+		return nil
+	}
+
+	parts, lastRows := w.sheetParts(sheetName)
+	styles := make(map[protectionStyleKey]int)
+
+	for i, part := range parts {
+		if err := w.setCellsLocked(part, headerRows, columns, lastRows[i], styles); err != nil {
+			return err
+		}
+		if err := w.file.ProtectSheet(part, &excelize.SheetProtectionOptions{
+			Password:            password,
+			AutoFilter:          true,
+			Sort:                true,
+			FormatColumns:       true,
+			SelectLockedCells:   true,
+			SelectUnlockedCells: true,
+		}); err != nil {
+			return fmt.Errorf("не удалось защитить лист '%s': %w", part, err)
+		}
+	}
+	return nil
+}
+
+// setCellsLocked блокирует ячейки строк шапки и снимает блокировку с ячеек данных до строки lastRow
+// styles кэширует производные стили, чтобы не создавать их для каждой ячейки
+func (w *Writer) setCellsLocked(sheetName string, headerRows, columns, lastRow int, styles map[protectionStyleKey]int) error {
+	for row := 1; row <= lastRow; row++ {
+		locked := row <= headerRows
+		for col := 1; col <= columns; col++ {
+			cell, err := excelize.CoordinatesToCellName(col, row)
+			if err != nil {
+				return err
+			}
+			style, err := w.file.GetCellStyle(sheetName, cell)
+			if err != nil {
+				return fmt.Errorf("не удалось прочитать стиль ячейки %s: %w", cell, err)
+			}
+			// Стиль по умолчанию уже заблокирован
+			if style == 0 && locked {
+				continue
+			}
+
+			key := protectionStyleKey{style: style, locked: locked}
+			id, ok := styles[key]
+			if !ok {
+				if id, err = w.protectionStyle(style, locked); err != nil {
+					return err
+				}
+				styles[key] = id
+			}
+			if id == style {
+				continue
+			}
+			if err := w.file.SetCellStyle(sheetName, cell, cell, id); err != nil {
+				return fmt.Errorf("не удалось применить стиль к ячейке %s: %w", cell, err)
+			}
+		}
+	}
+	return nil
+}
+
+// protectionStyle создает копию стиля style с заданной блокировкой ячейки
+func (w *Writer) protectionStyle(style int, locked bool) (int, error) {
+	definition := &excelize.Style{}
+	if style != 0 {
+		existing, err := w.file.GetStyle(style)
+		if err != nil {
+			return 0, fmt.Errorf("не удалось прочитать стиль ячейки: %w", err)
+		}
+		definition = existing
+	}
+	// Без настроек защиты ячейка заблокирована
+	if current := definition.Protection == nil || definition.Protection.Locked; current == locked {
+		return style, nil
+	}
+
+	definition.Protection = &excelize.Protection{Locked: locked}
+	id, err := w.file.NewStyle(definition)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось создать стиль защиты: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,131 @@
+package excel
+
+import (
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sheetXML возвращает XML листа номер n сохраненной книги
+func sheetXML(t *testing.T, path string, n int) string {
+	t.Helper()
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	name := "xl/worksheets/sheet" + string(rune('0'+n)) + ".xml"
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	t.Fatalf("в книге нет %s", name)
+	return ""
+}
+
+func TestProtectHeaderRows(t *testing.T) {
+	writer := NewWriter()
+	defer writer.Close()
+	writer.rowLimit = 4
+	writer.SetAutoSplit(true)
+	if err := writer.CreateSheet("Data"); err != nil {
+		t.Fatal(err)
+	}
+	header := [][]string{{"Отчет"}, {"Артикул", "Цена"}}
+	writer.SetHeaderRows("Data", header)
+	if err := writer.WriteRows("Data", 1, header); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteRows("Data", 3, [][]string{{"A1", "100"}, {"A2", "200"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Подпись с оформлением и строка на листе-продолжении
+	if err := writer.WriteLabelRow("Data", 5, "### Файл: b.xlsx"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteRow("Data", 6, []string{"B1", "300"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.ProtectHeaderRows("Data", 2, 2, "secret"); err != nil {
+		t.Fatalf("ProtectHeaderRows() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "protected.xlsx")
+	if err := writer.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Защита включена на листе и его продолжении; сортировка и автофильтр разрешены
+	for i, sheet := range []string{"Data", "Data_2"} {
+		xml := sheetXML(t, path, i+1)
+		if !strings.Contains(xml, `<sheetProtection`) || !strings.Contains(xml, `sheet="true"`) {
+			t.Fatalf("лист %s не защищен", sheet)
+		}
+		// В sheetProtection true означает запрет действия
+		for _, attr := range []string{`autoFilter="false"`, `sort="false"`} {
+			if !strings.Contains(xml, attr) {
+				t.Errorf("лист %s: ожидалось %s", sheet, attr)
+			}
+		}
+		if !strings.Contains(xml, `password="`) {
+			t.Errorf("лист %s: пароль не задан", sheet)
+		}
+	}
+
+	saved, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer saved.Close()
+
+	tests := []struct {
+		sheet, cell string
+		locked      bool
+	}{
+		{"Data", "A1", true},
+		{"Data", "B2", true},
+		{"Data", "A3", false},
+		{"Data", "B4", false},
+		{"Data_2", "A2", true},
+		{"Data_2", "A3", false},
+		{"Data_2", "B4", false},
+	}
+	for _, tt := range tests {
+		idx, err := saved.GetCellStyle(tt.sheet, tt.cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		style, err := saved.GetStyle(idx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locked := style.Protection == nil || style.Protection.Locked
+		if locked != tt.locked {
+			t.Errorf("%s!%s заблокирована = %v, ожидалось %v", tt.sheet, tt.cell, locked, tt.locked)
+		}
+	}
+
+	// Оформление подписи сохраняется в разблокированной ячейке
+	idx, _ := saved.GetCellStyle("Data_2", "A3")
+	style, _ := saved.GetStyle(idx)
+	if style.Font == nil || !style.Font.Bold {
+		t.Errorf("оформление подписи потеряно: %+v", style.Font)
+	}
+}
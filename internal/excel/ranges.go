@@ -0,0 +1,141 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// DataRange именованный диапазон или таблица Excel, отмечающие область данных листа
+// Строки и столбцы 1-based, границы включительно
+type DataRange struct {
+	Name      string // Имя диапазона или таблицы
+	Sheet     string // Лист, на котором находится диапазон
+	FirstRow  int
+	LastRow   int
+	FirstCol  int
+	LastCol   int
+	Table     bool // Диапазон - таблица Excel
+	HasHeader bool // Первая строка диапазона - заголовки (у именованного диапазона всегда)
+}
+
+// HeaderRow возвращает строку заголовков области данных: первую строку диапазона,
+// а для таблицы без строки заголовков - строку над ней (0 - заголовков нет)
+func (d DataRange) HeaderRow() int {
+	if d.HasHeader {
+		return d.FirstRow
+	}
+	return d.FirstRow - 1
+}
+
+// String возвращает диапазон для сообщений: "Шаблон!A4:F100"
+func (d DataRange) String() string {
+	first, _ := excelize.CoordinatesToCellName(d.FirstCol, d.FirstRow)
+	last, _ := excelize.CoordinatesToCellName(d.LastCol, d.LastRow)
+	return fmt.Sprintf("%s!%s:%s", d.Sheet, first, last)
+}
+
+// GetDataRanges возвращает именованные диапазоны книги, ссылающиеся на одну область листа,
+// и таблицы всех листов. Встроенные имена Excel (область печати, автофильтр) и имена,
+// ссылающиеся на формулы, константы или несколько областей, пропускаются
+func (r *Reader) GetDataRanges() ([]DataRange, error) {
+	var ranges []DataRange
+	for _, name := range r.file.GetDefinedName() {
+		if strings.HasPrefix(name.Name, "_xlnm.") {
+			continue
+		}
+		dataRange, err := ParseRangeRef(name.RefersTo)
+		if err != nil {
+			continue
+		}
+		// Имя листа в ссылке может отличаться регистром
+		sheet, ok := r.findSheetName(dataRange.Sheet)
+		if !ok {
+			continue
+		}
+		dataRange.Name = name.Name
+		dataRange.Sheet = sheet
+		dataRange.HasHeader = true
+		ranges = append(ranges, dataRange)
+	}
+
+	for _, sheet := range r.file.GetSheetList() {
+		tables, err := r.file.GetTables(sheet)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать таблицы листа '%s': %w", sheet, err)
+		}
+		for _, table := range tables {
+			dataRange, err := ParseRangeRef(table.Range)
+			if err != nil {
+				continue
+			}
+			dataRange.Name = table.Name
+			dataRange.Sheet = sheet
+			dataRange.Table = true
+			dataRange.HasHeader = table.ShowHeaderRow == nil || *table.ShowHeaderRow
+			ranges = append(ranges, dataRange)
+		}
+	}
+	return ranges, nil
+}
+
+// FindDataRange ищет именованный диапазон или таблицу name без учета регистра
+// При совпадении имени диапазона и таблицы возвращается таблица
+func (r *Reader) FindDataRange(name string) (DataRange, bool, error) {
+	ranges, err := r.GetDataRanges()
+	if err != nil {
+		return DataRange{}, false, err
+	}
+	var found DataRange
+	ok := false
+	for _, dataRange := range ranges {
+		if strings.EqualFold(dataRange.Name, strings.TrimSpace(name)) && (!ok || dataRange.Table) {
+			found, ok = dataRange, true
+		}
+	}
+	return found, ok, nil
+}
+
+// ParseRangeRef разбирает ссылку на область "Лист!$A$1:$D$10", "'Лист 1'!A1:D10" или "A1:D10"
+// Ссылка на одну ячейку - область из одной ячейки. Имя листа возвращается без кавычек
+func ParseRangeRef(ref string) (DataRange, error) {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "=")
+
+	var dataRange DataRange
+	if i := strings.LastIndex(ref, "!"); i >= 0 {
+		sheet := ref[:i]
+		if len(sheet) >= 2 && strings.HasPrefix(sheet, "'") && strings.HasSuffix(sheet, "'") {
+			sheet = strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+		}
+		dataRange.Sheet = sheet
+		ref = ref[i+1:]
+	}
+
+	first, last, found := strings.Cut(strings.ReplaceAll(ref, "$", ""), ":")
+	if !found {
+		last = first
+	}
+	firstCol, firstRow, err := excelize.CellNameToCoordinates(first)
+	if err != nil {
+		return DataRange{}, fmt.Errorf("некорректная ссылка на диапазон '%s': %w", ref, err)
+	}
+	lastCol, lastRow, err := excelize.CellNameToCoordinates(last)
+	if err != nil {
+		return DataRange{}, fmt.Errorf("некорректная ссылка на диапазон '%s': %w", ref, err)
+	}
+
+	dataRange.FirstRow, dataRange.LastRow = min(firstRow, lastRow), max(firstRow, lastRow)
+	dataRange.FirstCol, dataRange.LastCol = min(firstCol, lastCol), max(firstCol, lastCol)
+	return dataRange, nil
+}
+
+// findSheetName возвращает имя листа книги, совпадающее с name без учета регистра и пробелов
+func (r *Reader) findSheetName(name string) (string, bool) {
+	for _, sheet := range r.file.GetSheetList() {
+		if normalizeSheetName(sheet) == normalizeSheetName(name) {
+			return sheet, true
+		}
+	}
+	return "", false
+}
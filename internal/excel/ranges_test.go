@@ -0,0 +1,135 @@
+package excel
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// writeRangesWorkbook создает книгу с именованным диапазоном на листе "Шаблон"
+// и таблицей на листе "Видео"
+func writeRangesWorkbook(t *testing.T) string {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", "Шаблон"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.NewSheet("Видео"); err != nil {
+		t.Fatal(err)
+	}
+	rows := map[string][]any{
+		"A1": {"Шаблон для поставщика"},
+		"A4": {"Артикул", "Бренд", "Цена"},
+		"A5": {"A1", "Shuzzi", 100},
+	}
+	for cell, values := range rows {
+		if err := f.SetSheetRow("Шаблон", cell, &values); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.SetSheetRow("Видео", cell, &values); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SetDefinedName(&excelize.DefinedName{Name: "ШаблонДанные", RefersTo: "'Шаблон'!$A$4:$C$100"}); err != nil {
+		t.Fatal(err)
+	}
+	// Имя-константа не является областью листа
+	if err := f.SetDefinedName(&excelize.DefinedName{Name: "Ставка", RefersTo: "0.2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetDefinedName(&excelize.DefinedName{Name: "_xlnm.Print_Area", RefersTo: "'Шаблон'!$A$1:$C$5", Scope: "Шаблон"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.AddTable("Видео", &excelize.Table{Range: "B4:C5", Name: "ВидеоДанные"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "ranges.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFindDataRange(t *testing.T) {
+	reader, err := NewReader(writeRangesWorkbook(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	ranges, err := reader.GetDataRanges()
+	if err != nil {
+		t.Fatalf("GetDataRanges() error = %v", err)
+	}
+	if len(ranges) != 2 {
+		t.Fatalf("GetDataRanges() = %+v, ожидалось 2 диапазона", ranges)
+	}
+
+	tests := []struct {
+		name      string
+		want      DataRange
+		wantFound bool
+	}{
+		{"шаблонданные", DataRange{Name: "ШаблонДанные", Sheet: "Шаблон", FirstRow: 4, LastRow: 100, FirstCol: 1, LastCol: 3, HasHeader: true}, true},
+		{"ВидеоДанные", DataRange{Name: "ВидеоДанные", Sheet: "Видео", FirstRow: 4, LastRow: 5, FirstCol: 2, LastCol: 3, Table: true, HasHeader: true}, true},
+		{"Ставка", DataRange{}, false},
+		{"Нет", DataRange{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := reader.FindDataRange(tt.name)
+			if err != nil {
+				t.Fatalf("FindDataRange() error = %v", err)
+			}
+			if found != tt.wantFound || got != tt.want {
+				t.Errorf("FindDataRange() = %+v, %v, ожидалось %+v, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestParseRangeRef(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    DataRange
+		wantErr bool
+	}{
+		{"Шаблон!$A$4:$F$10", DataRange{Sheet: "Шаблон", FirstRow: 4, LastRow: 10, FirstCol: 1, LastCol: 6}, false},
+		{"='Лист ''1'''!B2:C3", DataRange{Sheet: "Лист '1'", FirstRow: 2, LastRow: 3, FirstCol: 2, LastCol: 3}, false},
+		{"D5:B2", DataRange{FirstRow: 2, LastRow: 5, FirstCol: 2, LastCol: 4}, false},
+		{"Лист1!C7", DataRange{Sheet: "Лист1", FirstRow: 7, LastRow: 7, FirstCol: 3, LastCol: 3}, false},
+		{"0.2", DataRange{}, true},
+		{"Лист1!#REF!", DataRange{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := ParseRangeRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRangeRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRangeRef() = %+v, ожидалось %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataRangeHeaderRow(t *testing.T) {
+	tests := []struct {
+		dataRange DataRange
+		want      int
+	}{
+		{DataRange{FirstRow: 4, HasHeader: true}, 4},
+		{DataRange{FirstRow: 4, Table: true}, 3},
+		{DataRange{FirstRow: 1, Table: true}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.dataRange.HeaderRow(); got != tt.want {
+			t.Errorf("%+v.HeaderRow() = %d, ожидалось %d", tt.dataRange, got, tt.want)
+		}
+	}
+}
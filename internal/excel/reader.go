@@ -3,7 +3,7 @@ package excel
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 
@@ -14,39 +14,64 @@ import (
 type Reader struct {
 	file *excelize.File
 	path string
+
+	fingerprints Fingerprints // Отпечатки содержимого файла на момент открытия
 }
 
 // NewReader создает новый Reader для указанного файла
+// Параметры открытия выбираются по размеру файла (см. OptionsForSize)
 func NewReader(path string) (*Reader, error) {
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	return NewReaderWithOptions(path, OptionsForSize(size))
+}
+
+// NewReaderWithOptions создает новый Reader для указанного файла с параметрами открытия opts
+func NewReaderWithOptions(path string, opts ReaderOptions) (*Reader, error) {
 	// Проверяем существование файла
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil, apperrors.NewFileNotFoundError(path)
 	}
 
-	// Проверяем расширение файла
-	ext := filepath.Ext(path)
+	// Проверяем расширение файла; сжатая gzip книга (file.xlsx.gz) распаковывается при открытии
+	ext := WorkbookExt(path)
 	if ext != ".xlsx" && ext != ".xlsm" {
 		return nil, apperrors.NewInvalidFormatError(path)
 	}
 
 	// Открываем файл
-	f, err := excelize.OpenFile(path)
+	f, fingerprints, err := openWorkbook(path, opts)
 	if err != nil {
-		return nil, apperrors.NewFileReadError(path, err)
+		return nil, err
 	}
 
+	openHandles.Add(1)
+
 	return &Reader{
-		file: f,
-		path: path,
+		file:         f,
+		path:         path,
+		fingerprints: fingerprints,
 	}, nil
 }
 
+// Fingerprints возвращает отпечатки содержимого файла, посчитанные при открытии
+func (r *Reader) Fingerprints() Fingerprints {
+	return r.fingerprints
+}
+
 // Close закрывает файл и освобождает ресурсы
+// Повторный вызов безопасен и ничего не делает
 func (r *Reader) Close() error {
-	if r.file != nil {
-		return r.file.Close()
+	if r.file == nil {
+		return nil
 	}
-	return nil
+
+	err := r.file.Close()
+	r.file = nil
+	openHandles.Add(-1)
+	return err
 }
 
 // GetSheetNames возвращает список всех листов в файле
@@ -54,6 +79,16 @@ func (r *Reader) GetSheetNames() []string {
 	return r.file.GetSheetList()
 }
 
+// GetActiveSheetName возвращает имя листа, активного при последнем сохранении файла
+func (r *Reader) GetActiveSheetName() (string, error) {
+	index := r.file.GetActiveSheetIndex()
+	name := r.file.GetSheetName(index)
+	if name == "" {
+		return "", fmt.Errorf("активный лист с индексом %d не найден", index)
+	}
+	return name, nil
+}
+
 // SheetExists проверяет существование листа
 func (r *Reader) SheetExists(sheetName string) bool {
 	for _, name := range r.GetSheetNames() {
@@ -64,8 +99,39 @@ func (r *Reader) SheetExists(sheetName string) bool {
 	return false
 }
 
+// FindSheetsByPattern возвращает листы, имя которых содержит substr, в порядке листов книги
+// Сравнение без учета регистра, пробелов по краям и количества пробелов внутри
+func (r *Reader) FindSheetsByPattern(substr string) ([]string, error) {
+	if strings.TrimSpace(substr) == "" {
+		return nil, fmt.Errorf("шаблон имени листа не задан")
+	}
+	return MatchSheetsByPattern(r.GetSheetNames(), substr), nil
+}
+
+// MatchSheetsByPattern отбирает из sheetNames имена, содержащие substr, как FindSheetsByPattern
+// Для пустого substr возвращает nil
+func MatchSheetsByPattern(sheetNames []string, substr string) []string {
+	pattern := normalizeSheetName(substr)
+	if pattern == "" {
+		return nil
+	}
+	var matches []string
+	for _, name := range sheetNames {
+		if strings.Contains(normalizeSheetName(name), pattern) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+// normalizeSheetName приводит имя листа к виду для сравнения (как core.NormalizeSheetName)
+func normalizeSheetName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
 // GetRows возвращает все строки указанного листа
-func (r *Reader) GetRows(sheetName string) ([][]string, error) {
+// Параметры чтения opts действуют только на этот вызов; без них - RowsOptions по умолчанию
+func (r *Reader) GetRows(sheetName string, opts ...RowsOptions) ([][]string, error) {
 	if !r.SheetExists(sheetName) {
 		return nil, apperrors.NewSheetNotFoundError(sheetName, r.path)
 	}
@@ -75,6 +141,47 @@ func (r *Reader) GetRows(sheetName string) ([][]string, error) {
 		return nil, fmt.Errorf("failed to read rows from sheet '%s': %w", sheetName, err)
 	}
 
+	if len(opts) > 0 && opts[0].RawCellFallback {
+		return r.fillRawCells(sheetName, rows)
+	}
+	return rows, nil
+}
+
+// fillRawCells заполняет пустые ячейки исходными значениями без форматирования
+// Проверяются ячейки до самого широкого столбца листа; строки в конце листа,
+// все значения которых скрыты, не восстанавливаются
+func (r *Reader) fillRawCells(sheetName string, rows [][]string) ([][]string, error) {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	for i, row := range rows {
+		for col := 0; col < width; col++ {
+			if col < len(row) && row[col] != "" {
+				continue
+			}
+
+			cell, err := excelize.CoordinatesToCellName(col+1, i+1)
+			if err != nil {
+				return nil, err
+			}
+			value, err := r.file.GetCellValue(sheetName, cell, excelize.Options{RawCellValue: true})
+			if err != nil {
+				return nil, fmt.Errorf("не удалось прочитать ячейку %s листа '%s': %w", cell, sheetName, err)
+			}
+			if value == "" {
+				continue
+			}
+
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			row[col] = value
+		}
+		rows[i] = row
+	}
+
 	return rows, nil
 }
 
@@ -148,6 +255,44 @@ func (r *Reader) GetCellValue(sheetName, cell string) (string, error) {
 	return value, nil
 }
 
+// GetRange возвращает значения прямоугольного диапазона листа от ячейки topLeft до bottomRight
+// включительно, например GetRange("Справочник", "B2", "D4") - блок 3×3.
+// Ячейки задаются в формате A1 (знаки $ допускаются). Результат всегда имеет размер диапазона:
+// ячейки за пределами заполненной части листа возвращаются пустыми строками
+func (r *Reader) GetRange(sheetName, topLeft, bottomRight string) ([][]string, error) {
+	left, top, err := excelize.CellNameToCoordinates(topLeft)
+	if err != nil {
+		return nil, fmt.Errorf("некорректная ячейка начала диапазона '%s': %w", topLeft, err)
+	}
+	right, bottom, err := excelize.CellNameToCoordinates(bottomRight)
+	if err != nil {
+		return nil, fmt.Errorf("некорректная ячейка конца диапазона '%s': %w", bottomRight, err)
+	}
+	if right < left || bottom < top {
+		return nil, fmt.Errorf("некорректный диапазон %s:%s: ячейка %s должна быть левее и выше %s",
+			topLeft, bottomRight, topLeft, bottomRight)
+	}
+
+	rows, err := r.GetRows(sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	block := make([][]string, bottom-top+1)
+	for i := range block {
+		block[i] = make([]string, right-left+1)
+		rowIdx := top - 1 + i
+		if rowIdx >= len(rows) {
+			continue
+		}
+		row := rows[rowIdx]
+		if left <= len(row) {
+			copy(block[i], row[left-1:min(right, len(row))])
+		}
+	}
+	return block, nil
+}
+
 // GetRowCount возвращает количество строк на листе
 func (r *Reader) GetRowCount(sheetName string) (int, error) {
 	rows, err := r.GetRows(sheetName)
@@ -181,15 +326,18 @@ func (r *Reader) ValidateStructure(sheetName string, headerRowNum int, baseHeade
 
 	// Проверяем количество заголовков
 	if len(headers) != len(baseHeaders) {
-		return fmt.Errorf("несовпадение количества столбцов на листе '%s': ожидается %d, получено %d",
-			sheetName, len(baseHeaders), len(headers))
+		return apperrors.NewStructureMismatchError(sheetName,
+			fmt.Sprintf("несовпадение количества столбцов на листе '%s': ожидается %d, получено %d",
+				sheetName, len(baseHeaders), len(headers)))
 	}
 
 	// Проверяем совпадение заголовков
 	for i, header := range headers {
 		if header != baseHeaders[i] {
-			return fmt.Errorf("несовпадение заголовка столбца %d на листе '%s': ожидается '%s', получено '%s'",
-				i+1, sheetName, baseHeaders[i], header)
+			return apperrors.NewStructureMismatchError(sheetName,
+				fmt.Sprintf("несовпадение заголовка столбца %d на листе '%s': ожидается '%s', получено '%s'",
+					i+1, sheetName, baseHeaders[i], header),
+				apperrors.WithContext("column", i+1))
 		}
 	}
 
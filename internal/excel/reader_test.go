@@ -1,8 +1,12 @@
 package excel
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/xuri/excelize/v2"
 )
 
 // Путь к тестовым файлам
@@ -344,3 +348,236 @@ func TestGetFilePath(t *testing.T) {
 		t.Errorf("Expected path %s, got %s", testFile, path)
 	}
 }
+
+// TestGetActiveSheetName тестирует определение активного листа
+func TestGetActiveSheetName(t *testing.T) {
+	tests := []struct {
+		name   string
+		active int
+		want   string
+	}{
+		{"первый лист", 0, "Инструкция"},
+		{"не первый лист", 2, "Товары"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "active.xlsx")
+			f := excelize.NewFile()
+			if err := f.SetSheetName("Sheet1", "Инструкция"); err != nil {
+				t.Fatal(err)
+			}
+			for _, sheet := range []string{"Справочник", "Товары"} {
+				if _, err := f.NewSheet(sheet); err != nil {
+					t.Fatal(err)
+				}
+			}
+			f.SetActiveSheet(tt.active)
+			if err := f.SaveAs(path); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			reader, err := NewReader(path)
+			if err != nil {
+				t.Fatalf("Failed to create reader: %v", err)
+			}
+			defer reader.Close()
+
+			got, err := reader.GetActiveSheetName()
+			if err != nil {
+				t.Fatalf("GetActiveSheetName failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetActiveSheetName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeHiddenValuesWorkbook создает лист Data, где формат ";;;" скрывает цену и комментарий второй строки
+func writeHiddenValuesWorkbook(t *testing.T, path string) {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", "Data"); err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{"Артикул", "Цена", "Комментарий"},
+		{"A1", 100, "видно"},
+		{"A2", 200, "скрыто"},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Data", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hidden := ";;;"
+	style, err := f.NewStyle(&excelize.Style{CustomNumFmt: &hidden})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetCellStyle("Data", "B3", "C3", style); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestGetRowsRawCellFallback тестирует восстановление значений, скрытых форматом ячейки
+func TestGetRowsRawCellFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hidden.xlsx")
+	writeHiddenValuesWorkbook(t, path)
+
+	tests := []struct {
+		name     string
+		fallback bool
+		want     string
+	}{
+		{"без перечитывания", false, "A2"},
+		{"с перечитыванием", true, "A2|200|скрыто"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(path)
+			if err != nil {
+				t.Fatalf("Failed to create reader: %v", err)
+			}
+			defer reader.Close()
+
+			rows, err := reader.GetRows("Data", RowsOptions{RawCellFallback: tt.fallback})
+			if err != nil {
+				t.Fatalf("GetRows failed: %v", err)
+			}
+			if len(rows) != 3 {
+				t.Fatalf("строк = %d, ожидалось 3: %q", len(rows), rows)
+			}
+			if got := strings.Join(rows[1], "|"); got != "A1|100|видно" {
+				t.Errorf("первая строка = %q", got)
+			}
+			if got := strings.Join(rows[2], "|"); got != tt.want {
+				t.Errorf("вторая строка = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetRange тестирует чтение прямоугольного диапазона ячеек
+func TestGetRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lookup.xlsx")
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", "Справочник"); err != nil {
+		t.Fatal(err)
+	}
+	// Таблица размеров B2:D4 внутри листа с пояснением сверху и примечанием справа
+	rows := map[string][]interface{}{
+		"A1": {"Таблица размеров"},
+		"B2": {"RU", "EU", "US"},
+		"B3": {38, 39, 6},
+		"B4": {40, 41, 8, "", "примечание"},
+	}
+	for cell, row := range rows {
+		if err := f.SetSheetRow("Справочник", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	reader, err := NewReader(path)
+	if err != nil {
+		t.Fatalf("не удалось открыть файл: %v", err)
+	}
+	defer reader.Close()
+
+	tests := []struct {
+		name        string
+		sheet       string
+		topLeft     string
+		bottomRight string
+		want        [][]string
+		wantErr     string
+	}{
+		{"блок 3×3", "Справочник", "B2", "D4", [][]string{{"RU", "EU", "US"}, {"38", "39", "6"}, {"40", "41", "8"}}, ""},
+		{"абсолютные ссылки", "Справочник", "$C$3", "$D$3", [][]string{{"39", "6"}}, ""},
+		{"выход за данные", "Справочник", "D4", "F5", [][]string{{"8", "", "примечание"}, {"", "", ""}}, ""},
+		{"вне данных", "Справочник", "X100", "Y100", [][]string{{"", ""}}, ""},
+		{"одна ячейка", "Справочник", "A1", "A1", [][]string{{"Таблица размеров"}}, ""},
+		{"обратный порядок", "Справочник", "D4", "B2", nil, "некорректный диапазон"},
+		{"некорректная ячейка", "Справочник", "B", "D4", nil, "некорректная ячейка начала"},
+		{"за пределами Excel", "Справочник", "A1", "XFE1", nil, "некорректная ячейка конца"},
+		{"нет листа", "Нет", "A1", "B2", nil, "E003"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reader.GetRange(tt.sheet, tt.topLeft, tt.bottomRight)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ошибка = %v, ожидалась %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ошибка: %v", err)
+			}
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("GetRange() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFindSheetsByPattern тестирует поиск листов по части имени
+func TestFindSheetsByPattern(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pattern.xlsx")
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", "Инструкция"); err != nil {
+		t.Fatal(err)
+	}
+	for _, sheet := range []string{"ПРАЙС  поставщика", "Справочник", "Старый прайс"} {
+		if _, err := f.NewSheet(sheet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	reader, err := NewReader(path)
+	if err != nil {
+		t.Fatalf("Failed to create reader: %v", err)
+	}
+	defer reader.Close()
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"прайс", []string{"ПРАЙС  поставщика", "Старый прайс"}},
+		{"  Прайс Поставщика ", []string{"ПРАЙС  поставщика"}},
+		{"СПРАВ", []string{"Справочник"}},
+		{"Остатки", nil},
+	}
+	for _, tt := range tests {
+		got, err := reader.FindSheetsByPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("FindSheetsByPattern(%q) error = %v", tt.pattern, err)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("FindSheetsByPattern(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+
+	if _, err := reader.FindSheetsByPattern("  "); err == nil {
+		t.Error("Expected error for empty pattern")
+	}
+}
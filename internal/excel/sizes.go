@@ -0,0 +1,192 @@
+package excel
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SheetSize размер листа: количество строк и наибольшее число ячеек в строке
+type SheetSize struct {
+	Rows    int
+	Columns int
+}
+
+// ScanSheetSizes быстро оценивает размеры листов файла без чтения значений ячеек
+// Разметка листов просматривается напрямую в архиве: строки и ячейки считаются по тегам
+// <row> и <c>, общие строки и стили не загружаются. Результат - по имени листа
+func ScanSheetSizes(filePath string) (map[string]SheetSize, error) {
+	archive, closeArchive, err := openArchive(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть файл %s: %w", filePath, err)
+	}
+	defer closeArchive()
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sheetPaths, err := worksheetPaths(files)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать структуру книги %s: %w", filePath, err)
+	}
+
+	sizes := make(map[string]SheetSize, len(sheetPaths))
+	for name, sheetPath := range sheetPaths {
+		file, ok := files[sheetPath]
+		if !ok {
+			continue
+		}
+		size, err := scanWorksheet(file)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось просмотреть лист %s: %w", name, err)
+		}
+		sizes[name] = size
+	}
+	return sizes, nil
+}
+
+// openArchive открывает книгу filePath как zip-архив и возвращает функцию его закрытия
+// Книга, сжатая gzip (file.xlsx.gz), распаковывается в память
+func openArchive(filePath string) (*zip.Reader, func() error, error) {
+	if !IsGzipPath(filePath) {
+		archive, err := zip.OpenReader(filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &archive.Reader, archive.Close, nil
+	}
+
+	file, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	unzipped, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unzipped.Close()
+	data, err := io.ReadAll(unzipped)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, err
+	}
+	return archive, func() error { return nil }, nil
+}
+
+// worksheetPaths возвращает пути разметки листов в архиве по именам листов
+func worksheetPaths(files map[string]*zip.File) (map[string]string, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeArchiveXML(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeArchiveXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		// Путь задается от xl/ или от корня архива со слешем в начале
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join("xl", rel.Target)
+		}
+	}
+
+	paths := make(map[string]string, len(workbook.Sheets))
+	for _, sheet := range workbook.Sheets {
+		if target, ok := targets[sheet.ID]; ok {
+			paths[sheet.Name] = target
+		}
+	}
+	return paths, nil
+}
+
+// decodeArchiveXML разбирает XML-файл name из архива в v
+func decodeArchiveXML(files map[string]*zip.File, name string, v any) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("в архиве нет %s", name)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// scanWorksheet считает строки и ячейки в разметке листа
+func scanWorksheet(file *zip.File) (SheetSize, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return SheetSize{}, err
+	}
+	defer rc.Close()
+
+	var size SheetSize
+	cells := 0
+	reader := bufio.NewReaderSize(rc, 64*1024)
+	for {
+		if _, err := reader.ReadSlice('<'); err != nil {
+			if err == bufio.ErrBufferFull {
+				continue
+			}
+			if err == io.EOF {
+				break
+			}
+			return SheetSize{}, err
+		}
+
+		tag, _ := reader.Peek(4)
+		switch {
+		case isTag(tag, "row"):
+			size.Rows++
+			size.Columns = max(size.Columns, cells)
+			cells = 0
+		case isTag(tag, "c"):
+			cells++
+		}
+	}
+	size.Columns = max(size.Columns, cells)
+	return size, nil
+}
+
+// isTag проверяет, что после "<" идет открывающий тег name
+func isTag(next []byte, name string) bool {
+	if len(next) <= len(name) || string(next[:len(name)]) != name {
+		return false
+	}
+	switch next[len(name)] {
+	case ' ', '>', '/', '\t', '\n', '\r':
+		return true
+	}
+	return false
+}
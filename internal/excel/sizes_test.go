@@ -0,0 +1,72 @@
+package excel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+)
+
+// TestScanSheetSizes тестирует оценку размеров листов по разметке архива
+func TestScanSheetSizes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sizes.xlsx")
+	writer := NewWriter()
+	defer writer.Close()
+
+	sheets := map[string][][]string{
+		"Данные": append([][]string{exceltest.BenchHeaders(5)}, exceltest.BenchRows(120, 5)...),
+		"Коды":   {{"Код"}, {"1"}, {"2"}},
+	}
+	for name, rows := range sheets {
+		if err := writer.CreateSheet(name); err != nil {
+			t.Fatalf("не удалось создать лист: %v", err)
+		}
+		if err := writer.WriteRows(name, 1, rows); err != nil {
+			t.Fatalf("не удалось записать строки: %v", err)
+		}
+	}
+	if err := writer.Save(path); err != nil {
+		t.Fatalf("не удалось сохранить файл: %v", err)
+	}
+
+	sizes, err := ScanSheetSizes(path)
+	if err != nil {
+		t.Fatalf("ScanSheetSizes() error = %v", err)
+	}
+
+	want := map[string]SheetSize{
+		"Данные": {Rows: 121, Columns: 5},
+		"Коды":   {Rows: 3, Columns: 1},
+	}
+	for name, size := range want {
+		if sizes[name] != size {
+			t.Errorf("размер листа %s = %+v, ожидалось %+v", name, sizes[name], size)
+		}
+	}
+}
+
+// TestScanSheetSizesInvalidFile тестирует ошибку для файла, который не является книгой Excel
+func TestScanSheetSizesInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "text.xlsx")
+	if err := os.WriteFile(path, []byte("не архив"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ScanSheetSizes(path); err == nil {
+		t.Error("ожидалась ошибка для файла без архива")
+	}
+}
+
+// TestScanSheetSizesGzip тестирует оценку размеров листов книги, сжатой gzip
+func TestScanSheetSizesGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sizes.xlsx.gz")
+	writeDataWorkbook(t, path, [][]string{{"Артикул", "Цена"}, {"A1", "100"}, {"A2", "200"}})
+
+	sizes, err := ScanSheetSizes(path)
+	if err != nil {
+		t.Fatalf("ScanSheetSizes() error = %v", err)
+	}
+	if want := (SheetSize{Rows: 3, Columns: 2}); sizes["Data"] != want {
+		t.Errorf("размер листа Data = %+v, ожидалось %+v", sizes["Data"], want)
+	}
+}
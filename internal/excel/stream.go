@@ -0,0 +1,90 @@
+package excel
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+)
+
+// StreamWriter записывает единственный лист новой книги построчно
+// Строки сразу сбрасываются во временный файл excelize, поэтому память
+// не растет с размером листа; писать можно только последовательно сверху вниз
+type StreamWriter struct {
+	file      *excelize.File
+	stream    *excelize.StreamWriter
+	sheetName string
+	rows      int
+}
+
+// NewStreamWriter создает книгу с листом sheetName для потоковой записи
+func NewStreamWriter(sheetName string) (*StreamWriter, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("некорректное имя листа '%s': %w", sheetName, err)
+	}
+
+	stream, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("не удалось начать запись листа '%s': %w", sheetName, err)
+	}
+
+	openHandles.Add(1)
+	return &StreamWriter{file: f, stream: stream, sheetName: sheetName}, nil
+}
+
+// WriteRow дописывает строку значений после предыдущей; значения записываются как текст
+func (s *StreamWriter) WriteRow(values []string) error {
+	if s.rows >= MaxExcelRows {
+		return fmt.Errorf("лист '%s' заполнен: в Excel не более %d строк на листе", s.sheetName, MaxExcelRows)
+	}
+
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, s.rows+1)
+	if err != nil {
+		return err
+	}
+	if err := s.stream.SetRow(cell, cells); err != nil {
+		return fmt.Errorf("не удалось записать строку %d листа '%s': %w", s.rows+1, s.sheetName, err)
+	}
+	s.rows++
+	return nil
+}
+
+// Rows возвращает количество записанных строк
+func (s *StreamWriter) Rows() int {
+	return s.rows
+}
+
+// SaveAtomic завершает лист и сохраняет книгу через временный файл, как Writer.SaveAtomic
+// После сохранения строки больше не дописываются
+func (s *StreamWriter) SaveAtomic(path string) error {
+	if err := s.stream.Flush(); err != nil {
+		return apperrors.NewSaveError(path, err)
+	}
+	save := func(tmpPath string) error { return s.file.SaveAs(tmpPath) }
+	if err := saveAtomic(path, save); err != nil {
+		return apperrors.NewSaveError(path, err)
+	}
+	return nil
+}
+
+// Close освобождает книгу и временные файлы потоковой записи
+// Повторный вызов безопасен и ничего не делает
+func (s *StreamWriter) Close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+	openHandles.Add(-1)
+	return err
+}
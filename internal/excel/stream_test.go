@@ -0,0 +1,61 @@
+package excel
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestStreamWriter тестирует построчную запись листа и освобождение книги
+func TestStreamWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.xlsx")
+	before := OpenHandles()
+
+	writer, err := NewStreamWriter("Данные")
+	if err != nil {
+		t.Fatalf("NewStreamWriter() error = %v", err)
+	}
+
+	rows := [][]string{{"Код", "Описание"}, {"00123", "строка 1\nстрока 2"}, {"7"}}
+	for _, row := range rows {
+		if err := writer.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if writer.Rows() != len(rows) {
+		t.Errorf("Rows() = %d, ожидалось %d", writer.Rows(), len(rows))
+	}
+	if err := writer.SaveAtomic(path); err != nil {
+		t.Fatalf("SaveAtomic() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Errorf("повторный Close() error = %v", err)
+	}
+	if OpenHandles() != before {
+		t.Errorf("открытых книг = %d, ожидалось %d", OpenHandles(), before)
+	}
+
+	reader, err := NewReader(path)
+	if err != nil {
+		t.Fatalf("не удалось открыть результат: %v", err)
+	}
+	defer reader.Close()
+
+	got, err := reader.GetRows("Данные")
+	if err != nil {
+		t.Fatalf("GetRows() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("строки = %q, ожидалось %q", got, rows)
+	}
+}
+
+// TestNewStreamWriterInvalidSheetName тестирует ошибку для недопустимого имени листа
+func TestNewStreamWriterInvalidSheetName(t *testing.T) {
+	if _, err := NewStreamWriter("лист[1]"); err == nil {
+		t.Error("ожидалась ошибка для имени листа с [ ]")
+	}
+}
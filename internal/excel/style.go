@@ -0,0 +1,145 @@
+package excel
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// HeaderStyles оформление строки заголовков, загруженное из файла-шаблона
+type HeaderStyles struct {
+	styles []*excelize.Style // Стили по столбцам (nil - ячейка без оформления)
+}
+
+// maxTemplateColumns количество столбцов шаблона оформления, в которых ищутся стили
+const maxTemplateColumns = 256
+
+// LoadHeaderStyles загружает оформление строки заголовков из файла-шаблона
+// Используется первая строка первого листа шаблона: стиль ячейки столбца N
+// применяется к заголовку столбца N, стиль последнего столбца - к остальным столбцам
+func LoadHeaderStyles(path string) (*HeaderStyles, error) {
+	reader, err := NewReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	sheets := reader.GetSheetNames()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("шаблон оформления %s не содержит листов", path)
+	}
+	sheet := sheets[0]
+
+	// Оформленные ячейки могут быть пустыми, поэтому проверяем стиль каждой ячейки строки
+	var styles []*excelize.Style
+	for col := 1; col <= maxTemplateColumns; col++ {
+		cell, err := excelize.CoordinatesToCellName(col, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		idx, err := reader.file.GetCellStyle(sheet, cell)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать стиль ячейки %s: %w", cell, err)
+		}
+		if idx == 0 {
+			continue
+		}
+
+		style, err := reader.file.GetStyle(idx)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать стиль ячейки %s: %w", cell, err)
+		}
+
+		for len(styles) < col-1 {
+			styles = append(styles, nil)
+		}
+		styles = append(styles, style)
+	}
+
+	if len(styles) == 0 {
+		return nil, fmt.Errorf("первая строка шаблона оформления %s не содержит оформленных ячеек", path)
+	}
+
+	return &HeaderStyles{styles: styles}, nil
+}
+
+// styleFor возвращает стиль для столбца (нумерация с 0)
+func (h *HeaderStyles) styleFor(col int) *excelize.Style {
+	if len(h.styles) == 0 {
+		return nil
+	}
+	if col >= len(h.styles) {
+		col = len(h.styles) - 1
+	}
+	return h.styles[col]
+}
+
+// ApplyHeaderStyles оформляет строку заголовков листа стилями из шаблона
+// columns - количество столбцов заголовка
+func (w *Writer) ApplyHeaderStyles(sheetName string, row, columns int, styles *HeaderStyles) error {
+	if styles == nil || row < 1 {
+		return nil
+	}
+
+	// Стили хранятся в книге по индексам, поэтому создаем их в результирующей книге
+	styleIDs := make(map[*excelize.Style]int)
+	for col := 0; col < columns; col++ {
+		style := styles.styleFor(col)
+		if style == nil {
+			continue
+		}
+
+		id, ok := styleIDs[style]
+		if !ok {
+			var err error
+			id, err = w.file.NewStyle(style)
+			if err != nil {
+				return fmt.Errorf("не удалось создать стиль заголовка: %w", err)
+			}
+			styleIDs[style] = id
+		}
+
+		cell, err := excelize.CoordinatesToCellName(col+1, row)
+		if err != nil {
+			return err
+		}
+		if err := w.file.SetCellStyle(sheetName, cell, cell, id); err != nil {
+			return fmt.Errorf("не удалось применить стиль к ячейке %s: %w", cell, err)
+		}
+	}
+
+	return nil
+}
+
+// labelRowStyle оформление строк-подписей: полужирный серый текст
+var labelRowStyle = &excelize.Style{Font: &excelize.Font{Bold: true, Color: "808080"}}
+
+// WriteLabelRow записывает строку-подпись с текстом text в первом столбце строки rowNum
+// Текст записывается как текст и оформляется полужирным серым шрифтом; строки за лимитом
+// переносятся на листы-продолжения, как в WriteRow
+func (w *Writer) WriteLabelRow(sheetName string, rowNum int, text string) error {
+	targetSheet, targetRow, err := w.resolveRow(sheetName, rowNum)
+	if err != nil {
+		return err
+	}
+	if err := w.writeCells(targetSheet, targetRow, []string{text}, nil); err != nil {
+		return err
+	}
+
+	if w.labelStyle == 0 {
+		if w.labelStyle, err = w.file.NewStyle(labelRowStyle); err != nil {
+			return fmt.Errorf("не удалось создать стиль строки-подписи: %w", err)
+		}
+	}
+	cell, err := excelize.CoordinatesToCellName(1, targetRow)
+	if err != nil {
+		return err
+	}
+	if err := w.file.SetCellStyle(targetSheet, cell, cell, w.labelStyle); err != nil {
+		return fmt.Errorf("не удалось применить стиль к ячейке %s: %w", cell, err)
+	}
+
+	w.markRow(sheetName, rowNum)
+	return nil
+}
@@ -0,0 +1,179 @@
+package excel
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// templateHeaderStyle стиль заголовка в тестовом шаблоне оформления
+var templateHeaderStyle = &excelize.Style{
+	Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"1F4E78"}},
+	Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+	Border: []excelize.Border{
+		{Type: "bottom", Color: "000000", Style: 2},
+	},
+}
+
+// writeStyleTemplate создает шаблон оформления: A1 оформлена стилем templateHeaderStyle,
+// B1 - тем же стилем с курсивом
+func writeStyleTemplate(t *testing.T, path string) {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+
+	boldID, err := f.NewStyle(templateHeaderStyle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	italic := *templateHeaderStyle
+	italic.Font = &excelize.Font{Bold: true, Italic: true, Color: "FFFFFF"}
+	italicID, err := f.NewStyle(&italic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.SetCellValue("Sheet1", "A1", "Заголовок")
+	if err := f.SetCellStyle("Sheet1", "A1", "A1", boldID); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetCellStyle("Sheet1", "B1", "B1", italicID); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestApplyHeaderStyles тестирует перенос оформления заголовков из шаблона
+func TestApplyHeaderStyles(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "style.xlsx")
+	writeStyleTemplate(t, templatePath)
+
+	styles, err := LoadHeaderStyles(templatePath)
+	if err != nil {
+		t.Fatalf("LoadHeaderStyles failed: %v", err)
+	}
+
+	writer := NewWriter()
+	defer writer.Close()
+
+	if err := writer.CreateSheet("Data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteRows("Data", 1, [][]string{
+		{"Отчет"},
+		{"Артикул", "Бренд", "Цена"},
+		{"A1", "Shuzzi", "100"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.ApplyHeaderStyles("Data", 2, 3, styles); err != nil {
+		t.Fatalf("ApplyHeaderStyles failed: %v", err)
+	}
+
+	tests := []struct {
+		cell       string
+		wantStyled bool
+		wantItalic bool
+	}{
+		{"A2", true, false},
+		{"B2", true, true},
+		{"C2", true, true}, // Стиль последнего столбца шаблона распространяется на остальные
+		{"A1", false, false},
+		{"A3", false, false},
+	}
+
+	for _, tt := range tests {
+		idx, err := writer.file.GetCellStyle("Data", tt.cell)
+		if err != nil {
+			t.Fatalf("GetCellStyle(%s) failed: %v", tt.cell, err)
+		}
+		if !tt.wantStyled {
+			if idx != 0 {
+				t.Errorf("cell %s must stay unstyled, got style %d", tt.cell, idx)
+			}
+			continue
+		}
+
+		style, err := writer.file.GetStyle(idx)
+		if err != nil {
+			t.Fatalf("GetStyle(%s) failed: %v", tt.cell, err)
+		}
+		if style.Font == nil || !style.Font.Bold || style.Font.Italic != tt.wantItalic {
+			t.Errorf("cell %s: unexpected font %+v", tt.cell, style.Font)
+		}
+		if len(style.Fill.Color) == 0 || style.Fill.Color[0] != "1F4E78" {
+			t.Errorf("cell %s: unexpected fill %+v", tt.cell, style.Fill)
+		}
+		if len(style.Border) != 1 || style.Border[0].Type != "bottom" || style.Border[0].Style != 2 {
+			t.Errorf("cell %s: unexpected border %+v", tt.cell, style.Border)
+		}
+	}
+}
+
+// TestLoadHeaderStylesErrors тестирует ошибки загрузки шаблона оформления
+func TestLoadHeaderStylesErrors(t *testing.T) {
+	if _, err := LoadHeaderStyles(filepath.Join(t.TempDir(), "missing.xlsx")); err == nil {
+		t.Error("expected error for missing template")
+	}
+
+	emptyPath := filepath.Join(t.TempDir(), "empty.xlsx")
+	f := excelize.NewFile()
+	if err := f.SaveAs(emptyPath); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if _, err := LoadHeaderStyles(emptyPath); err == nil {
+		t.Error("expected error for template with empty first row")
+	}
+}
+
+func TestWriteLabelRow(t *testing.T) {
+	writer := NewWriter()
+	defer writer.Close()
+	writer.rowLimit = 3
+	writer.SetAutoSplit(true)
+	if err := writer.CreateSheet("Data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteRows("Data", 1, [][]string{{"Артикул"}, {"A1"}}); err != nil {
+		t.Fatal(err)
+	}
+	writer.SetHeaderRows("Data", [][]string{{"Артикул"}})
+
+	// Вторая подпись попадает на лист-продолжение
+	for _, row := range []int{3, 4} {
+		if err := writer.WriteLabelRow("Data", row, "100"); err != nil {
+			t.Fatalf("WriteLabelRow(%d) error = %v", row, err)
+		}
+	}
+	if got := writer.NextRow("Data"); got != 5 {
+		t.Errorf("NextRow() = %d, ожидалось 5", got)
+	}
+
+	for _, cell := range []struct{ sheet, name string }{{"Data", "A3"}, {"Data_2", "A2"}} {
+		value, err := writer.file.GetCellValue(cell.sheet, cell.name)
+		if err != nil || value != "100" {
+			t.Errorf("%s!%s = %q, ошибка %v", cell.sheet, cell.name, value, err)
+		}
+		// Подпись остается текстом, даже если похожа на число
+		cellType, _ := writer.file.GetCellType(cell.sheet, cell.name)
+		if cellType == excelize.CellTypeNumber {
+			t.Errorf("%s!%s записана числом", cell.sheet, cell.name)
+		}
+		idx, err := writer.file.GetCellStyle(cell.sheet, cell.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		style, err := writer.file.GetStyle(idx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if style.Font == nil || !style.Font.Bold || style.Font.Color != "808080" {
+			t.Errorf("%s!%s: шрифт %+v, ожидался полужирный серый", cell.sheet, cell.name, style.Font)
+		}
+	}
+}
@@ -2,57 +2,289 @@ package excel
 
 import (
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 
 	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
 )
 
+// MaxExcelRows максимальное количество строк на листе Excel
+const MaxExcelRows = 1048576
+
+// maxSheetNameLength максимальная длина имени листа Excel
+const maxSheetNameLength = 31
+
+// defaultSheetName имя пустого листа, с которым excelize создает новую книгу
+const defaultSheetName = "Sheet1"
+
 // Writer предоставляет методы для записи Excel файлов
 type Writer struct {
 	file *excelize.File
+
+	// Разбиение листов, превышающих лимит строк
+	rowLimit    int                   // Лимит строк на лист (по умолчанию MaxExcelRows)
+	autoSplit   bool                  // Переносить строки сверх лимита на листы-продолжения
+	headerRows  map[string][][]string // Строки шапки, повторяемые на листах-продолжениях
+	splitSheets map[string][]string   // Созданные листы-продолжения для каждого листа
+
+	numberColumns map[string]map[int]bool // Числовые столбцы листов (0-based), записываемые как числа
+
+	// Курсор строк: последняя занятая строка каждого листа (для разбитых листов - логическая)
+	lastRows  map[string]int
+	uncounted map[string]bool // Листы исходного файла, строки которых еще не подсчитаны
+
+	// Пустой лист, который держит место в книге без листов: Sheet1 новой книги или лист,
+	// созданный при удалении единственного листа (пусто - такого листа нет). Следующий созданный
+	// лист занимает его место, а оставшийся рядом с другими листами удаляет TrimDefaultSheet
+	placeholder      string
+	keepDefaultSheet bool // Не удалять пустой лист-заполнитель при сохранении
+	labelStyle       int  // Стиль строк-подписей WriteLabelRow (0 - еще не создан)
+
+	saved Fingerprints // Отпечатки содержимого, записанного при последнем сохранении
 }
 
 // NewWriter создает новый Writer
 func NewWriter() *Writer {
-	return &Writer{
-		file: excelize.NewFile(),
-	}
+	w := newWriter(excelize.NewFile())
+	w.placeholder = defaultSheetName
+	return w
 }
 
 // NewWriterFromFile создает Writer на основе существующего файла
+// Листы, данные и оформление файла сохраняются; сам файл не изменяется до Save.
+// Параметры открытия выбираются по размеру файла, как в NewReader
 func NewWriterFromFile(path string) (*Writer, error) {
-	f, err := excelize.OpenFile(path)
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	f, _, err := openWorkbook(path, OptionsForSize(size))
 	if err != nil {
-		return nil, apperrors.NewFileReadError(path, err)
+		return nil, err
+	}
+
+	// Строки существующих листов подсчитываются при первом обращении к курсору
+	w := newWriter(f)
+	for _, name := range f.GetSheetList() {
+		w.uncounted[name] = true
 	}
+	return w, nil
+}
+
+// newWriter создает Writer для указанного excelize.File
+func newWriter(f *excelize.File) *Writer {
+	openHandles.Add(1)
 
 	return &Writer{
-		file: f,
-	}, nil
+		file:        f,
+		rowLimit:    MaxExcelRows,
+		headerRows:  make(map[string][][]string),
+		splitSheets: make(map[string][]string),
+
+		numberColumns: make(map[string]map[int]bool),
+
+		lastRows:  make(map[string]int),
+		uncounted: make(map[string]bool),
+	}
+}
+
+// NextRow возвращает номер первой свободной строки листа после записанных и существующих строк
+// Для листов, разбитых на продолжения, номер логический: его можно передавать в WriteRow/WriteRows.
+// Строки листов файла из NewWriterFromFile подсчитываются один раз при первом вызове
+func (w *Writer) NextRow(sheetName string) int {
+	if w.uncounted[sheetName] {
+		delete(w.uncounted, sheetName)
+		if rows, err := w.file.GetRows(sheetName); err == nil {
+			w.markRow(sheetName, len(rows))
+		}
+	}
+	return w.lastRows[sheetName] + 1
+}
+
+// markRow сдвигает курсор листа, если строка rowNum ниже последней занятой
+func (w *Writer) markRow(sheetName string, rowNum int) {
+	if rowNum > w.lastRows[sheetName] {
+		w.lastRows[sheetName] = rowNum
+	}
+}
+
+// SetAutoSplit включает или выключает автоматическое разбиение листов,
+// превышающих лимит строк Excel, на листы-продолжения (Лист_2, Лист_3, ...)
+func (w *Writer) SetAutoSplit(enabled bool) {
+	w.autoSplit = enabled
+}
+
+// SetRowLimit задает лимит строк на лист; значения вне 1..MaxExcelRows возвращают лимит Excel
+func (w *Writer) SetRowLimit(limit int) {
+	if limit <= 0 || limit > MaxExcelRows {
+		limit = MaxExcelRows
+	}
+	w.rowLimit = limit
+}
+
+// CheckRowLimit проверяет, что логическая строка rowNum листа может быть записана:
+// без разбиения строки за лимитом не записываются
+func (w *Writer) CheckRowLimit(sheetName string, rowNum int) error {
+	if rowNum > w.rowLimit && !w.autoSplit {
+		return apperrors.NewRowLimitExceededError(sheetName, rowNum, w.rowLimit)
+	}
+	return nil
+}
+
+// SetHeaderRows запоминает строки шапки листа (включая строку заголовков),
+// которые повторяются в начале каждого листа-продолжения
+func (w *Writer) SetHeaderRows(sheetName string, rows [][]string) {
+	w.headerRows[sheetName] = rows
+}
+
+// SetNumberColumns задает числовые столбцы листа (0-based), включая листы-продолжения
+// Значения этих столбцов, распознанные ParseNumber, записываются как числа;
+// остальные значения и строки шапки из SetHeaderRows записываются как текст
+func (w *Writer) SetNumberColumns(sheetName string, columns []int) {
+	if len(columns) == 0 {
+		delete(w.numberColumns, sheetName)
+		return
+	}
+
+	set := make(map[int]bool, len(columns))
+	for _, col := range columns {
+		set[col] = true
+	}
+	w.numberColumns[sheetName] = set
+}
+
+// ParseNumber распознает число в тексте ячейки
+// Допускаются пробелы-разделители разрядов (включая неразрывные) и десятичная запятая
+func ParseNumber(value string) (float64, bool) {
+	normalized := strings.NewReplacer(" ", "", "\u00a0", "", ",", ".").Replace(strings.TrimSpace(value))
+	if normalized == "" {
+		return 0, false
+	}
+
+	number, err := strconv.ParseFloat(normalized, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, false
+	}
+	return number, true
+}
+
+// GetSplitSheets возвращает имена листов-продолжений, созданных для листа
+func (w *Writer) GetSplitSheets(sheetName string) []string {
+	return w.splitSheets[sheetName]
+}
+
+// sheetParts возвращает лист и его листы-продолжения с последней занятой строкой каждого
+// Все листы, кроме последнего, заполнены до лимита строк
+func (w *Writer) sheetParts(sheetName string) (parts []string, lastRows []int) {
+	parts = append([]string{sheetName}, w.splitSheets[sheetName]...)
+	lastRow := w.NextRow(sheetName) - 1
+	lastRows = make([]int, len(parts))
+	for i := range parts {
+		lastRows[i] = w.rowLimit
+		if i == len(parts)-1 {
+			lastRows[i] = lastRow
+			if i > 0 {
+				_, lastRows[i] = splitPosition(lastRow, len(w.headerRows[sheetName]), w.rowLimit)
+			}
+		}
+	}
+	return parts, lastRows
+}
+
+// SplitSheetName возвращает имя листа-продолжения (part >= 2: Лист_2, Лист_3, ...)
+// с учетом ограничения Excel в 31 символ на имя листа
+func SplitSheetName(sheetName string, part int) string {
+	suffix := fmt.Sprintf("_%d", part)
+	name := []rune(sheetName)
+	if maxLen := maxSheetNameLength - len(suffix); len(name) > maxLen {
+		name = name[:maxLen]
+	}
+	return string(name) + suffix
+}
+
+// splitPosition вычисляет, в какую часть и строку попадает логическая строка листа
+// part = 0 — исходный лист, part = 1 — первый лист-продолжение и т.д.
+// На каждом листе-продолжении первые headerCount строк занимает повторенная шапка
+func splitPosition(row, headerCount, rowLimit int) (part, partRow int) {
+	if row <= rowLimit {
+		return 0, row
+	}
+
+	capacity := rowLimit - headerCount
+	offset := row - rowLimit - 1
+
+	return 1 + offset/capacity, headerCount + 1 + offset%capacity
+}
+
+// resolveRow определяет фактический лист и номер строки для записи логической строки
+// При необходимости создает лист-продолжение с повторенной шапкой
+func (w *Writer) resolveRow(sheetName string, rowNum int) (string, int, error) {
+	if rowNum <= w.rowLimit {
+		return sheetName, rowNum, nil
+	}
+
+	if err := w.CheckRowLimit(sheetName, rowNum); err != nil {
+		return "", 0, err
+	}
+
+	header := w.headerRows[sheetName]
+	if len(header) >= w.rowLimit {
+		return "", 0, fmt.Errorf("шапка листа '%s' не помещается в лимит строк %d", sheetName, w.rowLimit)
+	}
+
+	part, partRow := splitPosition(rowNum, len(header), w.rowLimit)
+
+	// Создаем недостающие листы-продолжения по порядку
+	for len(w.splitSheets[sheetName]) < part {
+		partName := SplitSheetName(sheetName, len(w.splitSheets[sheetName])+2)
+		if err := w.CreateSheet(partName); err != nil {
+			return "", 0, err
+		}
+		for i, row := range header {
+			if err := w.writeCells(partName, i+1, row, nil); err != nil {
+				return "", 0, err
+			}
+		}
+		w.splitSheets[sheetName] = append(w.splitSheets[sheetName], partName)
+	}
+
+	return w.splitSheets[sheetName][part-1], partRow, nil
 }
 
 // Close закрывает файл
+// Повторный вызов безопасен и ничего не делает
 func (w *Writer) Close() error {
-	if w.file != nil {
-		return w.file.Close()
+	if w.file == nil {
+		return nil
 	}
-	return nil
+
+	err := w.file.Close()
+	w.file = nil
+	openHandles.Add(-1)
+	return err
 }
 
 // CreateSheet создает новый лист с указанным именем
 func (w *Writer) CreateSheet(sheetName string) error {
-	// Если это первый создаваемый лист и существует только Sheet1 по умолчанию,
-	// переименовываем Sheet1 вместо создания нового листа
+	// Если в книге только пустой лист-заполнитель (Sheet1 новой книги),
+	// переименовываем его вместо создания нового листа
 	sheets := w.file.GetSheetList()
-	if len(sheets) == 1 && sheets[0] == "Sheet1" {
-		// Переименовываем Sheet1 в нужное имя
-		if err := w.file.SetSheetName("Sheet1", sheetName); err != nil {
+	if w.placeholder != "" && len(sheets) == 1 && sheets[0] == w.placeholder && w.NextRow(w.placeholder) == 1 {
+		// Переименовываем заполнитель в нужное имя
+		if err := w.file.SetSheetName(w.placeholder, sheetName); err != nil {
 			return fmt.Errorf("failed to rename default sheet to '%s': %w", sheetName, err)
 		}
 		// Устанавливаем его как активный
 		index, _ := w.file.GetSheetIndex(sheetName)
 		w.file.SetActiveSheet(index)
+		delete(w.lastRows, w.placeholder)
+		w.placeholder = ""
 		return nil
 	}
 
@@ -71,13 +303,64 @@ func (w *Writer) CreateSheet(sheetName string) error {
 }
 
 // DeleteSheet удаляет лист
+// Книга не может остаться без листов: единственный лист заменяется пустым листом-заполнителем
+// Sheet1, место которого займет следующий созданный лист. Если удаляется активный лист,
+// активным становится первый оставшийся
 func (w *Writer) DeleteSheet(sheetName string) error {
+	sheets := w.file.GetSheetList()
+	if len(sheets) == 1 && sheets[0] == sheetName {
+		placeholder := defaultSheetName
+		if sheetName == defaultSheetName {
+			placeholder = "Sheet2"
+		}
+		if _, err := w.file.NewSheet(placeholder); err != nil {
+			return fmt.Errorf("failed to delete sheet '%s': %w", sheetName, err)
+		}
+		w.placeholder = placeholder
+	}
+
+	active := w.file.GetSheetName(w.file.GetActiveSheetIndex())
 	if err := w.file.DeleteSheet(sheetName); err != nil {
 		return fmt.Errorf("failed to delete sheet '%s': %w", sheetName, err)
 	}
+	if active == sheetName {
+		w.file.SetActiveSheet(0)
+	}
+	delete(w.lastRows, sheetName)
+	delete(w.uncounted, sheetName)
 	return nil
 }
 
+// SetKeepDefaultSheet отключает удаление пустого листа-заполнителя (Sheet1 новой книги) при сохранении
+func (w *Writer) SetKeepDefaultSheet(keep bool) {
+	w.keepDefaultSheet = keep
+}
+
+// TrimDefaultSheet удаляет пустой лист-заполнитель (Sheet1 новой книги), если в книге есть другие листы
+// Лист, в который записаны строки, и единственный лист книги не удаляются; если заполнитель был
+// активным, активным становится первый из оставшихся листов. Возвращает true, если лист удален.
+// Вызывается при сохранении, если удаление не отключено SetKeepDefaultSheet
+func (w *Writer) TrimDefaultSheet() (bool, error) {
+	if w.keepDefaultSheet || w.placeholder == "" || !w.SheetExists(w.placeholder) {
+		return false, nil
+	}
+	if len(w.file.GetSheetList()) < 2 {
+		return false, nil
+	}
+	if w.NextRow(w.placeholder) > 1 {
+		// В заполнитель записаны данные: это уже настоящий лист
+		w.placeholder = ""
+		return false, nil
+	}
+
+	name := w.placeholder
+	if err := w.DeleteSheet(name); err != nil {
+		return false, err
+	}
+	w.placeholder = ""
+	return true, nil
+}
+
 // WriteHeaderRow записывает строку заголовков
 func (w *Writer) WriteHeaderRow(sheetName string, rowNum int, headers []string) error {
 	for colIdx, header := range headers {
@@ -91,22 +374,77 @@ func (w *Writer) WriteHeaderRow(sheetName string, rowNum int, headers []string)
 		}
 	}
 
+	w.markRow(sheetName, rowNum)
 	return nil
 }
 
 // WriteRow записывает одну строку данных
+// Строки за пределами лимита Excel переносятся на листы-продолжения,
+// если включено автоматическое разбиение, иначе возвращается ошибка
 func (w *Writer) WriteRow(sheetName string, rowNum int, data []string) error {
+	targetSheet, targetRow, err := w.resolveRow(sheetName, rowNum)
+	if err != nil {
+		return err
+	}
+
+	// Строки шапки всегда записываются как текст
+	var numberColumns map[int]bool
+	if rowNum > len(w.headerRows[sheetName]) {
+		numberColumns = w.numberColumns[sheetName]
+	}
+	if err := w.writeCells(targetSheet, targetRow, data, numberColumns); err != nil {
+		return err
+	}
+
+	// Строка считается занятой, даже если в ней нет значений
+	w.markRow(sheetName, rowNum)
+	return nil
+}
+
+// writeCells записывает значения в ячейки строки без учета лимита строк
+// Значения столбцов numberColumns, распознанные как числа, записываются числами
+func (w *Writer) writeCells(sheetName string, rowNum int, data []string, numberColumns map[int]bool) error {
 	for colIdx, value := range data {
 		cell, err := excelize.CoordinatesToCellName(colIdx+1, rowNum)
 		if err != nil {
 			return fmt.Errorf("failed to get cell name: %w", err)
 		}
 
+		var cellValue interface{} = value
+		if numberColumns[colIdx] {
+			if number, ok := ParseNumber(value); ok {
+				cellValue = number
+			}
+		}
+
+		if err := w.file.SetCellValue(sheetName, cell, cellValue); err != nil {
+			return fmt.Errorf("failed to write value to cell %s: %w", cell, err)
+		}
+	}
+
+	if len(data) > 0 {
+		w.markRow(sheetName, rowNum)
+	}
+	return nil
+}
+
+// WriteCellsAt записывает значения в строку rowNum, начиная со столбца startCol (1-based)
+// Остальные ячейки строки и оформление ячеек не изменяются
+func (w *Writer) WriteCellsAt(sheetName string, rowNum, startCol int, values []string) error {
+	for i, value := range values {
+		cell, err := excelize.CoordinatesToCellName(startCol+i, rowNum)
+		if err != nil {
+			return fmt.Errorf("failed to get cell name: %w", err)
+		}
+
 		if err := w.file.SetCellValue(sheetName, cell, value); err != nil {
 			return fmt.Errorf("failed to write value to cell %s: %w", cell, err)
 		}
 	}
 
+	if len(values) > 0 {
+		w.markRow(sheetName, rowNum)
+	}
 	return nil
 }
 
@@ -125,6 +463,9 @@ func (w *Writer) SetCellValue(sheetName, cell string, value interface{}) error {
 	if err := w.file.SetCellValue(sheetName, cell, value); err != nil {
 		return fmt.Errorf("failed to set cell value %s: %w", cell, err)
 	}
+	if _, rowNum, err := excelize.CellNameToCoordinates(cell); err == nil {
+		w.markRow(sheetName, rowNum)
+	}
 	return nil
 }
 
@@ -155,14 +496,128 @@ func (w *Writer) SetActiveSheet(sheetName string) error {
 	return nil
 }
 
+// FixedDocTimestamp время создания и изменения книги, которое задает ResetDocTimestamps
+const FixedDocTimestamp = "2000-01-01T00:00:00Z"
+
+// ResetDocTimestamps заменяет время создания и изменения в свойствах книги на FixedDocTimestamp
+// Без этого копия базового файла несет его время изменения, и одинаковые объединения
+// дают разные файлы
+func (w *Writer) ResetDocTimestamps() error {
+	props, err := w.file.GetDocProps()
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать свойства книги: %w", err)
+	}
+	props.Created = FixedDocTimestamp
+	props.Modified = FixedDocTimestamp
+	if err := w.file.SetDocProps(props); err != nil {
+		return fmt.Errorf("не удалось записать свойства книги: %w", err)
+	}
+	return nil
+}
+
 // Save сохраняет файл по указанному пути
 func (w *Writer) Save(path string) error {
-	if err := w.file.SaveAs(path); err != nil {
+	if _, err := w.TrimDefaultSheet(); err != nil {
 		return apperrors.NewSaveError(path, err)
 	}
+	if err := w.saveHashed(path); err != nil {
+		return apperrors.NewSaveError(path, err)
+	}
+	return nil
+}
+
+// SaveAtomic сохраняет файл через временный файл в том же каталоге
+// Существующий файл по пути path заменяется только после успешной записи,
+// поэтому прерванное сохранение не повреждает его
+func (w *Writer) SaveAtomic(path string) error {
+	if _, err := w.TrimDefaultSheet(); err != nil {
+		return apperrors.NewSaveError(path, err)
+	}
+	if err := saveAtomic(path, w.saveHashed); err != nil {
+		return apperrors.NewSaveError(path, err)
+	}
+	w.file.Path = path
+	return nil
+}
+
+// saveHashed записывает книгу в файл path и по ходу записи считает отпечатки содержимого
+// Повторяет excelize.File.SaveAs: тип книги определяется по расширению path
+func (w *Writer) saveHashed(path string) error {
+	w.file.Path = path
+	file, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hashes := newFingerprinter()
+	if err := w.file.Write(io.MultiWriter(file, hashes)); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	w.saved = hashes.fingerprints()
+	return nil
+}
+
+// SavedFingerprints возвращает отпечатки файла, записанного последним вызовом Save
+// или SaveAtomic (nil - книга еще не сохранялась)
+func (w *Writer) SavedFingerprints() Fingerprints {
+	return w.saved
+}
+
+// saveAtomic записывает файл функцией save во временный файл рядом с path
+// и переименовывает его в path; при ошибке временный файл удаляется
+func saveAtomic(path string, save func(tmpPath string) error) error {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+
+	// Временный файл с тем же расширением: по нему определяется тип книги
+	tmp, err := os.CreateTemp(filepath.Dir(path), "~"+strings.TrimSuffix(base, ext)+".*"+ext)
+	if err != nil {
+		return fmt.Errorf("не удалось создать временный файл: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	// Файл создается заново при записи с обычными правами доступа
+	os.Remove(tmpPath)
+
+	if err := save(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := syncFile(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("не удалось записать файл на диск: %w", err)
+	}
+
+	// Заменяемый файл сохраняет свои права доступа
+	if info, err := os.Stat(path); err == nil {
+		os.Chmod(tmpPath, info.Mode().Perm())
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
 	return nil
 }
 
+// syncFile сбрасывает содержимое файла на диск
+func syncFile(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 // GetFile возвращает внутренний объект excelize.File для продвинутых операций
 func (w *Writer) GetFile() *excelize.File {
 	return w.file
@@ -190,25 +645,103 @@ func (w *Writer) CopySheet(sourceFile *excelize.File, sourceSheet, targetSheet s
 }
 
 // MergeSheetData объединяет данные в существующий лист
-// Добавляет строки данных после существующих строк
+// Добавляет строки данных после существующих строк (см. NextRow)
 func (w *Writer) MergeSheetData(sheetName string, headerRow int, newRows [][]string) error {
-	// Получаем текущие строки
-	existingRows, err := w.file.GetRows(sheetName)
-	if err != nil {
-		return fmt.Errorf("failed to get existing rows: %w", err)
+	return w.WriteRows(sheetName, w.NextRow(sheetName), newRows)
+}
+
+// AppendTo дописывает данные листов книги в существующую книгу target
+// Листы сопоставляются по имени. В лист, который уже есть в target, дописываются только
+// строки данных после его занятых строк: шапка (SetHeaderRows) в нем уже есть. Лист, которого
+// нет в target, копируется вместе с шапкой. Строки листов-продолжений дописываются вместе
+// с основным листом, а в target переносятся на продолжения по настройке автоматического разбиения
+func (w *Writer) AppendTo(target *Writer) error {
+	continuations := make(map[string]bool)
+	for _, parts := range w.splitSheets {
+		for _, part := range parts {
+			continuations[part] = true
+		}
 	}
+	target.autoSplit = w.autoSplit
 
-	// Определяем начальную строку для новых данных
-	startRow := len(existingRows) + 1
+	for _, name := range w.GetSheetNames() {
+		if continuations[name] {
+			continue
+		}
 
-	// Записываем новые строки
-	if err := w.WriteRows(sheetName, startRow, newRows); err != nil {
+		header := w.headerRows[name]
+		rows, err := w.file.GetRows(name)
+		if err != nil {
+			return fmt.Errorf("failed to read rows from sheet '%s': %w", name, err)
+		}
+		data := rows[min(len(header), len(rows)):]
+		for _, part := range w.splitSheets[name] {
+			partRows, err := w.file.GetRows(part)
+			if err != nil {
+				return fmt.Errorf("failed to read rows from sheet '%s': %w", part, err)
+			}
+			data = append(data, partRows[min(len(header), len(partRows)):]...)
+		}
+
+		if !target.SheetExists(name) {
+			if err := target.CreateSheet(name); err != nil {
+				return err
+			}
+			if err := target.WriteRows(name, 1, rows[:min(len(header), len(rows))]); err != nil {
+				return err
+			}
+		}
+		if _, ok := target.headerRows[name]; !ok {
+			target.SetHeaderRows(name, header)
+		}
+		if columns, ok := w.numberColumns[name]; ok {
+			target.numberColumns[name] = columns
+		}
+
+		if err := target.MergeSheetData(name, len(header), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetTabColor устанавливает цвет ярлыка листа
+// hex - цвет в формате RRGGBB или AARRGGBB (допускается префикс #); пустая строка ничего не меняет
+func (w *Writer) SetTabColor(sheetName, hex string) error {
+	if hex == "" {
+		return nil
+	}
+
+	color, err := NormalizeTabColor(hex)
+	if err != nil {
 		return err
 	}
 
+	if err := w.file.SetSheetProps(sheetName, &excelize.SheetPropsOptions{TabColorRGB: &color}); err != nil {
+		return fmt.Errorf("не удалось установить цвет ярлыка листа '%s': %w", sheetName, err)
+	}
 	return nil
 }
 
+// NormalizeTabColor проверяет цвет ярлыка листа и приводит его к формату AARRGGBB
+func NormalizeTabColor(hex string) (string, error) {
+	color := strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(hex), "#"))
+	if len(color) == 6 {
+		color = "FF" + color
+	}
+
+	if len(color) != 8 {
+		return "", fmt.Errorf("некорректный цвет ярлыка '%s': ожидается формат #RRGGBB", hex)
+	}
+	for _, c := range color {
+		if !strings.ContainsRune("0123456789ABCDEF", c) {
+			return "", fmt.Errorf("некорректный цвет ярлыка '%s': ожидается формат #RRGGBB", hex)
+		}
+	}
+
+	return color, nil
+}
+
 // GetSheetNames возвращает список всех листов
 func (w *Writer) GetSheetNames() []string {
 	return w.file.GetSheetList()
@@ -229,6 +762,9 @@ func (w *Writer) SetSheetRow(sheetName, cell string, values []interface{}) error
 	if err := w.file.SetSheetRow(sheetName, cell, &values); err != nil {
 		return fmt.Errorf("failed to set sheet row: %w", err)
 	}
+	if _, rowNum, err := excelize.CellNameToCoordinates(cell); err == nil && len(values) > 0 {
+		w.markRow(sheetName, rowNum)
+	}
 	return nil
 }
 
@@ -1,9 +1,16 @@
 package excel
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
 )
 
 // TestNewWriter тестирует создание нового Writer
@@ -253,6 +260,93 @@ func TestSave(t *testing.T) {
 	}
 }
 
+// TestSaveAtomic тестирует сохранение через временный файл с заменой существующего
+func TestSaveAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "result.xlsx")
+	if err := os.WriteFile(path, []byte("previous"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	writer := NewWriter()
+	defer writer.Close()
+	if err := writer.WriteRows("Sheet1", 1, [][]string{{"Артикул"}, {"A1"}}); err != nil {
+		t.Fatalf("Failed to write rows: %v", err)
+	}
+
+	if err := writer.SaveAtomic(path); err != nil {
+		t.Fatalf("SaveAtomic() error = %v", err)
+	}
+
+	reader, err := NewReader(path)
+	if err != nil {
+		t.Fatalf("Failed to open saved file: %v", err)
+	}
+	defer reader.Close()
+	rows, err := reader.GetRows("Sheet1")
+	if err != nil || len(rows) != 2 {
+		t.Errorf("rows = %v, err = %v", rows, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("права доступа = %v, ожидались сохраненные 0640", info.Mode().Perm())
+	}
+	assertNoTempFiles(t, dir)
+}
+
+// TestSaveAtomicKeepsOriginalOnFailure тестирует, что прерванное сохранение не портит файл
+func TestSaveAtomicKeepsOriginalOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "result.xlsx")
+	if err := os.WriteFile(path, []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	saveErr := errors.New("disk full")
+	err := saveAtomic(path, func(tmpPath string) error {
+		if filepath.Dir(tmpPath) != dir || filepath.Ext(tmpPath) != ".xlsx" {
+			t.Errorf("временный файл %s должен быть рядом с целевым и иметь расширение .xlsx", tmpPath)
+		}
+
+		// Частичная запись во временный файл, затем сбой
+		if err := os.WriteFile(tmpPath, []byte("partial"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != "previous" {
+			t.Errorf("целевой файл изменен до переименования: %q", data)
+		}
+		return saveErr
+	})
+	if !errors.Is(err, saveErr) {
+		t.Fatalf("saveAtomic() error = %v, want %v", err, saveErr)
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "previous" {
+		t.Errorf("исходный файл поврежден: %q", data)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+// assertNoTempFiles проверяет, что в каталоге не осталось временных файлов
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("в каталоге остались лишние файлы: %v", names)
+	}
+}
+
 // TestWriteSheetWithData тестирует создание листа с данными
 func TestWriteSheetWithData(t *testing.T) {
 	writer := NewWriter()
@@ -344,6 +438,184 @@ func TestMergeSheetData(t *testing.T) {
 	}
 }
 
+// TestWriterNextRow тестирует курсор строк при чередовании WriteRows и MergeSheetData
+func TestWriterNextRow(t *testing.T) {
+	writer := NewWriter()
+	defer writer.Close()
+
+	if err := writer.CreateSheet("Data"); err != nil {
+		t.Fatal(err)
+	}
+	if got := writer.NextRow("Data"); got != 1 {
+		t.Errorf("NextRow() нового листа = %d, ожидалось 1", got)
+	}
+
+	steps := []struct {
+		name  string
+		write func() error
+		want  int
+	}{
+		{"шапка", func() error { return writer.WriteRows("Data", 1, [][]string{{"Артикул", "Цена"}}) }, 2},
+		{"дописывание", func() error { return writer.MergeSheetData("Data", 1, [][]string{{"A1", "10"}, {"A2", "20"}}) }, 4},
+		{"запись с пропуском", func() error { return writer.WriteRows("Data", 6, [][]string{{"A6", "60"}}) }, 7},
+		{"перезапись выше курсора", func() error { return writer.WriteRows("Data", 2, [][]string{{"B1", "11"}}) }, 7},
+		{"пустая строка", func() error { return writer.WriteRow("Data", 7, nil) }, 8},
+		{"дописывание после пустой", func() error { return writer.MergeSheetData("Data", 1, [][]string{{"A8", "80"}}) }, 9},
+	}
+	for _, step := range steps {
+		if err := step.write(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := writer.NextRow("Data"); got != step.want {
+			t.Errorf("%s: NextRow() = %d, ожидалось %d", step.name, got, step.want)
+		}
+	}
+
+	rows, err := writer.GetFile().GetRows("Data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 8 || rows[7][0] != "A8" {
+		t.Errorf("строки листа = %v", rows)
+	}
+}
+
+// TestWriterNextRowFromFile тестирует курсор строк листов существующего файла
+func TestWriterNextRowFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "base.xlsx")
+	source := NewWriter()
+	if err := source.CreateSheet("Data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.WriteRows("Data", 1, [][]string{{"Артикул"}, {"A1"}, {"A2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.CreateSheet("Пустой"); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	source.Close()
+
+	writer, err := NewWriterFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	// Запись до первого обращения к курсору не должна скрыть существующие строки
+	if err := writer.WriteCellsAt("Data", 1, 2, []string{"Бренд"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := writer.NextRow("Data"); got != 4 {
+		t.Errorf("NextRow(Data) = %d, ожидалось 4", got)
+	}
+	if got := writer.NextRow("Пустой"); got != 1 {
+		t.Errorf("NextRow(Пустой) = %d, ожидалось 1", got)
+	}
+
+	if err := writer.MergeSheetData("Data", 1, [][]string{{"A3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := writer.NextRow("Data"); got != 5 {
+		t.Errorf("NextRow(Data) после дописывания = %d, ожидалось 5", got)
+	}
+}
+
+// TestWriterAppendTo тестирует дописывание листов книги в существующий файл
+func TestWriterAppendTo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "target.xlsx")
+	existing := NewWriter()
+	if err := existing.CreateSheet("Data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := existing.WriteRows("Data", 1, [][]string{{"Артикул"}, {"Z1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := existing.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	existing.Close()
+
+	// Результат с шапкой из двух строк, разбитый на продолжения, и новым листом
+	source := NewWriter()
+	defer source.Close()
+	source.rowLimit = 4
+	source.SetAutoSplit(true)
+	header := [][]string{{"Отчет"}, {"Артикул"}}
+	if err := source.CreateSheet("Data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.WriteRows("Data", 1, header); err != nil {
+		t.Fatal(err)
+	}
+	source.SetHeaderRows("Data", header)
+	if err := source.WriteRows("Data", 3, [][]string{{"A1"}, {"A2"}, {"A3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.CreateSheet("Новый"); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.WriteRows("Новый", 1, [][]string{{"Бренд"}, {"B1"}}); err != nil {
+		t.Fatal(err)
+	}
+	source.SetHeaderRows("Новый", [][]string{{"Бренд"}})
+
+	target, err := NewWriterFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	if err := source.AppendTo(target); err != nil {
+		t.Fatalf("AppendTo() error = %v", err)
+	}
+
+	want := map[string][][]string{
+		"Data":  {{"Артикул"}, {"Z1"}, {"A1"}, {"A2"}, {"A3"}},
+		"Новый": {{"Бренд"}, {"B1"}},
+	}
+	for name, wantRows := range want {
+		rows, err := target.GetFile().GetRows(name)
+		if err != nil {
+			t.Fatalf("лист %s: %v", name, err)
+		}
+		if !reflect.DeepEqual(rows, wantRows) {
+			t.Errorf("лист %s = %q, ожидалось %q", name, rows, wantRows)
+		}
+	}
+	if target.SheetExists("Data_2") {
+		t.Error("лист-продолжение результата скопирован в файл")
+	}
+}
+
+// TestWriterNextRowAutoSplit тестирует логический курсор строк листа с продолжениями
+func TestWriterNextRowAutoSplit(t *testing.T) {
+	writer := NewWriter()
+	defer writer.Close()
+	writer.rowLimit = 3
+	writer.SetAutoSplit(true)
+
+	if err := writer.CreateSheet("Data"); err != nil {
+		t.Fatal(err)
+	}
+	writer.SetHeaderRows("Data", [][]string{{"Артикул"}})
+	if err := writer.WriteRows("Data", 1, [][]string{{"Артикул"}, {"A1"}, {"A2"}, {"A3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.MergeSheetData("Data", 1, [][]string{{"A4"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := writer.NextRow("Data"); got != 6 {
+		t.Errorf("NextRow() = %d, ожидалось 6", got)
+	}
+	rows, _ := writer.GetFile().GetRows("Data_2")
+	if len(rows) != 3 || rows[2][0] != "A4" {
+		t.Errorf("строки листа-продолжения = %v", rows)
+	}
+}
+
 // TestWriterGetSheetNames тестирует получение списка листов
 func TestWriterGetSheetNames(t *testing.T) {
 	writer := NewWriter()
@@ -398,3 +670,523 @@ func TestNewWriterFromFile(t *testing.T) {
 
 	t.Logf("Loaded file with %d sheets", len(sheets))
 }
+
+// TestNewWriterFromFileKeepsSheet1 тестирует, что лист Sheet1 существующего файла
+// не переименовывается при создании первого листа
+func TestNewWriterFromFileKeepsSheet1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "base.xlsx")
+	f := excelize.NewFile()
+	if err := f.SetCellValue("Sheet1", "A1", "данные"); err != nil {
+		t.Fatalf("SetCellValue failed: %v", err)
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	f.Close()
+
+	writer, err := NewWriterFromFile(path)
+	if err != nil {
+		t.Fatalf("NewWriterFromFile failed: %v", err)
+	}
+	defer writer.Close()
+
+	if err := writer.CreateSheet("Итог"); err != nil {
+		t.Fatalf("CreateSheet failed: %v", err)
+	}
+	if sheets := writer.GetSheetNames(); fmt.Sprint(sheets) != "[Sheet1 Итог]" {
+		t.Errorf("sheets = %v, want [Sheet1 Итог]", sheets)
+	}
+}
+
+// TestTrimDefaultSheet тестирует, что пустой Sheet1 новой книги не остается рядом с другими листами
+func TestTrimDefaultSheet(t *testing.T) {
+	// fromFile возвращает Writer для сохраненной книги с единственным листом "Data"
+	fromFile := func(t *testing.T) *Writer {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "base.xlsx")
+		f := excelize.NewFile()
+		if err := f.SetSheetName("Sheet1", "Data"); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.SaveAs(path); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		writer, err := NewWriterFromFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return writer
+	}
+
+	tests := []struct {
+		name        string
+		newWriter   func(t *testing.T) *Writer
+		build       func(w *Writer) error
+		wantTrimmed bool
+		wantSheets  string
+		wantActive  string // Пусто - активный лист не проверяется
+	}{
+		{
+			name:      "первый лист занимает место Sheet1",
+			newWriter: func(*testing.T) *Writer { return NewWriter() },
+			build: func(w *Writer) error {
+				if err := w.CreateSheet("A"); err != nil {
+					return err
+				}
+				return w.CreateSheet("B")
+			},
+			wantSheets: "[A B]",
+		},
+		{
+			name:      "удаление единственного листа",
+			newWriter: func(*testing.T) *Writer { return NewWriter() },
+			build: func(w *Writer) error {
+				if err := w.CreateSheet("A"); err != nil {
+					return err
+				}
+				if err := w.DeleteSheet("A"); err != nil {
+					return err
+				}
+				return w.CreateSheet("B")
+			},
+			wantSheets: "[B]",
+			wantActive: "B",
+		},
+		{
+			name:      "удаление единственного листа книги из файла",
+			newWriter: fromFile,
+			build: func(w *Writer) error {
+				if err := w.DeleteSheet("Data"); err != nil {
+					return err
+				}
+				return w.CreateSheet("Итог")
+			},
+			wantSheets: "[Итог]",
+			wantActive: "Итог",
+		},
+		{
+			name:      "Sheet1 рядом с листом, созданным в обход CreateSheet",
+			newWriter: func(*testing.T) *Writer { return NewWriter() },
+			build: func(w *Writer) error {
+				_, err := w.GetFile().NewSheet("Другой")
+				return err
+			},
+			wantTrimmed: true,
+			wantSheets:  "[Другой]",
+			wantActive:  "Другой",
+		},
+		{
+			name:      "Sheet1 с данными остается",
+			newWriter: func(*testing.T) *Writer { return NewWriter() },
+			build: func(w *Writer) error {
+				if err := w.WriteRow("Sheet1", 1, []string{"данные"}); err != nil {
+					return err
+				}
+				return w.CreateSheet("Итог")
+			},
+			wantSheets: "[Sheet1 Итог]",
+		},
+		{
+			name:       "единственный пустой Sheet1 остается",
+			newWriter:  func(*testing.T) *Writer { return NewWriter() },
+			build:      func(*Writer) error { return nil },
+			wantSheets: "[Sheet1]",
+			wantActive: "Sheet1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := tt.newWriter(t)
+			defer writer.Close()
+			if err := tt.build(writer); err != nil {
+				t.Fatal(err)
+			}
+
+			trimmed, err := writer.TrimDefaultSheet()
+			if err != nil {
+				t.Fatalf("TrimDefaultSheet() error = %v", err)
+			}
+			if trimmed != tt.wantTrimmed {
+				t.Errorf("TrimDefaultSheet() = %v, ожидалось %v", trimmed, tt.wantTrimmed)
+			}
+			if sheets := fmt.Sprint(writer.GetSheetNames()); sheets != tt.wantSheets {
+				t.Errorf("листы = %s, ожидалось %s", sheets, tt.wantSheets)
+			}
+			file := writer.GetFile()
+			if active := file.GetSheetName(file.GetActiveSheetIndex()); tt.wantActive != "" && active != tt.wantActive {
+				t.Errorf("активный лист = %s, ожидался %s", active, tt.wantActive)
+			}
+		})
+	}
+}
+
+// TestSaveTrimsDefaultSheet тестирует удаление пустого Sheet1 при сохранении и его отключение
+func TestSaveTrimsDefaultSheet(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		t.Run(fmt.Sprintf("keep=%v", keep), func(t *testing.T) {
+			writer := NewWriter()
+			defer writer.Close()
+			writer.SetKeepDefaultSheet(keep)
+			if _, err := writer.GetFile().NewSheet("Данные"); err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join(t.TempDir(), "out.xlsx")
+			if err := writer.Save(path); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			saved, err := excelize.OpenFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer saved.Close()
+
+			want := "[Данные]"
+			if keep {
+				want = "[Sheet1 Данные]"
+			}
+			if sheets := fmt.Sprint(saved.GetSheetList()); sheets != want {
+				t.Errorf("листы сохраненной книги = %s, ожидалось %s", sheets, want)
+			}
+		})
+	}
+}
+
+// TestWriteCellsAt тестирует запись ячеек с указанного столбца без изменения остальных ячеек и оформления
+func TestWriteCellsAt(t *testing.T) {
+	writer := NewWriter()
+	defer writer.Close()
+
+	if err := writer.WriteRow("Sheet1", 1, []string{"Артикул", "Цена"}); err != nil {
+		t.Fatalf("WriteRow failed: %v", err)
+	}
+	style, err := writer.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		t.Fatalf("NewStyle failed: %v", err)
+	}
+	if err := writer.file.SetCellStyle("Sheet1", "A1", "D1", style); err != nil {
+		t.Fatalf("SetCellStyle failed: %v", err)
+	}
+
+	if err := writer.WriteCellsAt("Sheet1", 1, 3, []string{"Акция", "Канал"}); err != nil {
+		t.Fatalf("WriteCellsAt failed: %v", err)
+	}
+
+	rows, err := writer.file.GetRows("Sheet1")
+	if err != nil {
+		t.Fatalf("GetRows failed: %v", err)
+	}
+	if fmt.Sprint(rows) != "[[Артикул Цена Акция Канал]]" {
+		t.Errorf("rows = %v", rows)
+	}
+	if idx, _ := writer.file.GetCellStyle("Sheet1", "C1"); idx != style {
+		t.Errorf("style C1 = %d, want %d", idx, style)
+	}
+}
+
+// TestSplitPosition тестирует вычисление листа-части и строки при разбиении
+func TestSplitPosition(t *testing.T) {
+	tests := []struct {
+		name        string
+		row         int
+		headerCount int
+		rowLimit    int
+		wantPart    int
+		wantRow     int
+	}{
+		{"строка в пределах лимита", 5, 2, 10, 0, 5},
+		{"последняя строка первого листа", 10, 2, 10, 0, 10},
+		{"первая строка второго листа", 11, 2, 10, 1, 3},
+		{"последняя строка второго листа", 18, 2, 10, 1, 10},
+		{"первая строка третьего листа", 19, 2, 10, 2, 3},
+		{"без шапки", 21, 0, 10, 2, 1},
+		{"реальный лимит Excel", MaxExcelRows + 1, 4, MaxExcelRows, 1, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			part, row := splitPosition(tt.row, tt.headerCount, tt.rowLimit)
+			if part != tt.wantPart || row != tt.wantRow {
+				t.Errorf("splitPosition(%d, %d, %d) = (%d, %d), want (%d, %d)",
+					tt.row, tt.headerCount, tt.rowLimit, part, row, tt.wantPart, tt.wantRow)
+			}
+		})
+	}
+}
+
+// TestSplitSheetName тестирует формирование имен листов-продолжений
+func TestSplitSheetName(t *testing.T) {
+	if got := SplitSheetName("Шаблон", 2); got != "Шаблон_2" {
+		t.Errorf("Expected 'Шаблон_2', got '%s'", got)
+	}
+
+	longName := "Очень длинное имя листа для теста"
+	got := SplitSheetName(longName, 3)
+	if len([]rune(got)) > maxSheetNameLength {
+		t.Errorf("Split sheet name '%s' exceeds %d characters", got, maxSheetNameLength)
+	}
+	if got[len(got)-2:] != "_3" {
+		t.Errorf("Expected suffix '_3', got '%s'", got)
+	}
+}
+
+// TestAutoSplitReplicatesHeader тестирует перенос строк сверх лимита с повтором шапки
+func TestAutoSplitReplicatesHeader(t *testing.T) {
+	writer := NewWriter()
+	defer writer.Close()
+	writer.rowLimit = 5
+	writer.SetAutoSplit(true)
+
+	sheetName := "Data"
+	if err := writer.CreateSheet(sheetName); err != nil {
+		t.Fatalf("Failed to create sheet: %v", err)
+	}
+
+	header := [][]string{{"Отчет"}, {"Артикул", "Цена"}}
+	if err := writer.WriteRows(sheetName, 1, header); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	writer.SetHeaderRows(sheetName, header)
+
+	// 8 строк данных: 3 на первом листе, 3 на втором, 2 на третьем
+	var data [][]string
+	for i := 1; i <= 8; i++ {
+		data = append(data, []string{fmt.Sprintf("A%d", i), fmt.Sprintf("%d", i*10)})
+	}
+	if err := writer.WriteRows(sheetName, 3, data); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+
+	splitSheets := writer.GetSplitSheets(sheetName)
+	if len(splitSheets) != 2 || splitSheets[0] != "Data_2" || splitSheets[1] != "Data_3" {
+		t.Fatalf("Expected split sheets [Data_2 Data_3], got %v", splitSheets)
+	}
+
+	expected := map[string]int{sheetName: 5, "Data_2": 5, "Data_3": 4}
+	for name, wantRows := range expected {
+		rows, err := writer.GetFile().GetRows(name)
+		if err != nil {
+			t.Fatalf("Failed to read sheet '%s': %v", name, err)
+		}
+		if len(rows) != wantRows {
+			t.Errorf("Sheet '%s': expected %d rows, got %d", name, wantRows, len(rows))
+		}
+		if len(rows) >= 2 && rows[1][0] != "Артикул" {
+			t.Errorf("Sheet '%s': header row not replicated, got %v", name, rows[1])
+		}
+	}
+
+	rows, _ := writer.GetFile().GetRows("Data_3")
+	if rows[2][0] != "A7" || rows[3][0] != "A8" {
+		t.Errorf("Unexpected data on Data_3: %v", rows[2:])
+	}
+}
+
+// TestRowLimitWithoutAutoSplit тестирует ошибку при превышении лимита без разбиения
+func TestRowLimitWithoutAutoSplit(t *testing.T) {
+	writer := NewWriter()
+	defer writer.Close()
+	writer.rowLimit = 3
+
+	if err := writer.CreateSheet("Data"); err != nil {
+		t.Fatalf("Failed to create sheet: %v", err)
+	}
+
+	err := writer.WriteRows("Data", 1, [][]string{{"1"}, {"2"}, {"3"}, {"4"}})
+	if err == nil {
+		t.Fatal("Expected row limit error, got nil")
+	}
+
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code != apperrors.ErrCodeRowLimitExceeded {
+		t.Errorf("Expected error code %s, got %v", apperrors.ErrCodeRowLimitExceeded, err)
+	}
+}
+
+// TestCheckRowLimit тестирует проверку лимита строк до записи
+func TestCheckRowLimit(t *testing.T) {
+	writer := NewWriter()
+	defer writer.Close()
+	writer.SetRowLimit(3)
+
+	if err := writer.CheckRowLimit("Data", 3); err != nil {
+		t.Errorf("строка в пределах лимита: %v", err)
+	}
+	err := writer.CheckRowLimit("Data", 4)
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code != apperrors.ErrCodeRowLimitExceeded {
+		t.Errorf("ожидалась ошибка %s, получено %v", apperrors.ErrCodeRowLimitExceeded, err)
+	}
+
+	// Строки за лимитом переносятся на листы-продолжения
+	writer.SetAutoSplit(true)
+	if err := writer.CheckRowLimit("Data", 4); err != nil {
+		t.Errorf("строка за лимитом с разбиением: %v", err)
+	}
+
+	writer.SetRowLimit(0)
+	if writer.rowLimit != MaxExcelRows {
+		t.Errorf("лимит после сброса = %d, ожидалось %d", writer.rowLimit, MaxExcelRows)
+	}
+}
+
+// TestSetTabColor тестирует сохранение цвета ярлыка листа
+func TestSetTabColor(t *testing.T) {
+	writer := NewWriter()
+	defer writer.Close()
+
+	if err := writer.CreateSheet("Товары"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.CreateSheet("Видео"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.SetTabColor("Товары", "#00b050"); err != nil {
+		t.Fatalf("SetTabColor failed: %v", err)
+	}
+	if err := writer.SetTabColor("Видео", ""); err != nil {
+		t.Fatalf("SetTabColor with empty color failed: %v", err)
+	}
+	if err := writer.SetTabColor("Товары", "зеленый"); err == nil {
+		t.Error("expected error for invalid color")
+	}
+
+	path := filepath.Join(t.TempDir(), "tabs.xlsx")
+	if err := writer.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	saved, err := NewWriterFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to reopen file: %v", err)
+	}
+	defer saved.Close()
+
+	props, err := saved.GetFile().GetSheetProps("Товары")
+	if err != nil {
+		t.Fatalf("GetSheetProps failed: %v", err)
+	}
+	if props.TabColorRGB == nil || *props.TabColorRGB != "FF00B050" {
+		t.Errorf("unexpected tab color: %v", props.TabColorRGB)
+	}
+
+	props, err = saved.GetFile().GetSheetProps("Видео")
+	if err != nil {
+		t.Fatalf("GetSheetProps failed: %v", err)
+	}
+	if props.TabColorRGB != nil && *props.TabColorRGB != "" {
+		t.Errorf("sheet without color got tab color %q", *props.TabColorRGB)
+	}
+}
+
+// TestNormalizeTabColor тестирует разбор цвета ярлыка
+func TestNormalizeTabColor(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"#FF0000", "FFFF0000", false},
+		{"00b050", "FF00B050", false},
+		{" #80112233 ", "80112233", false},
+		{"#F00", "", true},
+		{"#GG0000", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeTabColor(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeTabColor(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeTabColor(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestParseNumber тестирует распознавание чисел в тексте ячейки
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   float64
+		wantOK bool
+	}{
+		{"100", 100, true},
+		{" 1 234,5 ", 1234.5, true},
+		{"1 000", 1000, true},
+		{"-0.25", -0.25, true},
+		{"", 0, false},
+		{"по запросу", 0, false},
+		{"NaN", 0, false},
+		{"Inf", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseNumber(tt.value)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseNumber(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// TestSetNumberColumns тестирует запись числовых столбцов числами, включая листы-продолжения
+func TestSetNumberColumns(t *testing.T) {
+	writer := NewWriter()
+	defer writer.Close()
+	writer.rowLimit = 3
+	writer.SetAutoSplit(true)
+
+	sheetName := "Data"
+	if err := writer.CreateSheet(sheetName); err != nil {
+		t.Fatalf("Failed to create sheet: %v", err)
+	}
+
+	header := [][]string{{"Артикул", "100"}}
+	if err := writer.WriteRows(sheetName, 1, header); err != nil {
+		t.Fatalf("Failed to write header: %v", err)
+	}
+	writer.SetHeaderRows(sheetName, header)
+	writer.SetNumberColumns(sheetName, []int{1})
+
+	data := [][]string{{"007", "1 234,5"}, {"008", "по запросу"}, {"009", "42"}}
+	if err := writer.WriteRows(sheetName, 2, data); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+
+	f := writer.GetFile()
+	isNumber := func(sheet, cell string) bool {
+		cellType, err := f.GetCellType(sheet, cell)
+		if err != nil {
+			t.Fatalf("Failed to get cell type %s!%s: %v", sheet, cell, err)
+		}
+		return cellType == excelize.CellTypeUnset || cellType == excelize.CellTypeNumber
+	}
+
+	tests := []struct {
+		sheet, cell string
+		wantNumber  bool
+	}{
+		{sheetName, "B1", false}, // шапка
+		{sheetName, "A2", false}, // текстовый столбец
+		{sheetName, "B2", true},
+		{sheetName, "B3", false}, // не число
+		{"Data_2", "B1", false},  // повторенная шапка
+		{"Data_2", "B2", true},
+	}
+	for _, tt := range tests {
+		if got := isNumber(tt.sheet, tt.cell); got != tt.wantNumber {
+			t.Errorf("%s!%s: number = %v, want %v", tt.sheet, tt.cell, got, tt.wantNumber)
+		}
+	}
+
+	if value, _ := f.GetCellValue(sheetName, "A2"); value != "007" {
+		t.Errorf("A2 = %q, текст должен сохраниться без изменений", value)
+	}
+	if value, _ := f.GetCellValue(sheetName, "B2", excelize.Options{RawCellValue: true}); value != "1234.5" {
+		t.Errorf("B2 = %q, want 1234.5", value)
+	}
+}
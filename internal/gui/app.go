@@ -1,13 +1,20 @@
 package gui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/DatKorso/Merge-excel/internal/config"
@@ -15,6 +22,21 @@ import (
 	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
 	"github.com/DatKorso/Merge-excel/internal/excel"
 	"github.com/DatKorso/Merge-excel/internal/native"
+	"github.com/DatKorso/Merge-excel/internal/profiling"
+	"github.com/DatKorso/Merge-excel/internal/updater"
+)
+
+// Фильтры диалогов выбора файлов
+var (
+	excelFileFilter = native.FileFilter{Description: "Excel файлы", Extension: "xlsx"}
+	jsonFileFilter  = native.FileFilter{Description: "JSON файлы", Extension: "json"}
+)
+
+const (
+	// manualUpdateCheckTimeout таймаут ручной проверки обновлений
+	manualUpdateCheckTimeout = 15 * time.Second
+	// updateInstallTimeout таймаут загрузки и установки обновления
+	updateInstallTimeout = 10 * time.Minute
 )
 
 // App главная структура приложения
@@ -23,19 +45,34 @@ type App struct {
 	window        fyne.Window
 	logger        *slog.Logger
 	configManager *config.Manager
+	autosaver     *config.Autosaver
 	analyzer      *core.BaseAnalyzer
+	readers       *excel.ReaderPool
 	merger        *core.Merger
-	excelWriter   *excel.Writer
 
 	// Вкладки
 	baseFileTab *BaseFileTab
 	fileListTab *FileListTab
 	mergeTab    *MergeTab
+	settingsTab *SettingsTab
 
 	// Текущее состояние
 	currentProfile *core.Profile
 	baseFilePath   string
 	appSettings    *config.AppSettings // Настройки приложения
+	settingsErr    error               // Ошибка загрузки настроек (показывается при запуске)
+
+	// Диалоги выбора файлов и папок (подменяются в тестах)
+	dialogs native.Dialogs
+
+	// Профилирование объединений (по умолчанию выключено)
+	profiling profiling.Config
+
+	// Обновления
+	appVersion    string
+	updateRunner  *updater.CheckRunner
+	updateBanner  *UpdateBanner
+	notifications *updater.NotificationPresenter
 }
 
 // NewApp создает новое приложение
@@ -44,17 +81,24 @@ func NewApp(logger *slog.Logger, cfgManager *config.Manager) *App {
 		fyneApp:       app.NewWithID("com.excel-merger.app"),
 		logger:        logger,
 		configManager: cfgManager,
-		excelWriter:   excel.NewWriter(),
 	}
 
+	// Файл, проверенный при добавлении и перед объединением, открывается один раз
+	application.readers = excel.NewReaderPool(excel.DefaultReaderPoolSize)
 	application.analyzer = core.NewBaseAnalyzer(nil, logger)
+	application.analyzer.SetReaderPool(application.readers)
+	application.autosaver = config.NewAutosaver(cfgManager, config.DefaultAutosaveDelay)
 	application.merger = core.NewMerger(nil, logger)
+	application.merger.SetReaderPool(application.readers)
+	application.updateBanner = NewUpdateBanner(application)
+	application.notifications = updater.NewNotificationPresenter(application, logger.With("component", "updater"))
 
 	// Загружаем настройки приложения
 	settings, err := cfgManager.LoadSettings()
 	if err != nil {
 		logger.Warn("не удалось загрузить настройки, используем по умолчанию", "error", err)
 		settings = config.NewAppSettings()
+		application.settingsErr = err
 	}
 	application.appSettings = settings
 	logger.Info("настройки приложения загружены", "use_ozon_template", settings.UseOzonTemplate)
@@ -66,17 +110,22 @@ func NewApp(logger *slog.Logger, cfgManager *config.Manager) *App {
 func (a *App) Run() {
 	a.window = a.fyneApp.NewWindow("Excel Merger - Объединение файлов Excel")
 	a.window.Resize(fyne.NewSize(900, 700))
+	if a.dialogs == nil {
+		a.dialogs = native.NewFallbackDialogs(native.NewNativeDialogs(), newFyneDialogs(a.window), a.logger)
+	}
 
 	// Создаем вкладки
 	a.baseFileTab = NewBaseFileTab(a)
 	a.fileListTab = NewFileListTab(a)
 	a.mergeTab = NewMergeTab(a)
+	a.settingsTab = NewSettingsTab(a)
 
 	// Создаем контейнер с вкладками
 	tabs := container.NewAppTabs(
 		container.NewTabItem("1. Базовый файл", a.baseFileTab.Build()),
 		container.NewTabItem("2. Файлы для объединения", a.fileListTab.Build()),
 		container.NewTabItem("3. Объединение", a.mergeTab.Build()),
+		container.NewTabItem("Настройки", a.settingsTab.Build()),
 	)
 
 	// Устанавливаем активную вкладку
@@ -87,18 +136,34 @@ func (a *App) Run() {
 	a.window.SetMainMenu(mainMenu)
 
 	// Устанавливаем содержимое окна
-	a.window.SetContent(tabs)
+	// Баннер обновления размещается над вкладками и не зависит от активной вкладки
+	a.window.SetContent(container.NewBorder(a.updateBanner.Build(), nil, nil, nil, tabs))
+
+	// Сообщаем об ошибке в файле настроек, чтобы пользователь мог ее исправить
+	if a.settingsErr != nil {
+		a.ShowError(fmt.Errorf("%w\n\nИспользуются настройки по умолчанию", a.settingsErr))
+	}
+
+	// Предлагаем восстановить профиль, не сохраненный в прошлый раз
+	a.offerAutosaveRestore()
 
 	// Настраиваем Drag & Drop для всего окна
-	a.window.SetOnDropped(func(pos fyne.Position, items []fyne.URI) {
-		fmt.Printf("Window Drop event! Position: %v, Items: %d\n", pos, len(items))
-		
+	a.window.SetOnDropped(func(_ fyne.Position, items []fyne.URI) {
+		a.logger.Debug("Files dropped on window", "items", len(items), "tab", tabs.CurrentTabIndex())
+
 		// Проверяем, на какой вкладке мы находимся
 		if tabs.CurrentTabIndex() == 1 { // Вкладка "Файлы для объединения"
 			a.fileListTab.OnFilesDropped(items)
 		}
 	})
 
+	// Ctrl+V на вкладке списка файлов добавляет файлы по путям из буфера обмена
+	a.window.Canvas().AddShortcut(&fyne.ShortcutPaste{}, func(fyne.Shortcut) {
+		if tabs.CurrentTabIndex() == 1 { // Вкладка "Файлы для объединения"
+			a.fileListTab.OnPastePaths(a.fyneApp.Clipboard().Content())
+		}
+	})
+
 	// Обработчик закрытия
 	a.window.SetCloseIntercept(func() {
 		a.onClose()
@@ -118,10 +183,37 @@ func (a *App) createMainMenu() *fyne.MainMenu {
 		fyne.NewMenuItem("Сохранить профиль...", func() {
 			a.onSaveProfile()
 		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Открыть последний результат", func() {
+			a.onOpenLastOutput()
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Импортировать профили из папки...", func() {
+			a.onImportProfilesDir()
+		}),
+		fyne.NewMenuItem("Экспортировать профиль...", func() {
+			a.onExportProfile()
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Конвертировать CSV...", func() {
+			a.onConvertCSV()
+		}),
 	)
 
+	// Переключатель канала обновлений
+	prereleaseItem := fyne.NewMenuItem("Получать тестовые версии", nil)
+	prereleaseItem.Checked = a.appSettings.UpdateChannel == config.UpdateChannelPrerelease
+	prereleaseItem.Action = func() {
+		a.onTogglePrereleaseChannel(prereleaseItem)
+	}
+
 	// Меню "Помощь"
 	helpMenu := fyne.NewMenu("Помощь",
+		fyne.NewMenuItem("Проверить обновления...", func() {
+			a.onCheckForUpdates()
+		}),
+		prereleaseItem,
+		fyne.NewMenuItemSeparator(),
 		fyne.NewMenuItem("О программе", func() {
 			a.showAboutDialog()
 		}),
@@ -134,28 +226,36 @@ func (a *App) createMainMenu() *fyne.MainMenu {
 func (a *App) ShowError(err error) {
 	var message string
 
-	if appErr, ok := err.(*apperrors.AppError); ok {
-		if msg, exists := apperrors.UserMessages[appErr.Code]; exists {
-			message = msg
-		} else {
-			message = appErr.Message
+	// Несколько ошибок показываем нумерованным списком, каждую своим сообщением
+	var multi *apperrors.MultiError
+	if errors.As(err, &multi) && multi.Len() > 1 {
+		message = fmt.Sprintf("Ошибок: %d", multi.Len())
+		for i, memberErr := range multi.Errors {
+			message += fmt.Sprintf("\n%d. %s", i+1, a.errorMessage(memberErr))
 		}
-
-		// Логируем детали
-		a.logger.Error("Application error",
-			"code", appErr.Code,
-			"message", appErr.Message,
-			"context", appErr.Context,
-			"error", appErr.Err,
-		)
+		a.logger.Error("Multiple errors", "count", multi.Len(), "error", err)
 	} else {
-		message = err.Error()
-		a.logger.Error("Unknown error", "error", err)
+		message = a.errorMessage(err)
 	}
 
 	dialog.ShowError(fmt.Errorf("%s", message), a.window)
 }
 
+// errorMessage возвращает сообщение об ошибке на языке интерфейса и логирует детали
+func (a *App) errorMessage(err error) string {
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) {
+		a.logger.Error("Unknown error", "error", err)
+		return err.Error()
+	}
+
+	// Логируем детали
+	a.logger.Error("Application error", "error", appErr)
+
+	// Общее сообщение по коду дополняется подробностями конкретной ошибки
+	return apperrors.LocalizedMessage(appErr, a.appSettings.Language)
+}
+
 // ShowInfo показывает информационное сообщение
 func (a *App) ShowInfo(title, message string) {
 	dialog.ShowInformation(title, message, a.window)
@@ -168,30 +268,30 @@ func (a *App) ShowConfirm(title, message string, callback func(bool)) {
 
 // onLoadProfile обработчик загрузки профиля
 func (a *App) onLoadProfile() {
-	// Открываем нативный диалог выбора файла профиля
-	filename, err := native.FileOpenDialog(
-		"Загрузить профиль",
-		"JSON файлы",
-		"json",
-	)
-	
-	// Проверяем отмену пользователем
-	if native.IsCancelled(err) {
-		return
-	}
-	
-	if err != nil {
-		a.ShowError(err)
-		return
-	}
+	a.dialogs.OpenFile("Загрузить профиль", jsonFileFilter, func(filename string, err error) {
+		// Проверяем отмену пользователем
+		if native.IsCancelled(err) {
+			return
+		}
+
+		if err != nil {
+			a.ShowError(err)
+			return
+		}
 
+		a.loadProfile(filename)
+	})
+}
+
+// loadProfile загружает профиль из файла и применяет его
+func (a *App) loadProfile(filename string) {
 	profile, err := a.configManager.LoadProfile(filename)
 	if err != nil {
 		a.ShowError(err)
 		return
 	}
 
-	a.currentProfile = profile
+	a.UpdateProfile(profile)
 	a.baseFileTab.LoadProfile(profile)
 	a.ShowInfo("Профиль загружен", "Профиль '"+profile.ProfileName+"' успешно загружен")
 
@@ -205,31 +305,455 @@ func (a *App) onSaveProfile() {
 		return
 	}
 
-	// Открываем нативный диалог сохранения файла
-	filename, err := native.FileSaveDialogSimple(
-		"Сохранить профиль",
-		"JSON файлы",
-		"json",
-	)
-	
-	// Проверяем отмену пользователем
-	if native.IsCancelled(err) {
+	// Автосохранение не пишет слот, пока открыто окно сохранения: после сохранения он удаляется,
+	// а при отмене накопленные изменения записываются после закрытия окна
+	a.autosaver.Suspend()
+	a.dialogs.SaveFile("Сохранить профиль", a.currentProfile.ProfileName, "", jsonFileFilter, func(filename string, err error) {
+		defer a.autosaver.Resume()
+
+		// Проверяем отмену пользователем
+		if native.IsCancelled(err) {
+			return
+		}
+
+		if err != nil {
+			a.ShowError(err)
+			return
+		}
+
+		a.saveProfile(filename)
+	})
+}
+
+// saveProfile сохраняет текущий профиль в файл
+func (a *App) saveProfile(filename string) {
+	if err := a.configManager.SaveProfile(a.currentProfile, filename); err != nil {
+		a.ShowError(err)
+		return
+	}
+
+	// Сохраненный явно профиль больше не нужно восстанавливать при запуске
+	a.discardAutosave()
+
+	a.ShowInfo("Профиль сохранен", "Профиль '"+a.currentProfile.ProfileName+"' успешно сохранен")
+
+	a.logger.Info("Profile saved", "name", a.currentProfile.ProfileName, "path", filename)
+}
+
+// onOpenLastOutput открывает последний сохраненный результат средствами ОС
+func (a *App) onOpenLastOutput() {
+	path, ok := a.appSettings.LastOutput()
+	if path == "" {
+		a.ShowInfo("Последний результат", "Результат объединения еще не сохранялся")
 		return
 	}
-	
+	if !ok {
+		a.ShowInfo("Последний результат", "Файл не найден:\n"+path)
+		return
+	}
+
+	fileURL, err := url.Parse(storage.NewFileURI(path).String())
 	if err != nil {
 		a.ShowError(err)
 		return
 	}
 
-	if err := a.configManager.SaveProfile(a.currentProfile, filename); err != nil {
+	if err := a.fyneApp.OpenURL(fileURL); err != nil {
 		a.ShowError(err)
 		return
 	}
 
-	a.ShowInfo("Профиль сохранен", "Профиль '"+a.currentProfile.ProfileName+"' успешно сохранен")
+	a.logger.Info("Last output opened", "path", path)
+}
 
-	a.logger.Info("Profile saved", "name", a.currentProfile.ProfileName, "path", filename)
+// onImportProfilesDir обработчик импорта всех профилей из директории
+func (a *App) onImportProfilesDir() {
+	a.dialogs.PickDirectory("Выберите папку с профилями", "", func(dir string, err error) {
+		// Проверяем отмену пользователем
+		if native.IsCancelled(err) {
+			return
+		}
+		if err != nil {
+			a.ShowError(err)
+			return
+		}
+		a.confirmImportProfilesDir(dir)
+	})
+}
+
+// confirmImportProfilesDir спрашивает, что делать с совпадающими профилями, и запускает импорт
+func (a *App) confirmImportProfilesDir(dir string) {
+	collision := dialog.NewConfirm(
+		"Импорт профилей",
+		"Если профиль с таким именем уже существует, перезаписать его?\n"+
+			"При отказе импортируемый профиль будет сохранен под новым именем.",
+		func(overwrite bool) {
+			a.importProfilesDir(dir, overwrite)
+		},
+		a.window,
+	)
+	collision.SetConfirmText("Перезаписать")
+	collision.SetDismissText("Переименовать")
+	collision.Show()
+}
+
+// importProfilesDir импортирует профили и показывает итог пользователю
+func (a *App) importProfilesDir(dir string, overwrite bool) {
+	imported, errs := a.configManager.ImportProfilesDir(dir, overwrite)
+
+	a.logger.Info("Profiles imported from directory",
+		"dir", dir,
+		"imported", imported,
+		"failed", len(errs),
+	)
+
+	message := fmt.Sprintf("Импортировано профилей: %d", imported)
+	if len(errs) > 0 {
+		message += "\nНе удалось импортировать:\n" + apperrors.NewMultiError(errs...).Error()
+	}
+
+	a.ShowInfo("Импорт профилей", message)
+}
+
+// onExportProfile обработчик экспорта сохраненного профиля в выбранную директорию
+func (a *App) onExportProfile() {
+	profiles, err := a.configManager.ListProfiles()
+	if err != nil {
+		a.ShowError(err)
+		return
+	}
+	if len(profiles) == 0 {
+		a.ShowInfo("Экспорт профиля", "Нет сохраненных профилей для экспорта")
+		return
+	}
+
+	// Имя в списке может повторяться у разных файлов, поэтому показываем и имя файла
+	options := make([]string, len(profiles))
+	filenames := make(map[string]string, len(profiles))
+	for i, info := range profiles {
+		options[i] = fmt.Sprintf("%s (%s.json)", info.Name, info.Filename)
+		filenames[options[i]] = info.Filename
+	}
+
+	profileSelect := widget.NewSelect(options, nil)
+	profileSelect.SetSelectedIndex(0)
+
+	dialog.ShowCustomConfirm("Экспорт профиля", "Выбрать папку...", "Отмена", profileSelect,
+		func(confirm bool) {
+			if !confirm {
+				return
+			}
+			a.exportProfileTo(filenames[profileSelect.Selected])
+		},
+		a.window,
+	)
+}
+
+// exportProfileTo запрашивает директорию и экспортирует в нее профиль filename
+func (a *App) exportProfileTo(filename string) {
+	startDir, _ := os.UserHomeDir()
+	a.dialogs.PickDirectory("Выберите папку для экспорта профиля", startDir, func(dir string, err error) {
+		if native.IsCancelled(err) {
+			return
+		}
+		if err != nil {
+			a.ShowError(err)
+			return
+		}
+
+		if err := a.configManager.ExportProfile(filename, dir); err != nil {
+			a.ShowError(err)
+			return
+		}
+
+		a.ShowInfo("Экспорт профиля", "Профиль сохранен в:\n"+filepath.Join(dir, filename+".json"))
+	})
+}
+
+// onTogglePrereleaseChannel переключает канал обновлений между stable и prerelease
+func (a *App) onTogglePrereleaseChannel(item *fyne.MenuItem) {
+	item.Checked = !item.Checked
+	if item.Checked {
+		a.appSettings.UpdateChannel = config.UpdateChannelPrerelease
+	} else {
+		a.appSettings.UpdateChannel = config.UpdateChannelStable
+	}
+
+	if err := a.configManager.SaveSettings(a.appSettings); err != nil {
+		a.logger.Error("не удалось сохранить настройки", "error", err)
+	}
+
+	// Применяем канал к механизму проверки обновлений
+	if a.updateRunner != nil {
+		if checker, ok := a.updateRunner.Checker().(interface{ SetChannel(string) }); ok {
+			checker.SetChannel(a.appSettings.UpdateChannel)
+		}
+	}
+
+	if mainMenu := a.window.MainMenu(); mainMenu != nil {
+		mainMenu.Refresh()
+	}
+
+	a.logger.Info("Update channel changed", "channel", a.appSettings.UpdateChannel)
+}
+
+// onCheckForUpdates обработчик ручной проверки обновлений
+func (a *App) onCheckForUpdates() {
+	if a.updateRunner == nil {
+		a.ShowInfo("Проверка обновлений", "Проверка обновлений недоступна")
+		return
+	}
+
+	if a.updateRunner.InProgress() {
+		a.ShowInfo("Проверка обновлений", "Проверка обновлений уже выполняется")
+		return
+	}
+
+	progress := dialog.NewCustomWithoutButtons(
+		"Проверка обновлений",
+		container.NewVBox(
+			widget.NewLabel("Подключение к серверу обновлений..."),
+			widget.NewProgressBarInfinite(),
+		),
+		a.window,
+	)
+	progress.Show()
+
+	go func() {
+		defer apperrors.Recover(a.logger, "проверка обновлений", func(err error) {
+			fyne.Do(func() {
+				progress.Hide()
+				a.ShowError(err)
+			})
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), manualUpdateCheckTimeout)
+		defer cancel()
+
+		info, err := a.updateRunner.Check(ctx)
+
+		fyne.Do(func() {
+			progress.Hide()
+			if updater.ClassifyCheck(info, err).Recorded() {
+				a.recordUpdateCheck(err)
+			}
+			a.showUpdateCheckResult(info, err)
+		})
+	}()
+}
+
+// showUpdateCheckResult показывает результат ручной проверки обновлений
+func (a *App) showUpdateCheckResult(info *updater.ReleaseInfo, err error) {
+	switch updater.ClassifyCheck(info, err) {
+	case updater.OutcomeInProgress:
+		a.ShowInfo("Проверка обновлений", "Проверка обновлений уже выполняется")
+	case updater.OutcomeOffline:
+		a.ShowInfo("Проверка обновлений", "Нет подключения к сети. Проверьте соединение и повторите попытку.")
+	case updater.OutcomeRateLimited:
+		a.ShowInfo("Проверка обновлений", fmt.Sprintf("Сервер обновлений временно ограничил число запросов.\n%v", err))
+	case updater.OutcomeFailed:
+		a.logger.Warn("Ручная проверка обновлений завершилась ошибкой", "error", err)
+		retry := dialog.NewConfirm(
+			"Ошибка проверки обновлений",
+			fmt.Sprintf("Не удалось проверить обновления:\n%v", err),
+			func(retry bool) {
+				if retry {
+					a.onCheckForUpdates()
+				}
+			},
+			a.window,
+		)
+		retry.SetConfirmText("Повторить")
+		retry.SetDismissText("Закрыть")
+		retry.Show()
+	case updater.OutcomeUpdateAvailable:
+		a.ShowUpdate(info)
+	default:
+		a.ShowInfo("Проверка обновлений", fmt.Sprintf("Установлена последняя версия (%s)", a.appVersion))
+	}
+}
+
+// NotifyUpdate уведомляет о найденном при автоматической проверке обновлении
+// способом, выбранным в настройках (диалог, баннер или только журнал)
+func (a *App) NotifyUpdate(info *updater.ReleaseInfo) {
+	a.notifications.Present(info, a.appSettings.UpdateNotification, a.appSettings.SkippedVersion)
+}
+
+// ShowUpdateDialog показывает диалог обновления (реализация updater.UpdateNotifier)
+func (a *App) ShowUpdateDialog(info *updater.ReleaseInfo) {
+	a.ShowUpdate(info)
+}
+
+// ShowUpdateBanner показывает баннер обновления (реализация updater.UpdateNotifier)
+func (a *App) ShowUpdateBanner(info *updater.ReleaseInfo) {
+	a.updateBanner.Show(info)
+}
+
+// SkipUpdateVersion отключает напоминания о версии до выхода следующей
+func (a *App) SkipUpdateVersion(version string) {
+	a.appSettings.SkippedVersion = version
+	if err := a.configManager.SaveSettings(a.appSettings); err != nil {
+		a.logger.Error("не удалось сохранить настройки", "error", err)
+	}
+	a.logger.Info("Update version skipped", "version", version)
+}
+
+// ShowUpdate показывает диалог доступного обновления
+// Кнопка автоматической установки доступна, только если она разрешена в настройках;
+// пропущенная версия сохраняется в настройках, как из баннера
+func (a *App) ShowUpdate(info *updater.ReleaseInfo) {
+	skip := func() {
+		a.SkipUpdateVersion(info.Version)
+	}
+	if !a.appSettings.AllowSelfUpdate {
+		updater.ShowUpdateDialog(a.window, info, skip)
+		return
+	}
+
+	updater.ShowInstallableUpdateDialog(a.window, info, func() {
+		a.onInstallUpdate(info)
+	}, skip)
+}
+
+// onInstallUpdate запрашивает подтверждение и устанавливает обновление с перезапуском
+func (a *App) onInstallUpdate(info *updater.ReleaseInfo) {
+	a.ShowConfirm(
+		"Установка обновления",
+		fmt.Sprintf("Будет загружена и установлена версия %s, после чего приложение перезапустится.\n"+
+			"Несохраненные результаты будут потеряны. Продолжить?", info.Version),
+		func(confirmed bool) {
+			if confirmed {
+				a.installUpdate(info)
+			}
+		},
+	)
+}
+
+// installUpdate загружает и применяет обновление, затем перезапускает приложение
+// Загрузку можно отменить: недокачанный файл сохраняется, и следующая установка продолжит ее
+func (a *App) installUpdate(info *updater.ReleaseInfo) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	status := widget.NewLabel(fmt.Sprintf("Загрузка версии %s...", info.Version))
+	bar := widget.NewProgressBar()
+	cancelBtn := widget.NewButton("Отменить загрузку", cancel)
+
+	progress := dialog.NewCustomWithoutButtons(
+		"Установка обновления",
+		container.NewVBox(status, bar, cancelBtn),
+		a.window,
+	)
+	progress.Resize(fyne.NewSize(420, 0))
+	progress.Show()
+
+	lastPercent := -1
+	onProgress := func(done, total int64) {
+		// Обновляем окно только при смене процента, чтобы не перегружать интерфейс
+		percent := 0
+		if total > 0 {
+			percent = int(done * 100 / total)
+		}
+		if percent == lastPercent && done != total {
+			return
+		}
+		lastPercent = percent
+		fyne.Do(func() {
+			if total > 0 {
+				bar.SetValue(float64(done) / float64(total))
+				status.SetText(fmt.Sprintf("Загрузка версии %s: %s из %s", info.Version,
+					updater.FormatAssetSize(done), updater.FormatAssetSize(total)))
+			} else {
+				status.SetText(fmt.Sprintf("Загрузка версии %s: %s", info.Version, updater.FormatAssetSize(done)))
+			}
+		})
+	}
+	onDownloaded := func() {
+		fyne.Do(func() {
+			bar.SetValue(1)
+			status.SetText(fmt.Sprintf("Установка версии %s...", info.Version))
+			cancelBtn.Disable()
+		})
+	}
+
+	go func() {
+		defer cancel()
+		defer apperrors.Recover(a.logger, "установка обновления", func(err error) {
+			fyne.Do(func() {
+				progress.Hide()
+				a.ShowError(err)
+			})
+		})
+
+		exePath, err := installUpdateFiles(ctx, info, onProgress, onDownloaded)
+
+		fyne.Do(func() {
+			progress.Hide()
+			if errors.Is(err, context.Canceled) {
+				a.logger.Info("Загрузка обновления отменена", "version", info.Version)
+				a.ShowInfo("Установка обновления",
+					"Загрузка отменена. При следующей установке она продолжится с места остановки.")
+				return
+			}
+			if err != nil {
+				a.logger.Error("Не удалось установить обновление", "version", info.Version, "error", err)
+				a.ShowError(err)
+				return
+			}
+
+			a.logger.Info("Обновление установлено, перезапуск", "version", info.Version)
+			if err := updater.Relaunch(exePath, os.Args[1:]); err != nil {
+				a.ShowError(err)
+				return
+			}
+			a.fyneApp.Quit()
+		})
+	}()
+}
+
+// installUpdateFiles загружает сборку обновления, сверяет ее контрольную сумму и заменяет ею исполняемый файл
+// Сборка загружается в постоянную директорию пользователя, чтобы прерванную загрузку можно было
+// продолжить. onDownloaded вызывается после загрузки; отмена ctx прерывает только загрузку.
+// Возвращает путь к обновленному исполняемому файлу
+func installUpdateFiles(ctx context.Context, info *updater.ReleaseInfo, onProgress updater.DownloadProgress, onDownloaded func()) (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить путь к приложению: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+	if info.Asset == nil {
+		return "", fmt.Errorf("файл обновления для текущей платформы не найден")
+	}
+
+	downloadDir, err := updater.DownloadDir()
+	if err != nil {
+		return "", err
+	}
+
+	downloadCtx, cancel := context.WithTimeout(ctx, updateInstallTimeout)
+	defer cancel()
+
+	archivePath := filepath.Join(downloadDir, info.Version+"-"+filepath.Base(info.Asset.Name))
+	if err := updater.DownloadAssetWithProgress(downloadCtx, info.Asset, archivePath, onProgress); err != nil {
+		return "", err
+	}
+	defer os.Remove(archivePath)
+
+	// Сборка заменяет исполняемый файл, поэтому устанавливается только после сверки с контрольной суммой релиза
+	if err := updater.VerifyAssetChecksum(downloadCtx, info.Checksums, info.Asset.Name, archivePath); err != nil {
+		return "", err
+	}
+	onDownloaded()
+
+	installCtx, cancelInstall := context.WithTimeout(context.Background(), updateInstallTimeout)
+	defer cancelInstall()
+
+	if err := updater.ApplyUpdate(installCtx, archivePath, exePath, info.Version); err != nil {
+		return "", err
+	}
+
+	return exePath, nil
 }
 
 // showAboutDialog показывает диалог "О программе"
@@ -249,12 +773,76 @@ func (a *App) showAboutDialog() {
 // onClose обработчик закрытия приложения
 func (a *App) onClose() {
 	a.logger.Info("Application closing")
+	if a.appSettings.AutosaveProfile {
+		if err := a.autosaver.Flush(); err != nil {
+			a.logger.Warn("не удалось автосохранить профиль при закрытии", "error", err)
+		}
+	}
+	if a.mergeTab != nil {
+		a.mergeTab.releaseResult()
+	}
+	a.readers.CloseAll()
 	a.window.Close()
 }
 
 // UpdateProfile обновляет текущий профиль
 func (a *App) UpdateProfile(profile *core.Profile) {
 	a.currentProfile = profile
+	if a.mergeTab != nil {
+		a.mergeTab.refreshStyleTemplate()
+		a.mergeTab.refreshOnErrorPolicy()
+		a.mergeTab.refreshMergeIntoBase()
+		a.mergeTab.refreshPreserveConditionalFormats()
+		a.mergeTab.refreshSkipDuplicates()
+		a.mergeTab.refreshProtectHeaders()
+	}
+}
+
+// ProfileChanged отмечает изменение текущего профиля и откладывает его автосохранение
+func (a *App) ProfileChanged() {
+	if a.currentProfile == nil || !a.appSettings.AutosaveProfile {
+		return
+	}
+	a.autosaver.Schedule(a.currentProfile)
+}
+
+// discardAutosave отменяет отложенное автосохранение и удаляет сохраненное
+func (a *App) discardAutosave() {
+	a.autosaver.Cancel()
+	if err := a.configManager.ClearAutosave(); err != nil {
+		a.logger.Warn("не удалось удалить автосохранение профиля", "error", err)
+	}
+}
+
+// offerAutosaveRestore предлагает восстановить автосохраненный профиль при запуске
+// При отказе автосохранение удаляется
+func (a *App) offerAutosaveRestore() {
+	if !a.appSettings.AutosaveProfile {
+		return
+	}
+
+	profile, err := a.configManager.LoadAutosave()
+	if err != nil {
+		a.logger.Warn("не удалось загрузить автосохранение профиля", "error", err)
+		return
+	}
+	if profile == nil {
+		return
+	}
+
+	message := fmt.Sprintf("Найден несохраненный профиль '%s' от %s.\nВосстановить его?",
+		profile.ProfileName, profile.UpdatedAt.Local().Format("02.01.2006 15:04"))
+	a.ShowConfirm("Восстановление профиля", message, func(restore bool) {
+		if !restore {
+			a.discardAutosave()
+			a.logger.Info("Autosaved profile discarded", "name", profile.ProfileName)
+			return
+		}
+
+		a.UpdateProfile(profile)
+		a.baseFileTab.LoadProfile(profile)
+		a.logger.Info("Autosaved profile restored", "name", profile.ProfileName)
+	})
 }
 
 // GetProfile возвращает текущий профиль
@@ -280,6 +868,63 @@ func (a *App) GetSettings() *config.AppSettings {
 	return a.appSettings
 }
 
+// SetUpdateChecker устанавливает механизм проверки обновлений и текущую версию приложения
+func (a *App) SetUpdateChecker(checker updater.Checker, version string) {
+	a.appVersion = version
+	a.updateRunner = updater.NewCheckRunner(checker)
+}
+
+// GetUpdateRunner возвращает исполнитель проверок обновлений (nil, если не настроен)
+func (a *App) GetUpdateRunner() *updater.CheckRunner {
+	return a.updateRunner
+}
+
+// SetLastOutputPath запоминает путь к последнему сохраненному результату
+func (a *App) SetLastOutputPath(path string) {
+	a.appSettings.LastOutputPath = path
+	if err := a.configManager.SaveSettings(a.appSettings); err != nil {
+		a.logger.Error("не удалось сохранить настройки", "error", err)
+	}
+}
+
+// GetUpdateSchedule возвращает расписание автоматической проверки обновлений
+func (a *App) GetUpdateSchedule() updater.CheckSchedule {
+	return updater.CheckSchedule{
+		Enabled:       a.appSettings.CheckUpdates,
+		Interval:      time.Duration(a.appSettings.CheckIntervalHours) * time.Hour,
+		LastCheckedAt: a.appSettings.LastCheckedAt,
+		Failures:      a.appSettings.UpdateCheckFailures,
+	}
+}
+
+// RecordUpdateCheck сохраняет результат фоновой проверки обновлений в настройках
+func (a *App) RecordUpdateCheck(err error) {
+	fyne.Do(func() {
+		a.recordUpdateCheck(err)
+	})
+}
+
+// recordUpdateCheck фиксирует попытку проверки обновлений (вызывается в UI потоке)
+func (a *App) recordUpdateCheck(err error) {
+	a.appSettings.RecordUpdateCheck(time.Now(), err != nil)
+	if saveErr := a.configManager.SaveSettings(a.appSettings); saveErr != nil {
+		a.logger.Error("не удалось сохранить настройки", "error", saveErr)
+	}
+	if a.settingsTab != nil {
+		a.settingsTab.RefreshUpdateStatus()
+	}
+}
+
+// SetDialogs задает диалоги выбора файлов и папок (например, заглушки в тестах)
+func (a *App) SetDialogs(dialogs native.Dialogs) {
+	a.dialogs = dialogs
+}
+
+// SetProfiling задает снятие профилей вокруг каждого объединения
+func (a *App) SetProfiling(cfg profiling.Config) {
+	a.profiling = cfg
+}
+
 // GetWindow возвращает главное окно приложения
 func (a *App) GetWindow() fyne.Window {
 	return a.window
@@ -2,15 +2,18 @@ package gui
 
 import (
 	"fmt"
-	"path/filepath"
 	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/DatKorso/Merge-excel/internal/config"
 	"github.com/DatKorso/Merge-excel/internal/core"
 	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/excel"
 	"github.com/DatKorso/Merge-excel/internal/native"
 )
 
@@ -24,14 +27,38 @@ type BaseFileTab struct {
 	sheetList          *widget.List
 	profileNameEntry   *widget.Entry
 	useOzonTemplateChk *widget.Check // Чекбокс для шаблона Ozon
+	resetPresetBtn     *widget.Button
 	
 	// Панель настройки листа
 	configPanel       *fyne.Container
 	sheetNameLabel    *widget.Label
 	headerRowEntry    *widget.Entry
 	previewBtn        *widget.Button
+	filterPreviewBtn  *widget.Button
+	columnStatsBtn    *widget.Button
+	tabColorEntry     *widget.Entry
+	columnTypesEntry  *widget.Entry
+	constantsEntry    *widget.Entry
+	outputSheetEntry  *widget.Entry
+	sourceNamesEntry  *widget.Entry
+	sourcePatternEntry *widget.Entry
+	dataRangeEntry    *widget.Entry
+	typeRowEntry      *widget.Entry
+	dedupKeyEntry     *widget.Entry
+	coalesceEntry     *widget.Entry
+	groupByEntry      *widget.Entry
 	headerPreviewText *widget.Label
 
+	dataStartColumnEntry *widget.Entry
+	dataStartMarkerEntry *widget.Entry
+	rawCellFallbackChk   *widget.Check
+	skipProtectionChk    *widget.Check
+	rawTextEntry         *widget.Entry
+	valueMapsEntry       *widget.Entry
+	affixEntry           *widget.Entry
+	separatorSelect      *widget.Select
+	copySettingsBtn      *widget.Button
+
 	// Данные
 	sheets        []core.SheetConfig
 	selectedSheet int
@@ -67,10 +94,14 @@ func (t *BaseFileTab) Build() fyne.CanvasObject {
 	t.profileNameEntry.SetPlaceHolder("Введите имя профиля")
 
 	// Чекбокс для использования шаблона Ozon
-	t.useOzonTemplateChk = widget.NewCheck("Использовать шаблон Ozon (листы: Шаблон, Озон.Видео, Озон.Видеообложка + фильтрация по бренду Shuzzi)", func(checked bool) {
+	t.useOzonTemplateChk = widget.NewCheck("Использовать шаблон Ozon (листы: Шаблон, Озон.Видео, Озон.Видеообложка + фильтрация по брендам из настроек)", func(checked bool) {
 		t.onOzonTemplateToggled(checked)
 	})
 	
+	t.resetPresetBtn = widget.NewButton("Сбросить листы к шаблону", func() {
+		t.onResetToPreset()
+	})
+
 	// Загружаем настройку из конфига
 	if settings := t.app.GetSettings(); settings != nil {
 		t.useOzonTemplateChk.Checked = settings.UseOzonTemplate
@@ -143,6 +174,93 @@ func (t *BaseFileTab) Build() fyne.CanvasObject {
 		t.onPreviewHeaders()
 	})
 	t.previewBtn.Disable() // Включается при выборе листа
+
+	t.filterPreviewBtn = widget.NewButton("Предпросмотр фильтра", func() {
+		t.onPreviewFilter()
+	})
+	t.filterPreviewBtn.Disable() // Включается для листов с фильтрацией
+
+	t.columnStatsBtn = widget.NewButton("Значения столбца для фильтра...", func() {
+		t.onColumnStats()
+	})
+	t.columnStatsBtn.Disable() // Включается при выборе листа
+
+	t.tabColorEntry = widget.NewEntry()
+	t.tabColorEntry.SetPlaceHolder("#RRGGBB (пусто - без цвета)")
+	t.tabColorEntry.Disable() // Включается при выборе листа
+
+	t.columnTypesEntry = widget.NewEntry()
+	t.columnTypesEntry.SetPlaceHolder("Например: C:number, F:date (пусто - без проверки)")
+	t.columnTypesEntry.Disable() // Включается при выборе листа
+
+	t.typeRowEntry = widget.NewEntry()
+	t.typeRowEntry.SetPlaceHolder("Номер строки шапки с типами полей (пусто - не используется)")
+	t.typeRowEntry.Disable() // Включается при выборе листа
+
+	t.constantsEntry = widget.NewEntry()
+	t.constantsEntry.SetPlaceHolder("Например: Категория=Обувь; Кампания=SALE-25")
+	t.constantsEntry.Disable() // Включается при выборе листа
+
+	t.outputSheetEntry = widget.NewEntry()
+	t.outputSheetEntry.SetPlaceHolder("Пусто - лист с тем же именем")
+	t.outputSheetEntry.Disable() // Включается при выборе листа
+
+	t.sourceNamesEntry = widget.NewEntry()
+	t.sourceNamesEntry.SetPlaceHolder("Например: Прайс; Price; Остатки")
+	t.sourceNamesEntry.Disable() // Включается при выборе листа
+
+	t.sourcePatternEntry = widget.NewEntry()
+	t.sourcePatternEntry.SetPlaceHolder("Часть имени, например: прайс (если лист не найден по имени)")
+	t.sourcePatternEntry.Disable() // Включается при выборе листа
+
+	t.dataRangeEntry = widget.NewEntry()
+	t.dataRangeEntry.SetPlaceHolder("Имя диапазона или таблицы, например: ШаблонДанные (пусто - по номеру строки)")
+	t.dataRangeEntry.Disable() // Включается при выборе листа
+
+	t.dedupKeyEntry = widget.NewEntry()
+	t.dedupKeyEntry.SetPlaceHolder("Заголовок столбца-ключа, например: Артикул (пусто - не склеивать)")
+	t.dedupKeyEntry.Disable() // Включается при выборе листа
+
+	t.coalesceEntry = widget.NewEntry()
+	t.coalesceEntry.SetPlaceHolder("Например: Теги=join-unique(, ); Бренд=first (по умолчанию first)")
+	t.coalesceEntry.Disable() // Включается при выборе листа
+
+	t.groupByEntry = widget.NewEntry()
+	t.groupByEntry.SetPlaceHolder("Заголовок столбца, например: Бренд (при фильтре - по значениям фильтра)")
+	t.groupByEntry.Disable() // Включается при выборе листа
+
+	t.dataStartColumnEntry = widget.NewEntry()
+	t.dataStartColumnEntry.SetPlaceHolder("Буква столбца, например: A (пусто - данные сразу после заголовков)")
+	t.dataStartColumnEntry.Disable() // Включается при выборе листа
+
+	t.dataStartMarkerEntry = widget.NewEntry()
+	t.dataStartMarkerEntry.SetPlaceHolder("Значение строки-маркера, например: Артикул (пусто - первая заполненная ячейка)")
+	t.dataStartMarkerEntry.Disable() // Включается при выборе листа
+
+	t.rawCellFallbackChk = widget.NewCheck("Читать значения, скрытые форматом ячеек (медленнее)", nil)
+	t.rawCellFallbackChk.Disable() // Включается при выборе листа
+
+	t.skipProtectionChk = widget.NewCheck("Не защищать заголовки этого листа", nil)
+	t.skipProtectionChk.Disable() // Включается при выборе листа
+
+	t.rawTextEntry = widget.NewEntry()
+	t.rawTextEntry.SetPlaceHolder("Заголовки через ;, например: Озон.Видео: ссылка")
+	t.rawTextEntry.Disable() // Включается при выборе листа
+
+	t.valueMapsEntry = widget.NewEntry()
+	t.valueMapsEntry.SetPlaceHolder("Например: F: да=Yes, 1=Yes, нет=No (пусто - без замен)")
+	t.valueMapsEntry.Disable() // Включается при выборе листа
+
+	t.affixEntry = widget.NewEntry()
+	t.affixEntry.SetPlaceHolder("Например: Артикул: SHZ-*; Штрихкод: *-RU (пусто - без изменений)")
+	t.affixEntry.Disable() // Включается при выборе листа
+
+	separatorLabels := make([]string, len(separatorOptions))
+	for i, option := range separatorOptions {
+		separatorLabels[i] = option.label
+	}
+	t.separatorSelect = widget.NewSelect(separatorLabels, nil)
+	t.separatorSelect.Disable() // Включается при выборе листа
 	
 	t.headerPreviewText = widget.NewLabel("Выберите лист слева для настройки")
 	t.headerPreviewText.Wrapping = fyne.TextWrapWord
@@ -151,6 +269,15 @@ func (t *BaseFileTab) Build() fyne.CanvasObject {
 		t.onApplySheetConfig()
 	})
 	applyBtn.Importance = widget.HighImportance
+
+	t.copySettingsBtn = widget.NewButton("Скопировать настройки на другие листы...", func() {
+		t.onCopySheetSettings()
+	})
+	t.copySettingsBtn.Disable() // Включается при выборе листа
+
+	bulkHeaderRowBtn := widget.NewButton("Строка заголовков для нескольких листов...", func() {
+		t.onBulkHeaderRow()
+	})
 	
 	t.configPanel = container.NewVBox(
 		widget.NewLabel("Настройка выбранного листа:"),
@@ -163,10 +290,82 @@ func (t *BaseFileTab) Build() fyne.CanvasObject {
 		container.NewVBox(
 			widget.NewLabel("Номер строки с заголовками:"),
 			t.headerRowEntry,
+			widget.NewLabel("Область данных из именованного диапазона или таблицы Excel:"),
+			t.dataRangeEntry,
 			t.previewBtn,
+			t.filterPreviewBtn,
+			t.columnStatsBtn,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Цвет ярлыка листа в результате:"),
+			t.tabColorEntry,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Проверка типов данных столбцов:"),
+			t.columnTypesEntry,
+			t.typeRowEntry,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Столбцы с фиксированным значением:"),
+			t.constantsEntry,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Объединить в лист результата:"),
+			t.outputSheetEntry,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Другие имена листа в файлах:"),
+			t.sourceNamesEntry,
+			t.sourcePatternEntry,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Склейка дубликатов:"),
+			t.dedupKeyEntry,
+			t.coalesceEntry,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Разбивка итогов по значениям столбца:"),
+			t.groupByEntry,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Начало данных после пояснений под заголовками:"),
+			t.dataStartColumnEntry,
+			t.dataStartMarkerEntry,
+		),
+		widget.NewSeparator(),
+		t.rawCellFallbackChk,
+		t.skipProtectionChk,
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Столбцы без преобразований (ссылки, коды):"),
+			t.rawTextEntry,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Замены значений (Да/Нет, 1/0):"),
+			t.valueMapsEntry,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Префикс и суффикс значений (код продавца):"),
+			t.affixEntry,
+		),
+		widget.NewSeparator(),
+		container.NewVBox(
+			widget.NewLabel("Разделители между файлами:"),
+			t.separatorSelect,
 		),
 		widget.NewSeparator(),
 		applyBtn,
+		t.copySettingsBtn,
 	)
 
 	// Добавляем увеличенный padding вокруг панели настроек (двойной)
@@ -179,7 +378,7 @@ func (t *BaseFileTab) Build() fyne.CanvasObject {
 		container.NewPadded(
 			container.NewPadded(
 				container.NewBorder(
-					widget.NewLabel("Листы в файле:"), nil, nil, nil,
+					widget.NewLabel("Листы в файле:"), bulkHeaderRowBtn, nil, nil,
 					t.sheetList,
 				),
 			),
@@ -199,7 +398,7 @@ func (t *BaseFileTab) Build() fyne.CanvasObject {
 			widget.NewLabel("Имя профиля:"),
 			t.profileNameEntry,
 			widget.NewSeparator(),
-			t.useOzonTemplateChk, // Добавляем чекбокс шаблона
+			container.NewBorder(nil, nil, nil, t.resetPresetBtn, t.useOzonTemplateChk), // Чекбокс шаблона и сброс к нему
 			widget.NewSeparator(),
 			widget.NewLabel("Шаг 2: Настройте листы для объединения"),
 		),
@@ -215,25 +414,25 @@ func (t *BaseFileTab) Build() fyne.CanvasObject {
 
 // onSelectFile обработчик выбора файла
 func (t *BaseFileTab) onSelectFile() {
-	// Открываем нативный диалог выбора файла
-	filename, err := native.FileOpenDialog(
-		"Выбрать базовый Excel файл",
-		"Excel файлы",
-		"xlsx",
-	)
-	
-	// Проверяем отмену пользователем
-	if native.IsCancelled(err) {
-		return
-	}
-	
-	if err != nil {
-		t.app.ShowError(err)
-		return
-	}
+	t.app.dialogs.OpenFile("Выбрать базовый Excel файл", excelFileFilter, func(filename string, err error) {
+		// Проверяем отмену пользователем
+		if native.IsCancelled(err) {
+			return
+		}
+
+		if err != nil {
+			t.app.ShowError(err)
+			return
+		}
+
+		t.selectFile(filename)
+	})
+}
 
+// selectFile устанавливает выбранный базовый файл и анализирует его
+func (t *BaseFileTab) selectFile(filename string) {
 	// Проверяем расширение файла
-	if filepath.Ext(filename) != ".xlsx" {
+	if excel.WorkbookExt(filename) != ".xlsx" {
 		t.app.ShowError(apperrors.NewInvalidFormatError(filename))
 		return
 	}
@@ -249,54 +448,22 @@ func (t *BaseFileTab) onSelectFile() {
 
 // analyzeFile анализирует выбранный файл и загружает листы
 func (t *BaseFileTab) analyzeFile(filePath string) {
-	// Анализируем файл
-	sheetNames, err := t.app.analyzer.GetSheetNames(filePath)
+	// Записи журнала анализа помечаются идентификатором сеанса
+	runID := t.app.analyzer.StartSession()
+	t.app.logger.Info("Base file analysis started", "path", filePath, "run_id", runID)
+
+	// Создаем выключенные конфигурации листов со строкой заголовков из настроек
+	headerRow := t.app.GetSettings().HeaderRowForNewSheets()
+	sheets, err := t.app.analyzer.AnalyzeSheets(filePath, headerRow)
 	if err != nil {
 		t.app.ShowError(err)
 		return
 	}
-
-	// Создаем конфигурации для каждого листа
-	t.sheets = make([]core.SheetConfig, 0, len(sheetNames))
-	for _, name := range sheetNames {
-		t.sheets = append(t.sheets, core.SheetConfig{
-			SheetName: name,
-			Enabled:   false, // По умолчанию выключены
-			HeaderRow: 1,     // По умолчанию первая строка
-			Headers:   []string{},
-		})
-	}
+	t.sheets = sheets
 
 	// Применяем шаблон Ozon, если он включен
 	if t.useOzonTemplateChk.Checked {
-		template := t.app.configManager.GetOzonTemplate()
-		for i := range t.sheets {
-			sheet := &t.sheets[i]
-			if config, exists := template[sheet.SheetName]; exists {
-				sheet.Enabled = config.Enabled
-				sheet.HeaderRow = config.HeaderRow
-				sheet.FilterValues = config.FilterValues
-				
-				// Для листа "Шаблон" автоматически определяем столбец фильтрации
-				if sheet.SheetName == "Шаблон" && len(config.FilterValues) > 0 {
-					columnIndex, err := t.app.analyzer.FindBrandColumnInFirstRows(filePath, sheet.SheetName, sheet.HeaderRow)
-					if err != nil {
-						t.app.logger.Warn("не удалось найти столбец бренда для фильтрации", "error", err, "sheet", sheet.SheetName)
-					} else if columnIndex >= 0 {
-						sheet.FilterColumn = columnIndex
-						t.app.logger.Info("автоматически определен столбец фильтрации",
-							"sheet", sheet.SheetName,
-							"column_index", columnIndex,
-							"filter_values", sheet.FilterValues)
-					} else {
-						t.app.logger.Warn("столбец 'Бренд в одежде и обуви*' не найден, фильтрация не будет применена", "sheet", sheet.SheetName)
-						sheet.FilterColumn = -1
-					}
-				}
-				
-				t.app.logger.Debug("applied Ozon template on load", "sheet", sheet.SheetName, "enabled", sheet.Enabled, "header_row", sheet.HeaderRow)
-			}
-		}
+		t.applyPresetSheets(filePath)
 	}
 
 	// Устанавливаем флаг обновления UI и обновляем список
@@ -315,9 +482,45 @@ func (t *BaseFileTab) analyzeFile(filePath string) {
 	profile.Sheets = t.sheets
 
 	t.app.UpdateProfile(profile)
+	t.app.ProfileChanged()
+
+	t.selectActiveSheet(filePath)
+
+	message := fmt.Sprintf("Найдено листов: %d", len(t.sheets))
+	if groups, err := t.app.analyzer.FindSheetsWithSameHeaders(filePath, headerRow); err != nil {
+		t.app.logger.Warn("не удалось сравнить структуру листов", "error", err)
+	} else if len(groups) > 0 {
+		message += "\n\nЛисты с одинаковыми заголовками можно объединить в один лист результата " +
+			"(поле «Объединить в лист результата»):"
+		for _, group := range groups {
+			message += "\n  • " + strings.Join(group, ", ")
+		}
+	}
+	t.app.ShowInfo("Файл загружен", message)
+	t.app.logger.Info("File analyzed", "sheets_count", len(t.sheets), "header_row", headerRow)
+}
+
+// selectActiveSheet выбирает в списке лист, активный в файле
+// Если активный лист определить не удалось, выбирается первый лист
+func (t *BaseFileTab) selectActiveSheet(filePath string) {
+	if len(t.sheets) == 0 {
+		return
+	}
+
+	selected := 0
+	activeName, err := t.app.analyzer.GetActiveSheetName(filePath)
+	if err != nil {
+		t.app.logger.Warn("не удалось определить активный лист", "error", err)
+	}
+	for i, sheet := range t.sheets {
+		if sheet.SheetName == activeName {
+			selected = i
+			break
+		}
+	}
 
-	t.app.ShowInfo("Файл загружен", fmt.Sprintf("Найдено листов: %d", len(sheetNames)))
-	t.app.logger.Info("File analyzed", "sheets_count", len(sheetNames))
+	t.sheetList.Select(widget.ListItemID(selected))
+	t.sheetList.ScrollTo(widget.ListItemID(selected))
 }
 
 // updateConfigPanel обновляет панель настройки для выбранного листа
@@ -327,7 +530,48 @@ func (t *BaseFileTab) updateConfigPanel() {
 		t.sheetNameLabel.SetText("Не выбран")
 		t.headerRowEntry.SetText("")
 		t.headerRowEntry.Disable()
+		t.tabColorEntry.SetText("")
+		t.tabColorEntry.Disable()
+		t.columnTypesEntry.SetText("")
+		t.columnTypesEntry.Disable()
+		t.typeRowEntry.SetText("")
+		t.typeRowEntry.Disable()
+		t.constantsEntry.SetText("")
+		t.constantsEntry.Disable()
+		t.outputSheetEntry.SetText("")
+		t.outputSheetEntry.Disable()
+		t.sourceNamesEntry.SetText("")
+		t.sourceNamesEntry.Disable()
+		t.sourcePatternEntry.SetText("")
+		t.sourcePatternEntry.Disable()
+		t.dataRangeEntry.SetText("")
+		t.dataRangeEntry.Disable()
+		t.dedupKeyEntry.SetText("")
+		t.dedupKeyEntry.Disable()
+		t.coalesceEntry.SetText("")
+		t.coalesceEntry.Disable()
+		t.groupByEntry.SetText("")
+		t.groupByEntry.Disable()
+		t.dataStartColumnEntry.SetText("")
+		t.dataStartColumnEntry.Disable()
+		t.dataStartMarkerEntry.SetText("")
+		t.dataStartMarkerEntry.Disable()
+		t.rawCellFallbackChk.SetChecked(false)
+		t.rawCellFallbackChk.Disable()
+		t.skipProtectionChk.SetChecked(false)
+		t.skipProtectionChk.Disable()
+		t.rawTextEntry.SetText("")
+		t.rawTextEntry.Disable()
+		t.valueMapsEntry.SetText("")
+		t.valueMapsEntry.Disable()
+		t.affixEntry.SetText("")
+		t.affixEntry.Disable()
+		t.separatorSelect.ClearSelected()
+		t.separatorSelect.Disable()
 		t.previewBtn.Disable()
+		t.filterPreviewBtn.Disable()
+		t.columnStatsBtn.Disable()
+		t.copySettingsBtn.Disable()
 		t.headerPreviewText.SetText("Выберите лист слева для настройки")
 		return
 	}
@@ -338,7 +582,59 @@ func (t *BaseFileTab) updateConfigPanel() {
 	t.sheetNameLabel.SetText(sheet.SheetName)
 	t.headerRowEntry.SetText(strconv.Itoa(sheet.HeaderRow))
 	t.headerRowEntry.Enable()
+	t.tabColorEntry.SetText(sheet.TabColor)
+	t.tabColorEntry.Enable()
+	t.columnTypesEntry.SetText(core.FormatColumnTypes(sheet.ColumnTypes))
+	t.columnTypesEntry.Enable()
+	t.typeRowEntry.SetText("")
+	if sheet.TypeDescriptorRow > 0 {
+		t.typeRowEntry.SetText(strconv.Itoa(sheet.TypeDescriptorRow))
+	}
+	t.typeRowEntry.Enable()
+	t.constantsEntry.SetText(core.FormatConstantColumns(sheet.ConstantColumns))
+	t.constantsEntry.Enable()
+	t.outputSheetEntry.SetText(sheet.OutputSheet)
+	t.outputSheetEntry.Enable()
+	t.sourceNamesEntry.SetText(core.FormatSheetNames(sheet.SourceSheetNames))
+	t.sourceNamesEntry.Enable()
+	t.sourcePatternEntry.SetText(sheet.SourceSheetPattern)
+	t.sourcePatternEntry.Enable()
+	t.dataRangeEntry.SetText(sheet.DataRangeName)
+	t.dataRangeEntry.Enable()
+	t.dedupKeyEntry.SetText(sheet.DedupKey)
+	t.dedupKeyEntry.Enable()
+	t.coalesceEntry.SetText(core.FormatCoalesceColumns(sheet.Coalesce))
+	t.coalesceEntry.Enable()
+	t.groupByEntry.SetText(sheet.GroupByColumn)
+	t.groupByEntry.Enable()
+	t.dataStartColumnEntry.SetText(sheet.DataStartColumn)
+	t.dataStartColumnEntry.Enable()
+	t.dataStartMarkerEntry.SetText(sheet.DataStartMarker)
+	t.dataStartMarkerEntry.Enable()
+	t.rawCellFallbackChk.SetChecked(sheet.RawCellFallback)
+	t.rawCellFallbackChk.Enable()
+	t.skipProtectionChk.SetChecked(sheet.SkipHeaderProtection)
+	t.skipProtectionChk.Enable()
+	t.rawTextEntry.SetText(strings.Join(sheet.RawTextColumns, "; "))
+	t.rawTextEntry.Enable()
+	t.valueMapsEntry.SetText(core.FormatValueMaps(sheet.ValueMaps))
+	t.valueMapsEntry.Enable()
+	t.affixEntry.SetText(core.FormatAffixRules(sheet.AffixRules))
+	t.affixEntry.Enable()
+	t.separatorSelect.SetSelected(separatorOptionLabel(sheet.SeparatorMode))
+	t.separatorSelect.Enable()
 	t.previewBtn.Enable()
+	t.columnStatsBtn.Enable()
+	if len(t.sheets) > 1 {
+		t.copySettingsBtn.Enable()
+	} else {
+		t.copySettingsBtn.Disable()
+	}
+	if sheet.FilterColumn >= 0 && len(sheet.FilterValues) > 0 {
+		t.filterPreviewBtn.Enable()
+	} else {
+		t.filterPreviewBtn.Disable()
+	}
 	
 	if len(sheet.Headers) > 0 {
 		t.headerPreviewText.SetText(t.formatHeaders(sheet.Headers))
@@ -353,9 +649,9 @@ func (t *BaseFileTab) onPreviewHeaders() {
 		return
 	}
 
-	headerRow, err := strconv.Atoi(t.headerRowEntry.Text)
-	if err != nil || headerRow < 1 {
-		t.app.ShowError(apperrors.NewInvalidHeaderRowError(headerRow))
+	headerRow, err := parseHeaderRow(t.headerRowEntry.Text)
+	if err != nil {
+		t.app.ShowError(err)
 		return
 	}
 
@@ -380,20 +676,315 @@ func (t *BaseFileTab) onPreviewHeaders() {
 	t.app.logger.Info("Headers previewed", "sheet", sheet.SheetName, "header_row", headerRow, "count", len(headers))
 }
 
+// parseHeaderRow разбирает введенный номер строки заголовков
+// Ошибка для текста, который не является числом, содержит сам введенный текст
+func parseHeaderRow(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	headerRow, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, apperrors.NewInvalidHeaderRowInputError(text)
+	}
+	if headerRow < 1 {
+		return 0, apperrors.NewInvalidHeaderRowError(headerRow)
+	}
+	return headerRow, nil
+}
+
+// separatorOptions режимы разделителей между файлами и их названия в списке
+var separatorOptions = []struct{ mode, label string }{
+	{"", "Без разделителей"},
+	{core.SeparatorBlankRow, "Пустая строка"},
+	{core.SeparatorLabelRow, "Строка с именем файла"},
+}
+
+// separatorOptionLabel возвращает название режима разделителей mode для списка
+func separatorOptionLabel(mode string) string {
+	for _, option := range separatorOptions {
+		if option.mode == mode {
+			return option.label
+		}
+	}
+	return separatorOptions[0].label
+}
+
+// separatorOptionMode возвращает режим разделителей по названию из списка
+func separatorOptionMode(label string) string {
+	for _, option := range separatorOptions {
+		if option.label == label {
+			return option.mode
+		}
+	}
+	return ""
+}
+
+// filterPreviewSampleSize количество примеров строк в предпросмотре фильтра
+const filterPreviewSampleSize = 10
+
+// onPreviewFilter показывает примеры строк, которые будут исключены фильтром
+func (t *BaseFileTab) onPreviewFilter() {
+	if t.selectedSheet < 0 || t.selectedSheet >= len(t.sheets) {
+		return
+	}
+
+	sheet := &t.sheets[t.selectedSheet]
+	rules := core.FilterRules{
+		Column: sheet.FilterColumn,
+		Values: sheet.FilterValues,
+	}
+
+	preview, err := t.app.analyzer.PreviewFilter(t.app.GetBaseFile(), sheet.SheetName, sheet.HeaderRow, rules, filterPreviewSampleSize)
+	if err != nil {
+		t.app.ShowError(err)
+		return
+	}
+
+	message := fmt.Sprintf("Всего строк: %d\nОстанется: %d\nБудет удалено: %d",
+		preview.TotalRows, preview.KeptCount, preview.ExcludedCount)
+	if len(preview.Excluded) > 0 {
+		message += fmt.Sprintf("\n\nПримеры удаляемых строк (первые %d):", len(preview.Excluded))
+		for _, row := range preview.Excluded {
+			// Показываем только первые столбцы, чтобы строка помещалась в диалог
+			if len(row) > 5 {
+				row = append(row[:5:5], "...")
+			}
+			message += "\n• " + strings.Join(row, " | ")
+		}
+	}
+
+	t.app.ShowInfo("Предпросмотр фильтра", message)
+	t.app.logger.Info("Filter previewed", "sheet", sheet.SheetName, "kept", preview.KeptCount, "excluded", preview.ExcludedCount)
+}
+
+// onColumnStats показывает значения выбранного столбца листа и позволяет отметить,
+// строки с какими значениями оставить в результате. Значения фильтра выбираются из данных
+// базового файла, а не вводятся вручную, поэтому опечатка не приводит к пустому результату
+func (t *BaseFileTab) onColumnStats() {
+	if t.selectedSheet < 0 || t.selectedSheet >= len(t.sheets) {
+		return
+	}
+	index := t.selectedSheet
+	sheet := t.sheets[index]
+	baseFile := t.app.GetBaseFile()
+
+	headers := sheet.Headers
+	if len(headers) == 0 {
+		var err error
+		headers, err = t.app.analyzer.GetHeaders(baseFile, sheet.SheetName, sheet.HeaderRow)
+		if err != nil {
+			t.app.ShowError(err)
+			return
+		}
+	}
+	if len(headers) == 0 {
+		t.app.ShowInfo("Значения столбца", fmt.Sprintf("В строке %d нет заголовков", sheet.HeaderRow))
+		return
+	}
+
+	options := make([]string, len(headers))
+	for i, header := range headers {
+		options[i] = fmt.Sprintf("%s: %s", core.ColumnIndexToLetter(i), header)
+	}
+
+	summaryLabel := widget.NewLabel("Выберите столбец, чтобы увидеть его значения")
+	summaryLabel.Wrapping = fyne.TextWrapWord
+	missingLabel := widget.NewLabel("")
+	missingLabel.Wrapping = fyne.TextWrapWord
+	valuesGroup := widget.NewCheckGroup(nil, nil)
+	column := -1
+	valueByOption := make(map[string]string) // Значение по подписи флажка "Shuzzi (3)"
+
+	columnSelect := widget.NewSelect(options, func(selected string) {
+		column = -1
+		for i, option := range options {
+			if option == selected {
+				column = i
+			}
+		}
+		if column < 0 {
+			return
+		}
+
+		stats, err := t.app.analyzer.ColumnStats(baseFile, sheet.SheetName, sheet.HeaderRow, column, 0)
+		if err != nil {
+			t.app.ShowError(err)
+			return
+		}
+		summaryLabel.SetText(formatColumnStats(stats))
+
+		values, err := t.app.analyzer.DistinctValues(baseFile, sheet.SheetName, sheet.HeaderRow, column)
+		if err != nil {
+			t.app.ShowError(err)
+			return
+		}
+		optionByValue := make(map[string]string, len(values))
+		clear(valueByOption)
+		valuesGroup.Options = make([]string, len(values))
+		for i, value := range values {
+			option := fmt.Sprintf("%s (%d)", value.Value, value.Count)
+			valuesGroup.Options[i] = option
+			valueByOption[option] = value.Value
+			optionByValue[value.Value] = option
+		}
+
+		// Отмечаются значения текущего фильтра; значения фильтра, которых нет в данных, перечисляются отдельно
+		valuesGroup.Selected = nil
+		missingLabel.SetText("")
+		if column == sheet.FilterColumn {
+			checked, missing := core.MatchFilterValues(values, sheet.FilterValues)
+			for _, value := range checked {
+				valuesGroup.Selected = append(valuesGroup.Selected, optionByValue[value])
+			}
+			if len(missing) > 0 {
+				missingLabel.SetText("Нет в данных, будут убраны из фильтра: " + strings.Join(missing, ", "))
+			}
+		}
+		valuesGroup.Refresh()
+	})
+	if sheet.FilterColumn >= 0 && sheet.FilterColumn < len(options) {
+		columnSelect.SetSelected(options[sheet.FilterColumn])
+	}
+
+	content := container.NewBorder(
+		container.NewVBox(columnSelect, summaryLabel, missingLabel, widget.NewLabel("Оставить строки со значениями:")),
+		nil, nil, nil,
+		container.NewVScroll(valuesGroup),
+	)
+
+	confirm := dialog.NewCustomConfirm("Значения столбца", "Применить фильтр", "Закрыть", content,
+		func(confirmed bool) {
+			if !confirmed || column < 0 || index >= len(t.sheets) {
+				return
+			}
+
+			target := &t.sheets[index]
+			target.FilterColumn = column
+			target.FilterValues = make([]string, 0, len(valuesGroup.Selected))
+			for _, option := range valuesGroup.Selected {
+				target.FilterValues = append(target.FilterValues, valueByOption[option])
+			}
+			t.updateProfile()
+			t.updateConfigPanel()
+
+			t.app.logger.Info("Sheet filter set from column values", "sheet", target.SheetName, "column", column, "values", len(target.FilterValues))
+		},
+		t.app.window,
+	)
+	confirm.Resize(fyne.NewSize(520, 560))
+	confirm.Show()
+}
+
+// formatColumnStats формирует сводку столбца для диалога значений
+func formatColumnStats(stats *core.ColumnStats) string {
+	text := fmt.Sprintf("Строк: %d, пустых: %d, разных значений: %d",
+		stats.TotalRows, stats.EmptyCount, stats.DistinctCount)
+	switch stats.Type {
+	case core.ColumnTypeNumber:
+		text += fmt.Sprintf("\nЧисла от %s до %s",
+			strconv.FormatFloat(stats.Min, 'f', -1, 64), strconv.FormatFloat(stats.Max, 'f', -1, 64))
+	case core.ColumnTypeDate:
+		text += "\nДаты"
+	}
+	return text
+}
+
 // onApplySheetConfig применяет настройки листа
 func (t *BaseFileTab) onApplySheetConfig() {
 	if t.selectedSheet < 0 || t.selectedSheet >= len(t.sheets) {
 		return
 	}
 
-	headerRow, err := strconv.Atoi(t.headerRowEntry.Text)
-	if err != nil || headerRow < 1 {
-		t.app.ShowError(apperrors.NewInvalidHeaderRowError(headerRow))
+	headerRow, err := parseHeaderRow(t.headerRowEntry.Text)
+	if err != nil {
+		t.app.ShowError(err)
+		return
+	}
+
+	tabColor := strings.TrimSpace(t.tabColorEntry.Text)
+	if tabColor != "" {
+		if _, err := excel.NormalizeTabColor(tabColor); err != nil {
+			t.app.ShowError(err)
+			return
+		}
+	}
+
+	columnTypes, err := core.ParseColumnTypes(t.columnTypesEntry.Text)
+	if err != nil {
+		t.app.ShowError(err)
+		return
+	}
+
+	constantColumns, err := core.ParseConstantColumns(t.constantsEntry.Text)
+	if err != nil {
+		t.app.ShowError(err)
+		return
+	}
+
+	coalesce, err := core.ParseCoalesceColumns(t.coalesceEntry.Text)
+	if err != nil {
+		t.app.ShowError(err)
+		return
+	}
+
+	valueMaps, err := core.ParseValueMaps(t.valueMapsEntry.Text)
+	if err != nil {
+		t.app.ShowError(err)
+		return
+	}
+
+	affixRules, err := core.ParseAffixRules(t.affixEntry.Text)
+	if err != nil {
+		t.app.ShowError(err)
+		return
+	}
+
+	typeRow := 0
+	if text := strings.TrimSpace(t.typeRowEntry.Text); text != "" {
+		typeRow, err = strconv.Atoi(text)
+		if err != nil || typeRow < 1 || typeRow > headerRow {
+			t.app.ShowError(apperrors.NewConfigError(
+				fmt.Sprintf("Строка описания типов должна быть в шапке: от 1 до %d", headerRow)))
+			return
+		}
+	}
+
+	dataStart := core.SheetConfig{
+		DataStartColumn: strings.ToUpper(strings.TrimSpace(t.dataStartColumnEntry.Text)),
+		DataStartMarker: strings.TrimSpace(t.dataStartMarkerEntry.Text),
+	}
+	if _, err := dataStart.DataStartColumnIndex(); err != nil {
+		t.app.ShowError(apperrors.NewConfigError(
+			fmt.Sprintf("Столбец поиска начала данных: %v", err)))
+		return
+	}
+
+	separatorMode := separatorOptionMode(t.separatorSelect.Selected)
+	if err := core.ValidateSeparatorMode(separatorMode, strings.TrimSpace(t.dedupKeyEntry.Text)); err != nil {
+		t.app.ShowError(apperrors.NewConfigError(
+			fmt.Sprintf("Разделители между файлами: %v", err)))
 		return
 	}
 
 	sheet := &t.sheets[t.selectedSheet]
 	sheet.HeaderRow = headerRow
+	sheet.TabColor = tabColor
+	sheet.ColumnTypes = columnTypes
+	sheet.TypeDescriptorRow = typeRow
+	sheet.ConstantColumns = constantColumns
+	sheet.OutputSheet = strings.TrimSpace(t.outputSheetEntry.Text)
+	sheet.SourceSheetNames = core.ParseSheetNames(t.sourceNamesEntry.Text)
+	sheet.SourceSheetPattern = strings.TrimSpace(t.sourcePatternEntry.Text)
+	sheet.DataRangeName = strings.TrimSpace(t.dataRangeEntry.Text)
+	sheet.DedupKey = strings.TrimSpace(t.dedupKeyEntry.Text)
+	sheet.Coalesce = coalesce
+	sheet.GroupByColumn = strings.TrimSpace(t.groupByEntry.Text)
+	sheet.DataStartColumn = dataStart.DataStartColumn
+	sheet.DataStartMarker = dataStart.DataStartMarker
+	sheet.RawCellFallback = t.rawCellFallbackChk.Checked
+	sheet.SkipHeaderProtection = t.skipProtectionChk.Checked
+	sheet.RawTextColumns = core.ParseHeaderList(t.rawTextEntry.Text)
+	sheet.ValueMaps = valueMaps
+	sheet.AffixRules = affixRules
+	sheet.SeparatorMode = separatorMode
 	
 	// Автоматически включаем лист после применения настроек
 	if !sheet.Enabled {
@@ -419,6 +1010,184 @@ func (t *BaseFileTab) onApplySheetConfig() {
 	t.app.logger.Info("Sheet config updated", "sheet", sheet.SheetName, "header_row", headerRow, "enabled", sheet.Enabled)
 }
 
+// onCopySheetSettings копирует настройки выбранного листа на другие листы
+// Пользователь выбирает листы и видит, какие их настройки будут перезаписаны.
+// Применяются сохраненные настройки листа: несохраненные правки панели не копируются
+func (t *BaseFileTab) onCopySheetSettings() {
+	if t.selectedSheet < 0 || t.selectedSheet >= len(t.sheets) {
+		return
+	}
+	from := t.selectedSheet
+	source := t.sheets[from]
+
+	var names []string
+	indexByName := make(map[string]int)
+	for i, sheet := range t.sheets {
+		if i == from {
+			continue
+		}
+		names = append(names, sheet.SheetName)
+		indexByName[sheet.SheetName] = i
+	}
+
+	changesLabel := widget.NewLabel("")
+	changesLabel.Wrapping = fyne.TextWrapWord
+	targetsGroup := widget.NewCheckGroup(names, nil)
+
+	selectedTargets := func() []int {
+		targets := make([]int, 0, len(targetsGroup.Selected))
+		for _, name := range targetsGroup.Selected {
+			targets = append(targets, indexByName[name])
+		}
+		return targets
+	}
+	updateChanges := func() {
+		var lines []string
+		for _, i := range selectedTargets() {
+			if changes := core.SheetSettingsChanges(&source, &t.sheets[i]); len(changes) > 0 {
+				lines = append(lines, fmt.Sprintf("%s: %s", t.sheets[i].SheetName, strings.Join(changes, ", ")))
+			}
+		}
+		if len(lines) == 0 {
+			changesLabel.SetText("Настройки выбранных листов уже совпадают.")
+			return
+		}
+		changesLabel.SetText("Будут перезаписаны:\n" + strings.Join(lines, "\n"))
+	}
+	targetsGroup.OnChanged = func([]string) { updateChanges() }
+	targetsGroup.SetSelected(names)
+	updateChanges()
+
+	message := widget.NewLabel(fmt.Sprintf(
+		"Скопировать строку заголовков, фильтр, преобразования столбцов и флаги чтения листа '%s' на выбранные листы?\n"+
+			"Имя, цвет ярлыка, лист результата и фильтрация по артикулам листа \"Шаблон\" не копируются.",
+		source.SheetName))
+	message.Wrapping = fyne.TextWrapWord
+
+	content := container.NewBorder(message, nil, nil, nil,
+		container.NewVScroll(container.NewVBox(targetsGroup, widget.NewSeparator(), changesLabel)))
+
+	confirm := dialog.NewCustomConfirm("Копирование настроек листа", "Скопировать", "Отмена", content,
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			changed := t.copySheetSettings(from, selectedTargets())
+			t.updateProfile()
+
+			t.updatingUI = true
+			t.sheetList.Refresh()
+			t.updatingUI = false
+			t.updateConfigPanel()
+
+			t.app.logger.Info("Sheet settings copied", "from", source.SheetName, "sheets_count", len(changed))
+			t.app.ShowInfo("Копирование настроек листа", fmt.Sprintf("Настройки скопированы на листы: %d", len(changed)))
+		},
+		t.app.window,
+	)
+	confirm.Resize(fyne.NewSize(520, 480))
+	confirm.Show()
+}
+
+// onBulkHeaderRow устанавливает одну строку заголовков на несколько листов
+// По умолчанию отмечены включенные листы. Заголовки изменившихся листов перечитываются
+// из новой строки, чтобы предпросмотр и настройки столбцов соответствовали ей
+func (t *BaseFileTab) onBulkHeaderRow() {
+	if len(t.sheets) == 0 {
+		t.app.ShowInfo("Строка заголовков", "Сначала выберите базовый файл")
+		return
+	}
+
+	var names, enabled []string
+	indexByName := make(map[string]int)
+	for i, sheet := range t.sheets {
+		names = append(names, sheet.SheetName)
+		indexByName[sheet.SheetName] = i
+		if sheet.Enabled {
+			enabled = append(enabled, sheet.SheetName)
+		}
+	}
+
+	rowEntry := widget.NewEntry()
+	rowEntry.SetText(strconv.Itoa(t.app.GetSettings().HeaderRowForNewSheets()))
+	targetsGroup := widget.NewCheckGroup(names, nil)
+	targetsGroup.SetSelected(enabled)
+
+	content := container.NewBorder(
+		container.NewVBox(
+			widget.NewLabel("Номер строки заголовков:"),
+			rowEntry,
+			widget.NewLabel("Листы:"),
+		),
+		nil, nil, nil,
+		container.NewVScroll(targetsGroup),
+	)
+
+	confirm := dialog.NewCustomConfirm("Строка заголовков для нескольких листов", "Применить", "Отмена", content,
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			headerRow, err := parseHeaderRow(rowEntry.Text)
+			if err != nil {
+				t.app.ShowError(err)
+				return
+			}
+
+			targets := make([]int, 0, len(targetsGroup.Selected))
+			for _, name := range targetsGroup.Selected {
+				targets = append(targets, indexByName[name])
+			}
+			changed := core.SetSheetsHeaderRow(t.sheets, targets, headerRow)
+			t.rereadHeaders(changed)
+			t.updateProfile()
+
+			t.updatingUI = true
+			t.sheetList.Refresh()
+			t.updatingUI = false
+			t.updateConfigPanel()
+
+			t.app.logger.Info("Header row set for sheets", "header_row", headerRow, "sheets_count", len(changed))
+			t.app.ShowInfo("Строка заголовков",
+				fmt.Sprintf("Строка заголовков %d установлена для листов: %d", headerRow, len(changed)))
+		},
+		t.app.window,
+	)
+	confirm.Resize(fyne.NewSize(480, 480))
+	confirm.Show()
+}
+
+// copySheetSettings копирует настройки листа from на листы targets и возвращает измененные листы
+// Листы с другой строкой заголовков получают заголовки из новой строки
+func (t *BaseFileTab) copySheetSettings(from int, targets []int) []int {
+	var headerChanged []int
+	for _, i := range targets {
+		if t.sheets[i].HeaderRow != t.sheets[from].HeaderRow {
+			headerChanged = append(headerChanged, i)
+		}
+	}
+	changed := core.CopySheetSettings(t.sheets, from, targets)
+	t.rereadHeaders(headerChanged)
+	return changed
+}
+
+// rereadHeaders перечитывает заголовки листов indices из их строки заголовков базового файла
+// При ошибке лист остается без предпросмотра
+func (t *BaseFileTab) rereadHeaders(indices []int) {
+	baseFile := t.app.GetBaseFile()
+	for _, i := range indices {
+		sheet := &t.sheets[i]
+		headers, err := t.app.analyzer.GetHeaders(baseFile, sheet.SheetName, sheet.HeaderRow)
+		if err != nil {
+			t.app.logger.Warn("не удалось прочитать заголовки", "sheet", sheet.SheetName, "header_row", sheet.HeaderRow, "error", err)
+			continue
+		}
+		sheet.Headers = headers
+	}
+}
+
 // showSheetConfig показывает конфигурацию выбранного листа
 // DEPRECATED: заменено на updateConfigPanel
 func (t *BaseFileTab) showSheetConfig(id widget.ListItemID) {
@@ -459,6 +1228,7 @@ func (t *BaseFileTab) updateProfile() {
 		if name := t.profileNameEntry.Text; name != "" {
 			profile.ProfileName = name
 		}
+		t.app.ProfileChanged()
 	}
 }
 
@@ -505,39 +1275,15 @@ func (t *BaseFileTab) onOzonTemplateToggled(checked bool) {
 }
 
 // applyOzonTemplate применяет шаблон Ozon к загруженным листам
+// Листы, не входящие в шаблон, отключаются
 func (t *BaseFileTab) applyOzonTemplate() {
-	template := t.app.configManager.GetOzonTemplate()
-	baseFile := t.app.GetBaseFile()
-	
+	applied := make(map[int]bool)
+	for _, i := range t.applyPresetSheets(t.app.GetBaseFile()) {
+		applied[i] = true
+	}
 	for i := range t.sheets {
-		sheet := &t.sheets[i]
-		if config, exists := template[sheet.SheetName]; exists {
-			sheet.Enabled = config.Enabled
-			sheet.HeaderRow = config.HeaderRow
-			sheet.FilterValues = config.FilterValues
-			sheet.UseTemplateArticles = config.UseTemplateArticles
-			
-			// Для листа "Шаблон" автоматически определяем столбец фильтрации
-			if sheet.SheetName == "Шаблон" && len(config.FilterValues) > 0 {
-				columnIndex, err := t.app.analyzer.FindBrandColumnInFirstRows(baseFile, sheet.SheetName, sheet.HeaderRow)
-				if err != nil {
-					t.app.logger.Warn("не удалось найти столбец бренда для фильтрации", "error", err, "sheet", sheet.SheetName)
-				} else if columnIndex >= 0 {
-					sheet.FilterColumn = columnIndex
-					t.app.logger.Info("автоматически определен столбец фильтрации",
-						"sheet", sheet.SheetName,
-						"column_index", columnIndex,
-						"filter_values", sheet.FilterValues)
-				} else {
-					t.app.logger.Warn("столбец 'Бренд в одежде и обуви*' не найден, фильтрация не будет применена", "sheet", sheet.SheetName)
-					sheet.FilterColumn = -1
-				}
-			}
-			
-			t.app.logger.Debug("applied Ozon template", "sheet", sheet.SheetName, "enabled", sheet.Enabled, "header_row", sheet.HeaderRow, "use_template_articles", sheet.UseTemplateArticles)
-		} else {
-			// Листы, не входящие в шаблон, отключаем
-			sheet.Enabled = false
+		if !applied[i] {
+			t.sheets[i].Enabled = false
 		}
 	}
 	
@@ -551,12 +1297,123 @@ func (t *BaseFileTab) applyOzonTemplate() {
 	t.app.ShowInfo("Шаблон применен", "Применен шаблон Ozon для листов")
 }
 
+// onResetToPreset обработчик возврата листов шаблона к его настройкам
+// Базовый файл не перечитывается; листы вне шаблона не изменяются
+func (t *BaseFileTab) onResetToPreset() {
+	if len(t.sheets) == 0 {
+		t.app.ShowInfo("Сброс к шаблону", "Сначала выберите базовый файл")
+		return
+	}
+	if !t.useOzonTemplateChk.Checked {
+		t.app.ShowInfo("Сброс к шаблону", "Шаблон Ozon не включен")
+		return
+	}
+
+	t.app.ShowConfirm("Сброс к шаблону",
+		"Вернуть строки заголовков и фильтры листов шаблона Ozon к значениям по умолчанию?\n"+
+			"Остальные листы не изменятся.",
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+
+			applied := t.applyPresetSheets(t.app.GetBaseFile())
+
+			t.updatingUI = true
+			t.sheetList.Refresh()
+			t.updatingUI = false
+			t.updateConfigPanel()
+			t.updateProfile()
+
+			t.app.logger.Info("Sheets reset to Ozon template", "sheets_count", len(applied))
+			t.app.ShowInfo("Сброс к шаблону", fmt.Sprintf("Настройки шаблона восстановлены для листов: %d", len(applied)))
+		})
+}
+
+// applyPresetSheets применяет шаблон Ozon к его листам и определяет столбец бренда
+// для фильтрации листа "Шаблон"; возвращает индексы листов шаблона
+func (t *BaseFileTab) applyPresetSheets(filePath string) []int {
+	template := t.app.configManager.GetOzonTemplate(t.app.GetSettings().OzonBrands)
+	applied := core.ApplyPreset(t.sheets, template)
+
+	for _, i := range applied {
+		sheet := &t.sheets[i]
+
+		// Для листа "Шаблон" автоматически определяем столбец фильтрации
+		if core.IsTemplateSheet(sheet.SheetName) && len(sheet.FilterValues) > 0 {
+			t.detectBrandColumn(filePath, sheet)
+		}
+
+		t.app.logger.Debug("applied Ozon template", "sheet", sheet.SheetName, "enabled", sheet.Enabled, "header_row", sheet.HeaderRow, "use_template_articles", sheet.UseTemplateArticles)
+	}
+	return applied
+}
+
+// detectBrandColumn определяет столбец бренда листа "Шаблон" для фильтрации по брендам
+func (t *BaseFileTab) detectBrandColumn(filePath string, sheet *core.SheetConfig) {
+	columnIndex, err := t.app.analyzer.FindBrandColumnInFirstRows(filePath, sheet.SheetName, sheet.HeaderRow)
+	if err != nil {
+		t.app.logger.Warn("не удалось найти столбец бренда для фильтрации", "error", err, "sheet", sheet.SheetName)
+	} else if columnIndex >= 0 {
+		sheet.FilterColumn = columnIndex
+		t.app.logger.Info("автоматически определен столбец фильтрации",
+			"sheet", sheet.SheetName,
+			"column_index", columnIndex,
+			"filter_values", sheet.FilterValues)
+	} else {
+		t.app.logger.Warn("столбец 'Бренд в одежде и обуви*' не найден, фильтрация не будет применена", "sheet", sheet.SheetName)
+		sheet.FilterColumn = -1
+	}
+}
+
+// hasOzonTemplateSheets проверяет, загружен ли лист "Шаблон" при включенном шаблоне Ozon
+func (t *BaseFileTab) hasOzonTemplateSheets() bool {
+	if t.useOzonTemplateChk == nil || !t.useOzonTemplateChk.Checked {
+		return false
+	}
+	for _, sheet := range t.sheets {
+		if core.IsTemplateSheet(sheet.SheetName) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyOzonBrands применяет список брендов из настроек к фильтру загруженных листов "Шаблон"
+// Остальные настройки листов сохраняются; возвращает количество измененных листов
+func (t *BaseFileTab) applyOzonBrands() int {
+	brands := config.NormalizeBrands(t.app.GetSettings().OzonBrands)
+	changed := core.SetTemplateFilterValues(t.sheets, brands)
+	for _, i := range changed {
+		sheet := &t.sheets[i]
+		if len(sheet.FilterValues) > 0 && sheet.FilterColumn < 0 {
+			t.detectBrandColumn(t.app.GetBaseFile(), sheet)
+		}
+	}
+	if len(changed) == 0 {
+		return 0
+	}
+
+	t.updatingUI = true
+	t.sheetList.Refresh()
+	t.updatingUI = false
+	t.updateConfigPanel()
+	t.updateProfile()
+
+	t.app.logger.Info("Ozon brand list applied to loaded sheets", "sheets_count", len(changed), "brands", brands)
+	return len(changed)
+}
+
 // clearOzonTemplate снимает настройки шаблона Ozon
 func (t *BaseFileTab) clearOzonTemplate() {
 	// Сбрасываем все листы в состояние по умолчанию
+	headerRow := t.app.GetSettings().HeaderRowForNewSheets()
 	for i := range t.sheets {
 		t.sheets[i].Enabled = false
-		t.sheets[i].HeaderRow = 1
+		if t.sheets[i].HeaderRow != headerRow {
+			t.sheets[i].Headers = []string{}
+		}
+		t.sheets[i].HeaderRow = headerRow
 	}
 	
 	// Обновляем UI
@@ -0,0 +1,92 @@
+package gui
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DatKorso/Merge-excel/internal/config"
+	"github.com/DatKorso/Merge-excel/internal/core"
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/excel"
+)
+
+// TestBaseFileTabCopySheetSettingsRereadsHeaders тестирует, что листы, получившие другую
+// строку заголовков, получают заголовки из новой строки базового файла
+func TestBaseFileTabCopySheetSettingsRereadsHeaders(t *testing.T) {
+	basePath := filepath.Join(t.TempDir(), "base.xlsx")
+	writer := excel.NewWriter()
+	defer writer.Close()
+	for _, sheet := range []string{"Товары", "Цены", "Остатки"} {
+		if err := writer.CreateSheet(sheet); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.WriteRows(sheet, 1, [][]string{{"Выгрузка"}, {"Артикул", sheet}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Save(basePath); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	application := &App{
+		logger:       logger,
+		analyzer:     core.NewBaseAnalyzer(nil, logger),
+		appSettings:  config.NewAppSettings(),
+		baseFilePath: basePath,
+	}
+	tab := NewBaseFileTab(application)
+	tab.sheets = []core.SheetConfig{
+		{SheetName: "Товары", HeaderRow: 2, FilterColumn: -1, Headers: []string{"Артикул", "Товары"}},
+		{SheetName: "Цены", HeaderRow: 1, FilterColumn: -1, Headers: []string{"Выгрузка"}},
+		{SheetName: "Остатки", HeaderRow: 2, FilterColumn: -1, Headers: []string{"прежние"}},
+	}
+
+	tab.copySheetSettings(0, []int{1, 2})
+
+	if got := strings.Join(tab.sheets[1].Headers, "|"); got != "Артикул|Цены" {
+		t.Errorf("заголовки листа с новой строкой заголовков = %q", got)
+	}
+	// Строка заголовков не изменилась: заголовки не перечитываются
+	if got := strings.Join(tab.sheets[2].Headers, "|"); got != "прежние" {
+		t.Errorf("заголовки листа с той же строкой заголовков = %q", got)
+	}
+}
+
+// TestParseHeaderRow тестирует разбор введенного номера строки заголовков
+func TestParseHeaderRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    int
+		wantErr string
+	}{
+		{name: "число", text: "4", want: 4},
+		{name: "пробелы по краям", text: " 2 ", want: 2},
+		{name: "не число", text: "пятая", wantErr: "[E004] Неверный номер строки заголовков: 'пятая'"},
+		{name: "пустой ввод", text: "", wantErr: "[E004] Неверный номер строки заголовков: ''"},
+		{name: "ноль", text: "0", wantErr: "[E004] Неверный номер строки заголовков: 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHeaderRow(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseHeaderRow(%q) error = %v, ожидалось %q", tt.text, err, tt.wantErr)
+				}
+				if !errors.Is(err, apperrors.ErrInvalidHeaderRow) {
+					t.Errorf("код ошибки = %v, ожидался ErrCodeInvalidHeaderRow", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseHeaderRow(%q) = %d, %v, ожидалось %d", tt.text, got, err, tt.want)
+			}
+		})
+	}
+}
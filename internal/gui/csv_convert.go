@@ -0,0 +1,140 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/DatKorso/Merge-excel/internal/core"
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/native"
+)
+
+// csvFileFilter фильтр диалога выбора CSV-файла
+var csvFileFilter = native.FileFilter{Description: "CSV файлы", Extension: "csv"}
+
+// csvDelimiterOptions варианты разделителя полей CSV по подписи в интерфейсе
+var csvDelimiterOptions = []struct {
+	label     string
+	delimiter rune
+}{
+	{"Определить автоматически", 0},
+	{"Точка с запятой (;)", ';'},
+	{"Запятая (,)", ','},
+	{"Табуляция", '\t'},
+}
+
+// csvEncodingOptions варианты кодировки CSV по подписи в интерфейсе
+var csvEncodingOptions = []struct {
+	label    string
+	encoding string
+}{
+	{"UTF-8", core.CSVEncodingUTF8},
+	{"Windows-1251", core.CSVEncodingWindows1251},
+}
+
+// onConvertCSV обработчик преобразования CSV-файла в xlsx
+func (a *App) onConvertCSV() {
+	a.dialogs.OpenFile("Выберите CSV-файл", csvFileFilter, func(csvPath string, err error) {
+		if native.IsCancelled(err) {
+			return
+		}
+		if err != nil {
+			a.ShowError(err)
+			return
+		}
+		a.showCSVOptions(csvPath)
+	})
+}
+
+// showCSVOptions запрашивает разделитель и кодировку, затем путь сохранения
+func (a *App) showCSVOptions(csvPath string) {
+	delimiterLabels := make([]string, len(csvDelimiterOptions))
+	for i, option := range csvDelimiterOptions {
+		delimiterLabels[i] = option.label
+	}
+	delimiterSelect := widget.NewSelect(delimiterLabels, nil)
+	delimiterSelect.SetSelectedIndex(0)
+
+	encodingLabels := make([]string, len(csvEncodingOptions))
+	for i, option := range csvEncodingOptions {
+		encodingLabels[i] = option.label
+	}
+	encodingSelect := widget.NewSelect(encodingLabels, nil)
+	encodingSelect.SetSelectedIndex(0)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Разделитель", delimiterSelect),
+		widget.NewFormItem("Кодировка", encodingSelect),
+	}
+
+	dialog.ShowForm("Конвертировать CSV: "+filepath.Base(csvPath), "Сохранить как...", "Отмена", items,
+		func(confirm bool) {
+			if !confirm {
+				return
+			}
+			opts := core.CSVOptions{
+				Delimiter: csvDelimiterOptions[delimiterSelect.SelectedIndex()].delimiter,
+				Encoding:  csvEncodingOptions[encodingSelect.SelectedIndex()].encoding,
+			}
+			a.saveConvertedCSV(csvPath, opts)
+		},
+		a.window,
+	)
+}
+
+// saveConvertedCSV запрашивает путь результата рядом с CSV-файлом и запускает преобразование
+func (a *App) saveConvertedCSV(csvPath string, opts core.CSVOptions) {
+	defaultName := strings.TrimSuffix(filepath.Base(csvPath), filepath.Ext(csvPath))
+	a.dialogs.SaveFile("Сохранить как Excel", defaultName, filepath.Dir(csvPath), excelFileFilter,
+		func(xlsxPath string, err error) {
+			if native.IsCancelled(err) {
+				return
+			}
+			if err != nil {
+				a.ShowError(err)
+				return
+			}
+			a.convertCSV(csvPath, xlsxPath, opts)
+		})
+}
+
+// convertCSV преобразует CSV в xlsx в фоне и показывает итог
+func (a *App) convertCSV(csvPath, xlsxPath string, opts core.CSVOptions) {
+	progress := dialog.NewCustomWithoutButtons(
+		"Конвертация CSV",
+		container.NewVBox(
+			widget.NewLabel("Преобразование "+filepath.Base(csvPath)+"..."),
+			widget.NewProgressBarInfinite(),
+		),
+		a.window,
+	)
+	progress.Show()
+
+	go func() {
+		defer apperrors.Recover(a.logger, "конвертация CSV", func(err error) {
+			fyne.Do(func() {
+				progress.Hide()
+				a.ShowError(err)
+			})
+		})
+
+		rows, err := core.ConvertCSVToXLSX(csvPath, xlsxPath, opts)
+
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				a.ShowError(err)
+				return
+			}
+
+			a.logger.Info("CSV converted", "csv", csvPath, "xlsx", xlsxPath, "rows", rows)
+			a.ShowInfo("Конвертация CSV", fmt.Sprintf("Записано строк: %d\nФайл сохранен:\n%s", rows, xlsxPath))
+		})
+	}()
+}
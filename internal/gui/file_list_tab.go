@@ -3,14 +3,22 @@ package gui
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 	
+	"github.com/DatKorso/Merge-excel/internal/config"
+	"github.com/DatKorso/Merge-excel/internal/core"
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/excel"
 	"github.com/DatKorso/Merge-excel/internal/native"
 )
 
+// maxSkippedLines наибольшее количество пропущенных путей, перечисляемых в итоге вставки или перетаскивания
+const maxSkippedLines = 10
+
 // FileListTab вкладка со списком файлов для объединения
 type FileListTab struct {
 	app *App
@@ -18,13 +26,19 @@ type FileListTab struct {
 	// UI элементы
 	fileList      *widget.List
 	addBtn        *widget.Button
+	addFolderBtn  *widget.Button
+	pasteBtn      *widget.Button
 	removeBtn     *widget.Button
 	clearBtn      *widget.Button
+	checkHeadersBtn *widget.Button
 	fileCountLabel *widget.Label
 
 	// Данные
 	files       []string
 	selectedIdx int
+
+	// Сводка строк и размера файлов списка; заполняется фоновой оценкой добавленных файлов
+	summary *core.FileListSummary
 }
 
 // NewFileListTab создает новую вкладку списка файлов
@@ -33,6 +47,7 @@ func NewFileListTab(app *App) *FileListTab {
 		app:         app,
 		files:       []string{},
 		selectedIdx: -1,
+		summary:     core.NewFileListSummary(),
 	}
 
 	return tab
@@ -65,6 +80,16 @@ func (t *FileListTab) Build() fyne.CanvasObject {
 		t.onAddFiles()
 	})
 
+	// Кнопка добавления всех файлов из папки
+	t.addFolderBtn = widget.NewButton("Добавить папку...", func() {
+		t.onAddFolder()
+	})
+
+	// Кнопка добавления путей из буфера обмена (то же, что Ctrl+V на вкладке)
+	t.pasteBtn = widget.NewButton("Вставить пути из буфера", func() {
+		t.OnPastePaths(t.app.fyneApp.Clipboard().Content())
+	})
+
 	// Кнопка удаления выбранного файла
 	t.removeBtn = widget.NewButton("Удалить выбранный", func() {
 		t.onRemoveSelected()
@@ -77,6 +102,11 @@ func (t *FileListTab) Build() fyne.CanvasObject {
 	})
 	t.clearBtn.Disable()
 
+	// Кнопка проверки заголовков всех файлов списка
+	t.checkHeadersBtn = widget.NewButton("Проверить заголовки...", func() {
+		t.app.onCheckHeaders()
+	})
+
 	// Обработчик выбора в списке
 	t.fileList.OnSelected = func(id widget.ListItemID) {
 		t.selectedIdx = int(id)
@@ -91,8 +121,11 @@ func (t *FileListTab) Build() fyne.CanvasObject {
 	// Панель с кнопками
 	buttonsBox := container.NewVBox(
 		t.addBtn,
+		t.addFolderBtn,
+		t.pasteBtn,
 		t.removeBtn,
 		t.clearBtn,
+		t.checkHeadersBtn,
 		widget.NewSeparator(),
 		t.fileCountLabel,
 	)
@@ -103,6 +136,8 @@ func (t *FileListTab) Build() fyne.CanvasObject {
 			"Файлы должны иметь ту же структуру, что и базовый файл.\n\n" +
 			"Вы можете:\n" +
 			"• Нажать 'Добавить файлы...'\n" +
+			"• Нажать 'Добавить папку...', чтобы добавить все файлы .xlsx из папки\n" +
+			"• Скопировать пути файлов (по одному на строку) и нажать Ctrl+V\n" +
 			"• Перетащить файлы в это окно (Drag & Drop)",
 	)
 	instructionLabel.Wrapping = fyne.TextWrapWord
@@ -125,63 +160,246 @@ func (t *FileListTab) Build() fyne.CanvasObject {
 
 // onAddFiles обработчик добавления файлов через диалог
 func (t *FileListTab) onAddFiles() {
-	// Открываем нативный диалог выбора файла
-	filename, err := native.FileOpenDialog(
-		"Добавить Excel файл",
-		"Excel файлы",
-		"xlsx",
-	)
-	
-	// Проверяем отмену пользователем
-	if native.IsCancelled(err) {
-		return
-	}
-	
+	// Открываем диалог выбора файлов (можно выбрать несколько)
+	t.app.dialogs.OpenFiles("Добавить Excel файлы", excelFileFilter, func(filenames []string, err error) {
+		// Проверяем отмену пользователем
+		if native.IsCancelled(err) {
+			return
+		}
+
+		// Файлы, выбранные до ошибки, все равно добавляются
+		var added []string
+		for _, filename := range filenames {
+			if t.addFile(filename) {
+				added = append(added, filename)
+			} else if t.atFileLimit() {
+				break
+			}
+		}
+		t.checkSheets(added)
+		t.checkDuplicates(added)
+
+		if err != nil {
+			t.app.ShowError(err)
+		}
+	})
+}
+
+// onAddFolder обработчик добавления всех файлов .xlsx из выбранной папки
+func (t *FileListTab) onAddFolder() {
+	t.app.dialogs.PickDirectory("Добавить папку с Excel файлами", t.folderStartDir(), func(dir string, err error) {
+		if native.IsCancelled(err) {
+			return
+		}
+		if err != nil {
+			t.app.ShowError(err)
+			return
+		}
+		t.addFolder(dir)
+	})
+}
+
+// addFolder добавляет в список файлы .xlsx из директории dir, кроме базового и уже добавленных
+func (t *FileListTab) addFolder(dir string) {
+	files, err := core.ListExcelFiles(dir)
 	if err != nil {
 		t.app.ShowError(err)
 		return
 	}
 
-	t.addFile(filename)
+	var candidates []string
+	for _, path := range files {
+		if path != t.app.GetBaseFile() && !t.hasFile(path) {
+			candidates = append(candidates, path)
+		}
+	}
+	if len(candidates) == 0 {
+		t.app.logger.Info("Folder added to merge list", "dir", dir, "found", len(files), "added", 0)
+		t.app.ShowInfo("Добавить папку", "В папке нет новых файлов .xlsx:\n"+dir)
+		return
+	}
+
+	// Файлы сверх ограничения не добавляются; сообщение показывается один раз для всей папки
+	var added []string
+	for _, path := range candidates {
+		if t.atFileLimit() {
+			break
+		}
+		if t.addFile(path) {
+			added = append(added, path)
+		}
+	}
+
+	t.app.logger.Info("Folder added to merge list", "dir", dir, "found", len(files), "added", len(added))
+
+	if skipped := len(candidates) - len(added); skipped > 0 && t.atFileLimit() {
+		t.app.ShowInfo("Добавить папку", fmt.Sprintf(
+			"Добавлено файлов: %d из %d. В списке для объединения может быть не больше %d файлов, "+
+				"остальные %d файлов папки не добавлены.\n\nОграничение можно изменить на вкладке настроек.",
+			len(added), len(candidates), t.app.GetSettings().MaxMergeFiles, skipped))
+	}
+	t.checkSheets(added)
+	t.checkDuplicates(added)
+}
+
+// folderStartDir возвращает начальную директорию выбора папки: папку последнего добавленного
+// или базового файла
+func (t *FileListTab) folderStartDir() string {
+	if len(t.files) > 0 {
+		return filepath.Dir(t.files[len(t.files)-1])
+	}
+	if baseFile := t.app.GetBaseFile(); baseFile != "" {
+		return filepath.Dir(baseFile)
+	}
+	return ""
 }
 
-// OnFilesDropped обработчик Drag & Drop (публичный метод для вызова из App)
+// atFileLimit сообщает, достигнуто ли ограничение количества файлов из настроек
+func (t *FileListTab) atFileLimit() bool {
+	return !t.app.GetSettings().CanAddMergeFile(len(t.files))
+}
+
+// hasFile проверяет, есть ли файл в списке
+func (t *FileListTab) hasFile(path string) bool {
+	for _, f := range t.files {
+		if f == path {
+			return true
+		}
+	}
+	return false
+}
+
+// OnFilesDropped обрабатывает файлы, перетащенные в окно (публичный метод для вызова из App)
+// Книги .xlsx добавляются в список, CSV-файлы по настройке открываются в окне преобразования в xlsx,
+// остальные форматы, базовый файл и уже добавленные файлы пропускаются; итог показывается одним сообщением
 func (t *FileListTab) OnFilesDropped(uris []fyne.URI) {
-	fmt.Printf("onFilesDropped called with %d URIs\n", len(uris))
-	
+	paths := make([]string, 0, len(uris))
 	for _, uri := range uris {
-		path := uri.Path()
-		fmt.Printf("Processing URI: %s (ext: %s)\n", path, filepath.Ext(path))
-		
-		if filepath.Ext(path) == ".xlsx" {
-			t.addFile(path)
-		} else {
-			fmt.Printf("Skipping non-xlsx file: %s\n", path)
+		paths = append(paths, uri.Path())
+	}
+
+	convertCSV := t.app.GetSettings().DroppedCSV != config.DroppedCSVSkip
+	accepted, skipped := core.ClassifyDroppedPaths(paths, convertCSV)
+	var added, csvFiles []string
+	for _, dropped := range accepted {
+		if dropped.Action == core.DropConvertCSV {
+			csvFiles = append(csvFiles, dropped.Path)
+			continue
+		}
+		if reason := t.skipReason(dropped.Path); reason != "" {
+			skipped = append(skipped, core.SkippedPath{Path: dropped.Path, Reason: reason})
+		} else if t.addFile(dropped.Path) {
+			added = append(added, dropped.Path)
+		}
+	}
+
+	t.app.logger.Info("Dropped files processed", "dropped", len(paths), "added", len(added),
+		"csv", len(csvFiles), "skipped", len(skipped))
+
+	message := fmt.Sprintf("Добавлено файлов: %d из %d", len(added), len(paths))
+	if len(csvFiles) > 0 {
+		var lines []string
+		for _, path := range csvFiles {
+			lines = append(lines, "• "+path)
+		}
+		message += "\n\nПредложено преобразовать в xlsx:\n" + strings.Join(lines, "\n")
+	}
+	message += skippedSummary(skipped)
+	t.app.ShowInfo("Перетаскивание файлов", message)
+
+	t.checkSheets(added)
+	t.checkDuplicates(added)
+	for _, path := range csvFiles {
+		t.app.showCSVOptions(path)
+	}
+}
+
+// OnPastePaths добавляет в список файлы, пути которых вставлены из буфера обмена (публичный метод для вызова из App)
+// Пути без расширения .xlsx, несуществующие файлы, базовый файл и уже добавленные файлы пропускаются;
+// итог показывается одним сообщением
+func (t *FileListTab) OnPastePaths(text string) {
+	paths := core.ParsePastedPaths(text)
+	if len(paths) == 0 {
+		t.app.ShowInfo("Вставка путей", "В буфере обмена нет путей к файлам")
+		return
+	}
+
+	valid, skipped := core.ValidatePastedPaths(paths)
+	var added []string
+	for _, path := range valid {
+		if reason := t.skipReason(path); reason != "" {
+			skipped = append(skipped, core.SkippedPath{Path: path, Reason: reason})
+		} else if t.addFile(path) {
+			added = append(added, path)
 		}
 	}
+
+	t.app.logger.Info("Pasted paths added to merge list", "pasted", len(paths), "added", len(added), "skipped", len(skipped))
+
+	message := fmt.Sprintf("Добавлено файлов: %d из %d", len(added), len(paths))
+	t.app.ShowInfo("Вставка путей", message+skippedSummary(skipped))
+
+	t.checkSheets(added)
+	t.checkDuplicates(added)
+}
+
+// skipReason возвращает причину, по которой файл path не добавляется в список, или пустую строку
+// Проверяется до addFile, чтобы при добавлении нескольких файлов не показывать сообщение на каждый
+func (t *FileListTab) skipReason(path string) string {
+	switch {
+	case path == t.app.GetBaseFile():
+		return "это базовый файл"
+	case t.hasFile(path):
+		return "уже в списке"
+	case t.atFileLimit():
+		return "достигнуто ограничение количества файлов"
+	}
+	return ""
 }
 
+// skippedSummary перечисляет пропущенные пути с причинами для итогового сообщения
+// Без пропущенных путей возвращает пустую строку
+func skippedSummary(skipped []core.SkippedPath) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+	var lines []string
+	for i, path := range skipped {
+		if i == maxSkippedLines {
+			lines = append(lines, fmt.Sprintf("• и еще %d", len(skipped)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("• %s: %s", path.Path, path.Reason))
+	}
+	return "\n\nПропущено:\n" + strings.Join(lines, "\n")
+}
 
-// addFile добавляет файл в список
-func (t *FileListTab) addFile(path string) {
+// addFile добавляет файл в список; возвращает false, если файл не добавлен
+func (t *FileListTab) addFile(path string) bool {
 	// Проверяем расширение
-	if filepath.Ext(path) != ".xlsx" {
-		t.app.ShowError(fmt.Errorf("Неподдерживаемый формат файла. Только .xlsx файлы разрешены"))
-		return
+	if !strings.EqualFold(excel.WorkbookExt(path), ".xlsx") {
+		t.app.ShowError(fmt.Errorf("Неподдерживаемый формат файла. Только .xlsx (и сжатые .xlsx.gz) файлы разрешены"))
+		return false
 	}
 
 	// Проверяем, не является ли это базовым файлом
 	if path == t.app.GetBaseFile() {
 		t.app.ShowError(fmt.Errorf("Нельзя добавить базовый файл в список для объединения"))
-		return
+		return false
 	}
 
 	// Проверяем, что файл еще не добавлен
-	for _, f := range t.files {
-		if f == path {
-			t.app.ShowInfo("Файл уже добавлен", "Файл '"+filepath.Base(path)+"' уже есть в списке")
-			return
-		}
+	if t.hasFile(path) {
+		t.app.ShowInfo("Файл уже добавлен", "Файл '"+filepath.Base(path)+"' уже есть в списке")
+		return false
+	}
+
+	// Проверяем ограничение количества файлов
+	if t.atFileLimit() {
+		t.app.logger.Warn("merge file limit reached", "path", path, "max_files", t.app.GetSettings().MaxMergeFiles)
+		t.app.ShowError(fmt.Errorf("Нельзя добавить файл '%s': в списке уже %d файлов - это наибольшее количество. "+
+			"Уберите лишние файлы или измените ограничение на вкладке настроек", filepath.Base(path), len(t.files)))
+		return false
 	}
 
 	// Добавляем файл
@@ -195,6 +413,147 @@ func (t *FileListTab) addFile(path string) {
 	}
 
 	t.app.logger.Info("File added to merge list", "path", path, "total_files", len(t.files))
+	return true
+}
+
+// sheetConflict файл из списка, в котором нет части включенных листов профиля
+type sheetConflict struct {
+	path    string
+	missing []string
+}
+
+// checkSheets проверяет в фоне, что в добавленных файлах есть включенные листы профиля
+// Читаются только имена листов; файлы без нужных листов остаются в списке,
+// пользователю предлагается их убрать
+func (t *FileListTab) checkSheets(paths []string) {
+	profile := t.app.GetProfile()
+	if len(paths) == 0 || profile == nil {
+		return
+	}
+	sheets := append([]core.SheetConfig(nil), profile.Sheets...)
+
+	go func() {
+		defer apperrors.Recover(t.app.logger, "проверка листов добавленных файлов", nil)
+
+		var conflicts []sheetConflict
+		for _, path := range paths {
+			missing, err := t.app.analyzer.MissingSourceSheets(path, sheets)
+			if err != nil {
+				// Файл, который не удалось открыть, будет пропущен с предупреждением при объединении
+				t.app.logger.Warn("не удалось проверить листы файла", "path", path, "error", err)
+				continue
+			}
+			if len(missing) > 0 {
+				t.app.logger.Warn("в файле нет листов базового файла", "path", path, "missing", missing)
+				conflicts = append(conflicts, sheetConflict{path: path, missing: missing})
+			}
+		}
+
+		if len(conflicts) > 0 {
+			fyne.Do(func() { t.showSheetConflicts(conflicts) })
+		}
+	}()
+}
+
+// checkDuplicates проверяет в фоне, нет ли среди добавленных файлов копий базового файла или
+// файлов списка: одинаковое содержимое под разными именами удвоило бы строки результата
+// По политике профиля копии убираются из списка сразу или пользователю предлагается их убрать
+func (t *FileListTab) checkDuplicates(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	var settings core.ProfileSettings
+	if profile := t.app.GetProfile(); profile != nil {
+		settings = profile.Settings
+	}
+	all := append([]string{t.app.GetBaseFile()}, t.files...)
+	added := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		added[path] = true
+	}
+
+	go func() {
+		defer apperrors.Recover(t.app.logger, "поиск одинаковых файлов", nil)
+
+		var duplicates []core.DuplicateFile
+		for _, duplicate := range core.FindDuplicateFiles(all, settings.HashAlgorithm) {
+			if added[duplicate.Path] {
+				t.app.logger.Warn("файл совпадает по содержимому с другим файлом",
+					"path", duplicate.Path, "original", duplicate.Original)
+				duplicates = append(duplicates, duplicate)
+			}
+		}
+
+		if len(duplicates) > 0 {
+			fyne.Do(func() { t.showDuplicates(duplicates, settings.DuplicateFiles == core.DuplicateFilesSkip) })
+		}
+	}()
+}
+
+// showDuplicates убирает копии из списка (skip) или предлагает их убрать
+func (t *FileListTab) showDuplicates(duplicates []core.DuplicateFile, skip bool) {
+	var message strings.Builder
+	message.WriteString("Содержимое добавленных файлов совпадает с другими файлами:\n")
+	for _, duplicate := range duplicates {
+		fmt.Fprintf(&message, "\n%s = %s", filepath.Base(duplicate.Path), filepath.Base(duplicate.Original))
+	}
+
+	if skip {
+		for _, duplicate := range duplicates {
+			t.removeFile(duplicate.Path)
+		}
+		message.WriteString("\n\nКопии убраны из списка. Политику можно изменить на вкладке объединения.")
+		t.app.ShowInfo("Одинаковые файлы", message.String())
+		return
+	}
+
+	message.WriteString("\n\nСтроки таких файлов повторятся в результате. Убрать копии из списка?")
+	t.app.ShowConfirm("Одинаковые файлы", message.String(), func(remove bool) {
+		if !remove {
+			return
+		}
+		for _, duplicate := range duplicates {
+			t.removeFile(duplicate.Path)
+		}
+	})
+}
+
+// showSheetConflicts предлагает убрать из списка файлы без части листов базового файла
+func (t *FileListTab) showSheetConflicts(conflicts []sheetConflict) {
+	var message strings.Builder
+	message.WriteString("В добавленных файлах нет листов базового файла:\n")
+	for _, conflict := range conflicts {
+		fmt.Fprintf(&message, "\n%s: '%s'", filepath.Base(conflict.path), strings.Join(conflict.missing, "', '"))
+	}
+	message.WriteString("\n\nЭти листы будут пропущены при объединении. Убрать такие файлы из списка?")
+
+	t.app.ShowConfirm("Листы не совпадают", message.String(), func(remove bool) {
+		if !remove {
+			return
+		}
+		for _, conflict := range conflicts {
+			t.removeFile(conflict.path)
+		}
+	})
+}
+
+// removeFile удаляет файл из списка, если он там есть
+func (t *FileListTab) removeFile(path string) {
+	for i, f := range t.files {
+		if f != path {
+			continue
+		}
+		t.files = append(t.files[:i], t.files[i+1:]...)
+		t.selectedIdx = -1
+		t.fileList.UnselectAll()
+		t.fileList.Refresh()
+		t.updateFileCount()
+		if len(t.files) == 0 {
+			t.clearBtn.Disable()
+		}
+		t.app.logger.Info("File removed from merge list", "path", path, "total_files", len(t.files))
+		return
+	}
 }
 
 // onRemoveSelected обработчик удаления выбранного файла
@@ -239,8 +598,38 @@ func (t *FileListTab) onClearList() {
 }
 
 // updateFileCount обновляет счетчик файлов
+// Новые файлы оцениваются в фоне; до получения оценок строки и размер показываются как "≈ ?"
 func (t *FileListTab) updateFileCount() {
-	t.fileCountLabel.SetText(fmt.Sprintf("Файлов: %d", len(t.files)))
+	added := t.summary.SetFiles(t.files)
+	t.fileCountLabel.SetText(t.summary.String())
+	t.estimateFiles(added)
+}
+
+// estimateFiles оценивает строки и размер файлов paths по разметке листов и обновляет сводку
+// по мере получения оценок
+func (t *FileListTab) estimateFiles(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	var sheetConfigs map[string]*core.SheetConfig
+	if profile := t.app.GetProfile(); profile != nil {
+		sheetConfigs = enabledSheetConfigs(profile)
+	}
+
+	go func() {
+		defer apperrors.Recover(t.app.logger, "оценка файлов списка", nil)
+
+		for _, path := range paths {
+			estimate, err := core.EstimateSourceFile(path, sheetConfigs)
+			if err != nil {
+				t.app.logger.Warn("не удалось оценить файл списка", "path", path, "error", err)
+			}
+			fyne.Do(func() {
+				t.summary.SetEstimate(path, estimate, err)
+				t.fileCountLabel.SetText(t.summary.String())
+			})
+		}
+	}()
 }
 
 // GetFiles возвращает список всех файлов
@@ -272,7 +661,6 @@ func (z *DropZone) CreateRenderer() fyne.WidgetRenderer {
 
 // Drop обработчик Drop события
 func (z *DropZone) Drop(items []fyne.URI) {
-	fmt.Printf("DropZone: Drop event! %d items\n", len(items))
 	if z.onDrop != nil {
 		z.onDrop(items)
 	}
@@ -280,12 +668,10 @@ func (z *DropZone) Drop(items []fyne.URI) {
 
 // Dragged обработчик перетаскивания
 func (z *DropZone) Dragged(ev *fyne.DragEvent) {
-	fmt.Println("DropZone: Dragged")
 }
 
 // DragEnd обработчик окончания перетаскивания
 func (z *DropZone) DragEnd() {
-	fmt.Println("DropZone: DragEnd")
 }
 
 
@@ -0,0 +1,108 @@
+package gui
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/DatKorso/Merge-excel/internal/config"
+	"github.com/DatKorso/Merge-excel/internal/core"
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/excel/exceltest"
+	"github.com/DatKorso/Merge-excel/internal/native"
+)
+
+// fakeDialogs заглушка native.Dialogs: сразу возвращает заданный результат выбора
+type fakeDialogs struct {
+	dir      string
+	err      error
+	startDir string
+}
+
+func (f *fakeDialogs) OpenFile(title string, filter native.FileFilter, callback func(path string, err error)) {
+	callback("", apperrors.NewCancelledError())
+}
+
+func (f *fakeDialogs) OpenFiles(title string, filter native.FileFilter, callback func(paths []string, err error)) {
+	callback(nil, apperrors.NewCancelledError())
+}
+
+func (f *fakeDialogs) SaveFile(title, defaultName, startDir string, filter native.FileFilter, callback func(path string, err error)) {
+	callback("", apperrors.NewCancelledError())
+}
+
+func (f *fakeDialogs) PickDirectory(title, startDir string, callback func(dir string, err error)) {
+	f.startDir = startDir
+	callback(f.dir, f.err)
+}
+
+// newTestFileListTab создает вкладку списка файлов в тестовом окне Fyne с диалогами dialogs
+func newTestFileListTab(t *testing.T, dialogs native.Dialogs) *FileListTab {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	application := &App{
+		fyneApp:     test.NewTempApp(t),
+		logger:      logger,
+		analyzer:    core.NewBaseAnalyzer(nil, logger),
+		appSettings: config.NewAppSettings(),
+		dialogs:     dialogs,
+	}
+	application.window = test.NewTempWindow(t, nil)
+
+	tab := NewFileListTab(application)
+	application.window.SetContent(tab.Build())
+	return tab
+}
+
+func TestFileListTabAddFolder(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.xlsx")
+	for _, name := range []string{"base.xlsx", "a.xlsx", "b.xlsx"} {
+		exceltest.WriteWorkbook(t, filepath.Join(dir, name), "Data", [][]string{{"Артикул"}, {"A1"}})
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("заметки"), 0o644); err != nil {
+		t.Fatalf("не удалось создать файл: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		dialogs *fakeDialogs
+		want    []string
+	}{
+		{
+			name:    "файлы папки кроме базового",
+			dialogs: &fakeDialogs{dir: dir},
+			want:    []string{filepath.Join(dir, "a.xlsx"), filepath.Join(dir, "b.xlsx")},
+		},
+		{
+			name:    "отмена выбора",
+			dialogs: &fakeDialogs{err: apperrors.NewCancelledError()},
+		},
+		{
+			name:    "ошибка диалога",
+			dialogs: &fakeDialogs{err: errors.New("диалог недоступен")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tab := newTestFileListTab(t, tt.dialogs)
+			tab.app.SetBaseFile(basePath)
+
+			tab.onAddFolder()
+
+			if strings.Join(tab.files, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("файлы списка = %v, ожидалось %v", tab.files, tt.want)
+			}
+			// Папка выбирается начиная с папки базового файла
+			if tt.dialogs.startDir != dir {
+				t.Errorf("начальная папка диалога = %q, ожидалось %q", tt.dialogs.startDir, dir)
+			}
+		})
+	}
+}
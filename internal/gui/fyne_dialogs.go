@@ -0,0 +1,126 @@
+package gui
+
+import (
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/native"
+)
+
+// fyneDialogs реализация native.Dialogs диалогами Fyne
+// Используется, когда системные диалоги недоступны (например, в Linux без GTK)
+type fyneDialogs struct {
+	window fyne.Window
+}
+
+// newFyneDialogs создает диалоги Fyne для окна window
+func newFyneDialogs(window fyne.Window) *fyneDialogs {
+	return &fyneDialogs{window: window}
+}
+
+// OpenFile выбирает один файл
+func (d *fyneDialogs) OpenFile(title string, filter native.FileFilter, callback func(path string, err error)) {
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			callback("", err)
+			return
+		}
+		// Диалог закрыт без выбора
+		if reader == nil {
+			callback("", apperrors.NewCancelledError())
+			return
+		}
+		reader.Close()
+		callback(reader.URI().Path(), nil)
+	}, d.window)
+
+	openDialog.SetTitleText(title)
+	setExtensionFilter(openDialog, filter)
+	openDialog.Show()
+}
+
+// OpenFiles выбирает файл; диалог Fyne не поддерживает выбор нескольких файлов
+func (d *fyneDialogs) OpenFiles(title string, filter native.FileFilter, callback func(paths []string, err error)) {
+	d.OpenFile(title, filter, func(path string, err error) {
+		if err != nil {
+			callback(nil, err)
+			return
+		}
+		callback([]string{path}, nil)
+	})
+}
+
+// SaveFile выбирает путь сохранения с предложенным именем в startDir
+// К выбранному пути добавляется расширение фильтра, если его нет
+func (d *fyneDialogs) SaveFile(title, defaultName, startDir string, filter native.FileFilter, callback func(path string, err error)) {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			callback("", err)
+			return
+		}
+		if writer == nil {
+			callback("", apperrors.NewCancelledError())
+			return
+		}
+
+		// Диалог Fyne сам создает выбранный файл; пустой файл без расширения не оставляем
+		path := writer.URI().Path()
+		writer.Close()
+		if withExt := native.WithExtension(path, filter.Extension); withExt != path {
+			os.Remove(path)
+			path = withExt
+		}
+		callback(path, nil)
+	}, d.window)
+
+	saveDialog.SetTitleText(title)
+	setExtensionFilter(saveDialog, filter)
+	if defaultName != "" {
+		saveDialog.SetFileName(native.WithExtension(defaultName, filter.Extension))
+	}
+	setStartLocation(saveDialog, startDir)
+	saveDialog.Show()
+}
+
+// PickDirectory выбирает директорию, начиная со startDir
+func (d *fyneDialogs) PickDirectory(title, startDir string, callback func(dir string, err error)) {
+	folderDialog := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+		if err != nil {
+			callback("", err)
+			return
+		}
+		if uri == nil {
+			callback("", apperrors.NewCancelledError())
+			return
+		}
+		callback(uri.Path(), nil)
+	}, d.window)
+
+	folderDialog.SetTitleText(title)
+	setStartLocation(folderDialog, startDir)
+	folderDialog.Show()
+}
+
+// setExtensionFilter ограничивает диалог файлами с расширением фильтра
+func setExtensionFilter(fileDialog *dialog.FileDialog, filter native.FileFilter) {
+	ext := strings.TrimPrefix(filter.Extension, ".")
+	if ext == "" || ext == "*" {
+		return
+	}
+	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{"." + ext}))
+}
+
+// setStartLocation открывает диалог в директории dir, если она существует
+func setStartLocation(fileDialog *dialog.FileDialog, dir string) {
+	if dir == "" {
+		return
+	}
+	if location, err := storage.ListerForURI(storage.NewFileURI(dir)); err == nil {
+		fileDialog.SetLocation(location)
+	}
+}
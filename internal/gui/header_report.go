@@ -0,0 +1,125 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/DatKorso/Merge-excel/internal/core"
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/native"
+)
+
+// headerReportColumnWidth ширина столбца таблицы отчета о заголовках
+const headerReportColumnWidth = 260
+
+// onCheckHeaders проверяет заголовки включенных листов во всех файлах списка
+// и показывает матрицу файлы × листы
+func (a *App) onCheckHeaders() {
+	profile := a.GetProfile()
+	baseFile := a.GetBaseFile()
+	files := append([]string(nil), a.fileListTab.GetFiles()...)
+	switch {
+	case baseFile == "" || profile == nil:
+		a.ShowError(apperrors.NewConfigError("Сначала выберите базовый файл и настройте листы"))
+		return
+	case len(files) == 0:
+		a.ShowError(apperrors.NewConfigError("Добавьте файлы для объединения"))
+		return
+	}
+	sheets := append([]core.SheetConfig(nil), profile.Sheets...)
+
+	progress := dialog.NewCustomWithoutButtons(
+		"Проверка заголовков",
+		container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("Проверка заголовков в файлах (%d)...", len(files))),
+			widget.NewProgressBarInfinite(),
+		),
+		a.window,
+	)
+	progress.Show()
+
+	go func() {
+		defer apperrors.Recover(a.logger, "проверка заголовков файлов", func(err error) {
+			fyne.Do(func() {
+				progress.Hide()
+				a.ShowError(err)
+			})
+		})
+
+		report, err := a.analyzer.CheckHeaders(baseFile, files, sheets)
+
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				a.ShowError(err)
+				return
+			}
+			a.showHeaderReport(report)
+		})
+	}()
+}
+
+// showHeaderReport показывает отчет о заголовках с кнопками экспорта
+func (a *App) showHeaderReport(report *core.HeaderReport) {
+	rows := report.Rows()
+
+	table := widget.NewTable(
+		func() (int, int) { return len(rows), len(rows[0]) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			label.SetText(rows[id.Row][id.Col])
+			label.TextStyle = fyne.TextStyle{Bold: id.Row == 0 || id.Col == 0}
+		},
+	)
+	for col := range rows[0] {
+		table.SetColumnWidth(col, headerReportColumnWidth)
+	}
+
+	summary := "Заголовки всех листов совпадают с базовым файлом"
+	if problems := report.ProblemCount(); problems > 0 {
+		summary = fmt.Sprintf("Листов с расхождениями: %d. Столбцы сравниваются по имени без учета регистра и порядка", problems)
+	}
+	summaryLabel := widget.NewLabel(summary)
+	summaryLabel.Wrapping = fyne.TextWrapWord
+
+	var reportDialog dialog.Dialog
+	buttons := container.NewHBox(
+		widget.NewButton("Сохранить в Excel...", func() { a.exportHeaderReport(report, excelFileFilter) }),
+		widget.NewButton("Сохранить в CSV...", func() { a.exportHeaderReport(report, csvFileFilter) }),
+		widget.NewButton("Закрыть", func() { reportDialog.Hide() }),
+	)
+
+	content := container.NewBorder(summaryLabel, buttons, nil, nil, table)
+	reportDialog = dialog.NewCustomWithoutButtons("Проверка заголовков", content, a.window)
+	reportDialog.Resize(fyne.NewSize(900, 500))
+	reportDialog.Show()
+}
+
+// exportHeaderReport сохраняет отчет о заголовках в файл формата filter рядом с базовым файлом
+func (a *App) exportHeaderReport(report *core.HeaderReport, filter native.FileFilter) {
+	a.dialogs.SaveFile("Сохранить отчет о заголовках", "Проверка заголовков", filepath.Dir(a.GetBaseFile()), filter,
+		func(path string, err error) {
+			if native.IsCancelled(err) {
+				return
+			}
+			if err == nil {
+				err = report.Export(path)
+			}
+			if err != nil {
+				a.ShowError(err)
+				return
+			}
+			a.logger.Info("Header report exported", "path", path)
+			a.ShowInfo("Проверка заголовков", "Отчет сохранен:\n"+path)
+		})
+}
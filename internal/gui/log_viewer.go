@@ -0,0 +1,69 @@
+package gui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/DatKorso/Merge-excel/internal/logger"
+)
+
+// LogViewer окно просмотра журнала приложения с обновлением в реальном времени
+// Записи берутся из памяти логгера, файл журнала не читается
+type LogViewer struct {
+	app         *App
+	window      fyne.Window
+	list        *widget.List
+	lines       []string
+	unsubscribe func()
+}
+
+// NewLogViewer создает окно просмотра журнала
+func NewLogViewer(app *App) *LogViewer {
+	return &LogViewer{app: app}
+}
+
+// Show открывает окно и подписывается на новые записи журнала
+func (v *LogViewer) Show() {
+	// Подписка до снимка: запись между ними может показаться дважды, но не потеряется
+	records, unsubscribe := logger.Subscribe(256)
+	v.unsubscribe = unsubscribe
+
+	for _, record := range logger.Snapshot() {
+		v.lines = append(v.lines, record.String())
+	}
+
+	v.list = widget.NewList(
+		func() int { return len(v.lines) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(v.lines[id])
+		},
+	)
+
+	v.window = v.app.fyneApp.NewWindow("Журнал приложения")
+	v.window.SetContent(container.NewBorder(nil, nil, nil, nil, v.list))
+	v.window.Resize(fyne.NewSize(900, 500))
+	v.window.SetOnClosed(v.unsubscribe)
+	v.window.Show()
+	v.list.ScrollToBottom()
+
+	go func() {
+		for record := range records {
+			line := record.String()
+			fyne.Do(func() {
+				v.append(line)
+			})
+		}
+	}()
+}
+
+// append добавляет строку, удаляя самые старые сверх DefaultMemorySize
+func (v *LogViewer) append(line string) {
+	v.lines = append(v.lines, line)
+	if extra := len(v.lines) - logger.DefaultMemorySize; extra > 0 {
+		v.lines = append(v.lines[:0], v.lines[extra:]...)
+	}
+	v.list.Refresh()
+	v.list.ScrollToBottom()
+}
@@ -1,17 +1,26 @@
 package gui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/DatKorso/Merge-excel/internal/core"
 	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/excel"
 	"github.com/DatKorso/Merge-excel/internal/native"
+	"github.com/DatKorso/Merge-excel/internal/profiling"
 )
 
 // MergeTab вкладка объединения файлов
@@ -21,14 +30,39 @@ type MergeTab struct {
 	// UI элементы
 	startBtn      *widget.Button
 	saveBtn       *widget.Button
+	copyLogBtn    *widget.Button
 	progressBar   *widget.ProgressBar
 	statusLabel   *widget.Label
 	detailsLabel  *widget.Label
 	resultPreview *widget.Label
+	warningsText  *widget.RichText
+
+	// Шаблон оформления заголовков
+	styleTemplateLabel *widget.Label
+	styleClearBtn      *widget.Button
+
+	// Политика обработки ошибок листа
+	continueOnErrorChk *widget.Check
+
+	// Дописывание данных в копию базового файла
+	mergeIntoBaseChk *widget.Check
+
+	// Перенос условного форматирования базового файла
+	conditionalFormatsChk *widget.Check
+
+	// Пропуск файлов с одинаковым содержимым
+	skipDuplicatesChk *widget.Check
+
+	// Защита строк шапки листов результата
+	protectHeadersChk       *widget.Check
+	protectionPasswordEntry *widget.Entry
 
 	// Состояние
 	mergeResult   *core.MergeResult
 	mergeInProgress bool
+
+	profileStats *profiling.Stats    // Сведения профилирования последнего объединения
+	estimate     *core.MergeEstimate // Прогноз объема, показанный перед объединением
 }
 
 // NewMergeTab создает новую вкладку объединения
@@ -55,6 +89,12 @@ func (t *MergeTab) Build() fyne.CanvasObject {
 	})
 	t.saveBtn.Disable()
 
+	// Кнопка копирования журнала объединения (для обращения в поддержку)
+	t.copyLogBtn = widget.NewButton("Скопировать журнал", func() {
+		t.onCopyMergeLog()
+	})
+	t.copyLogBtn.Disable()
+
 	// Прогресс бар
 	t.progressBar = widget.NewProgressBar()
 	t.progressBar.Min = 0
@@ -72,6 +112,10 @@ func (t *MergeTab) Build() fyne.CanvasObject {
 	t.resultPreview = widget.NewLabel("")
 	t.resultPreview.Wrapping = fyne.TextWrapWord
 
+	// Предупреждения объединения, окрашенные по уровню важности
+	t.warningsText = widget.NewRichText()
+	t.warningsText.Wrapping = fyne.TextWrapWord
+
 	// Инструкция
 	instructionLabel := widget.NewLabel(
 		"Объединение файлов:\n\n" +
@@ -87,8 +131,59 @@ func (t *MergeTab) Build() fyne.CanvasObject {
 	buttonsBox := container.NewHBox(
 		t.startBtn,
 		t.saveBtn,
+		t.copyLogBtn,
 	)
 
+	// Шаблон оформления заголовков
+	t.styleTemplateLabel = widget.NewLabel("")
+	t.styleClearBtn = widget.NewButton("Сбросить", func() {
+		t.setStyleTemplate("")
+	})
+	styleBox := container.NewHBox(
+		widget.NewLabel("Оформление заголовков:"),
+		t.styleTemplateLabel,
+		widget.NewButton("Выбрать шаблон...", func() {
+			t.onSelectStyleTemplate()
+		}),
+		t.styleClearBtn,
+	)
+	t.refreshStyleTemplate()
+
+	// Политика обработки ошибок листа
+	t.continueOnErrorChk = widget.NewCheck("Пропускать листы с ошибками и продолжать объединение", func(checked bool) {
+		t.setOnErrorPolicy(checked)
+	})
+	t.refreshOnErrorPolicy()
+
+	// Дописывание данных в копию базового файла с сохранением его оформления
+	t.mergeIntoBaseChk = widget.NewCheck("Дописывать данные в копию базового файла (сохранить его оформление и листы)", func(checked bool) {
+		t.setMergeIntoBase(checked)
+	})
+	t.refreshMergeIntoBase()
+
+	// Условное форматирование базового файла продлевается на строки результата
+	t.conditionalFormatsChk = widget.NewCheck("Переносить условное форматирование листов базового файла", func(checked bool) {
+		t.setPreserveConditionalFormats(checked)
+	})
+	t.refreshPreserveConditionalFormats()
+
+	// Политика для файлов с одинаковым содержимым: по умолчанию копии объединяются с предупреждением
+	t.skipDuplicatesChk = widget.NewCheck("Пропускать файлы с тем же содержимым, что и у другого файла списка", func(checked bool) {
+		t.setSkipDuplicates(checked)
+	})
+	t.refreshSkipDuplicates()
+
+	// Защита шапки от случайного изменения; пароль необязателен
+	t.protectionPasswordEntry = widget.NewPasswordEntry()
+	t.protectionPasswordEntry.SetPlaceHolder("Пароль (необязательно)")
+	t.protectionPasswordEntry.OnChanged = func(password string) {
+		t.setProtectionPassword(password)
+	}
+	t.protectHeadersChk = widget.NewCheck("Защитить строки заголовков от изменения", func(checked bool) {
+		t.setProtectHeaders(checked)
+	})
+	t.refreshProtectHeaders()
+
 	// Панель прогресса
 	progressBox := container.NewVBox(
 		widget.NewLabel("Прогресс:"),
@@ -105,6 +200,12 @@ func (t *MergeTab) Build() fyne.CanvasObject {
 			instructionLabel,
 			widget.NewSeparator(),
 			buttonsBox,
+			styleBox,
+			t.continueOnErrorChk,
+			t.mergeIntoBaseChk,
+			t.conditionalFormatsChk,
+			t.skipDuplicatesChk,
+			container.NewBorder(nil, nil, t.protectHeadersChk, nil, t.protectionPasswordEntry),
 			widget.NewSeparator(),
 			progressBox,
 			widget.NewSeparator(),
@@ -114,12 +215,228 @@ func (t *MergeTab) Build() fyne.CanvasObject {
 		nil, // Left
 		nil, // Right
 		// Center - растягивается на всё доступное пространство
-		container.NewScroll(t.resultPreview),
+		container.NewScroll(container.NewVBox(t.resultPreview, t.warningsText)),
 	)
 
 	return mainContainer
 }
 
+// onSelectStyleTemplate обработчик выбора файла-шаблона оформления заголовков
+func (t *MergeTab) onSelectStyleTemplate() {
+	if t.app.GetProfile() == nil {
+		t.app.ShowError(apperrors.NewConfigError("Сначала выберите и проанализируйте базовый файл"))
+		return
+	}
+
+	t.app.dialogs.OpenFile("Выбрать шаблон оформления", excelFileFilter, func(filename string, err error) {
+		if native.IsCancelled(err) {
+			return
+		}
+		if err != nil {
+			t.app.ShowError(err)
+			return
+		}
+		t.selectStyleTemplate(filename)
+	})
+}
+
+// selectStyleTemplate проверяет выбранный файл-шаблон и сохраняет его в профиле
+func (t *MergeTab) selectStyleTemplate(filename string) {
+	// Проверяем шаблон сразу, чтобы не узнать об ошибке после объединения
+	if _, err := excel.LoadHeaderStyles(filename); err != nil {
+		t.app.ShowError(err)
+		return
+	}
+
+	t.setStyleTemplate(filename)
+}
+
+// setStyleTemplate сохраняет путь к шаблону оформления в текущем профиле
+func (t *MergeTab) setStyleTemplate(path string) {
+	if profile := t.app.GetProfile(); profile != nil {
+		profile.Settings.StyleTemplatePath = path
+		t.app.ProfileChanged()
+	}
+	t.refreshStyleTemplate()
+}
+
+// refreshStyleTemplate обновляет отображение шаблона оформления текущего профиля
+func (t *MergeTab) refreshStyleTemplate() {
+	if t.styleTemplateLabel == nil {
+		return
+	}
+
+	path := ""
+	if profile := t.app.GetProfile(); profile != nil {
+		path = profile.Settings.StyleTemplatePath
+	}
+
+	if path == "" {
+		t.styleTemplateLabel.SetText("без оформления")
+		t.styleClearBtn.Disable()
+		return
+	}
+	t.styleTemplateLabel.SetText(filepath.Base(path))
+	t.styleClearBtn.Enable()
+}
+
+// setOnErrorPolicy сохраняет политику обработки ошибок листа в текущем профиле
+func (t *MergeTab) setOnErrorPolicy(continueOnError bool) {
+	profile := t.app.GetProfile()
+	if profile == nil {
+		return
+	}
+
+	if continueOnError {
+		profile.Settings.OnError = core.OnErrorContinue
+	} else {
+		profile.Settings.OnError = core.OnErrorAbort
+	}
+	t.app.ProfileChanged()
+}
+
+// refreshOnErrorPolicy обновляет отображение политики обработки ошибок текущего профиля
+func (t *MergeTab) refreshOnErrorPolicy() {
+	if t.continueOnErrorChk == nil {
+		return
+	}
+
+	profile := t.app.GetProfile()
+	if profile == nil {
+		t.continueOnErrorChk.SetChecked(false)
+		t.continueOnErrorChk.Disable()
+		return
+	}
+	t.continueOnErrorChk.Enable()
+	t.continueOnErrorChk.SetChecked(profile.Settings.OnError == core.OnErrorContinue)
+}
+
+// setMergeIntoBase сохраняет режим дописывания в копию базового файла в текущем профиле
+func (t *MergeTab) setMergeIntoBase(enabled bool) {
+	if profile := t.app.GetProfile(); profile != nil {
+		profile.Settings.MergeIntoBase = enabled
+		t.app.ProfileChanged()
+	}
+}
+
+// refreshMergeIntoBase обновляет отображение режима дописывания в базовый файл текущего профиля
+func (t *MergeTab) refreshMergeIntoBase() {
+	if t.mergeIntoBaseChk == nil {
+		return
+	}
+
+	profile := t.app.GetProfile()
+	if profile == nil {
+		t.mergeIntoBaseChk.SetChecked(false)
+		t.mergeIntoBaseChk.Disable()
+		return
+	}
+	t.mergeIntoBaseChk.Enable()
+	t.mergeIntoBaseChk.SetChecked(profile.Settings.MergeIntoBase)
+}
+
+// setPreserveConditionalFormats сохраняет перенос условного форматирования в текущем профиле
+func (t *MergeTab) setPreserveConditionalFormats(enabled bool) {
+	if profile := t.app.GetProfile(); profile != nil {
+		profile.Settings.PreserveConditionalFormats = enabled
+		t.app.ProfileChanged()
+	}
+}
+
+// refreshPreserveConditionalFormats обновляет отображение переноса условного форматирования текущего профиля
+func (t *MergeTab) refreshPreserveConditionalFormats() {
+	if t.conditionalFormatsChk == nil {
+		return
+	}
+
+	profile := t.app.GetProfile()
+	if profile == nil {
+		t.conditionalFormatsChk.SetChecked(false)
+		t.conditionalFormatsChk.Disable()
+		return
+	}
+	t.conditionalFormatsChk.Enable()
+	t.conditionalFormatsChk.SetChecked(profile.Settings.PreserveConditionalFormats)
+}
+
+// setSkipDuplicates сохраняет политику для файлов с одинаковым содержимым в текущем профиле
+func (t *MergeTab) setSkipDuplicates(skip bool) {
+	profile := t.app.GetProfile()
+	if profile == nil {
+		return
+	}
+
+	if skip {
+		profile.Settings.DuplicateFiles = core.DuplicateFilesSkip
+	} else {
+		profile.Settings.DuplicateFiles = core.DuplicateFilesWarn
+	}
+	t.app.ProfileChanged()
+}
+
+// setProtectHeaders сохраняет защиту строк шапки листов результата в текущем профиле
+func (t *MergeTab) setProtectHeaders(enabled bool) {
+	if profile := t.app.GetProfile(); profile != nil {
+		profile.Settings.ProtectHeaders = enabled
+		t.app.ProfileChanged()
+	}
+	if enabled {
+		t.protectionPasswordEntry.Enable()
+	} else {
+		t.protectionPasswordEntry.Disable()
+	}
+}
+
+// setProtectionPassword сохраняет пароль защиты шапки в текущем профиле
+func (t *MergeTab) setProtectionPassword(password string) {
+	profile := t.app.GetProfile()
+	if profile == nil || profile.Settings.ProtectionPassword == password {
+		return
+	}
+	profile.Settings.ProtectionPassword = password
+	t.app.ProfileChanged()
+}
+
+// refreshProtectHeaders обновляет отображение защиты шапки текущего профиля
+func (t *MergeTab) refreshProtectHeaders() {
+	if t.protectHeadersChk == nil {
+		return
+	}
+
+	profile := t.app.GetProfile()
+	if profile == nil {
+		t.protectHeadersChk.SetChecked(false)
+		t.protectHeadersChk.Disable()
+		t.protectionPasswordEntry.SetText("")
+		t.protectionPasswordEntry.Disable()
+		return
+	}
+	t.protectHeadersChk.Enable()
+	t.protectionPasswordEntry.SetText(profile.Settings.ProtectionPassword)
+	t.protectHeadersChk.SetChecked(profile.Settings.ProtectHeaders)
+	if profile.Settings.ProtectHeaders {
+		t.protectionPasswordEntry.Enable()
+	} else {
+		t.protectionPasswordEntry.Disable()
+	}
+}
+
+// refreshSkipDuplicates обновляет отображение политики для одинаковых файлов текущего профиля
+func (t *MergeTab) refreshSkipDuplicates() {
+	if t.skipDuplicatesChk == nil {
+		return
+	}
+
+	profile := t.app.GetProfile()
+	if profile == nil {
+		t.skipDuplicatesChk.SetChecked(false)
+		t.skipDuplicatesChk.Disable()
+		return
+	}
+	t.skipDuplicatesChk.Enable()
+	t.skipDuplicatesChk.SetChecked(profile.Settings.DuplicateFiles == core.DuplicateFilesSkip)
+}
+
 // onStartMerge обработчик начала объединения
 func (t *MergeTab) onStartMerge() {
 	if t.mergeInProgress {
@@ -137,30 +454,128 @@ func (t *MergeTab) onStartMerge() {
 	profile := t.app.GetProfile()
 	files := t.app.fileListTab.GetFiles()
 
-	// Показываем предупреждение для больших объемов
-	if len(files) >= 5 {
-		t.app.ShowConfirm(
-			"Предупреждение",
-			fmt.Sprintf(
-				"Вы собираетесь объединить %d файлов.\n\n"+
-					"⚠️ Объединение может занять продолжительное время.\n\n"+
-					"При обработке больших файлов полоса прогресса может временно остановиться — "+
-					"это нормально и происходит при чтении файлов. "+
-					"Пожалуйста, дождитесь завершения операции.\n\n"+
-					"Продолжить?",
-				len(files),
-			),
+	t.estimateMerge(profile, files, func(estimate *core.MergeEstimate) {
+		t.estimate = estimate
+		t.confirmMerge(profile, files, estimate)
+	})
+}
+
+// estimateMerge оценивает объем объединения в фоне и передает прогноз в onDone
+// в главной горутине; если оценить не удалось, передается nil
+func (t *MergeTab) estimateMerge(profile *core.Profile, files []string, onDone func(*core.MergeEstimate)) {
+	t.startBtn.Disable()
+	progress := dialog.NewCustomWithoutButtons("Оценка объединения",
+		container.NewVBox(
+			widget.NewLabel("Оценка объема данных..."),
+			widget.NewProgressBarInfinite(),
+		),
+		t.app.window,
+	)
+	progress.Show()
+
+	go func() {
+		var estimate *core.MergeEstimate
+		defer func() {
+			fyne.Do(func() {
+				progress.Hide()
+				t.startBtn.Enable()
+				onDone(estimate)
+			})
+		}()
+		defer apperrors.Recover(t.app.logger, "оценка объединения", nil)
+
+		result, err := t.app.merger.EstimateFiles(t.app.GetBaseFile(), files, enabledSheetConfigs(profile))
+		if err != nil {
+			// Без прогноза объединение запускается как раньше; ошибка чтения проявится при объединении
+			t.app.logger.Warn("не удалось оценить объединение", "error", err)
+			return
+		}
+		estimate = &result
+	}()
+}
+
+// confirmMerge запускает объединение после предупреждений: о нехватке памяти, если прогноз
+// превышает порог из настроек, и о длительном объединении большого числа файлов
+func (t *MergeTab) confirmMerge(profile *core.Profile, files []string, estimate *core.MergeEstimate) {
+	start := func() {
+		t.startMergeProcess(profile, files)
+	}
+	settings := t.app.GetSettings()
+
+	// Предупреждение о памяти показывается всегда: флажок "Больше не показывать" его не отключает
+	if limit := settings.MergeMemoryLimit(); estimate != nil && estimate.ExceedsMemory(limit) {
+		t.app.logger.Warn("прогноз памяти объединения превышает порог",
+			"peak_memory_bytes", estimate.PeakMemoryBytes,
+			"limit_bytes", limit,
+		)
+		t.app.ShowConfirm("Может не хватить памяти",
+			fmt.Sprintf("⚠️ %s.\n\n"+
+				"Это больше порога %s из настроек: компьютер может не справиться, "+
+				"и объединение прервется.\n"+
+				"Рекомендуется разделить файлы на несколько объединений.\n\n"+
+				"Все равно продолжить?",
+				estimate, core.FormatSize(limit)),
 			func(confirmed bool) {
 				if confirmed {
-					t.startMergeProcess(profile, files)
+					start()
 				}
-			},
-		)
+			})
+		return
+	}
+
+	// Показываем предупреждение для больших объемов, если пользователь его не отключил
+	if settings.ShouldWarnLargeMerge(len(files)) {
+		t.confirmLargeMerge(len(files), estimate, start)
 		return
 	}
 
 	// Для малого количества файлов запускаем сразу
-	t.startMergeProcess(profile, files)
+	start()
+}
+
+// confirmLargeMerge показывает предупреждение о длительном объединении с флажком
+// "Больше не показывать"; выбор флажка сохраняется в настройках при любом ответе
+func (t *MergeTab) confirmLargeMerge(fileCount int, estimate *core.MergeEstimate, onConfirm func()) {
+	estimateLine := ""
+	if estimate != nil {
+		estimateLine = estimate.String() + ".\n\n"
+	}
+	message := widget.NewLabel(fmt.Sprintf(
+		"Вы собираетесь объединить %d файлов.\n\n"+
+			"%s"+
+			"⚠️ Объединение может занять продолжительное время.\n\n"+
+			"При обработке больших файлов полоса прогресса может временно остановиться — "+
+			"это нормально и происходит при чтении файлов. "+
+			"Пожалуйста, дождитесь завершения операции.\n\n"+
+			"Продолжить?",
+		fileCount,
+		estimateLine,
+	))
+	message.Wrapping = fyne.TextWrapWord
+	suppressChk := widget.NewCheck("Больше не показывать", nil)
+
+	confirm := dialog.NewCustomConfirm("Предупреждение", "Да", "Нет",
+		container.NewVBox(message, suppressChk),
+		func(confirmed bool) {
+			if suppressChk.Checked {
+				settings := t.app.GetSettings()
+				settings.SuppressLargeMergeWarning = true
+				if err := t.app.configManager.SaveSettings(settings); err != nil {
+					t.app.logger.Error("не удалось сохранить настройки", "error", err)
+				}
+				if t.app.settingsTab != nil {
+					t.app.settingsTab.RefreshLargeMergeWarning()
+				}
+				t.app.logger.Info("Large merge warning suppressed")
+			}
+			if confirmed {
+				onConfirm()
+			}
+		},
+		t.app.window,
+	)
+	confirm.Resize(fyne.NewSize(480, 0))
+	confirm.Show()
 }
 
 // startMergeProcess запускает процесс объединения
@@ -171,61 +586,91 @@ func (t *MergeTab) startMergeProcess(profile *core.Profile, files []string) {
 	t.statusLabel.SetText("Начинаю объединение...")
 	t.detailsLabel.SetText("")
 	t.resultPreview.SetText("")
+	t.showWarnings(nil)
 	t.startBtn.Disable()
 	t.saveBtn.Disable()
+	t.copyLogBtn.Disable()
 	t.mergeInProgress = true
+	t.releaseResult()
 
-	// Создаем канал для обновления прогресса
-	progressChan := make(chan core.ProgressUpdate, 10)
+	// Приемником прогресса владеет горутина отображения: она закрывает его после
+	// завершения объединения, и поздние обновления отбрасываются без паники
+	sink := core.NewProgressSink(context.Background(), 10)
 	doneChan := make(chan error, 1)
 
-	// Настраиваем callback для merger
-	t.app.merger.SetProgressCallback(func(current, total int, message string) {
-		progressChan <- core.ProgressUpdate{
-			Current: current,
-			Total:   total,
-			Message: message,
-		}
-	})
+	// Передаем настройки профиля и настраиваем callback для merger
+	t.app.merger.SetSettings(profile.Settings)
+	t.app.merger.SetProgressCallback(sink.Send)
 
 	// Запускаем объединение в горутине
 	go func() {
+		// Паника доставляется как обычная ошибка объединения, иначе вкладка
+		// осталась бы в состоянии "Объединение в процессе"
+		defer apperrors.Recover(t.app.logger, "объединение файлов", func(err error) {
+			doneChan <- err
+		})
+
 		startTime := time.Now()
 
 		// Создаем конфигурацию для объединения
-		sheetConfigs := make(map[string]*core.SheetConfig)
-		for i := range profile.Sheets {
-			if profile.Sheets[i].Enabled {
-				sheetConfigs[profile.Sheets[i].SheetName] = &profile.Sheets[i]
-			}
-		}
+		sheetConfigs := enabledSheetConfigs(profile)
 
 		// Получаем путь к базовому файлу
 		baseFile := t.app.GetBaseFile()
 
-		result, err := t.app.merger.MergeFiles(baseFile, files, sheetConfigs)
-		
-		doneChan <- err
-		close(progressChan)
+		session, profErr := profiling.Start(t.app.profiling, t.app.logger)
+		if profErr != nil {
+			t.app.logger.Warn("не удалось включить профилирование", "error", profErr)
+		}
 
+		result, err := t.app.merger.MergeFiles(baseFile, files, sheetConfigs)
+		t.app.merger.SetProgressCallback(nil)
 		if result != nil {
 			result.Duration = time.Since(startTime)
+			result.Estimate = t.estimate
 			t.mergeResult = result
+			session.AddRows(result.TotalRows)
+		}
+
+		stats, profErr := session.Stop()
+		if profErr != nil {
+			t.app.logger.Warn("не удалось сохранить профили объединения", "error", profErr)
 		}
+		t.profileStats = stats
+
+		doneChan <- err
 	}()
 
 	// Обновляем UI в главной горутине
 	go func() {
-		for update := range progressChan {
-			// Копируем значения для замыкания
-			currentUpdate := update
+		defer apperrors.Recover(t.app.logger, "отображение прогресса объединения", func(err error) {
+			// Закрытый приемник не блокирует объединение; дожидаемся его завершения
+			sink.Close()
+			<-doneChan
+			fyne.Do(func() {
+				t.finishMerge(profile, files, err)
+			})
+		})
+
+		for {
+			var currentUpdate core.ProgressUpdate
+			select {
+			case currentUpdate = <-sink.Updates():
+			case err := <-doneChan:
+				sink.Close()
+				fyne.Do(func() {
+					t.finishMerge(profile, files, err)
+				})
+				return
+			}
+
 			fyne.Do(func() {
 				if currentUpdate.Total > 0 {
 					progress := float64(currentUpdate.Current) / float64(currentUpdate.Total)
 					t.progressBar.SetValue(progress)
 				}
 				t.statusLabel.SetText(currentUpdate.Message)
-				
+
 				// Обновляем детали
 				t.detailsLabel.SetText(fmt.Sprintf(
 					"Обработано: %d из %d",
@@ -234,48 +679,95 @@ func (t *MergeTab) startMergeProcess(profile *core.Profile, files []string) {
 				))
 			})
 		}
+	}()
+}
 
-		// Ждем завершения
-		err := <-doneChan
-		
-		fyne.Do(func() {
-			t.mergeInProgress = false
-			t.startBtn.Enable()
+// enabledSheetConfigs возвращает конфигурации включенных листов профиля по имени листа
+func enabledSheetConfigs(profile *core.Profile) map[string]*core.SheetConfig {
+	sheetConfigs := make(map[string]*core.SheetConfig)
+	for i := range profile.Sheets {
+		if profile.Sheets[i].Enabled {
+			sheetConfigs[profile.Sheets[i].SheetName] = &profile.Sheets[i]
+		}
+	}
+	return sheetConfigs
+}
 
-			if err != nil {
-				t.statusLabel.SetText("Ошибка при объединении")
-				t.progressBar.SetValue(0)
-				t.app.ShowError(err)
-				t.app.logger.Error("Merge failed", "error", err)
-				return
-			}
+// finishMerge возвращает вкладку в исходное состояние и показывает итог объединения
+// Вызывается в главной горутине
+func (t *MergeTab) finishMerge(profile *core.Profile, files []string, err error) {
+	t.mergeInProgress = false
+	t.startBtn.Enable()
 
-			// Объединение успешно
-			t.statusLabel.SetText("Объединение завершено успешно!")
-			t.progressBar.SetValue(1)
-			t.saveBtn.Enable()
+	if err != nil {
+		t.statusLabel.SetText("Ошибка при объединении")
+		t.progressBar.SetValue(0)
+		t.app.logger.Error("Merge failed", "error", err)
+		// Журнал неудачного запуска можно скопировать для разбора причины
+		if t.mergeResult != nil {
+			t.copyLogBtn.Enable()
+		}
 
-			t.showMergeResult()
+		// При превышении лимита строк предлагаем разбить листы
+		if isRowLimitError(err) && !profile.Settings.AutoSplitLargeSheets {
+			t.offerAutoSplit(profile, files)
+			return
+		}
 
-			t.app.logger.Info("Merge completed successfully",
-				"duration_ms", t.mergeResult.Duration.Milliseconds(),
-				"total_rows", t.mergeResult.TotalRows,
-			)
-		})
-	}()
+		t.app.ShowError(err)
+		return
+	}
+
+	// Объединение успешно
+	t.statusLabel.SetText("Объединение завершено успешно!")
+	t.progressBar.SetValue(1)
+	t.saveBtn.Enable()
+	t.copyLogBtn.Enable()
+
+	t.showMergeResult()
+
+	t.app.logger.Info("Merge completed successfully",
+		"duration_ms", t.mergeResult.Duration.Milliseconds(),
+		"total_rows", t.mergeResult.TotalRows,
+	)
+}
+
+// offerAutoSplit предлагает включить разбиение больших листов и повторить объединение
+func (t *MergeTab) offerAutoSplit(profile *core.Profile, files []string) {
+	t.app.ShowConfirm(
+		"Превышен лимит строк Excel",
+		"Результат не помещается на один лист Excel (максимум 1 048 576 строк).\n\n"+
+			"Разбить данные на несколько листов (Лист, Лист_2, ...) и повторить объединение?",
+		func(confirmed bool) {
+			if confirmed {
+				profile.Settings.AutoSplitLargeSheets = true
+				t.app.ProfileChanged()
+				t.app.logger.Info("Auto split enabled after row limit error")
+				t.startMergeProcess(profile, files)
+			}
+		},
+	)
+}
+
+// isRowLimitError проверяет, вызвана ли ошибка превышением лимита строк Excel
+func isRowLimitError(err error) bool {
+	return errors.Is(err, apperrors.ErrRowLimitExceeded)
 }
 
-// validateReadiness проверяет готовность к объединению
+// validateReadiness проверяет готовность к объединению и возвращает все найденные проблемы сразу
 func (t *MergeTab) validateReadiness() error {
-	// Проверяем профиль
+	// Проверяем профиль: без него остальные проверки не имеют смысла
 	profile := t.app.GetProfile()
 	if profile == nil {
 		return apperrors.NewConfigError("Профиль не создан. Выберите базовый файл и проанализируйте его")
 	}
 
+	problems := apperrors.NewMultiError()
+
 	// Проверяем базовый файл
-	if t.app.GetBaseFile() == "" {
-		return apperrors.NewConfigError("Базовый файл не выбран")
+	baseFile := t.app.GetBaseFile()
+	if baseFile == "" {
+		problems.Append(apperrors.NewConfigError("Базовый файл не выбран"))
 	}
 
 	// Проверяем наличие включенных листов
@@ -287,16 +779,21 @@ func (t *MergeTab) validateReadiness() error {
 		}
 	}
 	if !hasEnabledSheets {
-		return apperrors.NewConfigError("Нет включенных листов для объединения")
+		problems.Append(apperrors.NewConfigError("Нет включенных листов для объединения"))
+	}
+
+	// Профиль мог быть создан для другого базового файла: все включенные листы должны в нем быть
+	if baseFile != "" && hasEnabledSheets {
+		problems.Append(t.app.analyzer.CheckEnabledSheets(baseFile, profile.Sheets))
 	}
 
 	// Проверяем список файлов
 	files := t.app.fileListTab.GetFiles()
 	if len(files) == 0 {
-		return apperrors.NewConfigError("Список файлов для объединения пуст")
+		problems.Append(apperrors.NewConfigError("Список файлов для объединения пуст"))
 	}
 
-	return nil
+	return problems.ErrorOrNil()
 }
 
 // showMergeResult показывает результат объединения
@@ -322,12 +819,121 @@ func (t *MergeTab) showMergeResult() {
 		result += "Детали по листам:\n"
 		for sheetName, stats := range t.mergeResult.SheetStats {
 			result += fmt.Sprintf("  • %s: %d строк\n", sheetName, stats.RowsMerged)
+			result += formatValueCounts(stats)
+		}
+	}
+
+	if estimate := t.mergeResult.Estimate; estimate != nil {
+		result += fmt.Sprintf("\nПрогноз перед объединением: результат ≈ %s, память ≈ %s\n",
+			core.FormatSize(estimate.OutputBytes), core.FormatSize(estimate.PeakMemoryBytes))
+	}
+
+	// Сведения для диагностики, только при включенном профилировании
+	if stats := t.profileStats; stats != nil {
+		result += fmt.Sprintf("\nПрофилирование: строк %d, куча %.1f МБ\n",
+			stats.RowsProcessed, float64(stats.HeapInUse)/(1<<20))
+		if stats.CPUProfile != "" {
+			result += fmt.Sprintf("  CPU-профиль: %s\n", stats.CPUProfile)
+		}
+		if stats.MemProfile != "" {
+			result += fmt.Sprintf("  Профиль памяти: %s\n", stats.MemProfile)
 		}
 	}
 
 	// Обновление UI должно происходить в UI-потоке
 	// Но этот метод уже вызывается из fyne.Do(), поэтому просто обновляем
 	t.resultPreview.SetText(result)
+	t.showWarnings(t.mergeResult)
+}
+
+// formatValueCounts выводит разбивку строк листа по значениям столбца небольшой таблицей
+func formatValueCounts(stats *core.SheetStat) string {
+	if len(stats.ValueCounts) == 0 {
+		return ""
+	}
+
+	// Ширина столбца значений по самому длинному значению, в символах
+	width := 0
+	for _, value := range stats.ValueCounts {
+		width = max(width, utf8.RuneCountInString(valueLabel(value.Value)))
+	}
+
+	text := fmt.Sprintf("      %s:\n", stats.GroupColumn)
+	for _, value := range stats.ValueCounts {
+		label := valueLabel(value.Value)
+		padding := strings.Repeat(" ", width-utf8.RuneCountInString(label))
+		text += fmt.Sprintf("        %s%s  %d\n", label, padding, value.Count)
+	}
+	return text
+}
+
+// valueLabel возвращает значение столбца для вывода; пустое значение подписывается
+func valueLabel(value string) string {
+	if value == "" {
+		return "(пусто)"
+	}
+	return value
+}
+
+// warningSeverityStyles порядок вывода и оформление предупреждений по уровню важности
+var warningSeverityStyles = []struct {
+	severity core.Severity
+	title    string
+	color    fyne.ThemeColorName
+}{
+	{core.SeverityError, "Пропущено из-за ошибок", theme.ColorNameError},
+	{core.SeverityWarning, "Предупреждения", theme.ColorNameWarning},
+	{core.SeverityInfo, "Сведения", theme.ColorNamePlaceHolder},
+}
+
+// showWarnings выводит предупреждения объединения, сгруппированные по уровню важности
+func (t *MergeTab) showWarnings(result *core.MergeResult) {
+	if result == nil || len(result.Warnings) == 0 {
+		t.warningsText.Segments = nil
+		t.warningsText.Refresh()
+		return
+	}
+
+	var segments []widget.RichTextSegment
+	for _, style := range warningSeverityStyles {
+		if result.WarningCounts[style.severity] == 0 {
+			continue
+		}
+		segments = append(segments, &widget.TextSegment{
+			Text:  fmt.Sprintf("%s (%d):", style.title, result.WarningCounts[style.severity]),
+			Style: widget.RichTextStyle{ColorName: style.color, TextStyle: fyne.TextStyle{Bold: true}},
+		})
+		for _, warning := range result.Warnings {
+			if warning.Severity != style.severity {
+				continue
+			}
+			segments = append(segments, &widget.TextSegment{
+				Text:  "  • " + warning.Message,
+				Style: widget.RichTextStyle{ColorName: style.color},
+			})
+		}
+	}
+
+	t.warningsText.Segments = segments
+	t.warningsText.Refresh()
+}
+
+// defaultResultName возвращает предлагаемое имя файла результата по имени базового файла
+func (t *MergeTab) defaultResultName() string {
+	baseFile := t.app.GetBaseFile()
+	if baseFile == "" {
+		return "Объединенный.xlsx"
+	}
+	name := excel.TrimGzipExt(filepath.Base(baseFile))
+	return strings.TrimSuffix(name, filepath.Ext(name)) + "_объединенный.xlsx"
+}
+
+// lastOutputDir возвращает папку последнего сохраненного результата или пустую строку
+func (t *MergeTab) lastOutputDir() string {
+	if path := t.app.GetSettings().LastOutputPath; path != "" {
+		return filepath.Dir(path)
+	}
+	return ""
 }
 
 // onSaveResult обработчик сохранения результата
@@ -337,34 +943,69 @@ func (t *MergeTab) onSaveResult() {
 		return
 	}
 
-	// Открываем нативный диалог сохранения файла
-	savePath, err := native.FileSaveDialogSimple(
-		"Сохранить объединенный файл",
-		"Excel файлы",
-		"xlsx",
-	)
-	
-	// Проверяем отмену пользователем
-	if native.IsCancelled(err) {
-		return
+	// Открываем диалог сохранения в папке последнего результата;
+	// расширение .xlsx диалог добавляет сам
+	t.app.dialogs.SaveFile("Сохранить объединенный файл", t.defaultResultName(), t.lastOutputDir(), excelFileFilter,
+		func(savePath string, err error) {
+			// Проверяем отмену пользователем
+			if native.IsCancelled(err) {
+				return
+			}
+
+			if err != nil {
+				t.app.ShowError(err)
+				return
+			}
+
+			t.saveResultAs(savePath)
+		})
+}
+
+// saveResultAs сохраняет результат в savePath по политике профиля для существующего файла
+// Если политика не задана, а файл уже есть, спрашивает: заменить файл или дописать в него
+func (t *MergeTab) saveResultAs(savePath string) {
+	onExisting := ""
+	if profile := t.app.GetProfile(); profile != nil {
+		onExisting = profile.Settings.OnExistingOutput
 	}
-	
-	if err != nil {
-		t.app.ShowError(err)
+	if _, err := os.Stat(savePath); onExisting != "" || err != nil {
+		t.saveResult(savePath, onExisting)
 		return
 	}
 
-	// Убеждаемся что путь имеет расширение .xlsx
-	if filepath.Ext(savePath) != ".xlsx" {
-		savePath += ".xlsx"
+	var choice dialog.Dialog
+	choose := func(policy string) func() {
+		return func() {
+			choice.Hide()
+			if policy != core.OnExistingCancel {
+				t.saveResult(savePath, policy)
+			}
+		}
 	}
+	message := widget.NewLabel(fmt.Sprintf("Файл уже существует:\n%s\n\nЗаменить его новым результатом или дописать строки в листы этого файла?", savePath))
+	message.Wrapping = fyne.TextWrapWord
+	buttons := container.NewHBox(
+		widget.NewButton("Заменить", choose(core.OnExistingOverwrite)),
+		widget.NewButton("Дописать", choose(core.OnExistingAppend)),
+		widget.NewButton("Отмена", choose(core.OnExistingCancel)),
+	)
+	choice = dialog.NewCustomWithoutButtons("Файл уже существует", container.NewBorder(nil, buttons, nil, nil, message), t.app.window)
+	choice.Resize(fyne.NewSize(500, 200))
+	choice.Show()
+}
 
-	// Сохраняем объединенный файл
-	if err := t.mergeResult.WorkbookData.Save(savePath); err != nil {
+// saveResult сохраняет результат объединения по пути savePath
+// onExisting - политика для уже существующего файла (core.OnExisting*)
+func (t *MergeTab) saveResult(savePath, onExisting string) {
+
+	// Сохраняем объединенный файл; существующий файл заменяется или дополняется только после успешной записи
+	if err := t.mergeResult.SaveWithPolicy(savePath, onExisting); err != nil {
 		t.app.ShowError(err)
 		return
 	}
 
+	t.app.SetLastOutputPath(savePath)
+
 	t.app.ShowInfo(
 		"Файл сохранен",
 		fmt.Sprintf("Результат успешно сохранен в:\n%s\n\nОбъединено строк: %d", 
@@ -376,6 +1017,34 @@ func (t *MergeTab) onSaveResult() {
 		"total_rows", t.mergeResult.TotalRows,
 		"processed_files", t.mergeResult.ProcessedFiles,
 	)
+	if hash := t.mergeResult.OutputHash; hash != nil {
+		t.app.logger.Info("Merge result fingerprint", "path", savePath, "algorithm", hash.Algorithm, "hash", hash.Sum, "size", hash.Size)
+	}
+	for _, hash := range t.mergeResult.InputHashes {
+		t.app.logger.Info("Merge input fingerprint", "path", hash.Path, "algorithm", hash.Algorithm, "hash", hash.Sum, "size", hash.Size)
+	}
+}
+
+// onCopyMergeLog копирует журнал последнего объединения в буфер обмена
+func (t *MergeTab) onCopyMergeLog() {
+	if t.mergeResult == nil {
+		return
+	}
+
+	t.app.fyneApp.Clipboard().SetContent(strings.Join(t.mergeResult.LogLines, "\n"))
+	t.app.ShowInfo("Журнал скопирован",
+		fmt.Sprintf("Записей журнала: %d\nИдентификатор запуска: %s", len(t.mergeResult.LogLines), t.mergeResult.RunID))
+}
+
+// releaseResult закрывает книгу предыдущего результата объединения
+func (t *MergeTab) releaseResult() {
+	if t.mergeResult == nil {
+		return
+	}
+	if err := t.mergeResult.Close(); err != nil {
+		t.app.logger.Warn("не удалось закрыть результат объединения", "error", err)
+	}
+	t.mergeResult = nil
 }
 
 // Reset сбрасывает состояние вкладки
@@ -384,8 +1053,10 @@ func (t *MergeTab) Reset() {
 	t.statusLabel.SetText("Готов к объединению")
 	t.detailsLabel.SetText("")
 	t.resultPreview.SetText("")
-	t.mergeResult = nil
+	t.showWarnings(nil)
+	t.releaseResult()
 	t.saveBtn.Disable()
+	t.copyLogBtn.Disable()
 	t.startBtn.Enable()
 	t.mergeInProgress = false
 }
@@ -0,0 +1,740 @@
+package gui
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/DatKorso/Merge-excel/internal/config"
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+	"github.com/DatKorso/Merge-excel/internal/logger"
+)
+
+// updateIntervalOption вариант интервала автоматической проверки обновлений
+type updateIntervalOption struct {
+	label string
+	hours int
+}
+
+// updateIntervalOptions доступные интервалы проверки обновлений
+var updateIntervalOptions = []updateIntervalOption{
+	{"При каждом запуске", 0},
+	{"Каждые 6 часов", 6},
+	{"Раз в день", 24},
+	{"Раз в неделю", 24 * 7},
+}
+
+// updateNotificationOption вариант уведомления о новой версии
+type updateNotificationOption struct {
+	label string
+	style string
+}
+
+// updateNotificationOptions доступные способы уведомления о новой версии
+var updateNotificationOptions = []updateNotificationOption{
+	{"Окно с описанием", config.UpdateNotificationDialog},
+	{"Баннер вверху окна", config.UpdateNotificationBanner},
+	{"Только запись в журнал", config.UpdateNotificationSilent},
+}
+
+// logLevelOption вариант уровня журнала
+type logLevelOption struct {
+	label string
+	level string
+}
+
+// logLevelOptions доступные уровни журнала
+var logLevelOptions = []logLevelOption{
+	{"Подробный (debug)", "debug"},
+	{"Обычный (info)", "info"},
+	{"Предупреждения (warn)", "warn"},
+	{"Только ошибки (error)", "error"},
+}
+
+// logFormatOption вариант формата журнала
+type logFormatOption struct {
+	label  string
+	format string
+}
+
+// logFormatOptions доступные форматы журнала
+var logFormatOptions = []logFormatOption{
+	{"JSON в файле, текст в консоли", logger.FormatBoth},
+	{"Текст (удобно читать)", logger.FormatText},
+	{"JSON (для обработки)", logger.FormatJSON},
+}
+
+// memoryLimitOption вариант порога ожидаемой памяти объединения
+type memoryLimitOption struct {
+	label   string
+	limitMB int
+}
+
+// memoryLimitOptions доступные пороги ожидаемой памяти объединения
+var memoryLimitOptions = []memoryLimitOption{
+	{"2 ГБ", 2048},
+	{"4 ГБ (по умолчанию)", 0},
+	{"8 ГБ", 8192},
+	{"16 ГБ", 16384},
+	{"Не предупреждать", -1},
+}
+
+// maxFilesOption вариант ограничения количества файлов для объединения
+type maxFilesOption struct {
+	label    string
+	maxFiles int
+}
+
+// maxFilesOptions доступные ограничения количества файлов для объединения
+var maxFilesOptions = []maxFilesOption{
+	{"50", 50},
+	{"100 (по умолчанию)", config.DefaultMaxMergeFiles},
+	{"250", 250},
+	{"500", 500},
+	{"Без ограничения", 0},
+}
+
+// droppedCSVOption вариант обработки перетащенных CSV-файлов
+type droppedCSVOption struct {
+	label  string
+	action string
+}
+
+// droppedCSVOptions доступные способы обработки перетащенных CSV-файлов
+var droppedCSVOptions = []droppedCSVOption{
+	{"Предлагать преобразовать в xlsx", config.DroppedCSVConvert},
+	{"Пропускать", config.DroppedCSVSkip},
+}
+
+// maxDefaultHeaderRow наибольшая строка заголовков для новых листов, доступная в настройках
+const maxDefaultHeaderRow = 10
+
+// languageOption вариант языка сообщений об ошибках
+type languageOption struct {
+	label string
+	lang  string
+}
+
+// languageOptions доступные языки сообщений об ошибках
+var languageOptions = []languageOption{
+	{"Русский", apperrors.LangRussian},
+	{"English", apperrors.LangEnglish},
+}
+
+// SettingsTab вкладка настроек приложения
+type SettingsTab struct {
+	app *App
+
+	// UI элементы
+	checkUpdatesChk *widget.Check
+	intervalSelect  *widget.Select
+	lastCheckLabel  *widget.Label
+	selfUpdateChk   *widget.Check
+	notifySelect    *widget.Select
+	logLevelSelect  *widget.Select
+	logFormatSelect *widget.Select
+	redactPathsChk  *widget.Check
+	languageSelect  *widget.Select
+	largeMergeChk   *widget.Check
+	memoryLimitSel  *widget.Select
+	maxFilesSel     *widget.Select
+	autosaveChk     *widget.Check
+	headerRowSel    *widget.Select
+	droppedCSVSel   *widget.Select
+	ozonBrandsLabel *widget.Label
+}
+
+// NewSettingsTab создает новую вкладку настроек
+func NewSettingsTab(app *App) *SettingsTab {
+	return &SettingsTab{app: app}
+}
+
+// Build создает UI вкладки
+func (t *SettingsTab) Build() fyne.CanvasObject {
+	settings := t.app.GetSettings()
+
+	// Переключатель автоматической проверки обновлений
+	t.checkUpdatesChk = widget.NewCheck("Автоматически проверять обновления при запуске", nil)
+	t.checkUpdatesChk.Checked = settings.CheckUpdates
+
+	// Интервал между проверками
+	labels := make([]string, 0, len(updateIntervalOptions)+1)
+	for _, option := range updateIntervalOptions {
+		labels = append(labels, option.label)
+	}
+	current := intervalLabel(settings.CheckIntervalHours)
+	if !containsString(labels, current) {
+		labels = append(labels, current)
+	}
+	t.intervalSelect = widget.NewSelect(labels, nil)
+	t.intervalSelect.SetSelected(current)
+	if !settings.CheckUpdates {
+		t.intervalSelect.Disable()
+	}
+
+	t.lastCheckLabel = widget.NewLabel("")
+	t.RefreshUpdateStatus()
+
+	// Разрешение автоматической установки обновлений
+	t.selfUpdateChk = widget.NewCheck("Разрешить установку обновлений из приложения (с подтверждением)", nil)
+	t.selfUpdateChk.Checked = settings.AllowSelfUpdate
+
+	// Способ уведомления о новой версии
+	notifyLabels := make([]string, 0, len(updateNotificationOptions))
+	for _, option := range updateNotificationOptions {
+		notifyLabels = append(notifyLabels, option.label)
+	}
+	t.notifySelect = widget.NewSelect(notifyLabels, nil)
+	t.notifySelect.SetSelected(notificationLabel(settings.UpdateNotification))
+
+	// Уровень журнала
+	levelLabels := make([]string, 0, len(logLevelOptions))
+	for _, option := range logLevelOptions {
+		levelLabels = append(levelLabels, option.label)
+	}
+	t.logLevelSelect = widget.NewSelect(levelLabels, nil)
+	t.logLevelSelect.SetSelected(logLevelLabel(logger.Level()))
+
+	// Формат журнала
+	formatLabels := make([]string, 0, len(logFormatOptions))
+	for _, option := range logFormatOptions {
+		formatLabels = append(formatLabels, option.label)
+	}
+	t.logFormatSelect = widget.NewSelect(formatLabels, nil)
+	t.logFormatSelect.SetSelected(logFormatLabel(logger.Format()))
+
+	// Скрытие пользовательских путей в журнале
+	t.redactPathsChk = widget.NewCheck("Скрывать путь к домашней папке пользователя в журнале", nil)
+	t.redactPathsChk.Checked = settings.RedactLogPaths
+
+	// Язык сообщений об ошибках
+	languageLabels := make([]string, 0, len(languageOptions))
+	for _, option := range languageOptions {
+		languageLabels = append(languageLabels, option.label)
+	}
+	t.languageSelect = widget.NewSelect(languageLabels, nil)
+	t.languageSelect.SetSelected(languageLabel(settings.Language))
+
+	// Предупреждение перед объединением большого числа файлов
+	t.largeMergeChk = widget.NewCheck(
+		fmt.Sprintf("Предупреждать перед объединением %d и более файлов", config.LargeMergeFileCount), nil)
+	t.largeMergeChk.Checked = !settings.SuppressLargeMergeWarning
+
+	// Порог ожидаемой памяти объединения
+	memoryLabels := make([]string, 0, len(memoryLimitOptions)+1)
+	for _, option := range memoryLimitOptions {
+		memoryLabels = append(memoryLabels, option.label)
+	}
+	currentLimit := memoryLimitLabel(settings.MergeMemoryLimitMB)
+	if !containsString(memoryLabels, currentLimit) {
+		memoryLabels = append(memoryLabels, currentLimit)
+	}
+	t.memoryLimitSel = widget.NewSelect(memoryLabels, nil)
+	t.memoryLimitSel.SetSelected(currentLimit)
+
+	// Ограничение количества файлов в списке для объединения
+	maxFilesLabels := make([]string, 0, len(maxFilesOptions)+1)
+	for _, option := range maxFilesOptions {
+		maxFilesLabels = append(maxFilesLabels, option.label)
+	}
+	currentMaxFiles := maxFilesLabel(settings.MaxMergeFiles)
+	if !containsString(maxFilesLabels, currentMaxFiles) {
+		maxFilesLabels = append(maxFilesLabels, currentMaxFiles)
+	}
+	t.maxFilesSel = widget.NewSelect(maxFilesLabels, nil)
+	t.maxFilesSel.SetSelected(currentMaxFiles)
+
+	// Автосохранение текущего профиля
+	t.autosaveChk = widget.NewCheck("Автосохранять текущий профиль и предлагать восстановить его при запуске", nil)
+	t.autosaveChk.Checked = settings.AutosaveProfile
+
+	// Строка заголовков для листов нового базового файла
+	headerRowLabels := make([]string, 0, maxDefaultHeaderRow+1)
+	for row := 1; row <= maxDefaultHeaderRow; row++ {
+		headerRowLabels = append(headerRowLabels, headerRowLabel(row))
+	}
+	currentHeaderRow := headerRowLabel(settings.HeaderRowForNewSheets())
+	if !containsString(headerRowLabels, currentHeaderRow) {
+		headerRowLabels = append(headerRowLabels, currentHeaderRow)
+	}
+	t.headerRowSel = widget.NewSelect(headerRowLabels, nil)
+	t.headerRowSel.SetSelected(currentHeaderRow)
+
+	// Перетащенные CSV-файлы
+	droppedCSVLabels := make([]string, 0, len(droppedCSVOptions))
+	for _, option := range droppedCSVOptions {
+		droppedCSVLabels = append(droppedCSVLabels, option.label)
+	}
+	t.droppedCSVSel = widget.NewSelect(droppedCSVLabels, nil)
+	t.droppedCSVSel.SetSelected(droppedCSVLabel(settings.DroppedCSV))
+
+	// Бренды фильтра шаблона Ozon
+	t.ozonBrandsLabel = widget.NewLabel("")
+	t.ozonBrandsLabel.Wrapping = fyne.TextWrapWord
+	t.refreshOzonBrands()
+
+	// Обработчики устанавливаются после начальной инициализации значений
+	t.checkUpdatesChk.OnChanged = t.onCheckUpdatesToggled
+	t.intervalSelect.OnChanged = t.onIntervalChanged
+	t.selfUpdateChk.OnChanged = t.onSelfUpdateToggled
+	t.notifySelect.OnChanged = t.onNotificationChanged
+	t.logLevelSelect.OnChanged = t.onLogLevelChanged
+	t.logFormatSelect.OnChanged = t.onLogFormatChanged
+	t.redactPathsChk.OnChanged = t.onRedactPathsToggled
+	t.languageSelect.OnChanged = t.onLanguageChanged
+	t.largeMergeChk.OnChanged = t.onLargeMergeWarningToggled
+	t.memoryLimitSel.OnChanged = t.onMemoryLimitChanged
+	t.maxFilesSel.OnChanged = t.onMaxFilesChanged
+	t.autosaveChk.OnChanged = t.onAutosaveToggled
+	t.headerRowSel.OnChanged = t.onHeaderRowChanged
+	t.droppedCSVSel.OnChanged = t.onDroppedCSVChanged
+
+	updatesCard := widget.NewCard("Обновления", "", container.NewVBox(
+		t.checkUpdatesChk,
+		container.NewBorder(nil, nil, widget.NewLabel("Интервал проверки:"), nil, t.intervalSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Уведомление о новой версии:"), nil, t.notifySelect),
+		t.lastCheckLabel,
+		t.selfUpdateChk,
+	))
+
+	logItems := []fyne.CanvasObject{
+		container.NewBorder(nil, nil, widget.NewLabel("Уровень журнала:"), nil, t.logLevelSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Формат журнала:"), nil, t.logFormatSelect),
+		t.redactPathsChk,
+		widget.NewButton("Просмотр журнала", t.onShowLogViewer),
+	}
+	for _, env := range []struct{ name, what string }{
+		{logger.LevelEnv, "уровень"},
+		{logger.FormatEnv, "формат"},
+	} {
+		if os.Getenv(env.name) != "" {
+			envHint := widget.NewLabel(fmt.Sprintf("При запуске %s задается переменной окружения %s", env.what, env.name))
+			envHint.Wrapping = fyne.TextWrapWord
+			logItems = append(logItems, envHint)
+		}
+	}
+	logCard := widget.NewCard("Журнал", "", container.NewVBox(logItems...))
+
+	languageCard := widget.NewCard("Язык", "", container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Сообщения об ошибках:"), nil, t.languageSelect),
+	))
+
+	mergeCard := widget.NewCard("Объединение", "", container.NewVBox(
+		t.largeMergeChk,
+		container.NewBorder(nil, nil, widget.NewLabel("Предупреждать, если потребуется памяти больше:"), nil, t.memoryLimitSel),
+		container.NewBorder(nil, nil, widget.NewLabel("Файлов в списке для объединения не больше:"), nil, t.maxFilesSel),
+		container.NewBorder(nil, nil, widget.NewLabel("Строка заголовков новых листов:"), nil, t.headerRowSel),
+		container.NewBorder(nil, nil, widget.NewLabel("Перетащенные CSV-файлы:"), nil, t.droppedCSVSel),
+		t.autosaveChk,
+	))
+
+	ozonCard := widget.NewCard("Шаблон Ozon", "", container.NewVBox(
+		t.ozonBrandsLabel,
+		widget.NewButton("Изменить список брендов...", t.onEditOzonBrands),
+	))
+
+	return container.NewVScroll(container.NewVBox(updatesCard, mergeCard, ozonCard, logCard, languageCard))
+}
+
+// RefreshUpdateStatus обновляет информацию о последней проверке обновлений
+func (t *SettingsTab) RefreshUpdateStatus() {
+	if t.lastCheckLabel == nil {
+		return
+	}
+
+	settings := t.app.GetSettings()
+	if settings.LastCheckedAt.IsZero() {
+		t.lastCheckLabel.SetText("Последняя проверка: не выполнялась")
+		return
+	}
+
+	text := fmt.Sprintf("Последняя проверка: %s", settings.LastCheckedAt.Format("02.01.2006 15:04"))
+	if settings.UpdateCheckFailures > 0 {
+		text += fmt.Sprintf(" (неудачных попыток подряд: %d)", settings.UpdateCheckFailures)
+	}
+	t.lastCheckLabel.SetText(text)
+}
+
+// onCheckUpdatesToggled обработчик переключения автоматической проверки обновлений
+func (t *SettingsTab) onCheckUpdatesToggled(checked bool) {
+	settings := t.app.GetSettings()
+	settings.CheckUpdates = checked
+	t.saveSettings()
+
+	if checked {
+		t.intervalSelect.Enable()
+	} else {
+		t.intervalSelect.Disable()
+	}
+
+	t.app.logger.Info("Automatic update check toggled", "enabled", checked)
+}
+
+// onIntervalChanged обработчик выбора интервала проверки обновлений
+func (t *SettingsTab) onIntervalChanged(label string) {
+	for _, option := range updateIntervalOptions {
+		if option.label == label {
+			t.app.GetSettings().CheckIntervalHours = option.hours
+			t.saveSettings()
+			t.app.logger.Info("Update check interval changed", "hours", option.hours)
+			return
+		}
+	}
+}
+
+// onSelfUpdateToggled обработчик разрешения установки обновлений из приложения
+func (t *SettingsTab) onSelfUpdateToggled(checked bool) {
+	t.app.GetSettings().AllowSelfUpdate = checked
+	t.saveSettings()
+	t.app.logger.Info("Self-update toggled", "enabled", checked)
+}
+
+// onNotificationChanged обработчик выбора способа уведомления о новой версии
+func (t *SettingsTab) onNotificationChanged(label string) {
+	for _, option := range updateNotificationOptions {
+		if option.label == label {
+			t.app.GetSettings().UpdateNotification = option.style
+			t.saveSettings()
+			t.app.logger.Info("Update notification style changed", "style", option.style)
+			return
+		}
+	}
+}
+
+// onLogLevelChanged обработчик выбора уровня журнала, применяется сразу
+func (t *SettingsTab) onLogLevelChanged(label string) {
+	for _, option := range logLevelOptions {
+		if option.label != label {
+			continue
+		}
+
+		level, err := logger.ParseLevel(option.level)
+		if err != nil {
+			t.app.logger.Warn("некорректный уровень журнала", "error", err)
+			return
+		}
+		logger.SetLevel(level)
+		t.app.GetSettings().LogLevel = option.level
+		t.saveSettings()
+		t.app.logger.Info("Log level changed", "level", option.level)
+		return
+	}
+}
+
+// onLogFormatChanged обработчик выбора формата журнала, применяется сразу
+func (t *SettingsTab) onLogFormatChanged(label string) {
+	for _, option := range logFormatOptions {
+		if option.label == label {
+			logger.SetFormat(option.format)
+			t.app.GetSettings().LogFormat = option.format
+			t.saveSettings()
+			t.app.logger.Info("Log format changed", "format", option.format)
+			return
+		}
+	}
+}
+
+// onRedactPathsToggled обработчик переключения скрытия путей, применяется сразу
+func (t *SettingsTab) onRedactPathsToggled(checked bool) {
+	settings := t.app.GetSettings()
+	logger.SetRedaction(checked, settings.RedactLogRoots)
+	settings.RedactLogPaths = checked
+	t.saveSettings()
+	t.app.logger.Info("Log path redaction toggled", "enabled", checked)
+}
+
+// RefreshLargeMergeWarning обновляет флажок предупреждения о большом объединении
+// после его отключения из диалога объединения
+func (t *SettingsTab) RefreshLargeMergeWarning() {
+	if t.largeMergeChk == nil {
+		return
+	}
+	// Без SetChecked, чтобы не вызывать обработчик и повторное сохранение
+	t.largeMergeChk.Checked = !t.app.GetSettings().SuppressLargeMergeWarning
+	t.largeMergeChk.Refresh()
+}
+
+// onLargeMergeWarningToggled обработчик переключения предупреждения о большом объединении
+func (t *SettingsTab) onLargeMergeWarningToggled(checked bool) {
+	t.app.GetSettings().SuppressLargeMergeWarning = !checked
+	t.saveSettings()
+	t.app.logger.Info("Large merge warning toggled", "enabled", checked)
+}
+
+// onMemoryLimitChanged обработчик выбора порога ожидаемой памяти объединения
+func (t *SettingsTab) onMemoryLimitChanged(label string) {
+	for _, option := range memoryLimitOptions {
+		if option.label == label {
+			t.app.GetSettings().MergeMemoryLimitMB = option.limitMB
+			t.saveSettings()
+			t.app.logger.Info("Merge memory limit changed", "limit_mb", option.limitMB)
+			return
+		}
+	}
+}
+
+// onMaxFilesChanged обработчик выбора ограничения количества файлов для объединения
+// Файлы, уже добавленные в список сверх нового ограничения, остаются в нем
+func (t *SettingsTab) onMaxFilesChanged(label string) {
+	for _, option := range maxFilesOptions {
+		if option.label == label {
+			t.app.GetSettings().MaxMergeFiles = option.maxFiles
+			t.saveSettings()
+			t.app.logger.Info("Merge file limit changed", "max_files", option.maxFiles)
+			return
+		}
+	}
+}
+
+// onDroppedCSVChanged обработчик выбора обработки перетащенных CSV-файлов
+func (t *SettingsTab) onDroppedCSVChanged(label string) {
+	for _, option := range droppedCSVOptions {
+		if option.label == label {
+			t.app.GetSettings().DroppedCSV = option.action
+			t.saveSettings()
+			t.app.logger.Info("Dropped CSV handling changed", "action", option.action)
+			return
+		}
+	}
+}
+
+// onAutosaveToggled обработчик переключения автосохранения профиля
+// При отключении отложенная запись отменяется, а прежнее автосохранение удаляется
+func (t *SettingsTab) onAutosaveToggled(checked bool) {
+	t.app.GetSettings().AutosaveProfile = checked
+	t.saveSettings()
+	if !checked {
+		t.app.discardAutosave()
+	}
+	t.app.logger.Info("Profile autosave toggled", "enabled", checked)
+}
+
+// onLanguageChanged обработчик выбора языка сообщений об ошибках, применяется сразу
+func (t *SettingsTab) onLanguageChanged(label string) {
+	for _, option := range languageOptions {
+		if option.label == label {
+			t.app.GetSettings().Language = option.lang
+			t.saveSettings()
+			t.app.logger.Info("Error message language changed", "language", option.lang)
+			return
+		}
+	}
+}
+
+// onHeaderRowChanged обработчик выбора строки заголовков для листов нового базового файла
+// Уже загруженные листы не изменяются
+func (t *SettingsTab) onHeaderRowChanged(label string) {
+	for row := 1; row <= maxDefaultHeaderRow; row++ {
+		if headerRowLabel(row) == label {
+			t.app.GetSettings().DefaultHeaderRow = row
+			t.saveSettings()
+			t.app.logger.Info("Default header row changed", "header_row", row)
+			return
+		}
+	}
+}
+
+// refreshOzonBrands показывает текущий список брендов шаблона Ozon
+func (t *SettingsTab) refreshOzonBrands() {
+	brands := config.NormalizeBrands(t.app.GetSettings().OzonBrands)
+	if len(brands) == 0 {
+		t.ozonBrandsLabel.SetText("Бренды для фильтра листа «Шаблон»: не заданы, строки не фильтруются")
+		return
+	}
+	t.ozonBrandsLabel.SetText("Бренды для фильтра листа «Шаблон»: " + strings.Join(brands, ", "))
+}
+
+// onEditOzonBrands открывает редактор списка брендов шаблона Ozon
+// Список очищается от пробелов и повторов при сохранении; если шаблон уже применен к
+// загруженным листам, предлагается применить новый список к их фильтру
+func (t *SettingsTab) onEditOzonBrands() {
+	brands := config.NormalizeBrands(t.app.GetSettings().OzonBrands)
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int { return len(brands) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil, widget.NewButton("Удалить", nil), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			row := item.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(brands[id])
+			row.Objects[1].(*widget.Button).OnTapped = func() {
+				brands = append(brands[:id:id], brands[id+1:]...)
+				list.Refresh()
+			}
+		},
+	)
+
+	newBrand := widget.NewEntry()
+	newBrand.SetPlaceHolder("Новый бренд")
+	addBrand := func() {
+		brands = config.NormalizeBrands(append(brands, newBrand.Text))
+		newBrand.SetText("")
+		list.Refresh()
+	}
+	newBrand.OnSubmitted = func(string) { addBrand() }
+
+	hint := widget.NewLabel("Строки листа «Шаблон» остаются в результате, если бренд совпадает с одним из списка " +
+		"(без учета регистра). Пустой список отключает фильтр.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	content := container.NewBorder(
+		container.NewVBox(hint, container.NewBorder(nil, nil, nil, widget.NewButton("Добавить", addBrand), newBrand)),
+		nil, nil, nil,
+		list,
+	)
+
+	editor := dialog.NewCustomConfirm("Бренды шаблона Ozon", "Сохранить", "Отмена", content, func(save bool) {
+		if !save {
+			return
+		}
+		// Незавершенный ввод тоже сохраняется
+		brands = config.NormalizeBrands(append(brands, newBrand.Text))
+		settings := t.app.GetSettings()
+		if slices.Equal(brands, config.NormalizeBrands(settings.OzonBrands)) {
+			return
+		}
+		settings.OzonBrands = brands
+		t.saveSettings()
+		t.refreshOzonBrands()
+		t.app.logger.Info("Ozon brand list changed", "brands", brands)
+
+		if t.app.baseFileTab != nil && t.app.baseFileTab.hasOzonTemplateSheets() {
+			t.app.ShowConfirm("Бренды шаблона Ozon",
+				"Применить новый список брендов к фильтру загруженного листа «Шаблон»?\n"+
+					"Остальные настройки листов не изменятся.",
+				func(apply bool) {
+					if apply {
+						changed := t.app.baseFileTab.applyOzonBrands()
+						t.app.ShowInfo("Бренды шаблона Ozon", fmt.Sprintf("Фильтр обновлен для листов: %d", changed))
+					}
+				})
+		}
+	}, t.app.window)
+	editor.Resize(fyne.NewSize(450, 420))
+	editor.Show()
+}
+
+// onShowLogViewer открывает окно просмотра журнала
+func (t *SettingsTab) onShowLogViewer() {
+	NewLogViewer(t.app).Show()
+}
+
+// saveSettings сохраняет настройки приложения
+func (t *SettingsTab) saveSettings() {
+	if err := t.app.configManager.SaveSettings(t.app.GetSettings()); err != nil {
+		t.app.logger.Error("не удалось сохранить настройки", "error", err)
+	}
+}
+
+// intervalLabel возвращает подпись для интервала проверки в часах
+func intervalLabel(hours int) string {
+	for _, option := range updateIntervalOptions {
+		if option.hours == hours {
+			return option.label
+		}
+	}
+	return fmt.Sprintf("Каждые %d ч", hours)
+}
+
+// memoryLimitLabel возвращает подпись для порога памяти в МБ
+func memoryLimitLabel(limitMB int) string {
+	for _, option := range memoryLimitOptions {
+		if option.limitMB == limitMB {
+			return option.label
+		}
+	}
+	return fmt.Sprintf("%d МБ", limitMB)
+}
+
+// maxFilesLabel возвращает подпись для ограничения количества файлов
+// Отрицательные значения, как и 0, снимают ограничение
+func maxFilesLabel(maxFiles int) string {
+	maxFiles = max(maxFiles, 0)
+	for _, option := range maxFilesOptions {
+		if option.maxFiles == maxFiles {
+			return option.label
+		}
+	}
+	return fmt.Sprintf("%d", maxFiles)
+}
+
+// notificationLabel возвращает подпись для способа уведомления
+// Неизвестные значения отображаются как уведомление диалогом
+func notificationLabel(style string) string {
+	for _, option := range updateNotificationOptions {
+		if option.style == style {
+			return option.label
+		}
+	}
+	return updateNotificationOptions[0].label
+}
+
+// droppedCSVLabel возвращает подпись для обработки перетащенных CSV-файлов
+// Пустое и неизвестные значения отображаются как преобразование
+func droppedCSVLabel(action string) string {
+	for _, option := range droppedCSVOptions {
+		if option.action == action {
+			return option.label
+		}
+	}
+	return droppedCSVOptions[0].label
+}
+
+// logLevelLabel возвращает подпись для уровня журнала
+func logLevelLabel(level slog.Level) string {
+	for _, option := range logLevelOptions {
+		if parsed, err := logger.ParseLevel(option.level); err == nil && parsed == level {
+			return option.label
+		}
+	}
+	return level.String()
+}
+
+// logFormatLabel возвращает подпись для формата журнала
+func logFormatLabel(format string) string {
+	for _, option := range logFormatOptions {
+		if option.format == format {
+			return option.label
+		}
+	}
+	return logFormatOptions[0].label
+}
+
+// headerRowLabel возвращает подпись строки заголовков для новых листов
+func headerRowLabel(row int) string {
+	if row == 1 {
+		return "1 (по умолчанию)"
+	}
+	return strconv.Itoa(row)
+}
+
+// languageLabel возвращает подпись для языка сообщений об ошибках
+func languageLabel(lang string) string {
+	lang = apperrors.NormalizeLanguage(lang)
+	for _, option := range languageOptions {
+		if option.lang == lang {
+			return option.label
+		}
+	}
+	return languageOptions[0].label
+}
+
+// containsString проверяет наличие строки в срезе
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
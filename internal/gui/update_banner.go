@@ -0,0 +1,100 @@
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/DatKorso/Merge-excel/internal/updater"
+)
+
+// UpdateBanner баннер о доступном обновлении вверху главного окна
+// Находится вне вкладок, поэтому остается видимым при переключении между ними
+type UpdateBanner struct {
+	app *App
+
+	// UI элементы
+	container *fyne.Container
+	label     *widget.Label
+
+	// Состояние
+	info *updater.ReleaseInfo
+}
+
+// NewUpdateBanner создает баннер обновления
+func NewUpdateBanner(app *App) *UpdateBanner {
+	return &UpdateBanner{app: app}
+}
+
+// Build создает UI баннера (изначально скрыт)
+func (b *UpdateBanner) Build() fyne.CanvasObject {
+	b.label = widget.NewLabel("")
+
+	detailsBtn := widget.NewButton("Подробнее", func() {
+		b.onDetails()
+	})
+	skipBtn := widget.NewButton("Пропустить", func() {
+		b.onSkip()
+	})
+	closeBtn := widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		b.Hide()
+	})
+	closeBtn.Importance = widget.LowImportance
+
+	background := canvas.NewRectangle(theme.Color(theme.ColorNameSelection))
+	b.container = container.NewStack(
+		background,
+		container.NewBorder(nil, nil, nil,
+			container.NewHBox(detailsBtn, skipBtn, closeBtn),
+			b.label,
+		),
+	)
+	b.container.Hide()
+
+	return b.container
+}
+
+// Show показывает баннер с информацией об обновлении
+// Баннер не перехватывает фокус ввода
+func (b *UpdateBanner) Show(info *updater.ReleaseInfo) {
+	if b.container == nil || info == nil {
+		return
+	}
+
+	b.info = info
+	text := fmt.Sprintf("Доступна версия %s", info.Version)
+	if info.VersionsBehind > 1 {
+		text += fmt.Sprintf(" (%s)", updater.NewVersionsLabel(info.VersionsBehind))
+	}
+	b.label.SetText(text)
+	b.container.Show()
+}
+
+// Hide скрывает баннер
+func (b *UpdateBanner) Hide() {
+	if b.container != nil {
+		b.container.Hide()
+	}
+	b.info = nil
+}
+
+// onDetails открывает полный диалог обновления
+func (b *UpdateBanner) onDetails() {
+	info := b.info
+	b.Hide()
+	if info != nil {
+		b.app.ShowUpdate(info)
+	}
+}
+
+// onSkip скрывает баннер и больше не напоминает об этой версии
+func (b *UpdateBanner) onSkip() {
+	if b.info != nil {
+		b.app.SkipUpdateVersion(b.info.Version)
+	}
+	b.Hide()
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// sinkKind назначение вывода журнала
+type sinkKind int
+
+const (
+	sinkFile sinkKind = iota
+	sinkConsole
+)
+
+// newHandler создает хендлер, записывающий журнал в файл и, если console не nil, в консоль;
+// если mem не nil, записи также сохраняются в памяти для просмотра в приложении.
+// Формат каждого приемника определяется текущим значением Format,
+// скрытие пользовательских путей - SetRedaction
+func newHandler(file, console io.Writer, mem *ringBuffer) slog.Handler {
+	opts := &slog.HandlerOptions{
+		Level:     &level,
+		AddSource: true,
+	}
+
+	handlers := []slog.Handler{newSinkHandler(sinkFile, file, opts)}
+	if console != nil {
+		handlers = append(handlers, newSinkHandler(sinkConsole, console, opts))
+	}
+	if mem != nil {
+		handlers = append(handlers, &memoryHandler{buf: mem})
+	}
+	return &redactHandler{next: &fanoutHandler{handlers: handlers}}
+}
+
+// usesJSON сообщает, пишет ли приемник в формате JSON при формате журнала f
+func usesJSON(sink sinkKind, f string) bool {
+	switch f {
+	case FormatJSON:
+		return true
+	case FormatText:
+		return false
+	default:
+		return sink == sinkFile
+	}
+}
+
+// sinkHandler хендлер одного приемника журнала
+// Держит JSON и текстовый хендлеры над одним writer, чтобы формат можно было менять на лету
+type sinkHandler struct {
+	sink sinkKind
+	json slog.Handler
+	text slog.Handler
+}
+
+// newSinkHandler создает хендлер приемника
+func newSinkHandler(sink sinkKind, w io.Writer, opts *slog.HandlerOptions) *sinkHandler {
+	return &sinkHandler{
+		sink: sink,
+		json: slog.NewJSONHandler(w, opts),
+		text: slog.NewTextHandler(w, opts),
+	}
+}
+
+// current возвращает хендлер для текущего формата журнала
+func (h *sinkHandler) current() slog.Handler {
+	if usesJSON(h.sink, Format()) {
+		return h.json
+	}
+	return h.text
+}
+
+func (h *sinkHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.current().Enabled(ctx, l)
+}
+
+func (h *sinkHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.current().Handle(ctx, r)
+}
+
+func (h *sinkHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sinkHandler{sink: h.sink, json: h.json.WithAttrs(attrs), text: h.text.WithAttrs(attrs)}
+}
+
+func (h *sinkHandler) WithGroup(name string) slog.Handler {
+	return &sinkHandler{sink: h.sink, json: h.json.WithGroup(name), text: h.text.WithGroup(name)}
+}
+
+// fanoutHandler передает каждую запись всем вложенным хендлерам
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+func (h *fanoutHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &fanoutHandler{handlers: handlers}
+}
+
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &fanoutHandler{handlers: handlers}
+}
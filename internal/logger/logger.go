@@ -6,15 +6,50 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
+// LevelEnv переменная окружения с уровнем журнала (имеет приоритет над настройками)
+const LevelEnv = "EXCEL_MERGER_LOG_LEVEL"
+
+// FormatEnv переменная окружения с форматом журнала (имеет приоритет над настройками)
+const FormatEnv = "EXCEL_MERGER_LOG_FORMAT"
+
+// Форматы журнала
+const (
+	FormatJSON = "json" // JSON в файле и в консоли
+	FormatText = "text" // Текст в файле и в консоли
+	FormatBoth = "both" // JSON в файле, текст в консоли (по умолчанию)
+)
+
+// Источники уровня и формата журнала
+const (
+	LevelSourceDefault  = "default"
+	LevelSourceSettings = "settings"
+	LevelSourceEnv      = "env"
+)
+
+// level текущий уровень журнала; меняется без пересоздания логгера
+var level slog.LevelVar
+
+// format текущий формат журнала; меняется без пересоздания логгера
+var format atomic.Value
+
 // Config конфигурация логгера
 type Config struct {
 	Level      slog.Level
 	LogFile    string
-	MaxSize    int64 // максимальный размер файла в байтах
-	MaxBackups int   // максимальное количество старых лог-файлов
-	Console    bool  // выводить ли в консоль
+	MaxSize    int64  // максимальный размер файла в байтах
+	MaxBackups int    // максимальное количество старых лог-файлов
+	MaxAgeDays int    // срок хранения старых лог-файлов в днях (0 - без ограничения)
+	Console    bool   // выводить ли в консоль
+	Format     string // формат журнала: json, text или both
+	MemorySize int    // количество записей, хранимых в памяти для просмотра (0 - не хранить)
+
+	Redact      bool     // заменять домашний каталог и RedactRoots на "~"
+	RedactRoots []string // дополнительные каталоги для скрытия
 }
 
 // DefaultConfig возвращает конфигурацию по умолчанию
@@ -29,7 +64,10 @@ func DefaultConfig() *Config {
 		LogFile:    filepath.Join(homeDir, ".excel-merger", "logs", "excel-merger.log"),
 		MaxSize:    10 * 1024 * 1024, // 10 MB
 		MaxBackups: 5,
+		MaxAgeDays: 30,
 		Console:    true,
+		Format:     FormatBoth,
+		MemorySize: DefaultMemorySize,
 	}
 }
 
@@ -41,53 +79,148 @@ func InitLogger(cfg *Config) (*slog.Logger, error) {
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
-	// Проверяем размер файла и ротируем при необходимости
-	if info, err := os.Stat(cfg.LogFile); err == nil && info.Size() > cfg.MaxSize {
-		if err := rotateLogFile(cfg); err != nil {
-			return nil, fmt.Errorf("failed to rotate log file: %w", err)
-		}
-	}
-
-	// Открываем файл для записи
-	file, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	// Открываем файл для записи; ротация выполняется при превышении MaxSize,
+	// в том числе во время работы приложения
+	file, err := newRotatingWriter(cfg.LogFile, cfg.MaxSize, cfg.MaxBackups)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %w", err)
+		return nil, err
 	}
 
 	// Настраиваем вывод
-	var writer io.Writer = file
+	var console io.Writer
 	if cfg.Console {
-		writer = io.MultiWriter(file, os.Stdout)
+		console = os.Stdout
+	}
+
+	// Уровень и формат можно менять во время работы через SetLevel и SetFormat
+	level.Set(cfg.Level)
+	if cfg.Format != "" {
+		f, err := ParseFormat(cfg.Format)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		SetFormat(f)
 	}
+	SetRedaction(cfg.Redact, cfg.RedactRoots)
 
-	// Создаем хендлер
-	handler := slog.NewJSONHandler(writer, &slog.HandlerOptions{
-		Level:     cfg.Level,
-		AddSource: true,
-	})
+	// Последние записи хранятся в памяти для просмотра журнала в приложении
+	var mem *ringBuffer
+	if cfg.MemorySize > 0 {
+		memory.resize(cfg.MemorySize)
+		mem = memory
+	}
 
-	logger := slog.New(handler)
+	logger := slog.New(newHandler(file, console, mem))
+	file.onRotateError = func(err error) {
+		// Запись из отдельной горутины: хендлер журнала еще удерживает блокировку
+		go logger.Warn("не удалось выполнить ротацию журнала, запись продолжается в текущий файл", "error", err)
+	}
 	slog.SetDefault(logger)
+	if file.startupRotateErr != nil {
+		logger.Warn("не удалось выполнить ротацию журнала, запись продолжается в текущий файл", "error", file.startupRotateErr)
+	}
+
+	// Удаляем устаревшие файлы журнала; активный файл не затрагивается
+	if cfg.MaxAgeDays > 0 {
+		purged, err := purgeOldLogs(cfg.LogFile, time.Duration(cfg.MaxAgeDays)*24*time.Hour, time.Now())
+		if len(purged) > 0 {
+			logger.Info("удалены устаревшие файлы журнала", "files", purged, "max_age_days", cfg.MaxAgeDays)
+		}
+		if err != nil {
+			logger.Warn("не удалось удалить устаревшие файлы журнала", "error", err)
+		}
+	}
 
 	return logger, nil
 }
 
-// rotateLogFile выполняет ротацию лог-файлов
-func rotateLogFile(cfg *Config) error {
-	// Удаляем самый старый файл, если достигнут лимит
-	if cfg.MaxBackups > 0 {
-		oldestBackup := fmt.Sprintf("%s.%d", cfg.LogFile, cfg.MaxBackups)
-		os.Remove(oldestBackup) // Игнорируем ошибку, если файл не существует
+// SetLevel меняет уровень журнала во время работы приложения
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Level возвращает текущий уровень журнала
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetFormat меняет формат журнала во время работы приложения
+// Неизвестный формат игнорируется
+func SetFormat(f string) {
+	if parsed, err := ParseFormat(f); err == nil {
+		format.Store(parsed)
+	}
+}
+
+// Format возвращает текущий формат журнала
+func Format() string {
+	if f, ok := format.Load().(string); ok {
+		return f
+	}
+	return FormatBoth
+}
+
+// ParseLevel разбирает уровень журнала: debug, info, warn (warning) или error
+// Регистр не учитывается
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("неизвестный уровень журнала %q, используется info", value)
+	}
+}
+
+// ResolveLevel определяет уровень журнала из переменной окружения LevelEnv
+// и значения из настроек; переменная окружения имеет приоритет.
+// Возвращает уровень и его источник. При некорректном значении возвращает
+// уровень Info вместе с ошибкой, которую следует записать как предупреждение
+func ResolveLevel(settingsValue string) (slog.Level, string, error) {
+	return resolve(LevelEnv, settingsValue, ParseLevel, slog.LevelInfo)
+}
+
+// ParseFormat разбирает формат журнала: json, text или both
+// Регистр не учитывается
+func ParseFormat(value string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(value)); f {
+	case FormatJSON, FormatText, FormatBoth:
+		return f, nil
+	default:
+		return FormatBoth, fmt.Errorf("неизвестный формат журнала %q, используется both", value)
 	}
+}
 
-	// Сдвигаем все файлы
-	for i := cfg.MaxBackups - 1; i > 0; i-- {
-		oldPath := fmt.Sprintf("%s.%d", cfg.LogFile, i)
-		newPath := fmt.Sprintf("%s.%d", cfg.LogFile, i+1)
-		os.Rename(oldPath, newPath) // Игнорируем ошибку, если файл не существует
+// ResolveFormat определяет формат журнала из переменной окружения FormatEnv
+// и значения из настроек аналогично ResolveLevel
+func ResolveFormat(settingsValue string) (string, string, error) {
+	return resolve(FormatEnv, settingsValue, ParseFormat, FormatBoth)
+}
+
+// resolve выбирает значение из переменной окружения env или из настроек
+// При некорректном значении возвращает def вместе с ошибкой
+func resolve[T any](env, settingsValue string, parse func(string) (T, error), def T) (T, string, error) {
+	if value := os.Getenv(env); strings.TrimSpace(value) != "" {
+		v, err := parse(value)
+		if err != nil {
+			return def, LevelSourceDefault, fmt.Errorf("%s: %w", env, err)
+		}
+		return v, LevelSourceEnv, nil
+	}
+
+	if strings.TrimSpace(settingsValue) != "" {
+		v, err := parse(settingsValue)
+		if err != nil {
+			return def, LevelSourceDefault, err
+		}
+		return v, LevelSourceSettings, nil
 	}
 
-	// Переименовываем текущий файл
-	backupPath := fmt.Sprintf("%s.1", cfg.LogFile)
-	return os.Rename(cfg.LogFile, backupPath)
+	return def, LevelSourceDefault, nil
 }
@@ -0,0 +1,196 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{" warn ", slog.LevelWarn, false},
+		{"warning", slog.LevelWarn, false},
+		{"Error", slog.LevelError, false},
+		{"trace", slog.LevelInfo, true},
+		{"", slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestResolveLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		settings   string
+		want       slog.Level
+		wantSource string
+		wantErr    bool
+	}{
+		{"Default", "", "", slog.LevelInfo, LevelSourceDefault, false},
+		{"Settings", "", "debug", slog.LevelDebug, LevelSourceSettings, false},
+		{"Env wins over settings", "error", "debug", slog.LevelError, LevelSourceEnv, false},
+		{"Invalid env falls back to info", "verbose", "debug", slog.LevelInfo, LevelSourceDefault, true},
+		{"Invalid settings falls back to info", "", "verbose", slog.LevelInfo, LevelSourceDefault, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(LevelEnv, tt.env)
+
+			got, source, err := ResolveLevel(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || source != tt.wantSource {
+				t.Errorf("ResolveLevel() = %v (%s), want %v (%s)", got, source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestSetLevelSwitchesLive(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LogFile = filepath.Join(t.TempDir(), "test.log")
+	cfg.Console = false
+
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	log, err := InitLogger(cfg)
+	if err != nil {
+		t.Fatalf("InitLogger() error = %v", err)
+	}
+	defer SetLevel(slog.LevelInfo)
+
+	log.Debug("до переключения")
+	SetLevel(slog.LevelDebug)
+	if Level() != slog.LevelDebug {
+		t.Errorf("Level() = %v, want debug", Level())
+	}
+	log.Debug("после переключения")
+
+	data, err := os.ReadFile(cfg.LogFile)
+	if err != nil {
+		t.Fatalf("не удалось прочитать журнал: %v", err)
+	}
+	content := string(data)
+	if strings.Contains(content, "до переключения") {
+		t.Error("debug message must be filtered at info level")
+	}
+	if !strings.Contains(content, "после переключения") {
+		t.Error("debug message must be written after switching to debug level")
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		settings   string
+		want       string
+		wantSource string
+		wantErr    bool
+	}{
+		{"Default", "", "", FormatBoth, LevelSourceDefault, false},
+		{"Settings", "", "Text", FormatText, LevelSourceSettings, false},
+		{"Env wins over settings", "json", "text", FormatJSON, LevelSourceEnv, false},
+		{"Invalid env falls back to both", "xml", "text", FormatBoth, LevelSourceDefault, true},
+		{"Invalid settings falls back to both", "", "xml", FormatBoth, LevelSourceDefault, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(FormatEnv, tt.env)
+
+			got, source, err := ResolveFormat(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || source != tt.wantSource {
+				t.Errorf("ResolveFormat() = %s (%s), want %s (%s)", got, source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestHandlerSinkFormats(t *testing.T) {
+	defer SetFormat(FormatBoth)
+
+	tests := []struct {
+		format      string
+		fileJSON    bool
+		consoleJSON bool
+	}{
+		{FormatBoth, true, false},
+		{FormatJSON, true, true},
+		{FormatText, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			SetFormat(tt.format)
+
+			var file, console bytes.Buffer
+			log := slog.New(newHandler(&file, &console, nil)).With("component", "test")
+			log.Info("пример записи", "rows", 42)
+
+			for _, sink := range []struct {
+				name     string
+				output   string
+				wantJSON bool
+			}{
+				{"file", file.String(), tt.fileJSON},
+				{"console", console.String(), tt.consoleJSON},
+			} {
+				if !strings.Contains(sink.output, "пример записи") {
+					t.Fatalf("%s: запись отсутствует: %q", sink.name, sink.output)
+				}
+
+				var record map[string]any
+				isJSON := json.Unmarshal([]byte(sink.output), &record) == nil
+				if isJSON != sink.wantJSON {
+					t.Errorf("%s: JSON = %v, want %v: %q", sink.name, isJSON, sink.wantJSON, sink.output)
+				}
+				if isJSON && (record["rows"] != float64(42) || record["component"] != "test") {
+					t.Errorf("%s: unexpected attributes %v", sink.name, record)
+				}
+				if !isJSON && !strings.Contains(sink.output, "level=INFO") {
+					t.Errorf("%s: expected text record, got %q", sink.name, sink.output)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerWithoutConsole(t *testing.T) {
+	var file bytes.Buffer
+	log := slog.New(newHandler(&file, nil, nil))
+	log.Debug("отфильтровано")
+	log.Warn("записано")
+
+	if strings.Contains(file.String(), "отфильтровано") {
+		t.Error("debug message must be filtered at info level")
+	}
+	if !strings.Contains(file.String(), "записано") {
+		t.Errorf("warn message missing: %q", file.String())
+	}
+}
@@ -0,0 +1,230 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMemorySize количество последних записей журнала, хранимых в памяти
+const DefaultMemorySize = 1000
+
+// Ограничения размера одной записи в памяти
+const (
+	maxMemoryValueLen = 1024 // максимальная длина сообщения и значения атрибута в байтах
+	maxMemoryAttrs    = 32   // максимальное количество атрибутов записи
+)
+
+// Record запись журнала, сохраненная в памяти для просмотра в приложении
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   []Attr
+}
+
+// Attr атрибут записи; ключи вложенных групп записываются через точку
+type Attr struct {
+	Key   string
+	Value string
+}
+
+// String форматирует запись в одну строку
+func (r Record) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %-5s %s", r.Time.Format("15:04:05"), r.Level, r.Message)
+	for _, a := range r.Attrs {
+		fmt.Fprintf(&b, " %s=%s", a.Key, a.Value)
+	}
+	return b.String()
+}
+
+// memory последние записи журнала; заполняется после InitLogger
+var memory = newRingBuffer(DefaultMemorySize)
+
+// Snapshot возвращает последние записи журнала, от старых к новым
+func Snapshot() []Record {
+	return memory.snapshot()
+}
+
+// Subscribe подписывает на новые записи журнала
+// Канал вмещает buffer записей; если читатель не успевает, записи для него
+// пропускаются, а не задерживают журнал. Функция отписки закрывает канал
+func Subscribe(buffer int) (<-chan Record, func()) {
+	return memory.subscribe(buffer)
+}
+
+// ringBuffer хранит последние записи журнала и рассылает новые подписчикам
+type ringBuffer struct {
+	mu          sync.Mutex
+	records     []Record
+	next        int  // индекс для следующей записи
+	full        bool // буфер заполнен и перезаписывается по кругу
+	subscribers map[int]chan Record
+	nextID      int
+}
+
+// newRingBuffer создает буфер на size записей
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{
+		records:     make([]Record, max(size, 1)),
+		subscribers: make(map[int]chan Record),
+	}
+}
+
+// resize меняет вместимость буфера, сохраняя последние записи
+func (b *ringBuffer) resize(size int) {
+	size = max(size, 1)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if size == len(b.records) {
+		return
+	}
+	records := b.snapshotLocked()
+	if len(records) > size {
+		records = records[len(records)-size:]
+	}
+	b.records = make([]Record, size)
+	copy(b.records, records)
+	b.next = len(records) % size
+	b.full = len(records) == size
+}
+
+// add сохраняет запись и рассылает ее подписчикам
+func (b *ringBuffer) add(r Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.records[b.next] = r
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- r:
+		default: // Читатель не успевает: запись для него пропускается
+		}
+	}
+}
+
+// snapshot возвращает копию записей от старых к новым
+func (b *ringBuffer) snapshot() []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.snapshotLocked()
+}
+
+func (b *ringBuffer) snapshotLocked() []Record {
+	if !b.full {
+		return append([]Record(nil), b.records[:b.next]...)
+	}
+	records := make([]Record, 0, len(b.records))
+	records = append(records, b.records[b.next:]...)
+	return append(records, b.records[:b.next]...)
+}
+
+// subscribe регистрирует подписчика с каналом на buffer записей
+func (b *ringBuffer) subscribe(buffer int) (<-chan Record, func()) {
+	ch := make(chan Record, max(buffer, 1))
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = ch
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// memoryHandler сохраняет записи в ringBuffer с сохранением атрибутов
+type memoryHandler struct {
+	buf    *ringBuffer
+	attrs  []Attr // атрибуты из WithAttrs
+	prefix string // префикс ключей открытых групп, например "merge."
+}
+
+func (h *memoryHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= level.Level()
+}
+
+func (h *memoryHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make([]Attr, 0, min(len(h.attrs)+r.NumAttrs(), maxMemoryAttrs))
+	attrs = append(attrs, h.attrs[:min(len(h.attrs), maxMemoryAttrs)]...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendMemoryAttr(attrs, h.prefix, a)
+		return len(attrs) < maxMemoryAttrs
+	})
+
+	h.buf.add(Record{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: truncateValue(r.Message),
+		Attrs:   attrs,
+	})
+	return nil
+}
+
+func (h *memoryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	merged := append([]Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		merged = appendMemoryAttr(merged, h.prefix, a)
+	}
+	return &memoryHandler{buf: h.buf, attrs: merged, prefix: h.prefix}
+}
+
+func (h *memoryHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &memoryHandler{buf: h.buf, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// appendMemoryAttr добавляет атрибут, раскрывая группы в ключи через точку
+func appendMemoryAttr(attrs []Attr, prefix string, a slog.Attr) []Attr {
+	if len(attrs) >= maxMemoryAttrs {
+		return attrs
+	}
+
+	value := a.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, attr := range value.Group() {
+			attrs = appendMemoryAttr(attrs, prefix, attr)
+		}
+		return attrs
+	}
+	if a.Key == "" {
+		return attrs
+	}
+	return append(attrs, Attr{Key: truncateValue(prefix + a.Key), Value: truncateValue(value.String())})
+}
+
+// truncateValue обрезает строку до maxMemoryValueLen байт, не разрывая символы
+func truncateValue(s string) string {
+	if len(s) <= maxMemoryValueLen {
+		return s
+	}
+	cut := maxMemoryValueLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestRingBufferKeepsLastRecords тестирует перезапись старых записей по кругу
+func TestRingBufferKeepsLastRecords(t *testing.T) {
+	buf := newRingBuffer(3)
+	log := slog.New(&memoryHandler{buf: buf})
+	for i := 0; i < 5; i++ {
+		log.Info(fmt.Sprintf("record %d", i))
+	}
+
+	var messages []string
+	for _, r := range buf.snapshot() {
+		messages = append(messages, r.Message)
+	}
+	if got := strings.Join(messages, ","); got != "record 2,record 3,record 4" {
+		t.Errorf("snapshot = %s", got)
+	}
+
+	// Уменьшение вместимости сохраняет самые новые записи
+	buf.resize(2)
+	log.Info("record 5")
+	messages = nil
+	for _, r := range buf.snapshot() {
+		messages = append(messages, r.Message)
+	}
+	if got := strings.Join(messages, ","); got != "record 4,record 5" {
+		t.Errorf("после resize snapshot = %s", got)
+	}
+}
+
+// TestMemoryHandlerPreservesAttrs тестирует сохранение атрибутов и групп
+func TestMemoryHandlerPreservesAttrs(t *testing.T) {
+	buf := newRingBuffer(10)
+	log := slog.New(&memoryHandler{buf: buf}).With("component", "merge").WithGroup("sheet")
+	log.Warn("лист пропущен", "name", "Шаблон", slog.Group("stats", "rows", 10))
+
+	records := buf.snapshot()
+	if len(records) != 1 {
+		t.Fatalf("записей = %d, ожидалась 1", len(records))
+	}
+	r := records[0]
+	if r.Level != slog.LevelWarn || r.Message != "лист пропущен" {
+		t.Errorf("запись = %+v", r)
+	}
+
+	var attrs []string
+	for _, a := range r.Attrs {
+		attrs = append(attrs, a.Key+"="+a.Value)
+	}
+	if got := strings.Join(attrs, " "); got != "component=merge sheet.name=Шаблон sheet.stats.rows=10" {
+		t.Errorf("атрибуты = %s", got)
+	}
+}
+
+// TestMemoryHandlerBoundsRecordSize тестирует ограничение размера записи
+func TestMemoryHandlerBoundsRecordSize(t *testing.T) {
+	buf := newRingBuffer(10)
+	log := slog.New(&memoryHandler{buf: buf})
+
+	args := []any{"huge", strings.Repeat("я", maxMemoryValueLen)}
+	for i := 0; i < maxMemoryAttrs*2; i++ {
+		args = append(args, fmt.Sprintf("key%d", i), i)
+	}
+	log.Info(strings.Repeat("x", maxMemoryValueLen*4), args...)
+
+	r := buf.snapshot()[0]
+	if len(r.Message) > maxMemoryValueLen+len("…") {
+		t.Errorf("длина сообщения = %d", len(r.Message))
+	}
+	if len(r.Attrs) != maxMemoryAttrs {
+		t.Errorf("атрибутов = %d, ожидалось %d", len(r.Attrs), maxMemoryAttrs)
+	}
+	huge := r.Attrs[0].Value
+	if len(huge) > maxMemoryValueLen+len("…") || !strings.HasSuffix(huge, "…") {
+		t.Errorf("длинное значение не обрезано: %d байт", len(huge))
+	}
+	if !strings.HasPrefix(huge, "яяя") || strings.ContainsRune(huge, '�') {
+		t.Error("обрезка не должна разрывать символы")
+	}
+}
+
+// TestRingBufferConcurrentWritersAndReader тестирует одновременную запись и чтение
+func TestRingBufferConcurrentWritersAndReader(t *testing.T) {
+	const (
+		writers   = 8
+		perWriter = 200
+		size      = 100
+	)
+	buf := newRingBuffer(size)
+	log := slog.New(&memoryHandler{buf: buf})
+
+	records, unsubscribe := buf.subscribe(writers * perWriter)
+	received := make(chan int)
+	go func() {
+		count := 0
+		for range records {
+			count++
+		}
+		received <- count
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				log.Info("record", "writer", w, "i", i)
+			}
+		}(w)
+	}
+
+	// Снимки во время записи всегда ограничены вместимостью
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			if n := len(buf.snapshot()); n > size {
+				t.Fatalf("снимок содержит %d записей при вместимости %d", n, size)
+			}
+		}
+	}
+
+	unsubscribe()
+	unsubscribe() // Повторная отписка безопасна
+
+	select {
+	case count := <-received:
+		if count != writers*perWriter {
+			t.Errorf("подписчик получил %d записей, ожидалось %d", count, writers*perWriter)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("канал подписчика не закрыт после отписки")
+	}
+
+	if n := len(buf.snapshot()); n != size {
+		t.Errorf("в буфере %d записей, ожидалось %d", n, size)
+	}
+
+	// Запись после отписки не блокируется и не паникует
+	log.Info("after unsubscribe")
+}
+
+// TestSubscribeDropsForSlowReader тестирует, что медленный читатель не задерживает журнал
+func TestSubscribeDropsForSlowReader(t *testing.T) {
+	buf := newRingBuffer(10)
+	log := slog.New(&memoryHandler{buf: buf})
+	records, unsubscribe := buf.subscribe(2)
+	defer unsubscribe()
+
+	for i := 0; i < 5; i++ {
+		log.Info(fmt.Sprintf("record %d", i))
+	}
+
+	if len(records) != 2 {
+		t.Errorf("в канале %d записей, ожидалось 2", len(records))
+	}
+	if first := <-records; first.Message != "record 0" {
+		t.Errorf("первая запись = %q", first.Message)
+	}
+	if n := len(buf.snapshot()); n != 5 {
+		t.Errorf("в буфере %d записей, ожидалось 5", n)
+	}
+}
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// redaction текущие правила скрытия путей; nil - скрытие выключено
+var redaction atomic.Pointer[redactor]
+
+// SetRedaction включает или выключает замену пользовательских путей на "~" в журнале
+// Скрывается домашний каталог пользователя и дополнительные каталоги roots
+func SetRedaction(enabled bool, roots []string) {
+	if !enabled {
+		redaction.Store(nil)
+		return
+	}
+
+	all := append([]string{}, roots...)
+	if home, err := os.UserHomeDir(); err == nil {
+		all = append(all, home)
+	}
+	redaction.Store(newRedactor(all))
+}
+
+// RedactionEnabled сообщает, включено ли скрытие путей
+func RedactionEnabled() bool {
+	return redaction.Load() != nil
+}
+
+// redactor заменяет вхождения каталогов на "~"
+type redactor struct {
+	pattern *regexp.Regexp
+}
+
+// newRedactor создает правила для каталогов roots; пустые значения пропускаются
+// Каталог совпадает без учета регистра и с любым разделителем пути, но только
+// целиком: "/home/ivan" не скрывает часть "/home/ivanova" или "/srv/home/ivan"
+func newRedactor(roots []string) *redactor {
+	seen := make(map[string]bool)
+	var alternatives []string
+	for _, root := range roots {
+		root = strings.TrimRight(strings.TrimSpace(root), `/\`)
+		if root == "" || seen[strings.ToLower(root)] {
+			continue
+		}
+		seen[strings.ToLower(root)] = true
+
+		// Любой разделитель пути: C:\Users\ivan и C:/Users/ivan
+		parts := strings.FieldsFunc(root, func(r rune) bool { return r == '/' || r == '\\' })
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		alternative := strings.Join(parts, `[/\\]+`)
+		if strings.HasPrefix(root, "/") || strings.HasPrefix(root, `\`) {
+			alternative = `[/\\]+` + alternative
+		}
+		alternatives = append(alternatives, alternative)
+	}
+	if len(alternatives) == 0 {
+		return &redactor{}
+	}
+
+	// Более длинные каталоги проверяются первыми
+	sort.Slice(alternatives, func(i, j int) bool { return len(alternatives[i]) > len(alternatives[j]) })
+	boundary := `[^\p{L}\p{N}_.-]`
+	pattern := `(?i)(^|` + boundary + `)(?:` + strings.Join(alternatives, "|") + `)(` + boundary + `|\z)`
+	return &redactor{pattern: regexp.MustCompile(pattern)}
+}
+
+// redact заменяет каталоги в строке на "~"
+func (r *redactor) redact(s string) string {
+	if r == nil || r.pattern == nil {
+		return s
+	}
+	// Соседние вхождения делят символ-границу, поэтому замена повторяется до стабилизации
+	for {
+		redacted := r.pattern.ReplaceAllString(s, "${1}~${2}")
+		if redacted == s {
+			return s
+		}
+		s = redacted
+	}
+}
+
+// redactAttr скрывает пути в значении атрибута, включая вложенные группы
+func (r *redactor) redactAttr(a slog.Attr) slog.Attr {
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, attr := range group {
+			attrs[i] = r.redactAttr(attr)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case error:
+			return slog.String(a.Key, r.redact(v.Error()))
+		case []string:
+			values := make([]string, len(v))
+			for i, s := range v {
+				values[i] = r.redact(s)
+			}
+			return slog.Any(a.Key, values)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: value}
+}
+
+// redactAttrs скрывает пути в списке атрибутов
+func (r *redactor) redactAttrs(attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = r.redactAttr(attr)
+	}
+	return redacted
+}
+
+// NewRedactHandler оборачивает next скрытием путей по правилам SetRedaction
+// Нужен для дополнительных приемников записей, минующих основной журнал (например, журнала объединения)
+func NewRedactHandler(next slog.Handler) slog.Handler {
+	return &redactHandler{next: next}
+}
+
+// redactHandler скрывает пользовательские пути в тексте и атрибутах записей
+// Правила берутся из SetRedaction в момент записи; при выключенном скрытии
+// записи передаются без изменений
+type redactHandler struct {
+	next slog.Handler
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	rules := redaction.Load()
+	if rules == nil {
+		return h.next.Handle(ctx, r)
+	}
+
+	redacted := slog.NewRecord(r.Time, r.Level, rules.redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(rules.redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs скрывает пути в атрибутах по правилам, действующим в момент вызова
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if rules := redaction.Load(); rules != nil {
+		attrs = rules.redactAttrs(attrs)
+	}
+	return &redactHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
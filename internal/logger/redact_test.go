@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestRedactPaths тестирует замену каталогов в строке
+func TestRedactPaths(t *testing.T) {
+	r := newRedactor([]string{"/home/ivanov", `C:\Users\Петров\`})
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/home/ivanov/Documents/base.xlsx", "~/Documents/base.xlsx"},
+		{"открыт /home/ivanov", "открыт ~"},
+		{"'/home/ivanov', '/home/ivanov/a'", "'~', '~/a'"},
+		{"/HOME/Ivanov/a.xlsx", "~/a.xlsx"},
+		{"/home/ivanova/a.xlsx", "/home/ivanova/a.xlsx"},
+		{"/srv/home/ivanov/a.xlsx", "/srv/home/ivanov/a.xlsx"},
+		{`C:\Users\Петров\Desktop\отчет.xlsx`, `~\Desktop\отчет.xlsx`},
+		{"c:/users/петров/Desktop", "~/Desktop"},
+		{`C:\Users\Петрова\Desktop`, `C:\Users\Петрова\Desktop`},
+		{"/home/ivanov /home/ivanov /home/ivanov", "~ ~ ~"},
+		{"без путей", "без путей"},
+	}
+
+	for _, tt := range tests {
+		if got := r.redact(tt.input); got != tt.want {
+			t.Errorf("redact(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestRedactHandlerAttributeShapes тестирует скрытие путей в разных видах атрибутов
+func TestRedactHandlerAttributeShapes(t *testing.T) {
+	t.Setenv("HOME", "/home/ivanov")
+	t.Setenv("USERPROFILE", "/home/ivanov")
+	defer SetRedaction(false, nil)
+	defer SetFormat(FormatBoth)
+	SetFormat(FormatJSON)
+
+	tests := []struct {
+		name    string
+		enabled bool
+	}{
+		{"включено", true},
+		{"выключено", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetRedaction(tt.enabled, []string{"/mnt/share/ivanov"})
+
+			var buf bytes.Buffer
+			log := slog.New(newHandler(&buf, nil, nil)).With("base_file", "/home/ivanov/base.xlsx")
+			log.WithGroup("merge").Info("открыт файл /home/ivanov/Загрузки/товары.xlsx",
+				"path", "/mnt/share/ivanov/отчет.xlsx",
+				"error", errors.New("open /home/ivanov/x.xlsx: permission denied"),
+				"files", []string{"/home/ivanov/a.xlsx", "/tmp/b.xlsx"},
+				slog.Group("output", "dir", "/home/ivanov/out", slog.Group("backup", "dir", "/home/ivanov/bak")),
+				"rows", 10,
+			)
+
+			output := buf.String()
+			var record map[string]any
+			if err := json.Unmarshal([]byte(output), &record); err != nil {
+				t.Fatalf("некорректный JSON %q: %v", output, err)
+			}
+
+			if strings.Contains(output, "ivanov") != !tt.enabled {
+				t.Errorf("пути скрыты = %v, ожидалось %v: %s", !strings.Contains(output, "ivanov"), tt.enabled, output)
+			}
+			if !tt.enabled {
+				return
+			}
+
+			for _, want := range []string{
+				`"msg":"открыт файл ~/Загрузки/товары.xlsx"`,
+				`"base_file":"~/base.xlsx"`,
+				`"path":"~/отчет.xlsx"`,
+				`"error":"open ~/x.xlsx: permission denied"`,
+				`"files":["~/a.xlsx","/tmp/b.xlsx"]`,
+				`"output":{"dir":"~/out","backup":{"dir":"~/bak"}}`,
+				`"rows":10`,
+			} {
+				if !strings.Contains(output, want) {
+					t.Errorf("запись не содержит %s: %s", want, output)
+				}
+			}
+		})
+	}
+}
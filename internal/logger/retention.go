@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// logDatePattern дата в имени файла журнала: 2025-01-31 или 20250131
+var logDatePattern = regexp.MustCompile(`(\d{4})-?(\d{2})-?(\d{2})`)
+
+// purgeOldLogs удаляет файлы журнала старше maxAge из каталога активного файла
+// Рассматриваются файлы, в имени которых есть ".log" (резервные копии, временные
+// файлы ротации, журналы прежних версий); активный файл и подкаталоги не затрагиваются.
+// Возвращает имена удаленных файлов
+func purgeOldLogs(activeFile string, maxAge time.Duration, now time.Time) ([]string, error) {
+	dir := filepath.Dir(activeFile)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log directory: %w", err)
+	}
+
+	activeName := filepath.Base(activeFile)
+	threshold := now.Add(-maxAge)
+
+	var purged []string
+	var errs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == activeName || !strings.Contains(name, ".log") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if !logFileTime(name, info).Before(threshold) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		purged = append(purged, name)
+	}
+
+	sort.Strings(purged)
+	if len(errs) > 0 {
+		return purged, fmt.Errorf("failed to remove old log files: %s", strings.Join(errs, "; "))
+	}
+	return purged, nil
+}
+
+// logFileTime определяет время файла журнала по дате в имени,
+// а если ее нет или она некорректна - по времени изменения файла
+func logFileTime(name string, info os.FileInfo) time.Time {
+	if match := logDatePattern.FindStringSubmatch(name); match != nil {
+		date := match[1] + "-" + match[2] + "-" + match[3]
+		if t, err := time.ParseInLocation("2006-01-02", date, time.Local); err == nil {
+			return t
+		}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPurgeOldLogs тестирует удаление устаревших файлов журнала
+func TestPurgeOldLogs(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.Local)
+	old := now.AddDate(0, 0, -45)
+	recent := now.AddDate(0, 0, -3)
+
+	files := []struct {
+		name    string
+		modTime time.Time
+	}{
+		{"excel-merger.log", old},               // Активный файл не удаляется даже если старый
+		{"excel-merger.log.1.gz", recent},       // Свежая копия
+		{"excel-merger.log.2.gz", old},          // Старая копия по времени изменения
+		{"excel-merger.log.3", old},             // Несжатая копия прежней версии
+		{"excel-merger-2025-04-01.log", recent}, // Дата в имени важнее времени изменения
+		{"excel-merger-20250625.log", old},      // Свежая дата в имени
+		{"excel-merger.log.20251399.gz", old},   // Некорректная дата - по времени изменения
+		{"excel-merger.log.rotating.gz", old},   // Остаток прерванной ротации
+		{"notes.txt", old},                      // Не файл журнала
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, []byte("запись"), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, f.modTime, f.modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "old.log.d"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := purgeOldLogs(filepath.Join(dir, "excel-merger.log"), 30*24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("purgeOldLogs() error = %v", err)
+	}
+
+	want := []string{
+		"excel-merger-2025-04-01.log",
+		"excel-merger.log.2.gz",
+		"excel-merger.log.20251399.gz",
+		"excel-merger.log.3",
+		"excel-merger.log.rotating.gz",
+	}
+	if strings.Join(purged, ",") != strings.Join(want, ",") {
+		t.Errorf("удалены %v, ожидалось %v", purged, want)
+	}
+
+	remaining, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range remaining {
+		names = append(names, entry.Name())
+	}
+	wantRemaining := "excel-merger-20250625.log,excel-merger.log,excel-merger.log.1.gz,notes.txt,old.log.d"
+	if strings.Join(names, ",") != wantRemaining {
+		t.Errorf("осталось %v, ожидалось %s", names, wantRemaining)
+	}
+}
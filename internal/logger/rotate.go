@@ -0,0 +1,238 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// rotatingWriter пишет журнал в файл и выполняет ротацию при превышении размера
+// Размер отслеживается счетчиком в памяти, поэтому проверка при записи дешевая.
+// Резервные копии сжимаются gzip: excel-merger.log.1.gz, excel-merger.log.2.gz и т.д.
+type rotatingWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+
+	// onRotateError вызывается при неудачной ротации; запись продолжается в текущий файл
+	onRotateError func(err error)
+
+	// startupRotateErr ошибка ротации при открытии; запись при этом продолжается в текущий файл
+	startupRotateErr error
+}
+
+// newRotatingWriter открывает файл журнала; слишком большой файл сразу ротируется
+// Неудачная ротация не мешает запуску: ошибка сохраняется в startupRotateErr,
+// а запись продолжается в текущий файл. Ошибка возвращается, только если файл не открыть
+func newRotatingWriter(path string, maxSize int64, maxBackups int) (*rotatingWriter, error) {
+	w := &rotatingWriter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	if w.maxSize > 0 && w.size > w.maxSize {
+		if err := w.rotate(); err != nil {
+			w.startupRotateErr = fmt.Errorf("failed to rotate log file: %w", err)
+			// Следующая попытка ротации - после еще maxSize байт
+			w.size = 0
+		}
+		if w.file == nil {
+			return nil, w.startupRotateErr
+		}
+	}
+
+	return w, nil
+}
+
+// Write записывает данные, предварительно выполняя ротацию, если файл переполнится
+// Запись, вызвавшая ротацию, попадает в новый файл целиком
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+
+	var rotateErr error
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			rotateErr = err
+			// Продолжаем текущий файл; следующая попытка - после еще maxSize байт
+			w.size = 0
+		}
+	}
+
+	// Файл мог остаться закрытым после неудачной ротации: пробуем открыть его снова
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			w.mu.Unlock()
+			return 0, fmt.Errorf("log file is not open: %w", err)
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	w.mu.Unlock()
+
+	if rotateErr != nil && w.onRotateError != nil {
+		w.onRotateError(rotateErr)
+	}
+
+	return n, err
+}
+
+// Close закрывает файл журнала
+func (w *rotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open открывает файл журнала на дозапись и запоминает его размер
+func (w *rotatingWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate переносит текущий файл в сжатую резервную копию и открывает новый файл
+// При ошибке архивации текущий файл открывается заново и запись продолжается в него.
+// Если файл не удалось открыть, w.file остается nil и Write повторит открытие
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	w.file = nil
+
+	archiveErr := w.archive()
+	if err := w.open(); err != nil {
+		return err
+	}
+	return archiveErr
+}
+
+// archive сжимает текущий файл в резервную копию .1.gz, сдвигая старые копии
+// Копии сверх maxBackups удаляются; при maxBackups = 0 файл просто удаляется
+func (w *rotatingWriter) archive() error {
+	if w.maxBackups <= 0 {
+		w.pruneLegacyBackups()
+		return os.Remove(w.path)
+	}
+
+	// Сначала сжимаем во временный файл, чтобы при ошибке не потерять журнал
+	tmpPath := w.path + ".rotating.gz"
+	if err := compressFile(w.path, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+
+	os.Remove(w.backupPath(w.maxBackups)) // Игнорируем ошибку, если файл не существует
+	for i := w.maxBackups - 1; i > 0; i-- {
+		os.Rename(w.backupPath(i), w.backupPath(i+1)) // Игнорируем ошибку, если файл не существует
+	}
+
+	if err := os.Rename(tmpPath, w.backupPath(1)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to store log backup: %w", err)
+	}
+
+	w.pruneLegacyBackups()
+	return os.Remove(w.path)
+}
+
+// pruneLegacyBackups удаляет несжатые копии прежнего формата (excel-merger.log.1, .2 и т.д.)
+// Вместе со сжатыми копиями их остается не больше maxBackups; сохраняются самые новые
+func (w *rotatingWriter) pruneLegacyBackups() {
+	entries, err := os.ReadDir(filepath.Dir(w.path))
+	if err != nil {
+		return
+	}
+
+	prefix := filepath.Base(w.path) + "."
+	var legacy []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(name, prefix)); err == nil && n > 0 {
+			legacy = append(legacy, n)
+		}
+	}
+	sort.Ints(legacy)
+
+	keep := w.maxBackups
+	for i := 1; i <= w.maxBackups; i++ {
+		if _, err := os.Stat(w.backupPath(i)); err == nil {
+			keep--
+		}
+	}
+	for i, n := range legacy {
+		if i >= keep {
+			os.Remove(w.legacyBackupPath(n)) // Игнорируем ошибку: попробуем при следующей ротации
+		}
+	}
+}
+
+// backupPath возвращает путь к резервной копии с номером n
+func (w *rotatingWriter) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d.gz", w.path, n)
+}
+
+// legacyBackupPath возвращает путь к несжатой резервной копии прежнего формата с номером n
+func (w *rotatingWriter) legacyBackupPath(n int) string {
+	return fmt.Sprintf("%s.%d", w.path, n)
+}
+
+// compressFile сжимает src в dst с помощью gzip
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		gz.Close()
+		out.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,255 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// readBackup возвращает распакованное содержимое резервной копии журнала
+func readBackup(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("резервная копия %s не найдена: %v", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("%s не сжата gzip: %v", filepath.Base(path), err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("не удалось распаковать %s: %v", filepath.Base(path), err)
+	}
+	return string(data)
+}
+
+// writeRecords записывает count строк журнала, начиная с номера start
+func writeRecords(t *testing.T, w io.Writer, start, count int) {
+	t.Helper()
+	for i := start; i < start+count; i++ {
+		if _, err := fmt.Fprintf(w, "record %02d %s\n", i, strings.Repeat("x", 20)); err != nil {
+			t.Fatalf("запись %d не удалась: %v", i, err)
+		}
+	}
+}
+
+// TestRotatingWriterRotatesDuringSession тестирует ротацию во время работы без потери записей
+func TestRotatingWriterRotatesDuringSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+
+	// Каждая запись - 31 байт, в файл помещается 3 записи
+	w, err := newRotatingWriter(path, 100, 2)
+	if err != nil {
+		t.Fatalf("newRotatingWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	writeRecords(t, w, 0, 4) // Первая ротация
+	writeRecords(t, w, 4, 3) // Вторая ротация
+
+	if _, err := os.Stat(path + ".3.gz"); !os.IsNotExist(err) {
+		t.Error("копий не должно быть больше MaxBackups")
+	}
+
+	backup1 := readBackup(t, path+".1.gz")
+	backup2 := readBackup(t, path+".2.gz")
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := backup2 + backup1 + string(current)
+	for i := 0; i < 7; i++ {
+		if strings.Count(all, fmt.Sprintf("record %02d ", i)) != 1 {
+			t.Errorf("запись %d потеряна или продублирована", i)
+		}
+	}
+	if !strings.HasPrefix(backup2, "record 00") || !strings.HasPrefix(backup1, "record 03") || !strings.HasPrefix(string(current), "record 06") {
+		t.Errorf("неожиданное распределение записей:\n.2.gz: %q\n.1.gz: %q\nтекущий: %q", backup2, backup1, current)
+	}
+
+	// Третья ротация вытесняет самую старую копию
+	writeRecords(t, w, 7, 3)
+	if got := readBackup(t, path+".2.gz"); !strings.HasPrefix(got, "record 03") {
+		t.Errorf("самая старая копия должна быть удалена, .2.gz = %q", got)
+	}
+	if _, err := os.Stat(path + ".3.gz"); !os.IsNotExist(err) {
+		t.Error("копий не должно быть больше MaxBackups")
+	}
+}
+
+// TestRotatingWriterRotatesOversizedFileOnOpen тестирует ротацию при запуске
+func TestRotatingWriterRotatesOversizedFileOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte(strings.Repeat("old\n", 50)), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := newRotatingWriter(path, 100, 2)
+	if err != nil {
+		t.Fatalf("newRotatingWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	if got := readBackup(t, path+".1.gz"); got != strings.Repeat("old\n", 50) {
+		t.Errorf("неожиданное содержимое копии: %q", got)
+	}
+	if w.size != 0 {
+		t.Errorf("после ротации размер = %d, ожидался 0", w.size)
+	}
+}
+
+// TestRotatingWriterContinuesWhenRotationFails тестирует продолжение записи при ошибке ротации
+func TestRotatingWriterContinuesWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+
+	w, err := newRotatingWriter(path, 100, 2)
+	if err != nil {
+		t.Fatalf("newRotatingWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	var rotateErrs []error
+	w.onRotateError = func(err error) {
+		rotateErrs = append(rotateErrs, err)
+	}
+
+	// Каталог на месте временного файла не дает сжать журнал
+	if err := os.Mkdir(path+".rotating.gz", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeRecords(t, w, 0, 5)
+
+	if len(rotateErrs) != 1 {
+		t.Fatalf("ожидалась одна ошибка ротации, получено %v", rotateErrs)
+	}
+	if _, err := os.Stat(path + ".1.gz"); !os.IsNotExist(err) {
+		t.Error("резервная копия не должна создаваться при ошибке")
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if !strings.Contains(string(current), fmt.Sprintf("record %02d ", i)) {
+			t.Errorf("запись %d потеряна", i)
+		}
+	}
+}
+
+// TestRotatingWriterStartupRotationFailure тестирует запуск при неудачной ротации большого файла
+func TestRotatingWriterStartupRotationFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte(strings.Repeat("old\n", 50)), 0666); err != nil {
+		t.Fatal(err)
+	}
+	// Каталог на месте временного файла не дает сжать журнал
+	if err := os.Mkdir(path+".rotating.gz", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := newRotatingWriter(path, 100, 2)
+	if err != nil {
+		t.Fatalf("newRotatingWriter() error = %v, ожидалась дозапись в текущий файл", err)
+	}
+	defer w.Close()
+
+	if w.startupRotateErr == nil {
+		t.Error("ошибка ротации при запуске должна сохраняться")
+	}
+
+	writeRecords(t, w, 0, 1)
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(current), "old\n") || !strings.Contains(string(current), "record 00 ") {
+		t.Errorf("запись должна продолжаться в текущий файл, получено %q", current)
+	}
+}
+
+// TestRotatingWriterReopensClosedFile тестирует повторное открытие файла,
+// который не удалось открыть после ротации
+func TestRotatingWriterReopensClosedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+
+	w, err := newRotatingWriter(path, 0, 2)
+	if err != nil {
+		t.Fatalf("newRotatingWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	// Так файл остается после ротации, если открыть новый файл не удалось
+	w.file.Close()
+	w.file = nil
+
+	writeRecords(t, w, 0, 1)
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(current), "record 00 ") {
+		t.Errorf("запись после повторного открытия потеряна, получено %q", current)
+	}
+}
+
+// TestRotatingWriterPrunesLegacyBackups тестирует удаление несжатых копий прежнего формата
+func TestRotatingWriterPrunesLegacyBackups(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxBackups int
+		gzBackups  []int
+		legacy     []int
+		wantLegacy []int
+	}{
+		{"копии сверх лимита", 3, nil, []int{1, 2, 3, 4, 5}, []int{1, 2}},
+		{"вместе со сжатыми копиями", 3, []int{1}, []int{1, 2, 3}, []int{1}},
+		{"без резервных копий", 0, nil, []int{1, 2}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.log")
+			for _, n := range tt.gzBackups {
+				if err := os.WriteFile(fmt.Sprintf("%s.%d.gz", path, n), nil, 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, n := range tt.legacy {
+				if err := os.WriteFile(fmt.Sprintf("%s.%d", path, n), []byte("legacy"), 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w, err := newRotatingWriter(path, 100, tt.maxBackups)
+			if err != nil {
+				t.Fatalf("newRotatingWriter() error = %v", err)
+			}
+			defer w.Close()
+
+			writeRecords(t, w, 0, 5)
+
+			var got []int
+			for _, n := range tt.legacy {
+				if _, err := os.Stat(fmt.Sprintf("%s.%d", path, n)); err == nil {
+					got = append(got, n)
+				}
+			}
+			if !slices.Equal(got, tt.wantLegacy) {
+				t.Errorf("оставшиеся копии прежнего формата = %v, ожидалось %v", got, tt.wantLegacy)
+			}
+		})
+	}
+}
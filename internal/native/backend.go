@@ -0,0 +1,157 @@
+package native
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// FileFilter тип файлов в диалоге: описание и расширение без точки
+// Пустое расширение - любые файлы
+type FileFilter struct {
+	Description string
+	Extension   string
+}
+
+// Dialogs диалоги выбора файлов и папок
+// Результат передается в callback; при отмене выбора err удовлетворяет IsCancelled
+type Dialogs interface {
+	// OpenFile выбирает один файл
+	OpenFile(title string, filter FileFilter, callback func(path string, err error))
+	// OpenFiles выбирает один или несколько файлов
+	OpenFiles(title string, filter FileFilter, callback func(paths []string, err error))
+	// SaveFile выбирает путь сохранения с предложенным именем в startDir
+	SaveFile(title, defaultName, startDir string, filter FileFilter, callback func(path string, err error))
+	// PickDirectory выбирает директорию, начиная со startDir
+	PickDirectory(title, startDir string, callback func(dir string, err error))
+}
+
+// Имена реализаций диалогов для журнала
+const (
+	BackendNative = "native"
+	BackendFyne   = "fyne"
+)
+
+// FallbackDialogs использует системные диалоги, а после первой их ошибки (кроме отмены)
+// переключается на резервные до конца работы приложения. Вызов, на котором произошла
+// ошибка, повторяется резервным диалогом
+type FallbackDialogs struct {
+	primary  Dialogs
+	fallback Dialogs
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	failed bool // Основные диалоги недоступны
+}
+
+// NewFallbackDialogs создает диалоги с переключением с primary на fallback
+func NewFallbackDialogs(primary, fallback Dialogs, logger *slog.Logger) *FallbackDialogs {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Info("диалоги выбора файлов", "backend", BackendNative)
+	return &FallbackDialogs{
+		primary:  primary,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+// Backend возвращает имя используемой реализации: BackendNative или BackendFyne
+func (d *FallbackDialogs) Backend() string {
+	if d.usesFallback() {
+		return BackendFyne
+	}
+	return BackendNative
+}
+
+// OpenFile выбирает один файл
+func (d *FallbackDialogs) OpenFile(title string, filter FileFilter, callback func(path string, err error)) {
+	if d.usesFallback() {
+		d.fallback.OpenFile(title, filter, callback)
+		return
+	}
+	d.primary.OpenFile(title, filter, func(path string, err error) {
+		if d.switchOnError(err) {
+			d.fallback.OpenFile(title, filter, callback)
+			return
+		}
+		callback(path, err)
+	})
+}
+
+// OpenFiles выбирает один или несколько файлов
+func (d *FallbackDialogs) OpenFiles(title string, filter FileFilter, callback func(paths []string, err error)) {
+	if d.usesFallback() {
+		d.fallback.OpenFiles(title, filter, callback)
+		return
+	}
+	d.primary.OpenFiles(title, filter, func(paths []string, err error) {
+		// Уже выбранные файлы не теряются: ошибка после них передается как есть
+		if len(paths) == 0 && d.switchOnError(err) {
+			d.fallback.OpenFiles(title, filter, callback)
+			return
+		}
+		callback(paths, err)
+	})
+}
+
+// SaveFile выбирает путь сохранения
+func (d *FallbackDialogs) SaveFile(title, defaultName, startDir string, filter FileFilter, callback func(path string, err error)) {
+	if d.usesFallback() {
+		d.fallback.SaveFile(title, defaultName, startDir, filter, callback)
+		return
+	}
+	d.primary.SaveFile(title, defaultName, startDir, filter, func(path string, err error) {
+		if d.switchOnError(err) {
+			d.fallback.SaveFile(title, defaultName, startDir, filter, callback)
+			return
+		}
+		callback(path, err)
+	})
+}
+
+// PickDirectory выбирает директорию
+func (d *FallbackDialogs) PickDirectory(title, startDir string, callback func(dir string, err error)) {
+	if d.usesFallback() {
+		d.fallback.PickDirectory(title, startDir, callback)
+		return
+	}
+	d.primary.PickDirectory(title, startDir, func(dir string, err error) {
+		if d.switchOnError(err) {
+			d.fallback.PickDirectory(title, startDir, callback)
+			return
+		}
+		callback(dir, err)
+	})
+}
+
+// usesFallback сообщает, что основные диалоги уже признаны недоступными
+func (d *FallbackDialogs) usesFallback() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.failed
+}
+
+// switchOnError запоминает недоступность основных диалогов, если ошибка требует переключения
+func (d *FallbackDialogs) switchOnError(err error) bool {
+	if !shouldFallback(err) {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.failed {
+		d.failed = true
+		d.logger.Warn("системные диалоги недоступны, используются диалоги Fyne",
+			"backend", BackendFyne,
+			"error", err,
+		)
+	}
+	return true
+}
+
+// shouldFallback сообщает, что ошибка системного диалога означает его недоступность
+// Отмена выбора пользователем к переключению не приводит
+func shouldFallback(err error) bool {
+	return err != nil && !IsCancelled(err)
+}
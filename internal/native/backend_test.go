@@ -0,0 +1,122 @@
+package native
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
+)
+
+// fakeDialogs заглушка диалогов: возвращает заданный результат и считает вызовы
+type fakeDialogs struct {
+	path  string
+	paths []string
+	err   error
+	calls int
+}
+
+func (f *fakeDialogs) OpenFile(title string, filter FileFilter, callback func(string, error)) {
+	f.calls++
+	callback(f.path, f.err)
+}
+
+func (f *fakeDialogs) OpenFiles(title string, filter FileFilter, callback func([]string, error)) {
+	f.calls++
+	callback(f.paths, f.err)
+}
+
+func (f *fakeDialogs) SaveFile(title, defaultName, startDir string, filter FileFilter, callback func(string, error)) {
+	f.calls++
+	callback(f.path, f.err)
+}
+
+func (f *fakeDialogs) PickDirectory(title, startDir string, callback func(string, error)) {
+	f.calls++
+	callback(f.path, f.err)
+}
+
+// TestShouldFallback тестирует решение о переключении на резервные диалоги
+func TestShouldFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"без ошибки", nil, false},
+		{"отмена выбора", apperrors.NewCancelledError(), false},
+		{"обернутая отмена", fmt.Errorf("диалог: %w", apperrors.NewCancelledError()), false},
+		{"ошибка диалога", errors.New("gtk init failed"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldFallback(tt.err); got != tt.want {
+				t.Errorf("shouldFallback(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFallbackDialogs тестирует переключение на резервные диалоги после ошибки основных
+func TestFallbackDialogs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	filter := FileFilter{Description: "Excel файлы", Extension: "xlsx"}
+
+	t.Run("отмена не переключает", func(t *testing.T) {
+		primary := &fakeDialogs{err: apperrors.NewCancelledError()}
+		fallback := &fakeDialogs{path: "/fyne/a.xlsx"}
+		dialogs := NewFallbackDialogs(primary, fallback, logger)
+
+		var gotErr error
+		dialogs.OpenFile("Открыть", filter, func(path string, err error) { gotErr = err })
+		if !IsCancelled(gotErr) {
+			t.Errorf("ожидалась отмена, получено %v", gotErr)
+		}
+		if fallback.calls != 0 || dialogs.Backend() != BackendNative {
+			t.Errorf("переключение после отмены: вызовов резервных %d, backend %s", fallback.calls, dialogs.Backend())
+		}
+	})
+
+	t.Run("ошибка переключает и повторяет вызов", func(t *testing.T) {
+		primary := &fakeDialogs{err: errors.New("zenity not found")}
+		fallback := &fakeDialogs{path: "/fyne/out", paths: []string{"/fyne/1.xlsx"}}
+		dialogs := NewFallbackDialogs(primary, fallback, logger)
+
+		var got string
+		var gotErr error
+		dialogs.SaveFile("Сохранить", "out.xlsx", "", filter, func(path string, err error) { got, gotErr = path, err })
+		if got != "/fyne/out" || gotErr != nil {
+			t.Errorf("SaveFile() = %q, %v; ожидался результат резервного диалога", got, gotErr)
+		}
+		if dialogs.Backend() != BackendFyne {
+			t.Errorf("Backend() = %s, want %s", dialogs.Backend(), BackendFyne)
+		}
+
+		// Дальнейшие вызовы сразу идут в резервные диалоги
+		var paths []string
+		dialogs.OpenFiles("Добавить", filter, func(p []string, err error) { paths = p })
+		dialogs.PickDirectory("Папка", "", func(string, error) {})
+		if primary.calls != 1 || fallback.calls != 3 {
+			t.Errorf("вызовов основных %d, резервных %d; ожидалось 1 и 3", primary.calls, fallback.calls)
+		}
+		if fmt.Sprint(paths) != "[/fyne/1.xlsx]" {
+			t.Errorf("OpenFiles() = %v", paths)
+		}
+	})
+
+	t.Run("выбранные файлы не теряются при ошибке", func(t *testing.T) {
+		primary := &fakeDialogs{paths: []string{"/a.xlsx"}, err: errors.New("dialog crashed")}
+		fallback := &fakeDialogs{}
+		dialogs := NewFallbackDialogs(primary, fallback, logger)
+
+		var paths []string
+		var gotErr error
+		dialogs.OpenFiles("Добавить", filter, func(p []string, err error) { paths, gotErr = p, err })
+		if len(paths) != 1 || gotErr == nil || fallback.calls != 0 {
+			t.Errorf("OpenFiles() = %v, %v; вызовов резервных %d", paths, gotErr, fallback.calls)
+		}
+	})
+}
@@ -1,9 +1,13 @@
 package native
 
 import (
-	"path/filepath"
+	"errors"
+	"fmt"
+	"os"
 
 	"github.com/sqweek/dialog"
+
+	apperrors "github.com/DatKorso/Merge-excel/internal/errors"
 )
 
 // FileOpenDialog показывает нативный диалог открытия файла
@@ -24,42 +28,67 @@ func FileOpenDialog(title string, filter string, ext string) (string, error) {
 	return filename, nil
 }
 
-// FileSaveDialog показывает нативный диалог сохранения файла
-// Возвращает путь для сохранения или ошибку
+// FilesOpenDialog показывает нативный диалог выбора нескольких файлов
+// Возвращает пути выбранных файлов; пустой список без ошибки - диалог закрыт без выбора.
+// Если пользователь отменил выбор, возвращается dialog.Cancelled.
+// Где выбор нескольких файлов недоступен, файлы выбираются по одному до отмены
+func FilesOpenDialog(title string, filter string, ext string) ([]string, error) {
+	files, err := filesOpenDialog(title, filter, ext)
+	if errors.Is(err, errMultiSelectUnavailable) {
+		return filesOpenOneByOne(title, filter, ext)
+	}
+	return files, err
+}
+
+// filesOpenOneByOne выбирает файлы повторными диалогами одного файла, пока пользователь не отменит выбор
+// Отмена первого же диалога возвращает dialog.Cancelled
+func filesOpenOneByOne(title string, filter string, ext string) ([]string, error) {
+	var files []string
+	for {
+		dialogTitle := title
+		if len(files) > 0 {
+			dialogTitle = fmt.Sprintf("%s (выбрано: %d, Отмена - завершить выбор)", title, len(files))
+		}
+
+		filename, err := FileOpenDialog(dialogTitle, filter, ext)
+		if errors.Is(err, dialog.Cancelled) {
+			if len(files) == 0 {
+				return nil, dialog.Cancelled
+			}
+			return files, nil
+		}
+		if err != nil {
+			return files, err
+		}
+		files = append(files, filename)
+	}
+}
+
+// FileSaveDialog показывает нативный диалог сохранения файла с предложенным именем defaultName
+// Диалог открывается в startDir; если она пуста или не существует - в папке Документы
+// или домашней директории. К выбранному пути добавляется расширение ext, если его нет.
 // Если пользователь отменил выбор, возвращается dialog.Cancelled
-func FileSaveDialog(title string, defaultName string, filter string, ext string) (string, error) {
+func FileSaveDialog(title string, defaultName string, startDir string, filter string, ext string) (string, error) {
 	dlg := dialog.File().Title(title)
-	
+
 	if filter != "" && ext != "" {
 		dlg = dlg.Filter(filter, ext)
 	}
-	
-	// Устанавливаем имя файла по умолчанию, если указано
+
+	home, _ := os.UserHomeDir()
+	if dir := saveStartDir(startDir, home); dir != "" {
+		dlg = dlg.SetStartDir(dir)
+	}
 	if defaultName != "" {
-		// Для dialog.Save() нужно установить начальный путь
-		// Получаем домашнюю директорию и добавляем имя файла
-		homeDir, err := dialog.Directory().Title("").Browse()
-		if err == dialog.Cancelled {
-			return "", dialog.Cancelled
-		}
-		if err == nil && homeDir != "" {
-			defaultPath := filepath.Join(homeDir, defaultName)
-			// Сохраняем с предложенным путём
-			filename, err := dialog.File().
-				Title(title).
-				Filter(filter, ext).
-				SetStartFile(defaultPath).
-				Save()
-			return filename, err
-		}
+		dlg = dlg.SetStartFile(WithExtension(defaultName, ext))
 	}
-	
+
 	filename, err := dlg.Save()
 	if err != nil {
 		return "", err
 	}
-	
-	return filename, nil
+
+	return WithExtension(filename, ext), nil
 }
 
 // FileSaveDialogSimple упрощенная версия диалога сохранения
@@ -79,7 +108,57 @@ func FileSaveDialogSimple(title string, filter string, ext string) (string, erro
 	return filename, nil
 }
 
-// IsCancelled проверяет, является ли ошибка отменой диалога пользователем
+// DirectoryDialog показывает нативный диалог выбора директории
+// startDir задает начальную директорию; пустая строка - директория по умолчанию.
+// Если пользователь отменил выбор или закрыл диалог без выбора, возвращается dialog.Cancelled
+func DirectoryDialog(title string, startDir string) (string, error) {
+	dlg := dialog.Directory().Title(title)
+
+	if startDir != "" {
+		dlg = dlg.SetStartDir(startDir)
+	}
+
+	dir, err := dlg.Browse()
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", dialog.Cancelled
+	}
+
+	return dir, nil
+}
+
+// IsCancelled проверяет, является ли ошибка отменой диалога или операции пользователем
 func IsCancelled(err error) bool {
-	return err == dialog.Cancelled
+	return errors.Is(err, dialog.Cancelled) || errors.Is(err, apperrors.ErrCancelled)
+}
+
+// nativeDialogs реализация Dialogs системными диалогами
+// Вызовы блокируют до закрытия диалога, callback вызывается до возврата
+type nativeDialogs struct{}
+
+// NewNativeDialogs возвращает системные диалоги выбора файлов и папок
+func NewNativeDialogs() Dialogs {
+	return nativeDialogs{}
+}
+
+// OpenFile выбирает один файл
+func (nativeDialogs) OpenFile(title string, filter FileFilter, callback func(path string, err error)) {
+	callback(FileOpenDialog(title, filter.Description, filter.Extension))
+}
+
+// OpenFiles выбирает один или несколько файлов
+func (nativeDialogs) OpenFiles(title string, filter FileFilter, callback func(paths []string, err error)) {
+	callback(FilesOpenDialog(title, filter.Description, filter.Extension))
+}
+
+// SaveFile выбирает путь сохранения
+func (nativeDialogs) SaveFile(title, defaultName, startDir string, filter FileFilter, callback func(path string, err error)) {
+	callback(FileSaveDialog(title, defaultName, startDir, filter.Description, filter.Extension))
+}
+
+// PickDirectory выбирает директорию
+func (nativeDialogs) PickDirectory(title, startDir string, callback func(dir string, err error)) {
+	callback(DirectoryDialog(title, startDir))
 }
@@ -0,0 +1,42 @@
+//go:build darwin
+
+package native
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/sqweek/dialog"
+)
+
+// filesOpenDialog выбирает несколько файлов через AppleScript choose file
+func filesOpenDialog(title string, filter string, ext string) ([]string, error) {
+	ofType := ""
+	if ext != "" {
+		ofType = fmt.Sprintf(" of type {%s}", appleScriptString(ext))
+	}
+	script := fmt.Sprintf(`set chosen to choose file with prompt %s%s with multiple selections allowed
+set output to ""
+repeat with f in chosen
+	set output to output & POSIX path of f & linefeed
+end repeat
+return output`, appleScriptString(title), ofType)
+
+	output, err := exec.Command("osascript", "-e", script).Output()
+	if err != nil {
+		// Отмена выбора завершает osascript ошибкой -128
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "-128") {
+			return nil, dialog.Cancelled
+		}
+		return nil, fmt.Errorf("не удалось открыть диалог выбора файлов: %w", err)
+	}
+	return splitSelectionLines(string(output)), nil
+}
+
+// appleScriptString возвращает строковый литерал AppleScript
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
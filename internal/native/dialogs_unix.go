@@ -0,0 +1,43 @@
+//go:build !windows && !darwin
+
+package native
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/sqweek/dialog"
+)
+
+// filesOpenDialog выбирает несколько файлов через zenity или kdialog
+// Без этих программ возвращает errMultiSelectUnavailable
+func filesOpenDialog(title string, filter string, ext string) ([]string, error) {
+	var cmd *exec.Cmd
+	if path, err := exec.LookPath("zenity"); err == nil {
+		args := []string{"--file-selection", "--multiple", "--separator=\n", "--title=" + title}
+		if filter != "" && ext != "" {
+			args = append(args, fmt.Sprintf("--file-filter=%s | *.%s", filter, ext))
+		}
+		cmd = exec.Command(path, args...)
+	} else if path, err := exec.LookPath("kdialog"); err == nil {
+		pattern := ""
+		if filter != "" && ext != "" {
+			pattern = fmt.Sprintf("*.%s|%s", ext, filter)
+		}
+		cmd = exec.Command(path, "--getopenfilename", ".", pattern, "--multiple", "--separate-output", "--title", title)
+	} else {
+		return nil, errMultiSelectUnavailable
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		// Код выхода 1 у zenity и kdialog - отмена выбора
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, dialog.Cancelled
+		}
+		return nil, fmt.Errorf("не удалось открыть диалог выбора файлов: %w", err)
+	}
+	return splitSelectionLines(string(output)), nil
+}
@@ -0,0 +1,97 @@
+//go:build windows
+
+package native
+
+import (
+	"fmt"
+	"path/filepath"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/sqweek/dialog"
+)
+
+var (
+	comdlg32                 = syscall.NewLazyDLL("comdlg32.dll")
+	procGetOpenFileNameW     = comdlg32.NewProc("GetOpenFileNameW")
+	procCommDlgExtendedError = comdlg32.NewProc("CommDlgExtendedError")
+)
+
+// Флаги OPENFILENAME
+const (
+	ofnNoChangeDir       = 0x00000008
+	ofnAllowMultiSelect  = 0x00000200
+	ofnPathMustExist     = 0x00000800
+	ofnFileMustExist     = 0x00001000
+	ofnExplorer          = 0x00080000
+	fnerrBufferTooSmall  = 0x3003
+	multiSelectBufferLen = 64 * 1024
+)
+
+// openFileName структура OPENFILENAMEW
+type openFileName struct {
+	structSize      uint32
+	owner           uintptr
+	instance        uintptr
+	filter          *uint16
+	customFilter    *uint16
+	maxCustomFilter uint32
+	filterIndex     uint32
+	file            *uint16
+	maxFile         uint32
+	fileTitle       *uint16
+	maxFileTitle    uint32
+	initialDir      *uint16
+	title           *uint16
+	flags           uint32
+	fileOffset      uint16
+	fileExtension   uint16
+	defExt          *uint16
+	custData        uintptr
+	fnHook          uintptr
+	templateName    *uint16
+	reserved        uintptr
+	reservedFlags   uint32
+	flagsEx         uint32
+}
+
+// filesOpenDialog выбирает несколько файлов стандартным диалогом Windows с флагом OFN_ALLOWMULTISELECT
+func filesOpenDialog(title string, filter string, ext string) ([]string, error) {
+	buf := make([]uint16, multiSelectBufferLen)
+
+	ofn := openFileName{
+		file:    &buf[0],
+		maxFile: uint32(len(buf)),
+		title:   utf16Ptr(title),
+		flags:   ofnExplorer | ofnAllowMultiSelect | ofnFileMustExist | ofnPathMustExist | ofnNoChangeDir,
+	}
+	ofn.structSize = uint32(unsafe.Sizeof(ofn))
+	if filter != "" && ext != "" {
+		// Фильтр - пары "описание\0шаблон\0", завершенные дополнительным нулем
+		pattern := "*." + ext
+		filterBuf := utf16.Encode([]rune(fmt.Sprintf("%s (%s)\x00%s\x00\x00", filter, pattern, pattern)))
+		ofn.filter = &filterBuf[0]
+	}
+
+	ok, _, _ := procGetOpenFileNameW.Call(uintptr(unsafe.Pointer(&ofn)))
+	if ok == 0 {
+		code, _, _ := procCommDlgExtendedError.Call()
+		switch code {
+		case 0:
+			return nil, dialog.Cancelled
+		case fnerrBufferTooSmall:
+			return nil, fmt.Errorf("выбрано слишком много файлов, выберите их несколькими частями")
+		default:
+			return nil, fmt.Errorf("не удалось открыть диалог выбора файлов: код ошибки 0x%x", code)
+		}
+	}
+
+	return joinSelectionParts(splitMultiString(buf), filepath.Join), nil
+}
+
+// utf16Ptr возвращает указатель на строку UTF-16 с завершающим нулем
+func utf16Ptr(s string) *uint16 {
+	encoded := utf16.Encode([]rune(s + "\x00"))
+	return &encoded[0]
+}
@@ -0,0 +1,44 @@
+package native
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// saveStartDir выбирает начальную директорию диалога сохранения: startDir, если она существует,
+// иначе папку Документы в домашней директории home, иначе саму home
+func saveStartDir(startDir, home string) string {
+	if isDir(startDir) {
+		return startDir
+	}
+	if home == "" {
+		return ""
+	}
+	if documents := filepath.Join(home, "Documents"); isDir(documents) {
+		return documents
+	}
+	return home
+}
+
+// WithExtension добавляет к пути расширение ext (без точки), если путь оканчивается другим
+// Регистр расширения не учитывается: "Отчет.XLSX" остается как есть
+func WithExtension(path, ext string) string {
+	ext = strings.TrimPrefix(ext, ".")
+	if path == "" || ext == "" || ext == "*" {
+		return path
+	}
+	if strings.EqualFold(filepath.Ext(path), "."+ext) {
+		return path
+	}
+	return path + "." + ext
+}
+
+// isDir проверяет, что путь указывает на существующую директорию
+func isDir(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,68 @@
+package native
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSaveStartDir тестирует выбор начальной директории диалога сохранения
+func TestSaveStartDir(t *testing.T) {
+	home := t.TempDir()
+	homeWithDocuments := t.TempDir()
+	documents := filepath.Join(homeWithDocuments, "Documents")
+	if err := os.Mkdir(documents, 0755); err != nil {
+		t.Fatalf("не удалось создать директорию: %v", err)
+	}
+	file := filepath.Join(home, "result.xlsx")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("не удалось создать файл: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		startDir string
+		home     string
+		want     string
+	}{
+		{"существующая директория", homeWithDocuments, home, homeWithDocuments},
+		{"пустая - Документы", "", homeWithDocuments, documents},
+		{"несуществующая - домашняя", filepath.Join(home, "missing"), home, home},
+		{"файл вместо директории", file, home, home},
+		{"нет домашней директории", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := saveStartDir(tt.startDir, tt.home); got != tt.want {
+				t.Errorf("saveStartDir(%q, %q) = %q, want %q", tt.startDir, tt.home, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWithExtension тестирует добавление расширения к выбранному пути
+func TestWithExtension(t *testing.T) {
+	tests := []struct {
+		path string
+		ext  string
+		want string
+	}{
+		{"/out/result", "xlsx", "/out/result.xlsx"},
+		{"/out/result.xlsx", "xlsx", "/out/result.xlsx"},
+		{"/out/Отчет.XLSX", ".xlsx", "/out/Отчет.XLSX"},
+		{"/out/result.csv", "xlsx", "/out/result.csv.xlsx"},
+		{"/out/v1.2", "json", "/out/v1.2.json"},
+		{"/out/result", "", "/out/result"},
+		{"/out/result", "*", "/out/result"},
+		{"", "xlsx", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+"+"+tt.ext, func(t *testing.T) {
+			if got := WithExtension(tt.path, tt.ext); got != tt.want {
+				t.Errorf("WithExtension(%q, %q) = %q, want %q", tt.path, tt.ext, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package native
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf16"
+)
+
+// errMultiSelectUnavailable выбор нескольких файлов не поддерживается в этой среде
+var errMultiSelectUnavailable = errors.New("выбор нескольких файлов недоступен")
+
+// splitSelectionLines разбирает вывод системного диалога: по одному пути в строке
+// Пустые строки и пробелы по краям отбрасываются
+func splitSelectionLines(output string) []string {
+	files := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files
+}
+
+// splitMultiString разбирает последовательность UTF-16 строк, разделенных нулем
+// и завершенных двумя нулями, как в буфере диалога Windows
+func splitMultiString(buf []uint16) []string {
+	var parts []string
+	start := 0
+	for i, c := range buf {
+		if c != 0 {
+			continue
+		}
+		if i == start {
+			break
+		}
+		parts = append(parts, string(utf16.Decode(buf[start:i])))
+		start = i + 1
+	}
+	return parts
+}
+
+// joinSelectionParts собирает пути из частей результата диалога Windows:
+// одна часть - полный путь к файлу, несколько - директория и имена файлов в ней
+func joinSelectionParts(parts []string, join func(elem ...string) string) []string {
+	switch len(parts) {
+	case 0:
+		return []string{}
+	case 1:
+		return parts
+	}
+
+	files := make([]string, 0, len(parts)-1)
+	for _, name := range parts[1:] {
+		files = append(files, join(parts[0], name))
+	}
+	return files
+}
@@ -0,0 +1,66 @@
+package native
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// TestSplitSelectionLines тестирует разбор вывода zenity, kdialog и osascript
+func TestSplitSelectionLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{"пустой вывод", "", []string{}},
+		{"один файл", "/home/user/Заказы.xlsx\n", []string{"/home/user/Заказы.xlsx"}},
+		{"несколько файлов", "/a/1.xlsx\n/a/2 копия.xlsx\r\n\n/b/3.xlsx", []string{"/a/1.xlsx", "/a/2 копия.xlsx", "/b/3.xlsx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitSelectionLines(tt.output)
+			if got == nil || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("splitSelectionLines(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWindowsSelectionParsing тестирует разбор буфера диалога Windows с выбором нескольких файлов
+func TestWindowsSelectionParsing(t *testing.T) {
+	encode := func(parts ...string) []uint16 {
+		var buf []uint16
+		for _, part := range parts {
+			buf = append(buf, utf16.Encode([]rune(part))...)
+			buf = append(buf, 0)
+		}
+		return append(buf, 0, 0, 0) // завершающий ноль и хвост буфера
+	}
+	join := func(elem ...string) string { return strings.Join(elem, `\`) }
+
+	tests := []struct {
+		name string
+		buf  []uint16
+		want []string
+	}{
+		{"пустой буфер", make([]uint16, 8), []string{}},
+		{"один файл", encode(`C:\Отчеты\Заказы.xlsx`), []string{`C:\Отчеты\Заказы.xlsx`}},
+		{
+			"несколько файлов",
+			encode(`C:\Отчеты`, "1.xlsx", "2 копия.xlsx"),
+			[]string{`C:\Отчеты\1.xlsx`, `C:\Отчеты\2 копия.xlsx`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := joinSelectionParts(splitMultiString(tt.buf), join)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("разбор = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
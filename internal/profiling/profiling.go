@@ -0,0 +1,197 @@
+package profiling
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"runtime/pprof"
+	"strings"
+	"sync/atomic"
+)
+
+// CPUProfileEnv переменная окружения с путем файла CPU-профиля объединения
+const CPUProfileEnv = "EXCEL_MERGER_CPUPROFILE"
+
+// MemProfileEnv переменная окружения с путем файла профиля памяти объединения
+const MemProfileEnv = "EXCEL_MERGER_MEMPROFILE"
+
+// Config пути файлов профилей; пустой путь - профиль не снимается
+// Относительные пути отсчитываются от Dir (директории журналов)
+type Config struct {
+	CPUProfile string
+	MemProfile string
+	Dir        string
+}
+
+// FromEnv читает пути профилей из переменных окружения CPUProfileEnv и MemProfileEnv
+// Без переменных профилирование выключено
+func FromEnv(dir string) Config {
+	return Config{
+		CPUProfile: strings.TrimSpace(os.Getenv(CPUProfileEnv)),
+		MemProfile: strings.TrimSpace(os.Getenv(MemProfileEnv)),
+		Dir:        dir,
+	}
+}
+
+// Enabled сообщает, что задан хотя бы один профиль
+func (c Config) Enabled() bool {
+	return c.CPUProfile != "" || c.MemProfile != ""
+}
+
+// resolve возвращает путь файла профиля с учетом директории Dir
+func (c Config) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) || c.Dir == "" {
+		return path
+	}
+	return filepath.Join(c.Dir, path)
+}
+
+// Stats сведения об объединении для быстрой диагностики
+type Stats struct {
+	RowsProcessed int64  `json:"rows_processed"`
+	BytesWritten  int64  `json:"bytes_written"`
+	HeapInUse     uint64 `json:"heap_in_use_bytes"`
+	CPUProfile    string `json:"cpu_profile,omitempty"`
+	MemProfile    string `json:"mem_profile,omitempty"`
+}
+
+// Session снятие профилей вокруг одной операции объединения
+type Session struct {
+	cpuProfile string
+	memProfile string
+	cpuFile    *os.File
+	logger     *slog.Logger
+
+	rows  atomic.Int64
+	bytes atomic.Int64
+}
+
+// Start начинает снятие профилей по cfg
+// Возвращает nil без ошибки, если профилирование выключено
+func Start(cfg Config, logger *slog.Logger) (*Session, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	s := &Session{
+		cpuProfile: cfg.resolve(cfg.CPUProfile),
+		memProfile: cfg.resolve(cfg.MemProfile),
+		logger:     logger,
+	}
+
+	if s.cpuProfile != "" {
+		file, err := createProfileFile(s.cpuProfile)
+		if err != nil {
+			return nil, err
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("не удалось начать CPU-профилирование: %w", err)
+		}
+		s.cpuFile = file
+	}
+
+	logger.Info("профилирование объединения включено",
+		"cpu_profile", s.cpuProfile,
+		"mem_profile", s.memProfile,
+	)
+	return s, nil
+}
+
+// AddRows учитывает обработанные строки
+func (s *Session) AddRows(n int) {
+	if s != nil {
+		s.rows.Add(int64(n))
+	}
+}
+
+// AddBytesWritten учитывает размер записанного результата
+func (s *Session) AddBytesWritten(n int64) {
+	if s != nil {
+		s.bytes.Add(n)
+	}
+}
+
+// Stop завершает профилирование, записывает профиль памяти и возвращает сведения об объединении
+// Для nil-сессии возвращает nil
+func (s *Session) Stop() (*Stats, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	stats := &Stats{
+		RowsProcessed: s.rows.Load(),
+		BytesWritten:  s.bytes.Load(),
+		HeapInUse:     heapInUse(),
+	}
+
+	var firstErr error
+	if s.cpuFile != nil {
+		pprof.StopCPUProfile()
+		if err := s.cpuFile.Close(); err != nil {
+			firstErr = fmt.Errorf("не удалось сохранить CPU-профиль: %w", err)
+		} else {
+			stats.CPUProfile = s.cpuProfile
+		}
+		s.cpuFile = nil
+	}
+
+	if s.memProfile != "" {
+		if err := writeHeapProfile(s.memProfile); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else {
+			stats.MemProfile = s.memProfile
+		}
+	}
+
+	s.logger.Info("профили объединения сохранены",
+		"cpu_profile", stats.CPUProfile,
+		"mem_profile", stats.MemProfile,
+		"rows_processed", stats.RowsProcessed,
+		"bytes_written", stats.BytesWritten,
+		"heap_in_use_bytes", stats.HeapInUse,
+	)
+	return stats, firstErr
+}
+
+// createProfileFile создает файл профиля вместе с директорией
+func createProfileFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("не удалось создать директорию профиля: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать файл профиля: %w", err)
+	}
+	return file, nil
+}
+
+// writeHeapProfile записывает профиль памяти после сборки мусора
+func writeHeapProfile(path string) error {
+	file, err := createProfileFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		return fmt.Errorf("не удалось записать профиль памяти: %w", err)
+	}
+	return nil
+}
+
+// heapInUse возвращает объем занятой объектами кучи
+func heapInUse() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	return sample[0].Value.Uint64()
+}
@@ -0,0 +1,84 @@
+package profiling
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFromEnv тестирует чтение путей профилей из переменных окружения
+func TestFromEnv(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("выключено по умолчанию", func(t *testing.T) {
+		t.Setenv(CPUProfileEnv, "")
+		t.Setenv(MemProfileEnv, "")
+
+		cfg := FromEnv(dir)
+		if cfg.Enabled() {
+			t.Errorf("профилирование включено без переменных окружения: %+v", cfg)
+		}
+		session, err := Start(cfg, nil)
+		if session != nil || err != nil {
+			t.Errorf("Start() = %v, %v; ожидалось nil, nil", session, err)
+		}
+		if stats, err := session.Stop(); stats != nil || err != nil {
+			t.Errorf("Stop() для nil-сессии = %v, %v", stats, err)
+		}
+	})
+
+	t.Run("относительный путь в директории журналов", func(t *testing.T) {
+		absolute := filepath.Join(t.TempDir(), "mem.pprof")
+		t.Setenv(CPUProfileEnv, "cpu.pprof")
+		t.Setenv(MemProfileEnv, absolute)
+
+		cfg := FromEnv(dir)
+		if got := cfg.resolve(cfg.CPUProfile); got != filepath.Join(dir, "cpu.pprof") {
+			t.Errorf("путь CPU-профиля = %q", got)
+		}
+		if got := cfg.resolve(cfg.MemProfile); got != absolute {
+			t.Errorf("путь профиля памяти = %q, ожидался %q", got, absolute)
+		}
+	})
+}
+
+// TestSessionWritesProfiles тестирует создание файлов профилей и сведения об объединении
+func TestSessionWritesProfiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	t.Setenv(CPUProfileEnv, "cpu.pprof")
+	t.Setenv(MemProfileEnv, "mem.pprof")
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	session, err := Start(FromEnv(dir), logger)
+	if err != nil || session == nil {
+		t.Fatalf("Start() = %v, %v", session, err)
+	}
+
+	session.AddRows(10)
+	session.AddRows(5)
+	session.AddBytesWritten(2048)
+
+	stats, err := session.Stop()
+	if err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if stats.RowsProcessed != 15 || stats.BytesWritten != 2048 || stats.HeapInUse == 0 {
+		t.Errorf("сведения = %+v", stats)
+	}
+
+	for _, path := range []string{stats.CPUProfile, stats.MemProfile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Errorf("файл профиля не создан: %v", err)
+			continue
+		}
+		if info.Size() == 0 {
+			t.Errorf("файл профиля %s пуст", path)
+		}
+		if filepath.Dir(path) != dir {
+			t.Errorf("профиль %s записан вне директории журналов %s", path, dir)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package updater
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// githubCacheFile имя файла кеша ответов GitHub API
+const githubCacheFile = "github_cache.json"
+
+// ErrRateLimited возвращается, если лимит запросов к GitHub API исчерпан
+var ErrRateLimited = errors.New("лимит запросов к GitHub API исчерпан")
+
+// RateLimitError ошибка исчерпания лимита запросов с временем его сброса
+type RateLimitError struct {
+	Reset time.Time // Время, после которого запросы снова разрешены
+}
+
+// Error реализует интерфейс error
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s, повторная проверка после %s", ErrRateLimited, e.Reset.Local().Format("15:04"))
+}
+
+// Is позволяет сравнивать ошибку с ErrRateLimited через errors.Is
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// cachedResponse сохраненный ответ GitHub API
+type cachedResponse struct {
+	ETag string          `json:"etag"`
+	Body json.RawMessage `json:"body"`
+}
+
+// responseCache дисковый кеш ответов GitHub API и состояния лимита запросов
+type responseCache struct {
+	Responses      map[string]cachedResponse `json:"responses"`
+	RateLimitReset time.Time                 `json:"rate_limit_reset,omitempty"`
+}
+
+// loadResponseCache загружает кеш из файла
+// Отсутствующий или поврежденный файл дает пустой кеш
+func loadResponseCache(path string) *responseCache {
+	cache := &responseCache{Responses: make(map[string]cachedResponse)}
+	if path == "" {
+		return cache
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+
+	if err := json.Unmarshal(data, cache); err != nil || cache.Responses == nil {
+		return &responseCache{Responses: make(map[string]cachedResponse)}
+	}
+
+	return cache
+}
+
+// save сохраняет кеш в файл
+func (c *responseCache) save(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("не удалось создать директорию кеша: %w", err)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать кеш: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("не удалось записать файл кеша: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package updater
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxChangelogLength максимальная длина объединенного списка изменений (в символах)
+const maxChangelogLength = 8000
+
+// changelogTruncatedNote отметка о сокращении списка изменений
+const changelogTruncatedNote = "\n\n…\n\n*Список изменений сокращен, полная история доступна на странице релизов.*"
+
+// newerReleases возвращает релизы новее current, отсортированные от новых к старым
+// Черновики и релизы с некорректной версией пропускаются,
+// pre-release учитываются только при includePrerelease
+func newerReleases(releases []GitHubRelease, current *Version, includePrerelease bool) []GitHubRelease {
+	type versioned struct {
+		release GitHubRelease
+		version *Version
+	}
+
+	var newer []versioned
+	for _, release := range releases {
+		if release.Draft || (release.Prerelease && !includePrerelease) {
+			continue
+		}
+
+		ver, err := ParseVersion(release.TagName)
+		if err != nil || !ver.IsNewer(current) {
+			continue
+		}
+
+		newer = append(newer, versioned{release: release, version: ver})
+	}
+
+	sort.SliceStable(newer, func(i, j int) bool {
+		return newer[i].version.IsNewer(newer[j].version)
+	})
+
+	result := make([]GitHubRelease, len(newer))
+	for i, item := range newer {
+		result[i] = item.release
+	}
+	return result
+}
+
+// aggregateChangelog объединяет списки изменений релизов с заголовками версий
+// Результат ограничивается maxChangelogLength символами
+func aggregateChangelog(releases []GitHubRelease) string {
+	var sb strings.Builder
+	for i, release := range releases {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("## ")
+		sb.WriteString(release.TagName)
+		sb.WriteString("\n\n")
+
+		body := strings.TrimSpace(release.Body)
+		if body == "" {
+			body = "Описание изменений недоступно"
+		}
+		sb.WriteString(body)
+	}
+
+	return truncateChangelog(sb.String(), maxChangelogLength)
+}
+
+// truncateChangelog обрезает текст до limit символов, добавляя отметку о сокращении
+func truncateChangelog(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	// Стараемся обрезать по границе строки
+	cut := string(runes[:limit])
+	if idx := strings.LastIndex(cut, "\n"); idx > 0 {
+		cut = cut[:idx]
+	}
+
+	return strings.TrimRight(cut, "\n ") + changelogTruncatedNote
+}
+
+// NewVersionsLabel возвращает подпись о количестве вышедших версий
+// например "3 новых версии с момента вашей"
+func NewVersionsLabel(count int) string {
+	var word string
+	switch mod100 := count % 100; {
+	case mod100 >= 11 && mod100 <= 14:
+		word = "новых версий"
+	case count%10 == 1:
+		word = "новая версия"
+	case count%10 >= 2 && count%10 <= 4:
+		word = "новых версии"
+	default:
+		word = "новых версий"
+	}
+
+	return fmt.Sprintf("%d %s с момента вашей", count, word)
+}
@@ -0,0 +1,134 @@
+package updater
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// mockReleases список релизов в порядке, в котором его может вернуть GitHub
+var mockReleases = []GitHubRelease{
+	{TagName: "v0.2.0", Body: "Изменения 0.2.0"},
+	{TagName: "v0.4.0", Body: "Изменения 0.4.0"},
+	{TagName: "v0.5.0-beta", Body: "Изменения 0.5.0-beta", Prerelease: true},
+	{TagName: "v0.1.0", Body: "Изменения 0.1.0"},
+	{TagName: "v0.3.0", Body: "Изменения 0.3.0"},
+	{TagName: "v0.6.0", Body: "Черновик", Draft: true},
+}
+
+func TestNewerReleasesOrdering(t *testing.T) {
+	current, _ := ParseVersion("0.1.0")
+
+	tests := []struct {
+		name              string
+		includePrerelease bool
+		want              []string
+	}{
+		{"Stable channel", false, []string{"v0.4.0", "v0.3.0", "v0.2.0"}},
+		{"Prerelease channel", true, []string{"v0.5.0-beta", "v0.4.0", "v0.3.0", "v0.2.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newerReleases(mockReleases, current, tt.includePrerelease)
+			var tags []string
+			for _, release := range got {
+				tags = append(tags, release.TagName)
+			}
+			if strings.Join(tags, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("newerReleases() = %v, want %v", tags, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildReleaseInfoAggregatesChangelog(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	checker := NewUpdateChecker("0.1.0", "owner", "repo", logger)
+
+	info, err := checker.buildReleaseInfo(mockReleases)
+	if err != nil {
+		t.Fatalf("buildReleaseInfo() error = %v", err)
+	}
+	if info == nil || info.Version != "v0.4.0" {
+		t.Fatalf("expected update to v0.4.0, got %+v", info)
+	}
+	if info.VersionsBehind != 3 {
+		t.Errorf("VersionsBehind = %d, want 3", info.VersionsBehind)
+	}
+
+	// Версии идут от новых к старым, каждая с заголовком
+	i4 := strings.Index(info.Changelog, "## v0.4.0")
+	i3 := strings.Index(info.Changelog, "## v0.3.0")
+	i2 := strings.Index(info.Changelog, "## v0.2.0")
+	if i4 < 0 || i3 < 0 || i2 < 0 || !(i4 < i3 && i3 < i2) {
+		t.Errorf("unexpected changelog order:\n%s", info.Changelog)
+	}
+	if strings.Contains(info.Changelog, "0.5.0-beta") || strings.Contains(info.Changelog, "Черновик") {
+		t.Errorf("changelog must not include prereleases or drafts on stable channel:\n%s", info.Changelog)
+	}
+	if strings.Contains(info.Changelog, "Изменения 0.1.0") {
+		t.Errorf("changelog must not include the current version:\n%s", info.Changelog)
+	}
+}
+
+func TestBuildReleaseInfoSingleVersionKeepsChangelog(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	checker := NewUpdateChecker("0.3.0", "owner", "repo", logger)
+
+	info, err := checker.buildReleaseInfo(mockReleases)
+	if err != nil {
+		t.Fatalf("buildReleaseInfo() error = %v", err)
+	}
+	if info == nil || info.VersionsBehind != 1 || info.Changelog != "Изменения 0.4.0" {
+		t.Errorf("unexpected release info: %+v", info)
+	}
+
+	checker = NewUpdateChecker("0.4.0", "owner", "repo", logger)
+	if info, _ := checker.buildReleaseInfo(mockReleases); info != nil {
+		t.Errorf("expected no update, got %+v", info)
+	}
+}
+
+func TestAggregateChangelogTruncation(t *testing.T) {
+	var releases []GitHubRelease
+	for i := 0; i < 20; i++ {
+		releases = append(releases, GitHubRelease{
+			TagName: "v1.0." + strings.Repeat("9", i+1),
+			Body:    strings.Repeat("строка изменений\n", 100),
+		})
+	}
+
+	changelog := aggregateChangelog(releases)
+	if !strings.HasSuffix(changelog, changelogTruncatedNote) {
+		t.Error("expected truncation note at the end")
+	}
+	if n := len([]rune(changelog)); n > maxChangelogLength+len([]rune(changelogTruncatedNote)) {
+		t.Errorf("changelog length %d exceeds limit", n)
+	}
+	if !strings.HasPrefix(changelog, "## "+releases[0].TagName) {
+		t.Error("newest release must stay at the top after truncation")
+	}
+}
+
+func TestNewVersionsLabel(t *testing.T) {
+	tests := []struct {
+		count int
+		want  string
+	}{
+		{1, "1 новая версия с момента вашей"},
+		{2, "2 новых версии с момента вашей"},
+		{3, "3 новых версии с момента вашей"},
+		{5, "5 новых версий с момента вашей"},
+		{11, "11 новых версий с момента вашей"},
+		{21, "21 новая версия с момента вашей"},
+		{24, "24 новых версии с момента вашей"},
+	}
+
+	for _, tt := range tests {
+		if got := NewVersionsLabel(tt.count); got != tt.want {
+			t.Errorf("NewVersionsLabel(%d) = %q, want %q", tt.count, got, tt.want)
+		}
+	}
+}
@@ -2,13 +2,21 @@ package updater
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 )
 
+// Каналы обновлений
+const (
+	ChannelStable     = "stable"     // Только стабильные релизы
+	ChannelPrerelease = "prerelease" // Стабильные релизы и pre-release версии
+)
+
 // UpdateChecker проверяет наличие обновлений
 type UpdateChecker struct {
 	currentVersion string
+	channel        string
 	githubClient   *GitHubClient
 	logger         *slog.Logger
 }
@@ -17,20 +25,50 @@ type UpdateChecker struct {
 func NewUpdateChecker(currentVersion, owner, repo string, logger *slog.Logger) *UpdateChecker {
 	return &UpdateChecker{
 		currentVersion: currentVersion,
+		channel:        ChannelStable,
 		githubClient:   NewGitHubClient(owner, repo),
 		logger:         logger,
 	}
 }
 
+// SetChannel устанавливает канал обновлений (stable или prerelease)
+// Неизвестные значения трактуются как stable
+func (uc *UpdateChecker) SetChannel(channel string) {
+	if channel != ChannelPrerelease {
+		channel = ChannelStable
+	}
+	uc.channel = channel
+}
+
+// SetSource задает источник обновлений: адрес API, репозиторий и сертификаты
+func (uc *UpdateChecker) SetSource(source Source) error {
+	return uc.githubClient.SetSource(source)
+}
+
+// SetCacheDir задает директорию для кеша ответов GitHub API
+func (uc *UpdateChecker) SetCacheDir(dir string) {
+	uc.githubClient.SetCacheDir(dir)
+}
+
 // CheckForUpdates проверяет наличие новой версии
 // Возвращает информацию об обновлении если оно доступно, или nil если обновлений нет
 func (uc *UpdateChecker) CheckForUpdates(ctx context.Context) (*ReleaseInfo, error) {
 	uc.logger.Info("Проверка обновлений",
 		"current_version", uc.currentVersion,
+		"channel", uc.channel,
 	)
 
-	// Получаем последний релиз из GitHub
-	release, err := uc.githubClient.GetLatestRelease(ctx)
+	releases, err := uc.fetchReleases(ctx)
+	if errors.Is(err, ErrRateLimited) {
+		uc.logger.Info("Проверка обновлений отложена до сброса лимита запросов GitHub API",
+			"error", err,
+		)
+		return nil, err
+	}
+	if errors.Is(err, ErrOffline) {
+		uc.logger.Debug("Сеть недоступна, проверка обновлений пропущена", "error", err)
+		return nil, err
+	}
 	if err != nil {
 		uc.logger.Warn("Не удалось получить информацию о последнем релизе",
 			"error", err,
@@ -38,13 +76,58 @@ func (uc *UpdateChecker) CheckForUpdates(ctx context.Context) (*ReleaseInfo, err
 		return nil, fmt.Errorf("ошибка получения информации о релизе: %w", err)
 	}
 
-	// Пропускаем черновики и pre-release версии
-	if release.Draft || release.Prerelease {
-		uc.logger.Info("Последний релиз является черновиком или pre-release, пропускаем",
-			"tag", release.TagName,
-			"draft", release.Draft,
-			"prerelease", release.Prerelease,
+	return uc.buildReleaseInfo(releases)
+}
+
+// fetchReleases получает релизы для выбора обновления с учетом канала
+// Стабильный канал запрашивает /releases/latest: последний стабильный релиз выбирает GitHub,
+// и без обновлений достаточно одного небольшого ответа. Полный список нужен, только если
+// этот релиз новее текущей версии, - для списка изменений пропущенных версий
+func (uc *UpdateChecker) fetchReleases(ctx context.Context) ([]GitHubRelease, error) {
+	if uc.channel == ChannelPrerelease {
+		return uc.githubClient.GetReleases(ctx)
+	}
+
+	latest, err := uc.githubClient.GetLatestRelease(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current, currentErr := ParseVersion(uc.currentVersion)
+	latestVer, latestErr := ParseVersion(latest.TagName)
+	if currentErr != nil || latestErr != nil || !latestVer.IsNewer(current) {
+		return []GitHubRelease{*latest}, nil
+	}
+
+	releases, err := uc.githubClient.GetReleases(ctx)
+	if err != nil {
+		uc.logger.Warn("Не удалось получить список релизов, показываются изменения только последней версии",
+			"error", err,
 		)
+		return []GitHubRelease{*latest}, nil
+	}
+
+	// Новее /releases/latest ничего не предлагается: из списка берутся версии не новее его
+	upTo := []GitHubRelease{*latest}
+	for _, release := range releases {
+		ver, err := ParseVersion(release.TagName)
+		if err != nil || release.TagName == latest.TagName || ver.IsNewer(latestVer) {
+			continue
+		}
+		upTo = append(upTo, release)
+	}
+	return upTo, nil
+}
+
+// buildReleaseInfo выбирает последний релиз с учетом канала обновлений и
+// формирует информацию об обновлении со списком изменений всех пропущенных версий
+// Возвращает nil, если обновлений нет
+func (uc *UpdateChecker) buildReleaseInfo(releases []GitHubRelease) (*ReleaseInfo, error) {
+	includePrerelease := uc.channel == ChannelPrerelease
+
+	release := selectNewestRelease(releases, includePrerelease)
+	if release == nil {
+		uc.logger.Info("Подходящих релизов не найдено", "releases_count", len(releases))
 		return nil, nil
 	}
 
@@ -53,8 +136,7 @@ func (uc *UpdateChecker) CheckForUpdates(ctx context.Context) (*ReleaseInfo, err
 		"published_at", release.PublishedAt,
 	)
 
-	// Сравниваем версии
-	isNewer, err := CompareVersions(uc.currentVersion, release.TagName)
+	current, err := ParseVersion(uc.currentVersion)
 	if err != nil {
 		uc.logger.Error("Ошибка сравнения версий",
 			"current", uc.currentVersion,
@@ -64,7 +146,9 @@ func (uc *UpdateChecker) CheckForUpdates(ctx context.Context) (*ReleaseInfo, err
 		return nil, fmt.Errorf("ошибка сравнения версий: %w", err)
 	}
 
-	if !isNewer {
+	// Все релизы новее текущей версии, от новых к старым
+	newer := newerReleases(releases, current, includePrerelease)
+	if len(newer) == 0 {
 		uc.logger.Info("Установлена последняя версия",
 			"current_version", uc.currentVersion,
 			"latest_version", release.TagName,
@@ -75,11 +159,43 @@ func (uc *UpdateChecker) CheckForUpdates(ctx context.Context) (*ReleaseInfo, err
 	uc.logger.Info("Доступно обновление",
 		"current_version", uc.currentVersion,
 		"new_version", release.TagName,
+		"versions_behind", len(newer),
 	)
 
 	// Создаем информацию об обновлении
 	info := release.ToReleaseInfo()
 	info.IsNewer = true
+	info.VersionsBehind = len(newer)
+	if len(newer) > 1 {
+		info.Changelog = aggregateChangelog(newer)
+	}
 
 	return info, nil
 }
+
+// selectNewestRelease выбирает самый новый релиз из списка по семантической версии
+// Черновики и релизы с некорректной версией пропускаются,
+// pre-release учитываются только при includePrerelease
+func selectNewestRelease(releases []GitHubRelease, includePrerelease bool) *GitHubRelease {
+	var newest *GitHubRelease
+	var newestVer *Version
+
+	for i := range releases {
+		release := &releases[i]
+		if release.Draft || (release.Prerelease && !includePrerelease) {
+			continue
+		}
+
+		ver, err := ParseVersion(release.TagName)
+		if err != nil {
+			continue
+		}
+
+		if newestVer == nil || ver.IsNewer(newestVer) {
+			newest = release
+			newestVer = ver
+		}
+	}
+
+	return newest
+}
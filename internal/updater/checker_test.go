@@ -0,0 +1,125 @@
+package updater
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSelectNewestRelease(t *testing.T) {
+	releases := []GitHubRelease{
+		{TagName: "v0.1.0"},
+		{TagName: "v0.3.0-alpha", Prerelease: true},
+		{TagName: "v0.2.0"},
+		{TagName: "v0.2.1-beta", Prerelease: true},
+		{TagName: "v0.4.0", Draft: true},
+		{TagName: "nightly"},
+	}
+
+	tests := []struct {
+		name              string
+		includePrerelease bool
+		want              string
+	}{
+		{
+			name:              "Stable channel ignores prereleases and drafts",
+			includePrerelease: false,
+			want:              "v0.2.0",
+		},
+		{
+			name:              "Prerelease channel picks newest prerelease",
+			includePrerelease: true,
+			want:              "v0.3.0-alpha",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectNewestRelease(releases, tt.includePrerelease)
+			if got == nil {
+				t.Fatalf("selectNewestRelease() = nil, want %s", tt.want)
+			}
+			if got.TagName != tt.want {
+				t.Errorf("selectNewestRelease() = %s, want %s", got.TagName, tt.want)
+			}
+		})
+	}
+
+	t.Run("Only drafts", func(t *testing.T) {
+		got := selectNewestRelease([]GitHubRelease{{TagName: "v1.0.0", Draft: true}}, true)
+		if got != nil {
+			t.Errorf("selectNewestRelease() = %s, want nil", got.TagName)
+		}
+	})
+}
+
+// TestCheckForUpdatesStableUsesLatestRelease тестирует, что стабильный канал выбирает
+// обновление по /releases/latest, а список релизов запрашивает только для списка изменений
+func TestCheckForUpdatesStableUsesLatestRelease(t *testing.T) {
+	const latest = `{"tag_name":"v1.2.0","body":"Изменения 1.2.0"}`
+	const releases = `[
+		{"tag_name":"v1.3.0","body":"Изменения 1.3.0"},
+		{"tag_name":"v1.2.0","body":"Изменения 1.2.0"},
+		{"tag_name":"v1.1.0","body":"Изменения 1.1.0"},
+		{"tag_name":"v1.0.0","body":"Изменения 1.0.0"}
+	]`
+
+	tests := []struct {
+		name           string
+		currentVersion string
+		wantVersion    string
+		wantBehind     int
+		wantList       bool
+	}{
+		{"установлена последняя версия", "v1.2.0", "", 0, false},
+		{"пропущено несколько версий", "v1.0.0", "v1.2.0", 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var listRequested atomic.Bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/repos/owner/repo/releases/latest":
+					w.Write([]byte(latest))
+				case "/repos/owner/repo/releases":
+					listRequested.Store(true)
+					w.Write([]byte(releases))
+				default:
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			checker := NewUpdateChecker(tt.currentVersion, "owner", "repo", slog.New(slog.DiscardHandler))
+			checker.githubClient = newTestClient(t, server)
+
+			info, err := checker.CheckForUpdates(context.Background())
+			if err != nil {
+				t.Fatalf("CheckForUpdates() error = %v", err)
+			}
+			if listRequested.Load() != tt.wantList {
+				t.Errorf("список релизов запрошен = %v, ожидалось %v", listRequested.Load(), tt.wantList)
+			}
+			if tt.wantVersion == "" {
+				if info != nil {
+					t.Errorf("CheckForUpdates() = %s, обновлений не ожидалось", info.Version)
+				}
+				return
+			}
+			if info == nil {
+				t.Fatalf("CheckForUpdates() = nil, ожидалось %s", tt.wantVersion)
+			}
+			if info.Version != tt.wantVersion || info.VersionsBehind != tt.wantBehind {
+				t.Errorf("версия %s, пропущено %d; ожидалось %s, %d", info.Version, info.VersionsBehind, tt.wantVersion, tt.wantBehind)
+			}
+			if strings.Contains(info.Changelog, "1.3.0") || !strings.Contains(info.Changelog, "Изменения 1.1.0") {
+				t.Errorf("неожиданный список изменений: %q", info.Changelog)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package updater
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// checksumAssetNames имена файлов контрольных сумм SHA-256 в релизе (формат sha256sum), без учета регистра
+var checksumAssetNames = []string{"checksums.txt", "sha256sums", "sha256sums.txt", "checksums.sha256"}
+
+// maxChecksumFileSize наибольший размер файла контрольных сумм
+const maxChecksumFileSize = 1 << 20
+
+// SelectChecksumAsset выбирает среди файлов релиза файл контрольных сумм SHA-256
+// Возвращает nil, если такого файла нет
+func SelectChecksumAsset(assets []GitHubAsset) *GitHubAsset {
+	for _, name := range checksumAssetNames {
+		for i := range assets {
+			if strings.EqualFold(assets[i].Name, name) {
+				return &assets[i]
+			}
+		}
+	}
+	return nil
+}
+
+// VerifyAssetChecksum сверяет SHA-256 загруженного файла path с контрольной суммой файла
+// релиза assetName из checksums. Без файла контрольных сумм обновление не устанавливается:
+// целостность загруженной сборки проверить нечем
+func VerifyAssetChecksum(ctx context.Context, checksums *GitHubAsset, assetName, path string) error {
+	if checksums == nil || checksums.BrowserDownloadURL == "" {
+		return fmt.Errorf("в релизе нет файла контрольных сумм: установите обновление вручную со страницы релиза")
+	}
+
+	data, err := fetchChecksums(ctx, checksums)
+	if err != nil {
+		return err
+	}
+	expected, err := findChecksum(data, assetName)
+	if err != nil {
+		return err
+	}
+
+	actual, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("контрольная сумма загруженного файла %s не совпадает с опубликованной: файл поврежден или подменен", assetName)
+	}
+	return nil
+}
+
+// fetchChecksums загружает файл контрольных сумм релиза
+func fetchChecksums(ctx context.Context, checksums *GitHubAsset) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checksums.BrowserDownloadURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("User-Agent", "Excel-Merger-Updater")
+
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return nil, classifyNetworkError(fmt.Errorf("ошибка загрузки контрольных сумм: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: resp.Status}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxChecksumFileSize+1))
+	if err != nil {
+		return nil, classifyNetworkError(fmt.Errorf("ошибка загрузки контрольных сумм: %w", err))
+	}
+	if len(data) > maxChecksumFileSize {
+		return nil, fmt.Errorf("файл контрольных сумм %s слишком большой", checksums.Name)
+	}
+	return data, nil
+}
+
+// findChecksum находит в файле контрольных сумм формата sha256sum ("<hex>  <имя>") сумму файла name
+// Имя может начинаться с "*" (двоичный режим sha256sum) и сравнивается без учета регистра
+func findChecksum(data []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		sum, file := strings.ToLower(fields[0]), strings.TrimPrefix(fields[1], "*")
+		if !strings.EqualFold(file, name) {
+			continue
+		}
+		if decoded, err := hex.DecodeString(sum); err != nil || len(decoded) != sha256.Size {
+			return "", fmt.Errorf("некорректная контрольная сумма файла %s: %q", name, fields[0])
+		}
+		return sum, nil
+	}
+	return "", fmt.Errorf("в файле контрольных сумм нет файла %s", name)
+}
+
+// fileSHA256 вычисляет SHA-256 файла path в шестнадцатеричном виде
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("не удалось открыть загруженный файл: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("не удалось прочитать загруженный файл: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
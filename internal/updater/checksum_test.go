@@ -0,0 +1,87 @@
+package updater
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelectChecksumAsset(t *testing.T) {
+	tests := []struct {
+		name   string
+		assets []GitHubAsset
+		want   string
+	}{
+		{"checksums.txt", []GitHubAsset{{Name: "excel-merger-linux-amd64.tar.gz"}, {Name: "checksums.txt"}}, "checksums.txt"},
+		{"SHA256SUMS", []GitHubAsset{{Name: "SHA256SUMS"}, {Name: "excel-merger-win-x64.exe"}}, "SHA256SUMS"},
+		{"нет файла", []GitHubAsset{{Name: "excel-merger-win-x64.exe"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectChecksumAsset(tt.assets)
+			name := ""
+			if got != nil {
+				name = got.Name
+			}
+			if name != tt.want {
+				t.Errorf("SelectChecksumAsset() = %q, want %q", name, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyAssetChecksum(t *testing.T) {
+	payload := []byte("сборка обновления")
+	sum := sha256.Sum256(payload)
+	goodSum := hex.EncodeToString(sum[:])
+	otherSum := strings.Repeat("ab", sha256.Size)
+
+	path := filepath.Join(t.TempDir(), "asset.zip")
+	if err := os.WriteFile(path, payload, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		checksums string // Содержимое файла контрольных сумм; пусто - файла нет в релизе
+		wantErr   string
+	}{
+		{"совпадает", otherSum + "  other.zip\n" + strings.ToUpper(goodSum) + "  asset.zip\n", ""},
+		{"двоичный режим sha256sum", goodSum + " *asset.zip\n", ""},
+		{"не совпадает", otherSum + "  asset.zip\n", "не совпадает"},
+		{"нет файла в списке", goodSum + "  other.zip\n", "нет файла asset.zip"},
+		{"некорректная сумма", "xyz  asset.zip\n", "некорректная контрольная сумма"},
+		{"нет файла контрольных сумм", "", "нет файла контрольных сумм"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.checksums))
+			}))
+			defer server.Close()
+
+			var checksums *GitHubAsset
+			if tt.checksums != "" {
+				checksums = &GitHubAsset{Name: "checksums.txt", BrowserDownloadURL: server.URL}
+			}
+			err := VerifyAssetChecksum(context.Background(), checksums, "asset.zip", path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("VerifyAssetChecksum() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VerifyAssetChecksum() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
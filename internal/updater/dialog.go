@@ -7,11 +7,21 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 )
 
 // ShowUpdateDialog показывает диалоговое окно с информацией об обновлении
-func ShowUpdateDialog(window fyne.Window, info *ReleaseInfo) {
+// onSkip вызывается по кнопке "Пропустить эту версию"
+func ShowUpdateDialog(window fyne.Window, info *ReleaseInfo, onSkip func()) {
+	ShowInstallableUpdateDialog(window, info, nil, onSkip)
+}
+
+// ShowInstallableUpdateDialog показывает диалог обновления с кнопкой автоматической установки
+// onInstall вызывается по кнопке "Установить и перезапустить"; при nil или отсутствии
+// сборки для текущей платформы кнопка не показывается. onSkip вызывается по кнопке
+// "Пропустить эту версию"; при nil кнопка не показывается
+func ShowInstallableUpdateDialog(window fyne.Window, info *ReleaseInfo, onInstall, onSkip func()) {
 	if info == nil || !info.IsNewer {
 		return
 	}
@@ -25,29 +35,54 @@ func ShowUpdateDialog(window fyne.Window, info *ReleaseInfo) {
 	})
 	downloadButton.Importance = widget.HighImportance
 
+	var d dialog.Dialog
 	laterButton := widget.NewButton("Напомнить позже", func() {
-		// Просто закрываем диалог
+		d.Hide()
 	})
 
-	skipButton := widget.NewButton("Пропустить эту версию", func() {
-		// TODO: В будущем можно добавить сохранение пропущенной версии
-	})
+	title := "🎉 Доступно обновление"
+	if info.Prerelease {
+		title = "🧪 Доступна тестовая версия"
+	}
+
+	buttons := container.NewGridWithColumns(2,
+		downloadButton,
+		laterButton,
+	)
+
+	// Пропущенная версия больше не предлагается до выхода следующей
+	if onSkip != nil {
+		skipButton := widget.NewButton("Пропустить эту версию", func() {
+			d.Hide()
+			onSkip()
+		})
+		buttons.Objects = append(buttons.Objects, skipButton)
+		buttons.Layout = layout.NewGridLayoutWithColumns(len(buttons.Objects))
+	}
 
 	// Создаем кастомный диалог
-	d := dialog.NewCustom(
-		"🎉 Доступно обновление",
+	d = dialog.NewCustom(
+		title,
 		"Закрыть",
 		container.NewVBox(
 			content,
-			container.NewGridWithColumns(3,
-				downloadButton,
-				laterButton,
-				skipButton,
-			),
+			buttons,
 		),
 		window,
 	)
 
+	// Кнопка автоматической установки
+	if onInstall != nil && info.Asset != nil {
+		installButton := widget.NewButton("Установить и перезапустить", func() {
+			d.Hide()
+			onInstall()
+		})
+		installButton.Importance = widget.HighImportance
+		downloadButton.Importance = widget.MediumImportance
+		buttons.Objects = append([]fyne.CanvasObject{installButton}, buttons.Objects...)
+		buttons.Layout = layout.NewGridLayoutWithColumns(len(buttons.Objects))
+	}
+
 	d.Resize(fyne.NewSize(600, 400))
 	d.Show()
 }
@@ -55,8 +90,12 @@ func ShowUpdateDialog(window fyne.Window, info *ReleaseInfo) {
 // createUpdateContent создает содержимое диалога с информацией об обновлении
 func createUpdateContent(info *ReleaseInfo) fyne.CanvasObject {
 	// Заголовок с версией
+	versionText := fmt.Sprintf("Версия %s", info.Version)
+	if info.Prerelease {
+		versionText += " (pre-release)"
+	}
 	versionLabel := widget.NewLabelWithStyle(
-		fmt.Sprintf("Версия %s", info.Version),
+		versionText,
 		fyne.TextAlignCenter,
 		fyne.TextStyle{Bold: true},
 	)
@@ -67,6 +106,13 @@ func createUpdateContent(info *ReleaseInfo) fyne.CanvasObject {
 	)
 	dateLabel.Alignment = fyne.TextAlignCenter
 
+	// Количество пропущенных версий
+	var versionsBehindLabel *widget.Label
+	if info.VersionsBehind > 1 {
+		versionsBehindLabel = widget.NewLabel(NewVersionsLabel(info.VersionsBehind))
+		versionsBehindLabel.Alignment = fyne.TextAlignCenter
+	}
+
 	// Описание изменений
 	changelogLabel := widget.NewLabel("Что нового:")
 	changelogLabel.TextStyle = fyne.TextStyle{Bold: true}
@@ -82,13 +128,36 @@ func createUpdateContent(info *ReleaseInfo) fyne.CanvasObject {
 	changelogScroll.SetMinSize(fyne.NewSize(550, 200))
 
 	// Собираем все вместе
-	return container.NewVBox(
+	content := container.NewVBox(
 		versionLabel,
 		dateLabel,
-		widget.NewSeparator(),
-		changelogLabel,
-		changelogScroll,
 	)
+	if versionsBehindLabel != nil {
+		content.Add(versionsBehindLabel)
+	}
+
+	// Размер сборки для текущей платформы
+	if info.Asset != nil {
+		if size := FormatAssetSize(info.Asset.Size); size != "" {
+			sizeLabel := widget.NewLabel(fmt.Sprintf("Размер загрузки: %s", size))
+			sizeLabel.Alignment = fyne.TextAlignCenter
+			content.Add(sizeLabel)
+		}
+	}
+
+	// Предупреждение для тестовых версий
+	if info.Prerelease {
+		prereleaseLabel := widget.NewLabel("⚠️ Это тестовая версия: возможны ошибки и незавершенные функции")
+		prereleaseLabel.Alignment = fyne.TextAlignCenter
+		prereleaseLabel.Wrapping = fyne.TextWrapWord
+		content.Add(prereleaseLabel)
+	}
+
+	content.Add(widget.NewSeparator())
+	content.Add(changelogLabel)
+	content.Add(changelogScroll)
+
+	return content
 }
 
 // openURL открывает URL в браузере по умолчанию
@@ -0,0 +1,289 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// platformKeywords ключевые слова в именах файлов релиза для каждой ОС
+var platformKeywords = map[string][]string{
+	"windows": {"win", "windows"},
+	"darwin":  {"mac", "macos", "darwin", "osx"},
+	"linux":   {"linux"},
+}
+
+// archKeywords ключевые слова в именах файлов релиза для каждой архитектуры
+var archKeywords = map[string][]string{
+	"amd64": {"amd64", "x64", "x86_64"},
+	"arm64": {"arm64", "aarch64"},
+	"386":   {"386", "x86"},
+}
+
+// SelectAsset выбирает файл релиза для указанной ОС и архитектуры
+// Файл должен содержать в имени признак ОС; совпадение архитектуры повышает приоритет.
+// Возвращает nil, если подходящего файла нет
+func SelectAsset(assets []GitHubAsset, goos, goarch string) *GitHubAsset {
+	var best *GitHubAsset
+	bestScore := 0
+
+	for i := range assets {
+		name := strings.ToLower(assets[i].Name)
+
+		score := 0
+		if containsAny(name, platformKeywords[goos]) {
+			score = 1
+			if containsAny(name, archKeywords[goarch]) {
+				score = 2
+			}
+		}
+
+		if score > bestScore {
+			best = &assets[i]
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
+// containsAny проверяет, содержит ли строка хотя бы одно из ключевых слов
+func containsAny(s string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(s, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// DownloadProgress получает количество загруженных байт и общий размер файла
+// total равен 0, если размер неизвестен
+type DownloadProgress func(done, total int64)
+
+// downloadTimeout наибольшее время загрузки одного файла обновления
+const downloadTimeout = 10 * time.Minute
+
+// downloadClient HTTP клиент загрузки файлов обновления
+// В отличие от http.DefaultClient ограничивает и ожидание ответа сервера, и всю загрузку
+var downloadClient = newDownloadClient()
+
+// newDownloadClient создает клиент загрузки с таймаутами
+func newDownloadClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = requestTimeout
+	return &http.Client{Timeout: downloadTimeout, Transport: transport}
+}
+
+// partSuffix суффикс недокачанного файла, с которого загрузка продолжается при повторе
+const partSuffix = ".part"
+
+// validatorSuffix суффикс файла рядом с недокачанным, хранящего ETag или Last-Modified его загрузки
+// Без него докачка невозможна: нельзя проверить, что на сервере тот же файл
+const validatorSuffix = ".part.validator"
+
+// DownloadDir возвращает директорию пользователя для загрузки обновлений и создает ее
+// Директория постоянная (~/.excel-merger/updates), чтобы прерванную загрузку можно было продолжить,
+// и доступна только пользователю: недокачанный файл не может подменить другой пользователь системы
+func DownloadDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		// Без домашней директории докачка невозможна, но загрузка остается закрытой для других
+		dir, err := os.MkdirTemp("", "excel-merger-update-")
+		if err != nil {
+			return "", fmt.Errorf("не удалось создать временную директорию: %w", err)
+		}
+		return dir, nil
+	}
+
+	dir := filepath.Join(home, ".excel-merger", "updates")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("не удалось создать директорию загрузки обновлений: %w", err)
+	}
+	return dir, nil
+}
+
+// DownloadAsset загружает файл релиза в destPath
+// Время загрузки ограничивается только контекстом
+func DownloadAsset(ctx context.Context, asset *GitHubAsset, destPath string) error {
+	return DownloadAssetWithProgress(ctx, asset, destPath, nil)
+}
+
+// DownloadAssetWithProgress загружает файл релиза в destPath, сообщая о ходе загрузки в onProgress
+// Данные пишутся в destPath+".part" и переименовываются в destPath после проверки размера.
+// Прерванная загрузка (отмена контекста, обрыв сети) оставляет недокачанный файл, и следующий
+// вызов продолжает ее запросом Range с If-Range (ETag или Last-Modified первой загрузки).
+// Если файл на сервере заменен, сервер не знает валидатора или не поддерживает докачку,
+// файл загружается заново: начало старого файла не склеивается с концом нового
+func DownloadAssetWithProgress(ctx context.Context, asset *GitHubAsset, destPath string, onProgress DownloadProgress) error {
+	if asset == nil || asset.BrowserDownloadURL == "" {
+		return fmt.Errorf("файл обновления для текущей платформы не найден")
+	}
+
+	// Прежний файл не должен выглядеть результатом этой загрузки, если она не удастся
+	os.Remove(destPath)
+
+	partPath := destPath + partSuffix
+	validatorPath := destPath + validatorSuffix
+	discardPart := func() {
+		os.Remove(partPath)
+		os.Remove(validatorPath)
+	}
+
+	offset := int64(0)
+	validator := ""
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+		if data, err := os.ReadFile(validatorPath); err == nil {
+			validator = strings.TrimSpace(string(data))
+		}
+	}
+	if validator == "" || asset.Size > 0 && offset > asset.Size {
+		discardPart()
+		offset = 0
+	}
+
+	n, err := downloadPart(ctx, asset, partPath, offset, validator, onProgress)
+	if errors.Is(err, errRangeNotSatisfiable) {
+		// Недокачанный файл не совпадает с файлом на сервере - загружаем заново
+		discardPart()
+		n, err = downloadPart(ctx, asset, partPath, 0, "", onProgress)
+	}
+	if err != nil {
+		return err
+	}
+
+	if asset.Size > 0 && n != asset.Size {
+		discardPart()
+		return fmt.Errorf("загружено %d байт из %d, файл обновления поврежден", n, asset.Size)
+	}
+
+	if err := os.Rename(partPath, destPath); err != nil {
+		discardPart()
+		return fmt.Errorf("не удалось сохранить файл обновления: %w", err)
+	}
+	os.Remove(validatorPath)
+	return nil
+}
+
+// responseValidator возвращает валидатор ответа для If-Range: строгий ETag или Last-Modified
+// Слабый ETag (W/"...") в If-Range не допускается
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// errRangeNotSatisfiable сервер отклонил продолжение загрузки с указанной позиции
+var errRangeNotSatisfiable = errors.New("сервер отклонил продолжение загрузки")
+
+// downloadPart загружает файл в partPath, продолжая с позиции offset, если она больше нуля
+// Продолжение запрашивается условно: If-Range с validator первой загрузки. Ответ 200 означает,
+// что файл на сервере изменился или сервер не поддерживает докачку: файл перезаписывается с начала.
+// Валидатор новой загрузки сохраняется рядом с partPath для следующего продолжения.
+// Возвращает размер файла после загрузки
+func downloadPart(ctx context.Context, asset *GitHubAsset, partPath string, offset int64, validator string, onProgress DownloadProgress) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.BrowserDownloadURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("User-Agent", "Excel-Merger-Updater")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+	}
+
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return 0, classifyNetworkError(fmt.Errorf("ошибка загрузки обновления: %w", err))
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+		// Без валидатора следующая попытка начнет загрузку заново
+		validatorPath := strings.TrimSuffix(partPath, partSuffix) + validatorSuffix
+		os.Remove(validatorPath)
+		if validator := responseValidator(resp); validator != "" {
+			os.WriteFile(validatorPath, []byte(validator), 0o600)
+		}
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			return 0, errRangeNotSatisfiable
+		}
+		flags = os.O_WRONLY | os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// Файл уже загружен полностью
+		if offset == asset.Size {
+			return offset, nil
+		}
+		return 0, errRangeNotSatisfiable
+	default:
+		return 0, &StatusError{StatusCode: resp.StatusCode, Body: resp.Status}
+	}
+
+	out, err := os.OpenFile(partPath, flags, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось создать файл: %w", err)
+	}
+
+	var dst io.Writer = out
+	if onProgress != nil {
+		onProgress(offset, asset.Size)
+		dst = &progressWriter{w: out, done: offset, total: asset.Size, onProgress: onProgress}
+	}
+
+	n, err := io.Copy(dst, resp.Body)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, fmt.Errorf("загрузка обновления прервана: %w", ctxErr)
+		}
+		return 0, classifyNetworkError(fmt.Errorf("ошибка загрузки обновления: %w", err))
+	}
+
+	return offset + n, nil
+}
+
+// progressWriter передает в onProgress количество записанных байт
+type progressWriter struct {
+	w          io.Writer
+	done       int64
+	total      int64
+	onProgress DownloadProgress
+}
+
+// Write записывает данные и сообщает о ходе загрузки
+func (p *progressWriter) Write(data []byte) (int, error) {
+	n, err := p.w.Write(data)
+	p.done += int64(n)
+	p.onProgress(p.done, p.total)
+	return n, err
+}
+
+// FormatAssetSize форматирует размер файла обновления для пользователя: "850 КБ", "12,4 МБ"
+// Для неизвестного размера возвращает пустую строку
+func FormatAssetSize(bytes int64) string {
+	const kb, mb = 1 << 10, 1 << 20
+	switch {
+	case bytes <= 0:
+		return ""
+	case bytes < kb:
+		return fmt.Sprintf("%d Б", bytes)
+	case bytes < mb:
+		return fmt.Sprintf("%d КБ", (bytes+kb/2)/kb)
+	default:
+		return strings.Replace(fmt.Sprintf("%.1f МБ", float64(bytes)/mb), ".", ",", 1)
+	}
+}
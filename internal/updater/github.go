@@ -6,23 +6,42 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	githubAPIURL = "https://api.github.com/repos/%s/%s/releases/latest"
-	requestTimeout = 10 * time.Second
+	githubAPIBaseURL     = "https://api.github.com"
+	githubAPIURL         = "%s/repos/%s/%s/releases/latest"
+	githubReleasesAPIURL = "%s/repos/%s/%s/releases?per_page=100"
+	requestTimeout       = 10 * time.Second
+
+	// githubTokenEnv переменная окружения с токеном для авторизованных запросов
+	githubTokenEnv = "GITHUB_TOKEN"
 )
 
 // GitHubRelease представляет информацию о релизе из GitHub API
 type GitHubRelease struct {
-	TagName     string    `json:"tag_name"`
-	Name        string    `json:"name"`
-	Body        string    `json:"body"`
-	HTMLURL     string    `json:"html_url"`
-	PublishedAt time.Time `json:"published_at"`
-	Draft       bool      `json:"draft"`
-	Prerelease  bool      `json:"prerelease"`
+	TagName     string        `json:"tag_name"`
+	Name        string        `json:"name"`
+	Body        string        `json:"body"`
+	HTMLURL     string        `json:"html_url"`
+	PublishedAt time.Time     `json:"published_at"`
+	Draft       bool          `json:"draft"`
+	Prerelease  bool          `json:"prerelease"`
+	Assets      []GitHubAsset `json:"assets"`
+}
+
+// GitHubAsset файл, прикрепленный к релизу
+type GitHubAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
 }
 
 // ReleaseInfo информация об обновлении для отображения
@@ -32,56 +51,230 @@ type ReleaseInfo struct {
 	DownloadURL string
 	Changelog   string
 	IsNewer     bool
+	Prerelease  bool // Тестовая версия (alpha, beta, rc)
+
+	VersionsBehind int          // Количество вышедших версий новее текущей
+	Asset          *GitHubAsset // Сборка для текущей платформы (nil, если не найдена)
+	Checksums      *GitHubAsset // Файл контрольных сумм SHA-256 релиза (nil, если не опубликован)
 }
 
 // GitHubClient клиент для работы с GitHub API
 type GitHubClient struct {
 	owner      string
 	repo       string
+	baseURL    string
+	token      string
 	httpClient *http.Client
+	retryDelay time.Duration
+
+	// Кеш ответов (ETag) и состояние лимита запросов
+	mu        sync.Mutex
+	cachePath string
+	cache     *responseCache
 }
 
 // NewGitHubClient создает новый клиент для GitHub API
+// Если задана переменная окружения GITHUB_TOKEN, запросы выполняются с авторизацией
 func NewGitHubClient(owner, repo string) *GitHubClient {
 	return &GitHubClient{
-		owner: owner,
-		repo:  repo,
+		owner:   owner,
+		repo:    repo,
+		baseURL: githubAPIBaseURL,
+		token:   os.Getenv(githubTokenEnv),
 		httpClient: &http.Client{
 			Timeout: requestTimeout,
 		},
+		retryDelay: initialRetryDelay,
+	}
+}
+
+// SetSource переключает клиент на другой источник обновлений (например, GitHub Enterprise)
+// Пустые поля source оставляют текущие значения. При ошибке настройки клиента не меняются
+func (gc *GitHubClient) SetSource(source Source) error {
+	baseURL := gc.baseURL
+	if strings.TrimSpace(source.APIBaseURL) != "" {
+		normalized, err := NormalizeAPIBaseURL(source.APIBaseURL)
+		if err != nil {
+			return err
+		}
+		baseURL = normalized
 	}
+
+	httpClient := gc.httpClient
+	if source.CABundlePath != "" {
+		client, err := newHTTPClient(source.CABundlePath)
+		if err != nil {
+			return err
+		}
+		httpClient = client
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	gc.baseURL = baseURL
+	gc.httpClient = httpClient
+	if source.Owner != "" {
+		gc.owner = source.Owner
+	}
+	if source.Repo != "" {
+		gc.repo = source.Repo
+	}
+
+	return nil
+}
+
+// SetCacheDir задает директорию для кеша ответов GitHub API
+// Пустая строка отключает сохранение кеша на диск
+func (gc *GitHubClient) SetCacheDir(dir string) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	gc.cachePath = ""
+	if dir != "" {
+		gc.cachePath = filepath.Join(dir, githubCacheFile)
+	}
+	gc.cache = nil
 }
 
 // GetLatestRelease получает информацию о последнем релизе из GitHub
 func (gc *GitHubClient) GetLatestRelease(ctx context.Context) (*GitHubRelease, error) {
-	url := fmt.Sprintf(githubAPIURL, gc.owner, gc.repo)
+	url := gc.apiURL(githubAPIURL)
+
+	var release GitHubRelease
+	if err := gc.getJSON(ctx, url, &release); err != nil {
+		return nil, err
+	}
+
+	return &release, nil
+}
+
+// GetReleases получает список релизов из GitHub (включая pre-release и черновики)
+func (gc *GitHubClient) GetReleases(ctx context.Context) ([]GitHubRelease, error) {
+	url := gc.apiURL(githubReleasesAPIURL)
+
+	var releases []GitHubRelease
+	if err := gc.getJSON(ctx, url, &releases); err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// apiURL формирует адрес запроса к API для текущего источника обновлений
+func (gc *GitHubClient) apiURL(format string) string {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	return fmt.Sprintf(format, gc.baseURL, gc.owner, gc.repo)
+}
+
+// getJSON выполняет GET запрос к GitHub API и декодирует JSON ответ в target
+// Временные сетевые ошибки и ответы 5xx повторяются с экспоненциальной задержкой
+func (gc *GitHubClient) getJSON(ctx context.Context, url string, target interface{}) error {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	return withRetry(ctx, maxRequestAttempts, gc.retryDelay, func() error {
+		return gc.doGetJSON(ctx, url, target)
+	})
+}
+
+// doGetJSON выполняет одну попытку запроса
+// Использует ETag из кеша: ответ 304 означает, что данные не изменились,
+// и target заполняется сохраненным телом ответа
+func (gc *GitHubClient) doGetJSON(ctx context.Context, url string, target interface{}) error {
+	cache := gc.loadCache()
+
+	// Пока лимит запросов не сброшен, к API не обращаемся
+	if time.Now().Before(cache.RateLimitReset) {
+		return &RateLimitError{Reset: cache.RateLimitReset}
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+		return fmt.Errorf("ошибка создания запроса: %w", err)
 	}
 
 	// Устанавливаем заголовки для GitHub API
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "Excel-Merger-Updater")
+	if gc.token != "" {
+		req.Header.Set("Authorization", "Bearer "+gc.token)
+	}
+
+	cached, hasCached := cache.Responses[url]
+	if hasCached && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
 
 	resp, err := gc.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
+		return fmt.Errorf("ошибка выполнения запроса: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCached:
+		if err := json.Unmarshal(cached.Body, target); err != nil {
+			return fmt.Errorf("ошибка парсинга кешированного ответа: %w", err)
+		}
+		return nil
+
+	case isRateLimited(resp):
+		cache.RateLimitReset = rateLimitReset(resp)
+		gc.saveCache()
+		return &RateLimitError{Reset: cache.RateLimitReset}
+
+	case resp.StatusCode != http.StatusOK:
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GitHub API вернул статус %d: %s", resp.StatusCode, string(body))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("ошибка парсинга ответа: %w", err)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения ответа: %w", err)
 	}
 
-	return &release, nil
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("ошибка парсинга ответа: %w", err)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		cache.Responses[url] = cachedResponse{ETag: etag, Body: body}
+		gc.saveCache()
+	}
+
+	return nil
+}
+
+// loadCache возвращает кеш ответов, загружая его с диска при первом обращении
+func (gc *GitHubClient) loadCache() *responseCache {
+	if gc.cache == nil {
+		gc.cache = loadResponseCache(gc.cachePath)
+	}
+	return gc.cache
+}
+
+// saveCache сохраняет кеш на диск; ошибки записи не мешают проверке обновлений
+func (gc *GitHubClient) saveCache() {
+	_ = gc.cache.save(gc.cachePath)
+}
+
+// isRateLimited проверяет, что ответ сообщает об исчерпании лимита запросов
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	return resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// rateLimitReset возвращает время сброса лимита из заголовка X-RateLimit-Reset
+// Если заголовок отсутствует или некорректен, используется час от текущего момента
+func rateLimitReset(resp *http.Response) time.Time {
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+		return time.Unix(reset, 0)
+	}
+	return time.Now().Add(time.Hour)
 }
 
 // ToReleaseInfo преобразует GitHubRelease в ReleaseInfo
@@ -92,5 +285,8 @@ func (r *GitHubRelease) ToReleaseInfo() *ReleaseInfo {
 		DownloadURL: r.HTMLURL,
 		Changelog:   r.Body,
 		IsNewer:     false, // Будет установлено при сравнении версий
+		Prerelease:  r.Prerelease,
+		Asset:       SelectAsset(r.Assets, runtime.GOOS, runtime.GOARCH),
+		Checksums:   SelectChecksumAsset(r.Assets),
 	}
 }
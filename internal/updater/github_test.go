@@ -0,0 +1,237 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient создает клиент, направленный на тестовый сервер
+func newTestClient(t *testing.T, server *httptest.Server) *GitHubClient {
+	t.Helper()
+	client := NewGitHubClient("owner", "repo")
+	client.baseURL = server.URL
+	client.token = ""
+	client.SetCacheDir(t.TempDir())
+	return client
+}
+
+func TestGetLatestReleaseETagFlow(t *testing.T) {
+	const etag = `"abc123"`
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/repos/owner/repo/releases/latest" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(`{"tag_name":"v1.2.0","html_url":"https://example.com/v1.2.0"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	// 200: ответ сохраняется вместе с ETag
+	release, err := client.GetLatestRelease(context.Background())
+	if err != nil {
+		t.Fatalf("GetLatestRelease() error = %v", err)
+	}
+	if release.TagName != "v1.2.0" {
+		t.Errorf("TagName = %s, want v1.2.0", release.TagName)
+	}
+
+	// 304: используется кешированный ответ
+	release, err = client.GetLatestRelease(context.Background())
+	if err != nil {
+		t.Fatalf("GetLatestRelease() after 304 error = %v", err)
+	}
+	if release.TagName != "v1.2.0" {
+		t.Errorf("TagName from cache = %s, want v1.2.0", release.TagName)
+	}
+
+	// Кеш переживает перезапуск: новый клиент с той же директорией отправляет ETag
+	restarted := NewGitHubClient("owner", "repo")
+	restarted.baseURL = server.URL
+	restarted.cachePath = client.cachePath
+	if _, err := restarted.GetLatestRelease(context.Background()); err != nil {
+		t.Fatalf("GetLatestRelease() with disk cache error = %v", err)
+	}
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestGetLatestReleaseRateLimited(t *testing.T) {
+	reset := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	_, err := client.GetLatestRelease(context.Background())
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("error = %v, want ErrRateLimited", err)
+	}
+
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) || !rateErr.Reset.Equal(reset) {
+		t.Errorf("reset = %v, want %v", rateErr, reset)
+	}
+
+	// До сброса лимита запросы к API не выполняются
+	if _, err := client.GetReleases(context.Background()); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("second call error = %v, want ErrRateLimited", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestGetLatestReleaseForbiddenWithoutRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	_, err := client.GetLatestRelease(context.Background())
+	if err == nil || errors.Is(err, ErrRateLimited) {
+		t.Errorf("error = %v, want plain status error", err)
+	}
+}
+
+func TestGitHubClientSendsToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	client.token = "secret"
+
+	if _, err := client.GetReleases(context.Background()); err != nil {
+		t.Fatalf("GetReleases() error = %v", err)
+	}
+}
+
+// flakyServer возвращает 503 первые failures запросов, затем успешный ответ
+func flakyServer(failures int32, requests *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"tag_name":"v2.0.0"}`))
+	}))
+}
+
+func TestGetLatestReleaseRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		wantErr      bool
+		wantRequests int32
+	}{
+		{"Succeeds first time", 0, false, 1},
+		{"Succeeds after one failure", 1, false, 2},
+		{"Succeeds on last attempt", 2, false, 3},
+		{"Gives up after max attempts", 5, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := flakyServer(tt.failures, &requests)
+			defer server.Close()
+
+			client := newTestClient(t, server)
+			client.retryDelay = time.Millisecond
+
+			release, err := client.GetLatestRelease(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && release.TagName != "v2.0.0" {
+				t.Errorf("TagName = %s, want v2.0.0", release.TagName)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestGetLatestReleaseDoesNotRetryClientErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	client.retryDelay = time.Millisecond
+
+	if _, err := client.GetLatestRelease(context.Background()); err == nil {
+		t.Fatal("expected error for 404")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestGetLatestReleaseRespectsContextDeadline(t *testing.T) {
+	var requests atomic.Int32
+	server := flakyServer(100, &requests)
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	client.retryDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.GetLatestRelease(ctx); err == nil {
+		t.Fatal("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("retry ignored context deadline, took %v", elapsed)
+	}
+}
+
+func TestGetLatestReleaseOffline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	client := newTestClient(t, server)
+	client.retryDelay = time.Millisecond
+	server.Close() // соединение будет отклонено
+
+	_, err := client.GetLatestRelease(context.Background())
+	if !errors.Is(err, ErrOffline) {
+		t.Errorf("error = %v, want ErrOffline", err)
+	}
+}
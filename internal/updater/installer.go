@@ -0,0 +1,263 @@
+package updater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Параметры установки обновления
+const (
+	backupSuffix        = ".old"           // Суффикс резервной копии заменяемого исполняемого файла
+	versionCheckTimeout = 10 * time.Second // Таймаут проверки нового бинарника через --version
+	maxBinarySize       = 512 << 20        // Ограничение размера распаковываемого бинарника
+)
+
+// ErrUnsupportedArchive возвращается для форматов архивов, которые не умеем распаковывать
+var ErrUnsupportedArchive = errors.New("формат архива не поддерживается")
+
+// ApplyUpdate устанавливает новую версию из загруженного архива:
+// распаковывает бинарник, заменяет им исполняемый файл и проверяет его запуском с --version.
+// Если проверка не прошла, восстанавливает прежний исполняемый файл
+func ApplyUpdate(ctx context.Context, archivePath, exePath, expectedVersion string) error {
+	// Распаковываем рядом с исполняемым файлом, чтобы замена выполнялась переименованием
+	stagingDir, err := os.MkdirTemp(filepath.Dir(exePath), ".update-")
+	if err != nil {
+		return fmt.Errorf("не удалось создать временную директорию: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	newBinary, err := ExtractBinary(archivePath, filepath.Base(exePath), stagingDir)
+	if err != nil {
+		return err
+	}
+
+	backupPath, err := SwapExecutable(exePath, newBinary)
+	if err != nil {
+		return err
+	}
+
+	if err := VerifyExecutable(ctx, exePath, expectedVersion); err != nil {
+		if rbErr := Rollback(exePath, backupPath); rbErr != nil {
+			return fmt.Errorf("новая версия не прошла проверку (%v), откат не удался: %w", err, rbErr)
+		}
+		return fmt.Errorf("новая версия не прошла проверку, установка отменена: %w", err)
+	}
+
+	return nil
+}
+
+// ExtractBinary извлекает исполняемый файл binaryName из архива в destDir
+// Поддерживаются .zip, .tar.gz/.tgz и сам исполняемый файл без архива.
+// Проверяет целостность архива (контрольные суммы zip/gzip) при чтении
+func ExtractBinary(archivePath, binaryName, destDir string) (string, error) {
+	destPath := filepath.Join(destDir, binaryName)
+	lower := strings.ToLower(archivePath)
+
+	var err error
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		err = extractFromZip(archivePath, binaryName, destPath)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		err = extractFromTarGz(archivePath, binaryName, destPath)
+	case strings.HasSuffix(lower, ".tar.xz"), strings.HasSuffix(lower, ".dmg"):
+		err = fmt.Errorf("%w: %s", ErrUnsupportedArchive, filepath.Base(archivePath))
+	default:
+		err = copyFile(archivePath, destPath)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.Chmod(destPath, 0755); err != nil {
+		return "", fmt.Errorf("не удалось установить права на исполнение: %w", err)
+	}
+
+	return destPath, nil
+}
+
+// SwapExecutable заменяет exePath файлом newBinary по схеме
+// "переименовать старый — переместить новый". Возвращает путь к резервной копии.
+// Переименование работает и для запущенного файла в Windows
+func SwapExecutable(exePath, newBinary string) (string, error) {
+	backupPath := exePath + backupSuffix
+	_ = os.Remove(backupPath)
+
+	if err := os.Rename(exePath, backupPath); err != nil {
+		return "", fmt.Errorf("не удалось переименовать текущий исполняемый файл: %w", err)
+	}
+
+	if err := moveFile(newBinary, exePath); err != nil {
+		if rbErr := os.Rename(backupPath, exePath); rbErr != nil {
+			return "", fmt.Errorf("не удалось установить новый файл (%v) и восстановить старый: %w", err, rbErr)
+		}
+		return "", fmt.Errorf("не удалось установить новый исполняемый файл: %w", err)
+	}
+
+	return backupPath, nil
+}
+
+// Rollback восстанавливает исполняемый файл из резервной копии
+func Rollback(exePath, backupPath string) error {
+	_ = os.Remove(exePath)
+	if err := os.Rename(backupPath, exePath); err != nil {
+		return fmt.Errorf("не удалось восстановить исполняемый файл: %w", err)
+	}
+	return nil
+}
+
+// VerifyExecutable запускает exePath с флагом --version и проверяет,
+// что вывод содержит ожидаемую версию
+func VerifyExecutable(ctx context.Context, exePath, expectedVersion string) error {
+	ctx, cancel := context.WithTimeout(ctx, versionCheckTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, exePath, "--version")
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("не удалось запустить новую версию: %w", err)
+	}
+
+	want := strings.TrimPrefix(strings.TrimPrefix(expectedVersion, "v"), "V")
+	if want != "" && !strings.Contains(out.String(), want) {
+		return fmt.Errorf("ожидалась версия %s, получено: %s", want, strings.TrimSpace(out.String()))
+	}
+
+	return nil
+}
+
+// Relaunch запускает exePath с теми же аргументами, не дожидаясь завершения
+func Relaunch(exePath string, args []string) error {
+	cmd := exec.Command(exePath, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("не удалось перезапустить приложение: %w", err)
+	}
+	return cmd.Process.Release()
+}
+
+// CleanupOldBinary удаляет резервную копию, оставшуюся после предыдущего обновления
+func CleanupOldBinary(exePath string) error {
+	err := os.Remove(exePath + backupSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// extractFromZip извлекает файл binaryName из zip архива
+func extractFromZip(archivePath, binaryName, destPath string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("архив обновления поврежден: %w", err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || filepath.Base(f.Name) != binaryName {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("не удалось открыть файл в архиве: %w", err)
+		}
+		defer rc.Close()
+
+		// zip проверяет CRC32 при дочитывании файла до конца
+		return writeLimited(rc, destPath)
+	}
+
+	return fmt.Errorf("файл %s не найден в архиве обновления", binaryName)
+}
+
+// extractFromTarGz извлекает файл binaryName из tar.gz архива
+func extractFromTarGz(archivePath, binaryName, destPath string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть архив: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("архив обновления поврежден: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("архив обновления поврежден: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg || filepath.Base(header.Name) != binaryName {
+			continue
+		}
+
+		return writeLimited(tr, destPath)
+	}
+
+	return fmt.Errorf("файл %s не найден в архиве обновления", binaryName)
+}
+
+// writeLimited записывает содержимое r в файл, ограничивая размер maxBinarySize
+func writeLimited(r io.Reader, destPath string) error {
+	out, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл: %w", err)
+	}
+
+	n, err := io.Copy(out, io.LimitReader(r, maxBinarySize+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось распаковать файл: %w", err)
+	}
+	if n > maxBinarySize {
+		return fmt.Errorf("распакованный файл превышает допустимый размер")
+	}
+
+	return nil
+}
+
+// moveFile перемещает файл, копируя его, если переименование невозможно
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// copyFile копирует файл src в dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть файл: %w", err)
+	}
+	defer in.Close()
+
+	return writeLimited(in, dst)
+}
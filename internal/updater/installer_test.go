@@ -0,0 +1,417 @@
+package updater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dummyExecutable возвращает содержимое скрипта, печатающего указанную версию
+func dummyExecutable(version string) []byte {
+	return []byte("#!/bin/sh\necho " + version + "\n")
+}
+
+// skipWithoutShell пропускает тесты, запускающие shell-скрипты
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("тест использует shell-скрипты вместо исполняемых файлов")
+	}
+}
+
+// writeZip создает zip архив с одним файлом
+func writeZip(t *testing.T, path, name string, content []byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("excel-merger/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTarGz создает tar.gz архив с одним файлом
+func writeTarGz(t *testing.T, path, name string, content []byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	header := &tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(header); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractBinary(t *testing.T) {
+	dir := t.TempDir()
+	content := dummyExecutable("2.0.0")
+
+	zipPath := filepath.Join(dir, "release.zip")
+	writeZip(t, zipPath, "excel-merger", content)
+	tarPath := filepath.Join(dir, "release.tar.gz")
+	writeTarGz(t, tarPath, "excel-merger", content)
+	rawPath := filepath.Join(dir, "excel-merger-linux-amd64")
+	if err := os.WriteFile(rawPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	corruptPath := filepath.Join(dir, "corrupt.zip")
+	if err := os.WriteFile(corruptPath, []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		archive string
+		binary  string
+		wantErr bool
+	}{
+		{"zip", zipPath, "excel-merger", false},
+		{"tar.gz", tarPath, "excel-merger", false},
+		{"raw binary", rawPath, "excel-merger", false},
+		{"binary missing in archive", zipPath, "other", true},
+		{"corrupt archive", corruptPath, "excel-merger", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractBinary(tt.archive, tt.binary, t.TempDir())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, err := os.ReadFile(got)
+			if err != nil || string(data) != string(content) {
+				t.Errorf("extracted content mismatch: %q, %v", data, err)
+			}
+		})
+	}
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := ExtractBinary(filepath.Join(dir, "release.tar.xz"), "excel-merger", t.TempDir())
+		if !errors.Is(err, ErrUnsupportedArchive) {
+			t.Errorf("error = %v, want ErrUnsupportedArchive", err)
+		}
+	})
+}
+
+func TestApplyUpdateSwapsExecutable(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+
+	exePath := filepath.Join(dir, "excel-merger")
+	if err := os.WriteFile(exePath, dummyExecutable("1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), "update.zip")
+	writeZip(t, archive, "excel-merger", dummyExecutable("2.0.0"))
+
+	if err := ApplyUpdate(context.Background(), archive, exePath, "v2.0.0"); err != nil {
+		t.Fatalf("ApplyUpdate() error = %v", err)
+	}
+
+	if err := VerifyExecutable(context.Background(), exePath, "2.0.0"); err != nil {
+		t.Errorf("new executable is not installed: %v", err)
+	}
+	backup, err := os.ReadFile(exePath + backupSuffix)
+	if err != nil || !strings.Contains(string(backup), "1.0.0") {
+		t.Errorf("backup of the old executable is missing: %v", err)
+	}
+
+	// Временные файлы распаковки не остаются рядом с исполняемым файлом
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("unexpected files left in install dir: %v", entries)
+	}
+
+	if err := CleanupOldBinary(exePath); err != nil {
+		t.Errorf("CleanupOldBinary() error = %v", err)
+	}
+	if _, err := os.Stat(exePath + backupSuffix); !os.IsNotExist(err) {
+		t.Error("backup must be removed by CleanupOldBinary")
+	}
+}
+
+func TestApplyUpdateRollsBackOnFailedSanityCheck(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+
+	exePath := filepath.Join(dir, "excel-merger")
+	if err := os.WriteFile(exePath, dummyExecutable("1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Новый бинарник сообщает неверную версию
+	archive := filepath.Join(t.TempDir(), "update.tar.gz")
+	writeTarGz(t, archive, "excel-merger", dummyExecutable("1.5.0"))
+
+	if err := ApplyUpdate(context.Background(), archive, exePath, "v2.0.0"); err == nil {
+		t.Fatal("expected sanity check failure")
+	}
+
+	if err := VerifyExecutable(context.Background(), exePath, "1.0.0"); err != nil {
+		t.Errorf("old executable must be restored: %v", err)
+	}
+	if _, err := os.Stat(exePath + backupSuffix); !os.IsNotExist(err) {
+		t.Error("backup must not remain after rollback")
+	}
+}
+
+func TestSelectAsset(t *testing.T) {
+	assets := []GitHubAsset{
+		{Name: "excel-merger.app.zip"},
+		{Name: "excel-merger-win-x64.exe"},
+		{Name: "excel-merger-linux-amd64.tar.gz"},
+		{Name: "excel-merger-linux-arm64.tar.gz"},
+		{Name: "checksums.txt"},
+	}
+
+	tests := []struct {
+		goos, goarch string
+		want         string
+	}{
+		{"windows", "amd64", "excel-merger-win-x64.exe"},
+		{"linux", "amd64", "excel-merger-linux-amd64.tar.gz"},
+		{"linux", "arm64", "excel-merger-linux-arm64.tar.gz"},
+		{"freebsd", "amd64", ""},
+	}
+
+	for _, tt := range tests {
+		got := SelectAsset(assets, tt.goos, tt.goarch)
+		name := ""
+		if got != nil {
+			name = got.Name
+		}
+		if name != tt.want {
+			t.Errorf("SelectAsset(%s/%s) = %q, want %q", tt.goos, tt.goarch, name, tt.want)
+		}
+	}
+}
+
+func TestDownloadAsset(t *testing.T) {
+	payload := strings.Repeat("x", 1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "asset.zip")
+	asset := &GitHubAsset{Name: "asset.zip", BrowserDownloadURL: server.URL, Size: int64(len(payload))}
+	if err := DownloadAsset(context.Background(), asset, dest); err != nil {
+		t.Fatalf("DownloadAsset() error = %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != payload {
+		t.Error("downloaded content mismatch")
+	}
+
+	// Несовпадение размера означает поврежденную загрузку
+	asset.Size = 2048
+	if err := DownloadAsset(context.Background(), asset, dest); err == nil {
+		t.Error("expected size mismatch error")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("incomplete download must be removed")
+	}
+}
+
+// rangeServerETag ETag файла, который отдает rangeServer
+const rangeServerETag = `"v2"`
+
+// rangeServer отдает payload; при acceptRanges поддерживает запросы Range и If-Range
+func rangeServer(payload string, acceptRanges bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if acceptRanges {
+			w.Header().Set("ETag", rangeServerETag)
+			http.ServeContent(w, r, "asset.zip", time.Time{}, strings.NewReader(payload))
+			return
+		}
+		w.Write([]byte(payload))
+	}))
+}
+
+func TestDownloadAssetWithProgress(t *testing.T) {
+	payload := strings.Repeat("0123456789", 10000)
+
+	tests := []struct {
+		name         string
+		acceptRanges bool
+		partial      string // Содержимое недокачанного файла перед загрузкой
+		validator    string // ETag загрузки недокачанного файла
+		wantFirst    int64  // Первое значение done
+	}{
+		{name: "загрузка с нуля", acceptRanges: true, wantFirst: 0},
+		{name: "докачка", acceptRanges: true, partial: payload[:4000], validator: rangeServerETag, wantFirst: 4000},
+		{name: "сервер без докачки", acceptRanges: false, partial: payload[:4000], validator: rangeServerETag, wantFirst: 0},
+		{name: "недокачанный файл больше файла сервера", acceptRanges: true, partial: payload + "лишнее", validator: rangeServerETag, wantFirst: 0},
+		// Начало прежней версии файла не должно склеиваться с концом новой
+		{name: "файл на сервере заменен", acceptRanges: true, partial: strings.Repeat("z", 4000), validator: `"v1"`, wantFirst: 0},
+		{name: "недокачанный файл без валидатора", acceptRanges: true, partial: strings.Repeat("z", 4000), wantFirst: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := rangeServer(payload, tt.acceptRanges)
+			defer server.Close()
+
+			dest := filepath.Join(t.TempDir(), "asset.zip")
+			if tt.partial != "" {
+				if err := os.WriteFile(dest+partSuffix, []byte(tt.partial), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.validator != "" {
+				if err := os.WriteFile(dest+validatorSuffix, []byte(tt.validator), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var calls [][2]int64
+			asset := &GitHubAsset{Name: "asset.zip", BrowserDownloadURL: server.URL, Size: int64(len(payload))}
+			err := DownloadAssetWithProgress(context.Background(), asset, dest, func(done, total int64) {
+				calls = append(calls, [2]int64{done, total})
+			})
+			if err != nil {
+				t.Fatalf("DownloadAssetWithProgress() error = %v", err)
+			}
+
+			if data, _ := os.ReadFile(dest); string(data) != payload {
+				t.Error("downloaded content mismatch")
+			}
+			if _, err := os.Stat(dest + partSuffix); !os.IsNotExist(err) {
+				t.Error("недокачанный файл должен быть переименован")
+			}
+			if _, err := os.Stat(dest + validatorSuffix); !os.IsNotExist(err) {
+				t.Error("валидатор загрузки должен быть удален")
+			}
+
+			if len(calls) < 2 {
+				t.Fatalf("progress calls = %v", calls)
+			}
+			if calls[0][0] != tt.wantFirst {
+				t.Errorf("first done = %d, want %d", calls[0][0], tt.wantFirst)
+			}
+			last := calls[len(calls)-1]
+			if last != [2]int64{asset.Size, asset.Size} {
+				t.Errorf("last progress = %v, want %d of %d", last, asset.Size, asset.Size)
+			}
+			for i := 1; i < len(calls); i++ {
+				if calls[i][0] < calls[i-1][0] {
+					t.Fatalf("progress decreased: %v", calls)
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("права доступа проверяются только в Unix")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir, err := DownloadDir()
+	if err != nil {
+		t.Fatalf("DownloadDir() error = %v", err)
+	}
+	if want := filepath.Join(home, ".excel-merger", "updates"); dir != want {
+		t.Errorf("DownloadDir() = %q, want %q", dir, want)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("директория загрузки не создана: %v", err)
+	}
+	if info.Mode().Perm() != 0o700 {
+		t.Errorf("права директории загрузки = %v, ожидалось 0700", info.Mode().Perm())
+	}
+}
+
+func TestDownloadAssetCancel(t *testing.T) {
+	payload := strings.Repeat("x", 64*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		w.Header().Set("ETag", rangeServerETag)
+		w.Write([]byte(payload[:1024]))
+		w.(http.Flusher).Flush()
+		// Остальное не отправляется до отмены загрузки
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dest := filepath.Join(t.TempDir(), "asset.zip")
+	asset := &GitHubAsset{Name: "asset.zip", BrowserDownloadURL: server.URL, Size: int64(len(payload))}
+	err := DownloadAssetWithProgress(ctx, asset, dest, func(done, total int64) {
+		if done >= 1024 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("DownloadAssetWithProgress() error = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("прерванная загрузка не должна создавать файл обновления")
+	}
+	// Недокачанный файл остается для продолжения загрузки
+	if info, err := os.Stat(dest + partSuffix); err != nil || info.Size() != 1024 {
+		t.Errorf("недокачанный файл не сохранен: %v", err)
+	}
+	if data, err := os.ReadFile(dest + validatorSuffix); err != nil || string(data) != rangeServerETag {
+		t.Errorf("валидатор загрузки = %q, %v; ожидался %s", data, err, rangeServerETag)
+	}
+}
+
+func TestFormatAssetSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, ""},
+		{512, "512 Б"},
+		{850 * 1024, "850 КБ"},
+		{13002342, "12,4 МБ"},
+	}
+	for _, tt := range tests {
+		if got := FormatAssetSize(tt.bytes); got != tt.want {
+			t.Errorf("FormatAssetSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
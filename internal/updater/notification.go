@@ -0,0 +1,63 @@
+package updater
+
+import "log/slog"
+
+// Способы уведомления о новой версии при автоматической проверке
+const (
+	NotifyDialog = "dialog" // Модальный диалог обновления
+	NotifyBanner = "banner" // Баннер вверху окна, диалог открывается по кнопке
+	NotifySilent = "silent" // Только запись в журнал
+)
+
+// UpdateNotifier отображает уведомления о новой версии
+type UpdateNotifier interface {
+	ShowUpdateDialog(info *ReleaseInfo)
+	ShowUpdateBanner(info *ReleaseInfo)
+}
+
+// NotificationPresenter выбирает способ уведомления о найденном обновлении
+type NotificationPresenter struct {
+	notifier UpdateNotifier
+	logger   *slog.Logger
+}
+
+// NewNotificationPresenter создает презентер уведомлений об обновлениях
+func NewNotificationPresenter(notifier UpdateNotifier, logger *slog.Logger) *NotificationPresenter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &NotificationPresenter{
+		notifier: notifier,
+		logger:   logger,
+	}
+}
+
+// Present уведомляет о найденном обновлении выбранным способом
+// Неизвестный способ трактуется как диалог, пропущенная пользователем версия
+// только записывается в журнал.
+// Возвращает использованный способ или пустую строку, если уведомлять не о чем
+func (p *NotificationPresenter) Present(info *ReleaseInfo, style, skippedVersion string) string {
+	if info == nil || !info.IsNewer {
+		return ""
+	}
+
+	if skippedVersion != "" && info.Version == skippedVersion {
+		p.logger.Info("Найдено обновление, версия пропущена пользователем", "new_version", info.Version)
+		return NotifySilent
+	}
+
+	switch style {
+	case NotifySilent:
+		p.logger.Info("Найдено обновление", "new_version", info.Version, "download_url", info.DownloadURL)
+	case NotifyBanner:
+		p.logger.Info("Найдено обновление, показываю баннер", "new_version", info.Version)
+		p.notifier.ShowUpdateBanner(info)
+	default:
+		style = NotifyDialog
+		p.logger.Info("Найдено обновление, показываю диалог", "new_version", info.Version)
+		p.notifier.ShowUpdateDialog(info)
+	}
+
+	return style
+}
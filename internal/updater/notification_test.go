@@ -0,0 +1,60 @@
+package updater
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// recordingNotifier запоминает показанные уведомления
+type recordingNotifier struct {
+	dialogs []string
+	banners []string
+}
+
+func (n *recordingNotifier) ShowUpdateDialog(info *ReleaseInfo) {
+	n.dialogs = append(n.dialogs, info.Version)
+}
+
+func (n *recordingNotifier) ShowUpdateBanner(info *ReleaseInfo) {
+	n.banners = append(n.banners, info.Version)
+}
+
+func TestNotificationPresenter(t *testing.T) {
+	newer := &ReleaseInfo{Version: "v0.2.0", IsNewer: true}
+
+	tests := []struct {
+		name        string
+		info        *ReleaseInfo
+		style       string
+		skipped     string
+		want        string
+		wantDialogs int
+		wantBanners int
+	}{
+		{"Dialog", newer, NotifyDialog, "", NotifyDialog, 1, 0},
+		{"Banner", newer, NotifyBanner, "", NotifyBanner, 0, 1},
+		{"Silent", newer, NotifySilent, "", NotifySilent, 0, 0},
+		{"Unknown style falls back to dialog", newer, "popup", "", NotifyDialog, 1, 0},
+		{"Empty style falls back to dialog", newer, "", "", NotifyDialog, 1, 0},
+		{"Skipped version", newer, NotifyDialog, "v0.2.0", NotifySilent, 0, 0},
+		{"Other version skipped", newer, NotifyBanner, "v0.1.5", NotifyBanner, 0, 1},
+		{"No update", &ReleaseInfo{Version: "v0.1.0"}, NotifyDialog, "", "", 0, 0},
+		{"Nil info", nil, NotifyBanner, "", "", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			presenter := NewNotificationPresenter(notifier, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			got := presenter.Present(tt.info, tt.style, tt.skipped)
+			if got != tt.want {
+				t.Errorf("Present() = %q, want %q", got, tt.want)
+			}
+			if len(notifier.dialogs) != tt.wantDialogs || len(notifier.banners) != tt.wantBanners {
+				t.Errorf("dialogs = %v, banners = %v", notifier.dialogs, notifier.banners)
+			}
+		})
+	}
+}
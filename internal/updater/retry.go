@@ -0,0 +1,86 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Параметры повторных запросов к GitHub API
+const (
+	maxRequestAttempts = 3
+	initialRetryDelay  = 500 * time.Millisecond
+)
+
+// ErrOffline возвращается, если сеть недоступна (DNS не разрешается, нет соединения)
+var ErrOffline = errors.New("нет подключения к сети")
+
+// StatusError ответ GitHub API с неожиданным HTTP статусом
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error реализует интерфейс error
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("GitHub API вернул статус %d: %s", e.StatusCode, e.Body)
+}
+
+// withRetry выполняет fn до attempts раз с экспоненциально растущей задержкой
+// Повторяются только временные ошибки; ожидание прерывается при отмене ctx
+func withRetry(ctx context.Context, attempts int, delay time.Duration, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= attempts || !isRetryable(err) {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return classifyNetworkError(err)
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+
+	return classifyNetworkError(err)
+}
+
+// isRetryable определяет, имеет ли смысл повторить запрос
+func isRetryable(err error) bool {
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || isOfflineError(err)
+}
+
+// isOfflineError определяет, что ошибка вызвана отсутствием сети
+func isOfflineError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// classifyNetworkError помечает ошибки отсутствия сети как ErrOffline
+func classifyNetworkError(err error) error {
+	if err != nil && isOfflineError(err) && !errors.Is(err, ErrOffline) {
+		return fmt.Errorf("%w: %w", ErrOffline, err)
+	}
+	return err
+}
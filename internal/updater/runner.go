@@ -0,0 +1,89 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrCheckInProgress возвращается, если проверка обновлений уже выполняется
+var ErrCheckInProgress = errors.New("проверка обновлений уже выполняется")
+
+// Checker проверяет наличие обновлений
+// Реализуется UpdateChecker; позволяет подменять проверку в тестах
+type Checker interface {
+	CheckForUpdates(ctx context.Context) (*ReleaseInfo, error)
+}
+
+var _ Checker = (*UpdateChecker)(nil)
+
+// CheckRunner выполняет проверки обновлений, не допуская одновременных запусков
+// Используется и фоновой проверкой при старте, и ручной проверкой из меню
+type CheckRunner struct {
+	checker Checker
+	running atomic.Bool
+}
+
+// NewCheckRunner создает новый CheckRunner
+func NewCheckRunner(checker Checker) *CheckRunner {
+	return &CheckRunner{
+		checker: checker,
+	}
+}
+
+// Check выполняет проверку обновлений
+// Если другая проверка уже выполняется, сразу возвращает ErrCheckInProgress
+func (r *CheckRunner) Check(ctx context.Context) (*ReleaseInfo, error) {
+	if !r.running.CompareAndSwap(false, true) {
+		return nil, ErrCheckInProgress
+	}
+	defer r.running.Store(false)
+
+	return r.checker.CheckForUpdates(ctx)
+}
+
+// CheckOutcome итог проверки обновлений, по которому приложение решает, что показать
+type CheckOutcome int
+
+const (
+	OutcomeUpToDate        CheckOutcome = iota // Установлена последняя версия
+	OutcomeUpdateAvailable                     // Найдена более новая версия
+	OutcomeInProgress                          // Другая проверка уже выполняется
+	OutcomeOffline                             // Сеть недоступна
+	OutcomeRateLimited                         // Лимит запросов к GitHub API исчерпан
+	OutcomeFailed                              // Проверка завершилась ошибкой
+)
+
+// ClassifyCheck определяет итог проверки по ее результату
+func ClassifyCheck(info *ReleaseInfo, err error) CheckOutcome {
+	switch {
+	case errors.Is(err, ErrCheckInProgress):
+		return OutcomeInProgress
+	case errors.Is(err, ErrRateLimited):
+		return OutcomeRateLimited
+	case errors.Is(err, ErrOffline):
+		return OutcomeOffline
+	case err != nil:
+		return OutcomeFailed
+	case info != nil && info.IsNewer:
+		return OutcomeUpdateAvailable
+	default:
+		return OutcomeUpToDate
+	}
+}
+
+// Recorded возвращает true, если проверку с этим итогом нужно учесть в расписании проверок
+// Пропущенная из-за другой проверки или лимита запросов проверка не считается попыткой
+func (o CheckOutcome) Recorded() bool {
+	return o != OutcomeInProgress && o != OutcomeRateLimited
+}
+
+// InProgress возвращает true, если проверка выполняется в данный момент
+func (r *CheckRunner) InProgress() bool {
+	return r.running.Load()
+}
+
+// Checker возвращает используемый механизм проверки
+func (r *CheckRunner) Checker() Checker {
+	return r.checker
+}
@@ -0,0 +1,117 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakeChecker имитирует проверку обновлений
+type fakeChecker struct {
+	info    *ReleaseInfo
+	err     error
+	calls   int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (f *fakeChecker) CheckForUpdates(ctx context.Context) (*ReleaseInfo, error) {
+	f.calls++
+	if f.started != nil {
+		close(f.started)
+	}
+	if f.release != nil {
+		<-f.release
+	}
+	return f.info, f.err
+}
+
+func TestCheckRunnerReturnsResult(t *testing.T) {
+	fake := &fakeChecker{info: &ReleaseInfo{Version: "v0.2.0", IsNewer: true}}
+	runner := NewCheckRunner(fake)
+
+	info, err := runner.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if info == nil || info.Version != "v0.2.0" {
+		t.Errorf("Check() = %+v, want version v0.2.0", info)
+	}
+	if runner.InProgress() {
+		t.Error("InProgress() = true after check completed")
+	}
+}
+
+func TestCheckRunnerPropagatesError(t *testing.T) {
+	wantErr := errors.New("network down")
+	runner := NewCheckRunner(&fakeChecker{err: wantErr})
+
+	_, err := runner.Check(context.Background())
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Check() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestCheckRunnerPreventsOverlap(t *testing.T) {
+	fake := &fakeChecker{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	runner := NewCheckRunner(fake)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runner.Check(context.Background())
+	}()
+
+	<-fake.started
+	if !runner.InProgress() {
+		t.Error("InProgress() = false while check is running")
+	}
+
+	if _, err := runner.Check(context.Background()); !errors.Is(err, ErrCheckInProgress) {
+		t.Errorf("second Check() error = %v, want ErrCheckInProgress", err)
+	}
+
+	close(fake.release)
+	<-done
+
+	if fake.calls != 1 {
+		t.Errorf("checker called %d times, want 1", fake.calls)
+	}
+}
+
+func TestClassifyCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		checker      *fakeChecker
+		want         CheckOutcome
+		wantRecorded bool
+	}{
+		{"новая версия", &fakeChecker{info: &ReleaseInfo{Version: "v0.2.0", IsNewer: true}}, OutcomeUpdateAvailable, true},
+		{"последняя версия", &fakeChecker{info: &ReleaseInfo{Version: "v0.1.0"}}, OutcomeUpToDate, true},
+		{"нет релизов", &fakeChecker{}, OutcomeUpToDate, true},
+		{"нет сети", &fakeChecker{err: fmt.Errorf("запрос: %w", ErrOffline)}, OutcomeOffline, true},
+		{"лимит запросов", &fakeChecker{err: &RateLimitError{}}, OutcomeRateLimited, false},
+		{"ошибка сервера", &fakeChecker{err: &StatusError{StatusCode: 500}}, OutcomeFailed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := NewCheckRunner(tt.checker).Check(context.Background())
+			got := ClassifyCheck(info, err)
+			if got != tt.want {
+				t.Errorf("ClassifyCheck() = %v, want %v", got, tt.want)
+			}
+			if got.Recorded() != tt.wantRecorded {
+				t.Errorf("Recorded() = %v, want %v", got.Recorded(), tt.wantRecorded)
+			}
+		})
+	}
+
+	if got := ClassifyCheck(nil, ErrCheckInProgress); got != OutcomeInProgress || got.Recorded() {
+		t.Errorf("ClassifyCheck(ErrCheckInProgress) = %v, Recorded() = %v", got, got.Recorded())
+	}
+}
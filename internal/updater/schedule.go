@@ -0,0 +1,52 @@
+package updater
+
+import "time"
+
+// Параметры повторных попыток после неудачной проверки
+const (
+	failureRetryDelay    = time.Hour // Задержка после первой неудачной проверки
+	maxFailureBackoffExp = 6         // Ограничение роста задержки (2^6 часов)
+)
+
+// CheckSchedule расписание автоматической проверки обновлений
+type CheckSchedule struct {
+	Enabled       bool          // Автоматическая проверка включена
+	Interval      time.Duration // Интервал между проверками (0 - при каждом запуске)
+	LastCheckedAt time.Time     // Время последней попытки проверки
+	Failures      int           // Количество неудачных проверок подряд
+}
+
+// NextCheckAt возвращает время, начиная с которого допустима следующая проверка
+// После неудачных проверок задержка растет экспоненциально, но не превышает интервал
+func (s CheckSchedule) NextCheckAt() time.Time {
+	if s.LastCheckedAt.IsZero() || s.Interval <= 0 {
+		return s.LastCheckedAt
+	}
+
+	delay := s.Interval
+	if s.Failures > 0 {
+		exp := s.Failures - 1
+		if exp > maxFailureBackoffExp {
+			exp = maxFailureBackoffExp
+		}
+		if backoff := failureRetryDelay << exp; backoff < delay {
+			delay = backoff
+		}
+	}
+
+	return s.LastCheckedAt.Add(delay)
+}
+
+// ShouldCheck определяет, нужно ли выполнять автоматическую проверку в момент now
+func (s CheckSchedule) ShouldCheck(now time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+
+	// Первая проверка или часы были переведены назад
+	if s.LastCheckedAt.IsZero() || s.LastCheckedAt.After(now) {
+		return true
+	}
+
+	return !now.Before(s.NextCheckAt())
+}
@@ -0,0 +1,107 @@
+package updater
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckScheduleShouldCheck(t *testing.T) {
+	now := time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		schedule CheckSchedule
+		want     bool
+	}{
+		{
+			name:     "Disabled never checks",
+			schedule: CheckSchedule{Enabled: false, Interval: day},
+			want:     false,
+		},
+		{
+			name:     "First launch checks",
+			schedule: CheckSchedule{Enabled: true, Interval: day},
+			want:     true,
+		},
+		{
+			name:     "Interval not elapsed",
+			schedule: CheckSchedule{Enabled: true, Interval: day, LastCheckedAt: now.Add(-23 * time.Hour)},
+			want:     false,
+		},
+		{
+			name:     "Interval elapsed exactly",
+			schedule: CheckSchedule{Enabled: true, Interval: day, LastCheckedAt: now.Add(-day)},
+			want:     true,
+		},
+		{
+			name:     "Interval elapsed long ago",
+			schedule: CheckSchedule{Enabled: true, Interval: day, LastCheckedAt: now.Add(-10 * day)},
+			want:     true,
+		},
+		{
+			name:     "Zero interval checks on every launch",
+			schedule: CheckSchedule{Enabled: true, Interval: 0, LastCheckedAt: now.Add(-time.Minute)},
+			want:     true,
+		},
+		{
+			name:     "Last check in the future (clock changed)",
+			schedule: CheckSchedule{Enabled: true, Interval: day, LastCheckedAt: now.Add(time.Hour)},
+			want:     true,
+		},
+		{
+			name:     "First failure retries after an hour",
+			schedule: CheckSchedule{Enabled: true, Interval: day, LastCheckedAt: now.Add(-time.Hour), Failures: 1},
+			want:     true,
+		},
+		{
+			name:     "Second failure waits two hours",
+			schedule: CheckSchedule{Enabled: true, Interval: day, LastCheckedAt: now.Add(-time.Hour), Failures: 2},
+			want:     false,
+		},
+		{
+			name:     "Backoff is capped by interval",
+			schedule: CheckSchedule{Enabled: true, Interval: day, LastCheckedAt: now.Add(-day), Failures: 10},
+			want:     true,
+		},
+		{
+			name:     "Disabled ignores failures",
+			schedule: CheckSchedule{Enabled: false, Interval: day, LastCheckedAt: now.Add(-10 * day), Failures: 3},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.ShouldCheck(now); got != tt.want {
+				t.Errorf("ShouldCheck() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckScheduleNextCheckAt(t *testing.T) {
+	last := time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{"No failures uses interval", 0, week},
+		{"One failure", 1, time.Hour},
+		{"Two failures", 2, 2 * time.Hour},
+		{"Three failures", 3, 4 * time.Hour},
+		{"Backoff growth is limited", 20, 64 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := CheckSchedule{Enabled: true, Interval: week, LastCheckedAt: last, Failures: tt.failures}
+			if got := schedule.NextCheckAt().Sub(last); got != tt.want {
+				t.Errorf("NextCheckAt() delay = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package updater
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/DatKorso/Merge-excel/internal/config"
+)
+
+// Source источник обновлений: API GitHub (или GitHub Enterprise) и репозиторий с релизами
+// Пустые поля означают значения по умолчанию
+type Source struct {
+	APIBaseURL   string // Базовый URL API, например https://github.example.com/api/v3
+	Owner        string // Владелец репозитория
+	Repo         string // Имя репозитория
+	CABundlePath string // PEM-файл с дополнительными корневыми сертификатами
+}
+
+// NormalizeAPIBaseURL проверяет базовый URL API и приводит его к виду без завершающего "/"
+// Проверка общая с файлом настроек (config.NormalizeUpdateAPIBaseURL); пустой адрес - api.github.com
+func NormalizeAPIBaseURL(raw string) (string, error) {
+	normalized, err := config.NormalizeUpdateAPIBaseURL(raw)
+	if err != nil || normalized != "" {
+		return normalized, err
+	}
+	return githubAPIBaseURL, nil
+}
+
+// newHTTPClient создает HTTP клиент, доверяющий системным сертификатам
+// и сертификатам из caBundlePath (если указан)
+func newHTTPClient(caBundlePath string) (*http.Client, error) {
+	client := &http.Client{Timeout: requestTimeout}
+	if caBundlePath == "" {
+		return client, nil
+	}
+
+	pem, err := os.ReadFile(caBundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл сертификатов %s: %w", caBundlePath, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("файл %s не содержит сертификатов в формате PEM", caBundlePath)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+	client.Transport = transport
+
+	return client, nil
+}
@@ -0,0 +1,127 @@
+package updater
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeAPIBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"Empty uses github.com", "", githubAPIBaseURL, false},
+		{"Enterprise", "https://github.example.com/api/v3", "https://github.example.com/api/v3", false},
+		{"Trailing slash", "https://github.example.com/api/v3/", "https://github.example.com/api/v3", false},
+		{"Spaces", "  http://localhost:8080  ", "http://localhost:8080", false},
+		{"No scheme", "github.example.com/api/v3", "", true},
+		{"Unsupported scheme", "ftp://github.example.com", "", true},
+		{"No host", "https:///api/v3", "", true},
+		{"Query", "https://github.example.com/api/v3?x=1", "", true},
+		{"Malformed", "https://exa mple.com", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeAPIBaseURL(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeAPIBaseURL(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeAPIBaseURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeServerCA сохраняет сертификат тестового TLS сервера в PEM-файл
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSetSourceEnterpriseEndpoint(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/corp/merger-fork/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"tag_name":"v2.0.0"}]`))
+	}))
+	defer server.Close()
+
+	source := Source{
+		APIBaseURL:   server.URL + "/api/v3/",
+		Owner:        "corp",
+		Repo:         "merger-fork",
+		CABundlePath: writeServerCA(t, server),
+	}
+
+	client := NewGitHubClient("owner", "repo")
+	client.token = ""
+	client.retryDelay = 0
+	client.SetCacheDir(t.TempDir())
+	if err := client.SetSource(source); err != nil {
+		t.Fatalf("SetSource() error = %v", err)
+	}
+
+	releases, err := client.GetReleases(context.Background())
+	if err != nil {
+		t.Fatalf("GetReleases() error = %v", err)
+	}
+	if len(releases) != 1 || releases[0].TagName != "v2.0.0" {
+		t.Errorf("unexpected releases: %+v", releases)
+	}
+
+	// Без сертификата сервера TLS соединение не устанавливается
+	untrusted := NewGitHubClient("corp", "merger-fork")
+	untrusted.token = ""
+	untrusted.retryDelay = 0
+	untrusted.SetCacheDir(t.TempDir())
+	if err := untrusted.SetSource(Source{APIBaseURL: server.URL + "/api/v3"}); err != nil {
+		t.Fatalf("SetSource() error = %v", err)
+	}
+	if _, err := untrusted.GetReleases(context.Background()); err == nil {
+		t.Error("expected TLS error without custom CA bundle")
+	}
+}
+
+func TestSetSourceRejectsInvalidSettings(t *testing.T) {
+	badPEM := filepath.Join(t.TempDir(), "bad.pem")
+	if err := os.WriteFile(badPEM, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		source Source
+	}{
+		{"Invalid URL", Source{APIBaseURL: "api.example.com"}},
+		{"Missing CA bundle", Source{CABundlePath: filepath.Join(t.TempDir(), "missing.pem")}},
+		{"CA bundle without certificates", Source{CABundlePath: badPEM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewGitHubClient("owner", "repo")
+			if err := client.SetSource(tt.source); err == nil {
+				t.Fatal("expected error")
+			}
+			if client.baseURL != githubAPIBaseURL || client.owner != "owner" {
+				t.Errorf("client must stay unchanged after failed SetSource: %s %s", client.baseURL, client.owner)
+			}
+		})
+	}
+}
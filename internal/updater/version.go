@@ -81,8 +81,59 @@ func (v *Version) IsNewer(other *Version) bool {
 		return false
 	}
 
-	// Если оба имеют prerelease, считаем версии равными
-	return false
+	// Если оба имеют prerelease, сравниваем их по правилам semver (alpha < beta < rc)
+	return comparePrerelease(v.Prerelease, other.Prerelease) > 0
+}
+
+// comparePrerelease сравнивает идентификаторы prerelease по правилам semver
+// Идентификаторы разделяются точками, числовые сравниваются как числа и считаются
+// младше буквенных, буквенные сравниваются лексикографически (alpha < beta < rc).
+// Возвращает -1, 0 или 1
+func comparePrerelease(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				if aNum > bNum {
+					return 1
+				}
+				return -1
+			}
+		case aErr == nil:
+			// Числовой идентификатор младше буквенного
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			aID := strings.ToLower(aParts[i])
+			bID := strings.ToLower(bParts[i])
+			if aID != bID {
+				if aID > bID {
+					return 1
+				}
+				return -1
+			}
+		}
+	}
+
+	// Более длинный набор идентификаторов считается новее (alpha < alpha.1)
+	switch {
+	case len(aParts) > len(bParts):
+		return 1
+	case len(aParts) < len(bParts):
+		return -1
+	}
+	return 0
 }
 
 // String возвращает строковое представление версии
@@ -150,11 +150,47 @@ func TestVersionIsNewer(t *testing.T) {
 			want:    false,
 		},
 		{
-			name:    "Both prereleases - same version",
+			name:    "Beta is newer than alpha",
 			current: "0.1.0-alpha",
 			latest:  "0.1.0-beta",
+			want:    true,
+		},
+		{
+			name:    "Alpha is not newer than beta",
+			current: "0.1.0-beta",
+			latest:  "0.1.0-alpha",
 			want:    false,
 		},
+		{
+			name:    "RC is newer than beta",
+			current: "0.1.0-beta",
+			latest:  "0.1.0-rc",
+			want:    true,
+		},
+		{
+			name:    "Numeric identifiers compared numerically",
+			current: "0.1.0-rc.2",
+			latest:  "0.1.0-rc.10",
+			want:    true,
+		},
+		{
+			name:    "Longer identifier set is newer",
+			current: "0.1.0-alpha",
+			latest:  "0.1.0-alpha.1",
+			want:    true,
+		},
+		{
+			name:    "Same prerelease is not newer",
+			current: "0.1.0-beta",
+			latest:  "0.1.0-beta",
+			want:    false,
+		},
+		{
+			name:    "Next patch prerelease is newer than release",
+			current: "0.1.0",
+			latest:  "0.1.1-alpha",
+			want:    true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -268,3 +304,22 @@ func TestVersionString(t *testing.T) {
 		})
 	}
 }
+
+func TestComparePrerelease(t *testing.T) {
+	// Порядок от младшей к старшей версии
+	ordered := []string{"alpha", "alpha.1", "alpha.beta", "beta", "beta.2", "beta.11", "rc", "rc.1"}
+
+	for i := 0; i < len(ordered)-1; i++ {
+		lower, higher := ordered[i], ordered[i+1]
+		if got := comparePrerelease(lower, higher); got != -1 {
+			t.Errorf("comparePrerelease(%q, %q) = %d, want -1", lower, higher, got)
+		}
+		if got := comparePrerelease(higher, lower); got != 1 {
+			t.Errorf("comparePrerelease(%q, %q) = %d, want 1", higher, lower, got)
+		}
+	}
+
+	if got := comparePrerelease("rc.1", "rc.1"); got != 0 {
+		t.Errorf("comparePrerelease(rc.1, rc.1) = %d, want 0", got)
+	}
+}